@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/engine/worker/pkg/localrun"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+	"github.com/ovh/cds/sdk/log"
+)
+
+var execCmd = cli.Command{
+	Name:  "exec",
+	Short: "Run a job of a pipeline on your machine",
+	Long: `
+Useful to debug a job without pushing it and waiting for a worker: the steps of the job are run on your machine by the worker engine and the logs are displayed like in CDS.
+
+Variables can be given with flags or taken from a workflow run, secrets are never loaded from CDS and must be given with flags:
+
+	cdsctl exec build.pip.yml Build --var cds.app.name=my-app --secret cds.proj.token=XXXX
+	cdsctl exec build.pip.yml Build --project MY-PROJECT --workflow my-workflow --run-number 42
+
+Uploaded artifacts are stored in a local directory, by tag, and artifacts are downloaded from it. Action plugins and actions used by the job are loaded from CDS, so you have to be logged in to use them. Cache, release, static files and deployment steps are not available.
+	`,
+	Args: []cli.Arg{
+		{Name: "pipeline-file"},
+		{Name: "job-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Type:      cli.FlagArray,
+			Name:      "var",
+			ShortHand: "v",
+			Usage:     "Specify a variable for the job like --var name=value",
+		},
+		{
+			Type:      cli.FlagArray,
+			Name:      "secret",
+			ShortHand: "s",
+			Usage:     "Specify a secret for the job like --secret name=value, its value is hidden in the logs",
+		},
+		{
+			Name:  "project",
+			Usage: "Key of the project of the workflow run used to load the variables of the job",
+		},
+		{
+			Name:  "workflow",
+			Usage: "Name of the workflow of the run used to load the variables of the job",
+		},
+		{
+			Name:  "run-number",
+			Usage: "Number of the workflow run used to load the variables of the job",
+		},
+		{
+			Name:  "node",
+			Usage: "Name of the workflow node used to load the variables of the job, default to the name of the pipeline",
+		},
+		{
+			Name:    "workspace",
+			Usage:   "Directory where the job is run, default to a temporary directory",
+			Default: "",
+		},
+		{
+			Name:    "artifacts-dir",
+			Usage:   "Directory where the artifacts are stored",
+			Default: ".cds-artifacts",
+		},
+	},
+}
+
+func execCommand() *cobra.Command {
+	return cli.NewCommand(execCmd, execRun, nil, withAllCommandModifiers()...)
+}
+
+func execRun(v cli.Values) error {
+	// logs of the worker engine are only displayed in verbose mode, logs of the job are printed on stdout
+	if v.GetBool("verbose") || os.Getenv("CDS_VERBOSE") == "true" {
+		log.Initialize(&log.Conf{Level: "debug"})
+	} else {
+		logrus.SetOutput(ioutil.Discard)
+	}
+
+	btes, err := ioutil.ReadFile(v.GetString("pipeline-file"))
+	if err != nil {
+		return err
+	}
+	payload, err := exportentities.ParsePipeline(filepath.Ext(v.GetString("pipeline-file")), btes)
+	if err != nil {
+		return err
+	}
+	p, err := payload.Pipeline()
+	if err != nil {
+		return err
+	}
+
+	var job *sdk.Job
+	for _, s := range p.Stages {
+		for i := range s.Jobs {
+			if s.Jobs[i].Action.Name == v.GetString("job-name") {
+				job = &s.Jobs[i]
+			}
+		}
+	}
+	if job == nil {
+		return fmt.Errorf("job %s not found in pipeline %s", v.GetString("job-name"), p.Name)
+	}
+
+	if err := execResolveSteps(job.Action.Actions); err != nil {
+		return err
+	}
+
+	params, err := execRunParameters(v, p.Name)
+	if err != nil {
+		return err
+	}
+	for _, p := range v.GetStringArray("var") {
+		name, value, err := execParseVariable(p)
+		if err != nil {
+			return err
+		}
+		sdk.ParameterAddOrSetValue(&params, name, sdk.StringParameter, value)
+	}
+
+	var secrets []sdk.Variable
+	for _, s := range v.GetStringArray("secret") {
+		name, value, err := execParseVariable(s)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, sdk.Variable{Name: name, Type: sdk.SecretVariable, Value: value})
+	}
+
+	workspace := v.GetString("workspace")
+	if workspace == "" {
+		workspace, err = ioutil.TempDir("", "cds-exec")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(workspace) // nolint
+	}
+
+	opts := localrun.Options{
+		Workspace:    workspace,
+		ArtifactsDir: v.GetString("artifacts-dir"),
+		Output:       os.Stdout,
+	}
+	if execLoggedIn() {
+		opts.Client = client
+	}
+
+	res, err := localrun.Run(context.Background(), *job, params, secrets, opts)
+	if err != nil {
+		return err
+	}
+	if res.Status != sdk.StatusSuccess {
+		if res.Reason != "" {
+			return fmt.Errorf("job %s ended with status %s: %s", job.Action.Name, res.Status, res.Reason)
+		}
+		return fmt.Errorf("job %s ended with status %s", job.Action.Name, res.Status)
+	}
+
+	fmt.Printf("Job %s succeeded in %s\n", job.Action.Name, res.Duration)
+	if abs, err := filepath.Abs(opts.ArtifactsDir); err == nil {
+		fmt.Printf("Artifacts are stored in %s\n", abs)
+	}
+	return nil
+}
+
+// execLoggedIn returns true if a CDS API is configured, the client is set even if no configuration was found.
+func execLoggedIn() bool {
+	return client != nil && cfg != nil && cfg.Host != ""
+}
+
+func execParseVariable(s string) (string, string, error) {
+	ps := strings.SplitN(s, "=", 2)
+	if len(ps) != 2 || ps[0] == "" {
+		return "", "", fmt.Errorf("invalid given variable %s, it should be like name=value", s)
+	}
+	return ps[0], ps[1], nil
+}
+
+// execRunParameters returns the parameters of the node run of given workflow run if any.
+func execRunParameters(v cli.Values, pipelineName string) ([]sdk.Parameter, error) {
+	if v.GetString("run-number") == "" {
+		return nil, nil
+	}
+	if v.GetString("project") == "" || v.GetString("workflow") == "" {
+		return nil, fmt.Errorf("project and workflow flags are required to load the variables of a run")
+	}
+	if !execLoggedIn() {
+		return nil, fmt.Errorf("you should be logged in to load the variables of a run, see %s login --help", os.Args[0])
+	}
+	number, err := strconv.ParseInt(v.GetString("run-number"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid given run number %s", v.GetString("run-number"))
+	}
+
+	wr, err := client.WorkflowRunGet(v.GetString("project"), v.GetString("workflow"), number)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeName := v.GetString("node")
+	if nodeName == "" {
+		nodeName = pipelineName
+	}
+	var nodeRun *sdk.WorkflowNodeRun
+	for _, nrs := range wr.WorkflowNodeRuns {
+		for i := range nrs {
+			if nrs[i].WorkflowNodeName != nodeName {
+				continue
+			}
+			if nodeRun == nil || nrs[i].SubNumber > nodeRun.SubNumber {
+				nodeRun = &nrs[i]
+			}
+		}
+	}
+	if nodeRun == nil {
+		return nil, fmt.Errorf("node %s not found in workflow %s run %d", nodeName, v.GetString("workflow"), number)
+	}
+
+	return append([]sdk.Parameter{}, nodeRun.BuildParameters...), nil
+}
+
+// execResolveSteps loads from CDS the action plugins and the actions used by given steps, like it is done by
+// the API when a pipeline is imported.
+func execResolveSteps(steps []sdk.Action) error {
+	for i := range steps {
+		s := &steps[i]
+		if s.Type == sdk.BuiltinAction || s.Type == sdk.PluginAction {
+			continue
+		}
+		if s.Type == sdk.DefaultAction && (len(s.Actions) > 0 || s.Group == nil) {
+			if err := execResolveSteps(s.Actions); err != nil {
+				return err
+			}
+			continue
+		}
+		if !execLoggedIn() {
+			return fmt.Errorf("you should be logged in to run step %s, see %s login --help", s.Name, os.Args[0])
+		}
+
+		if s.Group == nil {
+			if _, err := client.PluginGetBinaryInfos(s.Name, runtime.GOOS, runtime.GOARCH); err == nil {
+				s.Type = sdk.PluginAction
+				continue
+			}
+		}
+
+		groupName := sdk.SharedInfraGroupName
+		if s.Group != nil {
+			groupName = s.Group.Name
+		}
+		a, err := client.ActionGet(groupName, s.Name)
+		if err != nil {
+			return fmt.Errorf("cannot load action %s/%s: %v", groupName, s.Name, err)
+		}
+
+		// default values of the action parameters are replaced by the values given in the step
+		params := append([]sdk.Parameter{}, a.Parameters...)
+		for _, p := range s.Parameters {
+			sdk.ParameterAddOrSetValue(&params, p.Name, p.Type, p.Value)
+		}
+		s.Type = a.Type
+		s.Group = a.Group
+		s.Parameters = params
+		s.Requirements = a.Requirements
+		s.Actions = a.Actions
+		if err := execResolveSteps(s.Actions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ func main() {
 		contexts(),
 		environment(),
 		events(),
+		execCommand(),
 		group(),
 		health(),
 		login(),
@@ -76,6 +77,8 @@ func rootFromSubCommands(cmds []*cobra.Command) *cobra.Command {
 			cmd.Name() == "reset-password" ||
 			cmd.Name() == "confirm" ||
 			cmd.Name() == "version" ||
+			cmd.Name() == "exec" ||
+			(cmd.Name() == "lint" && cmd.Parent() != nil && cmd.Parent().Name() == "workflow") ||
 			cmd.Name() == "doc" || strings.HasPrefix(cmd.Use, "doc ") || (cmd.Run == nil && cmd.RunE == nil) {
 			return
 		}
@@ -25,7 +25,10 @@ func projectCommands() []*cobra.Command {
 		cli.NewCommand(projectCreateCmd, projectCreateRun, nil),
 		cli.NewDeleteCommand(projectDeleteCmd, projectDeleteRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(projectFavoriteCmd, projectFavoriteRun, nil, withAllCommandModifiers()...),
+		cli.NewListCommand(projectMetricsCmd, projectMetricsRun, nil, withAllCommandModifiers()...),
 		projectKey(),
+		projectLock(),
+		projectCalendar(),
 		projectGroup(),
 		projectVariable(),
 		projectIntegration(),
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var projectCalendarCmd = cli.Command{
+	Name:  "calendar",
+	Short: "Manage CDS project calendars",
+	Long: `A calendar defines blackout windows during which the scheduler hooks that reference it don't trigger their workflow,
+ex: public holidays or a freeze declared by ops.`,
+}
+
+func projectCalendar() *cobra.Command {
+	return cli.NewCommand(projectCalendarCmd, nil, []*cobra.Command{
+		cli.NewListCommand(projectCalendarListCmd, projectCalendarListRun, nil, withAllCommandModifiers()...),
+		cli.NewGetCommand(projectCalendarShowCmd, projectCalendarShowRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(projectCalendarImportCmd, projectCalendarImportRun, nil, withAllCommandModifiers()...),
+		cli.NewDeleteCommand(projectCalendarDeleteCmd, projectCalendarDeleteRun, nil, withAllCommandModifiers()...),
+	})
+}
+
+var projectCalendarListCmd = cli.Command{
+	Name:  "list",
+	Short: "List CDS project calendars",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+}
+
+func projectCalendarListRun(v cli.Values) (cli.ListResult, error) {
+	cs, err := client.ProjectCalendarList(v.GetString(_ProjectKey))
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(cs), nil
+}
+
+var projectCalendarShowCmd = cli.Command{
+	Name:  "show",
+	Short: "Show a CDS project calendar",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "name"},
+	},
+}
+
+func projectCalendarShowRun(v cli.Values) (interface{}, error) {
+	return client.ProjectCalendarGet(v.GetString(_ProjectKey), v.GetString("name"))
+}
+
+var projectCalendarImportCmd = cli.Command{
+	Name:  "import",
+	Short: "Import a CDS project calendar from a yaml file",
+	Example: `cdsctl project calendar import MY-PROJECT holidays.yml
+
+# holidays.yml
+name: holidays
+timezone: Europe/Paris
+blackouts:
+- name: christmas
+  from: "2020-12-24"
+  to: "2020-12-26"
+- name: freeze
+  from: "2020-11-02T18:00"
+  to: "2020-11-03T08:00"
+- name: weekend
+  cron: "0 0 * * 6"
+  duration: 48h`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "filename"},
+	},
+	Flags: []cli.Flag{
+		{Name: "force", Type: cli.FlagBool, Usage: "Update the calendar if it already exists"},
+	},
+}
+
+func projectCalendarImportRun(v cli.Values) error {
+	btes, err := ioutil.ReadFile(v.GetString("filename"))
+	if err != nil {
+		return fmt.Errorf("unable to read file %s: %v", v.GetString("filename"), err)
+	}
+	var c sdk.ProjectCalendar
+	if err := yaml.Unmarshal(btes, &c); err != nil {
+		return fmt.Errorf("unable to parse file %s: %v", v.GetString("filename"), err)
+	}
+
+	projectKey := v.GetString(_ProjectKey)
+	if v.GetBool("force") {
+		if _, err := client.ProjectCalendarGet(projectKey, c.Name); err == nil {
+			if err := client.ProjectCalendarUpdate(projectKey, &c); err != nil {
+				return err
+			}
+			fmt.Printf("Calendar %s updated in project %s\n", c.Name, projectKey)
+			return nil
+		} else if !sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return err
+		}
+	}
+
+	if err := client.ProjectCalendarCreate(projectKey, &c); err != nil {
+		return err
+	}
+	fmt.Printf("Calendar %s created in project %s\n", c.Name, projectKey)
+	return nil
+}
+
+var projectCalendarDeleteCmd = cli.Command{
+	Name:  "delete",
+	Short: "Delete a CDS project calendar",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "name"},
+	},
+}
+
+func projectCalendarDeleteRun(v cli.Values) error {
+	err := client.ProjectCalendarDelete(v.GetString(_ProjectKey), v.GetString("name"))
+	if err != nil && v.GetBool("force") && sdk.ErrorIs(err, sdk.ErrNotFound) {
+		fmt.Println(err.Error())
+		return nil
+	}
+	return err
+}
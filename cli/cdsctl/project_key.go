@@ -24,7 +24,7 @@ func projectKey() *cobra.Command {
 
 var projectKeyCreateCmd = cli.Command{
 	Name:  "add",
-	Short: "Add a new key on project. key-type can be ssh or pgp",
+	Short: "Add a new key on project. key-type can be ssh, pgp or hmac",
 	Ctx: []cli.Arg{
 		{Name: _ProjectKey},
 	},
@@ -46,6 +46,11 @@ func projectCreateKeyRun(v cli.Values) error {
 	}
 
 	fmt.Printf("Project key %s of type %s created with success in project %s\n", key.Name, key.Type, v.GetString(_ProjectKey))
+	if key.Type == sdk.KeyTypeHMAC {
+		// The secret is only displayed once, it has to be shared with the sender of the signed payloads
+		fmt.Println(key.Private)
+		return nil
+	}
 	fmt.Println(key.Public)
 	return nil
 }
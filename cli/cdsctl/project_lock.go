@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/cli"
+)
+
+var projectLockCmd = cli.Command{
+	Name:  "lock",
+	Short: "Manage CDS project locks",
+	Long: `A project lock is shared by all the workflows of a project. A node or an environment that declares a lock
+can't be run while another node run holds the lock with the same name.`,
+}
+
+func projectLock() *cobra.Command {
+	return cli.NewCommand(projectLockCmd, nil, []*cobra.Command{
+		cli.NewListCommand(projectLockListCmd, projectLockListRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(projectLockReleaseCmd, projectLockReleaseRun, nil, withAllCommandModifiers()...),
+	})
+}
+
+var projectLockListCmd = cli.Command{
+	Name:  "list",
+	Short: "List holders and waiters of CDS project locks",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+}
+
+func projectLockListRun(v cli.Values) (cli.ListResult, error) {
+	locks, err := client.ProjectLockList(v.GetString(_ProjectKey))
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(locks), nil
+}
+
+var projectLockReleaseCmd = cli.Command{
+	Name:  "release",
+	Short: "Force the release of a CDS project lock",
+	Long: `Force the release of a CDS project lock, the node run that holds the lock is not stopped
+and the next waiter acquires the lock. Only an administrator or the user that triggered the workflow
+run holding the lock can release it.`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "lock-name"},
+	},
+}
+
+func projectLockReleaseRun(v cli.Values) error {
+	if err := client.ProjectLockRelease(v.GetString(_ProjectKey), v.GetString("lock-name")); err != nil {
+		return err
+	}
+	fmt.Printf("Lock %s released in project %s\n", v.GetString("lock-name"), v.GetString(_ProjectKey))
+	return nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+var projectMetricsCmd = cli.Command{
+	Name:  "metrics",
+	Short: "Show delivery metrics of a CDS project",
+	Long: `Show delivery metrics computed from the deployments of a project: deployment frequency (per day), lead time for changes,
+change failure rate and mean time to restore (MTTR). A deployment is the run of a pipeline with an environment.
+
+	# metrics of the last 30 days
+	$ cdsctl project metrics KEY
+
+	# weekly metrics of the production environment of a workflow since the beginning of the year
+	$ cdsctl project metrics KEY --workflow=my-workflow --environment=production --from=2020-01-01 --period=7
+`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "workflow",
+			Usage: "Filter on workflow name",
+		},
+		{
+			Name:  "environment",
+			Usage: "Filter on environment name",
+		},
+		{
+			Name:  "from",
+			Usage: "Start date (YYYY-MM-DD), default is 30 days before end date",
+		},
+		{
+			Name:  "to",
+			Usage: "End date (YYYY-MM-DD), default is now",
+		},
+		{
+			Name:  "period",
+			Usage: "Split the metrics in windows of given number of days",
+		},
+		{
+			Name:  "tagged",
+			Type:  cli.FlagBool,
+			Usage: "Only count the deployments of tagged versions",
+		},
+	},
+}
+
+func projectMetricsRun(v cli.Values) (cli.ListResult, error) {
+	var filters []cdsclient.Filter
+	for _, name := range []string{"workflow", "environment", "period"} {
+		if v.GetString(name) != "" {
+			filters = append(filters, cdsclient.Filter{Name: name, Value: v.GetString(name)})
+		}
+	}
+	for _, name := range []string{"from", "to"} {
+		if v.GetString(name) == "" {
+			continue
+		}
+		d, err := time.ParseInLocation("2006-01-02", v.GetString(name), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s date %s, expected format is YYYY-MM-DD", name, v.GetString(name))
+		}
+		filters = append(filters, cdsclient.Filter{Name: name, Value: d.Format(time.RFC3339)})
+	}
+	if v.GetBool("tagged") {
+		filters = append(filters, cdsclient.Filter{Name: "tagged", Value: "true"})
+	}
+
+	ms, err := client.ProjectDeliveryMetrics(v.GetString(_ProjectKey), filters...)
+	if err != nil {
+		return nil, err
+	}
+
+	type metricsDisplay struct {
+		Workflow            string `cli:"workflow"`
+		Environment         string `cli:"environment"`
+		From                string `cli:"from"`
+		To                  string `cli:"to"`
+		Deployments         int64  `cli:"deployments"`
+		DeploymentFrequency string `cli:"frequency"`
+		LeadTime            string `cli:"lead_time"`
+		ChangeFailureRate   string `cli:"failure_rate"`
+		MTTR                string `cli:"mttr"`
+	}
+
+	res := make([]metricsDisplay, len(ms))
+	for i, m := range ms {
+		res[i] = metricsDisplay{
+			Workflow:            m.WorkflowName,
+			Environment:         m.EnvironmentName,
+			From:                m.From.Local().Format("2006-01-02 15:04"),
+			To:                  m.To.Local().Format("2006-01-02 15:04"),
+			Deployments:         m.Deployments,
+			DeploymentFrequency: fmt.Sprintf("%.2f/day", m.DeploymentFrequency),
+			LeadTime:            (time.Duration(m.LeadTime) * time.Second).String(),
+			ChangeFailureRate:   fmt.Sprintf("%.0f%%", m.ChangeFailureRate*100),
+			MTTR:                (time.Duration(m.MTTR) * time.Second).String(),
+		}
+		if m.WorkflowName == "" {
+			res[i].Workflow = "*"
+			res[i].Environment = "*"
+		}
+	}
+	return cli.AsListResult(res), nil
+}
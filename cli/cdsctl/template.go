@@ -30,6 +30,11 @@ func template() *cobra.Command {
 		cli.NewCommand(templateDeleteCmd, templateDeleteRun, nil, withAllCommandModifiers()...),
 		cli.NewListCommand(templateInstancesCmd, templateInstancesRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(templateDetachCmd, templateDetachRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(templateReleaseCmd, templateReleaseRun, nil, withAllCommandModifiers()...),
+		cli.NewListCommand(templateReleasesCmd, templateReleasesRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(templateUpgradeCmd, templateUpgradeRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(templateDiffCmd, templateDiffRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(templateRollbackCmd, templateRollbackRun, nil, withAllCommandModifiers()...),
 	})
 }
 
@@ -42,6 +42,10 @@ func templateApplyCmd(name string) cli.Command {
 				Usage:   "Set to generate a workflow detached from the template",
 				Default: "",
 			},
+			{
+				Name:  "version-range",
+				Usage: "Pin the workflow to the releases of the template matching a version range like '>=1.2.0 <2.0.0' or '1.x'",
+			},
 			{
 				Name:      "output-dir",
 				ShortHand: "d",
@@ -155,6 +159,12 @@ func templateApplyRun(v cli.Values) error {
 		}
 	}
 
+	// keep the version range of previous template instance if not given
+	versionRange := v.GetString("version-range")
+	if versionRange == "" && wti != nil {
+		versionRange = wti.Request.VersionRange
+	}
+
 	// set params from cli flags
 	paramPairs := v.GetStringArray("params")
 	for _, p := range paramPairs {
@@ -287,6 +297,7 @@ func templateApplyRun(v cli.Values) error {
 		WorkflowName: workflowName,
 		Parameters:   params,
 		Detached:     v.GetBool("detach"),
+		VersionRange: versionRange,
 	}
 	if err := wt.CheckParams(req); err != nil {
 		return err
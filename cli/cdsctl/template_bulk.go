@@ -497,40 +497,45 @@ func templateBulkRun(v cli.Values) error {
 	fmt.Printf("Bulk request with id %d successfully created for template %s/%s with %d operations\n", res.ID, wt.Group.Name, wt.Slug, len(res.Operations))
 
 	if v.GetBool("track") {
-		var currentDisplay = new(cli.Display)
-		currentDisplay.Printf("Looking for bulk %d...\n", b.ID)
-		currentDisplay.Do(context.Background())
-
-		for {
-			res, err = client.TemplateGetBulk(wt.Group.Name, wt.Slug, res.ID)
-			if err != nil {
-				return err
-			}
+		return templateTrackBulk(wt, res.ID)
+	}
 
-			var out string
-			for _, o := range res.Operations {
-				var status string
-				switch o.Status {
-				case sdk.OperationStatusPending:
-					status = cli.Blue("pending")
-				case sdk.OperationStatusProcessing:
-					status = cli.Yellow("processing")
-				case sdk.OperationStatusDone:
-					status = cli.Green("done")
-				case sdk.OperationStatusError:
-					status = cli.Red("error")
-				}
-				out += fmt.Sprintf("%s/%s -> %s %s\n", o.Request.ProjectKey, o.Request.WorkflowName, status, o.Error)
-			}
+	return nil
+}
 
-			currentDisplay.Printf(out)
+// templateTrackBulk displays the status of the operations of a bulk until it's over.
+func templateTrackBulk(wt *sdk.WorkflowTemplate, id int64) error {
+	var currentDisplay = new(cli.Display)
+	currentDisplay.Printf("Looking for bulk %d...\n", id)
+	currentDisplay.Do(context.Background())
 
-			time.Sleep(500 * time.Millisecond)
-			if res.IsDone() {
-				break
+	for {
+		res, err := client.TemplateGetBulk(wt.Group.Name, wt.Slug, id)
+		if err != nil {
+			return err
+		}
+
+		var out string
+		for _, o := range res.Operations {
+			var status string
+			switch o.Status {
+			case sdk.OperationStatusPending:
+				status = cli.Blue("pending")
+			case sdk.OperationStatusProcessing:
+				status = cli.Yellow("processing")
+			case sdk.OperationStatusDone:
+				status = cli.Green("done")
+			case sdk.OperationStatusError:
+				status = cli.Red("error")
 			}
+			out += fmt.Sprintf("%s/%s -> %s %s\n", o.Request.ProjectKey, o.Request.WorkflowName, status, o.Error)
 		}
-	}
 
-	return nil
+		currentDisplay.Printf(out)
+
+		time.Sleep(500 * time.Millisecond)
+		if res.IsDone() {
+			return nil
+		}
+	}
 }
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var templateReleaseCmd = cli.Command{
+	Name:    "release",
+	Short:   "Release the current version of a CDS workflow template",
+	Example: "cdsctl template release group-name/template-slug 1.2.0 --changelog \"Add a deploy pipeline\"",
+	Args: []cli.Arg{
+		{Name: "template-path"},
+		{Name: "version"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "changelog",
+			Usage: "Describe the changes of the release",
+		},
+	},
+}
+
+func templateReleaseRun(v cli.Values) error {
+	wt, err := getTemplateFromCLI(v)
+	if err != nil {
+		return err
+	}
+
+	r, err := client.TemplateRelease(wt.Group.Name, wt.Slug, sdk.WorkflowTemplateRelease{
+		Version:   v.GetString("version"),
+		Changelog: v.GetString("changelog"),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Release %s successfully created for template %s/%s\n", r.Version, wt.Group.Name, wt.Slug)
+
+	return nil
+}
+
+var templateReleasesCmd = cli.Command{
+	Name:    "releases",
+	Short:   "Get releases of a CDS workflow template",
+	Example: "cdsctl template releases group-name/template-slug",
+	OptionalArgs: []cli.Arg{
+		{Name: "template-path"},
+	},
+}
+
+func templateReleasesRun(v cli.Values) (cli.ListResult, error) {
+	wt, err := getTemplateFromCLI(v)
+	if err != nil {
+		return nil, err
+	}
+	if wt == nil {
+		wt, err = suggestTemplate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rs, err := client.TemplateGetReleases(wt.Group.Name, wt.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	type TemplateReleaseDisplay struct {
+		Version   string `cli:"version,key"`
+		Created   string `cli:"created"`
+		Changelog string `cli:"changelog"`
+	}
+
+	trds := make([]TemplateReleaseDisplay, len(rs))
+	for i := range rs {
+		trds[i].Version = rs[i].Version
+		trds[i].Created = fmt.Sprintf("On %s by %s", rs[i].Created.Format(time.RFC3339), rs[i].Author)
+		trds[i].Changelog = rs[i].Changelog
+	}
+
+	return cli.AsListResult(trds), nil
+}
+
+var templateUpgradeCmd = cli.Command{
+	Name:  "upgrade",
+	Short: "Upgrade the instances of a CDS workflow template to a release",
+	Long: `Upgrade the instances of a template to a release, the latest one if no version is given.
+
+The upgrade can be rolled out to a subset of the instances first, by percentage or by workflow labels.
+Instances pinned to a version range that doesn't match the release are skipped.`,
+	Example: "cdsctl template upgrade group-name/template-slug --version 1.2.0 --percentage 10 --dry-run",
+	OptionalArgs: []cli.Arg{
+		{Name: "template-path"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "version",
+			Usage: "Version of the release to upgrade to, default is the latest release",
+		},
+		{
+			Name:  "percentage",
+			Usage: "Upgrade only the given percentage of the instances",
+		},
+		{
+			Type:      cli.FlagArray,
+			Name:      "label",
+			ShortHand: "l",
+			Usage:     "Upgrade only the instances whose workflow has one of the given labels",
+			Default:   "",
+		},
+		{
+			Type:  cli.FlagBool,
+			Name:  "dry-run",
+			Usage: "Display the changes for each instance without applying them",
+		},
+		{
+			Type:  cli.FlagBool,
+			Name:  "track",
+			Usage: "Wait the upgrade to be over",
+		},
+	},
+}
+
+func templateUpgradeRun(v cli.Values) error {
+	wt, err := getTemplateFromCLI(v)
+	if err != nil {
+		return err
+	}
+	if wt == nil {
+		wt, err = suggestTemplate()
+		if err != nil {
+			return err
+		}
+	}
+
+	percentage, err := v.GetInt64("percentage")
+	if err != nil {
+		return err
+	}
+
+	req := sdk.WorkflowTemplateUpgradeRequest{
+		Version:    v.GetString("version"),
+		Percentage: int(percentage),
+		DryRun:     v.GetBool("dry-run"),
+	}
+	for _, l := range v.GetStringArray("label") {
+		if l != "" {
+			req.Labels = append(req.Labels, l)
+		}
+	}
+
+	res, err := client.TemplateUpgrade(wt.Group.Name, wt.Slug, req)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, d := range res.Instances {
+		if d.Skipped != "" {
+			fmt.Printf("%s/%s (%s) -> skipped: %s\n", d.ProjectKey, d.WorkflowName, d.FromVersion, d.Skipped)
+			continue
+		}
+		count++
+		fmt.Printf("%s/%s (%s) -> %s\n", d.ProjectKey, d.WorkflowName, d.FromVersion, cli.Green(d.ToVersion))
+		if req.DryRun {
+			templateDisplayFileDiffs(d.Files)
+		}
+	}
+
+	if req.DryRun {
+		fmt.Printf("%d instance(s) will be upgraded to %s\n", count, res.Version)
+		return nil
+	}
+	if res.Bulk == nil {
+		fmt.Println("No instance to upgrade")
+		return nil
+	}
+
+	fmt.Printf("Bulk request with id %d successfully created to upgrade %d instance(s) of template %s/%s to %s\n",
+		res.Bulk.ID, len(res.Bulk.Operations), wt.Group.Name, wt.Slug, res.Version)
+
+	if v.GetBool("track") {
+		return templateTrackBulk(wt, res.Bulk.ID)
+	}
+
+	return nil
+}
+
+var templateDiffCmd = cli.Command{
+	Name:    "diff",
+	Short:   "Display the changes of a release of a template for a workflow",
+	Example: "cdsctl template diff project-key workflow-name --version 1.2.0",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "version",
+			Usage: "Version of the release to compare with, default is the release that will be applied on the workflow",
+		},
+	},
+}
+
+func templateDiffRun(v cli.Values) error {
+	projectKey := v.GetString(_ProjectKey)
+	workflowName := v.GetString(_WorkflowName)
+
+	wti, err := client.WorkflowTemplateInstanceGet(projectKey, workflowName)
+	if err != nil {
+		return err
+	}
+
+	d, err := client.TemplateInstanceDiff(wti.Template.Group.Name, wti.Template.Slug, wti.ID, v.GetString("version"))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s/%s: %s -> %s\n", projectKey, workflowName, d.FromVersion, d.ToVersion)
+	if len(d.Files) == 0 {
+		fmt.Println("No change")
+		return nil
+	}
+	templateDisplayFileDiffs(d.Files)
+
+	return nil
+}
+
+var templateRollbackCmd = cli.Command{
+	Name:    "rollback",
+	Short:   "Rollback a workflow to the previous release of its template",
+	Example: "cdsctl template rollback project-key workflow-name",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+}
+
+func templateRollbackRun(v cli.Values) error {
+	projectKey := v.GetString(_ProjectKey)
+	workflowName := v.GetString(_WorkflowName)
+
+	wti, err := client.WorkflowTemplateInstanceGet(projectKey, workflowName)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := client.TemplateInstanceRollback(wti.Template.Group.Name, wti.Template.Slug, wti.ID)
+	for _, msg := range msgs {
+		fmt.Println(msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Workflow %s/%s successfully rolled back to release %s\n", projectKey, workflowName, wti.PreviousReleaseVersion)
+
+	return nil
+}
+
+func templateDisplayFileDiffs(files []sdk.WorkflowTemplateFileDiff) {
+	for _, f := range files {
+		for _, line := range strings.SplitAfter(f.Diff, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				fmt.Print(line)
+			case strings.HasPrefix(line, "+"):
+				fmt.Print(cli.Green(line))
+			case strings.HasPrefix(line, "-"):
+				fmt.Print(cli.Red(line))
+			default:
+				fmt.Print(line)
+			}
+		}
+	}
+}
@@ -42,6 +42,7 @@ var toolsYamlSchema = cli.Command{
 
 type yamlSchemaPath struct {
 	Workflow    string
+	WorkflowV2  string
 	Pipeline    string
 	Application string
 	Environment string
@@ -59,8 +60,8 @@ func (y yamlSchemaVSCodeInstaller) Install(schemas yamlSchemaPath) error {
 	fmt.Println("You will need to execute the following command:")
 	fmt.Println(cli.Cyan("code --install-extension %s", pluginVSCodeName))
 
-	// manually constructs a json to preserve rules order
-	paths := []string{schemas.Workflow, schemas.Application, schemas.Environment, schemas.Pipeline}
+	// manually constructs a json to preserve rules order, workflow files are validated with the latest syntax
+	paths := []string{schemas.WorkflowV2, schemas.Application, schemas.Environment, schemas.Pipeline}
 	globPatterns := []string{"*.cds*.yml", "*.cds*.app.yml", "*.cds*.env.yml", "*.cds*.pip.yml"}
 	var schs []string
 	for i := range paths {
@@ -89,6 +90,7 @@ func toolsYamlSchemaRun(v cli.Values) error {
 		reflect.TypeOf(exportentities.PipelineV1{}),
 		reflect.TypeOf(exportentities.Application{}),
 		reflect.TypeOf(exportentities.Environment{}),
+		reflect.TypeOf(exportentities.WorkflowV2{}),
 	}
 
 	home, err := os.UserHomeDir()
@@ -126,5 +128,6 @@ func toolsYamlSchemaRun(v cli.Values) error {
 		Pipeline:    results[1],
 		Application: results[2],
 		Environment: results[3],
+		WorkflowV2:  results[4],
 	})
 }
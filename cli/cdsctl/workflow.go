@@ -25,10 +25,14 @@ func workflow() *cobra.Command {
 		cli.NewGetCommand(workflowStatusCmd, workflowStatusRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowRunManualCmd, workflowRunManualRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowStopCmd, workflowStopRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(workflowApproveCmd, workflowApproveRun, nil, withAllCommandModifiers()...),
+		cli.NewListCommand(workflowPurgeCmd, workflowPurgeRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowExportCmd, workflowExportRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowImportCmd, workflowImportRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowPullCmd, workflowPullRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowPushCmd, workflowPushRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(workflowPlanCmd, workflowPlanRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(workflowLintCmd, workflowLintRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowFavoriteCmd, workflowFavoriteRun, nil, withAllCommandModifiers()...),
 		cli.NewGetCommand(workflowTransformAsCodeCmd, workflowTransformAsCodeRun, nil, withAllCommandModifiers()...),
 		workflowArtifact(),
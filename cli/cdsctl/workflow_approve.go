@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var workflowApproveCmd = cli.Command{
+	Name:  "approve",
+	Short: "Approve a CDS workflow approval node waiting for approval",
+	Long:  "Approve a CDS workflow approval node waiting for approval, the node's children are triggered when enough approvals were given",
+	Example: `cdsctl workflow approve MYPROJECT myworkflow 5 approval # To approve the node approval on workflow run 5
+cdsctl workflow approve MYPROJECT myworkflow 5 approval --comment "checked by QA"
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Args: []cli.Arg{
+		{Name: "run-number"},
+		{Name: "node-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "comment",
+			Usage: "Comment saved with the approval",
+		},
+	},
+}
+
+func workflowApproveRun(v cli.Values) error {
+	runNumber, err := v.GetInt64("run-number")
+	if err != nil {
+		return err
+	}
+
+	wr, err := client.WorkflowRunGet(v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber)
+	if err != nil {
+		return err
+	}
+
+	var nodeRunID int64
+	for _, wnrs := range wr.WorkflowNodeRuns {
+		if wnrs[0].WorkflowNodeName == v.GetString("node-name") {
+			if wnrs[0].Status != sdk.StatusWaitingApproval {
+				return fmt.Errorf("Node %s is not waiting for approval (status: %s)", v.GetString("node-name"), wnrs[0].Status)
+			}
+			nodeRunID = wnrs[0].ID
+			break
+		}
+	}
+	if nodeRunID == 0 {
+		return fmt.Errorf("Node not found")
+	}
+
+	wNodeRun, err := client.WorkflowNodeApprove(v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber, nodeRunID, v.GetString("comment"))
+	if err != nil {
+		return err
+	}
+
+	if wNodeRun.Status == sdk.StatusWaitingApproval {
+		fmt.Printf("Workflow node %s from workflow %s #%d has been approved (%d approval(s)), still waiting for approvals\n", v.GetString("node-name"), v.GetString(_WorkflowName), wNodeRun.Number, len(wNodeRun.Approvals))
+	} else {
+		fmt.Printf("Workflow node %s from workflow %s #%d has been approved\n", v.GetString("node-name"), v.GetString(_WorkflowName), wNodeRun.Number)
+	}
+	return nil
+}
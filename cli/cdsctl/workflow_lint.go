@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	repo "github.com/fsamin/go-repo"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+var workflowLintCmd = cli.Command{
+	Name:  "lint",
+	Short: "Check workflow files without pushing them",
+	Long: `
+Useful when you want to check the YAML files of a workflow and his dependencies (pipelines, applications, environments) before pushing them.
+
+Files are decoded in strict mode into each entity, so unknown fields and wrong types are reported, they are not validated
+against the published JSON schema. Files included by workflows are loaded from the current repository.
+The pipelines, applications, environments and integrations used by the workflow can be checked against a project:
+
+	cdsctl workflow lint .cds/*.yml --project MY-PROJECT
+
+Or against a fixtures file listing the names of the entities of the project, to be used offline:
+
+	cdsctl workflow lint .cds/*.yml --fixtures fixtures.yml
+
+	`,
+	VariadicArgs: cli.Arg{
+		Name: "yaml-file",
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "project",
+			Usage: "Key of the project used to check the references of the workflow",
+		},
+		{
+			Name:  "fixtures",
+			Usage: "Path of a YAML file with the pipelines, applications, environments and integrations used to check the references of the workflow",
+		},
+	},
+}
+
+func workflowLintRun(c cli.Values) error {
+	filesToRead, _, err := workflowFilesToRead(strings.Split(c.GetString("yaml-file"), ","))
+	if err != nil {
+		return err
+	}
+	if len(filesToRead) == 0 {
+		return fmt.Errorf("wrong usage: you should specify your workflow YAML files. See %s workflow lint --help for more details", os.Args[0])
+	}
+
+	refs, err := workflowLintReferences(c.GetString("project"), c.GetString("fixtures"))
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte, len(filesToRead))
+	for _, file := range filesToRead {
+		btes, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		files[file] = btes
+	}
+
+	includes, err := workflowLintIncludes(files)
+	if err != nil {
+		return err
+	}
+
+	msgs := exportentities.Lint(files, includes, refs)
+	for _, msg := range msgs {
+		fmt.Println(msg.String(""))
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%d problem(s) found", len(msgs))
+	}
+
+	fmt.Println("Workflow files are valid")
+	return nil
+}
+
+// workflowLintReferences returns the names of the entities from the given project or fixtures file if any.
+func workflowLintReferences(projectKey, fixtures string) (*exportentities.LintReferences, error) {
+	if fixtures != "" {
+		btes, err := ioutil.ReadFile(fixtures)
+		if err != nil {
+			return nil, err
+		}
+		var refs exportentities.LintReferences
+		if err := yaml.UnmarshalStrict(btes, &refs); err != nil {
+			return nil, fmt.Errorf("invalid fixtures file %s: %v", fixtures, err)
+		}
+		return &refs, nil
+	}
+
+	if projectKey == "" {
+		return nil, nil
+	}
+	if client == nil {
+		return nil, fmt.Errorf("you should be logged in to check the workflow against project %s, see %s login --help", projectKey, os.Args[0])
+	}
+
+	var refs exportentities.LintReferences
+	pips, err := client.PipelineList(projectKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pips {
+		refs.Pipelines = append(refs.Pipelines, p.Name)
+	}
+	apps, err := client.ApplicationList(projectKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range apps {
+		refs.Applications = append(refs.Applications, a.Name)
+	}
+	envs, err := client.EnvironmentList(projectKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range envs {
+		refs.Environments = append(refs.Environments, e.Name)
+	}
+	integrations, err := client.ProjectIntegrationList(projectKey)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range integrations {
+		refs.Integrations = append(refs.Integrations, i.Name)
+	}
+	return &refs, nil
+}
+
+// workflowLintIncludes loads recursively the files included by the given workflow files from the current
+// repository, included files are removed from the given files to not be checked as workflows.
+func workflowLintIncludes(files map[string][]byte) (map[string][]byte, error) {
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	if r, err := repo.New("."); err == nil {
+		f, err := r.Open(".")
+		if err == nil {
+			root = f.Name()
+			f.Close() // nolint
+		}
+	}
+
+	includes := map[string][]byte{}
+	var load func(btes []byte) error
+	load = func(btes []byte) error {
+		is, err := exportentities.ReadWorkflowIncludes(btes)
+		if err != nil {
+			return nil
+		}
+		for _, i := range is {
+			if i.IsLibrary() || i.IsValid() != nil {
+				continue
+			}
+			key := i.Key()
+			if _, ok := includes[key]; ok {
+				continue
+			}
+			btes, err := ioutil.ReadFile(filepath.Join(root, key))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return err
+			}
+			includes[key] = btes
+			if err := load(btes); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, btes := range files {
+		if err := load(btes); err != nil {
+			return nil, err
+		}
+	}
+
+	for file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		for key := range includes {
+			if abs == filepath.Join(root, key) {
+				delete(files, file)
+			}
+		}
+	}
+
+	return includes, nil
+}
@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -17,7 +22,7 @@ var workflowLogCmd = cli.Command{
 	Name:    "logs",
 	Aliases: []string{"log"},
 	Short:   "Manage CDS Workflow Run Logs",
-	Long: `Download logs from a workflow run.
+	Long: `Display, follow or download logs from a workflow run.
 
 	# list all logs files on latest run
 	$ cdsctl workflow logs list KEY WF
@@ -32,11 +37,39 @@ var workflowLogCmd = cli.Command{
 	$ cdsctl workflow logs download KEY WF 1 --pattern="MyJob"
 	# this will download file WF-1.0-pipeline.myPipeline-stage.MyStage-job.MyJob-status.Success-step.0.log
 
+	# print logs of latest run and follow them until the run ends
+	$ cdsctl workflow logs KEY WF --follow
+
 `,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	OptionalArgs: []cli.Arg{
+		{
+			Name: "run-number",
+			IsValid: func(s string) bool {
+				match, _ := regexp.MatchString(`[0-9]?`, s)
+				return match
+			},
+			Weight: 1,
+		},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "pattern",
+			Usage: "Filter on log filename",
+		},
+		{
+			Name:  "follow",
+			Type:  cli.FlagBool,
+			Usage: "Stream logs of running steps until the workflow run ends",
+		},
+	},
 }
 
 func workflowLog() *cobra.Command {
-	return cli.NewCommand(workflowLogCmd, nil, []*cobra.Command{
+	return cli.NewCommand(workflowLogCmd, workflowLogRun, []*cobra.Command{
 		cli.NewCommand(workflowLogListCmd, workflowLogListRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(workflowLogDownloadCmd, workflowLogDownloadRun, nil, withAllCommandModifiers()...),
 	})
@@ -153,7 +186,7 @@ func workflowLogProcess(wr *sdk.WorkflowRun) []workflowLogDetail {
 								workflowName: wr.Workflow.Name,
 								pipelineName: node.WorkflowNodeName,
 								stageName:    stage.Name,
-								jobName:      job.Job.Job.Action.Name,
+								jobName:      workflowJobRunName(job),
 								jobID:        job.ID,
 								status:       job.Status,
 								stepOrder:    step.StepOrder,
@@ -169,10 +202,18 @@ func workflowLogProcess(wr *sdk.WorkflowRun) []workflowLogDetail {
 	return logs
 }
 
+// workflowJobRunName returns the name of the job, followed by its matrix combination if any (ex: Test[go=1.13])
+func workflowJobRunName(job sdk.WorkflowNodeJobRun) string {
+	if len(job.Matrix) == 0 {
+		return job.Job.Job.Action.Name
+	}
+	return fmt.Sprintf("%s[%s]", job.Job.Job.Action.Name, strings.Replace(sdk.MatrixCombinationString(job.Matrix), ", ", ",", -1))
+}
+
 var workflowLogDownloadCmd = cli.Command{
 	Name:  "download",
 	Short: "Download logs from a workflow run.",
-	Long: `Download logs from a workflow run. You can download all logs files or just one log if you want.
+	Long: `Display, follow or download logs from a workflow run. You can download all logs files or just one log if you want.
 
 	# download all logs files on latest run
 	$ cdsctl workflow logs download KEY WF
@@ -260,3 +301,125 @@ func workflowLogDownloadRun(v cli.Values) error {
 	}
 	return nil
 }
+
+// workflowLogRun prints step logs of a workflow run on stdout. With --follow, the logs of running steps are streamed
+// until the run ends, the stream is resumed from the last received offset if the connection is closed. New jobs are
+// looked for when an event of the run is received.
+func workflowLogRun(v cli.Values) error {
+	runNumber, err := workflowLogSearchNumber(v)
+	if err != nil {
+		return err
+	}
+
+	var reg *regexp.Regexp
+	if v.GetString("pattern") != "" {
+		reg, err = regexp.Compile(v.GetString("pattern"))
+		if err != nil {
+			return fmt.Errorf("Invalid pattern %s: %v", v.GetString("pattern"), err)
+		}
+	}
+	follow := v.GetBool("follow")
+
+	var runEvents <-chan struct{}
+	if follow {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		runEvents = workflowLogListenRunEvents(ctx, v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber)
+	}
+
+	offsets := map[string]int64{}
+	done := map[string]bool{}
+	for {
+		wr, err := client.WorkflowRunGet(v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber)
+		if err != nil {
+			return err
+		}
+
+		for _, log := range workflowLogProcess(wr) {
+			// the filename contains the job status, use a key that does not change while the job is running
+			key := fmt.Sprintf("%d-%d-%d", log.runID, log.jobID, log.stepOrder)
+			if done[key] || (reg != nil && !reg.MatchString(log.getFilename())) {
+				continue
+			}
+
+			if _, ok := offsets[key]; !ok {
+				fmt.Printf("==> %s <==\n", log.getFilename())
+			}
+			for {
+				n, err := workflowLogStreamStep(v, runNumber, log, offsets[key], follow)
+				if err != nil {
+					return err
+				}
+				offsets[key] += n
+
+				buildState, err := client.WorkflowNodeRunJobStep(v.GetString(_ProjectKey), v.GetString(_WorkflowName),
+					runNumber, log.runID, log.jobID, log.stepOrder)
+				if err != nil {
+					return err
+				}
+				if !follow || sdk.StatusIsTerminated(buildState.Status) {
+					break
+				}
+			}
+			done[key] = true
+		}
+
+		if !follow || sdk.StatusIsTerminated(wr.Status) {
+			return nil
+		}
+		// wait for the next jobs to start, the run is reloaded anyway after a while in case an event was missed
+		select {
+		case <-runEvents:
+		case <-time.After(time.Minute):
+		}
+	}
+}
+
+// workflowLogListenRunEvents returns a channel notified when an event of given workflow run is received.
+func workflowLogListenRunEvents(ctx context.Context, projectKey, workflowName string, runNumber int64) <-chan struct{} {
+	chanSSE := make(chan cdsclient.SSEvent)
+	sdk.GoRoutine(ctx, "workflowLogListenRunEvents", func(ctx context.Context) {
+		client.EventsListen(ctx, chanSSE)
+	})
+
+	notify := make(chan struct{}, 1)
+	sdk.GoRoutine(ctx, "workflowLogNotifyRunEvents", func(ctx context.Context) {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case evt := <-chanSSE:
+				var e sdk.Event
+				content, _ := ioutil.ReadAll(evt.Data)
+				if err := json.Unmarshal(content, &e); err != nil {
+					continue
+				}
+				if !strings.HasPrefix(e.EventType, "sdk.EventRunWorkflow") || e.ProjectKey != projectKey ||
+					e.WorkflowName != workflowName || e.WorkflowRunNum != runNumber {
+					continue
+				}
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			}
+		}
+	})
+	return notify
+}
+
+func workflowLogStreamStep(v cli.Values, runNumber int64, log workflowLogDetail, offset int64, follow bool) (int64, error) {
+	reader, err := client.WorkflowNodeRunJobStepLogs(context.Background(), v.GetString(_ProjectKey), v.GetString(_WorkflowName),
+		runNumber, log.runID, log.jobID, log.stepOrder, offset)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close() // nolint
+
+	n, err := io.Copy(os.Stdout, reader)
+	if err != nil && follow {
+		// the stream was interrupted, it will be resumed from the new offset
+		return n, nil
+	}
+	return n, err
+}
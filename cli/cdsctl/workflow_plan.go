@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ovh/cds/cli"
+)
+
+var workflowPlanCmd = cli.Command{
+	Name:  "plan",
+	Short: "Display the changes that a push of a workflow would apply",
+	Long: `
+Useful when you want to check the changes of a workflow and his dependencies (pipelines, applications, environments) before pushing them.
+
+The files are parsed and imported by CDS like with the push command but nothing is saved, the differences with the current workflow are displayed:
+
+	cdsctl workflow plan tests.pip.yml build.pip.yml myWorkflow.yml
+
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	VariadicArgs: cli.Arg{
+		Name: "yaml-file",
+	},
+}
+
+func workflowPlanRun(c cli.Values) error {
+	filesToRead, _, err := workflowFilesToRead(strings.Split(c.GetString("yaml-file"), ","))
+	if err != nil {
+		return err
+	}
+	if len(filesToRead) == 0 {
+		return fmt.Errorf("wrong usage: you should specify your workflow YAML files. See %s workflow plan --help for more details", os.Args[0])
+	}
+
+	buf := new(bytes.Buffer)
+	if err := workflowFilesToTarWriter(filesToRead, buf); err != nil {
+		return err
+	}
+
+	plan, err := client.WorkflowPlan(c.GetString(_ProjectKey), buf)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range plan.Messages {
+		fmt.Println(msg)
+	}
+
+	if len(plan.Files) == 0 {
+		fmt.Println("No change")
+		return nil
+	}
+	templateDisplayFileDiffs(plan.Files)
+	fmt.Printf("%d file(s) will be changed by the push\n", len(plan.Files))
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"github.com/ovh/cds/cli"
+)
+
+var workflowPurgeCmd = cli.Command{
+	Name:  "purge",
+	Short: "Purge CDS workflow runs according to the workflow retention policy",
+	Long:  "Mark to delete the workflow runs that are not kept by the workflow retention policy, with --dry-run the runs and their artifacts are only listed",
+	Example: `cdsctl workflow purge MYPROJECT myworkflow --dry-run # To list the runs that would be deleted
+cdsctl workflow purge MYPROJECT myworkflow`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "dry-run",
+			Type:  cli.FlagBool,
+			Usage: "Only list the workflow runs that would be deleted",
+		},
+	},
+}
+
+func workflowPurgeRun(v cli.Values) (cli.ListResult, error) {
+	runs, err := client.WorkflowPurge(v.GetString(_ProjectKey), v.GetString(_WorkflowName), v.GetBool("dry-run"))
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(runs), nil
+}
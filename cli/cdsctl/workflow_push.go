@@ -40,42 +40,16 @@ For example if you have a workflow with pipelines build and tests you can push y
 }
 
 func workflowPushRun(c cli.Values) error {
-	// Get the file
-	files := strings.Split(c.GetString("yaml-file"), ",")
-
-	// Create a buffer to write our archive to.
-	buf := new(bytes.Buffer)
-	var dir string
-
-	// Create a new tar archive.
-	filesToRead := []string{}
-	for _, file := range files {
-		fi, err := os.Lstat(file)
-		if err != nil {
-			fmt.Printf("Skipping file %s: %v\n", file, err)
-			continue
-		}
-
-		//Skip the directory
-		if fi.IsDir() {
-			continue
-		}
-
-		fmt.Println("Reading file ", cli.Magenta(file))
-		if dir == "" {
-			dir = filepath.Dir(file)
-		}
-		if dir != filepath.Dir(file) {
-			return fmt.Errorf("files must be ine the same directory")
-		}
-
-		filesToRead = append(filesToRead, file)
+	filesToRead, dir, err := workflowFilesToRead(strings.Split(c.GetString("yaml-file"), ","))
+	if err != nil {
+		return err
 	}
-
 	if len(filesToRead) == 0 {
 		return fmt.Errorf("wrong usage: you should specify your workflow YAML files. See %s workflow push --help for more details", os.Args[0])
 	}
 
+	// Create a buffer to write our archive to.
+	buf := new(bytes.Buffer)
 	if err := workflowFilesToTarWriter(filesToRead, buf); err != nil {
 		return err
 	}
@@ -103,6 +77,35 @@ func workflowPushRun(c cli.Values) error {
 	return workflowTarReaderToFiles(c, dir, tr)
 }
 
+// workflowFilesToRead returns the given files that are not directories and their common directory.
+func workflowFilesToRead(files []string) ([]string, string, error) {
+	var dir string
+	filesToRead := []string{}
+	for _, file := range files {
+		fi, err := os.Lstat(file)
+		if err != nil {
+			fmt.Printf("Skipping file %s: %v\n", file, err)
+			continue
+		}
+
+		//Skip the directory
+		if fi.IsDir() {
+			continue
+		}
+
+		fmt.Println("Reading file ", cli.Magenta(file))
+		if dir == "" {
+			dir = filepath.Dir(file)
+		}
+		if dir != filepath.Dir(file) {
+			return nil, "", fmt.Errorf("files must be ine the same directory")
+		}
+
+		filesToRead = append(filesToRead, file)
+	}
+	return filesToRead, dir, nil
+}
+
 func workflowFilesToTarWriter(files []string, buf io.Writer) error {
 	tw := tar.NewWriter(buf)
 
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
 )
 
 var workflowHistoryCmd = cli.Command{
 	Name:  "history",
 	Short: "Display CDS workflow runs history",
+	Long: `Display CDS workflow runs history, runs can be filtered with flags.
+
+	# runs that deployed a commit last month
+	$ cdsctl workflow history KEY WF --commit=abc123 --from=2020-01-01 --to=2020-02-01
+
+	# failed runs of a branch that lasted more than 10 minutes
+	$ cdsctl workflow history KEY WF --branch=master --status=Fail --min-duration=10m
+
+	# runs started by a scheduler with given text in their step logs
+	$ cdsctl workflow history KEY WF --hook=Scheduler --text="connection refused"
+`,
 	Ctx: []cli.Arg{
 		{Name: _ProjectKey},
 		{Name: _WorkflowName},
@@ -31,6 +46,19 @@ var workflowHistoryCmd = cli.Command{
 			Weight: 2,
 		},
 	},
+	Flags: []cli.Flag{
+		{Name: "commit", Usage: "Filter on commit hash (or hash prefix)"},
+		{Name: "author", Usage: "Filter on commit author"},
+		{Name: "branch", Usage: "Filter on git branch"},
+		{Name: "tag", Usage: "Filter on git tag"},
+		{Name: "status", Usage: "Filter on run status, multiple status can be separated by commas"},
+		{Name: "min-duration", Usage: "Filter on minimum run duration (ex: 10m)"},
+		{Name: "max-duration", Usage: "Filter on maximum run duration (ex: 1h)"},
+		{Name: "from", Usage: "Filter on runs started after given date (YYYY-MM-DD)"},
+		{Name: "to", Usage: "Filter on runs started before given date (YYYY-MM-DD)"},
+		{Name: "hook", Usage: "Filter on type of triggering hook (ex: RepositoryWebHook, Scheduler) or Manual"},
+		{Name: "text", Usage: "Filter on text in step logs"},
+	},
 }
 
 func workflowHistoryRun(v cli.Values) (cli.ListResult, error) {
@@ -52,9 +80,58 @@ func workflowHistoryRun(v cli.Values) (cli.ListResult, error) {
 		}
 	}
 
+	filter, ok, err := workflowHistoryFilter(v)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		filter.Offset, filter.Limit = int(offset), int(limit)
+		w, err := client.WorkflowRunsSearch(v.GetString(_ProjectKey), v.GetString(_WorkflowName), filter)
+		if err != nil {
+			return nil, err
+		}
+		return cli.AsListResult(w), nil
+	}
+
 	w, err := client.WorkflowRunList(v.GetString(_ProjectKey), v.GetString(_WorkflowName), offset, limit)
 	if err != nil {
 		return nil, err
 	}
 	return cli.AsListResult(w), nil
 }
+
+// workflowHistoryFilter returns the search filter given by flags, and false if no filter was given.
+func workflowHistoryFilter(v cli.Values) (sdk.WorkflowRunSearchFilter, bool, error) {
+	f := sdk.WorkflowRunSearchFilter{
+		Commit:   v.GetString("commit"),
+		Author:   v.GetString("author"),
+		Branch:   v.GetString("branch"),
+		Tag:      v.GetString("tag"),
+		HookType: v.GetString("hook"),
+		Text:     v.GetString("text"),
+	}
+	if s := v.GetString("status"); s != "" {
+		f.Status = strings.Split(s, ",")
+	}
+
+	for name, d := range map[string]*int64{"min-duration": &f.MinDuration, "max-duration": &f.MaxDuration} {
+		if s := v.GetString(name); s != "" {
+			duration, err := time.ParseDuration(s)
+			if err != nil {
+				return f, false, fmt.Errorf("invalid %s %s: %v", name, s, err)
+			}
+			*d = int64(duration.Seconds())
+		}
+	}
+	for name, d := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if s := v.GetString(name); s != "" {
+			date, err := time.ParseInLocation("2006-01-02", s, time.Local)
+			if err != nil {
+				return f, false, fmt.Errorf("invalid %s date %s, expected format is YYYY-MM-DD", name, s)
+			}
+			*d = date
+		}
+	}
+
+	return f, len(f.QueryParams()) > 0, nil
+}
@@ -172,6 +172,8 @@ func workflowStatusRunWithoutTrack(v cli.Values) (interface{}, error) {
 		sdk.WorkflowRun
 		Payload string `cli:"payload"`
 		Tags    string `cli:"tags"`
+		Retries string `cli:"retries"`
+		Matrix  string `cli:"matrix"`
 	}
 
 	var payload []string
@@ -197,6 +199,77 @@ func workflowStatusRunWithoutTrack(v cli.Values) (interface{}, error) {
 		}
 		payload = append(payload)
 	}
-	wt := &wtags{*run, strings.Join(payload, " "), strings.Join(tags, " ")}
+	wt := &wtags{*run, strings.Join(payload, " "), strings.Join(tags, " "), strings.Join(workflowRunRetries(run), " "),
+		strings.Join(workflowRunMatrixJobs(run), " ")}
 	return *wt, nil
 }
+
+// workflowRunLatestNodeRuns returns the latest sub run of each node run
+func workflowRunLatestNodeRuns(run *sdk.WorkflowRun) []sdk.WorkflowNodeRun {
+	var res []sdk.WorkflowNodeRun
+	for _, nodeRuns := range run.WorkflowNodeRuns {
+		if len(nodeRuns) == 0 {
+			continue
+		}
+		nodeRun := nodeRuns[0]
+		for _, nr := range nodeRuns {
+			if nr.SubNumber > nodeRun.SubNumber {
+				nodeRun = nr
+			}
+		}
+		res = append(res, nodeRun)
+	}
+	return res
+}
+
+// workflowRunMatrixJobs returns the statuses of matrix job runs grouped by job (ex: build/Test[Success:4,Fail:2])
+func workflowRunMatrixJobs(run *sdk.WorkflowRun) []string {
+	var res []string
+	for _, nodeRun := range workflowRunLatestNodeRuns(run) {
+		for _, s := range nodeRun.Stages {
+			var jobIDs []int64
+			statuses := map[int64]map[string]int{}
+			names := map[int64]string{}
+			for _, rj := range s.RunJobs {
+				if len(rj.Matrix) == 0 {
+					continue
+				}
+				id := rj.Job.PipelineActionID
+				if _, ok := statuses[id]; !ok {
+					jobIDs = append(jobIDs, id)
+					statuses[id] = map[string]int{}
+					names[id] = rj.Job.Action.Name
+				}
+				statuses[id][rj.Status]++
+			}
+			for _, id := range jobIDs {
+				var counts []string
+				for status, n := range statuses[id] {
+					counts = append(counts, fmt.Sprintf("%s:%d", status, n))
+				}
+				sort.Strings(counts)
+				res = append(res, fmt.Sprintf("%s/%s[%s]", nodeRun.WorkflowNodeName, names[id], strings.Join(counts, ",")))
+			}
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// workflowRunRetries returns the attempts of retried jobs for the latest node runs (ex: build/Compile:2/3)
+func workflowRunRetries(run *sdk.WorkflowRun) []string {
+	var retries []string
+	for _, nodeRun := range workflowRunLatestNodeRuns(run) {
+		for _, s := range nodeRun.Stages {
+			for _, rj := range s.RunJobs {
+				if len(rj.Attempts) == 0 {
+					continue
+				}
+				retries = append(retries, fmt.Sprintf("%s/%s:%d/%d", nodeRun.WorkflowNodeName, workflowJobRunName(rj),
+					len(rj.Attempts)+1, rj.Job.Action.RetryPolicy.MaxAttempts))
+			}
+		}
+	}
+	sort.Strings(retries)
+	return retries
+}
@@ -145,8 +145,10 @@ type Configuration struct {
 		From     string `toml:"from" default:"no-reply@cds.local" json:"from"`
 	} `toml:"smtp" comment:"#####################\n# CDS SMTP Settings \n####################" json:"smtp"`
 	Artifact struct {
-		Mode  string `toml:"mode" default:"local" comment:"swift, awss3 or local" json:"mode"`
-		Local struct {
+		Mode             string `toml:"mode" default:"local" comment:"swift, awss3 or local" json:"mode"`
+		ContentAddressed bool   `toml:"contentAddressed" default:"false" comment:"Store artifacts by their SHA-256, identical artifacts are stored only once and deleted with their last reference" json:"contentAddressed"`
+		CacheMaxSize     int64  `toml:"cacheMaxSize" default:"10240" comment:"Max size in MB of the caches of a project on a storage integration, the least recently used caches are deleted above. 0 to disable" json:"cacheMaxSize"`
+		Local            struct {
 			BaseDirectory string `toml:"baseDirectory" default:"/var/lib/cds-engine/artifacts" json:"baseDirectory"`
 		} `toml:"local"`
 		Openstack struct {
@@ -191,8 +193,9 @@ type Configuration struct {
 		URL         string `toml:"url" comment:"Example: http://localhost:9000" json:"url"`
 	} `toml:"graylog" json:"graylog" comment:"###########################\n Graylog Search. \n When CDS API generates errors, you can fetch them with cdsctl. \n Examples: \n $ cdsctl admin errors get <error-id> \n $ cdsctl admin errors get 55f6e977-d39b-11e8-8513-0242ac110007 \n##########################"`
 	Log struct {
-		StepMaxSize    int64 `toml:"stepMaxSize" default:"15728640" comment:"Max step logs size in bytes (default: 15MB)" json:"stepMaxSize"`
-		ServiceMaxSize int64 `toml:"serviceMaxSize" default:"15728640" comment:"Max service logs size in bytes (default: 15MB)" json:"serviceMaxSize"`
+		StepMaxSize    int64  `toml:"stepMaxSize" default:"15728640" comment:"Max step logs size in bytes (default: 15MB)" json:"stepMaxSize"`
+		ServiceMaxSize int64  `toml:"serviceMaxSize" default:"15728640" comment:"Max service logs size in bytes (default: 15MB)" json:"serviceMaxSize"`
+		Store          string `toml:"store" default:"database" comment:"Step logs storage: database or objectstore. With objectstore, logs are stored in the artifacts storage and the logs in database are migrated" json:"store"`
 	} `toml:"log" json:"log" comment:"###########################\n Log settings.\n##########################"`
 }
 
@@ -272,6 +275,10 @@ type API struct {
 		WorkflowRunsMarkToDelete *stats.Int64Measure
 		WorkflowRunsDeleted      *stats.Int64Measure
 		DatabaseConns            *stats.Int64Measure
+		DeploymentFrequency      *stats.Float64Measure
+		LeadTime                 *stats.Int64Measure
+		ChangeFailureRate        *stats.Float64Measure
+		MTTR                     *stats.Int64Measure
 	}
 	AuthenticationDrivers map[sdk.AuthConsumerType]sdk.AuthDriver
 }
@@ -333,6 +340,12 @@ func (a *API) CheckConfiguration(config interface{}) error {
 		return fmt.Errorf("Invalid artifact mode")
 	}
 
+	switch aConfig.Log.Store {
+	case "", "database", "objectstore":
+	default:
+		return fmt.Errorf("Invalid log store")
+	}
+
 	if aConfig.Artifact.Mode == "local" {
 		if aConfig.Artifact.Local.BaseDirectory == "" {
 			return fmt.Errorf("Invalid artifact local base directory (empty name)")
@@ -788,6 +801,22 @@ func (a *API) Serve(ctx context.Context) error {
 		func(ctx context.Context) {
 			metrics.Init(ctx, a.DBConnectionFactory.GetDBMap)
 		}, a.PanicDump())
+	if a.Config.Log.Store == "objectstore" {
+		logStore := workflow.NewObjectStoreLogStore(a.SharedStorage)
+		workflow.SetLogStore(logStore)
+		sdk.GoRoutine(ctx, "workflow.MigrateDatabaseLogs",
+			func(ctx context.Context) {
+				logStore.MigrateDatabaseLogs(ctx, a.DBConnectionFactory.GetDBMap)
+			}, a.PanicDump())
+	}
+	sdk.GoRoutine(ctx, "api.startQueuedWorkflowRuns",
+		func(ctx context.Context) {
+			a.startQueuedWorkflowRuns(ctx)
+		}, a.PanicDump())
+	sdk.GoRoutine(ctx, "api.releaseStaleProjectLocks",
+		func(ctx context.Context) {
+			a.releaseStaleProjectLocks(ctx)
+		}, a.PanicDump())
 	sdk.GoRoutine(ctx, "Purge",
 		func(ctx context.Context) {
 			purge.Initialize(ctx, a.Cache, a.DBConnectionFactory.GetDBMap, a.SharedStorage, a.Metrics.WorkflowRunsMarkToDelete, a.Metrics.WorkflowRunsDeleted)
@@ -144,6 +144,14 @@ func (a *API) isWorker(ctx context.Context) (*sdk.Worker, bool) {
 	return w, true
 }
 
+func (a *API) isHooks(ctx context.Context) (*sdk.Service, bool) {
+	s, ok := a.isService(ctx)
+	if !ok || s.Type != services.TypeHooks {
+		return nil, false
+	}
+	return s, true
+}
+
 func (a *API) isHatchery(ctx context.Context) (*sdk.Service, bool) {
 	db := a.mustDBWithCtx(ctx)
 	session := getAuthSession(ctx)
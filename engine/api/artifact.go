@@ -20,9 +20,10 @@ func (api *API) getArtifactsStoreHandler() service.Handler {
 		if err != nil {
 			return sdk.WrapError(err, "Cannot init storage driver")
 		}
+		// Artifacts stored by content have to be uploaded through the API to be deduplicated
 		s := sdk.ArtifactsStore{
 			Name:                  storageDriver.GetProjectIntegration().Name,
-			TemporaryURLSupported: storageDriver.TemporaryURLSupported(),
+			TemporaryURLSupported: storageDriver.TemporaryURLSupported() && !api.Config.Artifact.ContentAddressed,
 		}
 		return service.WriteJSON(w, s, http.StatusOK)
 	}
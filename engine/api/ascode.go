@@ -111,6 +111,7 @@ func (api *API) postPerformImportAsCodeHandler() service.Handler {
 			project.LoadOptions.WithPipelines,
 			project.LoadOptions.WithFeatures,
 			project.LoadOptions.WithClearIntegrations,
+			project.LoadOptions.WithClearKeys,
 		)
 		if errp != nil {
 			return sdk.WrapError(errp, "postPerformImportAsCodeHandler> Cannot load project %s", key)
@@ -127,6 +128,10 @@ func (api *API) postPerformImportAsCodeHandler() service.Handler {
 			return sdk.ErrMethodNotAllowed
 		}
 
+		if err := workflow.ResolveIncludes(ctx, api.mustDB(), api.Cache, proj, ope); err != nil {
+			return err
+		}
+
 		tr, err := workflow.ReadCDSFiles(ope.LoadFiles.Results)
 		if err != nil {
 			return sdk.WrapError(err, "Unable to read cds files")
@@ -8,10 +8,85 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
 	"github.com/ovh/cds/engine/service"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
 )
 
+// cacheSizeReader counts the bytes read from a cache content.
+type cacheSizeReader struct {
+	io.ReadCloser
+	size int64
+}
+
+func (r *cacheSizeReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// evictCaches deletes the least recently used caches of a project on a storage integration
+// when their total size exceeds the configured max size.
+func (api *API) evictCaches(ctx context.Context, proj *sdk.Project, storageDriver objectstore.Driver, integrationName string) {
+	if api.Config.Artifact.CacheMaxSize <= 0 {
+		return
+	}
+	entries, err := workflow.LoadCacheEntriesToEvict(api.mustDB(), proj.ID, integrationName, api.Config.Artifact.CacheMaxSize*1024*1024)
+	if err != nil {
+		log.Error(ctx, "evictCaches> unable to load caches to evict for project %s: %v", proj.Key, err)
+		return
+	}
+	for _, e := range entries {
+		cacheObject := sdk.Cache{
+			Name:    "cache.tar",
+			Project: proj.Key,
+			Tag:     e.Tag,
+		}
+		log.Info(ctx, "evictCaches> deleting cache %s of project %s on %s (%d bytes)", e.Tag, proj.Key, integrationName, e.Size)
+		if err := storageDriver.Delete(ctx, &cacheObject); err != nil {
+			log.Error(ctx, "evictCaches> unable to delete cache %s of project %s: %v", e.Tag, proj.Key, err)
+			continue
+		}
+		if err := storageDriver.DeleteContainer(ctx, cacheObject.GetPath()); err != nil {
+			log.Error(ctx, "evictCaches> unable to delete container %s: %v", cacheObject.GetPath(), err)
+		}
+		if err := workflow.DeleteCacheEntry(api.mustDB(), e.ID); err != nil {
+			log.Error(ctx, "evictCaches> %v", err)
+		}
+	}
+}
+
+func (api *API) getCacheEntryHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, isWorker := api.isWorker(ctx); !isWorker {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		vars := mux.Vars(r)
+		if err := r.ParseForm(); err != nil {
+			return sdk.WithStack(sdk.ErrWrongRequest)
+		}
+		tags := r.Form["tag"]
+		if len(tags) == 0 {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "missing tag")
+		}
+
+		proj, err := project.Load(api.mustDB(), api.Cache, vars[permProjectKey])
+		if err != nil {
+			return err
+		}
+
+		entry, err := workflow.ResolveCacheEntry(api.mustDB(), proj.ID, vars["integrationName"], tags)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, entry, http.StatusOK)
+	}
+}
+
 func (api *API) postPushCacheHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		if _, isWorker := api.isWorker(ctx); !isWorker {
@@ -38,15 +113,26 @@ func (api *API) postPushCacheHandler() service.Handler {
 			Tag:     tag,
 		}
 
+		proj, err := project.Load(api.mustDB(), api.Cache, vars[permProjectKey])
+		if err != nil {
+			return err
+		}
+
 		storageDriver, err := objectstore.GetDriver(ctx, api.mustDB(), api.SharedStorage, vars[permProjectKey], vars["integrationName"])
 		if err != nil {
 			return err
 		}
 
-		if _, err := storageDriver.Store(&cacheObject, r.Body); err != nil {
+		body := &cacheSizeReader{ReadCloser: r.Body}
+		if _, err := storageDriver.Store(&cacheObject, body); err != nil {
 			return sdk.WrapError(err, "postPushCacheHandler>Cannot store cache")
 		}
 
+		if err := workflow.UpsertCacheEntry(api.mustDB(), proj.ID, vars["integrationName"], tag, body.size); err != nil {
+			return err
+		}
+		api.evictCaches(ctx, proj, storageDriver, vars["integrationName"])
+
 		return nil
 	}
 }
@@ -72,11 +158,20 @@ func (api *API) getPullCacheHandler() service.Handler {
 			Tag:     tag,
 		}
 
+		proj, err := project.Load(api.mustDB(), api.Cache, vars[permProjectKey])
+		if err != nil {
+			return err
+		}
+
 		storageDriver, err := objectstore.GetDriver(ctx, api.mustDB(), api.SharedStorage, vars[permProjectKey], vars["integrationName"])
 		if err != nil {
 			return err
 		}
 
+		if err := workflow.UpdateCacheEntryLastAccess(api.mustDB(), proj.ID, vars["integrationName"], tag); err != nil {
+			return err
+		}
+
 		s, temporaryURLSupported := storageDriver.(objectstore.DriverWithRedirect)
 		if storageDriver.TemporaryURLSupported() && temporaryURLSupported { // with temp URL
 			fURL, _, err := s.FetchURL(&cacheObject)
@@ -147,6 +242,49 @@ func (api *API) postPushCacheWithTempURLHandler() service.Handler {
 	}
 }
 
+// postPushCacheWithTempURLCallbackHandler records the cache entry once the worker uploaded the cache to the temporary URL.
+func (api *API) postPushCacheWithTempURLCallbackHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, isWorker := api.isWorker(ctx); !isWorker {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		vars := mux.Vars(r)
+		tag := vars["tag"]
+
+		// check tag name pattern
+		regexp := sdk.NamePatternRegex
+		if !regexp.MatchString(tag) {
+			return sdk.WithStack(sdk.ErrInvalidName)
+		}
+
+		var c sdk.Cache
+		if err := service.UnmarshalBody(r, &c); err != nil {
+			return err
+		}
+
+		storageDriver, err := objectstore.GetDriver(ctx, api.mustDB(), api.SharedStorage, vars[permProjectKey], vars["integrationName"])
+		if err != nil {
+			return err
+		}
+		if !storageDriver.TemporaryURLSupported() {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		proj, err := project.Load(api.mustDB(), api.Cache, vars[permProjectKey])
+		if err != nil {
+			return err
+		}
+
+		if err := workflow.UpsertCacheEntry(api.mustDB(), proj.ID, vars["integrationName"], tag, c.Size); err != nil {
+			return err
+		}
+		api.evictCaches(ctx, proj, storageDriver, vars["integrationName"])
+
+		return nil
+	}
+}
+
 func (api *API) getPullCacheWithTempURLHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		if _, isWorker := api.isWorker(ctx); !isWorker {
@@ -178,6 +316,14 @@ func (api *API) getPullCacheWithTempURLHandler() service.Handler {
 			Tag:     tag,
 		}
 
+		proj, err := project.Load(api.mustDB(), api.Cache, vars[permProjectKey])
+		if err != nil {
+			return err
+		}
+		if err := workflow.UpdateCacheEntryLastAccess(api.mustDB(), proj.ID, vars["integrationName"], tag); err != nil {
+			return err
+		}
+
 		url, key, err := store.FetchURL(&cacheObject)
 		if err != nil {
 			return sdk.WrapError(err, "cannot get tmp URL")
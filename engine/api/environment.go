@@ -222,6 +222,7 @@ func (api *API) updateEnvironmentHandler() service.Handler {
 
 		oldEnv := env
 		env.Name = envPost.Name
+		env.Lock = envPost.Lock
 
 		tx, errBegin := api.mustDB().Begin()
 		if errBegin != nil {
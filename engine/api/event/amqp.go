@@ -0,0 +1,101 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Azure/go-amqp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// AMQPClient embeddes the AMQP 1.0 connection, events are sent to the target address of the sender link
+type AMQPClient struct {
+	options AMQPConfig
+	client  *amqp.Client
+	session *amqp.Session
+	sender  *amqp.Sender
+}
+
+// AMQPConfig handles all config to connect to an AMQP 1.0 broker
+type AMQPConfig struct {
+	Enabled  bool
+	URL      string
+	User     string
+	Password string
+	Address  string
+}
+
+// initialize returns broker, isInit and err if
+func (c *AMQPClient) initialize(ctx context.Context, options interface{}) (Broker, error) {
+	conf, ok := options.(AMQPConfig)
+	if !ok {
+		return nil, fmt.Errorf("Invalid AMQP Initialization")
+	}
+
+	if conf.URL == "" || conf.Address == "" {
+		return nil, fmt.Errorf("initAMQP> Invalid AMQP Configuration")
+	}
+	c.options = conf
+
+	opts := []amqp.ConnOption{amqp.ConnContainerID("cds-api-" + cdsname)}
+	if conf.User != "" {
+		opts = append(opts, amqp.ConnSASLPlain(conf.User, conf.Password))
+	}
+	client, err := amqp.Dial(conf.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("initAMQP> Error while connecting to %s user:%s: %v", conf.URL, conf.User, err)
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close() // nolint
+		return nil, fmt.Errorf("initAMQP> Error while creating session on %s: %v", conf.URL, err)
+	}
+	sender, err := session.NewSender(amqp.LinkTargetAddress(conf.Address))
+	if err != nil {
+		client.Close() // nolint
+		return nil, fmt.Errorf("initAMQP> Error while creating sender on %s: %v", conf.Address, err)
+	}
+	c.client = client
+	c.session = session
+	c.sender = sender
+
+	log.Debug("initAMQP> AMQP used at %s on address:%s", conf.URL, conf.Address)
+	return c, nil
+}
+
+// close closes the sender link and the connection
+func (c *AMQPClient) close(ctx context.Context) {
+	if c.sender != nil {
+		if err := c.sender.Close(ctx); err != nil {
+			log.Warning(ctx, "closeAMQP> Error while closing amqp sender:%s", err.Error())
+		}
+	}
+	if c.client != nil {
+		if err := c.client.Close(); err != nil {
+			log.Warning(ctx, "closeAMQP> Error while closing amqp connection:%s", err.Error())
+		}
+	}
+}
+
+// sendEvent sends an event to the address and waits for the disposition of the broker
+func (c *AMQPClient) sendEvent(event *sdk.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return c.sender.Send(ctx, amqp.NewMessage(data))
+}
+
+// status returns the status of the sender
+func (c *AMQPClient) status() string {
+	if c.sender == nil {
+		return "AMQP KO"
+	}
+	return "AMQP OK"
+}
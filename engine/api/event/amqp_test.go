@@ -0,0 +1,92 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestAMQPBrokerConfig(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "amqp://" + l.Addr().String()
+	require.NoError(t, l.Close())
+
+	_, err = getBrokerFromIntegration(context.TODO(), sdk.AMQPIntegrationModel, sdk.IntegrationConfig{
+		"url": {Value: url},
+	})
+	assert.Error(t, err, "address is mandatory")
+
+	_, err = getBrokerFromIntegration(context.TODO(), sdk.AMQPIntegrationModel, sdk.IntegrationConfig{
+		"url":     {Value: url},
+		"address": {Value: "cds.events"},
+	})
+	assert.Error(t, err, "broker is not reachable")
+
+	assert.Equal(t, "AMQP KO", (&AMQPClient{}).status())
+}
+
+/*
+  To run the AMQP 1.0 broker test, put the configuration of a broker in the $HOME/.cds/tests.cfg.json file:
+	"amqpURL": "amqp://localhost:5672",
+	"amqpUsername": "",
+	"amqpPassword": "",
+	"amqpAddress": ""
+
+  If amqpURL is not set, the test is skipped
+*/
+
+func TestAMQPBroker(t *testing.T) {
+	cfg := test.LoadTestingConf(t)
+	if cfg["amqpURL"] == "" {
+		t.SkipNow()
+	}
+	address := cfg["amqpAddress"]
+	if address == "" {
+		address = "cds.tests." + sdk.RandomString(10)
+	}
+
+	// Receive on the address before sending the event
+	opts := []amqp.ConnOption{}
+	if cfg["amqpUsername"] != "" {
+		opts = append(opts, amqp.ConnSASLPlain(cfg["amqpUsername"], cfg["amqpPassword"]))
+	}
+	client, err := amqp.Dial(cfg["amqpURL"], opts...)
+	require.NoError(t, err)
+	defer client.Close() // nolint
+	session, err := client.NewSession()
+	require.NoError(t, err)
+	receiver, err := session.NewReceiver(amqp.LinkSourceAddress(address), amqp.LinkCredit(10))
+	require.NoError(t, err)
+
+	broker, err := getBrokerFromIntegration(context.TODO(), sdk.AMQPIntegrationModel, sdk.IntegrationConfig{
+		"url":      {Value: cfg["amqpURL"]},
+		"username": {Value: cfg["amqpUsername"]},
+		"password": {Value: cfg["amqpPassword"]},
+		"address":  {Value: address},
+	})
+	require.NoError(t, err)
+	defer broker.close(context.TODO())
+	assert.Equal(t, "AMQP OK", broker.status())
+
+	require.NoError(t, broker.sendEvent(&sdk.Event{EventType: "sdk.EventRunWorkflow", ProjectKey: "MY-PROJECT", WorkflowName: "my-workflow"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	msg, err := receiver.Receive(ctx)
+	require.NoError(t, err)
+	require.NoError(t, msg.Accept())
+	var e sdk.Event
+	require.NoError(t, json.Unmarshal(msg.GetData(), &e))
+	assert.Equal(t, "MY-PROJECT", e.ProjectKey)
+	assert.Equal(t, "my-workflow", e.WorkflowName)
+}
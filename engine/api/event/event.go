@@ -43,10 +43,50 @@ func getBroker(ctx context.Context, t string, option interface{}) (Broker, error
 	case "kafka":
 		k := &KafkaClient{}
 		return k.initialize(ctx, option)
+	case "nats":
+		n := &NATSClient{}
+		return n.initialize(ctx, option)
+	case "amqp":
+		a := &AMQPClient{}
+		return a.initialize(ctx, option)
 	}
 	return nil, fmt.Errorf("Invalid Broker Type %s", t)
 }
 
+// getBrokerFromIntegration returns the broker of an event integration according to its model
+func getBrokerFromIntegration(ctx context.Context, model string, cfg sdk.IntegrationConfig) (Broker, error) {
+	switch model {
+	case sdk.NATSIntegrationModel:
+		natsCfg := NATSConfig{
+			Enabled:  true,
+			URL:      cfg["url"].Value,
+			User:     cfg["username"].Value,
+			Password: cfg["password"].Value,
+			Subject:  cfg["subject"].Value,
+		}
+		return getBroker(ctx, "nats", natsCfg)
+	case sdk.AMQPIntegrationModel:
+		amqpCfg := AMQPConfig{
+			Enabled:  true,
+			URL:      cfg["url"].Value,
+			User:     cfg["username"].Value,
+			Password: cfg["password"].Value,
+			Address:  cfg["address"].Value,
+		}
+		return getBroker(ctx, "amqp", amqpCfg)
+	default:
+		kafkaCfg := KafkaConfig{
+			Enabled:         true,
+			BrokerAddresses: cfg["broker url"].Value,
+			User:            cfg["username"].Value,
+			Password:        cfg["password"].Value,
+			Topic:           cfg["topic"].Value,
+			MaxMessageByte:  10000000,
+		}
+		return getBroker(ctx, "kafka", kafkaCfg)
+	}
+}
+
 func ResetPublicIntegrations(ctx context.Context, db *gorp.DbMap) error {
 	filterType := sdk.IntegrationTypeEvent
 	integrations, err := integration.LoadPublicModelsByType(db, &filterType, true)
@@ -55,22 +95,13 @@ func ResetPublicIntegrations(ctx context.Context, db *gorp.DbMap) error {
 	}
 
 	for _, integration := range integrations {
-		for _, cfg := range integration.PublicConfigurations {
-			kafkaCfg := KafkaConfig{
-				Enabled:         true,
-				BrokerAddresses: cfg["broker url"].Value,
-				User:            cfg["username"].Value,
-				Password:        cfg["password"].Value,
-				Topic:           cfg["topic"].Value,
-				MaxMessageByte:  10000000,
-			}
-
-			kafkaBroker, errk := getBroker(ctx, "kafka", kafkaCfg)
+		for name, cfg := range integration.PublicConfigurations {
+			broker, errk := getBrokerFromIntegration(ctx, integration.Name, cfg)
 			if errk != nil {
-				return sdk.WrapError(errk, "cannot get broker for %s and user %s", cfg["broker url"].Value, cfg["username"].Value)
+				return sdk.WrapError(errk, "cannot get broker for %s and user %s", name, cfg["username"].Value)
 			}
 
-			publicBrokersConnectionCache = append(publicBrokersConnectionCache, kafkaBroker)
+			publicBrokersConnectionCache = append(publicBrokersConnectionCache, broker)
 		}
 	}
 
@@ -92,20 +123,12 @@ func ResetEventIntegration(ctx context.Context, db gorp.SqlExecutor, eventIntegr
 		return fmt.Errorf("cannot load project integration id %d and type event: %v", eventIntegrationID, err)
 	}
 
-	kafkaCfg := KafkaConfig{
-		Enabled:         true,
-		BrokerAddresses: projInt.Config["broker url"].Value,
-		User:            projInt.Config["username"].Value,
-		Password:        projInt.Config["password"].Value,
-		Topic:           projInt.Config["topic"].Value,
-		MaxMessageByte:  10000000,
-	}
-	kafkaBroker, errk := getBroker(ctx, "kafka", kafkaCfg)
+	broker, errk := getBrokerFromIntegration(ctx, projInt.Model.Name, projInt.Config)
 	if errk != nil {
-		return sdk.WrapError(sdk.ErrBadBrokerConfiguration, "cannot get broker for %s and user %s : %v", projInt.Config["broker url"].Value, projInt.Config["username"].Value, errk)
+		return sdk.WrapError(sdk.ErrBadBrokerConfiguration, "cannot get broker for %s and user %s : %v", projInt.Name, projInt.Config["username"].Value, errk)
 	}
-	if err := brokersConnectionCache.Add(brokerConnectionKey, kafkaBroker, gocache.DefaultExpiration); err != nil {
-		return sdk.WrapError(sdk.ErrBadBrokerConfiguration, "cannot add broker in cache for %s and user %s : %v", projInt.Config["broker url"].Value, projInt.Config["username"].Value, err)
+	if err := brokersConnectionCache.Add(brokerConnectionKey, broker, gocache.DefaultExpiration); err != nil {
+		return sdk.WrapError(sdk.ErrBadBrokerConfiguration, "cannot add broker in cache for %s and user %s : %v", projInt.Name, projInt.Config["username"].Value, err)
 	}
 	return nil
 }
@@ -174,24 +197,16 @@ func DequeueEvent(ctx context.Context, db *gorp.DbMap) {
 					continue
 				}
 
-				kafkaCfg := KafkaConfig{
-					Enabled:         true,
-					BrokerAddresses: projInt.Config["broker url"].Value,
-					User:            projInt.Config["username"].Value,
-					Password:        projInt.Config["password"].Value,
-					Topic:           projInt.Config["topic"].Value,
-					MaxMessageByte:  10000000,
-				}
-				kafkaBroker, errk := getBroker(ctx, "kafka", kafkaCfg)
+				broker, errk := getBrokerFromIntegration(ctx, projInt.Model.Name, projInt.Config)
 				if errk != nil {
-					log.Error(ctx, "Event.DequeueEvent> cannot get broker for %s and user %s : %v", projInt.Config["broker url"].Value, projInt.Config["username"].Value, errk)
+					log.Error(ctx, "Event.DequeueEvent> cannot get broker for %s and user %s : %v", projInt.Name, projInt.Config["username"].Value, errk)
 					continue
 				}
-				if err := brokersConnectionCache.Add(brokerConnectionKey, kafkaBroker, gocache.DefaultExpiration); err != nil {
-					log.Error(ctx, "Event.DequeueEvent> cannot add broker in cache for %s and user %s : %v", projInt.Config["broker url"].Value, projInt.Config["username"].Value, err)
+				if err := brokersConnectionCache.Add(brokerConnectionKey, broker, gocache.DefaultExpiration); err != nil {
+					log.Error(ctx, "Event.DequeueEvent> cannot add broker in cache for %s and user %s : %v", projInt.Name, projInt.Config["username"].Value, err)
 					continue
 				}
-				brokerConnection = kafkaBroker
+				brokerConnection = broker
 			}
 
 			broker, ok := brokerConnection.(Broker)
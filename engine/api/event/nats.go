@@ -0,0 +1,87 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// NATSClient embeddes the NATS connection, events are published with JetStream
+type NATSClient struct {
+	options NATSConfig
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+}
+
+// NATSConfig handles all config to connect to NATS
+type NATSConfig struct {
+	Enabled  bool
+	URL      string
+	User     string
+	Password string
+	Subject  string
+}
+
+// initialize returns broker, isInit and err if
+func (c *NATSClient) initialize(ctx context.Context, options interface{}) (Broker, error) {
+	conf, ok := options.(NATSConfig)
+	if !ok {
+		return nil, fmt.Errorf("Invalid NATS Initialization")
+	}
+
+	if conf.URL == "" || conf.Subject == "" {
+		return nil, fmt.Errorf("initNATS> Invalid NATS Configuration")
+	}
+	c.options = conf
+
+	opts := []nats.Option{nats.Name("cds-api-" + cdsname), nats.MaxReconnects(-1)}
+	if conf.User != "" {
+		opts = append(opts, nats.UserInfo(conf.User, conf.Password))
+	}
+	conn, err := nats.Connect(conf.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("initNATS> Error while connecting to %s user:%s: %v", conf.URL, conf.User, err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("initNATS> JetStream is not available on %s: %v", conf.URL, err)
+	}
+	c.conn = conn
+	c.js = js
+
+	log.Debug("initNATS> NATS used at %s on subject:%s", conf.URL, conf.Subject)
+	return c, nil
+}
+
+// close drains and closes the connection
+func (c *NATSClient) close(ctx context.Context) {
+	if c.conn != nil {
+		if err := c.conn.Drain(); err != nil {
+			log.Warning(ctx, "closeNATS> Error while closing nats connection:%s", err.Error())
+		}
+	}
+}
+
+// sendEvent publishes an event on the subject and waits for the acknowledgement of the stream
+func (c *NATSClient) sendEvent(event *sdk.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = c.js.Publish(c.options.Subject, data)
+	return err
+}
+
+// status returns the status of the connection
+func (c *NATSClient) status() string {
+	if c.conn == nil || !c.conn.IsConnected() {
+		return "NATS KO"
+	}
+	return "NATS OK"
+}
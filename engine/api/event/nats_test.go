@@ -0,0 +1,76 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestNATSBroker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nats")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  dir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go s.Start()
+	defer s.Shutdown()
+	require.True(t, s.ReadyForConnections(5*time.Second))
+
+	nc, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer nc.Close()
+	js, err := nc.JetStream()
+	require.NoError(t, err)
+	_, err = js.AddStream(&nats.StreamConfig{Name: "CDS", Subjects: []string{"cds.events"}})
+	require.NoError(t, err)
+
+	_, err = getBrokerFromIntegration(context.TODO(), sdk.NATSIntegrationModel, sdk.IntegrationConfig{
+		"url": {Value: s.ClientURL()},
+	})
+	assert.Error(t, err, "subject is mandatory")
+
+	broker, err := getBrokerFromIntegration(context.TODO(), sdk.NATSIntegrationModel, sdk.IntegrationConfig{
+		"url":     {Value: s.ClientURL()},
+		"subject": {Value: "cds.events"},
+	})
+	require.NoError(t, err)
+	defer broker.close(context.TODO())
+	assert.Equal(t, "NATS OK", broker.status())
+
+	require.NoError(t, broker.sendEvent(&sdk.Event{EventType: "sdk.EventRunWorkflow", ProjectKey: "MY-PROJECT", WorkflowName: "my-workflow"}))
+
+	sub, err := js.SubscribeSync("cds.events")
+	require.NoError(t, err)
+	msg, err := sub.NextMsg(5 * time.Second)
+	require.NoError(t, err)
+	var e sdk.Event
+	require.NoError(t, json.Unmarshal(msg.Data, &e))
+	assert.Equal(t, "MY-PROJECT", e.ProjectKey)
+	assert.Equal(t, "my-workflow", e.WorkflowName)
+
+	// Publishing on a subject without stream fails, the event is not acknowledged
+	broker, err = getBrokerFromIntegration(context.TODO(), sdk.NATSIntegrationModel, sdk.IntegrationConfig{
+		"url":     {Value: s.ClientURL()},
+		"subject": {Value: "other.events"},
+	})
+	require.NoError(t, err)
+	defer broker.close(context.TODO())
+	assert.Error(t, broker.sendEvent(&sdk.Event{ProjectKey: "MY-PROJECT"}))
+}
@@ -14,10 +14,22 @@ import (
 
 var store cache.Store
 
+type contextKey int
+
+const contextPublishDisabled contextKey = iota
+
+// WithPublishDisabled returns a context in which no event is published, ex: for changes that will be rolled back.
+func WithPublishDisabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextPublishDisabled, true)
+}
+
 func publishEvent(ctx context.Context, e sdk.Event) error {
 	if store == nil {
 		return nil
 	}
+	if disabled, _ := ctx.Value(contextPublishDisabled).(bool); disabled {
+		return nil
+	}
 
 	if err := store.Enqueue("events", e); err != nil {
 		return err
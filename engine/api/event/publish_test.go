@@ -0,0 +1,39 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/sdk"
+)
+
+type queueStore struct {
+	cache.Store
+	queues map[string][]interface{}
+}
+
+func (s *queueStore) Enqueue(queueName string, value interface{}) error {
+	s.queues[queueName] = append(s.queues[queueName], value)
+	return nil
+}
+
+func (s *queueStore) Publish(ctx context.Context, queueName string, value interface{}) error {
+	return s.Enqueue(queueName, value)
+}
+
+func TestWithPublishDisabled(t *testing.T) {
+	s := &queueStore{queues: map[string][]interface{}{}}
+	store = s
+	defer func() { store = nil }()
+
+	PublishPipelineAdd(WithPublishDisabled(context.TODO()), "MY-PROJECT", sdk.Pipeline{Name: "my-pipeline"}, nil)
+	assert.Len(t, s.queues["events"], 0)
+	assert.Len(t, s.queues["events_pubsub"], 0)
+
+	PublishPipelineAdd(context.TODO(), "MY-PROJECT", sdk.Pipeline{Name: "my-pipeline"}, nil)
+	assert.Len(t, s.queues["events"], 1)
+	assert.Len(t, s.queues["events_pubsub"], 1)
+}
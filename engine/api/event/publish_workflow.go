@@ -86,3 +86,17 @@ func PublishWorkflowPermissionDelete(ctx context.Context, projKey string, w sdk.
 	}
 	publishWorkflowEvent(ctx, e, projKey, w.Name, w.EventIntegrations, u)
 }
+
+// PublishWorkflowNodeRunApproval publishes an event when a user approves an approval node run
+func PublishWorkflowNodeRunApproval(ctx context.Context, projKey string, w sdk.Workflow, nr sdk.WorkflowNodeRun, approval sdk.WorkflowNodeRunApproval, requiredApprovals int, u sdk.Identifiable) {
+	e := sdk.EventWorkflowNodeRunApproval{
+		WorkflowID:        w.ID,
+		WorkflowRunNumber: nr.Number,
+		NodeRunID:         nr.ID,
+		NodeName:          nr.WorkflowNodeName,
+		Comment:           approval.Comment,
+		Approvals:         len(nr.Approvals),
+		RequiredApprovals: requiredApprovals,
+	}
+	publishWorkflowEvent(ctx, e, projKey, w.Name, w.EventIntegrations, u)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/ovh/cds/engine/api/group"
 	"github.com/ovh/cds/engine/api/project"
 	"github.com/ovh/cds/engine/api/user"
+	"github.com/ovh/cds/engine/api/workflowtemplate"
 	"github.com/ovh/cds/engine/service"
 	"github.com/ovh/cds/sdk"
 )
@@ -142,6 +143,19 @@ func (api *API) putGroupHandler() service.Handler {
 			return sdk.WrapError(err, "cannot update group with id: %d", newGroup.ID)
 		}
 
+		// Includes of templates are stored with the name of the group of the included template
+		if newGroup.Name != oldGroup.Name {
+			wts, err := workflowtemplate.LoadAllByGroupIDs(ctx, tx, []int64{newGroup.ID})
+			if err != nil {
+				return err
+			}
+			for _, wt := range wts {
+				if err := workflowtemplate.RenameIncludes(ctx, tx, oldGroup.Name+"/"+wt.Slug, newGroup.Name+"/"+wt.Slug); err != nil {
+					return err
+				}
+			}
+		}
+
 		// TODO Update all requirements that was using the group name
 
 		if err := tx.Commit(); err != nil {
@@ -92,6 +92,8 @@ func LoadProjectIntegrationsByKeyAndType(db gorp.SqlExecutor, key string, integr
 			query += " AND integration_model.hook = true"
 		case sdk.IntegrationTypeDeployment:
 			query += " AND integration_model.deployment = true"
+		case sdk.IntegrationTypeSecretBackend:
+			query += " AND integration_model.secret_backend = true"
 		}
 	}
 	if _, err := db.Select(&pps, query, key); err != nil {
@@ -43,6 +43,8 @@ func LoadPublicModelsByType(db gorp.SqlExecutor, integrationType *sdk.Integratio
 			query += " AND integration_model.hook = true"
 		case sdk.IntegrationTypeDeployment:
 			query += " AND integration_model.deployment = true"
+		case sdk.IntegrationTypeSecretBackend:
+			query += " AND integration_model.secret_backend = true"
 		}
 	}
 
@@ -12,8 +12,11 @@ var (
 	BuiltinModels = []sdk.IntegrationModel{
 		sdk.KafkaIntegration,
 		sdk.RabbitMQIntegration,
+		sdk.NATSIntegration,
+		sdk.AMQPIntegration,
 		sdk.OpenstackIntegration,
 		sdk.AWSIntegration,
+		sdk.VaultIntegration,
 	}
 )
 
@@ -0,0 +1,30 @@
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// GenerateHMACKey generates a new random secret, used to sign or verify payloads with HMAC
+func GenerateHMACKey(name string) (sdk.Key, error) {
+	k := sdk.Key{
+		Name: name,
+		Type: sdk.KeyTypeHMAC,
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return k, sdk.WrapError(err, "cannot generate hmac secret")
+	}
+	k.Private = hex.EncodeToString(secret)
+	k.KeyID = hmacKeyID(k.Private)
+	return k, nil
+}
+
+// hmacKeyID returns a short fingerprint of a secret, it allows to identify the secret without revealing it
+func hmacKeyID(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
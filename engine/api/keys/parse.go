@@ -56,6 +56,8 @@ func Parse(db gorp.SqlExecutor, projID int64, kname string, kval exportentities.
 				return nil, sdk.WrapError(errReadPub, "keys.Parse> Unable to read ssh public key")
 			}
 			k.Public = string(pubBytes)
+		case sdk.KeyTypeHMAC:
+			k.KeyID = hmacKeyID(k.Private)
 		default:
 			return nil, sdk.ErrUnknownKeyType
 		}
@@ -75,6 +77,12 @@ func Parse(db gorp.SqlExecutor, projID int64, kname string, kval exportentities.
 				return nil, sdk.WrapError(err, "Unable to generate SSH key pair")
 			}
 			k = &ktemp
+		case sdk.KeyTypeHMAC:
+			ktemp, err := GenerateHMACKey(kname)
+			if err != nil {
+				return nil, sdk.WrapError(err, "Unable to generate HMAC secret")
+			}
+			k = &ktemp
 		default:
 			return nil, sdk.ErrUnknownKeyType
 		}
@@ -0,0 +1,202 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+type dbDeployment struct {
+	ProjectKey      string         `db:"projectkey"`
+	WorkflowName    string         `db:"workflow_name"`
+	EnvironmentName string         `db:"environment_name"`
+	NodeRunID       int64          `db:"id"`
+	Status          string         `db:"status"`
+	Start           time.Time      `db:"start"`
+	Done            time.Time      `db:"done"`
+	VCSTag          sql.NullString `db:"vcs_tag"`
+	Commits         sql.NullString `db:"commits"`
+}
+
+// LoadDeployments returns the terminated runs of workflow nodes with an environment started between from and to,
+// ordered by start date. Empty project key, workflow name or environment name match all.
+func LoadDeployments(ctx context.Context, db gorp.SqlExecutor, projectKey, workflowName, environmentName string, from, to time.Time) ([]sdk.Deployment, error) {
+	// The environment is read from the build parameters of the node run to not depend on the current workflow definition
+	query := `
+	SELECT project.projectkey, workflow.name AS workflow_name, deploy.environment_name,
+		workflow_node_run.id, workflow_node_run.status, workflow_node_run.start, workflow_node_run.done,
+		workflow_node_run.vcs_tag, workflow_node_run.commits
+	FROM workflow_node_run
+	JOIN workflow ON workflow.id = workflow_node_run.workflow_id
+	JOIN project ON project.id = workflow.project_id
+	JOIN LATERAL (
+		SELECT param->>'value' AS environment_name
+		FROM jsonb_array_elements(CASE WHEN jsonb_typeof(workflow_node_run.build_parameters) = 'array' THEN workflow_node_run.build_parameters ELSE '[]'::jsonb END) param
+		WHERE param->>'name' = 'cds.environment'
+	) deploy ON true
+	WHERE workflow_node_run.start >= $1 AND workflow_node_run.start < $2
+	AND workflow_node_run.status IN ($3, $4)
+	AND ($5 = '' OR project.projectkey = $5)
+	AND ($6 = '' OR workflow.name = $6)
+	AND ($7 = '' OR deploy.environment_name = $7)
+	ORDER BY workflow_node_run.start`
+
+	var res []dbDeployment
+	if _, err := db.Select(&res, query, from, to, sdk.StatusSuccess, sdk.StatusFail, projectKey, workflowName, environmentName); err != nil {
+		return nil, sdk.WrapError(err, "cannot load deployments")
+	}
+
+	deployments := make([]sdk.Deployment, 0, len(res))
+	for _, r := range res {
+		d := sdk.Deployment{
+			ProjectKey:      r.ProjectKey,
+			WorkflowName:    r.WorkflowName,
+			EnvironmentName: r.EnvironmentName,
+			NodeRunID:       r.NodeRunID,
+			Status:          r.Status,
+			Start:           r.Start,
+			Done:            r.Done,
+			VCSTag:          r.VCSTag.String,
+		}
+		if r.Commits.Valid {
+			if err := json.Unmarshal([]byte(r.Commits.String), &d.Commits); err != nil {
+				log.Warning(ctx, "LoadDeployments> unable to unmarshal commits of node run %d: %v", r.NodeRunID, err)
+			}
+		}
+		deployments = append(deployments, d)
+	}
+	return deployments, nil
+}
+
+type deliveryGroup struct {
+	projectKey      string
+	workflowName    string
+	environmentName string
+}
+
+// ComputeDeliveryMetrics aggregates the deployments in metrics for each window of given period between from and to,
+// or on the whole range if period is zero. For each window, the first metrics are the totals of each project followed by
+// the metrics of each workflow and environment.
+func ComputeDeliveryMetrics(deployments []sdk.Deployment, from, to time.Time, period time.Duration) []sdk.DeliveryMetrics {
+	if period <= 0 {
+		period = to.Sub(from)
+	}
+
+	var res []sdk.DeliveryMetrics
+	for start := from; start.Before(to); start = start.Add(period) {
+		end := start.Add(period)
+		if end.After(to) {
+			end = to
+		}
+
+		var projects []string
+		var groups []deliveryGroup
+		byProject := map[string][]sdk.Deployment{}
+		byGroup := map[deliveryGroup][]sdk.Deployment{}
+		for _, d := range deployments {
+			if d.Start.Before(start) || !d.Start.Before(end) {
+				continue
+			}
+			if _, ok := byProject[d.ProjectKey]; !ok {
+				projects = append(projects, d.ProjectKey)
+			}
+			byProject[d.ProjectKey] = append(byProject[d.ProjectKey], d)
+
+			k := deliveryGroup{projectKey: d.ProjectKey, workflowName: d.WorkflowName, environmentName: d.EnvironmentName}
+			if _, ok := byGroup[k]; !ok {
+				groups = append(groups, k)
+			}
+			byGroup[k] = append(byGroup[k], d)
+		}
+
+		sort.Strings(projects)
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].projectKey != groups[j].projectKey {
+				return groups[i].projectKey < groups[j].projectKey
+			}
+			if groups[i].workflowName != groups[j].workflowName {
+				return groups[i].workflowName < groups[j].workflowName
+			}
+			return groups[i].environmentName < groups[j].environmentName
+		})
+
+		for _, p := range projects {
+			m := computeDeliveryMetrics(byProject[p], start, end)
+			m.ProjectKey = p
+			res = append(res, m)
+		}
+		for _, g := range groups {
+			m := computeDeliveryMetrics(byGroup[g], start, end)
+			m.ProjectKey, m.WorkflowName, m.EnvironmentName = g.projectKey, g.workflowName, g.environmentName
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// computeDeliveryMetrics computes the metrics of deployments ordered by start date:
+// - the deployment frequency is the number of successful deployments per day,
+// - the lead time is the median time between a commit and its successful deployment,
+// - the change failure rate is the ratio of failed deployments,
+// - the MTTR is the mean time between a failed deployment and the next successful deployment on the same environment.
+func computeDeliveryMetrics(deployments []sdk.Deployment, from, to time.Time) sdk.DeliveryMetrics {
+	m := sdk.DeliveryMetrics{From: from, To: to}
+
+	var successes int64
+	var leadTimes []int64
+	var restoreTimes []int64
+	failedSince := map[string]time.Time{}
+	for _, d := range deployments {
+		m.Deployments++
+		env := d.ProjectKey + "/" + d.WorkflowName + "/" + d.EnvironmentName
+
+		if d.Status != sdk.StatusSuccess {
+			m.FailedDeployments++
+			if _, ok := failedSince[env]; !ok {
+				failedSince[env] = d.Done
+			}
+			continue
+		}
+
+		successes++
+		for _, c := range d.Commits {
+			if c.Timestamp <= 0 {
+				continue
+			}
+			commitDate := time.Unix(0, c.Timestamp*int64(time.Millisecond))
+			if lt := d.Done.Sub(commitDate); lt > 0 {
+				leadTimes = append(leadTimes, int64(lt.Seconds()))
+			}
+		}
+		if failed, ok := failedSince[env]; ok {
+			restoreTimes = append(restoreTimes, int64(d.Done.Sub(failed).Seconds()))
+			delete(failedSince, env)
+		}
+	}
+
+	if days := to.Sub(from).Hours() / 24; days > 0 {
+		m.DeploymentFrequency = float64(successes) / days
+	}
+	if m.Deployments > 0 {
+		m.ChangeFailureRate = float64(m.FailedDeployments) / float64(m.Deployments)
+	}
+	if len(leadTimes) > 0 {
+		sort.Slice(leadTimes, func(i, j int) bool { return leadTimes[i] < leadTimes[j] })
+		m.LeadTime = leadTimes[len(leadTimes)/2]
+	}
+	if len(restoreTimes) > 0 {
+		var total int64
+		for _, t := range restoreTimes {
+			total += t
+		}
+		m.MTTR = total / int64(len(restoreTimes))
+	}
+	return m
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestComputeDeliveryMetrics(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(4 * 24 * time.Hour)
+
+	commit := func(d time.Time) sdk.VCSCommit {
+		return sdk.VCSCommit{Timestamp: d.Unix() * 1000}
+	}
+
+	deployments := []sdk.Deployment{
+		{ProjectKey: "KEY", WorkflowName: "wf", EnvironmentName: "prod", Status: sdk.StatusSuccess,
+			Start: from.Add(time.Hour), Done: from.Add(2 * time.Hour), Commits: []sdk.VCSCommit{commit(from)}},
+		{ProjectKey: "KEY", WorkflowName: "wf", EnvironmentName: "prod", Status: sdk.StatusFail,
+			Start: from.Add(24 * time.Hour), Done: from.Add(25 * time.Hour)},
+		{ProjectKey: "KEY", WorkflowName: "wf", EnvironmentName: "prod", Status: sdk.StatusFail,
+			Start: from.Add(26 * time.Hour), Done: from.Add(27 * time.Hour)},
+		{ProjectKey: "KEY", WorkflowName: "wf", EnvironmentName: "prod", Status: sdk.StatusSuccess,
+			Start: from.Add(28 * time.Hour), Done: from.Add(29 * time.Hour), Commits: []sdk.VCSCommit{commit(from.Add(23 * time.Hour)), commit(from.Add(19 * time.Hour))}},
+		{ProjectKey: "KEY", WorkflowName: "wf", EnvironmentName: "preprod", Status: sdk.StatusSuccess,
+			Start: from.Add(72 * time.Hour), Done: from.Add(73 * time.Hour)},
+	}
+
+	res := ComputeDeliveryMetrics(deployments, from, to, 0)
+	require.Len(t, res, 3)
+
+	total := res[0]
+	assert.Equal(t, "KEY", total.ProjectKey)
+	assert.Empty(t, total.WorkflowName)
+	assert.Equal(t, int64(5), total.Deployments)
+	assert.Equal(t, int64(2), total.FailedDeployments)
+	assert.Equal(t, 0.75, total.DeploymentFrequency)
+	assert.Equal(t, 0.4, total.ChangeFailureRate)
+	assert.Equal(t, int64(6*3600), total.LeadTime)
+	assert.Equal(t, int64(4*3600), total.MTTR)
+
+	assert.Equal(t, "preprod", res[1].EnvironmentName)
+	assert.Equal(t, int64(1), res[1].Deployments)
+	assert.Equal(t, "prod", res[2].EnvironmentName)
+	assert.Equal(t, int64(4), res[2].Deployments)
+
+	res = ComputeDeliveryMetrics(deployments, from, to, 2*24*time.Hour)
+	require.Len(t, res, 4)
+	assert.Equal(t, from, res[0].From)
+	assert.Equal(t, int64(4), res[0].Deployments)
+	assert.Equal(t, from.Add(2*24*time.Hour), res[2].From)
+	assert.Equal(t, int64(1), res[2].Deployments)
+}
@@ -159,6 +159,21 @@ func (s *AWSS3Store) Fetch(ctx context.Context, o Object) (io.ReadCloser, error)
 	return out.Body, nil
 }
 
+// FetchFrom an object from a bucket, from given offset
+func (s *AWSS3Store) FetchFrom(ctx context.Context, o Object, offset int64) (io.ReadCloser, error) {
+	s3n := s3.New(s.sess)
+	log.Debug("AWS-S3-Store> Fetching object %s from bucket %s from offset %d", s.getObjectPath(o), s.bucketName, offset)
+	out, err := s3n.GetObject(&s3.GetObjectInput{
+		Key:    aws.String(s.getObjectPath(o)),
+		Bucket: aws.String(s.bucketName),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-", offset)),
+	})
+	if err != nil {
+		return nil, sdk.WrapError(err, "AWS-S3-Store> Unable to download object %s", s.getObjectPath(o))
+	}
+	return out.Body, nil
+}
+
 // Delete deletes an artifact from a bucket
 func (s *AWSS3Store) Delete(ctx context.Context, o Object) error {
 	s3n := s3.New(s.sess)
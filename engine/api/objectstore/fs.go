@@ -75,6 +75,19 @@ func (fss *FilesystemStore) Fetch(ctx context.Context, o Object) (io.ReadCloser,
 	return os.Open(dst)
 }
 
+// FetchFrom lookup on disk for data from given offset
+func (fss *FilesystemStore) FetchFrom(ctx context.Context, o Object, offset int64) (io.ReadCloser, error) {
+	f, err := fss.Fetch(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.(*os.File).Seek(offset, io.SeekStart); err != nil {
+		f.Close() // nolint
+		return nil, err
+	}
+	return f, nil
+}
+
 // Delete deletes data from disk
 func (fss *FilesystemStore) Delete(ctx context.Context, o Object) error {
 	dst := path.Join(fss.basedir, o.GetPath(), o.GetName())
@@ -38,6 +38,12 @@ type DriverWithRedirect interface {
 	ServeStaticFilesURL(o Object, entrypoint string) (string, string, error)
 }
 
+// DriverWithRange has to be implemented if your storage backend supports to fetch an object from an offset
+type DriverWithRange interface {
+	// FetchFrom returns the content of an object from given offset in bytes
+	FetchFrom(ctx context.Context, o Object, offset int64) (io.ReadCloser, error)
+}
+
 // Kind will define const defining all supported objecstore drivers
 type Kind int
 
@@ -165,6 +165,25 @@ func (s *SwiftStore) Fetch(ctx context.Context, o Object) (io.ReadCloser, error)
 	return pipeReader, nil
 }
 
+// FetchFrom an object from swift, from given offset
+func (s *SwiftStore) FetchFrom(ctx context.Context, o Object, offset int64) (io.ReadCloser, error) {
+	container := s.containerPrefix + o.GetPath()
+	object := o.GetName()
+	escape(container, object)
+
+	pipeReader, pipeWriter := io.Pipe()
+	log.Debug("SwiftStore> Fetching /%s/%s from offset %d", container, object, offset)
+
+	go func() {
+		_, err := s.ObjectGet(container, object, pipeWriter, false, swift.Headers{"Range": fmt.Sprintf("bytes=%d-", offset)})
+		if err != nil {
+			log.Error(ctx, "SwiftStore> Unable to get object %s/%s: %s", container, object, err)
+		}
+		pipeWriter.CloseWithError(err) // nolint
+	}()
+	return pipeReader, nil
+}
+
 // Delete deletes an object from swift
 func (s *SwiftStore) Delete(ctx context.Context, o Object) error {
 	container := s.containerPrefix + o.GetPath()
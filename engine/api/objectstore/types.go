@@ -1,7 +1,20 @@
 package objectstore
 
+import (
+	"github.com/ovh/cds/sdk"
+)
+
 //Object is the interface for stuff needed to be stored in object store
 type Object interface {
 	GetName() string
 	GetPath() string
 }
+
+// ArtifactObject returns the object that contains the data of given artifact,
+// the shared blob if the artifact was stored by content.
+func ArtifactObject(a *sdk.WorkflowNodeRunArtifact) Object {
+	if a.BlobSHA256 != "" {
+		return &sdk.ArtifactBlob{SHA256: a.BlobSHA256}
+	}
+	return a
+}
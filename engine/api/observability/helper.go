@@ -35,6 +35,7 @@ const (
 	TagWorker             = "worker"
 	TagToken              = "token"
 	TagPermission         = "permission"
+	TagEnvironment        = "environment"
 )
 
 // LinkTo a traceID
@@ -56,6 +56,7 @@ func InsertStage(db gorp.SqlExecutor, s *sdk.Stage) error {
 func insertStageConditions(db gorp.SqlExecutor, s *sdk.Stage) error {
 	if s.Conditions.LuaScript != "" {
 		s.Conditions.PlainConditions = nil
+		s.Conditions.Groups = nil
 	}
 	query := "UPDATE pipeline_stage SET conditions = $1 WHERE id = $2"
 
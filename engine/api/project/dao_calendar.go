@@ -0,0 +1,52 @@
+package project
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadCalendars returns all the calendars of a project.
+func LoadCalendars(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([]sdk.ProjectCalendar, error) {
+	cs := []sdk.ProjectCalendar{}
+	query := gorpmapping.NewQuery("SELECT * FROM project_calendar WHERE project_id = $1 ORDER BY name").Args(projectID)
+	if err := gorpmapping.GetAll(ctx, db, query, &cs); err != nil {
+		return nil, sdk.WrapError(err, "cannot get calendars of project %d", projectID)
+	}
+	return cs, nil
+}
+
+// LoadCalendarByName returns the calendar of a project with given name.
+func LoadCalendarByName(ctx context.Context, db gorp.SqlExecutor, projectID int64, name string) (*sdk.ProjectCalendar, error) {
+	var c sdk.ProjectCalendar
+	query := gorpmapping.NewQuery("SELECT * FROM project_calendar WHERE project_id = $1 AND name = $2").Args(projectID, name)
+	found, err := gorpmapping.Get(ctx, db, query, &c)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot get calendar %s", name)
+	}
+	if !found {
+		return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "calendar %s not found", name)
+	}
+	return &c, nil
+}
+
+// InsertCalendar a new calendar in database.
+func InsertCalendar(db gorp.SqlExecutor, c *sdk.ProjectCalendar) error {
+	c.LastModified = time.Now()
+	return sdk.WrapError(gorpmapping.Insert(db, c), "unable to insert calendar %s", c.Name)
+}
+
+// UpdateCalendar a calendar in database.
+func UpdateCalendar(db gorp.SqlExecutor, c *sdk.ProjectCalendar) error {
+	c.LastModified = time.Now()
+	return sdk.WrapError(gorpmapping.Update(db, c), "unable to update calendar %s", c.Name)
+}
+
+// DeleteCalendar a calendar in database.
+func DeleteCalendar(db gorp.SqlExecutor, c *sdk.ProjectCalendar) error {
+	return sdk.WrapError(gorpmapping.Delete(db, c), "unable to delete calendar %s", c.Name)
+}
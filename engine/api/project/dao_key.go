@@ -96,6 +96,33 @@ func LoadAllDecryptedKeys(db gorp.SqlExecutor, proj *sdk.Project) error {
 	return nil
 }
 
+// LoadKey load a project key by its name, the private part is only decrypted if clearKey is true and for a HMAC key
+func LoadKey(db gorp.SqlExecutor, projectID int64, keyName string, clearKey bool) (*sdk.ProjectKey, error) {
+	var res dbProjectKey
+	if err := db.SelectOne(&res, "SELECT * FROM project_key WHERE project_id = $1 and builtin = false and name = $2", projectID, keyName); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sdk.WithStack(sdk.ErrNotFound)
+		}
+		return nil, sdk.WrapError(err, "Cannot load key %s", keyName)
+	}
+
+	k := sdk.ProjectKey(res)
+	if !clearKey {
+		k.Private = sdk.PasswordPlaceholder
+		return &k, nil
+	}
+	// Only the secret of a HMAC key can be decrypted, SSH and PGP private keys never leave the API
+	if k.Type != sdk.KeyTypeHMAC {
+		return nil, sdk.NewErrorFrom(sdk.ErrForbidden, "private part of %s key %s can't be read", k.Type, keyName)
+	}
+	decrypted, err := secret.Decrypt([]byte(k.Private))
+	if err != nil {
+		return nil, sdk.WrapError(err, "Unable to decrypt key %s", keyName)
+	}
+	k.Private = string(decrypted)
+	return &k, nil
+}
+
 // DeleteProjectKey Delete the given key from the given project
 func DeleteProjectKey(db gorp.SqlExecutor, projectID int64, keyName string) error {
 	_, err := db.Exec("DELETE FROM project_key WHERE project_id = $1 AND name = $2", projectID, keyName)
@@ -25,6 +25,7 @@ func init() {
 	gorpmapping.Register(gorpmapping.New(dbProjectVariableAudit{}, "project_variable_audit", true, "id"))
 	gorpmapping.Register(gorpmapping.New(dbProjectKey{}, "project_key", false))
 	gorpmapping.Register(gorpmapping.New(dbLabel{}, "project_label", true, "id"))
+	gorpmapping.Register(gorpmapping.New(sdk.ProjectCalendar{}, "project_calendar", true, "id"))
 }
 
 // PostGet is a db hook
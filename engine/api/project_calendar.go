@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getProjectCalendarsHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)[permProjectKey]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		cs, err := project.LoadCalendars(ctx, api.mustDB(), p.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, cs, http.StatusOK)
+	}
+}
+
+func (api *API) getProjectCalendarHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		c, err := project.LoadCalendarByName(ctx, api.mustDB(), p.ID, vars["calendarName"])
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, c, http.StatusOK)
+	}
+}
+
+func (api *API) postProjectCalendarHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		key := mux.Vars(r)[permProjectKey]
+
+		var c sdk.ProjectCalendar
+		if err := service.UnmarshalBody(r, &c); err != nil {
+			return sdk.WrapError(err, "cannot read body")
+		}
+		if err := c.IsValid(); err != nil {
+			return err
+		}
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		if _, err := project.LoadCalendarByName(ctx, api.mustDB(), p.ID, c.Name); err == nil {
+			return sdk.NewErrorFrom(sdk.ErrAlreadyExist, "calendar %s already exists", c.Name)
+		} else if !sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return err
+		}
+
+		c.ProjectID = p.ID
+		if err := project.InsertCalendar(api.mustDB(), &c); err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, c, http.StatusCreated)
+	}
+}
+
+func (api *API) putProjectCalendarHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+
+		var c sdk.ProjectCalendar
+		if err := service.UnmarshalBody(r, &c); err != nil {
+			return sdk.WrapError(err, "cannot read body")
+		}
+		if err := c.IsValid(); err != nil {
+			return err
+		}
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		old, err := project.LoadCalendarByName(ctx, api.mustDB(), p.ID, vars["calendarName"])
+		if err != nil {
+			return err
+		}
+
+		// The schedulers that use the calendar reference it by its name
+		if c.Name != old.Name {
+			names, err := workflow.LoadWorkflowNamesByCalendar(api.mustDB(), p.ID, old.Name)
+			if err != nil {
+				return err
+			}
+			if len(names) > 0 {
+				return sdk.NewErrorFrom(sdk.ErrForbidden, "calendar %s can't be renamed, it is used by the schedulers of workflows %s", old.Name, strings.Join(names, ", "))
+			}
+		}
+
+		c.ID = old.ID
+		c.ProjectID = p.ID
+		if err := project.UpdateCalendar(api.mustDB(), &c); err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, c, http.StatusOK)
+	}
+}
+
+func (api *API) deleteProjectCalendarHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		c, err := project.LoadCalendarByName(ctx, api.mustDB(), p.ID, vars["calendarName"])
+		if err != nil {
+			return err
+		}
+
+		// The schedulers that use the calendar would fail to trigger their workflow
+		names, err := workflow.LoadWorkflowNamesByCalendar(api.mustDB(), p.ID, c.Name)
+		if err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			return sdk.NewErrorFrom(sdk.ErrForbidden, "calendar %s is used by the schedulers of workflows %s", c.Name, strings.Join(names, ", "))
+		}
+
+		if err := project.DeleteCalendar(api.mustDB(), c); err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, nil, http.StatusOK)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_projectCalendarHandlers(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+
+	u, pass := assets.InsertAdminUser(t, db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, api.Cache, proj, sdk.RandomString(10))
+
+	do := func(method string, handler service.HandlerFunc, vars map[string]string, body interface{}) *httptest.ResponseRecorder {
+		uri := router.GetRoute(method, handler, vars)
+		require.NotEmpty(t, uri)
+		req := assets.NewAuthentifiedRequest(t, u, pass, method, uri, body)
+		rec := httptest.NewRecorder()
+		router.Mux.ServeHTTP(rec, req)
+		return rec
+	}
+	projectVars := map[string]string{"permProjectKey": proj.Key}
+	calendarVars := map[string]string{"permProjectKey": proj.Key, "calendarName": "holidays"}
+
+	calendar := sdk.ProjectCalendar{Name: "holidays", Blackouts: sdk.CalendarBlackouts{{From: "2020-12-25", To: "2020-12-25"}}}
+	rec := do("POST", api.postProjectCalendarHandler, projectVars, calendar)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	rec = do("POST", api.postProjectCalendarHandler, projectVars, calendar)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	rec = do("POST", api.postProjectCalendarHandler, projectVars, sdk.ProjectCalendar{Name: "invalid", Timezone: "Mars/Olympus"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do("GET", api.getProjectCalendarsHandler, projectVars, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var cs []sdk.ProjectCalendar
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &cs))
+	require.Len(t, cs, 1)
+	assert.Equal(t, "holidays", cs[0].Name)
+
+	// A scheduler is checked when the workflow is saved
+	saveScheduler := func(config map[string]string) error {
+		w1, err := workflow.Load(context.TODO(), db, api.Cache, proj, w.Name, workflow.LoadOptions{})
+		require.NoError(t, err)
+		c := sdk.SchedulerModel.DefaultConfig.Clone()
+		for k, v := range config {
+			c[k] = sdk.WorkflowNodeHookConfigValue{Value: v, Configurable: true, Type: sdk.HookConfigTypeString}
+		}
+		w1.WorkflowData.Node.Hooks = []sdk.NodeHook{{HookModelName: sdk.SchedulerModelName, Config: c}}
+		return workflow.Update(context.TODO(), db, api.Cache, w1, proj, workflow.UpdateOptions{DisableHookManagement: true})
+	}
+	assert.Error(t, saveScheduler(map[string]string{sdk.SchedulerModelCalendar: "unknown"}))
+	assert.Error(t, saveScheduler(map[string]string{sdk.SchedulerModelJitter: "five minutes"}))
+	assert.Error(t, saveScheduler(map[string]string{sdk.SchedulerModelCron: "0 * * * *", sdk.SchedulerModelJitter: "2h"}))
+	require.NoError(t, saveScheduler(map[string]string{sdk.SchedulerModelCalendar: "holidays", sdk.SchedulerModelJitter: "5m"}))
+
+	// A calendar used by a scheduler can't be deleted or renamed
+	rec = do("DELETE", api.deleteProjectCalendarHandler, calendarVars, nil)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	rec = do("PUT", api.putProjectCalendarHandler, calendarVars, sdk.ProjectCalendar{Name: "vacations"})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	calendar.Timezone = "Europe/Paris"
+	rec = do("PUT", api.putProjectCalendarHandler, calendarVars, calendar)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = do("GET", api.getProjectCalendarHandler, calendarVars, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var c sdk.ProjectCalendar
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &c))
+	assert.Equal(t, "Europe/Paris", c.Timezone)
+
+	require.NoError(t, saveScheduler(nil))
+	rec = do("DELETE", api.deleteProjectCalendarHandler, calendarVars, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = do("GET", api.getProjectCalendarHandler, calendarVars, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -84,6 +84,32 @@ func (api *API) getKeysInProjectHandler() service.Handler {
 	}
 }
 
+func (api *API) getKeyInProjectHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+		keyName := vars["name"]
+
+		// The secret of a HMAC key can only be read by the hooks service to check the signature of webhooks
+		clearKey := FormBool(r, "clearKey")
+		if _, isHooks := api.isHooks(ctx); clearKey && !isHooks {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		k, err := project.LoadKey(api.mustDB(), p.ID, keyName, clearKey)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, k, http.StatusOK)
+	}
+}
+
 func (api *API) deleteKeyInProjectHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		vars := mux.Vars(r)
@@ -160,6 +186,12 @@ func (api *API) addKeyInProjectHandler() service.Handler {
 				return sdk.WrapError(errGenerate, "addKeyInProjectHandler> Cannot generate pgpKey")
 			}
 			newKey.Key = k
+		case sdk.KeyTypeHMAC:
+			k, err := keys.GenerateHMACKey(newKey.Name)
+			if err != nil {
+				return sdk.WrapError(err, "addKeyInProjectHandler> Cannot generate hmac secret")
+			}
+			newKey.Key = k
 		default:
 			return sdk.WrapError(sdk.ErrUnknownKeyType, "addKeyInProjectHandler> unknown key of type: %s", newKey.Type)
 		}
@@ -170,6 +202,7 @@ func (api *API) addKeyInProjectHandler() service.Handler {
 		}
 		defer tx.Rollback() // nolint
 
+		hmacSecret := newKey.Private
 		if err := project.InsertKey(tx, &newKey); err != nil {
 			return sdk.WrapError(err, "Cannot insert project key")
 		}
@@ -178,6 +211,11 @@ func (api *API) addKeyInProjectHandler() service.Handler {
 			return sdk.WrapError(err, "Cannot commit transaction")
 		}
 
+		// A hmac secret has no public part, it is only returned once so it can be shared with the sender of the payloads
+		if newKey.Type == sdk.KeyTypeHMAC {
+			newKey.Private = hmacSecret
+		}
+
 		event.PublishAddProjectKey(ctx, p, newKey, getAPIConsumer(ctx))
 
 		return service.WriteJSON(w, newKey, http.StatusOK)
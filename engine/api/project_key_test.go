@@ -2,19 +2,24 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/authentication"
 	"github.com/ovh/cds/engine/api/environment"
 	"github.com/ovh/cds/engine/api/keys"
 	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/services"
 	"github.com/ovh/cds/engine/api/test"
 	"github.com/ovh/cds/engine/api/test/assets"
 	"github.com/ovh/cds/sdk"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_getAllKeysProjectHandler(t *testing.T) {
@@ -296,3 +301,78 @@ func Test_addKeyInProjectHandler(t *testing.T) {
 
 	assert.Equal(t, proj.ID, key.ProjectID)
 }
+
+func Test_getKeyInProjectHandler(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+
+	u, pass := assets.InsertAdminUser(t, api.mustDB())
+	pkey := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, pkey, pkey)
+
+	khmac, err := keys.GenerateHMACKey("my-hmac")
+	require.NoError(t, err)
+	kpgp, err := keys.GeneratePGPKeyPair("my-pgp")
+	require.NoError(t, err)
+	for _, k := range []sdk.Key{khmac, kpgp} {
+		require.NoError(t, project.InsertKey(api.mustDB(), &sdk.ProjectKey{Key: k, ProjectID: proj.ID}))
+	}
+
+	newServiceJWT := func(serviceType string) string {
+		srv, _ := assets.InsertService(t, db, sdk.RandomString(10), serviceType)
+		consumer, err := authentication.LoadConsumerByID(context.TODO(), db, *srv.ConsumerID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+		require.NoError(t, err)
+		session, err := authentication.NewSession(context.TODO(), db, consumer, 5*time.Minute, false)
+		require.NoError(t, err)
+		jwt, err := authentication.NewSessionJWT(session)
+		require.NoError(t, err)
+		return jwt
+	}
+	hooksJWT := newServiceJWT(services.TypeHooks)
+	hatcheryJWT := newServiceJWT(services.TypeHatchery)
+
+	get := func(req func(uri string) *http.Request, name string, clearKey bool) (*sdk.ProjectKey, int) {
+		uri := router.GetRoute("GET", api.getKeyInProjectHandler, map[string]string{
+			"permProjectKey": proj.Key,
+			"name":           name,
+		})
+		require.NotEmpty(t, uri)
+		if clearKey {
+			uri += "?clearKey=true"
+		}
+		w := httptest.NewRecorder()
+		router.Mux.ServeHTTP(w, req(uri))
+		if w.Code != http.StatusOK {
+			return nil, w.Code
+		}
+		var k sdk.ProjectKey
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &k))
+		return &k, w.Code
+	}
+	asUser := func(uri string) *http.Request {
+		return assets.NewAuthentifiedRequest(t, u, pass, "GET", uri, nil)
+	}
+	asService := func(jwt string) func(uri string) *http.Request {
+		return func(uri string) *http.Request {
+			return assets.NewJWTAuthentifiedRequest(t, jwt, "GET", uri, nil)
+		}
+	}
+
+	// The private part of a key is hidden by default
+	k, code := get(asUser, khmac.Name, false)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, sdk.PasswordPlaceholder, k.Private)
+
+	// Only the hooks service can read the secret of a HMAC key
+	_, code = get(asUser, khmac.Name, true)
+	assert.Equal(t, http.StatusForbidden, code)
+	_, code = get(asService(hatcheryJWT), khmac.Name, true)
+	assert.Equal(t, http.StatusForbidden, code)
+	k, code = get(asService(hooksJWT), khmac.Name, true)
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, khmac.Private, k.Private)
+
+	// The private part of the other keys can't be read
+	_, code = get(asService(hooksJWT), kpgp.Name, true)
+	assert.Equal(t, http.StatusForbidden, code)
+}
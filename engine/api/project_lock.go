@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+func (api *API) getProjectLocksHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		locks, err := workflow.LoadProjectLocks(api.mustDB(), p.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, locks, http.StatusOK)
+	}
+}
+
+func (api *API) deleteProjectLockHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+		lockName := vars["lockName"]
+
+		p, err := project.Load(api.mustDB(), api.Cache, key,
+			project.LoadOptions.WithVariables,
+			project.LoadOptions.WithFeatures,
+			project.LoadOptions.WithIntegrations,
+			project.LoadOptions.WithApplicationVariables,
+			project.LoadOptions.WithApplicationWithDeploymentStrategies,
+			project.LoadOptions.WithEnvironments,
+			project.LoadOptions.WithPipelines,
+		)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		// Only an admin or the user that triggered the workflow run holding the lock can release it
+		if !isAdmin(ctx) {
+			_, owner, err := workflow.LoadProjectLockOwner(api.mustDB(), p.ID, lockName)
+			if err != nil {
+				return err
+			}
+			if owner != getAPIConsumer(ctx).GetUsername() {
+				return sdk.NewErrorFrom(sdk.ErrForbidden, "lock %s can only be released by an administrator or by the user that triggered the workflow run holding it", lockName)
+			}
+		}
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WrapError(err, "cannot start transaction")
+		}
+		defer tx.Rollback() // nolint
+
+		report, err := workflow.ReleaseProjectLock(ctx, tx, api.Cache, p, lockName, getAPIConsumer(ctx).GetUsername())
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WrapError(err, "cannot commit transaction")
+		}
+
+		go workflow.SendEvent(context.Background(), api.mustDB(), p.Key, report)
+
+		return service.WriteJSON(w, nil, http.StatusOK)
+	}
+}
+
+// releaseStaleProjectLocks periodically releases the project locks held for more than sdk.ProjectLockTimeout or by a
+// node run that is over, and removes the waiters that are not waiting anymore.
+func (api *API) releaseStaleProjectLocks(ctx context.Context) {
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() != nil {
+				log.Error(ctx, "Exiting api.releaseStaleProjectLocks: %v", ctx.Err())
+				return
+			}
+		case <-tick.C:
+			locks, err := workflow.LoadStaleProjectLocks(api.mustDB())
+			if err != nil {
+				log.Warning(ctx, "api.releaseStaleProjectLocks> %v", err)
+				continue
+			}
+			for _, l := range locks {
+				if err := api.releaseStaleProjectLock(ctx, l); err != nil {
+					log.Error(ctx, "api.releaseStaleProjectLocks> unable to release project lock %d: %v", l.ID, err)
+				}
+			}
+		}
+	}
+}
+
+// releaseStaleProjectLock releases a stale project lock, the next waiter is executed with the project
+// loaded like for any workflow run. The node run that holds an expired lock is stopped first.
+func (api *API) releaseStaleProjectLock(ctx context.Context, l workflow.StaleProjectLock) error {
+	p, err := project.LoadByID(api.mustDB(), api.Cache, l.ProjectID,
+		project.LoadOptions.WithVariables,
+		project.LoadOptions.WithFeatures,
+		project.LoadOptions.WithIntegrations,
+		project.LoadOptions.WithApplicationVariables,
+		project.LoadOptions.WithApplicationWithDeploymentStrategies,
+		project.LoadOptions.WithEnvironments,
+		project.LoadOptions.WithPipelines,
+	)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load project %s", l.ProjectKey)
+	}
+
+	if l.Held && !l.Terminated() {
+		if err := api.stopProjectLockHolder(ctx, p, l); err != nil {
+			return err
+		}
+	}
+
+	tx, err := api.mustDB().Begin()
+	if err != nil {
+		return sdk.WrapError(err, "cannot start transaction")
+	}
+	defer tx.Rollback() // nolint
+
+	report, err := workflow.ReleaseStaleProjectLock(ctx, tx, api.Cache, p, l)
+	if err != nil {
+		// The lock has been released by another API instance
+		if sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return sdk.WrapError(err, "cannot commit transaction")
+	}
+
+	go workflow.SendEvent(context.Background(), api.mustDB(), p.Key, report)
+
+	return nil
+}
+
+// stopProjectLockHolder stops the node run that holds an expired lock, stopping the node run releases the lock.
+func (api *API) stopProjectLockHolder(ctx context.Context, p *sdk.Project, l workflow.StaleProjectLock) error {
+	nodeRun, err := workflow.LoadNodeRunByID(api.mustDB(), l.WorkflowNodeRunID, workflow.LoadRunOptions{})
+	if err != nil {
+		return sdk.WrapError(err, "cannot load node run %d", l.WorkflowNodeRunID)
+	}
+	// The node run has already been stopped, ex: by another API instance
+	if sdk.StatusIsTerminated(nodeRun.Status) {
+		return nil
+	}
+
+	stopInfos := sdk.SpawnInfo{
+		APITime:    time.Now(),
+		RemoteTime: time.Now(),
+		Message:    sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeLockExpired.ID, Args: []interface{}{l.Name, l.NodeName}},
+	}
+	report, err := api.stopWorkflowNodeRun(ctx, api.mustDB, api.Cache, p, nodeRun, l.WorkflowName, stopInfos)
+	if err != nil {
+		return sdk.WrapError(err, "cannot stop node run %d holding lock %s", nodeRun.ID, l.Name)
+	}
+
+	go workflow.SendEvent(context.Background(), api.mustDB(), p.Key, report)
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_releaseStaleProjectLock(t *testing.T) {
+	api, db, _, end := newTestAPI(t)
+	defer end()
+
+	u, _ := assets.InsertAdminUser(t, db)
+	consumer, _ := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, api.Cache, proj, sdk.RandomString(10))
+	w.WorkflowData.Node.Context.Lock = "deploy"
+	require.NoError(t, workflow.Update(context.TODO(), db, api.Cache, w, proj, workflow.UpdateOptions{}))
+
+	startRun := func() *sdk.WorkflowRun {
+		w1, err := workflow.Load(context.TODO(), db, api.Cache, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+		require.NoError(t, err)
+		wr, err := workflow.CreateRun(db, w1, nil, u)
+		require.NoError(t, err)
+		wr.Workflow = *w1
+		_, err = workflow.StartWorkflowRun(context.TODO(), db, api.Cache, proj, wr, &sdk.WorkflowRunPostHandlerOption{Manual: &sdk.WorkflowNodeRunManual{}}, consumer, nil)
+		require.NoError(t, err)
+		return wr
+	}
+
+	// The second run waits for the lock held by the first one
+	wr1 := startRun()
+	wr2 := startRun()
+	locks, err := workflow.LoadProjectLocks(db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, locks, 2)
+	assert.True(t, locks[0].Held)
+	assert.Equal(t, wr1.ID, locks[0].WorkflowRunID)
+	assert.False(t, locks[1].Held)
+	assert.Equal(t, wr2.ID, locks[1].WorkflowRunID)
+
+	// An expired lock is released and given to the waiter
+	_, err = db.Exec("UPDATE project_lock SET since = $2 WHERE id = $1", locks[0].ID, time.Now().Add(-sdk.ProjectLockTimeout-time.Hour))
+	require.NoError(t, err)
+	stale, err := workflow.LoadStaleProjectLocks(db)
+	require.NoError(t, err)
+	var expired *workflow.StaleProjectLock
+	for i := range stale {
+		if stale[i].ID == locks[0].ID {
+			expired = &stale[i]
+		}
+	}
+	require.NotNil(t, expired)
+	require.NoError(t, api.releaseStaleProjectLock(context.TODO(), *expired))
+
+	locks, err = workflow.LoadProjectLocks(db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.True(t, locks[0].Held)
+	assert.Equal(t, wr2.ID, locks[0].WorkflowRunID)
+
+	// The node run that held the expired lock has been stopped
+	nodeRun, err := workflow.LoadNodeRunByID(db, expired.WorkflowNodeRunID, workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusStopped, nodeRun.Status)
+
+	// The lock is not released twice if another API instance has already released it
+	require.NoError(t, api.releaseStaleProjectLock(context.TODO(), *expired))
+	locks, err = workflow.LoadProjectLocks(db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.Equal(t, wr2.ID, locks[0].WorkflowRunID)
+}
+
+func Test_deleteProjectLockHandler(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+	owner, ownerPass := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+	other, otherPass := assets.InsertLambdaUser(t, db, &proj.ProjectGroups[0].Group)
+	w := assets.InsertTestWorkflow(t, db, api.Cache, proj, sdk.RandomString(10))
+	w.WorkflowData.Node.Context.Lock = "deploy"
+	require.NoError(t, workflow.Update(context.TODO(), db, api.Cache, w, proj, workflow.UpdateOptions{}))
+
+	startRun := func(u *sdk.AuthentifiedUser) *sdk.WorkflowRun {
+		consumer, err := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+		require.NoError(t, err)
+		w1, err := workflow.Load(context.TODO(), db, api.Cache, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+		require.NoError(t, err)
+		wr, err := workflow.CreateRun(db, w1, nil, u)
+		require.NoError(t, err)
+		wr.Workflow = *w1
+		_, err = workflow.StartWorkflowRun(context.TODO(), db, api.Cache, proj, wr, &sdk.WorkflowRunPostHandlerOption{Manual: &sdk.WorkflowNodeRunManual{}}, consumer, nil)
+		require.NoError(t, err)
+		return wr
+	}
+
+	// The first run holds the lock, the second one waits for it
+	wr1 := startRun(owner)
+	wr2 := startRun(other)
+
+	uri := router.GetRoute("DELETE", api.deleteProjectLockHandler, map[string]string{
+		"permProjectKey": proj.Key,
+		"lockName":       "deploy",
+	})
+	require.NotEmpty(t, uri)
+
+	// A user with write permission on the project that didn't trigger the holder can't release the lock
+	req := assets.NewAuthentifiedRequest(t, other, otherPass, "DELETE", uri, nil)
+	rec := httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	locks, err := workflow.LoadProjectLocks(db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, locks, 2)
+	assert.Equal(t, wr1.ID, locks[0].WorkflowRunID)
+
+	// The user that triggered the holder can release the lock, the waiter acquires it
+	req = assets.NewAuthentifiedRequest(t, owner, ownerPass, "DELETE", uri, nil)
+	rec = httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	locks, err = workflow.LoadProjectLocks(db, proj.ID)
+	require.NoError(t, err)
+	require.Len(t, locks, 1)
+	assert.True(t, locks[0].Held)
+	assert.Equal(t, wr2.ID, locks[0].WorkflowRunID)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/metrics"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+// defaultDeliveryMetricsWindow is the time window used to compute delivery metrics when no date is given.
+const defaultDeliveryMetricsWindow = 30 * 24 * time.Hour
+
+func (api *API) getProjectDeliveryMetricsHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+
+		to := time.Now()
+		if s := r.FormValue("to"); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid date %s, expected format is RFC3339", s)
+			}
+			to = t
+		}
+		from := to.Add(-defaultDeliveryMetricsWindow)
+		if s := r.FormValue("from"); s != "" {
+			t, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid date %s, expected format is RFC3339", s)
+			}
+			from = t
+		}
+		if !from.Before(to) {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "from date should be before to date")
+		}
+
+		var period time.Duration
+		if s := r.FormValue("period"); s != "" {
+			days, err := strconv.Atoi(s)
+			if err != nil || days <= 0 {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid period %s, expected a number of days", s)
+			}
+			period = time.Duration(days) * 24 * time.Hour
+		}
+
+		deployments, err := metrics.LoadDeployments(ctx, api.mustDB(), key, r.FormValue("workflow"), r.FormValue("environment"), from, to)
+		if err != nil {
+			return err
+		}
+
+		// Only count the deployments of tagged versions
+		if tagged, _ := strconv.ParseBool(r.FormValue("tagged")); tagged {
+			filtered := deployments[:0]
+			for _, d := range deployments {
+				if d.VCSTag != "" {
+					filtered = append(filtered, d)
+				}
+			}
+			deployments = filtered
+		}
+
+		res := metrics.ComputeDeliveryMetrics(deployments, from, to, period)
+		if res == nil {
+			res = []sdk.DeliveryMetrics{}
+		}
+		return service.WriteJSON(w, res, http.StatusOK)
+	}
+}
@@ -36,6 +36,11 @@ func Initialize(ctx context.Context, store cache.Store, DBFunc func() *gorp.DbMa
 				log.Warning(ctx, "purge> Error on deleteWorkflowRunsHistory : %v", err)
 			}
 
+			log.Debug("purge> Applying workflow retention policies...")
+			if err := retentionPolicies(ctx, DBFunc(), workflowRunsMarkToDelete); err != nil {
+				log.Warning(ctx, "purge> Error on retentionPolicies : %v", err)
+			}
+
 			log.Debug("purge> Deleting all workflow marked to delete....")
 			if err := workflows(ctx, DBFunc(), store, workflowRunsMarkToDelete); err != nil {
 				log.Warning(ctx, "purge> Error on workflows : %v", err)
@@ -146,7 +151,7 @@ func workflows(ctx context.Context, db *gorp.DbMap, store cache.Store, workflowR
 }
 
 // deleteWorkflowRunsHistory is useful to delete all the workflow run marked with to delete flag in db
-func deleteWorkflowRunsHistory(ctx context.Context, db gorp.SqlExecutor, store cache.Store, sharedStorage objectstore.Driver, workflowRunsDeleted *stats.Int64Measure) error {
+func deleteWorkflowRunsHistory(ctx context.Context, db *gorp.DbMap, store cache.Store, sharedStorage objectstore.Driver, workflowRunsDeleted *stats.Int64Measure) error {
 	var workflowRunIDs []int64
 	if _, err := db.Select(&workflowRunIDs, "SELECT id FROM workflow_run WHERE to_delete = true ORDER BY id ASC LIMIT 2000"); err != nil {
 		return err
@@ -158,6 +163,11 @@ func deleteWorkflowRunsHistory(ctx context.Context, db gorp.SqlExecutor, store c
 			continue
 		}
 
+		if err := workflow.GetLogStore().DeleteRunLogs(ctx, db, workflowRunID); err != nil {
+			log.Error(ctx, "deleteWorkflowRunsHistory> error while deleting logs: %v", err)
+			continue
+		}
+
 		res, err := db.Exec("DELETE FROM workflow_run WHERE workflow_run.id = $1", workflowRunID)
 		if err != nil {
 			log.Error(ctx, "deleteWorkflowRunsHistory> unable to delete workflow run %d: %v", workflowRunID, err)
@@ -172,8 +182,9 @@ func deleteWorkflowRunsHistory(ctx context.Context, db gorp.SqlExecutor, store c
 	return nil
 }
 
-// DeleteArtifacts removes artifacts from storage
-func DeleteArtifacts(ctx context.Context, db gorp.SqlExecutor, store cache.Store, sharedStorage objectstore.Driver, workflowRunID int64) error {
+// DeleteArtifacts removes artifacts from storage, artifacts stored by content remove
+// their reference on the blob which is deleted with its last reference.
+func DeleteArtifacts(ctx context.Context, db *gorp.DbMap, store cache.Store, sharedStorage objectstore.Driver, workflowRunID int64) error {
 	wr, err := workflow.LoadRunByID(db, workflowRunID, workflow.LoadRunOptions{WithArtifacts: true, DisableDetailledNodeRun: false, WithDeleted: true})
 	if err != nil {
 		return sdk.WrapError(err, "error on load LoadRunByID:%d", workflowRunID)
@@ -206,6 +217,19 @@ func DeleteArtifacts(ctx context.Context, db gorp.SqlExecutor, store cache.Store
 					integrationName = sdk.DefaultStorageIntegrationName
 				}
 
+				storageDriver, err := objectstore.GetDriver(ctx, db, sharedStorage, proj.Key, integrationName)
+				if err != nil {
+					log.Error(ctx, "error while getting driver prj:%v integrationName:%v err:%v", proj.Key, integrationName, err)
+					continue
+				}
+
+				if art.BlobSHA256 != "" {
+					if err := deleteArtifactBlobReference(ctx, db, storageDriver, &art); err != nil {
+						log.Error(ctx, "error while deleting blob reference prj:%v wnr:%v name:%v err:%v", proj.Key, wnr.ID, art.Name, err)
+					}
+					continue
+				}
+
 				var found bool
 				for _, dc := range driversContainers {
 					if dc.containerPath == art.GetPath() && proj.Key == dc.projectKey && integrationName == dc.integrationName {
@@ -223,12 +247,6 @@ func DeleteArtifacts(ctx context.Context, db gorp.SqlExecutor, store cache.Store
 					})
 				}
 
-				storageDriver, err := objectstore.GetDriver(ctx, db, sharedStorage, proj.Key, integrationName)
-				if err != nil {
-					log.Error(ctx, "error while getting driver prj:%v integrationName:%v err:%v", proj.Key, integrationName, err)
-					continue
-				}
-
 				log.Debug("DeleteArtifacts> deleting %+v", art)
 				if err := storageDriver.Delete(ctx, &art); err != nil {
 					log.Error(ctx, "error while deleting container prj:%v wnr:%v name:%v err:%v", proj.Key, wnr.ID, art.GetPath(), err)
@@ -253,3 +271,22 @@ func DeleteArtifacts(ctx context.Context, db gorp.SqlExecutor, store cache.Store
 
 	return nil
 }
+
+// deleteArtifactBlobReference removes the reference of an artifact on its blob in a dedicated transaction.
+// The artifact is deleted in the same transaction, so its reference will not be removed again if the
+// deletion of the workflow run fails and is retried by the next purge.
+func deleteArtifactBlobReference(ctx context.Context, db *gorp.DbMap, storageDriver objectstore.Driver, art *sdk.WorkflowNodeRunArtifact) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer tx.Rollback() // nolint
+
+	if err := workflow.DeleteArtifactData(ctx, tx, storageDriver, art); err != nil {
+		return err
+	}
+	if err := workflow.DeleteArtifact(tx, art.ID); err != nil {
+		return err
+	}
+	return sdk.WithStack(tx.Commit())
+}
@@ -0,0 +1,95 @@
+package purge
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"go.opencensus.io/stats"
+
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// retentionPolicies marks to delete the workflow runs that are not kept by the retention policy of their workflow.
+func retentionPolicies(ctx context.Context, db *gorp.DbMap, workflowRunsMarkToDelete *stats.Int64Measure) error {
+	policies, err := workflow.LoadRetentionPolicies(db)
+	if err != nil {
+		return err
+	}
+
+	for workflowID, p := range policies {
+		if p.Policy.IsEmpty() {
+			continue
+		}
+		runs, err := ApplyRetentionPolicy(ctx, db, workflowID, p.Policy, p.HistoryLength, false)
+		if err != nil {
+			log.Error(ctx, "purge.retentionPolicies> unable to apply retention policy on workflow %d: %v", workflowID, err)
+			continue
+		}
+		if len(runs) > 0 && workflowRunsMarkToDelete != nil {
+			observability.Record(ctx, workflowRunsMarkToDelete, int64(len(runs)))
+		}
+	}
+
+	return nil
+}
+
+// ApplyRetentionPolicy marks to delete the runs of a workflow that are not kept by the given retention policy
+// and returns them. The history length of the workflow is used when the policy has no per branch rule.
+// With dry run, nothing is marked and the artifacts of each run are returned.
+func ApplyRetentionPolicy(ctx context.Context, db gorp.SqlExecutor, workflowID int64, policy sdk.WorkflowRetentionPolicy, historyLength int64, dryRun bool) ([]sdk.WorkflowRunPurge, error) {
+	runs, err := workflow.LoadRunsRetention(db, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedBranches, err := workflow.LoadDeletedBranches(db, workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	toDelete := policy.Apply(runs, deletedBranches, historyLength, time.Now())
+	res := make([]sdk.WorkflowRunPurge, len(toDelete))
+	ids := make([]int64, len(toDelete))
+	for i, r := range toDelete {
+		ids[i] = r.ID
+		res[i] = sdk.WorkflowRunPurge{
+			ID:           r.ID,
+			Number:       r.Number,
+			Status:       r.Status,
+			Branch:       r.Branch(),
+			LastModified: r.LastModified.Format(time.RFC3339),
+		}
+	}
+	if len(toDelete) == 0 {
+		return res, nil
+	}
+
+	if !dryRun {
+		log.Info(ctx, "purge.ApplyRetentionPolicy> will delete %d workflow runs for workflow %d", len(ids), workflowID)
+		if err := workflow.MarkWorkflowRunsAsDelete(db, ids); err != nil {
+			return nil, err
+		}
+		return res, nil
+	}
+
+	arts, err := workflow.LoadArtifactsByRunIDs(db, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range res {
+		for _, a := range arts {
+			if a.WorkflowID != res[i].ID { // WorkflowID holds the workflow run id
+				continue
+			}
+			res[i].NbArtifacts++
+			res[i].ArtifactsSize += a.Size
+			res[i].Artifacts = append(res[i].Artifacts, a.Name)
+		}
+	}
+
+	return res, nil
+}
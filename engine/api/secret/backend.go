@@ -0,0 +1,99 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	vault "github.com/hashicorp/vault/api"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// GetFieldFromVault returns the value of a field of the secret stored at given path.
+// Secrets from a KV version 2 engine contains their fields under the data key.
+func (secret *Secret) GetFieldFromVault(path, field string) (string, error) {
+	s, err := secret.Client.Logical().Read(path)
+	if err != nil {
+		return "", sdk.WrapError(err, "unable to read vault secret %s", path)
+	}
+	if s == nil {
+		return "", sdk.NewErrorFrom(sdk.ErrNotFound, "no vault secret found at %s", path)
+	}
+
+	data := s.Data
+	if d, ok := s.Data["data"].(map[string]interface{}); ok {
+		data = d
+	}
+	value, ok := data[field]
+	if !ok || value == nil {
+		return "", sdk.NewErrorFrom(sdk.ErrNotFound, "no field %s found in vault secret %s", field, path)
+	}
+
+	return fmt.Sprintf("%v", value), nil
+}
+
+// newBackendClient returns a Vault client whose requests end with the deadline of the context.
+func newBackendClient(ctx context.Context, token, addr string) (*Secret, error) {
+	cfg := vault.DefaultConfig()
+	if deadline, ok := ctx.Deadline(); ok {
+		cfg.HttpClient.Timeout = time.Until(deadline)
+	}
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(token)
+	client.SetAddress(addr)
+	return &Secret{
+		Client: client,
+		Token:  token,
+	}, nil
+}
+
+// ResolveBackendSecrets replaces the values of the variables that reference a Vault secret with the secret values
+// read from the secret backend integration of the project. Resolved values are never stored.
+func ResolveBackendSecrets(ctx context.Context, integrations []sdk.ProjectIntegration, vars []sdk.Variable) error {
+	var backend *sdk.ProjectIntegration
+	var client *Secret
+	for i := range vars {
+		if !sdk.IsVaultReference(vars[i].Value) {
+			continue
+		}
+
+		path, field, err := sdk.ParseVaultReference(vars[i].Value)
+		if err != nil {
+			return err
+		}
+
+		if client == nil {
+			for j := range integrations {
+				if !integrations[j].Model.SecretBackend || integrations[j].Model.Name != sdk.VaultIntegrationModel {
+					continue
+				}
+				if backend != nil {
+					return sdk.NewErrorFrom(sdk.ErrWrongRequest, "too many vault integrations on project, unable to resolve variable %s", vars[i].Name)
+				}
+				backend = &integrations[j]
+			}
+			if backend == nil {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "no vault integration found on project, unable to resolve variable %s", vars[i].Name)
+			}
+
+			client, err = newBackendClient(ctx, backend.Config["token"].Value, backend.Config["address"].Value)
+			if err != nil {
+				return sdk.WrapError(err, "unable to create vault client for integration %s", backend.Name)
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return sdk.WrapError(err, "unable to resolve variable %s", vars[i].Name)
+		}
+		value, err := client.GetFieldFromVault(path, field)
+		if err != nil {
+			return sdk.WrapError(err, "unable to resolve variable %s", vars[i].Name)
+		}
+		vars[i].Value = value
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestResolveBackendSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "my-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/myapp":
+			_, _ = w.Write([]byte(`{"data": {"data": {"db_password": "s3cr3t"}, "metadata": {"version": 1}}}`))
+		case "/v1/kv/myapp":
+			_, _ = w.Write([]byte(`{"data": {"api_key": "my-api-key"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	integrations := []sdk.ProjectIntegration{
+		{
+			Name:  "my-vault",
+			Model: sdk.VaultIntegration,
+			Config: sdk.IntegrationConfig{
+				"address": {Value: srv.URL},
+				"token":   {Value: "my-token"},
+			},
+		},
+	}
+
+	vars := []sdk.Variable{
+		{Name: "cds.proj.db_password", Type: sdk.SecretVariable, Value: "vault://secret/data/myapp#db_password"},
+		{Name: "cds.app.api_key", Type: sdk.SecretVariable, Value: "vault://kv/myapp#api_key"},
+		{Name: "cds.env.other", Type: sdk.SecretVariable, Value: "not-in-vault"},
+	}
+	require.NoError(t, ResolveBackendSecrets(context.TODO(), integrations, vars))
+	assert.Equal(t, "s3cr3t", vars[0].Value)
+	assert.Equal(t, "my-api-key", vars[1].Value)
+	assert.Equal(t, "not-in-vault", vars[2].Value)
+
+	err := ResolveBackendSecrets(context.TODO(), integrations, []sdk.Variable{{Name: "unknown", Value: "vault://secret/data/myapp#unknown"}})
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+
+	err = ResolveBackendSecrets(context.TODO(), nil, []sdk.Variable{{Name: "no-backend", Value: "vault://secret/data/myapp#db_password"}})
+	assert.Error(t, err)
+
+	_, _, err = sdk.ParseVaultReference("vault://secret/data/myapp")
+	assert.Error(t, err)
+}
+
+func TestResolveBackendSecretsTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+		_, _ = w.Write([]byte(`{"data": {"api_key": "my-api-key"}}`))
+	}))
+	defer srv.Close()
+
+	integrations := []sdk.ProjectIntegration{
+		{
+			Name:  "my-vault",
+			Model: sdk.VaultIntegration,
+			Config: sdk.IntegrationConfig{
+				"address": {Value: srv.URL},
+				"token":   {Value: "my-token"},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	vars := []sdk.Variable{{Name: "cds.app.api_key", Type: sdk.SecretVariable, Value: "vault://kv/myapp#api_key"}}
+	assert.Error(t, ResolveBackendSecrets(ctx, integrations, vars))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, "vault://kv/myapp#api_key", vars[0].Value)
+}
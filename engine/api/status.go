@@ -12,8 +12,10 @@ import (
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 
+	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/engine/api/event"
 	"github.com/ovh/cds/engine/api/mail"
+	"github.com/ovh/cds/engine/api/metrics"
 	"github.com/ovh/cds/engine/api/migrate"
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/engine/api/services"
@@ -77,6 +79,7 @@ var (
 	tagServiceName tag.Key
 	tagService     tag.Key
 	tagsService    []tag.Key
+	tagsDelivery   []tag.Key
 )
 
 // computeGlobalStatus returns global status
@@ -224,6 +227,23 @@ func (api *API) initMetrics(ctx context.Context) error {
 		"number database connections",
 		stats.UnitDimensionless)
 
+	api.Metrics.DeploymentFrequency = stats.Float64(
+		fmt.Sprintf("cds/cds-api/%s/delivery_deployment_frequency", api.Name()),
+		"number of successful deployments per day",
+		stats.UnitDimensionless)
+	api.Metrics.LeadTime = stats.Int64(
+		fmt.Sprintf("cds/cds-api/%s/delivery_lead_time", api.Name()),
+		"median time in seconds between a commit and its deployment",
+		stats.UnitDimensionless)
+	api.Metrics.ChangeFailureRate = stats.Float64(
+		fmt.Sprintf("cds/cds-api/%s/delivery_change_failure_rate", api.Name()),
+		"ratio of failed deployments",
+		stats.UnitDimensionless)
+	api.Metrics.MTTR = stats.Int64(
+		fmt.Sprintf("cds/cds-api/%s/delivery_mttr", api.Name()),
+		"mean time in seconds to restore a failed deployment",
+		stats.UnitDimensionless)
+
 	tagRange, _ = tag.NewKey("range")
 	tagStatus, _ = tag.NewKey("status")
 
@@ -231,6 +251,11 @@ func (api *API) initMetrics(ctx context.Context) error {
 	tagServiceName := observability.MustNewKey(observability.TagServiceName)
 	tagsRange := []tag.Key{tagRange, tagStatus}
 	tagsService = []tag.Key{tagServiceName, tagServiceType}
+	tagsDelivery = []tag.Key{
+		observability.MustNewKey(observability.TagProjectKey),
+		observability.MustNewKey(observability.TagWorkflow),
+		observability.MustNewKey(observability.TagEnvironment),
+	}
 
 	err := observability.RegisterView(
 		observability.NewViewLast("cds/nb_users", api.Metrics.nbUsers, nil),
@@ -250,9 +275,14 @@ func (api *API) initMetrics(ctx context.Context) error {
 		observability.NewViewCount("cds/workflow_runs_mark_to_delete", api.Metrics.WorkflowRunsMarkToDelete, tagsService),
 		observability.NewViewCount("cds/workflow_runs_deleted", api.Metrics.WorkflowRunsDeleted, tagsService),
 		observability.NewViewLast("cds/database_conn", api.Metrics.DatabaseConns, tagsService),
+		observability.NewViewLastFloat64("cds/delivery/deployment_frequency", api.Metrics.DeploymentFrequency, tagsDelivery),
+		observability.NewViewLast("cds/delivery/lead_time", api.Metrics.LeadTime, tagsDelivery),
+		observability.NewViewLastFloat64("cds/delivery/change_failure_rate", api.Metrics.ChangeFailureRate, tagsDelivery),
+		observability.NewViewLast("cds/delivery/mttr", api.Metrics.MTTR, tagsDelivery),
 	)
 
 	api.computeMetrics(ctx)
+	api.computeDeliveryMetrics(ctx)
 
 	return err
 }
@@ -309,6 +339,78 @@ func (api *API) computeMetrics(ctx context.Context) {
 	})
 }
 
+// deliveryMetricsInterval is the interval between two computations of the delivery metrics.
+const deliveryMetricsInterval = 10 * time.Minute
+
+// computeDeliveryMetrics records the delivery metrics of each workflow and environment over the last 30 days.
+func (api *API) computeDeliveryMetrics(ctx context.Context) {
+	sdk.GoRoutine(ctx, "api.computeDeliveryMetrics", func(ctx context.Context) {
+		tick := time.NewTicker(deliveryMetricsInterval).C
+		for {
+			select {
+			case <-ctx.Done():
+				if ctx.Err() != nil {
+					log.Error(ctx, "Exiting api.computeDeliveryMetrics: %v", ctx.Err())
+					return
+				}
+			case <-tick:
+				ms, err := api.loadDeliveryMetrics(ctx)
+				if err != nil {
+					log.Warning(ctx, "metrics> unable to load delivery metrics: %v", err)
+					continue
+				}
+				for _, m := range ms {
+					if m.WorkflowName == "" {
+						continue
+					}
+					ctx, _ := tag.New(ctx,
+						tag.Upsert(tagsDelivery[0], m.ProjectKey),
+						tag.Upsert(tagsDelivery[1], m.WorkflowName),
+						tag.Upsert(tagsDelivery[2], m.EnvironmentName),
+					)
+					observability.RecordFloat64(ctx, api.Metrics.DeploymentFrequency, m.DeploymentFrequency)
+					observability.Record(ctx, api.Metrics.LeadTime, m.LeadTime)
+					observability.RecordFloat64(ctx, api.Metrics.ChangeFailureRate, m.ChangeFailureRate)
+					observability.Record(ctx, api.Metrics.MTTR, m.MTTR)
+				}
+			}
+		}
+	})
+}
+
+// loadDeliveryMetrics returns the delivery metrics shared by all the API instances. Only the instance that takes
+// the lock loads the deployments, the others read the last metrics stored in cache.
+func (api *API) loadDeliveryMetrics(ctx context.Context) ([]sdk.DeliveryMetrics, error) {
+	metricsKey := cache.Key("api", "metrics", "delivery")
+	lockKey := cache.Key(metricsKey, "lock")
+	locked, err := api.Cache.Lock(lockKey, deliveryMetricsInterval-time.Minute, 0, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []sdk.DeliveryMetrics
+	if !locked {
+		// The metrics computed by another instance are recorded, there is none until its first computation
+		if _, err := api.Cache.Get(metricsKey, &ms); err != nil {
+			return nil, err
+		}
+		return ms, nil
+	}
+
+	to := time.Now()
+	from := to.Add(-defaultDeliveryMetricsWindow)
+	deployments, err := metrics.LoadDeployments(ctx, api.mustDB(), "", "", "", from, to)
+	if err != nil {
+		_ = api.Cache.Unlock(lockKey)
+		return nil, err
+	}
+	ms = metrics.ComputeDeliveryMetrics(deployments, from, to, 0)
+	if err := api.Cache.SetWithDuration(metricsKey, ms, 2*deliveryMetricsInterval); err != nil {
+		return nil, err
+	}
+	return ms, nil
+}
+
 func (api *API) countMetric(ctx context.Context, v *stats.Int64Measure, query string) {
 	n, err := api.mustDB().SelectInt(query)
 	if err != nil {
@@ -110,6 +110,9 @@ func (api *API) postTemplateHandler() service.Handler {
 		}
 
 		// execute template with no instance only to check if parsing is ok
+		if err := workflowtemplate.LoadIncludes(ctx, api.mustDB(), &data); err != nil {
+			return err
+		}
 		if _, err := workflowtemplate.Execute(&data, nil); err != nil {
 			return err
 		}
@@ -233,19 +236,46 @@ func (api *API) putTemplateHandler() service.Handler {
 		clone.Update(data)
 
 		// execute template with no instance only to check if parsing is ok
+		if err := workflowtemplate.LoadIncludes(ctx, api.mustDB(), &clone); err != nil {
+			return err
+		}
 		if _, err := workflowtemplate.Execute(&clone, nil); err != nil {
 			return err
 		}
 
-		if err := workflowtemplate.Update(api.mustDB(), &clone); err != nil {
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		if err := workflowtemplate.Update(tx, &clone); err != nil {
 			return err
 		}
 
-		newTemplate, err := workflowtemplate.LoadByID(ctx, api.mustDB(), clone.ID, workflowtemplate.LoadOptions.Default)
+		newTemplate, err := workflowtemplate.LoadByID(ctx, tx, clone.ID, workflowtemplate.LoadOptions.Default)
 		if err != nil {
 			return err
 		}
 
+		// templates that include the updated template should belong to its group, so it can only be renamed
+		if newTemplate.GroupID != old.GroupID {
+			if err := workflowtemplate.CheckNotIncluded(ctx, tx, old); err != nil {
+				return err
+			}
+		} else if err := workflowtemplate.RenameIncludes(ctx, tx, old.Path(), newTemplate.Path()); err != nil {
+			return err
+		}
+
+		// templates that include the updated template are now out of date
+		if err := workflowtemplate.UpdateDependents(ctx, tx, newTemplate, getAPIConsumer(ctx)); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+
 		event.PublishWorkflowTemplateUpdate(ctx, *old, *newTemplate, data.ChangeMessage, getAPIConsumer(ctx))
 
 		if err := workflowtemplate.LoadOptions.WithAudits(ctx, api.mustDB(), newTemplate); err != nil {
@@ -271,11 +301,16 @@ func (api *API) deleteTemplateHandler() service.Handler {
 			return err
 		}
 
-		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID)
+		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID, workflowtemplate.LoadOptions.Default)
 		if err != nil {
 			return err
 		}
 
+		// templates that include this template could not be applied without it
+		if err := workflowtemplate.CheckNotIncluded(ctx, api.mustDB(), wt); err != nil {
+			return err
+		}
+
 		if err := workflowtemplate.Delete(api.mustDB(), wt); err != nil {
 			return err
 		}
@@ -284,7 +319,7 @@ func (api *API) deleteTemplateHandler() service.Handler {
 	}
 }
 
-func (api *API) applyTemplate(ctx context.Context, u sdk.Identifiable, p *sdk.Project, wt *sdk.WorkflowTemplate, req sdk.WorkflowTemplateRequest) (sdk.WorkflowTemplateResult, error) {
+func (api *API) applyTemplate(ctx context.Context, u sdk.Identifiable, p *sdk.Project, wt *sdk.WorkflowTemplate, req sdk.WorkflowTemplateRequest, version string) (sdk.WorkflowTemplateResult, error) {
 	var result sdk.WorkflowTemplateResult
 
 	tx, err := api.mustDB().Begin()
@@ -293,6 +328,25 @@ func (api *API) applyTemplate(ctx context.Context, u sdk.Identifiable, p *sdk.Pr
 	}
 	defer func() { _ = tx.Rollback() }()
 
+	// if a version or a version range is given, apply the matching release instead of the current template
+	tmpl := wt
+	var releaseVersion string
+	if version != "" || req.VersionRange != "" {
+		rs, err := workflowtemplate.LoadReleasesByTemplateID(ctx, tx, wt.ID)
+		if err != nil {
+			return result, err
+		}
+		r, err := rs.Resolve(version, req.VersionRange)
+		if err != nil {
+			return result, err
+		}
+		t := wt.WithRelease(*r)
+		tmpl = &t
+		releaseVersion = r.Version
+	} else if err := workflowtemplate.LoadIncludes(ctx, tx, tmpl); err != nil {
+		return result, err
+	}
+
 	var wti *sdk.WorkflowTemplateInstance
 	// try to get a instance not assign to a workflow but with the same slug
 	wtis, err := workflowtemplate.GetInstancesByTemplateIDAndProjectIDAndRequestWorkflowName(tx, wt.ID, p.ID, req.WorkflowName)
@@ -324,8 +378,12 @@ func (api *API) applyTemplate(ctx context.Context, u sdk.Identifiable, p *sdk.Pr
 	if wti != nil {
 		clone := sdk.WorkflowTemplateInstance(*wti)
 		old = &clone
-		wti.WorkflowTemplateVersion = wt.Version
+		wti.WorkflowTemplateVersion = tmpl.Version
 		wti.Request = req
+		if wti.ReleaseVersion != releaseVersion {
+			wti.PreviousReleaseVersion = wti.ReleaseVersion
+			wti.ReleaseVersion = releaseVersion
+		}
 		if err := workflowtemplate.UpdateInstance(tx, wti); err != nil {
 			return result, err
 		}
@@ -333,8 +391,9 @@ func (api *API) applyTemplate(ctx context.Context, u sdk.Identifiable, p *sdk.Pr
 		wti = &sdk.WorkflowTemplateInstance{
 			ProjectID:               p.ID,
 			WorkflowTemplateID:      wt.ID,
-			WorkflowTemplateVersion: wt.Version,
+			WorkflowTemplateVersion: tmpl.Version,
 			Request:                 req,
+			ReleaseVersion:          releaseVersion,
 		}
 
 		// only store the new instance if request is not for a detached workflow
@@ -349,7 +408,7 @@ func (api *API) applyTemplate(ctx context.Context, u sdk.Identifiable, p *sdk.Pr
 	}
 
 	// execute template with request
-	result, err = workflowtemplate.Execute(wt, wti)
+	result, err = workflowtemplate.Execute(tmpl, wti)
 	if err != nil {
 		return result, err
 	}
@@ -451,7 +510,7 @@ func (api *API) postTemplateApplyHandler() service.Handler {
 			return err
 		}
 
-		res, err := api.applyTemplate(ctx, getAPIConsumer(ctx), p, wt, req)
+		res, err := api.applyTemplate(ctx, getAPIConsumer(ctx), p, wt, req, "")
 		if err != nil {
 			return err
 		}
@@ -549,79 +608,7 @@ func (api *API) postTemplateBulkHandler() service.Handler {
 
 		// start async bulk tasks
 		sdk.GoRoutine(context.Background(), "api.templateBulkApply", func(ctx context.Context) {
-			for i := range bulk.Operations {
-				if bulk.Operations[i].Status == sdk.OperationStatusPending {
-					bulk.Operations[i].Status = sdk.OperationStatusProcessing
-					if err := workflowtemplate.UpdateBulk(api.mustDB(), &bulk); err != nil {
-						log.Error(ctx, "%v", err)
-						return
-					}
-
-					errorDefer := func(err error) error {
-						if err != nil {
-							bulk.Operations[i].Status = sdk.OperationStatusError
-							bulk.Operations[i].Error = fmt.Sprintf("%s", sdk.Cause(err))
-							if err := workflowtemplate.UpdateBulk(api.mustDB(), &bulk); err != nil {
-								return err
-							}
-						}
-
-						return nil
-					}
-
-					// load project with key
-					p, err := project.Load(api.mustDB(), api.Cache, bulk.Operations[i].Request.ProjectKey,
-						project.LoadOptions.WithGroups,
-						project.LoadOptions.WithApplications,
-						project.LoadOptions.WithEnvironments,
-						project.LoadOptions.WithPipelines,
-						project.LoadOptions.WithApplicationWithDeploymentStrategies,
-						project.LoadOptions.WithIntegrations)
-					if err != nil {
-						if errD := errorDefer(err); errD != nil {
-							log.Error(ctx, "%v", errD)
-							return
-						}
-						continue
-					}
-
-					// apply and import workflow
-					res, err := api.applyTemplate(ctx, consumer, p, wt, bulk.Operations[i].Request)
-					if err != nil {
-						if errD := errorDefer(err); errD != nil {
-							log.Error(ctx, "%v", errD)
-							return
-						}
-						continue
-					}
-
-					buf := new(bytes.Buffer)
-					if err := workflowtemplate.Tar(ctx, wt, res, buf); err != nil {
-						if errD := errorDefer(err); errD != nil {
-							log.Error(ctx, "%v", errD)
-							return
-						}
-						continue
-					}
-
-					tr := tar.NewReader(buf)
-
-					_, _, err = workflow.Push(ctx, api.mustDB(), api.Cache, p, tr, nil, consumer, project.DecryptWithBuiltinKey)
-					if err != nil {
-						if errD := errorDefer(sdk.WrapError(err, "cannot push generated workflow")); errD != nil {
-							log.Error(ctx, "%v", errD)
-							return
-						}
-						continue
-					}
-
-					bulk.Operations[i].Status = sdk.OperationStatusDone
-					if err := workflowtemplate.UpdateBulk(api.mustDB(), &bulk); err != nil {
-						log.Error(ctx, "%v", err)
-						return
-					}
-				}
-			}
+			api.runTemplateBulk(ctx, consumer, wt, &bulk)
 		})
 
 		// returns created bulk
@@ -629,6 +616,68 @@ func (api *API) postTemplateBulkHandler() service.Handler {
 	}
 }
 
+// runTemplateBulk applies the template for each pending operation of the bulk then pushes the generated workflows.
+func (api *API) runTemplateBulk(ctx context.Context, consumer *sdk.AuthConsumer, wt *sdk.WorkflowTemplate, bulk *sdk.WorkflowTemplateBulk) {
+	for i := range bulk.Operations {
+		if bulk.Operations[i].Status == sdk.OperationStatusPending {
+			bulk.Operations[i].Status = sdk.OperationStatusProcessing
+			if err := workflowtemplate.UpdateBulk(api.mustDB(), bulk); err != nil {
+				log.Error(ctx, "%v", err)
+				return
+			}
+
+			if _, err := api.applyTemplateAndPush(ctx, consumer, wt, bulk.Operations[i].Request, bulk.Operations[i].Version); err != nil {
+				bulk.Operations[i].Status = sdk.OperationStatusError
+				bulk.Operations[i].Error = fmt.Sprintf("%s", sdk.Cause(err))
+				if err := workflowtemplate.UpdateBulk(api.mustDB(), bulk); err != nil {
+					log.Error(ctx, "%v", err)
+					return
+				}
+				continue
+			}
+
+			bulk.Operations[i].Status = sdk.OperationStatusDone
+			if err := workflowtemplate.UpdateBulk(api.mustDB(), bulk); err != nil {
+				log.Error(ctx, "%v", err)
+				return
+			}
+		}
+	}
+}
+
+// applyTemplateAndPush applies the template with given request then pushes the generated workflow on the project.
+func (api *API) applyTemplateAndPush(ctx context.Context, consumer *sdk.AuthConsumer, wt *sdk.WorkflowTemplate, req sdk.WorkflowTemplateRequest, version string) ([]sdk.Message, error) {
+	// load project with key
+	p, err := project.Load(api.mustDB(), api.Cache, req.ProjectKey,
+		project.LoadOptions.WithGroups,
+		project.LoadOptions.WithApplications,
+		project.LoadOptions.WithEnvironments,
+		project.LoadOptions.WithPipelines,
+		project.LoadOptions.WithApplicationWithDeploymentStrategies,
+		project.LoadOptions.WithIntegrations)
+	if err != nil {
+		return nil, err
+	}
+
+	// apply and import workflow
+	res, err := api.applyTemplate(ctx, consumer, p, wt, req, version)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := workflowtemplate.Tar(ctx, wt, res, buf); err != nil {
+		return nil, err
+	}
+
+	msgs, _, err := workflow.Push(ctx, api.mustDB(), api.Cache, p, tar.NewReader(buf), nil, consumer, project.DecryptWithBuiltinKey)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot push generated workflow")
+	}
+
+	return msgs, nil
+}
+
 func (api *API) getTemplateBulkHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		id, _ := requestVarInt(r, "bulkID") // ignore error, will check if not 0
@@ -874,11 +923,21 @@ func (api *API) postTemplatePushHandler() service.Handler {
 			return err
 		}
 
-		msgs, err := workflowtemplate.Push(ctx, api.mustDB(), &wt, getAPIConsumer(ctx))
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		msgs, err := workflowtemplate.Push(ctx, tx, &wt, getAPIConsumer(ctx))
 		if err != nil {
 			return sdk.WrapError(err, "cannot push template")
 		}
 
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+
 		w.Header().Add(sdk.ResponseTemplateGroupNameHeader, wt.Group.Name)
 		w.Header().Add(sdk.ResponseTemplateSlugHeader, wt.Slug)
 
@@ -0,0 +1,468 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/api/workflowtemplate"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getTemplateReleasesHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		groupName := vars["permGroupName"]
+		templateSlug := vars["permTemplateSlug"]
+
+		g, err := group.LoadByName(ctx, api.mustDB(), groupName)
+		if err != nil {
+			return err
+		}
+
+		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID)
+		if err != nil {
+			return err
+		}
+
+		rs, err := workflowtemplate.LoadReleasesByTemplateID(ctx, api.mustDB(), wt.ID)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, rs, http.StatusOK)
+	}
+}
+
+func (api *API) postTemplateReleaseHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		groupName := vars["permGroupName"]
+		templateSlug := vars["permTemplateSlug"]
+
+		g, err := group.LoadByName(ctx, api.mustDB(), groupName)
+		if err != nil {
+			return err
+		}
+
+		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID, workflowtemplate.LoadOptions.Default)
+		if err != nil {
+			return err
+		}
+
+		var data sdk.WorkflowTemplateRelease
+		if err := service.UnmarshalBody(r, &data); err != nil {
+			return err
+		}
+		if err := data.IsValid(); err != nil {
+			return err
+		}
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		rs, err := workflowtemplate.LoadReleasesByTemplateID(ctx, tx, wt.ID)
+		if err != nil {
+			return err
+		}
+		if err := rs.CheckNewVersion(data.Version); err != nil {
+			return err
+		}
+
+		// the release contains a snapshot of the template without its aggregates
+		snapshot := *wt
+		snapshot.Group = nil
+		snapshot.FirstAudit, snapshot.LastAudit = nil, nil
+		snapshot.Editable = false
+		snapshot.ChangeMessage = ""
+		if err := workflowtemplate.LoadIncludes(ctx, tx, &snapshot); err != nil {
+			return err
+		}
+
+		release := sdk.WorkflowTemplateRelease{
+			WorkflowTemplateID: wt.ID,
+			Version:            data.Version,
+			Changelog:          data.Changelog,
+			Template:           snapshot,
+			Author:             getAPIConsumer(ctx).GetUsername(),
+			Created:            time.Now(),
+		}
+		if err := workflowtemplate.InsertRelease(tx, &release); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+
+		return service.WriteJSON(w, release, http.StatusOK)
+	}
+}
+
+func (api *API) postTemplateUpgradeHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		groupName := vars["groupName"]
+		templateSlug := vars["templateSlug"]
+
+		g, err := group.LoadByName(ctx, api.mustDB(), groupName)
+		if err != nil {
+			return err
+		}
+		if !(isGroupMember(ctx, g) || isMaintainer(ctx)) {
+			return sdk.WithStack(sdk.ErrNotFound)
+		}
+
+		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID, workflowtemplate.LoadOptions.Default)
+		if err != nil {
+			return err
+		}
+
+		var req sdk.WorkflowTemplateUpgradeRequest
+		if err := service.UnmarshalBody(r, &req); err != nil {
+			return err
+		}
+		if err := req.IsValid(); err != nil {
+			return err
+		}
+
+		rs, err := workflowtemplate.LoadReleasesByTemplateID(ctx, api.mustDB(), wt.ID)
+		if err != nil {
+			return err
+		}
+		if len(rs) == 0 {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "template %s/%s has no release", g.Name, wt.Slug)
+		}
+		target := &rs[0]
+		if req.Version != "" {
+			target, err = rs.Get(req.Version)
+			if err != nil {
+				return err
+			}
+		}
+
+		is, err := api.loadTemplateInstancesForConsumer(ctx, wt)
+		if err != nil {
+			return err
+		}
+
+		consumer := getAPIConsumer(ctx)
+
+		res := sdk.WorkflowTemplateUpgradeResult{
+			Version:   target.Version,
+			Instances: []sdk.WorkflowTemplateInstanceDiff{},
+		}
+		var ops []sdk.WorkflowTemplateBulkOperation
+		for i := range is {
+			// only instances of imported workflows can be upgraded
+			if is[i].Workflow == nil {
+				continue
+			}
+
+			var labels []string
+			if len(req.Labels) > 0 {
+				ls, err := workflow.Labels(api.mustDB(), is[i].Workflow.ID)
+				if err != nil {
+					return err
+				}
+				for _, l := range ls {
+					labels = append(labels, l.Name)
+				}
+			}
+			if !req.Selects(is[i].ID, labels) {
+				continue
+			}
+
+			d := newTemplateInstanceDiff(is[i])
+			d.ToVersion = target.Version
+			if is[i].ReleaseVersion == target.Version {
+				d.Skipped = "already up to date"
+			} else if _, err := rs.Resolve(target.Version, is[i].Request.VersionRange); err != nil {
+				d.Skipped = fmt.Sprintf("pinned to version range %s", is[i].Request.VersionRange)
+			} else if !consumer.Admin() && api.checkProjectPermissions(ctx, is[i].Project.Key, sdk.PermissionReadWriteExecute, nil) != nil {
+				d.Skipped = "write permission on project required"
+			} else if req.DryRun {
+				d, err = templateInstanceDiff(ctx, api.mustDB(), wt, rs, is[i], target)
+				if err != nil {
+					return err
+				}
+			}
+
+			if d.Skipped == "" {
+				ops = append(ops, sdk.WorkflowTemplateBulkOperation{
+					Status:  sdk.OperationStatusPending,
+					Request: is[i].Request,
+					Version: target.Version,
+				})
+			}
+			res.Instances = append(res.Instances, d)
+		}
+
+		if req.DryRun || len(ops) == 0 {
+			return service.WriteJSON(w, res, http.StatusOK)
+		}
+
+		// store the bulk that applies the upgrade
+		bulk := sdk.WorkflowTemplateBulk{
+			UserID:             consumer.AuthentifiedUser.OldUserStruct.ID,
+			WorkflowTemplateID: wt.ID,
+			Operations:         ops,
+		}
+		if err := workflowtemplate.InsertBulk(api.mustDB(), &bulk); err != nil {
+			return err
+		}
+		created := bulk
+		created.Operations = append([]sdk.WorkflowTemplateBulkOperation{}, bulk.Operations...)
+		res.Bulk = &created
+
+		// start async bulk tasks
+		sdk.GoRoutine(context.Background(), "api.templateUpgrade", func(ctx context.Context) {
+			api.runTemplateBulk(ctx, consumer, wt, &bulk)
+		})
+
+		return service.WriteJSON(w, res, http.StatusOK)
+	}
+}
+
+func (api *API) getTemplateInstanceDiffHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		groupName := vars["groupName"]
+		templateSlug := vars["templateSlug"]
+
+		g, err := group.LoadByName(ctx, api.mustDB(), groupName)
+		if err != nil {
+			return err
+		}
+		if !(isGroupMember(ctx, g) || isMaintainer(ctx)) {
+			return sdk.WithStack(sdk.ErrNotFound)
+		}
+
+		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID, workflowtemplate.LoadOptions.Default)
+		if err != nil {
+			return err
+		}
+
+		wti, err := api.loadTemplateInstanceForConsumer(ctx, r, wt)
+		if err != nil {
+			return err
+		}
+
+		rs, err := workflowtemplate.LoadReleasesByTemplateID(ctx, api.mustDB(), wt.ID)
+		if err != nil {
+			return err
+		}
+
+		// compare with the release that will be applied on the instance, or with the current template if there is no release
+		var to *sdk.WorkflowTemplateRelease
+		if version := r.FormValue("version"); version != "" || len(rs) > 0 {
+			to, err = rs.Resolve(version, wti.Request.VersionRange)
+			if err != nil {
+				return err
+			}
+		}
+
+		d, err := templateInstanceDiff(ctx, api.mustDB(), wt, rs, *wti, to)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, d, http.StatusOK)
+	}
+}
+
+func (api *API) postTemplateInstanceRollbackHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+
+		groupName := vars["groupName"]
+		templateSlug := vars["templateSlug"]
+
+		g, err := group.LoadByName(ctx, api.mustDB(), groupName)
+		if err != nil {
+			return err
+		}
+		if !(isGroupMember(ctx, g) || isMaintainer(ctx)) {
+			return sdk.WithStack(sdk.ErrNotFound)
+		}
+
+		wt, err := workflowtemplate.LoadBySlugAndGroupID(ctx, api.mustDB(), templateSlug, g.ID, workflowtemplate.LoadOptions.Default)
+		if err != nil {
+			return err
+		}
+
+		wti, err := api.loadTemplateInstanceForConsumer(ctx, r, wt)
+		if err != nil {
+			return err
+		}
+		if wti.PreviousReleaseVersion == "" {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "no previous release to rollback the workflow template instance to")
+		}
+
+		consumer := getAPIConsumer(ctx)
+		if !consumer.Admin() {
+			if err := api.checkProjectPermissions(ctx, wti.Project.Key, sdk.PermissionReadWriteExecute, nil); err != nil {
+				return sdk.NewErrorFrom(sdk.ErrForbidden, "write permission on project required to import generated workflow.")
+			}
+		}
+
+		msgs, err := api.applyTemplateAndPush(ctx, consumer, wt, wti.Request, wti.PreviousReleaseVersion)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, translate(r, msgs), http.StatusOK)
+	}
+}
+
+// loadTemplateInstancesForConsumer returns the instances of the template in the projects of the current consumer,
+// with their project and workflow, sorted by project key and workflow name.
+func (api *API) loadTemplateInstancesForConsumer(ctx context.Context, wt *sdk.WorkflowTemplate) ([]sdk.WorkflowTemplateInstance, error) {
+	ps, err := api.loadProjectsForConsumer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	is, err := workflowtemplate.GetInstancesByTemplateIDAndProjectIDs(api.mustDB(), wt.ID, sdk.ProjectsToIDs(ps))
+	if err != nil {
+		return nil, err
+	}
+
+	mProjects := make(map[int64]sdk.Project, len(ps))
+	for i := range ps {
+		mProjects[ps[i].ID] = ps[i]
+	}
+	isPointers := make([]*sdk.WorkflowTemplateInstance, len(is))
+	for i := range is {
+		p := mProjects[is[i].ProjectID]
+		is[i].Project = &p
+		isPointers[i] = &is[i]
+	}
+	if err := workflow.AggregateOnWorkflowTemplateInstance(api.mustDB(), isPointers...); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(is, func(i, j int) bool {
+		if is[i].Project.Key != is[j].Project.Key {
+			return is[i].Project.Key < is[j].Project.Key
+		}
+		return is[i].Request.WorkflowName < is[j].Request.WorkflowName
+	})
+
+	return is, nil
+}
+
+// loadTemplateInstanceForConsumer returns the instance of the template given in request vars if it's in a project
+// of the current consumer, with its project and workflow.
+func (api *API) loadTemplateInstanceForConsumer(ctx context.Context, r *http.Request, wt *sdk.WorkflowTemplate) (*sdk.WorkflowTemplateInstance, error) {
+	instanceID, err := requestVarInt(r, "instanceID")
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := api.loadProjectsForConsumer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wti, err := workflowtemplate.GetInstanceByIDForTemplateIDAndProjectIDs(api.mustDB(), instanceID, wt.ID, sdk.ProjectsToIDs(ps))
+	if err != nil {
+		return nil, err
+	}
+	if wti == nil {
+		return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "no workflow template instance found")
+	}
+
+	for i := range ps {
+		if ps[i].ID == wti.ProjectID {
+			wti.Project = &ps[i]
+			break
+		}
+	}
+	if err := workflow.AggregateOnWorkflowTemplateInstance(api.mustDB(), wti); err != nil {
+		return nil, err
+	}
+
+	return wti, nil
+}
+
+func (api *API) loadProjectsForConsumer(ctx context.Context) (sdk.Projects, error) {
+	if isMaintainer(ctx) {
+		return project.LoadAll(ctx, api.mustDB(), api.Cache)
+	}
+	return project.LoadAllByGroupIDs(ctx, api.mustDB(), api.Cache, getAPIConsumer(ctx).GetGroupIDs())
+}
+
+func newTemplateInstanceDiff(wti sdk.WorkflowTemplateInstance) sdk.WorkflowTemplateInstanceDiff {
+	d := sdk.WorkflowTemplateInstanceDiff{
+		InstanceID:   wti.ID,
+		WorkflowName: wti.Request.WorkflowName,
+		FromVersion:  sdk.WorkflowTemplateVersionName(wti.ReleaseVersion, wti.WorkflowTemplateVersion),
+	}
+	if wti.Project != nil {
+		d.ProjectKey = wti.Project.Key
+	}
+	if wti.Workflow != nil {
+		d.WorkflowName = wti.Workflow.Name
+	}
+	return d
+}
+
+// templateInstanceDiff returns the differences between the files generated for the instance by its release
+// and by given release, or by the current template if nil. For an instance that was not applied from a
+// release, the current template is used as the source of the comparison.
+func templateInstanceDiff(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate, rs sdk.WorkflowTemplateReleases,
+	wti sdk.WorkflowTemplateInstance, to *sdk.WorkflowTemplateRelease) (sdk.WorkflowTemplateInstanceDiff, error) {
+	d := newTemplateInstanceDiff(wti)
+	d.ToVersion = sdk.WorkflowTemplateVersionName("", wt.Version)
+
+	current := *wt
+	if err := workflowtemplate.LoadIncludes(ctx, db, &current); err != nil {
+		return d, err
+	}
+
+	from := current
+	if wti.ReleaseVersion != "" {
+		r, err := rs.Get(wti.ReleaseVersion)
+		if err != nil {
+			return d, err
+		}
+		from = wt.WithRelease(*r)
+	}
+	target := current
+	if to != nil {
+		target = wt.WithRelease(*to)
+		d.ToVersion = to.Version
+	}
+
+	before, err := workflowtemplate.Execute(&from, &wti)
+	if err != nil {
+		return d, err
+	}
+	after, err := workflowtemplate.Execute(&target, &wti)
+	if err != nil {
+		return d, err
+	}
+
+	d.Files, err = workflowtemplate.Diff(before, after)
+	if err != nil {
+		return d, err
+	}
+	return d, nil
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflowtemplate"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_templateReleaseUpgradeDiffAndRollback(t *testing.T) {
+	api, db, _, end := newTestAPI(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	proj := assets.InsertTestProject(t, db, api.Cache, sdk.RandomString(10), sdk.RandomString(10))
+	projectGroup := &proj.ProjectGroups[0].Group
+	_, jwt := assets.InsertAdminUser(t, db)
+
+	pipelineName := sdk.RandomString(10)
+	template := generateTemplate(projectGroup.ID, pipelineName)
+	require.NoError(t, workflowtemplate.Insert(db, template))
+
+	release := func(version string) {
+		uri := api.Router.GetRoute("POST", api.postTemplateReleaseHandler, map[string]string{
+			"permGroupName":    projectGroup.Name,
+			"permTemplateSlug": template.Slug,
+		})
+		test.NotEmpty(t, uri)
+		req := assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, sdk.WorkflowTemplateRelease{Version: version})
+		rec := httptest.NewRecorder()
+		api.Router.Mux.ServeHTTP(rec, req)
+		require.Equal(t, 200, rec.Code)
+	}
+
+	loadInstance := func(workflowName string) *sdk.WorkflowTemplateInstance {
+		wti, err := workflowtemplate.GetInstanceByWorkflowNameAndTemplateIDAndProjectID(db, workflowName, template.ID, proj.ID)
+		require.NoError(t, err)
+		require.NotNil(t, wti)
+		return wti
+	}
+
+	apply := func(wtr sdk.WorkflowTemplateRequest) {
+		uri := api.Router.GetRoute("POST", api.postTemplateApplyHandler, map[string]string{
+			"groupName":    projectGroup.Name,
+			"templateSlug": template.Slug,
+		})
+		test.NotEmpty(t, uri)
+		req := assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri+"?import=true", wtr)
+		rec := httptest.NewRecorder()
+		api.Router.Mux.ServeHTTP(rec, req)
+		require.Equal(t, 200, rec.Code)
+	}
+
+	rollback := func(instanceID int64) *httptest.ResponseRecorder {
+		uri := api.Router.GetRoute("POST", api.postTemplateInstanceRollbackHandler, map[string]string{
+			"groupName":    projectGroup.Name,
+			"templateSlug": template.Slug,
+			"instanceID":   strconv.FormatInt(instanceID, 10),
+		})
+		test.NotEmpty(t, uri)
+		req := assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, nil)
+		rec := httptest.NewRecorder()
+		api.Router.Mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// apply the first release on a new workflow
+	release("1.0.0")
+	wtr := sdk.WorkflowTemplateRequest{
+		ProjectKey:   proj.Key,
+		WorkflowName: sdk.RandomString(10),
+		VersionRange: ">=1.0.0",
+	}
+	apply(wtr)
+	wti := loadInstance(wtr.WorkflowName)
+	assert.Equal(t, "1.0.0", wti.ReleaseVersion)
+	assert.Equal(t, "", wti.PreviousReleaseVersion)
+
+	// an instance applied only once has nothing to rollback to
+	assert.Equal(t, 400, rollback(wti.ID).Code)
+
+	// release a new version of the template that changes the pipeline script
+	template.Pipelines[0].Value = base64.StdEncoding.EncodeToString([]byte(
+		`version: v1.0
+name: ` + pipelineName + `
+stages:
+- Stage 1
+jobs:
+- job: Job 1
+  stage: Stage 1
+  steps:
+  - script:
+    - echo "Hello Release!"`,
+	))
+	template.Version++
+	require.NoError(t, workflowtemplate.Update(db, template))
+	release("2.0.0")
+
+	// the diff compares the release of the instance with the greatest release in its range
+	uri := api.Router.GetRoute("GET", api.getTemplateInstanceDiffHandler, map[string]string{
+		"groupName":    projectGroup.Name,
+		"templateSlug": template.Slug,
+		"instanceID":   strconv.FormatInt(wti.ID, 10),
+	})
+	test.NotEmpty(t, uri)
+	req := assets.NewJWTAuthentifiedRequest(t, jwt, "GET", uri, nil)
+	rec := httptest.NewRecorder()
+	api.Router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+
+	var diff sdk.WorkflowTemplateInstanceDiff
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &diff))
+	assert.Equal(t, wti.ID, diff.InstanceID)
+	assert.Equal(t, proj.Key, diff.ProjectKey)
+	assert.Equal(t, "1.0.0", diff.FromVersion)
+	assert.Equal(t, "2.0.0", diff.ToVersion)
+	require.Len(t, diff.Files, 1)
+	assert.Equal(t, pipelineName+".pip.yml", diff.Files[0].Name)
+	assert.True(t, strings.Contains(diff.Files[0].Diff, `-    - echo "Hello World!"`), diff.Files[0].Diff)
+	assert.True(t, strings.Contains(diff.Files[0].Diff, `+    - echo "Hello Release!"`), diff.Files[0].Diff)
+
+	// a dry run upgrade returns the diff of the instance without starting a bulk
+	uri = api.Router.GetRoute("POST", api.postTemplateUpgradeHandler, map[string]string{
+		"groupName":    projectGroup.Name,
+		"templateSlug": template.Slug,
+	})
+	test.NotEmpty(t, uri)
+	req = assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, sdk.WorkflowTemplateUpgradeRequest{DryRun: true})
+	rec = httptest.NewRecorder()
+	api.Router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+
+	var upgrade sdk.WorkflowTemplateUpgradeResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &upgrade))
+	assert.Equal(t, "2.0.0", upgrade.Version)
+	assert.Nil(t, upgrade.Bulk)
+	require.Len(t, upgrade.Instances, 1)
+	assert.Equal(t, wti.ID, upgrade.Instances[0].InstanceID)
+	assert.Equal(t, "", upgrade.Instances[0].Skipped)
+	assert.Equal(t, diff.Files, upgrade.Instances[0].Files)
+
+	// apply the new release then rollback to the previous one
+	apply(wtr)
+	wti = loadInstance(wtr.WorkflowName)
+	assert.Equal(t, "2.0.0", wti.ReleaseVersion)
+	assert.Equal(t, "1.0.0", wti.PreviousReleaseVersion)
+
+	req = assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, sdk.WorkflowTemplateUpgradeRequest{DryRun: true})
+	rec = httptest.NewRecorder()
+	api.Router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &upgrade))
+	require.Len(t, upgrade.Instances, 1)
+	assert.Equal(t, "already up to date", upgrade.Instances[0].Skipped)
+
+	rec = rollback(wti.ID)
+	require.Equal(t, 200, rec.Code)
+	var msgs []string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &msgs))
+
+	wti = loadInstance(wtr.WorkflowName)
+	assert.Equal(t, "1.0.0", wti.ReleaseVersion)
+	assert.Equal(t, "2.0.0", wti.PreviousReleaseVersion)
+}
@@ -324,3 +324,34 @@ func Test_getTemplateInstancesHandler(t *testing.T) {
 		getUsage(t, jwtLambdaInGroupOneAndTwo, []string{workflowProjectOneName, workflowProjectTwoName})
 	})
 }
+
+func Test_deleteTemplateHandler(t *testing.T) {
+	api, db, _, end := newTestAPI(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	grp := assets.InsertTestGroup(t, db, sdk.RandomString(10))
+	_, jwtAdmin := assets.InsertAdminUser(t, api.mustDB())
+
+	fragment := generateTemplate(grp.ID, sdk.RandomString(10))
+	require.NoError(t, workflowtemplate.Insert(db, fragment))
+	build := generateTemplate(grp.ID, sdk.RandomString(10))
+	build.Includes = sdk.WorkflowTemplateIncludes{{Template: grp.Name + "/" + fragment.Slug}}
+	require.NoError(t, workflowtemplate.Insert(db, build))
+
+	deleteTemplate := func(wt *sdk.WorkflowTemplate) int {
+		uri := api.Router.GetRoute("DELETE", api.deleteTemplateHandler, map[string]string{
+			"permGroupName":    grp.Name,
+			"permTemplateSlug": wt.Slug,
+		})
+		test.NotEmpty(t, uri)
+		req := assets.NewJWTAuthentifiedRequest(t, jwtAdmin, "DELETE", uri, nil)
+		rec := httptest.NewRecorder()
+		api.Router.Mux.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// the fragment can't be deleted while the build template includes it
+	assert.Equal(t, http.StatusForbidden, deleteTemplate(fragment))
+	assert.Equal(t, http.StatusOK, deleteTemplate(build))
+	assert.Equal(t, http.StatusOK, deleteTemplate(fragment))
+}
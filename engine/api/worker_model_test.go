@@ -382,6 +382,31 @@ func Test_postWorkerModelWithWrongRequest(t *testing.T) {
 
 	t.Logf("Body: %s", w.Body.String())
 
+	//Pod template must be valid
+	model = sdk.Model{
+		Name:    "Test1",
+		Type:    sdk.Docker,
+		GroupID: g.ID,
+		ModelDocker: sdk.ModelDocker{
+			Image:       "buildpack-deps:jessie",
+			Cmd:         "worker",
+			Shell:       "sh -c",
+			PodTemplate: "spec:\n  containers: invalid",
+		},
+	}
+
+	//Prepare request
+	req = assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, model)
+
+	//Do the request
+	w = httptest.NewRecorder()
+	api.Router.Mux.ServeHTTP(w, req)
+
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "invalid pod template")
+
+	t.Logf("Body: %s", w.Body.String())
+
 	//SendBadRequest
 
 	//Prepare request
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/go-gorp/gorp"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
 
 	"github.com/ovh/cds/engine/api/action"
 	"github.com/ovh/cds/engine/api/group"
@@ -37,6 +39,7 @@ func Create(ctx context.Context, db gorp.SqlExecutor, data sdk.Model, ident sdk.
 			data.ModelDocker.Cmd = modelPattern.Model.Cmd
 			data.ModelDocker.Shell = modelPattern.Model.Shell
 			data.ModelDocker.Envs = modelPattern.Model.Envs
+			data.ModelDocker.PodTemplate = ""
 		default:
 			data.ModelVirtualMachine.PreCmd = modelPattern.Model.PreCmd
 			data.ModelVirtualMachine.Cmd = modelPattern.Model.Cmd
@@ -44,6 +47,10 @@ func Create(ctx context.Context, db gorp.SqlExecutor, data sdk.Model, ident sdk.
 		}
 	}
 
+	if err := checkPodTemplate(data); err != nil {
+		return nil, err
+	}
+
 	// init new model from given data
 	var model sdk.Model
 	model.Update(data)
@@ -89,6 +96,7 @@ func Update(ctx context.Context, db gorp.SqlExecutor, old *sdk.Model, data sdk.M
 			data.ModelDocker.Cmd = modelPattern.Model.Cmd
 			data.ModelDocker.Shell = modelPattern.Model.Shell
 			data.ModelDocker.Envs = modelPattern.Model.Envs
+			data.ModelDocker.PodTemplate = ""
 		default:
 			data.ModelVirtualMachine.PreCmd = modelPattern.Model.PreCmd
 			data.ModelVirtualMachine.Cmd = modelPattern.Model.Cmd
@@ -106,6 +114,10 @@ func Update(ctx context.Context, db gorp.SqlExecutor, old *sdk.Model, data sdk.M
 		data.ModelDocker.Password = modelClear.ModelDocker.Password
 	}
 
+	if err := checkPodTemplate(data); err != nil {
+		return nil, err
+	}
+
 	// update fields from request data
 	model := sdk.Model(*old)
 	model.Update(data)
@@ -164,6 +176,7 @@ func CopyModelTypeData(old, data *sdk.Model) error {
 			data.ModelDocker.Cmd = old.ModelDocker.Cmd
 			data.ModelDocker.Shell = old.ModelDocker.Shell
 			data.ModelDocker.Envs = old.ModelDocker.Envs
+			data.ModelDocker.PodTemplate = old.ModelDocker.PodTemplate
 		default:
 			data.ModelVirtualMachine.PreCmd = old.ModelVirtualMachine.PreCmd
 			data.ModelVirtualMachine.Cmd = old.ModelVirtualMachine.Cmd
@@ -173,3 +186,15 @@ func CopyModelTypeData(old, data *sdk.Model) error {
 
 	return nil
 }
+
+// checkPodTemplate checks that the Kubernetes pod template of a docker model can be parsed by the hatchery.
+func checkPodTemplate(data sdk.Model) error {
+	if data.Type != sdk.Docker || data.ModelDocker.PodTemplate == "" {
+		return nil
+	}
+	var tmpl apiv1.PodTemplateSpec
+	if err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(data.ModelDocker.PodTemplate), 4096).Decode(&tmpl); err != nil {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pod template: %v", err)
+	}
+	return nil
+}
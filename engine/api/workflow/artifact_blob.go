@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// StoreArtifactBlob stores the data of an artifact by its SHA-256. If the same content was already
+// stored in the storage, only a reference is added on the existing blob. It returns true if the data
+// was uploaded to the storage. Given db should be a transaction that also inserts the artifact.
+func StoreArtifactBlob(ctx context.Context, db gorp.SqlExecutor, storageDriver objectstore.Driver, art *sdk.WorkflowNodeRunArtifact, data io.ReadSeeker) (bool, error) {
+	h := sha256.New()
+	size, err := io.Copy(h, data)
+	if err != nil {
+		return false, sdk.WrapError(err, "cannot compute sha256 of artifact %s", art.Name)
+	}
+	if _, err := data.Seek(0, io.SeekStart); err != nil {
+		return false, sdk.WithStack(err)
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+
+	blob, err := LockArtifactBlob(db, sum, storageDriver.GetProjectIntegration().ID)
+	if err != nil {
+		return false, err
+	}
+
+	var uploaded bool
+	if !blob.Stored {
+		objectPath, err := storageDriver.Store(blob, ioutil.NopCloser(data))
+		if err != nil {
+			return false, sdk.WrapError(err, "cannot store artifact blob %s", sum)
+		}
+		blob.ObjectPath = objectPath
+		blob.Size = size
+		blob.Stored = true
+		uploaded = true
+	} else {
+		log.Debug("StoreArtifactBlob> artifact %s is already stored as blob %s", art.Name, sum)
+	}
+	blob.RefCount++
+	if err := UpdateArtifactBlob(db, blob); err != nil {
+		if uploaded {
+			_ = storageDriver.Delete(ctx, blob)
+		}
+		return false, err
+	}
+
+	art.BlobSHA256 = blob.SHA256
+	art.ObjectPath = blob.ObjectPath
+	return uploaded, nil
+}
+
+// DeleteArtifactData deletes the data of an artifact from the storage. For an artifact stored by
+// content, a reference is removed from the blob and the blob is deleted only with its last reference.
+func DeleteArtifactData(ctx context.Context, db gorp.SqlExecutor, storageDriver objectstore.Driver, art *sdk.WorkflowNodeRunArtifact) error {
+	if art.BlobSHA256 == "" {
+		return storageDriver.Delete(ctx, art)
+	}
+
+	blob, err := LockArtifactBlobForDelete(db, art.BlobSHA256, storageDriver.GetProjectIntegration().ID)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrNotFound) {
+			log.Warning(ctx, "DeleteArtifactData> blob %s of artifact %d not found", art.BlobSHA256, art.ID)
+			return nil
+		}
+		return err
+	}
+
+	blob.RefCount--
+	if blob.RefCount > 0 {
+		return UpdateArtifactBlob(db, blob)
+	}
+
+	log.Debug("DeleteArtifactData> deleting blob %s", blob.SHA256)
+	if err := DeleteArtifactBlob(db, blob); err != nil {
+		return err
+	}
+	return sdk.WrapError(storageDriver.Delete(ctx, blob), "cannot delete blob %s", blob.SHA256)
+}
@@ -0,0 +1,75 @@
+package workflow_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestStoreArtifactBlob(t *testing.T) {
+	db, _, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	basedir, err := ioutil.TempDir("", "store")
+	require.NoError(t, err)
+	defer os.RemoveAll(basedir) // nolint
+
+	storageDriver, err := objectstore.Init(context.TODO(), objectstore.Config{
+		Kind: objectstore.Filesystem,
+		Options: objectstore.ConfigOptions{
+			Filesystem: objectstore.ConfigOptionsFilesystem{Basedir: basedir},
+		},
+	})
+	require.NoError(t, err)
+
+	content := []byte(sdk.RandomString(100))
+	art1 := &sdk.WorkflowNodeRunArtifact{Name: "file1", Tag: "1"}
+	art2 := &sdk.WorkflowNodeRunArtifact{Name: "file2", Tag: "2"}
+
+	// The first artifact uploads the blob
+	tx, err := db.Begin()
+	require.NoError(t, err)
+	uploaded, err := workflow.StoreArtifactBlob(context.TODO(), tx, storageDriver, art1, bytes.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	assert.True(t, uploaded)
+	assert.Len(t, art1.BlobSHA256, 64)
+
+	// The second one with the same content only adds a reference
+	tx, err = db.Begin()
+	require.NoError(t, err)
+	uploaded, err = workflow.StoreArtifactBlob(context.TODO(), tx, storageDriver, art2, bytes.NewReader(content))
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+	assert.False(t, uploaded)
+	assert.Equal(t, art1.BlobSHA256, art2.BlobSHA256)
+
+	blobPath := path.Join(basedir, "blobs-"+art1.BlobSHA256[:2], art1.BlobSHA256)
+	f, err := storageDriver.Fetch(context.TODO(), objectstore.ArtifactObject(art2))
+	require.NoError(t, err)
+	btes, err := ioutil.ReadAll(f)
+	require.NoError(t, err)
+	_ = f.Close()
+	assert.Equal(t, content, btes)
+
+	// The blob is deleted with its last reference
+	require.NoError(t, workflow.DeleteArtifactData(context.TODO(), db, storageDriver, art1))
+	_, err = os.Stat(blobPath)
+	assert.NoError(t, err)
+
+	require.NoError(t, workflow.DeleteArtifactData(context.TODO(), db, storageDriver, art2))
+	_, err = os.Stat(blobPath)
+	assert.True(t, os.IsNotExist(err))
+}
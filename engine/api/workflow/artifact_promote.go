@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// NewArtifactProvenance returns the provenance of an artifact of given node run. The provenance
+// of an artifact that was already promoted is kept.
+func NewArtifactProvenance(projectKey, workflowName string, runNumber int64, nodeRun sdk.WorkflowNodeRun, art sdk.WorkflowNodeRunArtifact) sdk.ArtifactProvenance {
+	if art.Provenance != nil {
+		p := *art.Provenance
+		p.Promoted = time.Now()
+		return p
+	}
+	return sdk.ArtifactProvenance{
+		ProjectKey:   projectKey,
+		WorkflowName: workflowName,
+		RunNumber:    runNumber,
+		NodeRunID:    nodeRun.ID,
+		ArtifactID:   art.ID,
+		Repository:   nodeRun.VCSRepository,
+		Branch:       nodeRun.VCSBranch,
+		Commit:       nodeRun.VCSHash,
+		MD5sum:       art.MD5sum,
+		SHA512sum:    art.SHA512sum,
+		Promoted:     time.Now(),
+	}
+}
+
+// PromoteArtifact inserts the artifact dst with the data of the artifact src from another run. If link is true
+// and src is stored by content in the storage of dst, a reference is added on its blob. Otherwise the data
+// is copied and checked against the checksums of src. Given db should be a transaction.
+func PromoteArtifact(ctx context.Context, db gorp.SqlExecutor, srcDriver, dstDriver objectstore.Driver, src sdk.WorkflowNodeRunArtifact, dst *sdk.WorkflowNodeRunArtifact, link, contentAddressed bool) error {
+	dstIntegrationID := dstDriver.GetProjectIntegration().ID
+	if dstIntegrationID > 0 {
+		dst.ProjectIntegrationID = &dstIntegrationID
+	}
+	dst.Size = src.Size
+	dst.Perm = src.Perm
+	dst.MD5sum = src.MD5sum
+	dst.SHA512sum = src.SHA512sum
+
+	var srcIntegrationID int64
+	if src.ProjectIntegrationID != nil {
+		srcIntegrationID = *src.ProjectIntegrationID
+	}
+	if link && src.BlobSHA256 != "" && srcIntegrationID == dstIntegrationID {
+		blob, err := LockArtifactBlobForDelete(db, src.BlobSHA256, dstIntegrationID)
+		if err == nil {
+			blob.RefCount++
+			if err := UpdateArtifactBlob(db, blob); err != nil {
+				return err
+			}
+			dst.BlobSHA256 = blob.SHA256
+			dst.ObjectPath = blob.ObjectPath
+			return sdk.WrapError(InsertArtifact(db, dst), "cannot insert artifact %s", dst.Name)
+		}
+		if !sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return err
+		}
+		log.Warning(ctx, "PromoteArtifact> blob %s of artifact %d not found, artifact will be copied", src.BlobSHA256, src.ID)
+	}
+
+	tmp, err := ioutil.TempFile("", "cds-artifact-promote")
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer os.Remove(tmp.Name()) // nolint
+	defer tmp.Close()           // nolint
+
+	f, err := srcDriver.Fetch(ctx, objectstore.ArtifactObject(&src))
+	if err != nil {
+		return sdk.WrapError(err, "cannot fetch artifact %s", src.Name)
+	}
+	md5Hash, sha512Hash := md5.New(), sha512.New()
+	size, err := io.Copy(io.MultiWriter(tmp, md5Hash, sha512Hash), f)
+	_ = f.Close()
+	if err != nil {
+		return sdk.WrapError(err, "cannot copy artifact %s", src.Name)
+	}
+
+	md5sum, sha512sum := hex.EncodeToString(md5Hash.Sum(nil)), hex.EncodeToString(sha512Hash.Sum(nil))
+	if (src.SHA512sum != "" && src.SHA512sum != sha512sum) || (src.MD5sum != "" && src.MD5sum != md5sum) {
+		return sdk.WithStack(fmt.Errorf("checksum of artifact %s doesn't match the checksum of its source", src.Name))
+	}
+	dst.Size, dst.MD5sum, dst.SHA512sum = size, md5sum, sha512sum
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return sdk.WithStack(err)
+	}
+
+	if contentAddressed {
+		if _, err := StoreArtifactBlob(ctx, db, dstDriver, dst, tmp); err != nil {
+			return sdk.WrapError(err, "cannot store artifact %s", dst.Name)
+		}
+		return sdk.WrapError(InsertArtifact(db, dst), "cannot insert artifact %s", dst.Name)
+	}
+
+	objectPath, err := dstDriver.Store(dst, ioutil.NopCloser(tmp))
+	if err != nil {
+		return sdk.WrapError(err, "cannot store artifact %s", dst.Name)
+	}
+	dst.ObjectPath = objectPath
+	if err := InsertArtifact(db, dst); err != nil {
+		_ = dstDriver.Delete(ctx, dst)
+		return sdk.WrapError(err, "cannot insert artifact %s", dst.Name)
+	}
+	return nil
+}
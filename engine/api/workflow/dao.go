@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -23,7 +24,9 @@ import (
 	"github.com/ovh/cds/engine/api/keys"
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/workflowtemplate"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
 	"github.com/ovh/cds/sdk/log"
 )
 
@@ -146,12 +149,14 @@ func (w *Workflow) PostInsert(db gorp.SqlExecutor) error {
 // PostGet is a db hook
 func (w *Workflow) PostGet(db gorp.SqlExecutor) error {
 	var res = struct {
-		Metadata     sql.NullString `db:"metadata"`
-		PurgeTags    sql.NullString `db:"purge_tags"`
-		WorkflowData sql.NullString `db:"workflow_data"`
+		Metadata        sql.NullString `db:"metadata"`
+		PurgeTags       sql.NullString `db:"purge_tags"`
+		RetentionPolicy sql.NullString `db:"retention_policy"`
+		Concurrency     sql.NullString `db:"concurrency"`
+		WorkflowData    sql.NullString `db:"workflow_data"`
 	}{}
 
-	if err := db.SelectOne(&res, "SELECT metadata, purge_tags, workflow_data FROM workflow WHERE id = $1", w.ID); err != nil {
+	if err := db.SelectOne(&res, "SELECT metadata, purge_tags, retention_policy, concurrency, workflow_data FROM workflow WHERE id = $1", w.ID); err != nil {
 		return sdk.WrapError(err, "PostGet> Unable to load marshalled workflow")
 	}
 
@@ -167,6 +172,22 @@ func (w *Workflow) PostGet(db gorp.SqlExecutor) error {
 	}
 	w.PurgeTags = purgeTags
 
+	if res.RetentionPolicy.Valid {
+		policy := &sdk.WorkflowRetentionPolicy{}
+		if err := gorpmapping.JSONNullString(res.RetentionPolicy, policy); err != nil {
+			return sdk.WrapError(err, "unable to unmarshall workflow retention policy")
+		}
+		w.RetentionPolicy = policy
+	}
+
+	if res.Concurrency.Valid {
+		concurrency := &sdk.WorkflowConcurrency{}
+		if err := gorpmapping.JSONNullString(res.Concurrency, concurrency); err != nil {
+			return sdk.WrapError(err, "unable to unmarshall workflow concurrency")
+		}
+		w.Concurrency = concurrency
+	}
+
 	data := &sdk.WorkflowData{}
 	if err := gorpmapping.JSONNullString(res.WorkflowData, data); err != nil {
 		return sdk.WrapError(err, "Unable to unmarshall workflow data")
@@ -216,7 +237,24 @@ func (w *Workflow) PostUpdate(db gorp.SqlExecutor) error {
 	if errD != nil {
 		return sdk.WrapError(errD, "Workflow.PostUpdate> Unable to marshall workflow data")
 	}
-	if _, err := db.Exec("update workflow set purge_tags = $1, workflow_data = $3 where id = $2", pt, w.ID, data); err != nil {
+	var policy sql.NullString
+	if w.RetentionPolicy != nil {
+		var err error
+		policy, err = gorpmapping.JSONToNullString(w.RetentionPolicy)
+		if err != nil {
+			return sdk.WrapError(err, "unable to marshall workflow retention policy")
+		}
+	}
+	var concurrency sql.NullString
+	if w.Concurrency != nil {
+		var err error
+		concurrency, err = gorpmapping.JSONToNullString(w.Concurrency)
+		if err != nil {
+			return sdk.WrapError(err, "unable to marshall workflow concurrency")
+		}
+	}
+
+	if _, err := db.Exec("update workflow set purge_tags = $1, workflow_data = $3, retention_policy = $4, concurrency = $5 where id = $2", pt, w.ID, data, policy, concurrency); err != nil {
 		return err
 	}
 
@@ -624,9 +662,19 @@ func IsFavorite(db gorp.SqlExecutor, w *sdk.Workflow, uID int64) (bool, error) {
 
 // Insert inserts a new workflow
 func Insert(ctx context.Context, db gorp.SqlExecutor, store cache.Store, w *sdk.Workflow, p *sdk.Project) error {
+	return insert(ctx, db, store, w, p, false)
+}
+
+func insert(ctx context.Context, db gorp.SqlExecutor, store cache.Store, w *sdk.Workflow, p *sdk.Project, disableHookManagement bool) error {
 	if err := IsValid(ctx, store, db, w, p, LoadOptions{}); err != nil {
 		return sdk.WrapError(err, "Unable to validate workflow")
 	}
+	if err := checkSchedulerHooks(db, p, w); err != nil {
+		return err
+	}
+	if err := checkIntegrationHooks(db, p, w); err != nil {
+		return err
+	}
 
 	if w.WorkflowData.Node.Context != nil && w.WorkflowData.Node.Context.ApplicationID != 0 {
 		var err error
@@ -693,7 +741,7 @@ func Insert(ctx context.Context, db gorp.SqlExecutor, store cache.Store, w *sdk.
 	}
 
 	// Manage new hooks
-	if len(w.WorkflowData.Node.Hooks) > 0 {
+	if len(w.WorkflowData.Node.Hooks) > 0 && !disableHookManagement {
 		if err := hookRegistration(ctx, db, store, p, w, nil); err != nil {
 			return err
 		}
@@ -751,6 +799,7 @@ func RenameNode(ctx context.Context, db gorp.SqlExecutor, w *sdk.Workflow) error
 	maxNumberByPipeline := map[int64]int{}
 	maxNumberByHookModel := map[int64]int{}
 	var maxForkNumber int
+	var maxApprovalNumber int
 
 	nodesToNamed := []*sdk.Node{}
 	// Search max numbers by nodes type
@@ -838,6 +887,22 @@ func RenameNode(ctx context.Context, db gorp.SqlExecutor, w *sdk.Workflow) error
 					maxForkNumber = forkNumber
 				}
 			}
+		case sdk.NodeTypeApproval:
+			if nodes[i].Name == sdk.NodeTypeApproval || strings.HasPrefix(nodes[i].Name, sdk.NodeTypeApproval+"_") {
+				var approvalNumber int
+				if nodes[i].Name == sdk.NodeTypeApproval {
+					approvalNumber = 1
+				} else {
+					// Retrieve Number
+					current, errI := strconv.Atoi(strings.Replace(nodes[i].Name, sdk.NodeTypeApproval+"_", "", 1))
+					if errI == nil {
+						approvalNumber = current
+					}
+				}
+				if maxApprovalNumber < approvalNumber {
+					maxApprovalNumber = approvalNumber
+				}
+			}
 		case sdk.NodeTypeOutGoingHook:
 			model := w.OutGoingHookModels[nodes[i].OutGoingHookContext.HookModelID]
 			// Check if node is named pipName_12
@@ -892,6 +957,14 @@ func RenameNode(ctx context.Context, db gorp.SqlExecutor, w *sdk.Workflow) error
 				nodesToNamed[i].Name = sdk.NodeTypeFork
 			}
 			maxForkNumber++
+		case sdk.NodeTypeApproval:
+			nextNumber := maxApprovalNumber + 1
+			if nextNumber > 1 {
+				nodesToNamed[i].Name = fmt.Sprintf("%s_%d", sdk.NodeTypeApproval, nextNumber)
+			} else {
+				nodesToNamed[i].Name = sdk.NodeTypeApproval
+			}
+			maxApprovalNumber++
 		case sdk.NodeTypeOutGoingHook:
 			hookModelID := nodesToNamed[i].OutGoingHookContext.HookModelID
 			nextNumber := maxNumberByHookModel[hookModelID] + 1
@@ -917,6 +990,12 @@ func Update(ctx context.Context, db gorp.SqlExecutor, store cache.Store, w *sdk.
 	if err := IsValid(ctx, store, db, w, p, LoadOptions{}); err != nil {
 		return err
 	}
+	if err := checkSchedulerHooks(db, p, w); err != nil {
+		return err
+	}
+	if err := checkIntegrationHooks(db, p, w); err != nil {
+		return err
+	}
 
 	if err := DeleteNotifications(db, w.ID); err != nil {
 		return sdk.WrapError(err, "unable to delete all notifications on workflow(%d - %s)", w.ID, w.Name)
@@ -1065,6 +1144,18 @@ func IsValid(ctx context.Context, store cache.Store, db gorp.SqlExecutor, w *sdk
 		return sdk.NewError(sdk.ErrWorkflowInvalid, fmt.Errorf("Invalid workflow name. It should match %s", sdk.NamePattern))
 	}
 
+	if w.RetentionPolicy != nil {
+		if err := w.RetentionPolicy.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if w.Concurrency != nil {
+		if err := w.Concurrency.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	//Check refs
 	for _, j := range w.WorkflowData.Joins {
 		if len(j.JoinContext) == 0 {
@@ -1231,6 +1322,57 @@ func checkHooks(db gorp.SqlExecutor, w *sdk.Workflow, n *sdk.Node) error {
 	return nil
 }
 
+// checkSchedulerHooks checks the config of the schedulers when the workflow is saved, the hooks service can't trigger
+// the workflow with an invalid jitter or a missing calendar.
+func checkSchedulerHooks(db gorp.SqlExecutor, proj *sdk.Project, w *sdk.Workflow) error {
+	for _, n := range w.WorkflowData.Array() {
+		for _, h := range n.Hooks {
+			if h.HookModelName != sdk.SchedulerModelName {
+				continue
+			}
+			if err := h.Config.IsValidScheduler(); err != nil {
+				return err
+			}
+			calendar := h.Config[sdk.SchedulerModelCalendar].Value
+			if calendar == "" {
+				continue
+			}
+			nb, err := db.SelectInt("SELECT COUNT(1) FROM project_calendar WHERE project_id = $1 AND name = $2", proj.ID, calendar)
+			if err != nil {
+				return sdk.WrapError(err, "unable to load calendar %s", calendar)
+			}
+			if nb == 0 {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "calendar %s of scheduler not found in project %s", calendar, proj.Key)
+			}
+		}
+	}
+	return nil
+}
+
+// checkIntegrationHooks checks that the hooks consuming a broker use an integration of the project with the matching model
+func checkIntegrationHooks(db gorp.SqlExecutor, proj *sdk.Project, w *sdk.Workflow) error {
+	for _, n := range w.WorkflowData.Array() {
+		for _, h := range n.Hooks {
+			modelName := sdk.HookModelIntegrationModel(h.HookModelName)
+			if modelName == "" {
+				continue
+			}
+			integrationName := h.Config[sdk.HookModelIntegration].Value
+			pi, err := integration.LoadProjectIntegrationByName(db, proj.Key, integrationName, false)
+			if err != nil {
+				if sdk.Cause(err) == sql.ErrNoRows {
+					return sdk.NewErrorFrom(sdk.ErrWrongRequest, "integration %s of hook %s not found in project %s", integrationName, h.HookModelName, proj.Key)
+				}
+				return sdk.WrapError(err, "unable to load integration %s", integrationName)
+			}
+			if pi.Model.Name != modelName {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "integration %s of hook %s must be a %s integration", integrationName, h.HookModelName, modelName)
+			}
+		}
+	}
+	return nil
+}
+
 // CheckProjectIntegration checks CheckProjectIntegration data
 func checkProjectIntegration(proj *sdk.Project, w *sdk.Workflow, n *sdk.Node) error {
 	if n.Context.ProjectIntegrationID != 0 {
@@ -1387,35 +1529,92 @@ func checkPipeline(ctx context.Context, db gorp.SqlExecutor, proj *sdk.Project,
 func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Project, tr *tar.Reader, opts *PushOption, u sdk.Identifiable, decryptFunc keys.DecryptFunc) ([]sdk.Message, *sdk.Workflow, error) {
 	ctx, end := observability.Span(ctx, "workflow.Push")
 	defer end()
-	allMsg := []sdk.Message{}
 
 	data, err := extractFromCDSFiles(ctx, tr)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var workflowExists bool
-	var oldWf *sdk.Workflow
+	oldWf, err := loadPushedWorkflow(ctx, db, store, proj, data.wrkflw.Name, opts)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if opts != nil && opts.OldWorkflow != nil {
-		oldWf = opts.OldWorkflow
+	// if a old workflow as code exists, we want to check if the new workflow is also as code on the same repository
+	if oldWf != nil && oldWf.FromRepository != "" && (opts == nil || opts.FromRepository != oldWf.FromRepository) {
+		return nil, nil, sdk.WithStack(sdk.ErrWorkflowAlreadyAsCode)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, sdk.WrapError(err, "Unable to start tx")
+	}
+	defer tx.Rollback() // nolint
+
+	allMsg, wf, err := importPushedEntities(ctx, tx, store, proj, data, oldWf, opts, u, decryptFunc, false)
+	if err != nil {
+		return allMsg, nil, err
+	}
+
+	isDefaultBranch := true
+	if opts != nil {
+		isDefaultBranch = opts.IsDefaultBranch
+	}
+
+	if !isDefaultBranch {
+		_ = tx.Rollback()
+		log.Debug("workflow %s rollbacked because it's not coming from the default branch", wf.Name)
 	} else {
-		// load the workflow from database if exists
-		workflowExists, err = Exists(db, proj.Key, data.wrkflw.Name)
-		if err != nil {
-			return nil, nil, sdk.WrapError(err, "Cannot check if workflow exists")
+		if err := tx.Commit(); err != nil {
+			return nil, nil, sdk.WrapError(err, "Cannot commit transaction")
 		}
-		if workflowExists {
-			oldWf, err = Load(ctx, db, store, proj, data.wrkflw.Name, LoadOptions{WithIcon: true})
-			if err != nil {
-				return nil, nil, sdk.WrapError(err, "Unable to load existing workflow")
-			}
+
+		if oldWf != nil {
+			event.PublishWorkflowUpdate(ctx, proj.Key, *wf, *oldWf, u)
+		} else {
+			event.PublishWorkflowAdd(ctx, proj.Key, *wf, u)
 		}
+
+		log.Debug("workflow %s updated", wf.Name)
 	}
 
-	// if a old workflow as code exists, we want to check if the new workflow is also as code on the same repository
-	if oldWf != nil && oldWf.FromRepository != "" && (opts == nil || opts.FromRepository != oldWf.FromRepository) {
-		return nil, nil, sdk.WithStack(sdk.ErrWorkflowAlreadyAsCode)
+	return allMsg, wf, nil
+}
+
+// Plan imports a workflow from cds files like Push but always rollbacks the transaction, it returns the unified diffs
+// between the files of the existing workflow and the files of the imported workflow.
+func Plan(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Project, tr *tar.Reader, u sdk.Identifiable,
+	decryptFunc keys.DecryptFunc, encryptFunc sdk.EncryptFunc) ([]sdk.Message, []sdk.WorkflowTemplateFileDiff, error) {
+	ctx, end := observability.Span(ctx, "workflow.Plan")
+	defer end()
+	// nothing is imported so no event should be published
+	ctx = event.WithPublishDisabled(ctx)
+
+	data, err := extractFromCDSFiles(ctx, tr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	oldWf, err := loadPushedWorkflow(ctx, db, store, proj, data.wrkflw.Name, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var opts *PushOption
+	var before sdk.WorkflowTemplateResult
+	if oldWf != nil {
+		// a workflow as code is planned as if the files came from its repository
+		if oldWf.FromRepository != "" {
+			opts = &PushOption{FromRepository: oldWf.FromRepository, IsDefaultBranch: true}
+		}
+		wp, err := Pull(ctx, db, store, proj, oldWf.Name, exportentities.FormatYAML, encryptFunc)
+		if err != nil {
+			return nil, nil, err
+		}
+		before, err = pulledToTemplateResult(wp)
+		if err != nil {
+			return nil, nil, err
+		}
 	}
 
 	tx, err := db.Begin()
@@ -1424,12 +1623,61 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 	}
 	defer tx.Rollback() // nolint
 
+	allMsg, wf, err := importPushedEntities(ctx, tx, store, proj, data, oldWf, opts, u, decryptFunc, true)
+	if err != nil {
+		return allMsg, nil, err
+	}
+
+	wp, err := Pull(ctx, tx, store, proj, wf.Name, exportentities.FormatYAML, encryptFunc)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := pulledToTemplateResult(wp)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diffs, err := workflowtemplate.Diff(before, after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return allMsg, diffs, nil
+}
+
+// loadPushedWorkflow returns the existing workflow that will be replaced by a push, or nil if it doesn't exist.
+func loadPushedWorkflow(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, name string, opts *PushOption) (*sdk.Workflow, error) {
+	if opts != nil && opts.OldWorkflow != nil {
+		return opts.OldWorkflow, nil
+	}
+
+	// load the workflow from database if exists
+	workflowExists, err := Exists(db, proj.Key, name)
+	if err != nil {
+		return nil, sdk.WrapError(err, "Cannot check if workflow exists")
+	}
+	if !workflowExists {
+		return nil, nil
+	}
+	oldWf, err := Load(ctx, db, store, proj, name, LoadOptions{WithIcon: true})
+	if err != nil {
+		return nil, sdk.WrapError(err, "Unable to load existing workflow")
+	}
+	return oldWf, nil
+}
+
+// importPushedEntities imports the applications, environments, pipelines and the workflow extracted from cds files.
+func importPushedEntities(ctx context.Context, tx gorp.SqlExecutor, store cache.Store, proj *sdk.Project, data *exportedEntities, oldWf *sdk.Workflow,
+	opts *PushOption, u sdk.Identifiable, decryptFunc keys.DecryptFunc, dryRun bool) ([]sdk.Message, *sdk.Workflow, error) {
+	allMsg := []sdk.Message{}
+
+	var fromRepo string
+	if opts != nil {
+		fromRepo = opts.FromRepository
+	}
+
 	for filename, app := range data.apps {
 		log.Debug("Push> Parsing %s", filename)
-		var fromRepo string
-		if opts != nil {
-			fromRepo = opts.FromRepository
-		}
 		appDB, msgList, err := application.ParseAndImport(ctx, tx, store, proj, &app, application.ImportOptions{Force: true, FromRepository: fromRepo}, decryptFunc, u)
 		if err != nil {
 			return nil, nil, sdk.ErrorWithFallback(err, sdk.ErrWrongRequest, "unable to import application %s/%s", proj.Key, app.Name)
@@ -1441,10 +1689,6 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 
 	for filename, env := range data.envs {
 		log.Debug("Push> Parsing %s", filename)
-		var fromRepo string
-		if opts != nil {
-			fromRepo = opts.FromRepository
-		}
 		envDB, msgList, err := environment.ParseAndImport(tx, proj, &env, environment.ImportOptions{Force: true, FromRepository: fromRepo}, decryptFunc, u)
 		if err != nil {
 			return nil, nil, sdk.ErrorWithFallback(err, sdk.ErrWrongRequest, "unable to import environment %s/%s", proj.Key, env.Name)
@@ -1456,10 +1700,6 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 
 	for filename, pip := range data.pips {
 		log.Debug("Push> Parsing %s", filename)
-		var fromRepo string
-		if opts != nil {
-			fromRepo = opts.FromRepository
-		}
 		pipDB, msgList, err := pipeline.ParseAndImport(ctx, tx, store, proj, &pip, u, pipeline.ImportOptions{Force: true, FromRepository: fromRepo})
 		if err != nil {
 			return nil, nil, sdk.ErrorWithFallback(err, sdk.ErrWrongRequest, "unable to import pipeline %s/%s", proj.Key, pip.Name)
@@ -1470,13 +1710,9 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 		log.Debug("Push> -- %s OK", filename)
 	}
 
-	isDefaultBranch := true
-	if opts != nil {
-		isDefaultBranch = opts.IsDefaultBranch
-	}
-
 	var importOptions = ImportOptions{
-		Force: true,
+		Force:  true,
+		DryRun: dryRun,
 	}
 
 	if opts != nil {
@@ -1489,7 +1725,7 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 		importOptions.HookUUID = opts.HookUUID
 	}
 
-	wf, msgList, err := ParseAndImport(ctx, tx, store, proj, oldWf, &data.wrkflw, u, importOptions)
+	wf, msgList, err := ParseAndImportV2(ctx, tx, store, proj, oldWf, &data.wrkflw, u, importOptions)
 	if err != nil {
 		return msgList, nil, sdk.WrapError(err, "unable to import workflow %s", data.wrkflw.Name)
 	}
@@ -1513,26 +1749,46 @@ func Push(ctx context.Context, db *gorp.DbMap, store cache.Store, proj *sdk.Proj
 		wf.Applications[wf.WorkflowData.Node.Context.ApplicationID] = app
 	}
 
-	allMsg = append(allMsg, msgList...)
+	return append(allMsg, msgList...), wf, nil
+}
 
-	if !isDefaultBranch {
-		_ = tx.Rollback()
-		log.Debug("workflow %s rollbacked because it's not coming from the default branch", wf.Name)
-	} else {
-		if err := tx.Commit(); err != nil {
-			return nil, nil, sdk.WrapError(err, "Cannot commit transaction")
+// pulledToTemplateResult returns the decoded files of a pulled workflow, in the same form as the result of a template.
+func pulledToTemplateResult(wp exportentities.WorkflowPulled) (sdk.WorkflowTemplateResult, error) {
+	var res sdk.WorkflowTemplateResult
+	decode := func(item exportentities.WorkflowPulledItem) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(item.Value)
+		if err != nil {
+			return "", sdk.WrapError(err, "cannot decode file %s", item.Name)
 		}
+		return string(b), nil
+	}
 
-		if oldWf != nil {
-			event.PublishWorkflowUpdate(ctx, proj.Key, *wf, *oldWf, u)
-		} else {
-			event.PublishWorkflowAdd(ctx, proj.Key, *wf, u)
+	var err error
+	if res.Workflow, err = decode(wp.Workflow); err != nil {
+		return res, err
+	}
+	for _, p := range wp.Pipelines {
+		v, err := decode(p)
+		if err != nil {
+			return res, err
 		}
-
-		log.Debug("workflow %s updated", wf.Name)
+		res.Pipelines = append(res.Pipelines, v)
 	}
-
-	return allMsg, wf, nil
+	for _, a := range wp.Applications {
+		v, err := decode(a)
+		if err != nil {
+			return res, err
+		}
+		res.Applications = append(res.Applications, v)
+	}
+	for _, e := range wp.Environments {
+		v, err := decode(e)
+		if err != nil {
+			return res, err
+		}
+		res.Environments = append(res.Environments, v)
+	}
+	return res, nil
 }
 
 // UpdateFavorite add or delete workflow from user favorites
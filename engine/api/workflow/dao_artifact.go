@@ -23,7 +23,9 @@ func LoadWorkfowArtifactByHash(db gorp.SqlExecutor, hash string) (*sdk.WorkflowN
 				created,
 				workflow_run_id,
 				project_integration_id,
-				coalesce(sha512sum, '') AS sha512sum
+				coalesce(sha512sum, '') AS sha512sum,
+				coalesce(blob_sha256, '') AS blob_sha256,
+				provenance
 		  FROM workflow_node_run_artifacts
 		  WHERE workflow_node_run_artifacts.download_hash = $1`
 	if err := db.SelectOne(&artGorp, query, hash); err != nil {
@@ -52,7 +54,9 @@ func LoadArtifactByIDs(db gorp.SqlExecutor, workflowID, artifactID int64) (*sdk.
 			workflow_node_run_artifacts.created,
 			workflow_node_run_artifacts.workflow_run_id,
 			workflow_node_run_artifacts.project_integration_id,
-			coalesce(workflow_node_run_artifacts.sha512sum, '') AS sha512sum
+			coalesce(workflow_node_run_artifacts.sha512sum, '') AS sha512sum,
+			coalesce(workflow_node_run_artifacts.blob_sha256, '') AS blob_sha256,
+			workflow_node_run_artifacts.provenance
 		FROM workflow_node_run_artifacts
 		JOIN workflow_run ON workflow_run.id = workflow_node_run_artifacts.workflow_run_id
 		WHERE workflow_run.workflow_id = $1 AND workflow_node_run_artifacts.id = $2
@@ -81,7 +85,9 @@ func loadArtifactByNodeRunID(db gorp.SqlExecutor, nodeRunID int64) ([]sdk.Workfl
 			created,
 			workflow_run_id,
 			project_integration_id,
-			coalesce(sha512sum, '') AS sha512sum
+			coalesce(sha512sum, '') AS sha512sum,
+			coalesce(blob_sha256, '') AS blob_sha256,
+			provenance
 		FROM workflow_node_run_artifacts WHERE workflow_node_run_id = $1`, nodeRunID); err != nil {
 		return nil, err
 	}
@@ -93,6 +99,12 @@ func loadArtifactByNodeRunID(db gorp.SqlExecutor, nodeRunID int64) ([]sdk.Workfl
 	return artifacts, nil
 }
 
+// DeleteArtifact deletes an artifact from table workflow_node_run_artifacts
+func DeleteArtifact(db gorp.SqlExecutor, id int64) error {
+	_, err := db.Exec("DELETE FROM workflow_node_run_artifacts WHERE id = $1", id)
+	return sdk.WrapError(err, "cannot delete artifact %d", id)
+}
+
 // InsertArtifact insert in table workflow_artifacts
 func InsertArtifact(db gorp.SqlExecutor, a *sdk.WorkflowNodeRunArtifact) error {
 	wArtifactDB := NodeRunArtifact(*a)
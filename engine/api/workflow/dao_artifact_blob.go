@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LockArtifactBlob creates the blob for given hash and storage if not exists, and locks it until
+// the end of the transaction. The lock prevents the blob to be purged while a new reference is added,
+// and two identical artifacts to be stored at the same time.
+func LockArtifactBlob(db gorp.SqlExecutor, sha256 string, projectIntegrationID int64) (*sdk.ArtifactBlob, error) {
+	// The blob can be deleted by the purge between the insert and the select, so retry
+	for i := 0; i < 3; i++ {
+		if _, err := db.Exec(`INSERT INTO artifact_blob (sha256, project_integration_id, created) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING`,
+			sha256, projectIntegrationID, time.Now()); err != nil {
+			return nil, sdk.WrapError(err, "cannot insert artifact blob %s", sha256)
+		}
+
+		var b dbArtifactBlob
+		if err := db.SelectOne(&b, `SELECT * FROM artifact_blob WHERE sha256 = $1 AND project_integration_id = $2 FOR UPDATE`,
+			sha256, projectIntegrationID); err != nil {
+			if err == sql.ErrNoRows {
+				continue
+			}
+			return nil, sdk.WrapError(err, "cannot lock artifact blob %s", sha256)
+		}
+		blob := sdk.ArtifactBlob(b)
+		return &blob, nil
+	}
+	return nil, sdk.WithStack(sdk.ErrNotFound)
+}
+
+// LockArtifactBlobForDelete locks an existing blob until the end of the transaction.
+func LockArtifactBlobForDelete(db gorp.SqlExecutor, sha256 string, projectIntegrationID int64) (*sdk.ArtifactBlob, error) {
+	var b dbArtifactBlob
+	if err := db.SelectOne(&b, `SELECT * FROM artifact_blob WHERE sha256 = $1 AND project_integration_id = $2 FOR UPDATE`,
+		sha256, projectIntegrationID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sdk.WithStack(sdk.ErrNotFound)
+		}
+		return nil, sdk.WrapError(err, "cannot lock artifact blob %s", sha256)
+	}
+	blob := sdk.ArtifactBlob(b)
+	return &blob, nil
+}
+
+// UpdateArtifactBlob updates given blob.
+func UpdateArtifactBlob(db gorp.SqlExecutor, blob *sdk.ArtifactBlob) error {
+	b := dbArtifactBlob(*blob)
+	if _, err := db.Update(&b); err != nil {
+		return sdk.WrapError(err, "cannot update artifact blob %s", blob.SHA256)
+	}
+	return nil
+}
+
+// DeleteArtifactBlob deletes given blob.
+func DeleteArtifactBlob(db gorp.SqlExecutor, blob *sdk.ArtifactBlob) error {
+	if _, err := db.Exec(`DELETE FROM artifact_blob WHERE id = $1`, blob.ID); err != nil {
+		return sdk.WrapError(err, "cannot delete artifact blob %s", blob.SHA256)
+	}
+	return nil
+}
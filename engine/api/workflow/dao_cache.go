@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// UpsertCacheEntry records a cache pushed on a storage integration of a project.
+func UpsertCacheEntry(db gorp.SqlExecutor, projectID int64, integrationName, tag string, size int64) error {
+	now := time.Now()
+	if _, err := db.Exec(`
+		INSERT INTO cache_entry (project_id, integration_name, tag, size, created, last_access) VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (project_id, integration_name, tag) DO UPDATE SET size = $4, created = $5, last_access = $5`,
+		projectID, integrationName, tag, size, now); err != nil {
+		return sdk.WrapError(err, "unable to insert cache entry %s", tag)
+	}
+	return nil
+}
+
+// UpdateCacheEntryLastAccess sets the last access date of a cache to now.
+func UpdateCacheEntryLastAccess(db gorp.SqlExecutor, projectID int64, integrationName, tag string) error {
+	if _, err := db.Exec("UPDATE cache_entry SET last_access = $4 WHERE project_id = $1 AND integration_name = $2 AND tag = $3",
+		projectID, integrationName, tag, time.Now()); err != nil {
+		return sdk.WrapError(err, "unable to update cache entry %s", tag)
+	}
+	return nil
+}
+
+// ResolveCacheEntry returns the first cache matching one of given tags, checked in order. For each tag, an exact
+// match is returned first, otherwise the most recent cache whose tag starts with the given one.
+func ResolveCacheEntry(db gorp.SqlExecutor, projectID int64, integrationName string, tags []string) (*sdk.CacheEntry, error) {
+	for _, tag := range tags {
+		var entries []sdk.CacheEntry
+		if _, err := db.Select(&entries, `
+			SELECT id, project_id, integration_name, tag, size, created, last_access
+			FROM cache_entry
+			WHERE project_id = $1 AND integration_name = $2 AND left(tag, length($3)) = $3
+			ORDER BY tag = $3 DESC, created DESC
+			LIMIT 1`, projectID, integrationName, tag); err != nil {
+			return nil, sdk.WrapError(err, "unable to resolve cache entry %s", tag)
+		}
+		if len(entries) > 0 {
+			return &entries[0], nil
+		}
+	}
+	return nil, sdk.WithStack(sdk.ErrNotFound)
+}
+
+// LoadCacheEntriesToEvict returns the least recently used caches of a storage integration to delete
+// for the total size of the caches to fit in given max size.
+func LoadCacheEntriesToEvict(db gorp.SqlExecutor, projectID int64, integrationName string, maxSize int64) ([]sdk.CacheEntry, error) {
+	var entries []sdk.CacheEntry
+	if _, err := db.Select(&entries, `
+		SELECT id, project_id, integration_name, tag, size, created, last_access
+		FROM (
+			SELECT *, sum(size) OVER (ORDER BY last_access DESC, id DESC) AS total
+			FROM cache_entry
+			WHERE project_id = $1 AND integration_name = $2
+		) AS entries
+		WHERE total > $3
+		ORDER BY last_access ASC`, projectID, integrationName, maxSize); err != nil {
+		return nil, sdk.WrapError(err, "unable to load cache entries to evict")
+	}
+	return entries, nil
+}
+
+// DeleteCacheEntry removes a cache entry.
+func DeleteCacheEntry(db gorp.SqlExecutor, id int64) error {
+	if _, err := db.Exec("DELETE FROM cache_entry WHERE id = $1", id); err != nil {
+		return sdk.WrapError(err, "unable to delete cache entry %d", id)
+	}
+	return nil
+}
@@ -57,10 +57,8 @@ func insertNodeContextData(db gorp.SqlExecutor, w *sdk.Workflow, n *sdk.Node) er
 		}
 	}
 
-	for _, cond := range n.Context.Conditions.PlainConditions {
-		if _, ok := sdk.WorkflowConditionsOperators[cond.Operator]; !ok {
-			return sdk.ErrWorkflowConditionBadOperator
-		}
+	if err := n.Context.Conditions.IsValid(); err != nil {
+		return err
 	}
 
 	var errC error
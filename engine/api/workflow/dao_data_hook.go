@@ -26,6 +26,26 @@ func CountRepositoryWebHooksByApplication(db gorp.SqlExecutor, appID int64) (int
 	return count, nil
 }
 
+// LoadWorkflowNamesByCalendar returns the names of the workflows of a project with a scheduler that uses given calendar.
+func LoadWorkflowNamesByCalendar(db gorp.SqlExecutor, projectID int64, calendar string) ([]string, error) {
+	query := `
+    SELECT DISTINCT workflow.name
+    FROM w_node_hook
+    JOIN w_node ON w_node.id = w_node_hook.node_id
+    JOIN workflow ON workflow.id = w_node.workflow_id
+    JOIN workflow_hook_model ON workflow_hook_model.id = w_node_hook.hook_model_id
+	WHERE workflow.project_id = $1
+	AND workflow_hook_model.name = $2
+	AND w_node_hook.config->$3->>'value' = $4
+	ORDER BY workflow.name;
+  `
+	var names []string
+	if _, err := db.Select(&names, query, projectID, sdk.SchedulerModelName, sdk.SchedulerModelCalendar, calendar); err != nil {
+		return nil, sdk.WithStack(err)
+	}
+	return names, nil
+}
+
 // LoadHookByUUID load a hook by his uuid
 func LoadHookByUUID(db gorp.SqlExecutor, uuid string) (sdk.NodeHook, error) {
 	var hook sdk.NodeHook
@@ -50,7 +50,8 @@ workflow_node_run.uuid,
 workflow_node_run.outgoinghook,
 workflow_node_run.hook_execution_timestamp,
 workflow_node_run.execution_id,
-workflow_node_run.callback
+workflow_node_run.callback,
+workflow_node_run.approvals
 `
 
 const nodeRunTestsField string = ", workflow_node_run.tests"
@@ -378,6 +379,12 @@ func fromDBNodeRun(rr NodeRun, opts LoadRunOptions) (*sdk.WorkflowNodeRun, error
 		}
 	}
 
+	if rr.Approvals.Valid {
+		if err := gorpmapping.JSONNullString(rr.Approvals, &r.Approvals); err != nil {
+			return nil, sdk.WrapError(err, "Error loading node run %d: Approvals", r.ID)
+		}
+	}
+
 	return r, nil
 }
 
@@ -503,6 +510,14 @@ func makeDBNodeRun(n sdk.WorkflowNodeRun) (*NodeRun, error) {
 	}
 	nodeRunDB.OutgoingHook = oh
 
+	if n.Approvals != nil {
+		s, err := gorpmapping.JSONToNullString(n.Approvals)
+		if err != nil {
+			return nil, sdk.WrapError(err, "unable to get json from approvals")
+		}
+		nodeRunDB.Approvals = s
+	}
+
 	return nodeRunDB, nil
 }
 
@@ -286,6 +286,38 @@ func LoadDeadNodeJobRun(ctx context.Context, db gorp.SqlExecutor, store cache.St
 	return deadJobs, nil
 }
 
+//LoadTimedOutNodeJobRun load NodeJobRuns which are Building with a timeout exceeded since the given grace period
+func LoadTimedOutNodeJobRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, gracePeriod time.Duration) ([]sdk.WorkflowNodeJobRun, error) {
+	var jobsDB []JobRun
+	query := `
+	SELECT workflow_node_run_job.*
+	FROM workflow_node_run_job
+	WHERE status = $1
+	AND COALESCE((job->'action'->>'timeout')::BIGINT, 0) > 0
+	AND start + ((job->'action'->>'timeout')::BIGINT + $2) * INTERVAL '1 second' < NOW()`
+	if _, err := db.Select(&jobsDB, query, sdk.StatusBuilding, int64(gracePeriod.Seconds())); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, sdk.WithStack(err)
+	}
+
+	jobs := make([]sdk.WorkflowNodeJobRun, len(jobsDB))
+	for i := range jobsDB {
+		if store != nil {
+			getHatcheryInfo(ctx, store, &jobsDB[i])
+		}
+
+		jr, err := jobsDB[i].WorkflowNodeRunJob()
+		if err != nil {
+			return nil, err
+		}
+		jobs[i] = jr
+	}
+
+	return jobs, nil
+}
+
 //LoadAndLockNodeJobRunWait load for update a NodeJobRun given its ID
 func LoadAndLockNodeJobRunWait(ctx context.Context, db gorp.SqlExecutor, store cache.Store, id int64) (*sdk.WorkflowNodeJobRun, error) {
 	j := JobRun{}
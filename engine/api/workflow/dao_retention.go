@@ -0,0 +1,129 @@
+package workflow
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// RetentionPolicy is the retention policy of a workflow with its history length.
+type RetentionPolicy struct {
+	HistoryLength int64
+	Policy        sdk.WorkflowRetentionPolicy
+}
+
+// LoadRetentionPolicies returns the retention policy of each workflow that defines one.
+func LoadRetentionPolicies(db gorp.SqlExecutor) (map[int64]RetentionPolicy, error) {
+	var res []struct {
+		ID              int64          `db:"id"`
+		HistoryLength   int64          `db:"history_length"`
+		RetentionPolicy sql.NullString `db:"retention_policy"`
+	}
+	if _, err := db.Select(&res, "SELECT id, history_length, retention_policy FROM workflow WHERE retention_policy IS NOT NULL AND to_delete = false ORDER BY id ASC"); err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflows with retention policy")
+	}
+	policies := make(map[int64]RetentionPolicy, len(res))
+	for _, r := range res {
+		var p sdk.WorkflowRetentionPolicy
+		if err := gorpmapping.JSONNullString(r.RetentionPolicy, &p); err != nil {
+			return nil, sdk.WrapError(err, "unable to unmarshall retention policy of workflow %d", r.ID)
+		}
+		policies[r.ID] = RetentionPolicy{HistoryLength: r.HistoryLength, Policy: p}
+	}
+	return policies, nil
+}
+
+// LoadRunsRetention returns the workflow runs not marked to delete with their tags.
+func LoadRunsRetention(db gorp.SqlExecutor, workflowID int64) ([]sdk.WorkflowRunRetention, error) {
+	var runs []sdk.WorkflowRunRetention
+	if _, err := db.Select(&runs, `
+		SELECT id, num, status, last_modified
+		FROM workflow_run
+		WHERE workflow_id = $1 AND to_delete = false
+		ORDER BY num DESC`, workflowID); err != nil {
+		return nil, sdk.WrapError(err, "unable to load runs for workflow %d", workflowID)
+	}
+	if len(runs) == 0 {
+		return runs, nil
+	}
+
+	ids := make([]int64, len(runs))
+	for i := range runs {
+		ids[i] = runs[i].ID
+	}
+	var dbTags []RunTag
+	if _, err := db.Select(&dbTags, "SELECT * FROM workflow_run_tag WHERE workflow_run_id = ANY(string_to_array($1, ',')::int[])",
+		gorpmapping.IDsToQueryString(ids)); err != nil {
+		return nil, sdk.WrapError(err, "unable to load tags for workflow %d", workflowID)
+	}
+	tagsByRun := make(map[int64][]sdk.WorkflowRunTag, len(runs))
+	for _, t := range dbTags {
+		tagsByRun[t.WorkflowRunID] = append(tagsByRun[t.WorkflowRunID], sdk.WorkflowRunTag(t))
+	}
+	for i := range runs {
+		runs[i].Tags = tagsByRun[runs[i].ID]
+	}
+
+	return runs, nil
+}
+
+// InsertDeletedBranch records the deletion date of a branch for a workflow.
+func InsertDeletedBranch(db gorp.SqlExecutor, workflowID int64, branch string) error {
+	if _, err := db.Exec(`
+		INSERT INTO workflow_deleted_branch (workflow_id, branch, deleted) VALUES ($1, $2, $3)
+		ON CONFLICT (workflow_id, branch) DO UPDATE SET deleted = $3`, workflowID, branch, time.Now()); err != nil {
+		return sdk.WrapError(err, "unable to insert deleted branch %s for workflow %d", branch, workflowID)
+	}
+	return nil
+}
+
+// LoadDeletedBranches returns the deletion date of each deleted branch of a workflow.
+func LoadDeletedBranches(db gorp.SqlExecutor, workflowID int64) (map[string]time.Time, error) {
+	var res []struct {
+		Branch  string    `db:"branch"`
+		Deleted time.Time `db:"deleted"`
+	}
+	if _, err := db.Select(&res, "SELECT branch, deleted FROM workflow_deleted_branch WHERE workflow_id = $1", workflowID); err != nil {
+		return nil, sdk.WrapError(err, "unable to load deleted branches for workflow %d", workflowID)
+	}
+	branches := make(map[string]time.Time, len(res))
+	for _, r := range res {
+		branches[r.Branch] = r.Deleted
+	}
+	return branches, nil
+}
+
+// LoadArtifactsByRunIDs returns the artifacts of given workflow runs.
+func LoadArtifactsByRunIDs(db gorp.SqlExecutor, runIDs []int64) ([]sdk.WorkflowNodeRunArtifact, error) {
+	var artifactsGorp []NodeRunArtifact
+	if _, err := db.Select(&artifactsGorp, `SELECT
+			id,
+			name,
+			tag,
+			ref,
+			workflow_node_run_id,
+			download_hash,
+			size,
+			perm,
+			md5sum,
+			object_path,
+			created,
+			workflow_run_id,
+			project_integration_id,
+			coalesce(sha512sum, '') AS sha512sum,
+			coalesce(blob_sha256, '') AS blob_sha256
+		FROM workflow_node_run_artifacts WHERE workflow_run_id = ANY(string_to_array($1, ',')::int[])`,
+		gorpmapping.IDsToQueryString(runIDs)); err != nil {
+		return nil, sdk.WrapError(err, "unable to load artifacts")
+	}
+
+	artifacts := make([]sdk.WorkflowNodeRunArtifact, len(artifactsGorp))
+	for i := range artifactsGorp {
+		artifacts[i] = sdk.WorkflowNodeRunArtifact(artifactsGorp[i])
+	}
+	return artifacts, nil
+}
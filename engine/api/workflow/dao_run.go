@@ -238,6 +238,36 @@ func LoadLastRun(db gorp.SqlExecutor, projectkey, workflowname string, loadOpts
 	return loadRun(db, loadOpts, query, projectkey, workflowname)
 }
 
+// LoadLastSuccessfulRun returns the last successful run for a workflow, on given branch if not empty
+func LoadLastSuccessfulRun(db gorp.SqlExecutor, projectkey, workflowname, branch string, loadOpts LoadRunOptions) (*sdk.WorkflowRun, error) {
+	if branch == "" {
+		query := fmt.Sprintf(`select %s
+		from workflow_run
+		join project on workflow_run.project_id = project.id
+		join workflow on workflow_run.workflow_id = workflow.id
+		where project.projectkey = $1
+		and workflow.name = $2
+		and workflow_run.status = $3
+		and workflow_run.to_delete = false
+		order by workflow_run.num desc limit 1`, wfRunfields)
+		return loadRun(db, loadOpts, query, projectkey, workflowname, sdk.StatusSuccess)
+	}
+
+	query := fmt.Sprintf(`select %s
+	from workflow_run
+	join project on workflow_run.project_id = project.id
+	join workflow on workflow_run.workflow_id = workflow.id
+	join workflow_run_tag on workflow_run_tag.workflow_run_id = workflow_run.id
+	where project.projectkey = $1
+	and workflow.name = $2
+	and workflow_run.status = $3
+	and workflow_run.to_delete = false
+	and workflow_run_tag.tag = $4
+	and workflow_run_tag.value = $5
+	order by workflow_run.num desc limit 1`, wfRunfields)
+	return loadRun(db, loadOpts, query, projectkey, workflowname, sdk.StatusSuccess, "git.branch", branch)
+}
+
 // LockRun locks a workflow run
 func LockRun(db gorp.SqlExecutor, id int64) (*sdk.WorkflowRun, error) {
 	query := fmt.Sprintf(`SELECT %s
@@ -303,6 +333,14 @@ func LoadRunByID(db gorp.SqlExecutor, id int64, loadOpts LoadRunOptions) (*sdk.W
 	return loadRun(db, loadOpts, query, id)
 }
 
+// LoadAndLockRunByID loads a run by its id and locks it until the end of the transaction.
+func LoadAndLockRunByID(db gorp.SqlExecutor, id int64, loadOpts LoadRunOptions) (*sdk.WorkflowRun, error) {
+	query := fmt.Sprintf(`select %s
+	from workflow_run
+	where workflow_run.id = $1 for update`, wfRunfields)
+	return loadRun(db, loadOpts, query, id)
+}
+
 // LoadAndLockRunByJobID loads a run by a job id
 func LoadAndLockRunByJobID(db gorp.SqlExecutor, id int64, loadOpts LoadRunOptions) (*sdk.WorkflowRun, error) {
 	query := fmt.Sprintf(`select %s
@@ -728,6 +766,12 @@ func PurgeWorkflowRun(ctx context.Context, db gorp.SqlExecutor, wf sdk.Workflow,
 		return nil
 	}
 
+	// Runs of workflows with a retention policy are purged by the purge goroutine, that also applies the history length
+	if wf.RetentionPolicy != nil && !wf.RetentionPolicy.IsEmpty() {
+		log.Debug("PurgeWorkflowRun> workflow %d has a retention policy, skipping purge", wf.ID)
+		return nil
+	}
+
 	filteredPurgeTags := []string{}
 	for _, t := range wf.PurgeTags {
 		if t != "" {
@@ -0,0 +1,315 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/services"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// maxSearchDocumentLogsSize is the max size of the step logs indexed for a workflow run.
+const maxSearchDocumentLogsSize = 1024 * 1024
+
+// escapeLikePattern escapes the wildcards of a LIKE pattern, so given value is matched as is.
+func escapeLikePattern(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(v)
+}
+
+// escapeBytea returns given value in the escape format of a bytea, used to match a text in a bytea column
+// converted with encode(column, 'escape').
+func escapeBytea(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		switch c := v[i]; {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c == 0 || c >= 0x80:
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// SearchRuns returns the ids of the runs matching given filter ordered by start date desc, and the total count of matching runs.
+func SearchRuns(db gorp.SqlExecutor, f sdk.WorkflowRunSearchFilter) ([]int64, int64, error) {
+	args := []interface{}{f.ProjectKey, f.WorkflowName}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conds := []string{
+		"project.projectkey = $1",
+		"workflow.name = $2",
+		"workflow_run.to_delete = false",
+	}
+	tagCond := func(tag, value string) string {
+		return fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM workflow_run_tag
+			WHERE workflow_run_tag.workflow_run_id = workflow_run.id AND workflow_run_tag.tag = %s AND workflow_run_tag.value = %s
+		)`, arg(tag), arg(value))
+	}
+
+	if f.Commit != "" {
+		p := arg(escapeLikePattern(f.Commit) + "%")
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM workflow_node_run
+			WHERE workflow_node_run.workflow_run_id = workflow_run.id
+			AND (workflow_node_run.vcs_hash LIKE %s OR EXISTS (
+				SELECT 1 FROM jsonb_array_elements(CASE WHEN jsonb_typeof(workflow_node_run.commits) = 'array' THEN workflow_node_run.commits ELSE '[]'::jsonb END) commit
+				WHERE commit->>'id' LIKE %s
+			))
+		)`, p, p))
+	}
+	if f.Author != "" {
+		p := arg("%" + escapeLikePattern(strings.ToLower(f.Author)) + "%")
+		conds = append(conds, fmt.Sprintf(`(EXISTS (
+			SELECT 1 FROM workflow_run_tag
+			WHERE workflow_run_tag.workflow_run_id = workflow_run.id AND workflow_run_tag.tag = %s AND lower(workflow_run_tag.value) LIKE %s
+		) OR EXISTS (
+			SELECT 1 FROM workflow_node_run
+			JOIN LATERAL jsonb_array_elements(CASE WHEN jsonb_typeof(workflow_node_run.commits) = 'array' THEN workflow_node_run.commits ELSE '[]'::jsonb END) commit ON true
+			WHERE workflow_node_run.workflow_run_id = workflow_run.id
+			AND (lower(commit->'author'->>'name') LIKE %s OR lower(commit->'author'->>'emailAddress') LIKE %s)
+		))`, arg(tagGitAuthor), p, p, p))
+	}
+	if f.Branch != "" {
+		conds = append(conds, tagCond(tagGitBranch, f.Branch))
+	}
+	if f.Tag != "" {
+		conds = append(conds, tagCond(tagGitTag, f.Tag))
+	}
+	if len(f.Status) > 0 {
+		conds = append(conds, fmt.Sprintf("workflow_run.status = ANY(string_to_array(%s, ',')::text[])", arg(strings.Join(f.Status, ","))))
+	}
+	if f.MinDuration > 0 {
+		conds = append(conds, fmt.Sprintf("EXTRACT(EPOCH FROM (workflow_run.last_modified - workflow_run.start)) >= %s", arg(f.MinDuration)))
+	}
+	if f.MaxDuration > 0 {
+		conds = append(conds, fmt.Sprintf("EXTRACT(EPOCH FROM (workflow_run.last_modified - workflow_run.start)) <= %s", arg(f.MaxDuration)))
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, fmt.Sprintf("workflow_run.start >= %s", arg(f.From)))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, fmt.Sprintf("workflow_run.start < %s", arg(f.To)))
+	}
+	if f.HookType == sdk.WorkflowRunSearchManual {
+		conds = append(conds, `EXISTS (
+			SELECT 1 FROM workflow_node_run
+			WHERE workflow_node_run.workflow_run_id = workflow_run.id AND workflow_node_run.manual IS NOT NULL AND workflow_node_run.manual::text <> 'null'
+		)`)
+	} else if f.HookType != "" {
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM workflow_node_run
+			JOIN w_node_hook ON w_node_hook.uuid = workflow_node_run.hook_event->>'uuid'
+			JOIN workflow_hook_model ON workflow_hook_model.id = w_node_hook.hook_model_id
+			WHERE workflow_node_run.workflow_run_id = workflow_run.id AND workflow_hook_model.name = %s
+		)`, arg(f.HookType)))
+	}
+	if f.Text != "" {
+		// Only the logs stored in database can be searched without elasticsearch
+		if _, ok := GetLogStore().(DatabaseLogStore); !ok {
+			return nil, 0, sdk.NewErrorFrom(sdk.ErrWrongRequest, "text search in step logs is only available with the elasticsearch service when logs are kept in the object store")
+		}
+		conds = append(conds, fmt.Sprintf(`EXISTS (
+			SELECT 1 FROM workflow_node_run
+			JOIN workflow_node_run_job_logs ON workflow_node_run_job_logs.workflow_node_run_id = workflow_node_run.id
+			WHERE workflow_node_run.workflow_run_id = workflow_run.id AND encode(workflow_node_run_job_logs.value, 'escape') ILIKE %s
+		)`, arg("%"+escapeLikePattern(escapeBytea(f.Text))+"%")))
+	}
+
+	from := `
+	FROM workflow_run
+	JOIN project ON workflow_run.project_id = project.id
+	JOIN workflow ON workflow_run.workflow_id = workflow.id
+	WHERE ` + strings.Join(conds, "\n\tAND ")
+
+	count, err := db.SelectInt("SELECT COUNT(workflow_run.id)"+from, args...)
+	if err != nil {
+		return nil, 0, sdk.WrapError(err, "unable to count runs")
+	}
+	if count == 0 {
+		return nil, 0, nil
+	}
+
+	query := "SELECT workflow_run.id" + from + fmt.Sprintf("\n\tORDER BY workflow_run.start DESC LIMIT %s OFFSET %s", arg(f.Limit), arg(f.Offset))
+	var ids []int64
+	if _, err := db.Select(&ids, query, args...); err != nil {
+		return nil, 0, sdk.WrapError(err, "unable to search runs")
+	}
+	return ids, count, nil
+}
+
+// LoadRunsByIDs returns the runs with given ids and their tags, in the order of the ids.
+func LoadRunsByIDs(db gorp.SqlExecutor, projectKey string, ids []int64) ([]sdk.WorkflowRun, error) {
+	if len(ids) == 0 {
+		return []sdk.WorkflowRun{}, nil
+	}
+
+	query := fmt.Sprintf(`select %s
+	from workflow_run
+	join project on workflow_run.project_id = project.id
+	where project.projectkey = $1 AND workflow_run.id = ANY(string_to_array($2, ',')::bigint[])`, wfRunfields)
+
+	idsS := make([]string, len(ids))
+	for i := range ids {
+		idsS[i] = fmt.Sprintf("%d", ids[i])
+	}
+
+	var runs []Run
+	if _, err := db.Select(&runs, query, projectKey, strings.Join(idsS, ",")); err != nil {
+		return nil, sdk.WrapError(err, "unable to load runs")
+	}
+
+	byID := make(map[int64]sdk.WorkflowRun, len(runs))
+	for i := range runs {
+		wr := sdk.WorkflowRun(runs[i])
+		if err := loadRunTags(db, &wr); err != nil {
+			return nil, sdk.WrapError(err, "unable to load tags")
+		}
+		byID[wr.ID] = wr
+	}
+
+	wruns := make([]sdk.WorkflowRun, 0, len(ids))
+	for _, id := range ids {
+		if wr, ok := byID[id]; ok {
+			wruns = append(wruns, wr)
+		}
+	}
+	return wruns, nil
+}
+
+// IndexRun sends the search document of a terminated run to the elasticsearch service if any.
+func IndexRun(ctx context.Context, db gorp.SqlExecutor, projectKey string, wr sdk.WorkflowRun) {
+	srvs, err := services.LoadAllByType(ctx, db, services.TypeElasticsearch)
+	if err != nil {
+		log.Error(ctx, "IndexRun> unable to get elasticsearch service: %v", err)
+		return
+	}
+	if len(srvs) == 0 {
+		return
+	}
+
+	run, err := LoadRunByID(db, wr.ID, LoadRunOptions{})
+	if err != nil {
+		log.Warning(ctx, "IndexRun> unable to load workflow run %d: %v", wr.ID, err)
+		return
+	}
+
+	doc := newRunSearchDocument(ctx, db, projectKey, *run)
+	if _, code, err := services.DoJSONRequest(ctx, db, srvs, "POST", "/runs", doc, nil); code >= 400 || err != nil {
+		log.Error(ctx, "IndexRun> unable to index workflow run %d [%d]: %v", wr.ID, code, err)
+	}
+}
+
+func newRunSearchDocument(ctx context.Context, db gorp.SqlExecutor, projectKey string, wr sdk.WorkflowRun) sdk.WorkflowRunSearchDocument {
+	doc := sdk.WorkflowRunSearchDocument{
+		ID:           wr.ID,
+		ProjectKey:   projectKey,
+		WorkflowName: wr.Workflow.Name,
+		Number:       wr.Number,
+		Status:       wr.Status,
+		Start:        wr.Start,
+		End:          wr.LastModified,
+		Duration:     int64(wr.LastModified.Sub(wr.Start).Seconds()),
+	}
+
+	values := map[string]map[string]struct{}{}
+	add := func(name, value string) {
+		if value == "" {
+			return
+		}
+		if _, ok := values[name]; !ok {
+			values[name] = map[string]struct{}{}
+		}
+		values[name][value] = struct{}{}
+	}
+
+	hooks := wr.Workflow.WorkflowData.GetHooks()
+	var logs strings.Builder
+	for _, nrs := range wr.WorkflowNodeRuns {
+		for _, nr := range nrs {
+			add("branches", nr.VCSBranch)
+			add("tags", nr.VCSTag)
+			add("commits", nr.VCSHash)
+			for _, c := range nr.Commits {
+				add("commits", c.Hash)
+				add("authors", c.Author.Name)
+				add("authors", c.Author.Email)
+			}
+			if nr.Manual != nil {
+				add("hooks", sdk.WorkflowRunSearchManual)
+			}
+			if nr.HookEvent != nil {
+				if h, ok := hooks[nr.HookEvent.WorkflowNodeHookUUID]; ok {
+					add("hooks", h.HookModelName)
+				}
+			}
+
+			for _, s := range nr.Stages {
+				for _, rj := range s.RunJobs {
+					for _, ss := range rj.Job.StepStatus {
+						// Only the beginning of the logs is loaded, up to the max size of the document
+						size := int64(maxSearchDocumentLogsSize - logs.Len())
+						if size <= 0 {
+							continue
+						}
+						val, err := GetLogStore().LoadHead(ctx, db, rj.ID, int64(ss.StepOrder), size)
+						if err != nil {
+							log.Warning(ctx, "IndexRun> unable to load logs of job %d step %d: %v", rj.ID, ss.StepOrder, err)
+							continue
+						}
+						logs.WriteString(val)
+					}
+				}
+			}
+		}
+	}
+	for _, t := range wr.Tags {
+		if t.Tag == tagGitAuthor {
+			add("authors", t.Value)
+		}
+	}
+
+	list := func(name string) []string {
+		var res []string
+		for v := range values[name] {
+			res = append(res, v)
+		}
+		sort.Strings(res)
+		return res
+	}
+	doc.Branches = list("branches")
+	doc.Tags = list("tags")
+	doc.Commits = list("commits")
+	doc.Authors = list("authors")
+	doc.HookTypes = list("hooks")
+	doc.Logs = logs.String()
+	return doc
+}
+
+// SearchRunsInIndex searches for runs in the elasticsearch service, it returns sdk.ErrNotFound if no service is available.
+func SearchRunsInIndex(ctx context.Context, db gorp.SqlExecutor, f sdk.WorkflowRunSearchFilter) ([]int64, int64, error) {
+	srvs, err := services.LoadAllByType(ctx, db, services.TypeElasticsearch)
+	if err != nil {
+		return nil, 0, sdk.WrapError(err, "unable to get elasticsearch service")
+	}
+	if len(srvs) == 0 {
+		return nil, 0, sdk.WithStack(sdk.ErrNotFound)
+	}
+
+	var res sdk.WorkflowRunSearchResult
+	if _, _, err := services.DoJSONRequest(ctx, db, srvs, "GET", "/runs", f, &res); err != nil {
+		return nil, 0, sdk.WrapError(err, "unable to search runs")
+	}
+	return res.IDs, res.Total, nil
+}
@@ -0,0 +1,132 @@
+package workflow_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestSearchRuns(t *testing.T) {
+	db, cache, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	u, _ := assets.InsertAdminUser(t, db)
+	consumer, _ := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, cache, proj, sdk.RandomString(10))
+	w1, err := workflow.Load(context.TODO(), db, cache, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+	require.NoError(t, err)
+
+	// The first run is started with a commit and logs, the second one is only created
+	wr1, err := workflow.CreateRun(db, w1, nil, u)
+	require.NoError(t, err)
+	wr1.Workflow = *w1
+	_, err = workflow.StartWorkflowRun(context.TODO(), db, cache, proj, wr1, &sdk.WorkflowRunPostHandlerOption{
+		Manual: &sdk.WorkflowNodeRunManual{Username: u.Username},
+	}, consumer, nil)
+	require.NoError(t, err)
+	require.NoError(t, workflow.InsertWorkflowRunTags(db, wr1.ID, []sdk.WorkflowRunTag{
+		{Tag: "git.branch", Value: "master"},
+		{Tag: "git.author", Value: "john_doe"},
+	}))
+
+	wr1, err = workflow.LoadRunByID(db, wr1.ID, workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	nodeRun := &wr1.WorkflowNodeRuns[w1.WorkflowData.Node.ID][0]
+	nodeRun.VCSHash = "abc123"
+	require.NoError(t, workflow.UpdateNodeRun(db, nodeRun))
+	jobRun := nodeRun.Stages[0].RunJobs[0]
+	_, err = workflow.DatabaseLogStore{}.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: nodeRun.ID, StepOrder: 0, Val: "100% done\n"}, 0)
+	require.NoError(t, err)
+
+	wr2, err := workflow.CreateRun(db, w1, nil, u)
+	require.NoError(t, err)
+	wr2.Status = sdk.StatusFail
+	require.NoError(t, workflow.UpdateWorkflowRunStatus(db, wr2))
+	require.NoError(t, workflow.InsertWorkflowRunTags(db, wr2.ID, []sdk.WorkflowRunTag{
+		{Tag: "git.branch", Value: "feat/x"},
+		{Tag: "git.author", Value: "johnxdoe"},
+	}))
+
+	tests := []struct {
+		name     string
+		filter   sdk.WorkflowRunSearchFilter
+		expected []int64
+	}{
+		{name: "all", expected: []int64{wr2.ID, wr1.ID}},
+		{name: "branch", filter: sdk.WorkflowRunSearchFilter{Branch: "master"}, expected: []int64{wr1.ID}},
+		{name: "author", filter: sdk.WorkflowRunSearchFilter{Author: "JOHN"}, expected: []int64{wr2.ID, wr1.ID}},
+		{name: "author with wildcard", filter: sdk.WorkflowRunSearchFilter{Author: "john_"}, expected: []int64{wr1.ID}},
+		{name: "commit", filter: sdk.WorkflowRunSearchFilter{Commit: "abc"}, expected: []int64{wr1.ID}},
+		{name: "commit with wildcard", filter: sdk.WorkflowRunSearchFilter{Commit: "a%"}},
+		{name: "status", filter: sdk.WorkflowRunSearchFilter{Status: []string{sdk.StatusFail, sdk.StatusStopped}}, expected: []int64{wr2.ID}},
+		{name: "manual", filter: sdk.WorkflowRunSearchFilter{HookType: sdk.WorkflowRunSearchManual}, expected: []int64{wr1.ID}},
+		{name: "text", filter: sdk.WorkflowRunSearchFilter{Text: "100% DONE"}, expected: []int64{wr1.ID}},
+		{name: "text with wildcard", filter: sdk.WorkflowRunSearchFilter{Text: "1_0"}},
+		{name: "multiple filters", filter: sdk.WorkflowRunSearchFilter{Branch: "master", Status: []string{sdk.StatusFail}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.filter
+			f.ProjectKey = proj.Key
+			f.WorkflowName = w1.Name
+			f.Limit = 10
+			ids, count, err := workflow.SearchRuns(db, f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ids)
+			assert.Equal(t, int64(len(tt.expected)), count)
+		})
+	}
+
+	// The count is not limited by the pagination
+	ids, count, err := workflow.SearchRuns(db, sdk.WorkflowRunSearchFilter{ProjectKey: proj.Key, WorkflowName: w1.Name, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{wr1.ID}, ids)
+	assert.Equal(t, int64(2), count)
+
+	// The logs kept in the object store can't be searched in database
+	driver, clean := newLogStoreTestDriver(t)
+	defer clean()
+	workflow.SetLogStore(workflow.NewObjectStoreLogStore(driver))
+	defer workflow.SetLogStore(workflow.DatabaseLogStore{})
+	_, _, err = workflow.SearchRuns(db, sdk.WorkflowRunSearchFilter{ProjectKey: proj.Key, WorkflowName: w1.Name, Text: "done", Limit: 10})
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+}
+
+func TestObjectStoreLogStoreLoadHead(t *testing.T) {
+	db, cache, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+	driver, clean := newLogStoreTestDriver(t)
+	defer clean()
+
+	jobRun := insertLogStoreTestJobRun(t, db, cache)
+	s := workflow.NewObjectStoreLogStore(driver)
+
+	_, err := workflow.DatabaseLogStore{}.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 0, Val: "db\n"}, 0)
+	require.NoError(t, err)
+	head, err := workflow.DatabaseLogStore{}.LoadHead(context.TODO(), db, jobRun.ID, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "db", head)
+
+	_, err = s.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 0, Val: "chunk\n"}, 0)
+	require.NoError(t, err)
+	for size, expected := range map[int64]string{0: "", 2: "db", 5: "db\nch", 20: "db\nchunk\n"} {
+		head, err := s.LoadHead(context.TODO(), db, jobRun.ID, 0, size)
+		require.NoError(t, err)
+		assert.Equal(t, expected, head, "size %d", size)
+	}
+
+	head, err = s.LoadHead(context.TODO(), db, jobRun.ID, 1, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "", head)
+}
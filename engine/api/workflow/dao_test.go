@@ -20,6 +20,7 @@ import (
 	"github.com/ovh/cds/engine/api/application"
 	"github.com/ovh/cds/engine/api/bootstrap"
 	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/engine/api/integration"
 	"github.com/ovh/cds/engine/api/permission"
 	"github.com/ovh/cds/engine/api/pipeline"
 	"github.com/ovh/cds/engine/api/project"
@@ -1822,3 +1823,46 @@ vcs_ssh_key: proj-blabla
 	}
 
 }
+
+func TestUpdateWorkflowWithBrokerHookIntegration(t *testing.T) {
+	db, cache, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, cache, proj, sdk.RandomString(10))
+
+	kafkaModel, err := integration.LoadModelByName(db, sdk.KafkaIntegrationModel, false)
+	require.NoError(t, err)
+	projInt := sdk.ProjectIntegration{
+		Name:               "my-kafka",
+		ProjectID:          proj.ID,
+		Model:              kafkaModel,
+		IntegrationModelID: kafkaModel.ID,
+		Config:             kafkaModel.DefaultConfig.Clone(),
+	}
+	require.NoError(t, integration.InsertIntegration(db, &projInt))
+
+	addHook := func(hookModelName, integrationName string) error {
+		w1, err := workflow.Load(context.TODO(), db, cache, proj, w.Name, workflow.LoadOptions{})
+		require.NoError(t, err)
+		w1.WorkflowData.Node.Hooks = append(w1.WorkflowData.Node.Hooks, sdk.NodeHook{
+			HookModelName: hookModelName,
+			Config: sdk.WorkflowNodeHookConfig{
+				sdk.HookModelIntegration: {Value: integrationName, Type: sdk.HookConfigTypeIntegration},
+			},
+		})
+		return workflow.Update(context.TODO(), db, cache, w1, proj, workflow.UpdateOptions{DisableHookManagement: true})
+	}
+
+	// A hook can't consume a broker through an integration of another model
+	err = addHook(sdk.AMQPHookModelName, "my-kafka")
+	require.Error(t, err)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	err = addHook(sdk.NATSHookModelName, "unknown")
+	require.Error(t, err)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	require.NoError(t, addHook(sdk.KafkaHookModelName, "my-kafka"))
+}
@@ -0,0 +1,156 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// ApproveNodeRun adds an approval on a node run waiting for approval, when enough approvals
+// were given the node run succeed and the workflow is reprocessed to trigger its children.
+func ApproveNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, wr *sdk.WorkflowRun, nodeRun *sdk.WorkflowNodeRun, approval sdk.WorkflowNodeRunApproval) (*ProcessorReport, error) {
+	ctx, end := observability.Span(ctx, "workflow.ApproveNodeRun")
+	defer end()
+
+	report := new(ProcessorReport)
+
+	node := wr.Workflow.WorkflowData.NodeByID(nodeRun.WorkflowNodeID)
+	if node == nil || node.Type != sdk.NodeTypeApproval || node.ApprovalContext == nil {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "node %s is not an approval node", nodeRun.WorkflowNodeName)
+	}
+	if nodeRun.Status != sdk.StatusWaitingApproval {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "node run %s is not waiting for approval", nodeRun.WorkflowNodeName)
+	}
+	if node.ApprovalContext.IsExpired(nodeRun.Start) {
+		return nil, sdk.NewErrorFrom(sdk.ErrForbidden, "approval delay of node %s has expired", nodeRun.WorkflowNodeName)
+	}
+	if nodeRun.HasApproved(approval.UserID) {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "node run %s already approved by %s", nodeRun.WorkflowNodeName, approval.Username)
+	}
+
+	nodeRun.Approvals = append(nodeRun.Approvals, approval)
+	required := node.ApprovalContext.RequiredApprovals()
+	AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeApproved.ID,
+		Args: []interface{}{nodeRun.WorkflowNodeName, approval.Username, len(nodeRun.Approvals), required},
+	})
+
+	if len(nodeRun.Approvals) >= required {
+		nodeRun.Status = sdk.StatusSuccess
+		nodeRun.Done = time.Now()
+	}
+	nodeRun.LastModified = time.Now()
+
+	if err := UpdateNodeRun(db, nodeRun); err != nil {
+		return nil, sdk.WrapError(err, "unable to update approval node run %d", nodeRun.ID)
+	}
+	report.Add(ctx, *nodeRun)
+
+	if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+		return nil, sdk.WrapError(err, "unable to update workflow run")
+	}
+
+	if !sdk.StatusIsTerminated(nodeRun.Status) {
+		return report, nil
+	}
+
+loop:
+	for i := range wr.WorkflowNodeRuns {
+		nrs := wr.WorkflowNodeRuns[i]
+		for j := range nrs {
+			if nrs[j].ID == nodeRun.ID {
+				nrs[j] = *nodeRun
+				break loop
+			}
+		}
+	}
+
+	r1, _, err := processWorkflowDataRun(ctx, db, store, proj, wr, nil, nil, nil)
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to process workflow run after approval")
+	}
+	report.Merge(ctx, r1, nil) // nolint
+
+	return report, nil
+}
+
+// failExpiredApprovals set fail status on all node runs waiting for an approval after their expiry
+func failExpiredApprovals(ctx context.Context, DBFunc func() *gorp.DbMap) error {
+	db := DBFunc()
+
+	var nodeRuns []struct {
+		ID            int64     `db:"id"`
+		WorkflowRunID int64     `db:"workflow_run_id"`
+		Start         time.Time `db:"start"`
+	}
+	query := `SELECT id, workflow_run_id, start FROM workflow_node_run WHERE status = $1`
+	if _, err := db.Select(&nodeRuns, query, sdk.StatusWaitingApproval); err != nil {
+		return sdk.WrapError(err, "cannot load node runs waiting for approval")
+	}
+
+	for _, r := range nodeRuns {
+		wr, err := LoadRunByID(db, r.WorkflowRunID, LoadRunOptions{DisableDetailledNodeRun: true})
+		if err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot load workflow run %d: %v", r.WorkflowRunID, err)
+			continue
+		}
+		nodeRun, err := LoadNodeRunByID(db, r.ID, LoadRunOptions{DisableDetailledNodeRun: true})
+		if err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot load node run %d: %v", r.ID, err)
+			continue
+		}
+		node := wr.Workflow.WorkflowData.NodeByID(nodeRun.WorkflowNodeID)
+		if node == nil || node.ApprovalContext == nil || !node.ApprovalContext.IsExpired(r.Start) {
+			continue
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot create transaction: %v", err)
+			continue
+		}
+
+		report := new(ProcessorReport)
+		nodeRun.Status = sdk.StatusFail
+		nodeRun.Done = time.Now()
+		if err := UpdateNodeRun(tx, nodeRun); err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot update node run %d: %v", nodeRun.ID, err)
+			_ = tx.Rollback()
+			continue
+		}
+		report.Add(ctx, *nodeRun)
+
+		AddWorkflowRunInfo(wr, true, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeApprovalExpired.ID,
+			Args: []interface{}{nodeRun.WorkflowNodeName},
+		})
+		r1, err := computeAndUpdateWorkflowRunStatus(ctx, tx, wr)
+		if err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot compute workflow run %d status: %v", wr.ID, err)
+			_ = tx.Rollback()
+			continue
+		}
+		report.Merge(ctx, r1, nil) // nolint
+		if err := UpdateWorkflowRun(ctx, tx, wr); err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot update workflow run %d: %v", wr.ID, err)
+			_ = tx.Rollback()
+			continue
+		}
+		report.Add(ctx, *wr)
+
+		if err := tx.Commit(); err != nil {
+			log.Error(ctx, "failExpiredApprovals> cannot commit transaction: %v", err)
+			continue
+		}
+
+		go SendEvent(context.Background(), DBFunc(), wr.Workflow.ProjectKey, report)
+	}
+
+	return nil
+}
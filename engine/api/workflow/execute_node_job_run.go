@@ -26,6 +26,8 @@ type ProcessorReport struct {
 	nodes     []sdk.WorkflowNodeRun
 	workflows []sdk.WorkflowRun
 	errors    []error
+	// workflow runs to cancel because they are superseded by a new run
+	superseded []int64
 }
 
 // WorkflowRuns returns the list of concerned workflow runs
@@ -36,6 +38,16 @@ func (r *ProcessorReport) WorkflowRuns() []sdk.WorkflowRun {
 	return r.workflows
 }
 
+// SupersededRuns returns the ids of the workflow runs superseded by a new run according to the workflow concurrency
+func (r *ProcessorReport) SupersededRuns() []int64 {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.superseded
+}
+
 // Add something to the report
 func (r *ProcessorReport) Add(ctx context.Context, i ...interface{}) {
 	r.mutex.Lock()
@@ -85,6 +97,10 @@ func (r *ProcessorReport) Merge(ctx context.Context, r1 *ProcessorReport, err er
 	}
 	data := r1.All()
 	r.Add(ctx, data...)
+	superseded := r1.SupersededRuns()
+	r.mutex.Lock()
+	r.superseded = append(r.superseded, superseded...)
+	r.mutex.Unlock()
 	return r, err
 }
 
@@ -135,7 +151,7 @@ func UpdateNodeJobRunStatus(ctx context.Context, db gorp.SqlExecutor, store cach
 		job.Start = time.Now()
 		job.Status = status
 
-	case sdk.StatusFail, sdk.StatusSuccess, sdk.StatusDisabled, sdk.StatusSkipped, sdk.StatusStopped:
+	case sdk.StatusFail, sdk.StatusSuccess, sdk.StatusDisabled, sdk.StatusSkipped, sdk.StatusStopped, sdk.StatusTimeout:
 		if currentStatus != sdk.StatusWaiting && currentStatus != sdk.StatusBuilding && status != sdk.StatusDisabled && status != sdk.StatusSkipped {
 			log.Debug("workflow.UpdateNodeJobRunStatus> Status is %s, cannot update %d to %s", currentStatus, job.ID, status)
 			// too late, Nate
@@ -249,6 +265,10 @@ func TakeNodeJobRun(ctx context.Context, dbFunc func() *gorp.DbMap, db gorp.SqlE
 	if err := checkStatusWaiting(ctx, store, jobID, job.Status); err != nil {
 		return nil, report, err
 	}
+	// A job put back in the queue for a retry can't be taken before the end of its delay
+	if job.Queued.After(time.Now()) {
+		return nil, report, sdk.NewErrorFrom(sdk.ErrForbidden, "job %d can't be taken before the end of its retry delay (%s)", jobID, job.Queued.Format(time.RFC3339))
+	}
 
 	job.Model = workerModel
 	job.Job.WorkerName = workerName
@@ -512,9 +532,37 @@ func LoadSecrets(db gorp.SqlExecutor, store cache.Store, nodeRun *sdk.WorkflowNo
 			return nil, sdk.WrapError(err, "Unable to decrypt variables")
 		}
 	}
+
 	return secrets, nil
 }
 
+// backendSecretsTimeout is the maximum duration to fetch the secrets stored in the secret backend of a project.
+const backendSecretsTimeout = 10 * time.Second
+
+// ResolveBackendSecrets replaces the references to the secret backend of the project in given secrets by their
+// values. The backend is called over the network so this should not be called in a transaction.
+func ResolveBackendSecrets(ctx context.Context, db gorp.SqlExecutor, projectID int64, secrets []sdk.Variable) error {
+	var hasReference bool
+	for _, s := range secrets {
+		if sdk.IsVaultReference(s.Value) {
+			hasReference = true
+			break
+		}
+	}
+	if !hasReference {
+		return nil
+	}
+
+	integrations, err := integration.LoadIntegrationsByProjectID(db, projectID, true)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load project integrations")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backendSecretsTimeout)
+	defer cancel()
+	return secret.ResolveBackendSecrets(ctx, integrations, secrets)
+}
+
 //BookNodeJobRun  Book a job for a hatchery
 func BookNodeJobRun(ctx context.Context, store cache.Store, id int64, hatchery *sdk.Service) (*sdk.Service, error) {
 	k := keyBookJob(id)
@@ -553,29 +601,14 @@ func FreeNodeJobRun(ctx context.Context, store cache.Store, id int64) error {
 	return sdk.WrapError(sdk.ErrJobNotBooked, "BookNodeJobRun> job %d already released", id)
 }
 
-//AddLog adds a build log
-func AddLog(db gorp.SqlExecutor, job *sdk.WorkflowNodeJobRun, logs *sdk.Log, maxLogSize int64) error {
+//AddLog adds a build log and returns the offset where it was written, -1 if the max log size was already reached
+func AddLog(ctx context.Context, db gorp.SqlExecutor, job *sdk.WorkflowNodeJobRun, logs *sdk.Log, maxLogSize int64) (int64, error) {
 	if job != nil {
 		logs.JobID = job.ID
 		logs.NodeRunID = job.WorkflowNodeRunID
 	}
 
-	// check if log exists without loading data but with log size
-	exists, size, err := ExistsStepLog(db, logs.JobID, logs.StepOrder)
-	if err != nil {
-		return sdk.WrapError(err, "cannot check if log exists")
-	}
-
-	// ignore the log if max size already reached
-	if maxReached := truncateLogs(maxLogSize, size, logs); maxReached {
-		return nil
-	}
-
-	if !exists {
-		return sdk.WrapError(insertLog(db, logs), "cannot insert log")
-	}
-
-	return sdk.WrapError(updateLog(db, logs), "cannot update log")
+	return logStore.Append(ctx, db, logs, maxLogSize)
 }
 
 //AddServiceLog adds a service log
@@ -619,27 +652,9 @@ func RestartWorkflowNodeJob(ctx context.Context, db gorp.SqlExecutor, wNodeJob s
 	ctx, end = observability.Span(ctx, "workflow.RestartWorkflowNodeJob")
 	defer end()
 
-	for iS := range wNodeJob.Job.StepStatus {
-		step := &wNodeJob.Job.StepStatus[iS]
-		if step.Status == sdk.StatusNeverBuilt || step.Status == sdk.StatusSkipped || step.Status == sdk.StatusDisabled {
-			continue
-		}
-		l, errL := LoadStepLogs(db, wNodeJob.ID, int64(step.StepOrder))
-		if errL != nil {
-			return sdk.WrapError(errL, "RestartWorkflowNodeJob> error while load step logs")
-		}
-		wNodeJob.Job.Reason = "Killed (Reason: Timeout)\n"
-		step.Status = sdk.StatusWaiting
-		step.Done = time.Time{}
-		if l != nil { // log could be nil here
-			l.Done = nil
-			logbuf := bytes.NewBufferString(l.Val)
-			logbuf.WriteString("\n\n\n-=-=-=-=-=- Worker timeout: job replaced in queue -=-=-=-=-=-\n\n\n")
-			l.Val = logbuf.String()
-			if err := updateLog(db, l); err != nil {
-				return sdk.WrapError(errL, "RestartWorkflowNodeJob> error while update step log")
-			}
-		}
+	wNodeJob.Job.Reason = "Killed (Reason: Timeout)\n"
+	if err := resetStepStatusesAndLogs(ctx, db, &wNodeJob, "\n\n\n-=-=-=-=-=- Worker timeout: job replaced in queue -=-=-=-=-=-\n\n\n"); err != nil {
+		return sdk.WrapError(err, "RestartWorkflowNodeJob> error while resetting steps")
 	}
 
 	nodeRun, errNR := LoadAndLockNodeRunByID(ctx, db, wNodeJob.WorkflowNodeRunID)
@@ -666,3 +681,75 @@ func RestartWorkflowNodeJob(ctx context.Context, db gorp.SqlExecutor, wNodeJob s
 
 	return nil
 }
+
+// RetryWorkflowNodeJob puts a failed workflow node job back in the queue, it will be available
+// for workers after the given delay. Logs of previous attempts are kept.
+func RetryWorkflowNodeJob(ctx context.Context, db gorp.SqlExecutor, wNodeJob *sdk.WorkflowNodeJobRun, delay time.Duration) error {
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.RetryWorkflowNodeJob")
+	defer end()
+
+	msg := fmt.Sprintf("\n\n\n-=-=-=-=-=- Attempt %d/%d failed: job replaced in queue -=-=-=-=-=-\n\n\n",
+		len(wNodeJob.Attempts), wNodeJob.Job.Action.RetryPolicy.MaxAttempts)
+	if err := resetStepStatusesAndLogs(ctx, db, wNodeJob, msg); err != nil {
+		return sdk.WrapError(err, "error while resetting steps")
+	}
+
+	wNodeJob.Status = sdk.StatusWaiting
+	wNodeJob.Queued = time.Now().Add(delay)
+	wNodeJob.Start = time.Time{}
+	wNodeJob.Done = time.Time{}
+	wNodeJob.Job.Reason = ""
+	wNodeJob.Job.WorkerID = ""
+	wNodeJob.Job.WorkerName = ""
+	if err := UpdateNodeJobRun(ctx, db, wNodeJob); err != nil {
+		return sdk.WrapError(err, "cannot update node job run %d", wNodeJob.ID)
+	}
+
+	if _, err := db.Exec("UPDATE workflow_node_run_job SET worker_id = NULL WHERE id = $1", wNodeJob.ID); err != nil {
+		return sdk.WrapError(err, "unable to unset worker on node job run %d", wNodeJob.ID)
+	}
+	if _, err := db.Exec("UPDATE worker SET job_run_id = NULL WHERE job_run_id = $1", wNodeJob.ID); err != nil {
+		return sdk.WrapError(err, "unable to set workers")
+	}
+
+	nodeRun, err := LoadAndLockNodeRunByID(ctx, db, wNodeJob.WorkflowNodeRunID)
+	if err != nil {
+		return err
+	}
+
+	//Synchronize struct but not in db
+	sync, err := SyncNodeRunRunJob(ctx, db, nodeRun, *wNodeJob)
+	if err != nil {
+		return sdk.WrapError(err, "error on sync nodeJobRun")
+	}
+	if !sync {
+		log.Warning(ctx, "RetryWorkflowNodeJob> sync doesn't find a nodeJobRun")
+	}
+
+	return sdk.WrapError(UpdateNodeRun(db, nodeRun), "cannot update node run")
+}
+
+// resetStepStatusesAndLogs sets executed steps to waiting and appends the given message to their logs.
+func resetStepStatusesAndLogs(ctx context.Context, db gorp.SqlExecutor, wNodeJob *sdk.WorkflowNodeJobRun, msg string) error {
+	for iS := range wNodeJob.Job.StepStatus {
+		step := &wNodeJob.Job.StepStatus[iS]
+		if step.Status == sdk.StatusNeverBuilt || step.Status == sdk.StatusSkipped || step.Status == sdk.StatusDisabled {
+			continue
+		}
+		step.Status = sdk.StatusWaiting
+		step.Done = time.Time{}
+		now := time.Now()
+		l := &sdk.Log{
+			JobID:        wNodeJob.ID,
+			NodeRunID:    wNodeJob.WorkflowNodeRunID,
+			StepOrder:    int64(step.StepOrder),
+			LastModified: &now,
+			Val:          msg,
+		}
+		if _, err := logStore.Append(ctx, db, l, 0); err != nil {
+			return sdk.WrapError(err, "error while update step log")
+		}
+	}
+	return nil
+}
@@ -36,6 +36,7 @@ func syncJobInNodeRun(n *sdk.WorkflowNodeRun, j *sdk.WorkflowNodeJobRun, stageIn
 			rj.Job = j.Job
 			rj.Header = j.Header
 			rj.Parameters = j.Parameters
+			rj.Attempts = j.Attempts
 		}
 	}
 }
@@ -122,6 +123,20 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		return nil, nil
 	}
 
+	//If jobs are not queued yet, check that the node run holds its project lock
+	if len(nr.Stages) > 0 && nr.Stages[0].Status == "" {
+		acquired, err := acquireProjectLock(ctx, db, wr, wr.Workflow.WorkflowData.NodeByID(nr.WorkflowNodeID), nr)
+		if err != nil {
+			return nil, sdk.WrapError(err, "unable to acquire project lock")
+		}
+		if !acquired {
+			if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+				return nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+			}
+			return report, nil
+		}
+	}
+
 	var newStatus = nr.Status
 
 	//If no stages ==> success
@@ -261,6 +276,13 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 	// If pipeline build succeed, reprocess the workflow (in the same transaction)
 	//Delete jobs only when node is over
 	if sdk.StatusIsTerminated(nr.Status) {
+		//Release the project lock held by the node run
+		r0, err := releaseProjectLock(ctx, db, store, proj, nr)
+		if err != nil {
+			return nil, sdk.WrapError(err, "unable to release project lock")
+		}
+		report, _ = report.Merge(ctx, r0, nil)
+
 		if nr.Status != sdk.StatusStopped {
 			r1, _, err := processWorkflowDataRun(ctx, db, store, proj, updatedWorkflowRun, nil, nil, nil)
 			if err != nil {
@@ -377,110 +399,122 @@ func addJobsToQueue(ctx context.Context, db gorp.SqlExecutor, stage *sdk.Stage,
 
 	skippedOrDisabledJobs := 0
 	failedJobs := 0
+	nbJobRuns := 0
 	//Browse the jobs
 	for j := range stage.Jobs {
 		job := &stage.Jobs[j]
 
-		// errors generated in the loop will be added to job run spawn info
-		spawnErrs := sdk.MultiError{}
-
-		//Process variables for the jobs
-		_, next = observability.Span(ctx, "workflow..getNodeJobRunParameters")
-		jobParams, err := getNodeJobRunParameters(db, *job, nr, stage)
-		next()
-		if err != nil {
-			spawnErrs.Join(*err)
+		// A job with a matrix is run once for each combination of the matrix
+		matrixCombinations := job.Action.Matrix.Combinations()
+		if len(matrixCombinations) == 0 {
+			matrixCombinations = []map[string]string{nil}
 		}
 
-		_, next = observability.Span(ctx, "workflow.processNodeJobRunRequirements")
-		jobRequirements, containsService, wm, err := processNodeJobRunRequirements(ctx, db, *job, nr, sdk.Groups(groups).ToIDs(), integrationPluginBinaries)
-		next()
-		if err != nil {
-			spawnErrs.Join(*err)
-		}
+		for _, matrix := range matrixCombinations {
+			nbJobRuns++
 
-		// check that children actions used by job can be used by the project
-		if err := action.CheckChildrenForGroupIDsWithLoop(ctx, db, &job.Action, sdk.Groups(groups).ToIDs()); err != nil {
-			spawnErrs.Append(err)
-		}
+			// errors generated in the loop will be added to job run spawn info
+			spawnErrs := sdk.MultiError{}
 
-		// add requirements in job parameters, to use them as {{.job.requirement...}} in job
-		_, next = observability.Span(ctx, "workflow.prepareRequirementsToNodeJobRunParameters")
-		jobParams = append(jobParams, prepareRequirementsToNodeJobRunParameters(jobRequirements)...)
-		next()
-
-		//Create the job run
-		wjob := sdk.WorkflowNodeJobRun{
-			ProjectID:                 wr.ProjectID,
-			WorkflowNodeRunID:         nr.ID,
-			Start:                     time.Time{},
-			Queued:                    time.Now(),
-			Status:                    sdk.StatusWaiting,
-			Parameters:                jobParams,
-			ExecGroups:                groups,
-			IntegrationPluginBinaries: integrationPluginBinaries,
-			Job: sdk.ExecutedJob{
-				Job: *job,
-			},
-			Header:          nr.Header,
-			ContainsService: containsService,
-		}
-		if wm != nil {
-			wjob.ModelType = wm.Type
-		}
-		wjob.Job.Job.Action.Requirements = jobRequirements // Set the interpolated requirements on the job run only
-
-		if !stage.Enabled || !wjob.Job.Enabled {
-			wjob.Status = sdk.StatusDisabled
-			skippedOrDisabledJobs++
-		} else if !conditionsOK {
-			wjob.Status = sdk.StatusSkipped
-			skippedOrDisabledJobs++
-		}
+			//Process variables for the jobs
+			_, next = observability.Span(ctx, "workflow..getNodeJobRunParameters")
+			jobParams, err := getNodeJobRunParameters(db, *job, nr, stage, matrix)
+			next()
+			if err != nil {
+				spawnErrs.Join(*err)
+			}
 
-		// If there is any error in the previous operation, mark the job as failed
-		if !spawnErrs.IsEmpty() {
-			failedJobs++
-			wjob.Status = sdk.StatusFail
+			_, next = observability.Span(ctx, "workflow.processNodeJobRunRequirements")
+			jobRequirements, containsService, wm, err := processNodeJobRunRequirements(ctx, db, *job, nr, matrix, sdk.Groups(groups).ToIDs(), integrationPluginBinaries)
+			next()
+			if err != nil {
+				spawnErrs.Join(*err)
+			}
+
+			// check that children actions used by job can be used by the project
+			if err := action.CheckChildrenForGroupIDsWithLoop(ctx, db, &job.Action, sdk.Groups(groups).ToIDs()); err != nil {
+				spawnErrs.Append(err)
+			}
 
-			for _, e := range spawnErrs {
-				msg := sdk.SpawnMsg{
-					ID: sdk.MsgSpawnInfoJobError.ID,
+			// add requirements in job parameters, to use them as {{.job.requirement...}} in job
+			_, next = observability.Span(ctx, "workflow.prepareRequirementsToNodeJobRunParameters")
+			jobParams = append(jobParams, prepareRequirementsToNodeJobRunParameters(jobRequirements)...)
+			next()
+
+			//Create the job run
+			wjob := sdk.WorkflowNodeJobRun{
+				ProjectID:                 wr.ProjectID,
+				WorkflowNodeRunID:         nr.ID,
+				Start:                     time.Time{},
+				Queued:                    time.Now(),
+				Status:                    sdk.StatusWaiting,
+				Parameters:                jobParams,
+				ExecGroups:                groups,
+				IntegrationPluginBinaries: integrationPluginBinaries,
+				Job: sdk.ExecutedJob{
+					Job: *job,
+				},
+				Header:          nr.Header,
+				ContainsService: containsService,
+				Matrix:          matrix,
+			}
+			if wm != nil {
+				wjob.ModelType = wm.Type
+			}
+			wjob.Job.Job.Action.Requirements = jobRequirements // Set the interpolated requirements on the job run only
+
+			if !stage.Enabled || !wjob.Job.Enabled {
+				wjob.Status = sdk.StatusDisabled
+				skippedOrDisabledJobs++
+			} else if !conditionsOK {
+				wjob.Status = sdk.StatusSkipped
+				skippedOrDisabledJobs++
+			}
+
+			// If there is any error in the previous operation, mark the job as failed
+			if !spawnErrs.IsEmpty() {
+				failedJobs++
+				wjob.Status = sdk.StatusFail
+
+				for _, e := range spawnErrs {
+					msg := sdk.SpawnMsg{
+						ID: sdk.MsgSpawnInfoJobError.ID,
+					}
+					msg.Args = []interface{}{sdk.Cause(e).Error()}
+					wjob.SpawnInfos = append(wjob.SpawnInfos, sdk.SpawnInfo{
+						APITime:    time.Now(),
+						Message:    msg,
+						RemoteTime: time.Now(),
+					})
 				}
-				msg.Args = []interface{}{sdk.Cause(e).Error()}
-				wjob.SpawnInfos = append(wjob.SpawnInfos, sdk.SpawnInfo{
+			} else {
+				wjob.SpawnInfos = []sdk.SpawnInfo{{
 					APITime:    time.Now(),
-					Message:    msg,
+					Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobInQueue.ID},
 					RemoteTime: time.Now(),
-				})
+				}}
 			}
-		} else {
-			wjob.SpawnInfos = []sdk.SpawnInfo{{
-				APITime:    time.Now(),
-				Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobInQueue.ID},
-				RemoteTime: time.Now(),
-			}}
-		}
 
-		// insert in database
-		_, next = observability.Span(ctx, "workflow.insertWorkflowNodeJobRun")
-		if err := insertWorkflowNodeJobRun(db, &wjob); err != nil {
+			// insert in database
+			_, next = observability.Span(ctx, "workflow.insertWorkflowNodeJobRun")
+			if err := insertWorkflowNodeJobRun(db, &wjob); err != nil {
+				next()
+				return report, sdk.WrapError(err, "unable to insert in table workflow_node_run_job")
+			}
 			next()
-			return report, sdk.WrapError(err, "unable to insert in table workflow_node_run_job")
-		}
-		next()
 
-		if err := AddSpawnInfosNodeJobRun(db, wjob.ID, PrepareSpawnInfos(wjob.SpawnInfos)); err != nil {
-			return nil, sdk.WrapError(err, "cannot save spawn info job %d", wjob.ID)
-		}
+			if err := AddSpawnInfosNodeJobRun(db, wjob.ID, PrepareSpawnInfos(wjob.SpawnInfos)); err != nil {
+				return nil, sdk.WrapError(err, "cannot save spawn info job %d", wjob.ID)
+			}
 
-		//Put the job run in database
-		stage.RunJobs = append(stage.RunJobs, wjob)
+			//Put the job run in database
+			stage.RunJobs = append(stage.RunJobs, wjob)
 
-		report.Add(ctx, wjob)
+			report.Add(ctx, wjob)
+		}
 	}
 
-	if skippedOrDisabledJobs == len(stage.Jobs) {
+	if skippedOrDisabledJobs == nbJobRuns {
 		stage.Status = sdk.StatusSkipped
 	}
 
@@ -590,7 +624,7 @@ func syncStage(ctx context.Context, db gorp.SqlExecutor, store cache.Store, stag
 				if finalStatus == sdk.StatusBuilding || finalStatus == sdk.StatusDisabled {
 					finalStatus = sdk.StatusSkipped
 				}
-			case sdk.StatusFail:
+			case sdk.StatusFail, sdk.StatusTimeout:
 				finalStatus = sdk.StatusFail
 				break finalStageLoop
 			case sdk.StatusSuccess:
@@ -803,6 +837,11 @@ func StopWorkflowNodeRun(ctx context.Context, dbFunc func() *gorp.DbMap, store c
 	if nodeRun.OutgoingHook != nil {
 		errS = stopWorkflowNodeOutGoingHook(ctx, dbFunc, &nodeRun)
 	}
+	if nodeRun.Status == sdk.StatusWaitingApproval {
+		nodeRun.Status = sdk.StatusStopped
+		nodeRun.Done = time.Now()
+		errS = UpdateNodeRun(dbFunc(), &nodeRun)
+	}
 
 	if errS != nil {
 		return report, sdk.WrapError(errS, "Unable to stop workflow node run")
@@ -915,6 +954,7 @@ func SyncNodeRunRunJob(ctx context.Context, db gorp.SqlExecutor, nodeRun *sdk.Wo
 				}
 				runJob.SpawnInfos = spawnInfos
 				runJob.Job.StepStatus = nodeJobRun.Job.StepStatus
+				runJob.Attempts = nodeJobRun.Attempts
 				found = true
 				break
 			}
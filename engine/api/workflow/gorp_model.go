@@ -58,6 +58,7 @@ type NodeRun struct {
 	HookExecutionTimestamp sql.NullInt64  `db:"hook_execution_timestamp"`
 	ExecutionID            sql.NullString `db:"execution_id"`
 	Callback               sql.NullString `db:"callback"`
+	Approvals              sql.NullString `db:"approvals"`
 }
 
 // JobRun is a gorp wrapper around sdk.WorkflowNodeJobRun
@@ -79,6 +80,8 @@ type JobRun struct {
 	ContainsService           bool           `db:"contains_service"`
 	ModelType                 sql.NullString `db:"model_type"`
 	Header                    sql.NullString `db:"header"`
+	Attempts                  sql.NullString `db:"attempts"`
+	Matrix                    sql.NullString `db:"matrix"`
 }
 
 // ToJobRun transform the JobRun with data of the provided sdk.WorkflowNodeJobRun
@@ -114,6 +117,14 @@ func (j *JobRun) ToJobRun(jr *sdk.WorkflowNodeJobRun) (err error) {
 	if err != nil {
 		return sdk.WrapError(err, "column header")
 	}
+	j.Attempts, err = gorpmapping.JSONToNullString(jr.Attempts)
+	if err != nil {
+		return sdk.WrapError(err, "column attempts")
+	}
+	j.Matrix, err = gorpmapping.JSONToNullString(jr.Matrix)
+	if err != nil {
+		return sdk.WrapError(err, "column matrix")
+	}
 	return nil
 }
 
@@ -147,6 +158,12 @@ func (j JobRun) WorkflowNodeRunJob() (sdk.WorkflowNodeJobRun, error) {
 	if err := gorpmapping.JSONNullString(j.Header, &jr.Header); err != nil {
 		return jr, sdk.WrapError(err, "header")
 	}
+	if err := gorpmapping.JSONNullString(j.Attempts, &jr.Attempts); err != nil {
+		return jr, sdk.WrapError(err, "attempts")
+	}
+	if err := gorpmapping.JSONNullString(j.Matrix, &jr.Matrix); err != nil {
+		return jr, sdk.WrapError(err, "matrix")
+	}
 	if j.ModelType.Valid {
 		jr.ModelType = j.ModelType.String
 	}
@@ -175,6 +192,8 @@ func (j JobRun) WorkflowNodeRunJob() (sdk.WorkflowNodeJobRun, error) {
 // NodeRunArtifact is a gorp wrapper around sdk.WorkflowNodeRunArtifact
 type NodeRunArtifact sdk.WorkflowNodeRunArtifact
 
+type dbArtifactBlob sdk.ArtifactBlob
+
 // dbStaticFiles is a gorp wrapper around sdk.StaticFiles
 type dbStaticFiles sdk.StaticFiles
 
@@ -204,6 +223,7 @@ func init() {
 	gorpmapping.Register(gorpmapping.New(NodeRun{}, "workflow_node_run", true, "id"))
 	gorpmapping.Register(gorpmapping.New(JobRun{}, "workflow_node_run_job", true, "id"))
 	gorpmapping.Register(gorpmapping.New(NodeRunArtifact{}, "workflow_node_run_artifacts", true, "id"))
+	gorpmapping.Register(gorpmapping.New(dbArtifactBlob{}, "artifact_blob", true, "id"))
 	gorpmapping.Register(gorpmapping.New(RunTag{}, "workflow_run_tag", false, "workflow_run_id", "tag"))
 	gorpmapping.Register(gorpmapping.New(hookModel{}, "workflow_hook_model", true, "id"))
 	gorpmapping.Register(gorpmapping.New(outgoingHookModel{}, "workflow_outgoing_hook_model", true, "id"))
@@ -2,6 +2,8 @@ package workflow
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/go-gorp/gorp"
 
@@ -12,6 +14,50 @@ import (
 
 const maxRetry = 3
 
+// timeoutGracePeriod is the delay given to a worker to send the result of a timed out job
+const timeoutGracePeriod = 5 * time.Minute
+
+// stopTimedOutJobs set timeout status on all jobs which are building after their timeout,
+// it happens when the worker disappeared without sending any result
+func stopTimedOutJobs(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Store) error {
+	db := DBFunc()
+	jobs, err := LoadTimedOutNodeJobRun(ctx, db, store, timeoutGracePeriod)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load timed out node job run")
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error(ctx, "stopTimedOutJobs> cannot create transaction: %v", err)
+			continue
+		}
+
+		job.Job.Reason = fmt.Sprintf("Job timed out after %s, the worker did not send any result", time.Duration(job.Job.Action.Timeout)*time.Second)
+		if err := AddSpawnInfosNodeJobRun(tx, job.ID, PrepareSpawnInfos([]sdk.SpawnInfo{{
+			RemoteTime: time.Now(),
+			Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobTimeout.ID, Args: []interface{}{job.Job.Reason}},
+		}})); err != nil {
+			log.Error(ctx, "stopTimedOutJobs> cannot save spawn info on node run job %d: %v", job.ID, err)
+			_ = tx.Rollback()
+			continue
+		}
+
+		if _, err := UpdateNodeJobRunStatus(ctx, tx, store, nil, job, sdk.StatusTimeout); err != nil {
+			log.Error(ctx, "stopTimedOutJobs> cannot update node run job %d: %v", job.ID, err)
+			_ = tx.Rollback()
+			continue
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error(ctx, "stopTimedOutJobs> cannot commit transaction: %v", err)
+		}
+	}
+
+	return nil
+}
+
 // restartDeadJob restart all jobs which are building but without worker
 func restartDeadJob(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Store) error {
 	db := DBFunc()
@@ -31,9 +31,15 @@ func Initialize(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Stor
 				return
 			}
 		case <-tickHeart.C:
+			if err := stopTimedOutJobs(ctx, DBFunc, store); err != nil {
+				log.Warning(ctx, "workflow.stopTimedOutJobs> Error on stopTimedOutJobs : %v", err)
+			}
 			if err := restartDeadJob(ctx, DBFunc, store); err != nil {
 				log.Warning(ctx, "workflow.restartDeadJob> Error on restartDeadJob : %v", err)
 			}
+			if err := failExpiredApprovals(ctx, DBFunc); err != nil {
+				log.Warning(ctx, "workflow.failExpiredApprovals> Error on failExpiredApprovals : %v", err)
+			}
 		case <-tickStop.C:
 			if err := stopRunsBlocked(ctx, db); err != nil {
 				log.Warning(ctx, "workflow.stopRunsBlocked> Error on stopRunsBlocked : %v", err)
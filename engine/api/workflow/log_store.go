@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LogStore stores the step logs of job runs.
+type LogStore interface {
+	// Append adds logs at the end of a step log and returns the offset in bytes where they were written,
+	// or -1 if the max size of the step log was already reached.
+	Append(ctx context.Context, db gorp.SqlExecutor, logs *sdk.Log, maxLogSize int64) (int64, error)
+	// Load returns the step log from given offset in bytes, nil if the step has no log.
+	Load(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, offset int64) (*sdk.Log, error)
+	// LoadHead returns at most size bytes from the beginning of the step log.
+	LoadHead(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, size int64) (string, error)
+	// Complete is called when a job run ends.
+	Complete(ctx context.Context, db *gorp.DbMap, jobID int64) error
+	// DeleteRunLogs removes the logs of a workflow run.
+	DeleteRunLogs(ctx context.Context, db gorp.SqlExecutor, workflowRunID int64) error
+}
+
+var logStore LogStore = DatabaseLogStore{}
+
+// SetLogStore sets the store used for step logs, logs are stored in database by default.
+func SetLogStore(s LogStore) {
+	logStore = s
+}
+
+// GetLogStore returns the store used for step logs.
+func GetLogStore() LogStore {
+	return logStore
+}
+
+// DatabaseLogStore stores the step logs in the workflow_node_run_job_logs table.
+type DatabaseLogStore struct{}
+
+// Append adds logs at the end of a step log, logs above the max size are truncated.
+func (DatabaseLogStore) Append(ctx context.Context, db gorp.SqlExecutor, logs *sdk.Log, maxLogSize int64) (int64, error) {
+	// check if log exists without loading data but with log size
+	exists, size, err := ExistsStepLog(db, logs.JobID, logs.StepOrder)
+	if err != nil {
+		return -1, sdk.WrapError(err, "cannot check if log exists")
+	}
+
+	// ignore the log if max size already reached
+	if maxReached := truncateLogs(maxLogSize, size, logs); maxReached {
+		return -1, nil
+	}
+
+	if !exists {
+		return size, sdk.WrapError(insertLog(db, logs), "cannot insert log")
+	}
+
+	return size, sdk.WrapError(updateLog(db, logs), "cannot update log")
+}
+
+// Load returns the step log from given offset.
+func (DatabaseLogStore) Load(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, offset int64) (*sdk.Log, error) {
+	logs, err := LoadStepLogs(db, jobID, stepOrder)
+	if err != nil || logs == nil {
+		return nil, err
+	}
+	logs.Size = int64(len(logs.Val))
+	logs.Val = logValueFrom(logs.Val, offset)
+	return logs, nil
+}
+
+// LoadHead returns the beginning of the step log, only the returned bytes are read from database.
+func (DatabaseLogStore) LoadHead(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, size int64) (string, error) {
+	val, err := db.SelectNullStr("SELECT substring(value FROM 1 FOR $3) FROM workflow_node_run_job_logs WHERE workflow_node_run_job_id = $1 AND step_order = $2", jobID, stepOrder, size)
+	if err != nil {
+		return "", sdk.WrapError(err, "cannot load log of job %d step %d", jobID, stepOrder)
+	}
+	return val.String, nil
+}
+
+// Complete does nothing, logs in database are never compressed.
+func (DatabaseLogStore) Complete(ctx context.Context, db *gorp.DbMap, jobID int64) error {
+	return nil
+}
+
+// DeleteRunLogs does nothing, logs in database are deleted with their node run.
+func (DatabaseLogStore) DeleteRunLogs(ctx context.Context, db gorp.SqlExecutor, workflowRunID int64) error {
+	return nil
+}
+
+func logValueHead(val string, size int64) string {
+	if int64(len(val)) > size {
+		return val[:size]
+	}
+	return val
+}
+
+func logValueFrom(val string, offset int64) string {
+	if offset <= 0 {
+		return val
+	}
+	if offset >= int64(len(val)) {
+		return ""
+	}
+	return val[offset:]
+}
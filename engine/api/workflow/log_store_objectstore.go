@@ -0,0 +1,592 @@
+package workflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/lib/pq"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// logBlockSize is the size of the blocks of a compressed step log, each block can be fetched without the previous ones.
+var logBlockSize int64 = 1 << 20
+
+// logObject is an object of a step log in the object store.
+type logObject struct {
+	jobID     int64
+	stepOrder int64
+	name      string
+}
+
+func (o logObject) GetName() string { return o.name }
+
+func (o logObject) GetPath() string { return fmt.Sprintf("logs-%d-%d", o.jobID, o.stepOrder) }
+
+func compressedLogObjectName(version int64) string { return fmt.Sprintf("step-%d.log.gz", version) }
+
+func chunkLogObjectName(id int64) string { return fmt.Sprintf("chunk-%d.log", id) }
+
+// stepLogObject describes the objects of a step log, the compressed object then the chunks appended after it.
+type stepLogObject struct {
+	JobID             int64         `db:"workflow_node_run_job_id"`
+	StepOrder         int64         `db:"step_order"`
+	NodeRunID         int64         `db:"workflow_node_run_id"`
+	Start             pq.NullTime   `db:"start"`
+	LastModified      pq.NullTime   `db:"last_modified"`
+	Done              pq.NullTime   `db:"done"`
+	CompressedVersion int64         `db:"compressed_version"`
+	CompressedSize    int64         `db:"compressed_size"`
+	CompressedBlocks  pq.Int64Array `db:"compressed_blocks"`
+	NextChunk         int64         `db:"next_chunk"`
+	ChunkIDs          pq.Int64Array `db:"chunk_ids"`
+	Chunks            pq.Int64Array `db:"chunks"`
+}
+
+const stepLogObjectColumns = "workflow_node_run_job_id, step_order, workflow_node_run_id, start, last_modified, done, compressed_version, compressed_size, compressed_blocks, next_chunk, chunk_ids, chunks"
+
+func loadStepLogObjects(db gorp.SqlExecutor, query string, args ...interface{}) ([]stepLogObject, error) {
+	var objs []stepLogObject
+	if _, err := db.Select(&objs, query, args...); err != nil {
+		return nil, sdk.WrapError(err, "unable to load step log objects")
+	}
+	return objs, nil
+}
+
+func loadStepLogObject(db gorp.SqlExecutor, jobID, stepOrder int64, forUpdate bool) (*stepLogObject, error) {
+	query := "SELECT " + stepLogObjectColumns + " FROM workflow_node_run_job_logs_object WHERE workflow_node_run_job_id = $1 AND step_order = $2"
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	objs, err := loadStepLogObjects(db, query, jobID, stepOrder)
+	if err != nil || len(objs) == 0 {
+		return nil, err
+	}
+	return &objs[0], nil
+}
+
+// loadStepLog returns the step log stored in database and the objects of the step log with a single query,
+// to not load the step log in database after it was compressed in the object store.
+func loadStepLog(db gorp.SqlExecutor, jobID, stepOrder int64) (*sdk.Log, *stepLogObject, error) {
+	var hasObject, hasLogs bool
+	var o stepLogObject
+	var logs sdk.Log
+	var s, m, d pq.NullTime
+	if err := db.QueryRow(`
+		SELECT o.workflow_node_run_job_id IS NOT NULL, COALESCE(o.workflow_node_run_id, 0), o.start, o.last_modified, o.done,
+			COALESCE(o.compressed_version, 0), COALESCE(o.compressed_size, 0), COALESCE(o.compressed_blocks, '{}'),
+			COALESCE(o.chunk_ids, '{}'), COALESCE(o.chunks, '{}'),
+			l.id IS NOT NULL, COALESCE(l.id, 0), COALESCE(l.workflow_node_run_id, 0), l.start, l.last_modified, l.done, COALESCE(l.value, '')
+		FROM (SELECT $1::BIGINT AS job_id, $2::BIGINT AS step_order) AS k
+		LEFT JOIN workflow_node_run_job_logs_object AS o ON o.workflow_node_run_job_id = k.job_id AND o.step_order = k.step_order
+		LEFT JOIN workflow_node_run_job_logs AS l ON l.workflow_node_run_job_id = k.job_id AND l.step_order = k.step_order`, jobID, stepOrder).Scan(
+		&hasObject, &o.NodeRunID, &o.Start, &o.LastModified, &o.Done,
+		&o.CompressedVersion, &o.CompressedSize, &o.CompressedBlocks,
+		&o.ChunkIDs, &o.Chunks,
+		&hasLogs, &logs.ID, &logs.NodeRunID, &s, &m, &d, &logs.Val); err != nil {
+		return nil, nil, sdk.WrapError(err, "unable to load log of job %d step %d", jobID, stepOrder)
+	}
+
+	var res *sdk.Log
+	if hasLogs {
+		logs.JobID, logs.StepOrder = jobID, stepOrder
+		if s.Valid {
+			logs.Start = &s.Time
+		}
+		if m.Valid {
+			logs.LastModified = &m.Time
+		}
+		if d.Valid {
+			logs.Done = &d.Time
+		}
+		res = &logs
+	}
+	if !hasObject {
+		return res, nil, nil
+	}
+	o.JobID, o.StepOrder = jobID, stepOrder
+	return res, &o, nil
+}
+
+func sumChunks(chunks []int64) int64 {
+	var size int64
+	for _, c := range chunks {
+		size += c
+	}
+	return size
+}
+
+// compressLogBlocks compresses the content by blocks of logBlockSize bytes. Each block is a gzip member, so the
+// result is a valid gzip file that can be read from the start of any block. It returns the compressed size of each block.
+func compressLogBlocks(content []byte) ([]byte, []int64, error) {
+	buf := new(bytes.Buffer)
+	var blocks []int64
+	for start := int64(0); start < int64(len(content)); start += logBlockSize {
+		end := start + logBlockSize
+		if end > int64(len(content)) {
+			end = int64(len(content))
+		}
+		size := buf.Len()
+		gz := gzip.NewWriter(buf)
+		if _, err := gz.Write(content[start:end]); err != nil {
+			return nil, nil, sdk.WithStack(err)
+		}
+		if err := gz.Close(); err != nil {
+			return nil, nil, sdk.WithStack(err)
+		}
+		blocks = append(blocks, int64(buf.Len()-size))
+	}
+	return buf.Bytes(), blocks, nil
+}
+
+// ObjectStoreLogStore stores the step logs in an object storage. Logs are stored by chunks while the job is running,
+// then compressed in a single object when the job ends. Logs found in database are kept before the stored ones.
+type ObjectStoreLogStore struct {
+	driver objectstore.Driver
+}
+
+// NewObjectStoreLogStore returns a log store using given object storage.
+func NewObjectStoreLogStore(driver objectstore.Driver) *ObjectStoreLogStore {
+	return &ObjectStoreLogStore{driver: driver}
+}
+
+// Append stores the logs as a new chunk of the step log. The chunk is added to the step log once stored,
+// so a compression never includes a chunk that is being stored.
+func (s *ObjectStoreLogStore) Append(ctx context.Context, db gorp.SqlExecutor, logs *sdk.Log, maxLogSize int64) (int64, error) {
+	dbLogs, o, err := loadStepLog(db, logs.JobID, logs.StepOrder)
+	if err != nil {
+		return -1, err
+	}
+	var size int64
+	if dbLogs != nil {
+		size += int64(len(dbLogs.Val))
+	}
+	if o != nil {
+		size += o.CompressedSize + sumChunks(o.Chunks)
+	}
+
+	// ignore the log if max size already reached
+	if maxReached := truncateLogs(maxLogSize, size, logs); maxReached {
+		return -1, nil
+	}
+
+	now := time.Now()
+	if logs.LastModified == nil {
+		logs.LastModified = &now
+	}
+
+	var id int64
+	if err := db.QueryRow(`
+		INSERT INTO workflow_node_run_job_logs_object (workflow_node_run_job_id, step_order, workflow_node_run_id, start, last_modified, done, next_chunk)
+		VALUES ($1, $2, $3, $4, $5, $6, 1)
+		ON CONFLICT (workflow_node_run_job_id, step_order) DO UPDATE SET next_chunk = workflow_node_run_job_logs_object.next_chunk + 1
+		RETURNING next_chunk - 1`,
+		logs.JobID, logs.StepOrder, logs.NodeRunID, logs.Start, logs.LastModified, logs.Done).Scan(&id); err != nil {
+		return -1, sdk.WrapError(err, "unable to reserve log chunk")
+	}
+
+	obj := logObject{jobID: logs.JobID, stepOrder: logs.StepOrder, name: chunkLogObjectName(id)}
+	if _, err := s.driver.Store(obj, ioutil.NopCloser(strings.NewReader(logs.Val))); err != nil {
+		return -1, sdk.WrapError(err, "cannot store log chunk")
+	}
+
+	// The offset is computed from the step log updated with the chunk, logs in database may have been compressed meanwhile
+	var compressedSize, dbSize int64
+	var chunks pq.Int64Array
+	if err := db.QueryRow(`
+		UPDATE workflow_node_run_job_logs_object SET
+			last_modified = $3,
+			done = $4,
+			chunk_ids = array_append(chunk_ids, $5::BIGINT),
+			chunks = array_append(chunks, $6::BIGINT)
+		WHERE workflow_node_run_job_id = $1 AND step_order = $2
+		RETURNING compressed_size, chunks, (
+			SELECT COALESCE(SUM(octet_length(value)), 0) FROM workflow_node_run_job_logs WHERE workflow_node_run_job_id = $1 AND step_order = $2
+		)`,
+		logs.JobID, logs.StepOrder, logs.LastModified, logs.Done, id, int64(len(logs.Val))).Scan(&compressedSize, &chunks, &dbSize); err != nil {
+		return -1, sdk.WrapError(err, "unable to add log chunk")
+	}
+
+	return dbSize + compressedSize + sumChunks(chunks[:len(chunks)-1]), nil
+}
+
+// Load fetches the objects of the step log containing data after given offset. Objects replaced by a compression
+// can be deleted while they are fetched, the step log is loaded again in this case.
+func (s *ObjectStoreLogStore) Load(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, offset int64) (*sdk.Log, error) {
+	logs, err := s.load(ctx, db, jobID, stepOrder, offset)
+	if err != nil {
+		log.Warning(ctx, "ObjectStoreLogStore.Load> unable to load log of job %d step %d, retrying: %v", jobID, stepOrder, err)
+		return s.load(ctx, db, jobID, stepOrder, offset)
+	}
+	return logs, nil
+}
+
+func (s *ObjectStoreLogStore) load(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, offset int64) (*sdk.Log, error) {
+	dbLogs, o, err := loadStepLog(db, jobID, stepOrder)
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		if dbLogs == nil {
+			return nil, nil
+		}
+		dbLogs.Size = int64(len(dbLogs.Val))
+		dbLogs.Val = logValueFrom(dbLogs.Val, offset)
+		return dbLogs, nil
+	}
+
+	logs := &sdk.Log{
+		JobID:     o.JobID,
+		NodeRunID: o.NodeRunID,
+		StepOrder: o.StepOrder,
+	}
+	if o.Start.Valid {
+		logs.Start = &o.Start.Time
+	}
+	if o.LastModified.Valid {
+		logs.LastModified = &o.LastModified.Time
+	}
+	if o.Done.Valid {
+		logs.Done = &o.Done.Time
+	}
+
+	buf := new(bytes.Buffer)
+	if err := s.write(ctx, buf, dbLogs, o, offset); err != nil {
+		return nil, err
+	}
+	if dbLogs != nil {
+		logs.ID = dbLogs.ID
+		logs.Start = dbLogs.Start
+	}
+
+	logs.Val = buf.String()
+	logs.Size = o.CompressedSize + sumChunks(o.Chunks)
+	if dbLogs != nil {
+		logs.Size += int64(len(dbLogs.Val))
+	}
+	return logs, nil
+}
+
+// errLogHeadFull is returned by a headWriter when its size is reached, to stop reading a step log.
+var errLogHeadFull = fmt.Errorf("log head is full")
+
+// headWriter keeps the first bytes written, up to its size.
+type headWriter struct {
+	buf  bytes.Buffer
+	size int64
+}
+
+func (w *headWriter) Write(p []byte) (int, error) {
+	if left := w.size - int64(w.buf.Len()); int64(len(p)) > left {
+		w.buf.Write(p[:left])
+		return int(left), errLogHeadFull
+	}
+	return w.buf.Write(p)
+}
+
+// LoadHead returns the beginning of the step log, objects are read until the given size is reached.
+func (s *ObjectStoreLogStore) LoadHead(ctx context.Context, db gorp.SqlExecutor, jobID, stepOrder, size int64) (string, error) {
+	dbLogs, o, err := loadStepLog(db, jobID, stepOrder)
+	if err != nil {
+		return "", err
+	}
+	if o == nil {
+		if dbLogs == nil {
+			return "", nil
+		}
+		return logValueHead(dbLogs.Val, size), nil
+	}
+
+	w := &headWriter{size: size}
+	if err := s.write(ctx, w, dbLogs, o, 0); err != nil && sdk.Cause(err) != errLogHeadFull {
+		return "", err
+	}
+	return w.buf.String(), nil
+}
+
+// write writes the content of the step log from given offset, only the objects containing data after the offset are fetched.
+func (s *ObjectStoreLogStore) write(ctx context.Context, w io.Writer, dbLogs *sdk.Log, o *stepLogObject, offset int64) error {
+	var pos int64
+	if dbLogs != nil {
+		if _, err := io.WriteString(w, logValueFrom(dbLogs.Val, offset)); err != nil {
+			return sdk.WithStack(err)
+		}
+		pos = int64(len(dbLogs.Val))
+	}
+
+	if o.CompressedSize > 0 {
+		if offset < pos+o.CompressedSize {
+			if err := s.fetchCompressed(ctx, w, o, offset-pos); err != nil {
+				return err
+			}
+		}
+		pos += o.CompressedSize
+	}
+
+	for i, c := range o.Chunks {
+		if c > 0 && offset < pos+c {
+			obj := logObject{jobID: o.JobID, stepOrder: o.StepOrder, name: chunkLogObjectName(o.ChunkIDs[i])}
+			skip := offset - pos
+			if skip < 0 {
+				skip = 0
+			}
+			if err := s.fetch(ctx, w, obj, skip); err != nil {
+				return err
+			}
+		}
+		pos += c
+	}
+	return nil
+}
+
+// fetchFrom returns the content of an object from given offset, with a ranged read if the driver supports it.
+func (s *ObjectStoreLogStore) fetchFrom(ctx context.Context, obj logObject, offset int64) (io.ReadCloser, error) {
+	if d, ok := s.driver.(objectstore.DriverWithRange); ok && offset > 0 {
+		r, err := d.FetchFrom(ctx, obj, offset)
+		if err != nil {
+			return nil, sdk.WrapError(err, "cannot fetch log object %s/%s", obj.GetPath(), obj.GetName())
+		}
+		return r, nil
+	}
+
+	r, err := s.driver.Fetch(ctx, obj)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot fetch log object %s/%s", obj.GetPath(), obj.GetName())
+	}
+	if offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, r, offset); err != nil {
+			r.Close() // nolint
+			return nil, sdk.WrapError(err, "cannot read log object %s/%s", obj.GetPath(), obj.GetName())
+		}
+	}
+	return r, nil
+}
+
+// fetch writes the content of a chunk in given writer, from given offset.
+func (s *ObjectStoreLogStore) fetch(ctx context.Context, w io.Writer, obj logObject, offset int64) error {
+	r, err := s.fetchFrom(ctx, obj, offset)
+	if err != nil {
+		return err
+	}
+	defer r.Close() // nolint
+
+	if _, err := io.Copy(w, r); err != nil {
+		return sdk.WrapError(err, "cannot read log object %s/%s", obj.GetPath(), obj.GetName())
+	}
+	return nil
+}
+
+// fetchCompressed writes the content of the compressed object in given writer from given offset, the object
+// is fetched from the block that contains the offset.
+func (s *ObjectStoreLogStore) fetchCompressed(ctx context.Context, w io.Writer, o *stepLogObject, offset int64) error {
+	obj := logObject{jobID: o.JobID, stepOrder: o.StepOrder, name: compressedLogObjectName(o.CompressedVersion)}
+	block := offset / logBlockSize
+	if offset <= 0 || block >= int64(len(o.CompressedBlocks)) {
+		block = 0
+	}
+
+	r, err := s.fetchFrom(ctx, obj, sumChunks(o.CompressedBlocks[:block]))
+	if err != nil {
+		return err
+	}
+	defer r.Close() // nolint
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return sdk.WrapError(err, "cannot read log object %s/%s", obj.GetPath(), obj.GetName())
+	}
+	defer gz.Close() // nolint
+
+	if skip := offset - block*logBlockSize; skip > 0 {
+		if _, err := io.CopyN(ioutil.Discard, gz, skip); err != nil {
+			return sdk.WrapError(err, "cannot read log object %s/%s", obj.GetPath(), obj.GetName())
+		}
+	}
+	if _, err := io.Copy(w, gz); err != nil {
+		return sdk.WrapError(err, "cannot read log object %s/%s", obj.GetPath(), obj.GetName())
+	}
+	return nil
+}
+
+// Complete compresses the logs of each step of the job in a single object.
+func (s *ObjectStoreLogStore) Complete(ctx context.Context, db *gorp.DbMap, jobID int64) error {
+	objs, err := loadStepLogObjects(db, "SELECT "+stepLogObjectColumns+" FROM workflow_node_run_job_logs_object WHERE workflow_node_run_job_id = $1", jobID)
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		if len(o.Chunks) == 0 {
+			continue
+		}
+		if err := s.compress(ctx, db, o.JobID, o.StepOrder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compress replaces the step log stored in database, the compressed object and the chunks by a new compressed object.
+// The step log is locked, only the chunks added before the lock are compressed and removed.
+func (s *ObjectStoreLogStore) compress(ctx context.Context, db *gorp.DbMap, jobID, stepOrder int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer tx.Rollback() // nolint
+
+	// The step log object is created for logs only stored in database, to be locked
+	if _, err := tx.Exec(`
+		INSERT INTO workflow_node_run_job_logs_object (workflow_node_run_job_id, step_order, workflow_node_run_id, start, last_modified, done)
+		SELECT workflow_node_run_job_id, step_order, workflow_node_run_id, start, last_modified, done
+		FROM workflow_node_run_job_logs
+		WHERE workflow_node_run_job_id = $1 AND step_order = $2
+		ON CONFLICT (workflow_node_run_job_id, step_order) DO NOTHING`, jobID, stepOrder); err != nil {
+		return sdk.WrapError(err, "unable to create log object of job %d step %d", jobID, stepOrder)
+	}
+	o, err := loadStepLogObject(tx, jobID, stepOrder, true)
+	if err != nil || o == nil {
+		return err
+	}
+	dbLogs, err := LoadStepLogs(tx, jobID, stepOrder)
+	if err != nil {
+		return sdk.WrapError(err, "unable to load log of job %d step %d", jobID, stepOrder)
+	}
+	if dbLogs == nil && len(o.ChunkIDs) == 0 {
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	if err := s.write(ctx, buf, dbLogs, o, 0); err != nil {
+		return err
+	}
+	content, blocks, err := compressLogBlocks(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	version := o.CompressedVersion + 1
+	if _, err := s.driver.Store(logObject{jobID: jobID, stepOrder: stepOrder, name: compressedLogObjectName(version)}, ioutil.NopCloser(bytes.NewReader(content))); err != nil {
+		return sdk.WrapError(err, "cannot store compressed log of job %d step %d", jobID, stepOrder)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE workflow_node_run_job_logs_object SET
+			compressed_version = $3,
+			compressed_size = $4,
+			compressed_blocks = $5,
+			chunk_ids = COALESCE(chunk_ids[$6:array_upper(chunk_ids, 1)], '{}'),
+			chunks = COALESCE(chunks[$6:array_upper(chunks, 1)], '{}')
+		WHERE workflow_node_run_job_id = $1 AND step_order = $2`,
+		jobID, stepOrder, version, int64(buf.Len()), pq.Int64Array(blocks), len(o.ChunkIDs)+1); err != nil {
+		return sdk.WrapError(err, "unable to update log object of job %d step %d", jobID, stepOrder)
+	}
+	if _, err := tx.Exec("DELETE FROM workflow_node_run_job_logs WHERE workflow_node_run_job_id = $1 AND step_order = $2", jobID, stepOrder); err != nil {
+		return sdk.WrapError(err, "unable to delete log of job %d step %d", jobID, stepOrder)
+	}
+	if err := tx.Commit(); err != nil {
+		return sdk.WithStack(err)
+	}
+
+	var replaced []logObject
+	if o.CompressedVersion > 0 {
+		replaced = append(replaced, logObject{jobID: jobID, stepOrder: stepOrder, name: compressedLogObjectName(o.CompressedVersion)})
+	}
+	for _, id := range o.ChunkIDs {
+		replaced = append(replaced, logObject{jobID: jobID, stepOrder: stepOrder, name: chunkLogObjectName(id)})
+	}
+	for _, obj := range replaced {
+		if err := s.driver.Delete(ctx, obj); err != nil {
+			log.Warning(ctx, "ObjectStoreLogStore.compress> unable to delete log object %s/%s: %v", obj.GetPath(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// DeleteRunLogs removes the objects of the step logs of a workflow run.
+func (s *ObjectStoreLogStore) DeleteRunLogs(ctx context.Context, db gorp.SqlExecutor, workflowRunID int64) error {
+	objs, err := loadStepLogObjects(db, `
+		SELECT `+stepLogObjectColumns+`
+		FROM workflow_node_run_job_logs_object
+		WHERE workflow_node_run_id IN (SELECT id FROM workflow_node_run WHERE workflow_run_id = $1)`, workflowRunID)
+	if err != nil {
+		return err
+	}
+	for _, o := range objs {
+		obj := logObject{jobID: o.JobID, stepOrder: o.StepOrder}
+		if o.CompressedVersion > 0 {
+			obj.name = compressedLogObjectName(o.CompressedVersion)
+			if err := s.driver.Delete(ctx, obj); err != nil {
+				return sdk.WrapError(err, "cannot delete log object %s/%s", obj.GetPath(), obj.GetName())
+			}
+		}
+		for _, id := range o.ChunkIDs {
+			obj.name = chunkLogObjectName(id)
+			if err := s.driver.Delete(ctx, obj); err != nil {
+				return sdk.WrapError(err, "cannot delete log object %s/%s", obj.GetPath(), obj.GetName())
+			}
+		}
+		if err := s.driver.DeleteContainer(ctx, obj.GetPath()); err != nil {
+			return sdk.WrapError(err, "cannot delete log container %s", obj.GetPath())
+		}
+	}
+	return nil
+}
+
+// MigrateDatabaseLogsBatch moves at most limit step logs stored in database after given log id to the object store.
+// It returns the id of the last log of the batch, or 0 if there is no more logs. Logs that can't be moved
+// are kept in database and are not retried.
+func (s *ObjectStoreLogStore) MigrateDatabaseLogsBatch(ctx context.Context, db *gorp.DbMap, fromID int64, limit int) (int64, error) {
+	var steps []struct {
+		ID        int64 `db:"id"`
+		JobID     int64 `db:"workflow_node_run_job_id"`
+		StepOrder int64 `db:"step_order"`
+	}
+	if _, err := db.Select(&steps, "SELECT id, workflow_node_run_job_id, step_order FROM workflow_node_run_job_logs WHERE id > $1 ORDER BY id LIMIT $2", fromID, limit); err != nil {
+		return fromID, sdk.WrapError(err, "unable to load logs")
+	}
+	if len(steps) == 0 {
+		return 0, nil
+	}
+	for _, step := range steps {
+		if err := s.compress(ctx, db, step.JobID, step.StepOrder); err != nil {
+			log.Error(ctx, "ObjectStoreLogStore.MigrateDatabaseLogs> unable to migrate log of job %d step %d: %v", step.JobID, step.StepOrder, err)
+		}
+	}
+	return steps[len(steps)-1].ID, nil
+}
+
+// MigrateDatabaseLogs moves the step logs stored in database to the object store, by batch until there is no more logs
+// in database.
+func (s *ObjectStoreLogStore) MigrateDatabaseLogs(ctx context.Context, DBFunc func() *gorp.DbMap) {
+	tick := time.NewTicker(time.Minute)
+	defer tick.Stop()
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() != nil {
+				log.Error(ctx, "ObjectStoreLogStore.MigrateDatabaseLogs> exiting: %v", ctx.Err())
+			}
+			return
+		case <-tick.C:
+			id, err := s.MigrateDatabaseLogsBatch(ctx, DBFunc(), lastID, 100)
+			if err != nil {
+				log.Error(ctx, "ObjectStoreLogStore.MigrateDatabaseLogs> %v", err)
+				continue
+			}
+			if id == 0 {
+				log.Info(ctx, "ObjectStoreLogStore.MigrateDatabaseLogs> all logs have been migrated")
+				return
+			}
+			lastID = id
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package workflow_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func insertLogStoreTestJobRun(t *testing.T, db *gorp.DbMap, store cache.Store) *sdk.WorkflowNodeJobRun {
+	u, _ := assets.InsertAdminUser(t, db)
+	consumer, _ := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, store, key, key)
+	w := assets.InsertTestWorkflow(t, db, store, proj, sdk.RandomString(10))
+
+	w1, err := workflow.Load(context.TODO(), db, store, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+	require.NoError(t, err)
+	wr, err := workflow.CreateRun(db, w1, nil, u)
+	require.NoError(t, err)
+	wr.Workflow = *w1
+	_, err = workflow.StartWorkflowRun(context.TODO(), db, store, proj, wr, &sdk.WorkflowRunPostHandlerOption{
+		Manual: &sdk.WorkflowNodeRunManual{Username: u.Username},
+	}, consumer, nil)
+	require.NoError(t, err)
+
+	lastRun, err := workflow.LoadLastRun(db, proj.Key, w1.Name, workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	return &lastRun.WorkflowNodeRuns[w1.WorkflowData.Node.ID][0].Stages[0].RunJobs[0]
+}
+
+func newLogStoreTestDriver(t *testing.T) (objectstore.Driver, func()) {
+	dir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	driver, err := objectstore.Init(context.TODO(), objectstore.Config{
+		Kind:    objectstore.Filesystem,
+		Options: objectstore.ConfigOptions{Filesystem: objectstore.ConfigOptionsFilesystem{Basedir: dir}},
+	})
+	require.NoError(t, err)
+	return driver, func() { os.RemoveAll(dir) } // nolint
+}
+
+// failingStoreDriver is a driver that can't store any object.
+type failingStoreDriver struct {
+	objectstore.Driver
+}
+
+func (failingStoreDriver) Store(o objectstore.Object, data io.ReadCloser) (string, error) {
+	return "", fmt.Errorf("cannot store %s", o.GetName())
+}
+
+func TestObjectStoreLogStore(t *testing.T) {
+	db, cache, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+	driver, clean := newLogStoreTestDriver(t)
+	defer clean()
+
+	jobRun := insertLogStoreTestJobRun(t, db, cache)
+	s := workflow.NewObjectStoreLogStore(driver)
+
+	// logs stored in database before the object store was enabled are kept first
+	_, err := workflow.DatabaseLogStore{}.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 0, Val: "db\n"}, 0)
+	require.NoError(t, err)
+
+	var expected string
+	for i, val := range []string{"first\n", "second\n", "third\n"} {
+		offset, err := s.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 0, Val: val}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len("db\n"+expected)), offset, "offset of chunk %d", i)
+		expected += val
+	}
+	expected = "db\n" + expected
+
+	logs, err := s.Load(context.TODO(), db, jobRun.ID, 0, 0)
+	require.NoError(t, err)
+	require.NotNil(t, logs)
+	assert.Equal(t, expected, logs.Val)
+	assert.Equal(t, int64(len(expected)), logs.Size)
+
+	logs, err = s.Load(context.TODO(), db, jobRun.ID, 0, 5)
+	require.NoError(t, err)
+	assert.Equal(t, expected[5:], logs.Val)
+
+	// the database log and the chunks are replaced by a compressed object
+	require.NoError(t, s.Complete(context.TODO(), db, jobRun.ID))
+	dbLogs, err := workflow.LoadStepLogs(db, jobRun.ID, 0)
+	require.NoError(t, err)
+	assert.Nil(t, dbLogs)
+	logs, err = s.Load(context.TODO(), db, jobRun.ID, 0, 9)
+	require.NoError(t, err)
+	assert.Equal(t, expected[9:], logs.Val)
+	assert.Equal(t, int64(len(expected)), logs.Size)
+
+	// a chunk added after the compression is kept after the compressed object
+	offset, err := s.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 0, Val: "late\n"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(expected)), offset)
+	expected += "late\n"
+	logs, err = s.Load(context.TODO(), db, jobRun.ID, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, logs.Val)
+
+	require.NoError(t, s.Complete(context.TODO(), db, jobRun.ID))
+	logs, err = s.Load(context.TODO(), db, jobRun.ID, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, expected, logs.Val)
+
+	// logs above the max size are truncated
+	_, err = s.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 1, Val: "1234567890"}, 15)
+	require.NoError(t, err)
+	_, err = s.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 1, Val: "1234567890"}, 15)
+	require.NoError(t, err)
+	offset, err = s.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 1, Val: "1234567890"}, 15)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), offset)
+	logs, err = s.Load(context.TODO(), db, jobRun.ID, 1, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "123456789012345... truncated\n", logs.Val)
+
+	// a step without logs
+	logs, err = s.Load(context.TODO(), db, jobRun.ID, 2, 0)
+	require.NoError(t, err)
+	assert.Nil(t, logs)
+}
+
+func TestObjectStoreLogStoreMigrateDatabaseLogs(t *testing.T) {
+	db, cache, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+	driver, clean := newLogStoreTestDriver(t)
+	defer clean()
+
+	jobRun := insertLogStoreTestJobRun(t, db, cache)
+	s := workflow.NewObjectStoreLogStore(driver)
+
+	for _, step := range []int64{0, 1} {
+		_, err := workflow.DatabaseLogStore{}.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: step, Val: "db\n"}, 0)
+		require.NoError(t, err)
+	}
+	dbLogs, err := workflow.LoadStepLogs(db, jobRun.ID, 0)
+	require.NoError(t, err)
+	require.NotNil(t, dbLogs)
+
+	// logs before the given id are not migrated
+	lastID, err := s.MigrateDatabaseLogsBatch(context.TODO(), db, dbLogs.ID, 1)
+	require.NoError(t, err)
+	assert.True(t, lastID > dbLogs.ID)
+	logs, err := workflow.LoadStepLogs(db, jobRun.ID, 0)
+	require.NoError(t, err)
+	assert.NotNil(t, logs)
+	logs, err = workflow.LoadStepLogs(db, jobRun.ID, 1)
+	require.NoError(t, err)
+	assert.Nil(t, logs)
+
+	lastID, err = s.MigrateDatabaseLogsBatch(context.TODO(), db, dbLogs.ID-1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, dbLogs.ID, lastID)
+	for _, step := range []int64{0, 1} {
+		logs, err := workflow.LoadStepLogs(db, jobRun.ID, step)
+		require.NoError(t, err)
+		assert.Nil(t, logs)
+		logs, err = s.Load(context.TODO(), db, jobRun.ID, step, 0)
+		require.NoError(t, err)
+		require.NotNil(t, logs)
+		assert.Equal(t, "db\n", logs.Val)
+	}
+
+	// a log that can't be migrated is kept in database and the migration goes on with the next ones
+	_, err = workflow.DatabaseLogStore{}.Append(context.TODO(), db, &sdk.Log{JobID: jobRun.ID, NodeRunID: jobRun.WorkflowNodeRunID, StepOrder: 2, Val: "db\n"}, 0)
+	require.NoError(t, err)
+	failing, err := workflow.LoadStepLogs(db, jobRun.ID, 2)
+	require.NoError(t, err)
+	lastID, err = workflow.NewObjectStoreLogStore(failingStoreDriver{driver}).MigrateDatabaseLogsBatch(context.TODO(), db, failing.ID-1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, failing.ID, lastID)
+	logs, err = workflow.LoadStepLogs(db, jobRun.ID, 2)
+	require.NoError(t, err)
+	assert.NotNil(t, logs)
+}
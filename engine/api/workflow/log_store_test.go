@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLogValueFrom(t *testing.T) {
+	assert.Equal(t, "foobar", logValueFrom("foobar", 0))
+	assert.Equal(t, "bar", logValueFrom("foobar", 3))
+	assert.Equal(t, "", logValueFrom("foobar", 6))
+	assert.Equal(t, "", logValueFrom("foobar", 10))
+}
+
+func TestLogValueHead(t *testing.T) {
+	assert.Equal(t, "foo", logValueHead("foobar", 3))
+	assert.Equal(t, "foobar", logValueHead("foobar", 10))
+}
+
+func TestCompressLogBlocks(t *testing.T) {
+	defer func(size int64) { logBlockSize = size }(logBlockSize)
+	logBlockSize = 4
+
+	content, blocks, err := compressLogBlocks([]byte("0123456789"))
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+	assert.Equal(t, int64(len(content)), sumChunks(blocks))
+
+	// the compressed content is a valid gzip file, and can be read from the start of each block
+	gz, err := gzip.NewReader(bytes.NewReader(content))
+	require.NoError(t, err)
+	btes, err := ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(btes))
+
+	gz, err = gzip.NewReader(bytes.NewReader(content[blocks[0]+blocks[1]:]))
+	require.NoError(t, err)
+	btes, err = ioutil.ReadAll(gz)
+	require.NoError(t, err)
+	assert.Equal(t, "89", string(btes))
+}
+
+// rangeDriver records the offsets of the ranged reads.
+type rangeDriver struct {
+	*objectstore.FilesystemStore
+	offsets []int64
+}
+
+func (d *rangeDriver) FetchFrom(ctx context.Context, o objectstore.Object, offset int64) (io.ReadCloser, error) {
+	d.offsets = append(d.offsets, offset)
+	return d.FilesystemStore.FetchFrom(ctx, o, offset)
+}
+
+// noRangeDriver hides the ranged reads of the driver.
+type noRangeDriver struct {
+	objectstore.Driver
+}
+
+func TestObjectStoreLogStoreWrite(t *testing.T) {
+	defer func(size int64) { logBlockSize = size }(logBlockSize)
+	logBlockSize = 4
+
+	dir, err := ioutil.TempDir("", "logs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint
+	driver, err := objectstore.Init(context.TODO(), objectstore.Config{
+		Kind:    objectstore.Filesystem,
+		Options: objectstore.ConfigOptions{Filesystem: objectstore.ConfigOptionsFilesystem{Basedir: dir}},
+	})
+	require.NoError(t, err)
+
+	// a step log with logs in database, a compressed object then two chunks
+	dbLogs := &sdk.Log{Val: "db-"}
+	content, blocks, err := compressLogBlocks([]byte("compressed-"))
+	require.NoError(t, err)
+	o := &stepLogObject{
+		JobID:             1,
+		StepOrder:         2,
+		CompressedVersion: 3,
+		CompressedSize:    11,
+		CompressedBlocks:  blocks,
+		ChunkIDs:          []int64{4, 5},
+		Chunks:            []int64{6, 6},
+	}
+	_, err = driver.Store(logObject{jobID: 1, stepOrder: 2, name: compressedLogObjectName(3)}, ioutil.NopCloser(bytes.NewReader(content)))
+	require.NoError(t, err)
+	_, err = driver.Store(logObject{jobID: 1, stepOrder: 2, name: chunkLogObjectName(4)}, ioutil.NopCloser(strings.NewReader("chunk1")))
+	require.NoError(t, err)
+	_, err = driver.Store(logObject{jobID: 1, stepOrder: 2, name: chunkLogObjectName(5)}, ioutil.NopCloser(strings.NewReader("chunk2")))
+	require.NoError(t, err)
+
+	full := "db-compressed-chunk1chunk2"
+	for _, d := range []objectstore.Driver{driver, noRangeDriver{driver}} {
+		s := NewObjectStoreLogStore(d)
+		for offset := int64(0); offset <= int64(len(full)); offset++ {
+			buf := new(bytes.Buffer)
+			require.NoError(t, s.write(context.TODO(), buf, dbLogs, o, offset))
+			assert.Equal(t, full[offset:], buf.String(), "offset %d", offset)
+		}
+	}
+
+	// only the objects after the offset are fetched, from the block or the byte that contains the offset
+	d := &rangeDriver{FilesystemStore: driver.(*objectstore.FilesystemStore)}
+	s := NewObjectStoreLogStore(d)
+	buf := new(bytes.Buffer)
+	require.NoError(t, s.write(context.TODO(), buf, dbLogs, o, 12))
+	assert.Equal(t, "d-chunk1chunk2", buf.String())
+	assert.Equal(t, []int64{blocks[0] + blocks[1]}, d.offsets)
+
+	d.offsets = nil
+	buf.Reset()
+	require.NoError(t, s.write(context.TODO(), buf, dbLogs, o, 17))
+	assert.Equal(t, "nk1chunk2", buf.String())
+	assert.Equal(t, []int64{3}, d.offsets)
+
+	// the objects after the head are not fetched
+	d.offsets = nil
+	for size := int64(0); size <= int64(len(full)); size++ {
+		w := &headWriter{size: size}
+		err := s.write(context.TODO(), w, dbLogs, o, 0)
+		if size < int64(len(full)) {
+			assert.Equal(t, errLogHeadFull, sdk.Cause(err), "size %d", size)
+		}
+		assert.Equal(t, full[:size], w.buf.String(), "size %d", size)
+	}
+	assert.Empty(t, d.offsets)
+}
@@ -46,7 +46,7 @@ func checkCondition(ctx context.Context, wr *sdk.WorkflowRun, conditions sdk.Wor
 	var conditionsOK bool
 	var errc error
 	if conditions.LuaScript == "" {
-		conditionsOK, errc = sdk.WorkflowCheckConditions(conditions.PlainConditions, params)
+		conditionsOK, errc = sdk.WorkflowCheckNodeConditions(conditions, params)
 	} else {
 		luacheck, err := luascript.NewCheck()
 		if err != nil {
@@ -85,7 +85,7 @@ func AddWorkflowRunInfo(run *sdk.WorkflowRun, isError bool, infos ...sdk.SpawnMs
 
 // computeRunStatus is useful to compute number of runs in success, building and fail
 type statusCounter struct {
-	success, building, failed, stoppped, skipped, disabled int
+	success, building, failed, stoppped, skipped, disabled, waitingApproval int
 }
 
 // getRunStatus return the status depending on number of runs in success, building, stopped and fail
@@ -93,6 +93,8 @@ func getRunStatus(counter statusCounter) string {
 	switch {
 	case counter.building > 0:
 		return sdk.StatusBuilding
+	case counter.waitingApproval > 0:
+		return sdk.StatusWaitingApproval
 	case counter.failed > 0:
 		return sdk.StatusFail
 	case counter.stoppped > 0:
@@ -114,7 +116,7 @@ func computeRunStatus(status string, counter *statusCounter) {
 		counter.success++
 	case sdk.StatusBuilding, sdk.StatusWaiting:
 		counter.building++
-	case sdk.StatusFail:
+	case sdk.StatusFail, sdk.StatusTimeout:
 		counter.failed++
 	case sdk.StatusStopped:
 		counter.stoppped++
@@ -122,6 +124,8 @@ func computeRunStatus(status string, counter *statusCounter) {
 		counter.skipped++
 	case sdk.StatusDisabled:
 		counter.disabled++
+	case sdk.StatusWaitingApproval:
+		counter.waitingApproval++
 	}
 }
 
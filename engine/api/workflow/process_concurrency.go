@@ -0,0 +1,204 @@
+package workflow
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fsamin/go-dump"
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/interpolate"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// ConcurrencyInProgressStatus contains the statuses of the workflow runs counted by the concurrency of a workflow.
+var ConcurrencyInProgressStatus = []string{sdk.StatusWaiting, sdk.StatusBuilding, sdk.StatusWaitingApproval}
+
+// processConcurrency applies the concurrency of the workflow to a new run before starting its root node.
+// It returns false if the run should not be started now, in this case the status of the run is set to
+// skipped or pending if the run is queued. With the cancel policy the superseded runs are added to the report.
+func processConcurrency(ctx context.Context, db gorp.SqlExecutor, proj *sdk.Project, wr *sdk.WorkflowRun,
+	hookEvent *sdk.WorkflowNodeRunHookEvent, manual *sdk.WorkflowNodeRunManual) (*ProcessorReport, bool, error) {
+	report := new(ProcessorReport)
+	c := wr.Workflow.Concurrency
+	if c == nil {
+		return report, true, nil
+	}
+
+	key, err := computeConcurrencyKey(proj, wr, hookEvent, manual)
+	if err != nil {
+		return nil, false, err
+	}
+	if key == "" {
+		log.Debug("processConcurrency> empty concurrency key for workflow run %d", wr.ID)
+		return report, true, nil
+	}
+	wr.Tag(sdk.WorkflowConcurrencyTag, key)
+
+	// Runs with the same key are processed one at a time, the lock is released at the end of the transaction
+	if _, err := db.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", fmt.Sprintf("workflow-concurrency-%d-%s", wr.WorkflowID, key)); err != nil {
+		return nil, false, sdk.WrapError(err, "unable to lock concurrency key %s", key)
+	}
+
+	ids, err := LoadConcurrentRunIDs(db, wr.WorkflowID, key, ConcurrencyInProgressStatus...)
+	if err != nil {
+		return nil, false, err
+	}
+	var inProgress, older []int64
+	for _, id := range ids {
+		if id == wr.ID {
+			continue
+		}
+		inProgress = append(inProgress, id)
+		if id < wr.ID {
+			older = append(older, id)
+		}
+	}
+
+	switch c.GetPolicy() {
+	case sdk.WorkflowConcurrencyPolicySkip:
+		if int64(len(inProgress)) >= c.GetMax() {
+			AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+				ID:   sdk.MsgWorkflowRunConcurrencySkipped.ID,
+				Args: []interface{}{len(inProgress), key},
+			})
+			wr.Status = sdk.StatusSkipped
+			return report, false, nil
+		}
+	case sdk.WorkflowConcurrencyPolicyQueue:
+		// Older queued runs with the same key must start first
+		queued, err := LoadConcurrentRunIDs(db, wr.WorkflowID, key, sdk.StatusPending)
+		if err != nil {
+			return nil, false, err
+		}
+		var olderQueued bool
+		for _, id := range queued {
+			if id < wr.ID {
+				olderQueued = true
+			}
+		}
+		if olderQueued || int64(len(inProgress)) >= c.GetMax() {
+			AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+				ID:   sdk.MsgWorkflowRunConcurrencyQueued.ID,
+				Args: []interface{}{len(inProgress), key},
+			})
+			wr.Status = sdk.StatusPending
+			return report, false, nil
+		}
+	default:
+		report.superseded = c.Superseded(older)
+	}
+
+	return report, true, nil
+}
+
+// computeConcurrencyKey interpolates the concurrency key of the workflow with the payload of the run.
+func computeConcurrencyKey(proj *sdk.Project, wr *sdk.WorkflowRun, hookEvent *sdk.WorkflowNodeRunHookEvent, manual *sdk.WorkflowNodeRunManual) (string, error) {
+	payload, err := computePayload(&wr.Workflow.WorkflowData.Node, hookEvent, manual)
+	if err != nil {
+		return "", err
+	}
+
+	e := dump.NewDefaultEncoder()
+	e.Formatters = []dump.KeyFormatterFunc{dump.WithDefaultLowerCaseFormatter()}
+	e.ExtraFields.DetailedMap = false
+	e.ExtraFields.DetailedStruct = false
+	e.ExtraFields.Len = false
+	e.ExtraFields.Type = false
+	vars, err := e.ToStringMap(payload)
+	if err != nil {
+		return "", sdk.WrapError(err, "unable to dump payload")
+	}
+	vars["cds.project"] = proj.Key
+	vars["cds.workflow"] = wr.Workflow.Name
+	if manual != nil {
+		vars["cds.triggered_by.username"] = manual.Username
+	}
+
+	key, err := interpolate.Do(wr.Workflow.Concurrency.Key, vars)
+	if err != nil {
+		return "", sdk.NewErrorFrom(sdk.ErrWorkflowInvalid, "unable to compute concurrency key %s: %v", wr.Workflow.Concurrency.Key, err)
+	}
+	return strings.TrimSpace(key), nil
+}
+
+// LoadConcurrentRunIDs returns the ids of the runs of a workflow with given concurrency key and statuses, from the oldest to the most recent.
+func LoadConcurrentRunIDs(db gorp.SqlExecutor, workflowID int64, key string, status ...string) ([]int64, error) {
+	query := `SELECT workflow_run.id FROM workflow_run
+	JOIN workflow_run_tag ON workflow_run_tag.workflow_run_id = workflow_run.id
+	WHERE workflow_run.workflow_id = $1
+	AND workflow_run_tag.tag = $2 AND workflow_run_tag.value = $3
+	AND workflow_run.status = ANY(string_to_array($4, ','))
+	ORDER BY workflow_run.id ASC`
+	var ids []int64
+	if _, err := db.Select(&ids, query, workflowID, sdk.WorkflowConcurrencyTag, key, strings.Join(status, ",")); err != nil {
+		return nil, sdk.WrapError(err, "unable to load concurrent runs of workflow %d", workflowID)
+	}
+	return ids, nil
+}
+
+// LoadQueuedRunIDs returns the ids of the runs queued by the concurrency of their workflow, from the oldest to the most recent.
+func LoadQueuedRunIDs(db gorp.SqlExecutor) ([]int64, error) {
+	query := `SELECT workflow_run.id FROM workflow_run
+	JOIN workflow_run_tag ON workflow_run_tag.workflow_run_id = workflow_run.id
+	WHERE workflow_run.status = $1 AND workflow_run_tag.tag = $2
+	ORDER BY workflow_run.id ASC`
+	var ids []int64
+	if _, err := db.Select(&ids, query, sdk.StatusPending, sdk.WorkflowConcurrencyTag); err != nil {
+		return nil, sdk.WrapError(err, "unable to load queued runs")
+	}
+	return ids, nil
+}
+
+// ConcurrencyKey returns the concurrency key of a workflow run.
+func ConcurrencyKey(wr sdk.WorkflowRun) string {
+	for _, t := range wr.Tags {
+		if t.Tag == sdk.WorkflowConcurrencyTag {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// InsertQueuedRun stores what is needed to start a workflow run queued by the concurrency of its workflow.
+func InsertQueuedRun(db gorp.SqlExecutor, runID int64, opts sdk.WorkflowRunPostHandlerOption, consumerID string) error {
+	btes, err := json.Marshal(opts)
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	query := `INSERT INTO workflow_run_queued (workflow_run_id, options, consumer_id) VALUES ($1, $2, $3)
+	ON CONFLICT (workflow_run_id) DO UPDATE SET options = $2, consumer_id = $3`
+	if _, err := db.Exec(query, runID, btes, consumerID); err != nil {
+		return sdk.WrapError(err, "unable to insert queued run %d", runID)
+	}
+	return nil
+}
+
+// LoadQueuedRun returns the options and the consumer id of a queued workflow run, it returns sdk.ErrNotFound if the run is not queued.
+func LoadQueuedRun(db gorp.SqlExecutor, runID int64) (*sdk.WorkflowRunPostHandlerOption, string, error) {
+	var btes []byte
+	var consumerID string
+	if err := db.QueryRow("SELECT options, consumer_id FROM workflow_run_queued WHERE workflow_run_id = $1", runID).Scan(&btes, &consumerID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, "", sdk.WithStack(sdk.ErrNotFound)
+		}
+		return nil, "", sdk.WrapError(err, "unable to load queued run %d", runID)
+	}
+	var opts sdk.WorkflowRunPostHandlerOption
+	if err := json.Unmarshal(btes, &opts); err != nil {
+		return nil, "", sdk.WrapError(err, "unable to unmarshal options of queued run %d", runID)
+	}
+	return &opts, consumerID, nil
+}
+
+// DeleteQueuedRun removes the options of a queued workflow run.
+func DeleteQueuedRun(db gorp.SqlExecutor, runID int64) error {
+	if _, err := db.Exec("DELETE FROM workflow_run_queued WHERE workflow_run_id = $1", runID); err != nil {
+		return sdk.WrapError(err, "unable to delete queued run %d", runID)
+	}
+	return nil
+}
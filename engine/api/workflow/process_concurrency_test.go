@@ -0,0 +1,118 @@
+package workflow_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestProcessConcurrency(t *testing.T) {
+	db, cache, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	u, _ := assets.InsertAdminUser(t, db)
+	consumer, _ := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, cache, proj, sdk.RandomString(10))
+
+	setConcurrency := func(c sdk.WorkflowConcurrency) {
+		w1, err := workflow.Load(context.TODO(), db, cache, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+		require.NoError(t, err)
+		w1.Concurrency = &c
+		require.NoError(t, workflow.Update(context.TODO(), db, cache, w1, proj, workflow.UpdateOptions{}))
+	}
+	createRun := func() *sdk.WorkflowRun {
+		w1, err := workflow.Load(context.TODO(), db, cache, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+		require.NoError(t, err)
+		wr, err := workflow.CreateRun(db, w1, nil, u)
+		require.NoError(t, err)
+		wr.Workflow = *w1
+		return wr
+	}
+	startRun := func(wr *sdk.WorkflowRun) *sdk.WorkflowRun {
+		_, err := workflow.StartWorkflowRun(context.TODO(), db, cache, proj, wr, &sdk.WorkflowRunPostHandlerOption{
+			Manual: &sdk.WorkflowNodeRunManual{Payload: map[string]string{"foo": "bar"}},
+		}, consumer, nil)
+		require.NoError(t, err)
+		res, err := workflow.LoadRunByID(db, wr.ID, workflow.LoadRunOptions{})
+		require.NoError(t, err)
+		return res
+	}
+
+	// With the queue policy, a run is queued while another one is in progress for the same key
+	setConcurrency(sdk.WorkflowConcurrency{Key: "{{.cds.workflow}}", Policy: sdk.WorkflowConcurrencyPolicyQueue})
+	wr1 := startRun(createRun())
+	assert.Contains(t, workflow.ConcurrencyInProgressStatus, wr1.Status)
+	assert.Equal(t, w.Name, workflow.ConcurrencyKey(*wr1))
+
+	wr2 := startRun(createRun())
+	assert.Equal(t, sdk.StatusPending, wr2.Status)
+	ids, err := workflow.LoadQueuedRunIDs(db)
+	require.NoError(t, err)
+	assert.Contains(t, ids, wr2.ID)
+
+	// The options of the queued run are kept in database
+	opts, consumerID, err := workflow.LoadQueuedRun(db, wr2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, consumer.ID, consumerID)
+	require.NotNil(t, opts.Manual)
+	assert.Equal(t, "bar", opts.Manual.Payload.(map[string]interface{})["foo"])
+
+	// With the skip policy, a run is skipped while another one is in progress for the same key
+	setConcurrency(sdk.WorkflowConcurrency{Key: "{{.cds.workflow}}", Policy: sdk.WorkflowConcurrencyPolicySkip})
+	wr3 := startRun(createRun())
+	assert.Equal(t, sdk.StatusSkipped, wr3.Status)
+
+	// When the run in progress ends, the queued run can start and its options are removed
+	wr1.Status = sdk.StatusSuccess
+	require.NoError(t, workflow.UpdateWorkflowRunStatus(db, wr1))
+	setConcurrency(sdk.WorkflowConcurrency{Key: "{{.cds.workflow}}", Policy: sdk.WorkflowConcurrencyPolicyQueue})
+	wr2.Workflow = createRun().Workflow
+	_, err = workflow.StartWorkflowRun(context.TODO(), db, cache, proj, wr2, opts, consumer, nil)
+	require.NoError(t, err)
+	wr2, err = workflow.LoadRunByID(db, wr2.ID, workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, workflow.ConcurrencyInProgressStatus, wr2.Status)
+	_, _, err = workflow.LoadQueuedRun(db, wr2.ID)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+
+	// Runs started at the same time for the same key are processed one after the other
+	wr2.Status = sdk.StatusSuccess
+	require.NoError(t, workflow.UpdateWorkflowRunStatus(db, wr2))
+	setConcurrency(sdk.WorkflowConcurrency{Key: "{{.cds.workflow}}", Policy: sdk.WorkflowConcurrencyPolicySkip})
+	var runs []*sdk.WorkflowRun
+	for i := 0; i < 5; i++ {
+		runs = append(runs, createRun())
+	}
+	var wg sync.WaitGroup
+	for _, wr := range runs {
+		wg.Add(1)
+		go func(wr *sdk.WorkflowRun) {
+			defer wg.Done()
+			_, err := workflow.StartWorkflowRun(context.TODO(), db, cache, proj, wr, &sdk.WorkflowRunPostHandlerOption{Manual: &sdk.WorkflowNodeRunManual{}}, consumer, nil)
+			assert.NoError(t, err)
+		}(wr)
+	}
+	wg.Wait()
+
+	var started int
+	for _, wr := range runs {
+		res, err := workflow.LoadRunByID(db, wr.ID, workflow.LoadRunOptions{})
+		require.NoError(t, err)
+		if res.Status != sdk.StatusSkipped {
+			started++
+		}
+	}
+	assert.Equal(t, 1, started)
+}
@@ -85,6 +85,14 @@ func processWorkflowDataRun(ctx context.Context, db gorp.SqlExecutor, store cach
 		}
 		report, _ = report.Merge(ctx, r1, nil)
 
+		// The run was skipped or queued by the workflow concurrency
+		if wr.Status == sdk.StatusSkipped || wr.Status == sdk.StatusPending {
+			if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+				return nil, false, err
+			}
+			return report, conditionOK, nil
+		}
+
 		r2, err := computeAndUpdateWorkflowRunStatus(ctx, db, wr)
 		if err != nil {
 			return nil, false, sdk.WrapError(err, "unable to compute workflow run status")
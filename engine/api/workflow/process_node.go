@@ -75,13 +75,13 @@ func processNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		n := wr.Workflow.WorkflowData.NodeByID(parentNodeRuns[0].WorkflowNodeID)
 		// If fork or JOIN and No run conditions
 		if (n.Type == sdk.NodeTypeJoin || n.Type == sdk.NodeTypeFork) &&
-			(n.Context == nil || (n.Context.Conditions.LuaScript == "" && len(n.Context.Conditions.PlainConditions) == 0)) {
+			(n.Context == nil || n.Context.Conditions.IsEmpty()) {
 			manual = parentNodeRuns[0].Manual
 		}
 	}
 
 	switch n.Type {
-	case sdk.NodeTypeFork, sdk.NodeTypePipeline, sdk.NodeTypeJoin:
+	case sdk.NodeTypeFork, sdk.NodeTypePipeline, sdk.NodeTypeJoin, sdk.NodeTypeApproval:
 		r1, conditionOK, errT := processNode(ctx, db, store, proj, wr, mapNodes, n, subNumber, parentNodeRuns, hookEvent, manual)
 		if errT != nil {
 			return nil, false, sdk.WrapError(errT, "Unable to processNode")
@@ -115,6 +115,9 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 	}
 
 	nr := createWorkflowNodeRun(wr, n, parents, subNumber, hookEvent, manual)
+	if n.Type == sdk.NodeTypeApproval {
+		nr.Status = sdk.StatusWaitingApproval
+	}
 
 	// PIPELINE PARAMETER
 	if n.Type == sdk.NodeTypePipeline {
@@ -344,6 +347,18 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		return nil, false, sdk.WrapError(err, "unable to update workflow run")
 	}
 
+	// An approval node run waits for approvals, its children will be triggered by ApproveNodeRun
+	if nr.Status == sdk.StatusWaitingApproval {
+		AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeWaitingApproval.ID,
+			Args: []interface{}{n.Name, n.ApprovalContext.RequiredApprovals()},
+		})
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, false, sdk.WrapError(err, "unable to update workflow run")
+		}
+		return report, true, nil
+	}
+
 	//Check the context.mutex to know if we are allowed to run it
 	if n.Context.Mutex {
 		//Check if there are previous waiting or builing workflownoderun
@@ -384,6 +399,18 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		//Mutex is free, continue
 	}
 
+	//Check the project lock needed by the node, the node run waits until the lock is released
+	acquired, err := acquireProjectLock(ctx, db, wr, n, nr)
+	if err != nil {
+		return nil, false, sdk.WrapError(err, "unable to acquire project lock")
+	}
+	if !acquired {
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, false, sdk.WrapError(err, "unable to update workflow run")
+		}
+		return report, true, nil
+	}
+
 	//Execute the node run !
 	r1, err := executeNodeRun(ctx, db, store, proj, nr)
 	if err != nil {
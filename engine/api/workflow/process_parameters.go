@@ -13,8 +13,11 @@ import (
 	"github.com/ovh/cds/sdk/interpolate"
 )
 
-func getNodeJobRunParameters(db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, stage *sdk.Stage) ([]sdk.Parameter, *sdk.MultiError) {
-	params := run.BuildParameters
+func getNodeJobRunParameters(db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, stage *sdk.Stage, matrix map[string]string) ([]sdk.Parameter, *sdk.MultiError) {
+	// Copy build parameters, they are shared by all the jobs of the node run
+	params := make([]sdk.Parameter, len(run.BuildParameters), len(run.BuildParameters)+len(matrix)+2)
+	copy(params, run.BuildParameters)
+	params = append(params, sdk.MatrixParameters(matrix)...)
 	tmp := map[string]string{
 		"cds.stage": stage.Name,
 		"cds.job":   j.Action.Name,
@@ -0,0 +1,318 @@
+package workflow
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+const projectLockFields = "id, project_id, name, held, workflow_name, workflow_run_id, workflow_run_number, workflow_node_run_id, node_name, since"
+
+// projectLockName returns the name of the project lock needed by a node, declared on the node or on its environment.
+func projectLockName(wr *sdk.WorkflowRun, n *sdk.Node) string {
+	if n == nil || n.Context == nil {
+		return ""
+	}
+	if n.Context.Lock != "" {
+		return n.Context.Lock
+	}
+	if n.Context.EnvironmentID != 0 {
+		if env, ok := wr.Workflow.Environments[n.Context.EnvironmentID]; ok {
+			return env.Lock
+		}
+	}
+	return ""
+}
+
+// LoadProjectLocks returns the holders and the waiters of the locks of a project, holders first then waiters from the oldest.
+func LoadProjectLocks(db gorp.SqlExecutor, projectID int64) ([]sdk.ProjectLock, error) {
+	var locks []sdk.ProjectLock
+	if _, err := db.Select(&locks, "SELECT "+projectLockFields+" FROM project_lock WHERE project_id = $1 ORDER BY name, held DESC, since, id", projectID); err != nil {
+		return nil, sdk.WrapError(err, "unable to load locks of project %d", projectID)
+	}
+	return locks, nil
+}
+
+func loadProjectLock(db gorp.SqlExecutor, query string, args ...interface{}) (*sdk.ProjectLock, error) {
+	var locks []sdk.ProjectLock
+	if _, err := db.Select(&locks, "SELECT "+projectLockFields+" FROM project_lock WHERE "+query, args...); err != nil {
+		return nil, sdk.WrapError(err, "unable to load project lock")
+	}
+	if len(locks) == 0 {
+		return nil, nil
+	}
+	return &locks[0], nil
+}
+
+// acquireProjectLock tries to acquire the project lock needed by a node run. It returns false if the lock
+// is held by another node run, in this case the node run is added to the waiters of the lock.
+func acquireProjectLock(ctx context.Context, db gorp.SqlExecutor, wr *sdk.WorkflowRun, n *sdk.Node, nr *sdk.WorkflowNodeRun) (bool, error) {
+	name := projectLockName(wr, n)
+	if name == "" {
+		return true, nil
+	}
+
+	current, err := loadProjectLock(db, "workflow_node_run_id = $1", nr.ID)
+	if err != nil {
+		return false, err
+	}
+	if current != nil && current.Held {
+		return true, nil
+	}
+
+	// A waiter keeps the date since when it is waiting, the oldest waiter is the next to acquire the lock
+	since := time.Now()
+	if current != nil {
+		since = current.Since
+		if _, err := db.Exec("DELETE FROM project_lock WHERE id = $1", current.ID); err != nil {
+			return false, sdk.WrapError(err, "unable to delete waiter of lock %s", name)
+		}
+	}
+
+	res, err := db.Exec(`INSERT INTO project_lock (project_id, name, held, workflow_name, workflow_run_id, workflow_run_number, workflow_node_run_id, node_name, since)
+		VALUES ($1, $2, true, $3, $4, $5, $6, $7, $8) ON CONFLICT (project_id, name) WHERE held DO NOTHING`,
+		wr.ProjectID, name, wr.Workflow.Name, wr.ID, wr.Number, nr.ID, n.Name, time.Now())
+	if err != nil {
+		return false, sdk.WrapError(err, "unable to acquire lock %s", name)
+	}
+	if nb, _ := res.RowsAffected(); nb > 0 {
+		log.Debug("acquireProjectLock> lock %s acquired by node run %d", name, nr.ID)
+		if current != nil {
+			AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+				ID:   sdk.MsgWorkflowNodeLockAcquired.ID,
+				Args: []interface{}{n.Name, name},
+			})
+		}
+		return true, nil
+	}
+
+	if _, err := db.Exec(`INSERT INTO project_lock (project_id, name, held, workflow_name, workflow_run_id, workflow_run_number, workflow_node_run_id, node_name, since)
+		VALUES ($1, $2, false, $3, $4, $5, $6, $7, $8)`,
+		wr.ProjectID, name, wr.Workflow.Name, wr.ID, wr.Number, nr.ID, n.Name, since); err != nil {
+		return false, sdk.WrapError(err, "unable to wait for lock %s", name)
+	}
+
+	if current == nil {
+		holder, err := loadProjectLock(db, "project_id = $1 AND name = $2 AND held", wr.ProjectID, name)
+		if err != nil {
+			return false, err
+		}
+		var holderName string
+		var holderNumber int64
+		if holder != nil {
+			holderName, holderNumber = holder.WorkflowName, holder.WorkflowRunNumber
+		}
+		AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeLockWaiting.ID,
+			Args: []interface{}{n.Name, name, holderName, holderNumber},
+		})
+	}
+	log.Debug("acquireProjectLock> node run %d is waiting for lock %s", nr.ID, name)
+	return false, nil
+}
+
+// releaseProjectLock releases the project lock held by a node run and starts the next waiter.
+func releaseProjectLock(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, nr *sdk.WorkflowNodeRun) (*ProcessorReport, error) {
+	report := new(ProcessorReport)
+
+	var locks []sdk.ProjectLock
+	if _, err := db.Select(&locks, "DELETE FROM project_lock WHERE workflow_node_run_id = $1 RETURNING "+projectLockFields, nr.ID); err != nil {
+		return nil, sdk.WrapError(err, "unable to release lock of node run %d", nr.ID)
+	}
+	for _, l := range locks {
+		if !l.Held {
+			continue
+		}
+		log.Debug("releaseProjectLock> lock %s released by node run %d", l.Name, nr.ID)
+		r1, err := startProjectLockWaiter(ctx, db, store, proj, l.ProjectID, l.Name)
+		report, err = report.Merge(ctx, r1, err)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// startProjectLockWaiter gives a free lock to its oldest waiter and executes the waiting node run.
+func startProjectLockWaiter(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, projectID int64, name string) (*ProcessorReport, error) {
+	report := new(ProcessorReport)
+	for {
+		waiter, err := loadProjectLock(db, "project_id = $1 AND name = $2 AND NOT held ORDER BY since, id LIMIT 1", projectID, name)
+		if err != nil {
+			return nil, err
+		}
+		if waiter == nil {
+			return report, nil
+		}
+
+		nodeRun, err := LoadNodeRunByID(db, waiter.WorkflowNodeRunID, LoadRunOptions{})
+		if err != nil && sdk.Cause(err) != sql.ErrNoRows {
+			return nil, err
+		}
+		// The waiter is not waiting anymore, ex: its workflow run has been stopped
+		if nodeRun == nil || sdk.StatusIsTerminated(nodeRun.Status) {
+			if _, err := db.Exec("DELETE FROM project_lock WHERE id = $1", waiter.ID); err != nil {
+				return nil, sdk.WrapError(err, "unable to delete waiter of lock %s", name)
+			}
+			continue
+		}
+
+		// The run of the waiter can be processed at the same time by another transaction
+		wr, err := LoadAndLockRunByID(db, nodeRun.WorkflowRunID, LoadRunOptions{})
+		if err != nil {
+			return nil, err
+		}
+		acquired, err := acquireProjectLock(ctx, db, wr, wr.Workflow.WorkflowData.NodeByID(nodeRun.WorkflowNodeID), nodeRun)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return report, nil
+		}
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, sdk.WrapError(err, "unable to update workflow run %d after lock acquisition", wr.ID)
+		}
+
+		log.Debug("startProjectLockWaiter> process the node run %d because lock %s has been released", nodeRun.ID, name)
+		r1, err := executeNodeRun(ctx, db, store, proj, nodeRun)
+		return report.Merge(ctx, r1, err)
+	}
+}
+
+// LoadProjectLockOwner returns the holder of a project lock and the username of the user that triggered
+// the workflow run holding it. It returns sdk.ErrNotFound if the lock is not held.
+func LoadProjectLockOwner(db gorp.SqlExecutor, projectID int64, name string) (*sdk.ProjectLock, string, error) {
+	holder, err := loadProjectLock(db, "project_id = $1 AND name = $2 AND held", projectID, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if holder == nil {
+		return nil, "", sdk.NewErrorFrom(sdk.ErrNotFound, "lock %s is not held", name)
+	}
+	wr, err := LoadRunByID(db, holder.WorkflowRunID, LoadRunOptions{DisableDetailledNodeRun: true})
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrWorkflowNotFound) {
+			return holder, "", nil
+		}
+		return nil, "", err
+	}
+	for _, t := range wr.Tags {
+		if t.Tag == tagTriggeredBy {
+			return holder, t.Value, nil
+		}
+	}
+	return holder, "", nil
+}
+
+// ReleaseProjectLock force the release of a project lock by a user, the node run that holds the lock is not stopped.
+func ReleaseProjectLock(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, name string, username string) (*ProcessorReport, error) {
+	holder, err := loadProjectLock(db, "project_id = $1 AND name = $2 AND held", proj.ID, name)
+	if err != nil {
+		return nil, err
+	}
+	if holder == nil {
+		return nil, sdk.NewErrorFrom(sdk.ErrNotFound, "lock %s is not held", name)
+	}
+	if _, err := db.Exec("DELETE FROM project_lock WHERE id = $1", holder.ID); err != nil {
+		return nil, sdk.WrapError(err, "unable to release lock %s", name)
+	}
+
+	report := new(ProcessorReport)
+	wr, err := LoadAndLockRunByID(db, holder.WorkflowRunID, LoadRunOptions{DisableDetailledNodeRun: true})
+	if err != nil && !sdk.ErrorIs(err, sdk.ErrWorkflowNotFound) {
+		return nil, err
+	}
+	if wr != nil {
+		AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeLockReleased.ID,
+			Args: []interface{}{name, username},
+		})
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+		}
+		report.Add(ctx, *wr)
+	}
+
+	r1, err := startProjectLockWaiter(ctx, db, store, proj, proj.ID, name)
+	return report.Merge(ctx, r1, err)
+}
+
+// StaleProjectLock is a project lock with the status of its node run.
+type StaleProjectLock struct {
+	sdk.ProjectLock
+	ProjectKey string         `db:"projectkey"`
+	Status     sql.NullString `db:"status"`
+}
+
+// Terminated returns true if the node run of the lock is over.
+func (l StaleProjectLock) Terminated() bool {
+	return !l.Status.Valid || sdk.StatusIsTerminated(l.Status.String)
+}
+
+// LoadStaleProjectLocks returns the project locks held for more than sdk.ProjectLockTimeout or by a node run
+// that is over, and the waiters that are not waiting anymore, holders first.
+func LoadStaleProjectLocks(db gorp.SqlExecutor) ([]StaleProjectLock, error) {
+	var locks []StaleProjectLock
+	query := `SELECT project_lock.id, project_lock.project_id, project_lock.name, project_lock.held, project_lock.workflow_name,
+		project_lock.workflow_run_id, project_lock.workflow_run_number, project_lock.workflow_node_run_id, project_lock.node_name,
+		project_lock.since, project.projectkey, workflow_node_run.status
+	FROM project_lock
+	JOIN project ON project.id = project_lock.project_id
+	LEFT JOIN workflow_node_run ON workflow_node_run.id = project_lock.workflow_node_run_id
+	ORDER BY project_lock.held DESC, project_lock.since`
+	if _, err := db.Select(&locks, query); err != nil {
+		return nil, sdk.WrapError(err, "cannot load project locks")
+	}
+
+	stale := make([]StaleProjectLock, 0, len(locks))
+	for _, l := range locks {
+		if l.Terminated() || l.IsExpired() {
+			stale = append(stale, l)
+		}
+	}
+	return stale, nil
+}
+
+// ReleaseStaleProjectLock releases a stale project lock and starts the next waiter. The project must be loaded
+// like for a workflow run, it returns sdk.ErrNotFound if the lock was already released.
+func ReleaseStaleProjectLock(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, l StaleProjectLock) (*ProcessorReport, error) {
+	res, err := db.Exec("DELETE FROM project_lock WHERE id = $1", l.ID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot delete project lock %d", l.ID)
+	}
+	if nb, _ := res.RowsAffected(); nb == 0 {
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+
+	report := new(ProcessorReport)
+	if !l.Held {
+		return report, nil
+	}
+
+	if !l.Terminated() {
+		wr, err := LoadAndLockRunByID(db, l.WorkflowRunID, LoadRunOptions{DisableDetailledNodeRun: true})
+		if err != nil {
+			return nil, sdk.WrapError(err, "cannot load workflow run %d", l.WorkflowRunID)
+		}
+		AddWorkflowRunInfo(wr, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeLockExpired.ID,
+			Args: []interface{}{l.Name, l.NodeName},
+		})
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, sdk.WrapError(err, "cannot update workflow run %d", wr.ID)
+		}
+		report.Add(ctx, *wr)
+	}
+
+	r1, err := startProjectLockWaiter(ctx, db, store, proj, l.ProjectID, l.Name)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot start waiter of lock %s", l.Name)
+	}
+	return report.Merge(ctx, r1, nil)
+}
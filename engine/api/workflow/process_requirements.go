@@ -15,13 +15,13 @@ import (
 )
 
 // processNodeJobRunRequirements returns requirements list interpolated, and true or false if at least
-// one requirement is of type "Service"
-func processNodeJobRunRequirements(ctx context.Context, db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, execsGroupIDs []int64, integrationPluginBinaries []sdk.GRPCPluginBinary) (sdk.RequirementList, bool, *sdk.Model, *sdk.MultiError) {
+// one requirement is of type "Service". Requirements are interpolated with the matrix combination of the job run.
+func processNodeJobRunRequirements(ctx context.Context, db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, matrix map[string]string, execsGroupIDs []int64, integrationPluginBinaries []sdk.GRPCPluginBinary) (sdk.RequirementList, bool, *sdk.Model, *sdk.MultiError) {
 	var requirements sdk.RequirementList
 	var errm sdk.MultiError
 	var containsService bool
 	var model string
-	var tmp = sdk.ParametersToMap(run.BuildParameters)
+	var tmp = sdk.ParametersToMap(append(sdk.MatrixParameters(matrix), run.BuildParameters...))
 
 	for i := range integrationPluginBinaries {
 		j.Action.Requirements = append(j.Action.Requirements, integrationPluginBinaries[i].Requirements...)
@@ -63,11 +63,21 @@ func processStartFromRootNode(ctx context.Context, db gorp.SqlExecutor, store ca
 		},
 	})
 
-	r1, conditionOK, errP := processNodeRun(ctx, db, store, proj, wr, mapNodes, &wr.Workflow.WorkflowData.Node, 0, nil, hookEvent, manual)
+	// Check the concurrency of the workflow, the run can be skipped or queued
+	r1, canStart, errC := processConcurrency(ctx, db, proj, wr, hookEvent, manual)
+	if errC != nil {
+		return nil, false, sdk.WrapError(errC, "Unable to process workflow concurrency")
+	}
+	report, _ = report.Merge(ctx, r1, nil)
+	if !canStart {
+		return report, true, nil
+	}
+
+	r2, conditionOK, errP := processNodeRun(ctx, db, store, proj, wr, mapNodes, &wr.Workflow.WorkflowData.Node, 0, nil, hookEvent, manual)
 	if errP != nil {
 		return nil, false, sdk.WrapError(errP, "Unable to process workflow node run")
 	}
-	report, _ = report.Merge(ctx, r1, nil)
+	report, _ = report.Merge(ctx, r2, nil)
 	return report, conditionOK, nil
 }
 
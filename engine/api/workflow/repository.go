@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/engine/api/keys"
 	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/engine/api/repositoriesmanager"
 	"github.com/ovh/cds/engine/api/services"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/exportentities"
@@ -79,7 +81,10 @@ func extractWorkflow(ctx context.Context, db *gorp.DbMap, store cache.Store, p *
 	ctx, end := observability.Span(ctx, "workflow.extractWorkflow")
 	defer end()
 	var allMsgs []sdk.Message
-	// Read files
+	// Resolve includes then read files
+	if err := ResolveIncludes(ctx, db, store, p, &ope); err != nil {
+		return allMsgs, err
+	}
 	tr, err := ReadCDSFiles(ope.LoadFiles.Results)
 	if err != nil {
 		allMsgs = append(allMsgs, sdk.NewMessage(sdk.MsgWorkflowErrorBadCdsDir))
@@ -141,8 +146,135 @@ func ReadCDSFiles(files map[string][]byte) (*tar.Reader, error) {
 	return tar.NewReader(buf), nil
 }
 
+// ResolveIncludes loads the files included from library repositories by the workflow files of given operation, then
+// replaces the v2.0 workflow files by their version with includes resolved. Included files are removed from the results.
+func ResolveIncludes(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, ope *sdk.Operation) error {
+	ctx, end := observability.Span(ctx, "workflow.ResolveIncludes")
+	defer end()
+
+	if err := loadLibraryIncludes(ctx, db, store, proj, ope); err != nil {
+		return err
+	}
+
+	for key := range ope.LoadFiles.Includes {
+		delete(ope.LoadFiles.Results, key)
+	}
+
+	for fname, btes := range ope.LoadFiles.Results {
+		if strings.Contains(fname, ".app.") || strings.Contains(fname, ".pip.") || strings.Contains(fname, ".env.") {
+			continue
+		}
+		ew, err := exportentities.UnmarshalWorkflow(btes, exportentities.FormatYAML)
+		if err != nil || len(ew.Include) == 0 {
+			// invalid files will be reported when extracting the workflow
+			continue
+		}
+		if err := ew.ResolveIncludes(ope.LoadFiles.Includes); err != nil {
+			return sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWorkflowInvalid, "unable to resolve includes of %s: %v", fname, sdk.Cause(err)))
+		}
+		resolved, err := yaml.Marshal(ew)
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		ope.LoadFiles.Results[fname] = resolved
+	}
+
+	return nil
+}
+
+// loadLibraryIncludes loads recursively the files included from library repositories, with an operation for each file
+// on the same repository manager as given operation. Loaded files are added to the includes of given operation.
+func loadLibraryIncludes(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, ope *sdk.Operation) error {
+	type file struct {
+		content []byte
+		library *exportentities.WorkflowInclude
+	}
+	files := make([]file, 0, len(ope.LoadFiles.Results)+len(ope.LoadFiles.Includes))
+	for _, btes := range ope.LoadFiles.Results {
+		files = append(files, file{content: btes})
+	}
+	for _, btes := range ope.LoadFiles.Includes {
+		files = append(files, file{content: btes})
+	}
+
+	var client sdk.VCSAuthorizedClient
+	for len(files) > 0 {
+		f := files[0]
+		files = files[1:]
+
+		includes, err := exportentities.ReadWorkflowIncludes(f.content)
+		if err != nil {
+			continue
+		}
+		for _, i := range includes {
+			if !i.IsLibrary() {
+				// files of the workflow repository are loaded by the repositories service
+				if f.library == nil {
+					continue
+				}
+				i.Repository, i.Ref = f.library.Repository, f.library.Ref
+			}
+			if _, ok := ope.LoadFiles.Includes[i.Key()]; ok || i.IsValid() != nil {
+				continue
+			}
+
+			if client == nil {
+				vcsServer := repositoriesmanager.GetProjectVCSServer(proj, ope.VCSServer)
+				if vcsServer == nil {
+					return sdk.WithStack(fmt.Errorf("no vcsServer found"))
+				}
+				client, err = repositoriesmanager.AuthorizedClient(ctx, db, store, proj.Key, vcsServer)
+				if err != nil {
+					return sdk.NewErrorWithStack(err, sdk.ErrNoReposManagerClientAuth)
+				}
+			}
+			repo, err := client.RepoByFullname(ctx, i.Repository)
+			if err != nil {
+				return sdk.WrapError(err, "cannot get library repository %s", i.Repository)
+			}
+
+			libOpe := sdk.Operation{
+				VCSServer:          ope.VCSServer,
+				RepoFullName:       i.Repository,
+				URL:                repo.HTTPCloneURL,
+				RepositoryStrategy: ope.RepositoryStrategy,
+				Setup: sdk.OperationSetup{
+					Checkout: sdk.OperationCheckout{
+						Tag: i.Ref,
+					},
+				},
+				LoadFiles: sdk.OperationLoadFiles{
+					Pattern: path.Clean(strings.TrimPrefix(i.Path, "/")),
+				},
+			}
+			if ope.RepositoryStrategy.ConnectionType == "ssh" {
+				libOpe.URL = repo.SSHCloneURL
+			}
+			if err := PostRepositoryOperation(ctx, db, *proj, &libOpe, nil); err != nil {
+				return sdk.WrapError(err, "unable to post repository operation")
+			}
+			if err := pollRepositoryOperation(ctx, db, store, &libOpe); err != nil {
+				return sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWorkflowInvalid, "cannot load included file %s", i.Key()))
+			}
+
+			if ope.LoadFiles.Includes == nil {
+				ope.LoadFiles.Includes = make(map[string][]byte)
+			}
+			for _, loaded := range []map[string][]byte{libOpe.LoadFiles.Results, libOpe.LoadFiles.Includes} {
+				for p, btes := range loaded {
+					inc := exportentities.WorkflowInclude{Path: p, Repository: i.Repository, Ref: i.Ref}
+					ope.LoadFiles.Includes[inc.Key()] = btes
+					files = append(files, file{content: btes, library: &inc})
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 type exportedEntities struct {
-	wrkflw exportentities.Workflow
+	wrkflw exportentities.WorkflowV2
 	apps   map[string]exportentities.Application
 	pips   map[string]exportentities.PipelineV1
 	envs   map[string]exportentities.Environment
@@ -208,12 +340,32 @@ func extractFromCDSFiles(ctx context.Context, tr *tar.Reader) (*exportedEntities
 				mError.Append(fmt.Errorf("two workflows files found: %s and %s", workflowFileName, hdr.Name))
 				break
 			}
-			if err := yaml.Unmarshal(b, &res.wrkflw); err != nil {
+			wrkflw, err := exportentities.UnmarshalWorkflow(b, exportentities.FormatYAML)
+			if err != nil {
 				log.Error(ctx, "Push> Unable to unmarshal workflow %s: %v", hdr.Name, err)
-				mError.Append(fmt.Errorf("Unable to unmarshal workflow %s: %v", hdr.Name, err))
+				mError.Append(fmt.Errorf("Unable to unmarshal workflow %s: %v", hdr.Name, sdk.Cause(err)))
 				continue
 			}
+			res.wrkflw = *wrkflw
+		}
+	}
+
+	// pipelines and environments declared in a v2.0 workflow file are imported as the ones from their own files
+	for _, pip := range res.wrkflw.InlinePipelines() {
+		fname := fmt.Sprintf(exportentities.PullPipelineName, pip.Name)
+		if _, ok := res.pips[fname]; ok {
+			mError.Append(fmt.Errorf("pipeline %s is declared in workflow file and in file %s", pip.Name, fname))
+			continue
+		}
+		res.pips[fname] = pip
+	}
+	for _, env := range res.wrkflw.InlineEnvironments() {
+		fname := fmt.Sprintf(exportentities.PullEnvironmentName, env.Name)
+		if _, ok := res.envs[fname]; ok {
+			mError.Append(fmt.Errorf("environment %s is declared in workflow file and in file %s", env.Name, fname))
+			continue
 		}
+		res.envs[fname] = env
 	}
 
 	// We only use the multiError during unmarshalling steps.
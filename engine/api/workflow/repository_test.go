@@ -0,0 +1,214 @@
+package workflow_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/repositoriesmanager"
+	"github.com/ovh/cds/engine/api/services"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func TestResolveIncludes(t *testing.T) {
+	db, cache, end := test.SetupPG(t)
+	defer end()
+
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+	require.NoError(t, repositoriesmanager.InsertForProject(db, proj, &sdk.ProjectVCSServer{
+		Name: "github",
+		Data: map[string]string{
+			"token":  "foo",
+			"secret": "bar",
+		},
+	}))
+	proj, err := project.Load(db, cache, proj.Key)
+	require.NoError(t, err)
+
+	mockServiceVCS, _ := assets.InsertService(t, db, "TestResolveIncludesVCS", services.TypeVCS)
+	defer func() {
+		_ = services.Delete(db, mockServiceVCS) // nolint
+	}()
+	mockServiceRepositories, _ := assets.InsertService(t, db, "TestResolveIncludesRepositories", services.TypeRepositories)
+	defer func() {
+		_ = services.Delete(db, mockServiceRepositories) // nolint
+	}()
+
+	// files loaded by the repositories service for each library operation, by include key
+	libraries := map[string]sdk.OperationLoadFiles{
+		"my-org/cds-library@v1.0.0:deploy.yml": {
+			Results: map[string][]byte{
+				"deploy.yml": []byte(`include:
+- path: envs/prod.yml
+- path: notify.yml
+  repository: my-org/cds-notify
+  ref: v2.0.0
+workflow:
+  deploy:
+    needs:
+    - build
+    pipeline: deploy
+    environment: prod
+pipelines:
+  deploy: {}
+`),
+			},
+			Includes: map[string][]byte{
+				"envs/prod.yml": []byte(`environments:
+  prod: {}
+`),
+			},
+		},
+		"my-org/cds-notify@v2.0.0:notify.yml": {
+			Results: map[string][]byte{
+				"notify.yml": []byte(`workflow:
+  notify:
+    needs:
+    - deploy
+    pipeline: notify
+pipelines:
+  notify: {}
+`),
+			},
+		},
+	}
+	operations := make(map[string]sdk.Operation)
+
+	//This is a mock for the repositories and vcs services
+	services.HTTPClient = mock(
+		func(r *http.Request) (*http.Response, error) {
+			body := new(bytes.Buffer)
+			w := new(http.Response)
+			enc := json.NewEncoder(body)
+			w.Body = ioutil.NopCloser(body)
+			w.StatusCode = http.StatusOK
+			switch {
+			case r.URL.String() == "/operations":
+				var ope sdk.Operation
+				if err := json.NewDecoder(r.Body).Decode(&ope); err != nil {
+					return writeError(w, err)
+				}
+				inc := exportentities.WorkflowInclude{Path: ope.LoadFiles.Pattern, Repository: ope.RepoFullName, Ref: ope.Setup.Checkout.Tag}
+				files, ok := libraries[inc.Key()]
+				if !ok {
+					return writeError(w, sdk.NewErrorFrom(sdk.ErrNotFound, "unknown file %s", inc.Key()))
+				}
+				ope.UUID = sdk.UUID()
+				ope.Status = sdk.OperationStatusDone
+				ope.LoadFiles.Results = files.Results
+				ope.LoadFiles.Includes = files.Includes
+				operations[ope.UUID] = ope
+				if err := enc.Encode(ope); err != nil {
+					return writeError(w, err)
+				}
+			case strings.HasPrefix(r.URL.String(), "/operations/"):
+				ope, ok := operations[strings.TrimPrefix(r.URL.String(), "/operations/")]
+				if !ok {
+					return writeError(w, sdk.WithStack(sdk.ErrNotFound))
+				}
+				if err := enc.Encode(ope); err != nil {
+					return writeError(w, err)
+				}
+			case r.URL.String() == "/vcs/github/repos/my-org/cds-library", r.URL.String() == "/vcs/github/repos/my-org/cds-notify":
+				fullname := strings.TrimPrefix(r.URL.String(), "/vcs/github/repos/")
+				vcsRepo := sdk.VCSRepo{
+					Name:         fullname,
+					SSHCloneURL:  "git:" + fullname,
+					HTTPCloneURL: "https:" + fullname,
+				}
+				if err := enc.Encode(vcsRepo); err != nil {
+					return writeError(w, err)
+				}
+			default:
+				w.StatusCode = http.StatusNotFound
+			}
+
+			return w, nil
+		},
+	)
+
+	// the workflow includes a file of its repository and a file of a library that includes a file of another library
+	ope := sdk.Operation{
+		VCSServer:    "github",
+		RepoFullName: "foo/myrepo",
+		LoadFiles: sdk.OperationLoadFiles{
+			Pattern: ".cds/**/*.yml",
+			Results: map[string][]byte{
+				".cds/my-workflow.yml": []byte(`name: my-workflow
+version: v2.0
+include:
+- path: .cds/ci/build.yml
+- path: deploy.yml
+  repository: my-org/cds-library
+  ref: v1.0.0
+workflow:
+  build:
+    pipeline: build
+`),
+				".cds/ci/build.yml": []byte(`pipelines:
+  build: {}
+`),
+			},
+			Includes: map[string][]byte{
+				".cds/ci/build.yml": []byte(`pipelines:
+  build: {}
+`),
+			},
+		},
+	}
+	require.NoError(t, workflow.ResolveIncludes(context.TODO(), db, cache, proj, &ope))
+
+	assert.Contains(t, ope.LoadFiles.Includes, "my-org/cds-library@v1.0.0:deploy.yml")
+	assert.Contains(t, ope.LoadFiles.Includes, "my-org/cds-library@v1.0.0:envs/prod.yml")
+	assert.Contains(t, ope.LoadFiles.Includes, "my-org/cds-notify@v2.0.0:notify.yml")
+
+	// included files are not imported as workflows
+	require.Len(t, ope.LoadFiles.Results, 1)
+	ew, err := exportentities.UnmarshalWorkflow(ope.LoadFiles.Results[".cds/my-workflow.yml"], exportentities.FormatYAML)
+	require.NoError(t, err)
+	assert.Empty(t, ew.Include)
+	assert.Contains(t, ew.Pipelines, "build")
+	assert.Contains(t, ew.Pipelines, "deploy")
+	assert.Contains(t, ew.Pipelines, "notify")
+	assert.Contains(t, ew.Environments, "prod")
+	assert.Equal(t, []string{"build"}, ew.Workflow["deploy"].Needs)
+	assert.Equal(t, []string{"deploy"}, ew.Workflow["notify"].Needs)
+	_, err = ew.GetWorkflow()
+	require.NoError(t, err)
+
+	// a file of the repository that was not loaded can't be included
+	ope = sdk.Operation{
+		VCSServer:    "github",
+		RepoFullName: "foo/myrepo",
+		LoadFiles: sdk.OperationLoadFiles{
+			Pattern: ".cds/**/*.yml",
+			Results: map[string][]byte{
+				".cds/my-workflow.yml": []byte(`name: my-workflow
+version: v2.0
+include:
+- path: .cds/ci/unknown.yml
+workflow:
+  build:
+    pipeline: build
+`),
+			},
+		},
+	}
+	err = workflow.ResolveIncludes(context.TODO(), db, cache, proj, &ope)
+	require.Error(t, err)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWorkflowInvalid))
+	assert.Contains(t, err.Error(), "cannot find included file .cds/ci/unknown.yml")
+}
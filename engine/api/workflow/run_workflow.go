@@ -114,6 +114,9 @@ func StartWorkflowRun(ctx context.Context, db *gorp.DbMap, store cache.Store, p
 	if err := UpdateWorkflowRun(ctx, tx, wr); err != nil {
 		return report, err
 	}
+	if err := DeleteQueuedRun(tx, wr.ID); err != nil {
+		return report, err
+	}
 
 	if opts.Hook != nil {
 		// Run from HOOK
@@ -165,6 +168,13 @@ func StartWorkflowRun(ctx context.Context, db *gorp.DbMap, store cache.Store, p
 		}
 	}
 
+	// The run was queued by the workflow concurrency, keep its options to start it later
+	if wr.Status == sdk.StatusPending {
+		if err := InsertQueuedRun(tx, wr.ID, *opts, u.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	//Commit and return success
 	if err := tx.Commit(); err != nil {
 		return nil, sdk.WrapError(err, "unable to commit transaction")
@@ -626,16 +626,18 @@ queueRun:
 		assert.Len(t, secrets, 1)
 
 		//TestAddLog
-		assert.NoError(t, workflow.AddLog(db, j, &sdk.Log{
+		_, err = workflow.AddLog(context.TODO(), db, j, &sdk.Log{
 			Val: "This is a log",
-		}, workflow.DefaultMaxLogSize))
+		}, workflow.DefaultMaxLogSize)
+		assert.NoError(t, err)
 		if t.Failed() {
 			tx.Rollback()
 			t.FailNow()
 		}
-		assert.NoError(t, workflow.AddLog(db, j, &sdk.Log{
+		_, err = workflow.AddLog(context.TODO(), db, j, &sdk.Log{
 			Val: "This is another log",
-		}, workflow.DefaultMaxLogSize))
+		}, workflow.DefaultMaxLogSize)
+		assert.NoError(t, err)
 		if t.Failed() {
 			tx.Rollback()
 			t.FailNow()
@@ -14,7 +14,7 @@ import (
 )
 
 //Import is able to create a new workflow and all its components
-func Import(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, oldW, w *sdk.Workflow, u sdk.Identifiable, force bool, msgChan chan<- sdk.Message) error {
+func Import(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, oldW, w *sdk.Workflow, u sdk.Identifiable, opts ImportOptions, msgChan chan<- sdk.Message) error {
 	ctx, end := observability.Span(ctx, "workflow.Import")
 	defer end()
 
@@ -34,7 +34,7 @@ func Import(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *s
 
 	// create the workflow if not exists
 	if oldW == nil {
-		if err := Insert(ctx, db, store, w, proj); err != nil {
+		if err := insert(ctx, db, store, w, proj, opts.DryRun); err != nil {
 			return sdk.WrapError(err, "Unable to insert workflow")
 		}
 		if msgChan != nil {
@@ -53,7 +53,7 @@ func Import(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *s
 		w.Icon = oldW.Icon
 	}
 
-	if !force {
+	if !opts.Force {
 		return sdk.NewError(sdk.ErrConflict, fmt.Errorf("Workflow exists"))
 	}
 
@@ -83,7 +83,7 @@ func Import(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *s
 	// Hook registration must only be done on default branch in case of workflow as-code
 	// The derivation branch is set in workflow parser it is not coming from the default branch
 	uptOptions := UpdateOptions{
-		DisableHookManagement: w.DerivationBranch != "",
+		DisableHookManagement: w.DerivationBranch != "" || opts.DryRun,
 		OldWorkflow:           oldW,
 	}
 
@@ -24,10 +24,18 @@ type ImportOptions struct {
 	RepositoryName     string
 	RepositoryStrategy sdk.RepositoryStrategy
 	HookUUID           string
+	// DryRun disables the registration of the hooks, it should be set when the import is rolled back
+	DryRun bool
 }
 
-// Parse parse an exportentities.workflow and return the parsed workflow
+// Parse parse an exportentities.workflow and return the parsed workflow, the workflow is converted to the v2.0 syntax first.
 func Parse(ctx context.Context, proj *sdk.Project, ew *exportentities.Workflow) (*sdk.Workflow, error) {
+	ew2 := exportentities.NewWorkflowV2(*ew)
+	return ParseV2(ctx, proj, &ew2)
+}
+
+// ParseV2 parse an exportentities.WorkflowV2 and return the parsed workflow
+func ParseV2(ctx context.Context, proj *sdk.Project, ew *exportentities.WorkflowV2) (*sdk.Workflow, error) {
 	log.Info(ctx, "Parse>> Parse workflow %s in project %s", ew.Name, proj.Key)
 	log.Debug("Parse>> Workflow: %+v", ew)
 
@@ -58,6 +66,12 @@ func Parse(ctx context.Context, proj *sdk.Project, ew *exportentities.Workflow)
 
 // ParseAndImport parse an exportentities.workflow and insert or update the workflow in database
 func ParseAndImport(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, oldW *sdk.Workflow, ew *exportentities.Workflow, u sdk.Identifiable, opts ImportOptions) (*sdk.Workflow, []sdk.Message, error) {
+	ew2 := exportentities.NewWorkflowV2(*ew)
+	return ParseAndImportV2(ctx, db, store, proj, oldW, &ew2, u, opts)
+}
+
+// ParseAndImportV2 parse an exportentities.WorkflowV2 and insert or update the workflow in database
+func ParseAndImportV2(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj *sdk.Project, oldW *sdk.Workflow, ew *exportentities.WorkflowV2, u sdk.Identifiable, opts ImportOptions) (*sdk.Workflow, []sdk.Message, error) {
 	ctx, end := observability.Span(ctx, "workflow.ParseAndImport")
 	defer end()
 
@@ -65,7 +79,7 @@ func ParseAndImport(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 	log.Debug("ParseAndImport>> Workflow: %+v", ew)
 
 	//Parse workflow
-	w, errW := Parse(ctx, proj, ew)
+	w, errW := ParseV2(ctx, proj, ew)
 	if errW != nil {
 		return nil, nil, errW
 	}
@@ -178,7 +192,7 @@ func ParseAndImport(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		}
 	}(&msgList)
 
-	globalError := Import(ctx, db, store, proj, oldW, w, u, opts.Force, msgChan)
+	globalError := Import(ctx, db, store, proj, oldW, w, u, opts, msgChan)
 	close(msgChan)
 	done.Wait()
 
@@ -24,6 +24,9 @@ func SendEvent(ctx context.Context, db gorp.SqlExecutor, key string, report *Pro
 	}
 	for _, wr := range report.workflows {
 		event.PublishWorkflowRun(ctx, wr, key)
+		if sdk.StatusIsTerminated(wr.Status) {
+			IndexRun(ctx, db, key, wr)
+		}
 	}
 	for _, wnr := range report.nodes {
 		wr, errWR := LoadRunByID(db, wnr.WorkflowRunID, LoadRunOptions{
@@ -9,6 +9,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/ovh/cds/engine/api/objectstore"
 	"github.com/ovh/cds/engine/api/project"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
 	"github.com/ovh/cds/engine/api/workflow"
@@ -105,7 +106,7 @@ func (api *API) releaseApplicationWorkflowHandler() service.Handler {
 		}
 
 		for _, a := range artifactToUpload {
-			f, err := api.SharedStorage.Fetch(ctx, &a)
+			f, err := api.SharedStorage.Fetch(ctx, objectstore.ArtifactObject(&a))
 			if err != nil {
 				return sdk.WrapError(err, "Cannot fetch artifact")
 			}
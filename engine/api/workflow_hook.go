@@ -103,11 +103,11 @@ func (api *API) getWorkflowHookModelsHandler() service.Handler {
 			}
 		}
 
-		hasKafka := false
+		// A hook on a message broker is only available with a project integration of its broker
+		hookIntegrationModels := make(map[string]bool)
 		for _, integration := range p.Integrations {
 			if integration.Model.Hook {
-				hasKafka = true
-				break
+				hookIntegrationModels[integration.Model.Name] = true
 			}
 		}
 
@@ -140,8 +140,8 @@ func (api *API) getWorkflowHookModelsHandler() service.Handler {
 				if repoPollerEnable {
 					models = append(models, m[i])
 				}
-			case sdk.KafkaHookModelName:
-				if hasKafka {
+			case sdk.KafkaHookModelName, sdk.RabbitMQHookModelName, sdk.NATSHookModelName, sdk.AMQPHookModelName:
+				if hookIntegrationModels[sdk.HookModelIntegrationModel(m[i].Name)] {
 					models = append(models, m[i])
 				}
 			default:
@@ -232,6 +232,26 @@ func (api *API) postWorkflowJobHookCallbackHandler() service.Handler {
 			return sdk.WrapError(err, "Unable to unmarshal body")
 		}
 
+		wr, err := workflow.LoadRun(ctx, api.mustDB(), key, workflowName, number, workflow.LoadRunOptions{
+			DisableDetailledNodeRun: true,
+		})
+		if err != nil {
+			return sdk.WrapError(err, "postWorkflowJobHookCallbackHandler> Cannot load workflow run")
+		}
+
+		pv, err := project.GetAllVariableInProject(api.mustDB(), wr.Workflow.ProjectID, project.WithClearPassword())
+		if err != nil {
+			return sdk.WrapError(err, "Cannot load project variable")
+		}
+
+		secrets, errSecret := workflow.LoadSecrets(api.mustDB(), api.Cache, nil, wr, pv)
+		if errSecret != nil {
+			return sdk.WrapError(errSecret, "postWorkflowJobHookCallbackHandler> Cannot load secrets")
+		}
+		if err := workflow.ResolveBackendSecrets(ctx, api.mustDB(), wr.Workflow.ProjectID, secrets); err != nil {
+			return sdk.WrapError(err, "postWorkflowJobHookCallbackHandler> Cannot load secrets from secret backend")
+		}
+
 		tx, err := api.mustDB().Begin()
 		if err != nil {
 			return err
@@ -251,22 +271,6 @@ func (api *API) postWorkflowJobHookCallbackHandler() service.Handler {
 		if errP != nil {
 			return sdk.WrapError(errP, "postWorkflowJobHookCallbackHandler> Cannot load project")
 		}
-		wr, err := workflow.LoadRun(ctx, tx, key, workflowName, number, workflow.LoadRunOptions{
-			DisableDetailledNodeRun: true,
-		})
-		if err != nil {
-			return sdk.WrapError(err, "postWorkflowJobHookCallbackHandler> Cannot load workflow run")
-		}
-
-		pv, err := project.GetAllVariableInProject(tx, wr.Workflow.ProjectID, project.WithClearPassword())
-		if err != nil {
-			return sdk.WrapError(err, "Cannot load project variable")
-		}
-
-		secrets, errSecret := workflow.LoadSecrets(tx, api.Cache, nil, wr, pv)
-		if errSecret != nil {
-			return sdk.WrapError(errSecret, "postWorkflowJobHookCallbackHandler> Cannot load secrets")
-		}
 
 		// Hide secrets in payload
 		for _, s := range secrets {
@@ -326,6 +330,9 @@ func (api *API) getWorkflowJobHookDetailsHandler() service.Handler {
 		if errSecret != nil {
 			return sdk.WrapError(errSecret, "cannot load secrets")
 		}
+		if err := workflow.ResolveBackendSecrets(ctx, db, wr.Workflow.ProjectID, secrets); err != nil {
+			return sdk.WrapError(err, "cannot load secrets from secret backend")
+		}
 		hr.BuildParameters = append(hr.BuildParameters, sdk.VariablesToParameters("", secrets)...)
 		return service.WriteJSON(w, hr, http.StatusOK)
 	}
@@ -4,13 +4,11 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/gorilla/mux"
-	"gopkg.in/yaml.v2"
 
 	"github.com/ovh/cds/engine/api/event"
 	"github.com/ovh/cds/engine/api/observability"
@@ -52,22 +50,25 @@ func (api *API) postWorkflowPreviewHandler() service.Handler {
 			contentType = http.DetectContentType(body)
 		}
 
-		var ew = new(exportentities.Workflow)
-		var errw error
+		var format exportentities.Format
 		switch contentType {
 		case "application/json":
-			errw = json.Unmarshal(body, ew)
+			format = exportentities.FormatJSON
 		case "application/x-yaml", "text/x-yaml":
-			errw = yaml.Unmarshal(body, ew)
+			format = exportentities.FormatYAML
 		default:
 			return sdk.NewError(sdk.ErrWrongRequest, fmt.Errorf("unsupported content-type: %s", contentType))
 		}
 
+		ew, errw := exportentities.UnmarshalWorkflow(body, format)
 		if errw != nil {
-			return sdk.NewError(sdk.ErrWrongRequest, errw)
+			return sdk.NewError(sdk.ErrWrongRequest, sdk.Cause(errw))
+		}
+		if err := ew.CheckNoInlineEntities(); err != nil {
+			return err
 		}
 
-		wf, globalError := workflow.Parse(ctx, proj, ew)
+		wf, globalError := workflow.ParseV2(ctx, proj, ew)
 		if globalError != nil {
 			return sdk.WrapError(globalError, "postWorkflowPreviewHandler> Unable import workflow %s", ew.Name)
 		}
@@ -116,19 +117,22 @@ func (api *API) postWorkflowImportHandler() service.Handler {
 			contentType = http.DetectContentType(body)
 		}
 
-		var ew = new(exportentities.Workflow)
-		var errw error
+		var format exportentities.Format
 		switch contentType {
 		case "application/json":
-			errw = json.Unmarshal(body, ew)
+			format = exportentities.FormatJSON
 		case "application/x-yaml", "text/x-yaml":
-			errw = yaml.Unmarshal(body, ew)
+			format = exportentities.FormatYAML
 		default:
 			return sdk.WrapError(sdk.ErrWrongRequest, "Unsupported content-type: %s", contentType)
 		}
 
+		ew, errw := exportentities.UnmarshalWorkflow(body, format)
 		if errw != nil {
-			return sdk.NewError(sdk.ErrWrongRequest, errw)
+			return sdk.NewError(sdk.ErrWrongRequest, sdk.Cause(errw))
+		}
+		if err := ew.CheckNoInlineEntities(); err != nil {
+			return err
 		}
 
 		tx, errtx := api.mustDB().Begin()
@@ -152,7 +156,7 @@ func (api *API) postWorkflowImportHandler() service.Handler {
 			}
 		}
 
-		wrkflw, msgList, globalError := workflow.ParseAndImport(ctx, tx, api.Cache, proj, wf, ew, getAPIConsumer(ctx), workflow.ImportOptions{Force: force})
+		wrkflw, msgList, globalError := workflow.ParseAndImportV2(ctx, tx, api.Cache, proj, wf, ew, getAPIConsumer(ctx), workflow.ImportOptions{Force: force})
 		msgListString := translate(r, msgList)
 		if globalError != nil {
 			if len(msgListString) != 0 {
@@ -228,19 +232,22 @@ func (api *API) putWorkflowImportHandler() service.Handler {
 			contentType = http.DetectContentType(body)
 		}
 
-		var ew = new(exportentities.Workflow)
-		var errw error
+		var format exportentities.Format
 		switch contentType {
 		case "application/json":
-			errw = json.Unmarshal(body, ew)
+			format = exportentities.FormatJSON
 		case "application/x-yaml", "text/x-yaml":
-			errw = yaml.Unmarshal(body, ew)
+			format = exportentities.FormatYAML
 		default:
 			return sdk.WrapError(sdk.ErrWrongRequest, "Unsupported content-type: %s", contentType)
 		}
 
+		ew, errw := exportentities.UnmarshalWorkflow(body, format)
 		if errw != nil {
-			return sdk.NewError(sdk.ErrWrongRequest, errw)
+			return sdk.NewError(sdk.ErrWrongRequest, sdk.Cause(errw))
+		}
+		if err := ew.CheckNoInlineEntities(); err != nil {
+			return err
 		}
 
 		tx, errtx := api.mustDB().Begin()
@@ -251,7 +258,7 @@ func (api *API) putWorkflowImportHandler() service.Handler {
 			_ = tx.Rollback()
 		}()
 
-		wrkflw, msgList, globalError := workflow.ParseAndImport(ctx, tx, api.Cache, proj, wf, ew, u, workflow.ImportOptions{Force: true, WorkflowName: wfName})
+		wrkflw, msgList, globalError := workflow.ParseAndImportV2(ctx, tx, api.Cache, proj, wf, ew, u, workflow.ImportOptions{Force: true, WorkflowName: wfName})
 		msgListString := translate(r, msgList)
 		if globalError != nil {
 			if len(msgListString) != 0 {
@@ -340,3 +347,48 @@ func (api *API) postWorkflowPushHandler() service.Handler {
 		return service.WriteJSON(w, msgListString, http.StatusOK)
 	}
 }
+
+func (api *API) postWorkflowPlanHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		db := api.mustDB()
+		vars := mux.Vars(r)
+		key := vars[permProjectKey]
+
+		observability.Current(ctx,
+			observability.Tag(observability.TagProjectKey, key),
+		)
+
+		if r.Body == nil {
+			return sdk.ErrWrongRequest
+		}
+
+		btes, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return sdk.NewErrorWithStack(err, sdk.ErrWrongRequest)
+		}
+		defer r.Body.Close()
+
+		tr := tar.NewReader(bytes.NewReader(btes))
+
+		proj, err := project.Load(db, api.Cache, key,
+			project.LoadOptions.WithGroups,
+			project.LoadOptions.WithApplications,
+			project.LoadOptions.WithEnvironments,
+			project.LoadOptions.WithPipelines,
+			project.LoadOptions.WithApplicationWithDeploymentStrategies,
+			project.LoadOptions.WithIntegrations)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		allMsg, diffs, err := workflow.Plan(ctx, db, api.Cache, proj, tr, getAPIConsumer(ctx), project.DecryptWithBuiltinKey, project.EncryptWithBuiltinKey)
+		if err != nil {
+			return err
+		}
+
+		return service.WriteJSON(w, sdk.WorkflowPlan{
+			Messages: translate(r, allMsg),
+			Files:    diffs,
+		}, http.StatusOK)
+	}
+}
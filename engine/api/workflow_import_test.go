@@ -1,8 +1,11 @@
 package api
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"io/ioutil"
 	"net/http/httptest"
 	"strings"
@@ -13,6 +16,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ovh/cds/engine/api/application"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/environment"
 	"github.com/ovh/cds/engine/api/group"
 	"github.com/ovh/cds/engine/api/keys"
 	"github.com/ovh/cds/engine/api/pipeline"
@@ -21,6 +26,7 @@ import (
 	"github.com/ovh/cds/engine/api/test/assets"
 	"github.com/ovh/cds/engine/api/workflow"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
 )
 
 func Test_postWorkflowImportHandler(t *testing.T) {
@@ -925,3 +931,189 @@ metadata:
 	t.Logf("%+v", wUpdated.WorkflowData)
 	assert.Equal(t, 1, len(wUpdated.WorkflowData.Joins))
 }
+
+func Test_postWorkflowPlanHandler(t *testing.T) {
+	api, db, _, end := newTestAPI(t)
+	defer end()
+
+	u, pass := assets.InsertAdminUser(t, db)
+	proj := assets.InsertTestProject(t, db, api.Cache, sdk.RandomString(10), sdk.RandomString(10))
+	pip := sdk.Pipeline{
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		Name:       "pip1",
+	}
+	test.NoError(t, pipeline.InsertPipeline(db, api.Cache, proj, &pip))
+	proj, _ = project.Load(db, api.Cache, proj.Key, project.LoadOptions.WithPipelines)
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: &sdk.WorkflowData{
+			Node: sdk.Node{
+				Name:    "pip1",
+				Type:    sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{PipelineID: pip.ID},
+			},
+		},
+	}
+	test.NoError(t, workflow.Insert(context.TODO(), db, api.Cache, &w, proj))
+
+	// the planned workflow triggers a new pipeline
+	pulled := exportentities.WorkflowPulled{
+		Workflow: exportentities.WorkflowPulledItem{
+			Name: "test_1",
+			Value: base64.StdEncoding.EncodeToString([]byte(`name: test_1
+version: v2.0
+workflow:
+  pip1:
+    pipeline: pip1
+  pip2:
+    depends_on:
+    - pip1
+    pipeline: pip2`)),
+		},
+		Pipelines: []exportentities.WorkflowPulledItem{{
+			Name: "pip2",
+			Value: base64.StdEncoding.EncodeToString([]byte(`version: v1.0
+name: pip2`)),
+		}},
+	}
+	buf := new(bytes.Buffer)
+	require.NoError(t, pulled.Tar(context.TODO(), buf))
+
+	uri := api.Router.GetRoute("POST", api.postWorkflowPlanHandler, map[string]string{
+		"permProjectKey": proj.Key,
+	})
+	test.NotEmpty(t, uri)
+	req := assets.NewAuthentifiedRequest(t, u, pass, "POST", uri, nil)
+	req.Body = ioutil.NopCloser(buf)
+	req.Header.Set("Content-Type", "application/tar")
+	rec := httptest.NewRecorder()
+	api.Router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+
+	var plan sdk.WorkflowPlan
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &plan))
+	files := map[string]string{}
+	for _, f := range plan.Files {
+		files[f.Name] = f.Diff
+	}
+	assert.Contains(t, files["test_1.yml"], "pipeline: pip2")
+	assert.Contains(t, files["pip2.pip.yml"], "+name: pip2")
+
+	// nothing was imported
+	_, err := pipeline.LoadPipeline(context.TODO(), db, proj.Key, "pip2", false)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrPipelineNotFound))
+	wf, err := workflow.Load(context.TODO(), db, api.Cache, proj, "test_1", workflow.LoadOptions{})
+	require.NoError(t, err)
+	assert.Len(t, wf.WorkflowData.Array(), 1)
+}
+
+const workflowWithInlineEntities = `name: test_inline
+version: v2.0
+pipelines:
+  build:
+    stages:
+    - Stage 1
+    jobs:
+    - job: Job 1
+      stage: Stage 1
+      steps:
+      - script:
+        - echo "Hello World!"
+environments:
+  prod:
+    values:
+      region:
+        type: string
+        value: eu
+workflow:
+  build:
+    pipeline: build
+    environment: prod
+`
+
+func Test_postWorkflowPushHandlerWithInlineEntities(t *testing.T) {
+	api, db, _, end := newTestAPI(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	u, pass := assets.InsertAdminUser(t, db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "test_inline.yml",
+		Mode: 0644,
+		Size: int64(len(workflowWithInlineEntities)),
+	}))
+	_, err := tw.Write([]byte(workflowWithInlineEntities))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	uri := api.Router.GetRoute("POST", api.postWorkflowPushHandler, map[string]string{
+		"permProjectKey": proj.Key,
+	})
+	test.NotEmpty(t, uri)
+	req := assets.NewAuthentifiedRequest(t, u, pass, "POST", uri, nil)
+	req.Body = ioutil.NopCloser(buf)
+	req.Header.Set("Content-Type", "application/tar")
+
+	rec := httptest.NewRecorder()
+	api.Router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+
+	// inline pipelines and environments are imported as the ones from their own files
+	pip, err := pipeline.LoadPipeline(context.TODO(), db, proj.Key, "build", true)
+	require.NoError(t, err)
+	require.Len(t, pip.Stages, 1)
+	require.Len(t, pip.Stages[0].Jobs, 1)
+	assert.Equal(t, "Job 1", pip.Stages[0].Jobs[0].Action.Name)
+
+	env, err := environment.LoadEnvironmentByName(db, proj.Key, "prod")
+	require.NoError(t, err)
+	require.Len(t, env.Variable, 1)
+	assert.Equal(t, "region", env.Variable[0].Name)
+	assert.Equal(t, "eu", env.Variable[0].Value)
+
+	wf, err := workflow.Load(context.TODO(), db, api.Cache, proj, "test_inline", workflow.LoadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, pip.ID, wf.WorkflowData.Node.Context.PipelineID)
+	assert.Equal(t, env.ID, wf.WorkflowData.Node.Context.EnvironmentID)
+}
+
+func Test_postWorkflowImportHandlerWithInlineEntities(t *testing.T) {
+	api, db, _, end := newTestAPI(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	u, pass := assets.InsertAdminUser(t, db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+
+	// a workflow file imported alone can't declare inline pipelines and environments
+	vars := map[string]string{
+		"permProjectKey": proj.Key,
+	}
+	for _, uri := range []string{
+		api.Router.GetRoute("POST", api.postWorkflowPreviewHandler, vars),
+		api.Router.GetRoute("POST", api.postWorkflowImportHandler, vars),
+	} {
+		test.NotEmpty(t, uri)
+		req := assets.NewAuthentifiedRequest(t, u, pass, "POST", uri, nil)
+		req.Body = ioutil.NopCloser(strings.NewReader(workflowWithInlineEntities))
+		req.Header.Set("Content-Type", "application/x-yaml")
+
+		rec := httptest.NewRecorder()
+		api.Router.Mux.ServeHTTP(rec, req)
+		assert.Equal(t, 400, rec.Code)
+		assert.Contains(t, rec.Body.String(), "inline pipelines and environments of workflow test_inline are only supported")
+	}
+
+	_, err := pipeline.LoadPipeline(context.TODO(), db, proj.Key, "build", false)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrPipelineNotFound))
+	exists, err := workflow.Exists(db, proj.Key, "test_inline")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
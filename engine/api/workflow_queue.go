@@ -84,6 +84,13 @@ func (api *API) postTakeWorkflowJobHandler() service.Handler {
 }
 
 func takeJob(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, p *sdk.Project, id int64, workerModel string, wnjri *sdk.WorkflowNodeJobRunData, wk *sdk.Worker) (*workflow.ProcessorReport, error) {
+	// Load the secrets before the transaction, fetching the ones stored in the secret backend of the project
+	// should not hold the lock on the job
+	secrets, err := loadJobSecrets(ctx, dbFunc(), store, p, id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Start a tx
 	tx, errBegin := dbFunc().Begin()
 	if errBegin != nil {
@@ -134,17 +141,6 @@ func takeJob(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store,
 		return nil, sdk.WrapError(err, "Unable to load workflow run")
 	}
 
-	//Load the secrets
-	pv, err := project.GetAllVariableInProject(tx, p.ID, project.WithClearPassword())
-	if err != nil {
-		return nil, sdk.WrapError(err, "Cannot load project variable")
-	}
-
-	secrets, errSecret := workflow.LoadSecrets(tx, store, noderun, workflowRun, pv)
-	if errSecret != nil {
-		return nil, sdk.WrapError(errSecret, "Cannot load secrets")
-	}
-
 	//Feed the worker
 	wnjri.NodeJobRun = *job
 	wnjri.Number = noderun.Number
@@ -165,6 +161,40 @@ func takeJob(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store,
 	return report, nil
 }
 
+// loadJobSecrets loads the secrets of a job and fetches the ones stored in the secret backend of the project.
+func loadJobSecrets(ctx context.Context, db gorp.SqlExecutor, store cache.Store, p *sdk.Project, id int64) ([]sdk.Variable, error) {
+	job, err := workflow.LoadNodeJobRun(ctx, db, store, id)
+	if err != nil {
+		return nil, sdk.WrapError(err, "Cannot load job %d", id)
+	}
+
+	noderun, err := workflow.LoadNodeRunByID(db, job.WorkflowNodeRunID, workflow.LoadRunOptions{DisableDetailledNodeRun: true})
+	if err != nil {
+		return nil, sdk.WrapError(err, "Cannot get node run")
+	}
+
+	workflowRun, err := workflow.LoadRunByID(db, noderun.WorkflowRunID, workflow.LoadRunOptions{})
+	if err != nil {
+		return nil, sdk.WrapError(err, "Unable to load workflow run")
+	}
+
+	pv, err := project.GetAllVariableInProject(db, p.ID, project.WithClearPassword())
+	if err != nil {
+		return nil, sdk.WrapError(err, "Cannot load project variable")
+	}
+
+	secrets, err := workflow.LoadSecrets(db, store, noderun, workflowRun, pv)
+	if err != nil {
+		return nil, sdk.WrapError(err, "Cannot load secrets")
+	}
+
+	if err := workflow.ResolveBackendSecrets(ctx, db, p.ID, secrets); err != nil {
+		return nil, sdk.WrapError(err, "Cannot load secrets from secret backend")
+	}
+
+	return secrets, nil
+}
+
 func (api *API) postBookWorkflowJobHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		id, err := requestVarInt(r, "permJobID")
@@ -354,6 +384,12 @@ func (api *API) postWorkflowJobResultHandler() service.Handler {
 			return sdk.WrapError(err, "unable to post job result")
 		}
 
+		sdk.GoRoutine(context.Background(), fmt.Sprintf("workflow.CompleteLogs-%d", id), func(ctx context.Context) {
+			if err := workflow.GetLogStore().Complete(ctx, api.mustDB(), id); err != nil {
+				log.Error(ctx, "unable to complete logs of job %d: %v", id, err)
+			}
+		}, api.PanicDump())
+
 		workflowRuns := report.WorkflowRuns()
 		if len(workflowRuns) > 0 {
 			observability.Current(ctx,
@@ -401,6 +437,13 @@ func postJobResult(ctx context.Context, dbFunc func(context.Context) *gorp.DbMap
 		RemoteTime: res.RemoteTime,
 		Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoWorkerEnd.ID, Args: []interface{}{wr.Name, res.Duration}},
 	}}
+	if res.Status == sdk.StatusTimeout {
+		job.Job.Reason = res.Reason
+		infos = append(infos, sdk.SpawnInfo{
+			RemoteTime: res.RemoteTime,
+			Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobTimeout.ID, Args: []interface{}{res.Reason}},
+		})
+	}
 
 	if err := workflow.AddSpawnInfosNodeJobRun(tx, job.ID, workflow.PrepareSpawnInfos(infos)); err != nil {
 		return nil, sdk.WrapError(err, "Cannot save spawn info job %d", job.ID)
@@ -459,6 +502,46 @@ func postJobResult(ctx context.Context, dbFunc func(context.Context) *gorp.DbMap
 		return nil, sdk.WrapError(err, "Cannot update worker %s status", wr.ID)
 	}
 
+	// If the job can be retried, put it back in the queue instead of updating the stage
+	attempt := sdk.JobRunAttempt{
+		Attempt:    len(job.Attempts) + 1,
+		Status:     res.Status,
+		Reason:     res.Reason,
+		ExitCode:   res.ExitCode,
+		WorkerName: wr.Name,
+		Start:      job.Start,
+		Done:       time.Now(),
+	}
+	policy := job.Job.Action.RetryPolicy
+	if policy.ShouldRetry(attempt.Attempt, *res) {
+		job.Attempts = append(job.Attempts, attempt)
+		delay := policy.Delay(attempt.Attempt)
+		log.Info(ctx, "postJobResult> retrying job %d (attempt %d/%d) in %s", job.ID, attempt.Attempt, policy.MaxAttempts, delay)
+
+		retryInfos := []sdk.SpawnInfo{{
+			RemoteTime: res.RemoteTime,
+			Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobRetry.ID, Args: []interface{}{attempt.Attempt, policy.MaxAttempts, delay.String()}},
+		}}
+		if err := workflow.AddSpawnInfosNodeJobRun(tx, job.ID, workflow.PrepareSpawnInfos(retryInfos)); err != nil {
+			return nil, sdk.WrapError(err, "Cannot save spawn info job %d", job.ID)
+		}
+
+		if err := workflow.RetryWorkflowNodeJob(ctx, tx, job, delay); err != nil {
+			return nil, sdk.WrapError(err, "Cannot retry node job run %d", job.ID)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return nil, sdk.WrapError(err, "Cannot commit tx")
+		}
+
+		// The waiting job is not published during the retry delay, it will be listed in the queue at the end of the delay
+		report := new(workflow.ProcessorReport)
+		if delay == 0 {
+			report.Add(ctx, *job)
+		}
+		return report, nil
+	}
+
 	// Update action status
 	log.Debug("postJobResult> Updating %d to %s in queue", job.ID, res.Status)
 	newDBFunc := func() *gorp.DbMap {
@@ -527,9 +610,13 @@ func (api *API) postWorkflowJobLogsHandler() service.Handler {
 
 		log.Debug("postWorkflowJobLogsHandler> Logs: %+v", logs)
 
-		if err := workflow.AddLog(api.mustDB(), pbJob, &logs, api.Config.Log.StepMaxSize); err != nil {
+		offset, err := workflow.AddLog(ctx, api.mustDB(), pbJob, &logs, api.Config.Log.StepMaxSize)
+		if err != nil {
 			return err
 		}
+		if offset >= 0 {
+			api.publishStepLogs(ctx, logs.JobID, logs.StepOrder, stepLogsMessage{Offset: offset, Val: logs.Val})
+		}
 
 		return nil
 	}
@@ -653,6 +740,10 @@ func (api *API) postWorkflowJobStepStatusHandler() service.Handler {
 			return sdk.WrapError(err, "cannot commit transaction")
 		}
 
+		if sdk.StatusIsTerminated(step.Status) {
+			api.publishStepLogs(ctx, nodeJobRun.ID, int64(step.StepOrder), stepLogsMessage{Done: true})
+		}
+
 		if nodeRun.ID == 0 {
 			nodeRunP, err := workflow.LoadNodeRunByID(api.mustDB(), nodeJobRun.WorkflowNodeRunID, workflow.LoadRunOptions{
 				DisableDetailledNodeRun: true,
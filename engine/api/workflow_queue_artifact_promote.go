@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/integration"
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+func (api *API) postWorkflowJobArtifactPromoteHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if _, isWorker := api.isWorker(ctx); !isWorker {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		jobID, err := requestVarInt(r, "permJobID")
+		if err != nil {
+			return err
+		}
+
+		var req sdk.ArtifactPromoteRequest
+		if err := service.UnmarshalBody(r, &req); err != nil {
+			return sdk.WrapError(err, "cannot read body")
+		}
+		if err := req.IsValid(); err != nil {
+			return err
+		}
+		var pattern *regexp.Regexp
+		if req.Pattern != "" {
+			pattern, err = regexp.Compile(req.Pattern)
+			if err != nil {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pattern %s: %v", req.Pattern, err)
+			}
+		}
+
+		db := api.mustDB()
+
+		nodeJobRun, err := workflow.LoadNodeJobRun(ctx, db, api.Cache, jobID)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load node job run")
+		}
+		nodeRun, err := workflow.LoadNodeRunByID(db, nodeJobRun.WorkflowNodeRunID, workflow.LoadRunOptions{DisableDetailledNodeRun: true})
+		if err != nil {
+			return sdk.WrapError(err, "cannot load node run")
+		}
+		wr, err := workflow.LoadRunByID(db, nodeRun.WorkflowRunID, workflow.LoadRunOptions{DisableDetailledNodeRun: true})
+		if err != nil {
+			return sdk.WrapError(err, "cannot load workflow run")
+		}
+
+		// The groups that can execute the job should be able to read the source workflow and to execute the current one
+		groupIDs := nodeJobRun.ExecGroups.ToIDs()
+		if err := api.checkArtifactPromotePermission(ctx, req.ProjectKey, req.WorkflowName, groupIDs, sdk.PermissionRead); err != nil {
+			return err
+		}
+		if err := api.checkArtifactPromotePermission(ctx, wr.Workflow.ProjectKey, wr.Workflow.Name, groupIDs, sdk.PermissionReadExecute); err != nil {
+			return err
+		}
+
+		var srcRun *sdk.WorkflowRun
+		if req.Number > 0 {
+			srcRun, err = workflow.LoadRun(ctx, db, req.ProjectKey, req.WorkflowName, req.Number, workflow.LoadRunOptions{WithArtifacts: true})
+		} else {
+			srcRun, err = workflow.LoadLastSuccessfulRun(db, req.ProjectKey, req.WorkflowName, req.Branch, workflow.LoadRunOptions{WithArtifacts: true})
+		}
+		if err != nil {
+			return sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrNotFound, "cannot find a run of workflow %s/%s to promote", req.ProjectKey, req.WorkflowName))
+		}
+		if srcRun.ID == wr.ID {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "artifacts can't be promoted from the current run")
+		}
+
+		dstDriver, err := objectstore.GetDriver(ctx, db, api.SharedStorage, wr.Workflow.ProjectKey, sdk.DefaultIfEmptyStorage(req.IntegrationName))
+		if err != nil {
+			return err
+		}
+
+		// All the artifacts are promoted or none
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		srcDrivers := map[int64]objectstore.Driver{}
+		promoted := []sdk.WorkflowNodeRunArtifact{}
+
+		// The copies that are not stored by content are only referenced by their artifact, so they are
+		// removed from the storage if the promotion fails
+		var committed bool
+		defer func() {
+			if committed {
+				return
+			}
+			for i := range promoted {
+				if promoted[i].BlobSHA256 != "" {
+					continue
+				}
+				if err := dstDriver.Delete(ctx, &promoted[i]); err != nil {
+					log.Error(ctx, "unable to delete promoted artifact %s: %v", promoted[i].Name, err)
+				}
+			}
+		}()
+		for _, runs := range srcRun.WorkflowNodeRuns {
+			if len(runs) == 0 {
+				continue
+			}
+			sort.Slice(runs, func(i, j int) bool {
+				return runs[i].SubNumber > runs[j].SubNumber
+			})
+
+			for _, src := range runs[0].Artifacts {
+				if pattern != nil && !pattern.MatchString(src.Name) {
+					continue
+				}
+				if req.Tag != "" && src.Tag != req.Tag {
+					continue
+				}
+
+				var srcIntegrationID int64
+				if src.ProjectIntegrationID != nil {
+					srcIntegrationID = *src.ProjectIntegrationID
+				}
+				srcDriver, ok := srcDrivers[srcIntegrationID]
+				if !ok {
+					srcDriver, err = api.getArtifactStorageDriver(ctx, req.ProjectKey, src)
+					if err != nil {
+						return err
+					}
+					srcDrivers[srcIntegrationID] = srcDriver
+				}
+
+				dst, err := api.promoteArtifact(ctx, tx, req, srcDriver, dstDriver, *srcRun, runs[0], src, *nodeRun)
+				if err != nil {
+					return err
+				}
+				log.Info(ctx, "artifact %s promoted from %s/%s #%d to %s/%s #%d", src.Name, req.ProjectKey, req.WorkflowName, srcRun.Number,
+					wr.Workflow.ProjectKey, wr.Workflow.Name, wr.Number)
+				promoted = append(promoted, *dst)
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+		committed = true
+
+		return service.WriteJSON(w, promoted, http.StatusOK)
+	}
+}
+
+func (api *API) checkArtifactPromotePermission(ctx context.Context, projectKey, workflowName string, groupIDs []int64, perm int) error {
+	perms, err := permission.LoadWorkflowMaxLevelPermission(ctx, api.mustDB(), projectKey, []string{workflowName}, groupIDs)
+	if err != nil {
+		return err
+	}
+	if perms.Level(workflowName) < perm {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "not authorized to promote artifacts with workflow %s/%s", projectKey, workflowName)
+	}
+	return nil
+}
+
+// getArtifactStorageDriver returns the driver of the storage of given artifact.
+func (api *API) getArtifactStorageDriver(ctx context.Context, projectKey string, art sdk.WorkflowNodeRunArtifact) (objectstore.Driver, error) {
+	integrationName := sdk.DefaultStorageIntegrationName
+	if art.ProjectIntegrationID != nil && *art.ProjectIntegrationID > 0 {
+		projectIntegration, err := integration.LoadProjectIntegrationByID(api.mustDB(), *art.ProjectIntegrationID, false)
+		if err != nil {
+			return nil, sdk.WrapError(err, "cannot load project integration %s/%d", projectKey, *art.ProjectIntegrationID)
+		}
+		integrationName = projectIntegration.Name
+	}
+	return objectstore.GetDriver(ctx, api.mustDB(), api.SharedStorage, projectKey, integrationName)
+}
+
+func (api *API) promoteArtifact(ctx context.Context, db gorp.SqlExecutor, req sdk.ArtifactPromoteRequest, srcDriver, dstDriver objectstore.Driver,
+	srcRun sdk.WorkflowRun, srcNodeRun sdk.WorkflowNodeRun, src sdk.WorkflowNodeRunArtifact, nodeRun sdk.WorkflowNodeRun) (*sdk.WorkflowNodeRunArtifact, error) {
+	hash, err := sdk.GenerateHash()
+	if err != nil {
+		return nil, sdk.WrapError(err, "could not generate hash")
+	}
+
+	tag := req.DestinationTag
+	if tag == "" {
+		tag = src.Tag
+	}
+	provenance := workflow.NewArtifactProvenance(req.ProjectKey, req.WorkflowName, srcRun.Number, srcNodeRun, src)
+	dst := sdk.WorkflowNodeRunArtifact{
+		Name:              src.Name,
+		Tag:               tag,
+		Ref:               base64.RawURLEncoding.EncodeToString([]byte(tag)),
+		DownloadHash:      hash,
+		WorkflowNodeRunID: nodeRun.ID,
+		WorkflowID:        nodeRun.WorkflowRunID,
+		Created:           time.Now(),
+		Provenance:        &provenance,
+	}
+
+	link := req.Mode != sdk.ArtifactPromoteModeCopy
+	if err := workflow.PromoteArtifact(ctx, db, srcDriver, dstDriver, src, &dst, link, api.Config.Artifact.ContentAddressed); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
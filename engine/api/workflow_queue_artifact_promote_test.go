@@ -0,0 +1,147 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_postWorkflowJobArtifactPromoteHandler(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+
+	storage, err := objectstore.Init(context.Background(), objectstore.Config{
+		Kind: objectstore.Filesystem,
+		Options: objectstore.ConfigOptions{
+			Filesystem: objectstore.ConfigOptionsFilesystem{
+				Basedir: path.Join(os.TempDir(), "store"),
+			},
+		},
+	})
+	require.NoError(t, err)
+	api.SharedStorage = storage
+
+	// The artifacts are promoted from a run of another project to the job of the current run
+	src := testRunWorkflow(t, api, router)
+	ctx := testRunWorkflow(t, api, router)
+	testGetWorkflowJobAsWorker(t, api, router, &ctx)
+	require.NotNil(t, ctx.job)
+
+	uri := router.GetRoute("POST", api.postTakeWorkflowJobHandler, map[string]string{
+		"key":              ctx.project.Key,
+		"permWorkflowName": ctx.workflow.Name,
+		"id":               fmt.Sprintf("%d", ctx.job.ID),
+	})
+	req := assets.NewJWTAuthentifiedRequest(t, ctx.workerToken, "POST", uri, nil)
+	rec := httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+
+	var srcNodeRun sdk.WorkflowNodeRun
+	for _, runs := range src.run.WorkflowNodeRuns {
+		srcNodeRun = runs[0]
+	}
+	insertArtifact := func(name string, content []byte) {
+		art := sdk.WorkflowNodeRunArtifact{
+			Name:              name,
+			Tag:               "latest",
+			Ref:               base64.RawURLEncoding.EncodeToString([]byte("latest")),
+			DownloadHash:      sdk.RandomString(10),
+			WorkflowNodeRunID: srcNodeRun.ID,
+			WorkflowID:        src.run.ID,
+			Created:           time.Now(),
+			Size:              int64(len(content)),
+		}
+		if content != nil {
+			_, err := api.SharedStorage.Store(&art, ioutil.NopCloser(bytes.NewReader(content)))
+			require.NoError(t, err)
+		}
+		require.NoError(t, workflow.InsertArtifact(db, &art))
+	}
+	insertArtifact("artifact-1", []byte("Hi, I am foo"))
+	// The content of this artifact is missing in the storage so it can't be promoted
+	insertArtifact("artifact-2", nil)
+
+	promote := func(jwt string, promoteReq sdk.ArtifactPromoteRequest) *httptest.ResponseRecorder {
+		uri := router.GetRoute("POST", api.postWorkflowJobArtifactPromoteHandler, map[string]string{
+			"permJobID": fmt.Sprintf("%d", ctx.job.ID),
+		})
+		req := assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, promoteReq)
+		rec := httptest.NewRecorder()
+		router.Mux.ServeHTTP(rec, req)
+		return rec
+	}
+	loadArtifacts := func() []sdk.WorkflowNodeRunArtifact {
+		nodeRun, err := workflow.LoadNodeRunByID(db, ctx.job.WorkflowNodeRunID, workflow.LoadRunOptions{WithArtifacts: true})
+		require.NoError(t, err)
+		return nodeRun.Artifacts
+	}
+	promoteReq := sdk.ArtifactPromoteRequest{
+		ProjectKey:   src.project.Key,
+		WorkflowName: src.workflow.Name,
+		Number:       src.run.Number,
+		Mode:         sdk.ArtifactPromoteModeCopy,
+	}
+
+	// Only a worker can promote artifacts
+	_, jwt := assets.InsertLambdaUser(t, db)
+	assert.Equal(t, 403, promote(jwt, promoteReq).Code)
+
+	// The groups of the job can't read the source workflow
+	assert.Equal(t, 403, promote(ctx.workerToken, promoteReq).Code)
+	assert.Len(t, loadArtifacts(), 0)
+
+	for _, g := range ctx.job.ExecGroups {
+		require.NoError(t, group.InsertLinkGroupProject(db, &group.LinkGroupProject{
+			GroupID:   g.ID,
+			ProjectID: src.project.ID,
+			Role:      sdk.PermissionRead,
+		}))
+		require.NoError(t, group.AddWorkflowGroup(context.TODO(), db, src.workflow, sdk.GroupPermission{
+			Group:      g,
+			Permission: sdk.PermissionRead,
+		}))
+	}
+
+	// A failure doesn't leave a partial promotion, the copied content is removed from the storage
+	assert.Equal(t, 500, promote(ctx.workerToken, promoteReq).Code)
+	assert.Len(t, loadArtifacts(), 0)
+	_, err = api.SharedStorage.Fetch(context.TODO(), &sdk.WorkflowNodeRunArtifact{
+		Name:              "artifact-1",
+		Ref:               base64.RawURLEncoding.EncodeToString([]byte("latest")),
+		WorkflowNodeRunID: ctx.job.WorkflowNodeRunID,
+		WorkflowID:        ctx.run.ID,
+	})
+	assert.Error(t, err)
+
+	promoteReq.Pattern = "^artifact-1$"
+	rec = promote(ctx.workerToken, promoteReq)
+	require.Equal(t, 200, rec.Code)
+	var promoted []sdk.WorkflowNodeRunArtifact
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &promoted))
+	require.Len(t, promoted, 1)
+	assert.Equal(t, "artifact-1", promoted[0].Name)
+
+	arts := loadArtifacts()
+	require.Len(t, arts, 1)
+	require.NotNil(t, arts[0].Provenance)
+	assert.Equal(t, src.project.Key, arts[0].Provenance.ProjectKey)
+	assert.Equal(t, src.run.Number, arts[0].Provenance.RunNumber)
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/base64"
+	"io"
 	"mime"
 	"net/http"
 	"strconv"
@@ -225,6 +226,14 @@ func (api *API) postWorkflowJobArtifactHandler() service.Handler {
 		}
 
 		files := m.File[fileName]
+		if len(files) == 1 && api.Config.Artifact.ContentAddressed {
+			file, err := files[0].Open()
+			if err != nil {
+				return sdk.WrapError(err, "cannot open file")
+			}
+			defer file.Close() // nolint
+			return api.storeWorkflowJobArtifactBlob(ctx, storageDriver, &art, file)
+		}
 		if len(files) == 1 {
 			file, err := files[0].Open()
 			if err != nil {
@@ -251,6 +260,35 @@ func (api *API) postWorkflowJobArtifactHandler() service.Handler {
 	}
 }
 
+// storeWorkflowJobArtifactBlob stores an artifact by content, only a reference is added if the same content was already stored.
+func (api *API) storeWorkflowJobArtifactBlob(ctx context.Context, storageDriver objectstore.Driver, art *sdk.WorkflowNodeRunArtifact, file io.ReadSeeker) error {
+	tx, err := api.mustDB().Begin()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer tx.Rollback() // nolint
+
+	uploaded, err := workflow.StoreArtifactBlob(ctx, tx, storageDriver, art, file)
+	if err != nil {
+		return sdk.WrapError(err, "cannot store artifact")
+	}
+
+	if err := workflow.InsertArtifact(tx, art); err != nil {
+		if uploaded {
+			_ = storageDriver.Delete(ctx, objectstore.ArtifactObject(art))
+		}
+		return sdk.WrapError(err, "cannot insert artifact")
+	}
+
+	if err := tx.Commit(); err != nil {
+		if uploaded {
+			_ = storageDriver.Delete(ctx, objectstore.ArtifactObject(art))
+		}
+		return sdk.WithStack(err)
+	}
+	return nil
+}
+
 func (api *API) postWorkflowJobArtifactWithTempURLCallbackHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		if _, isWorker := api.isWorker(ctx); !isWorker {
@@ -321,7 +359,7 @@ func (api *API) postWorkflowJobArtifacWithTempURLHandler() service.Handler {
 			return err
 		}
 
-		if !storageDriver.TemporaryURLSupported() {
+		if !storageDriver.TemporaryURLSupported() || api.Config.Artifact.ContentAddressed {
 			return sdk.WithStack(sdk.ErrForbidden)
 		}
 
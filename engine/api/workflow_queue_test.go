@@ -378,6 +378,53 @@ func Test_postTakeWorkflowJobHandler(t *testing.T) {
 	require.Equal(t, "Building", run.Status)
 }
 
+func Test_postTakeWorkflowJobHandlerDuringRetryDelay(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+	ctx := testRunWorkflow(t, api, router)
+	testGetWorkflowJobAsWorker(t, api, router, &ctx)
+	assert.NotNil(t, ctx.job)
+
+	//Register the worker
+	testRegisterWorker(t, api, router, &ctx)
+
+	// Put the job back in the queue as it would be after a failed attempt
+	job, err := workflow.LoadNodeJobRun(context.TODO(), db, api.Cache, ctx.job.ID)
+	require.NoError(t, err)
+	require.NoError(t, workflow.RetryWorkflowNodeJob(context.TODO(), db, job, time.Hour))
+
+	uri := router.GetRoute("POST", api.postTakeWorkflowJobHandler, map[string]string{
+		"key":              ctx.project.Key,
+		"permWorkflowName": ctx.workflow.Name,
+		"id":               fmt.Sprintf("%d", ctx.job.ID),
+	})
+	test.NotEmpty(t, uri)
+
+	// The job can't be taken during the retry delay
+	req := assets.NewJWTAuthentifiedRequest(t, ctx.workerToken, "POST", uri, nil)
+	rec := httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 403, rec.Code)
+
+	run, err := workflow.LoadNodeJobRun(context.TODO(), db, api.Cache, ctx.job.ID)
+	require.NoError(t, err)
+	require.Equal(t, sdk.StatusWaiting, run.Status)
+
+	// The job is not in the queue during the retry delay
+	queue, err := workflow.LoadNodeJobRunQueue(context.TODO(), db, api.Cache, workflow.NewQueueFilter())
+	require.NoError(t, err)
+	for _, j := range queue {
+		require.NotEqual(t, ctx.job.ID, j.ID)
+	}
+
+	// The job can be taken at the end of the delay
+	require.NoError(t, workflow.RetryWorkflowNodeJob(context.TODO(), db, run, 0))
+	req = assets.NewJWTAuthentifiedRequest(t, ctx.workerToken, "POST", uri, nil)
+	rec = httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+}
+
 func Test_postBookWorkflowJobHandler(t *testing.T) {
 	api, _, router, end := newTestAPI(t)
 	defer end()
@@ -737,6 +784,78 @@ func Test_postWorkflowJobArtifactHandler(t *testing.T) {
 
 }
 
+func Test_purgeDeleteArtifactsWithSharedBlob(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+
+	basedir, err := ioutil.TempDir("", "store")
+	require.NoError(t, err)
+	defer os.RemoveAll(basedir) // nolint
+	storage, err := objectstore.Init(context.Background(), objectstore.Config{
+		Kind: objectstore.Filesystem,
+		Options: objectstore.ConfigOptions{
+			Filesystem: objectstore.ConfigOptionsFilesystem{Basedir: basedir},
+		},
+	})
+	require.NoError(t, err)
+	api.SharedStorage = storage
+
+	// Two runs store an artifact with the same content in the same blob
+	content := []byte(sdk.RandomString(100))
+	var blobSHA256 string
+	var integrationID int64
+	runs := []testRunWorkflowCtx{testRunWorkflow(t, api, router), testRunWorkflow(t, api, router)}
+	for _, r := range runs {
+		var nodeRun sdk.WorkflowNodeRun
+		for _, nrs := range r.run.WorkflowNodeRuns {
+			nodeRun = nrs[0]
+		}
+		storageDriver, err := objectstore.GetDriver(context.TODO(), db, api.SharedStorage, r.project.Key, sdk.DefaultStorageIntegrationName)
+		require.NoError(t, err)
+		integrationID = storageDriver.GetProjectIntegration().ID
+
+		art := &sdk.WorkflowNodeRunArtifact{
+			Name:              "myartifact",
+			Tag:               "latest",
+			Ref:               base64.RawURLEncoding.EncodeToString([]byte("latest")),
+			DownloadHash:      sdk.RandomString(10),
+			WorkflowNodeRunID: nodeRun.ID,
+			WorkflowID:        r.run.ID,
+			Created:           time.Now(),
+			Size:              int64(len(content)),
+		}
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		_, err = workflow.StoreArtifactBlob(context.TODO(), tx, storageDriver, art, bytes.NewReader(content))
+		require.NoError(t, err)
+		require.NoError(t, workflow.InsertArtifact(tx, art))
+		require.NoError(t, tx.Commit())
+		blobSHA256 = art.BlobSHA256
+	}
+	blobPath := path.Join(basedir, "blobs-"+blobSHA256[:2], blobSHA256)
+
+	refCount := func() int64 {
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		defer tx.Rollback() // nolint
+		blob, err := workflow.LockArtifactBlobForDelete(tx, blobSHA256, integrationID)
+		require.NoError(t, err)
+		return blob.RefCount
+	}
+	require.Equal(t, int64(2), refCount())
+
+	// Purging the artifacts of the first run twice, as if the deletion of the run failed after it, removes only one reference
+	require.NoError(t, purge.DeleteArtifacts(context.TODO(), db, api.Cache, api.SharedStorage, runs[0].run.ID))
+	require.NoError(t, purge.DeleteArtifacts(context.TODO(), db, api.Cache, api.SharedStorage, runs[0].run.ID))
+	assert.Equal(t, int64(1), refCount())
+	assert.True(t, fileExists(blobPath))
+
+	// The blob is deleted with its last reference
+	require.NoError(t, purge.DeleteArtifacts(context.TODO(), db, api.Cache, api.SharedStorage, runs[1].run.ID))
+	_, err = os.Stat(blobPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
 func fileExists(filename string) bool {
 	info, err := os.Stat(filename)
 	if os.IsNotExist(err) {
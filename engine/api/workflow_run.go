@@ -20,6 +20,7 @@ import (
 	"github.com/ovh/cds/engine/api/objectstore"
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/purge"
 	"github.com/ovh/cds/engine/api/workflow"
 	"github.com/ovh/cds/engine/service"
 	"github.com/ovh/cds/sdk"
@@ -169,6 +170,32 @@ func (api *API) deleteWorkflowRunsBranchHandler() service.Handler {
 		name := vars["permWorkflowName"]
 		branch := vars["branch"]
 
+		proj, err := project.Load(api.mustDB(), api.Cache, key)
+		if err != nil {
+			return err
+		}
+
+		wf, err := workflow.Load(ctx, api.mustDB(), api.Cache, proj, name, workflow.LoadOptions{Minimal: true})
+		if err != nil {
+			return err
+		}
+
+		// The deleted branch is kept for the retention policy, its runs are deleted by the purge after the TTL
+		if wf.RetentionPolicy != nil && !wf.RetentionPolicy.IsEmpty() {
+			if err := workflow.InsertDeletedBranch(api.mustDB(), wf.ID, branch); err != nil {
+				return err
+			}
+			if wf.RetentionPolicy.DeletedBranchesTTL > 0 {
+				log.Info(ctx, "deleteWorkflowRunsBranchHandler> runs of branch %s of workflow %d will be deleted in %ds", branch, wf.ID, wf.RetentionPolicy.DeletedBranchesTTL)
+				return service.WriteJSON(w, nil, http.StatusAccepted)
+			}
+			// Without TTL, the runs of the branch are deleted now except the ones kept by the policy
+			if _, err := purge.ApplyRetentionPolicy(ctx, api.mustDB(), wf.ID, *wf.RetentionPolicy, wf.HistoryLength, false); err != nil {
+				return err
+			}
+			return service.WriteJSON(w, nil, http.StatusOK)
+		}
+
 		wfIDs, err := workflow.LoadRunsIDByTag(api.mustDB(), key, name, "git.branch", branch)
 		if err != nil {
 			return err
@@ -182,6 +209,47 @@ func (api *API) deleteWorkflowRunsBranchHandler() service.Handler {
 	}
 }
 
+// getWorkflowPurgeHandler returns the workflow runs that would be deleted by the retention policy of the workflow
+func (api *API) getWorkflowPurgeHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return api.purgeWorkflowRuns(ctx, w, r, true)
+	}
+}
+
+// postWorkflowPurgeHandler marks to delete the workflow runs that are not kept by the retention policy of the workflow
+func (api *API) postWorkflowPurgeHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		return api.purgeWorkflowRuns(ctx, w, r, false)
+	}
+}
+
+func (api *API) purgeWorkflowRuns(ctx context.Context, w http.ResponseWriter, r *http.Request, dryRun bool) error {
+	vars := mux.Vars(r)
+	key := vars["key"]
+	name := vars["permWorkflowName"]
+
+	proj, err := project.Load(api.mustDB(), api.Cache, key)
+	if err != nil {
+		return err
+	}
+
+	wf, err := workflow.Load(ctx, api.mustDB(), api.Cache, proj, name, workflow.LoadOptions{Minimal: true})
+	if err != nil {
+		return err
+	}
+
+	if wf.RetentionPolicy == nil || wf.RetentionPolicy.IsEmpty() {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "workflow %s has no retention policy", wf.Name)
+	}
+
+	runs, err := purge.ApplyRetentionPolicy(ctx, api.mustDB(), wf.ID, *wf.RetentionPolicy, wf.HistoryLength, dryRun)
+	if err != nil {
+		return err
+	}
+
+	return service.WriteJSON(w, runs, http.StatusOK)
+}
+
 // getWorkflowRunNumHandler returns the last run number for the given workflow
 func (api *API) getWorkflowRunNumHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
@@ -713,7 +781,12 @@ func (api *API) stopWorkflowNodeRunHandler() service.Handler {
 			return sdk.WrapError(err, "Unable to load last workflow run")
 		}
 
-		report, err := api.stopWorkflowNodeRun(ctx, api.mustDB, api.Cache, p, nodeRun, name, getAPIConsumer(ctx))
+		stopInfos := sdk.SpawnInfo{
+			APITime:    time.Now(),
+			RemoteTime: time.Now(),
+			Message:    sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeStop.ID, Args: []interface{}{getAPIConsumer(ctx).GetUsername()}},
+		}
+		report, err := api.stopWorkflowNodeRun(ctx, api.mustDB, api.Cache, p, nodeRun, name, stopInfos)
 		if err != nil {
 			return sdk.WrapError(err, "Unable to stop workflow run")
 		}
@@ -725,18 +798,13 @@ func (api *API) stopWorkflowNodeRunHandler() service.Handler {
 }
 
 func (api *API) stopWorkflowNodeRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store,
-	p *sdk.Project, nodeRun *sdk.WorkflowNodeRun, workflowName string, ident sdk.Identifiable) (*workflow.ProcessorReport, error) {
+	p *sdk.Project, nodeRun *sdk.WorkflowNodeRun, workflowName string, stopInfos sdk.SpawnInfo) (*workflow.ProcessorReport, error) {
 	tx, errTx := dbFunc().Begin()
 	if errTx != nil {
 		return nil, sdk.WrapError(errTx, "unable to create transaction")
 	}
 	defer tx.Rollback() // nolint
 
-	stopInfos := sdk.SpawnInfo{
-		APITime:    time.Now(),
-		RemoteTime: time.Now(),
-		Message:    sdk.SpawnMsg{ID: sdk.MsgWorkflowNodeStop.ID, Args: []interface{}{ident.GetUsername()}},
-	}
 	report, errS := workflow.StopWorkflowNodeRun(ctx, dbFunc, store, p, *nodeRun, stopInfos)
 	if errS != nil {
 		return nil, sdk.WrapError(errS, "unable to stop workflow node run")
@@ -870,7 +938,7 @@ func (api *API) postWorkflowRunHandler() service.Handler {
 			var errc error
 			var conditionsOK bool
 			if conditions.LuaScript == "" {
-				conditionsOK, errc = sdk.WorkflowCheckConditions(conditions.PlainConditions, params)
+				conditionsOK, errc = sdk.WorkflowCheckNodeConditions(conditions, params)
 			} else {
 				luacheck, err := luascript.NewCheck()
 				if err != nil {
@@ -1026,6 +1094,14 @@ func (api *API) initWorkflowRun(ctx context.Context, db *gorp.DbMap, cache cache
 		return
 	}
 
+	// The run was queued by the workflow concurrency, it will be started later
+	if wfRun.Status == sdk.StatusPending {
+		return
+	}
+	if ids := r1.SupersededRuns(); len(ids) > 0 {
+		report.Merge(ctx, api.cancelSupersededWorkflowRuns(ctx, p, wfRun, ids, u), nil) // nolint
+	}
+
 	workflow.ResyncNodeRunsWithCommits(ctx, db, cache, p, report)
 
 	// Purge workflow run
@@ -1077,7 +1153,7 @@ func (api *API) downloadworkflowArtifactDirectHandler() service.Handler {
 		w.Header().Add("Content-Type", "application/octet-stream")
 		w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", art.Name))
 
-		f, err := api.SharedStorage.Fetch(ctx, art)
+		f, err := api.SharedStorage.Fetch(ctx, objectstore.ArtifactObject(art))
 		if err != nil {
 			return sdk.WrapError(err, "Cannot fetch artifact")
 		}
@@ -1140,7 +1216,7 @@ func (api *API) getDownloadArtifactHandler() service.Handler {
 			return err
 		}
 
-		f, err := storageDriver.Fetch(ctx, art)
+		f, err := storageDriver.Fetch(ctx, objectstore.ArtifactObject(art))
 		if err != nil {
 			_ = f.Close()
 			return sdk.WrapError(err, "Cannot fetch artifact")
@@ -1210,7 +1286,7 @@ func (api *API) getWorkflowRunArtifactsHandler() service.Handler {
 
 					s, temporaryURLSupported := storageDriver.(objectstore.DriverWithRedirect)
 					if temporaryURLSupported { // with temp URL
-						fURL, _, err := s.FetchURL(art)
+						fURL, _, err := s.FetchURL(objectstore.ArtifactObject(art))
 						if err != nil {
 							log.Error(ctx, "Cannot fetch cache object: %v", err)
 						} else if fURL != "" {
@@ -1287,38 +1363,17 @@ func (api *API) getWorkflowNodeRunJobStepHandler() service.Handler {
 		if errS != nil {
 			return sdk.WrapError(errS, "stepOrder: invalid number")
 		}
-
-		// Check nodeRunID is link to workflow
-		nodeRun, errNR := workflow.LoadNodeRun(api.mustDB(), projectKey, workflowName, number, nodeRunID, workflow.LoadRunOptions{DisableDetailledNodeRun: true})
-		if errNR != nil {
-			return sdk.WrapError(errNR, "cannot find nodeRun %d/%d for workflow %s in project %s", nodeRunID, number, workflowName, projectKey)
-		}
-
-		var stepStatus string
-		// Find job/step in nodeRun
-	stageLoop:
-		for _, s := range nodeRun.Stages {
-			for _, rj := range s.RunJobs {
-				if rj.ID != runJobID {
-					continue
-				}
-				ss := rj.Job.StepStatus
-				for _, sss := range ss {
-					if int64(sss.StepOrder) == stepOrder {
-						stepStatus = sss.Status
-						break
-					}
-				}
-				break stageLoop
-			}
+		offset, err := requestLogOffset(r)
+		if err != nil {
+			return err
 		}
 
-		if stepStatus == "" {
-			return sdk.WrapError(sdk.ErrStepNotFound, "cannot find step %d on job %d in nodeRun %d/%d for workflow %s in project %s",
-				stepOrder, runJobID, nodeRunID, number, workflowName, projectKey)
+		stepStatus, err := api.getWorkflowNodeRunJobStepStatus(projectKey, workflowName, number, nodeRunID, runJobID, stepOrder)
+		if err != nil {
+			return err
 		}
 
-		logs, errL := workflow.LoadStepLogs(api.mustDB(), runJobID, stepOrder)
+		logs, errL := workflow.GetLogStore().Load(ctx, api.mustDB(), runJobID, stepOrder, offset)
 		if errL != nil {
 			return sdk.WrapError(errL, "cannot load log for runJob %d on step %d", runJobID, stepOrder)
 		}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/event"
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) postWorkflowNodeRunApprovalHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+		number, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+		nodeRunID, err := requestVarInt(r, "nodeRunID")
+		if err != nil {
+			return err
+		}
+
+		var req sdk.WorkflowNodeRunApprovalRequest
+		if err := service.UnmarshalBody(r, &req); err != nil {
+			return err
+		}
+
+		consumer := getAPIConsumer(ctx)
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		_, next := observability.Span(ctx, "project.Load")
+		proj, err := project.Load(tx, api.Cache, key,
+			project.LoadOptions.WithVariables,
+			project.LoadOptions.WithFeatures,
+			project.LoadOptions.WithIntegrations,
+			project.LoadOptions.WithApplicationVariables,
+			project.LoadOptions.WithApplicationWithDeploymentStrategies,
+		)
+		next()
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project %s", key)
+		}
+
+		wr, err := workflow.LoadRun(ctx, tx, key, name, number, workflow.LoadRunOptions{})
+		if err != nil {
+			return sdk.WrapError(err, "cannot load workflow run %s/%s#%d", key, name, number)
+		}
+
+		nodeRun, err := workflow.LoadNodeRun(tx, key, name, number, nodeRunID, workflow.LoadRunOptions{})
+		if err != nil {
+			return sdk.WrapError(err, "cannot load node run %d", nodeRunID)
+		}
+
+		// Lock the node run to not lose an approval or resume the workflow twice when approvals are concurrent
+		nodeRun, err = workflow.LoadAndLockNodeRunByID(ctx, tx, nodeRun.ID)
+		if err != nil {
+			return sdk.WrapError(err, "cannot lock node run %d", nodeRunID)
+		}
+
+		// Only members of the approver groups can approve the node run
+		node := wr.Workflow.WorkflowData.NodeByID(nodeRun.WorkflowNodeID)
+		if node != nil && node.ApprovalContext != nil && len(node.ApprovalContext.Groups) > 0 && !isAdmin(ctx) {
+			var isApprover bool
+			for _, groupName := range node.ApprovalContext.Groups {
+				g, err := group.LoadByName(ctx, tx, groupName)
+				if err != nil {
+					if sdk.ErrorIs(err, sdk.ErrNotFound) {
+						continue
+					}
+					return err
+				}
+				if isGroupMember(ctx, g) {
+					isApprover = true
+					break
+				}
+			}
+			if !isApprover {
+				return sdk.NewErrorFrom(sdk.ErrForbidden, "you are not a member of the approver groups of node %s", nodeRun.WorkflowNodeName)
+			}
+		}
+
+		approval := sdk.WorkflowNodeRunApproval{
+			UserID:   consumer.AuthentifiedUserID,
+			Username: consumer.GetUsername(),
+			Comment:  req.Comment,
+			Date:     time.Now(),
+		}
+		report, err := workflow.ApproveNodeRun(ctx, tx, api.Cache, proj, wr, nodeRun, approval)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+
+		event.PublishWorkflowNodeRunApproval(ctx, key, wr.Workflow, *nodeRun, approval, node.ApprovalContext.RequiredApprovals(), consumer)
+		go workflow.SendEvent(context.Background(), api.mustDB(), key, report)
+
+		return service.WriteJSON(w, nodeRun, http.StatusOK)
+	}
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+func queuedWorkflowRunLockKey(id int64) string {
+	return cache.Key("api", "workflow", "run", "queued", "lock", strconv.FormatInt(id, 10))
+}
+
+// cancelSupersededWorkflowRuns stops the runs superseded by a new run according to the concurrency of the workflow.
+func (api *API) cancelSupersededWorkflowRuns(ctx context.Context, p *sdk.Project, wfRun *sdk.WorkflowRun, ids []int64, u *sdk.AuthConsumer) *workflow.ProcessorReport {
+	report := new(workflow.ProcessorReport)
+	for _, id := range ids {
+		run, err := workflow.LoadRunByID(api.mustDB(), id, workflow.LoadRunOptions{})
+		if err != nil {
+			log.Error(ctx, "cancelSupersededWorkflowRuns> unable to load workflow run %d: %v", id, err)
+			continue
+		}
+		if sdk.StatusIsTerminated(run.Status) {
+			continue
+		}
+
+		workflow.AddWorkflowRunInfo(run, false, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowRunConcurrencyCancelled.ID,
+			Args: []interface{}{wfRun.Number, workflow.ConcurrencyKey(*wfRun)},
+		})
+		r1, err := stopWorkflowRun(ctx, api.mustDB, api.Cache, p, run, u, 0)
+		if err != nil {
+			log.Error(ctx, "cancelSupersededWorkflowRuns> unable to stop workflow run %d: %v", id, err)
+			continue
+		}
+		report.Merge(ctx, r1, nil) // nolint
+
+		if err := workflow.ResyncCommitStatus(ctx, api.mustDB(), api.Cache, p, run); err != nil {
+			log.Error(ctx, "cancelSupersededWorkflowRuns> unable to resync commit status of workflow run %d: %v", id, err)
+		}
+	}
+	return report
+}
+
+// startQueuedWorkflowRuns periodically starts the workflow runs queued by the concurrency of their workflow
+// when the number of runs in progress for their concurrency key is below the max.
+func (api *API) startQueuedWorkflowRuns(ctx context.Context) {
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if ctx.Err() != nil {
+				log.Error(ctx, "Exiting api.startQueuedWorkflowRuns: %v", ctx.Err())
+				return
+			}
+		case <-tick.C:
+			ids, err := workflow.LoadQueuedRunIDs(api.mustDB())
+			if err != nil {
+				log.Warning(ctx, "api.startQueuedWorkflowRuns> %v", err)
+				continue
+			}
+			for _, id := range ids {
+				if err := api.startQueuedWorkflowRun(ctx, id); err != nil {
+					log.Error(ctx, "api.startQueuedWorkflowRuns> unable to start queued workflow run %d: %v", id, err)
+				}
+			}
+		}
+	}
+}
+
+// startQueuedWorkflowRun starts a queued run if possible, a run is started by only one API instance at a time.
+func (api *API) startQueuedWorkflowRun(ctx context.Context, id int64) error {
+	lockKey := queuedWorkflowRunLockKey(id)
+	locked, err := api.Cache.Lock(lockKey, 5*time.Minute, 0, 1)
+	if err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer func() {
+		if err := api.Cache.Unlock(lockKey); err != nil {
+			log.Warning(ctx, "api.startQueuedWorkflowRun> unable to unlock queued workflow run %d: %v", id, err)
+		}
+	}()
+
+	wfRun, err := workflow.LoadRunByID(api.mustDB(), id, workflow.LoadRunOptions{})
+	if err != nil {
+		return err
+	}
+	if wfRun.Status != sdk.StatusPending || wfRun.Workflow.Concurrency == nil {
+		return nil
+	}
+
+	// Queued runs are started from the oldest, the first one that can't start blocks the others with the same key
+	key := workflow.ConcurrencyKey(*wfRun)
+	queued, err := workflow.LoadConcurrentRunIDs(api.mustDB(), wfRun.WorkflowID, key, sdk.StatusPending)
+	if err != nil {
+		return err
+	}
+	if len(queued) > 0 && queued[0] != wfRun.ID {
+		return nil
+	}
+	inProgress, err := workflow.LoadConcurrentRunIDs(api.mustDB(), wfRun.WorkflowID, key, workflow.ConcurrencyInProgressStatus...)
+	if err != nil {
+		return err
+	}
+	if int64(len(inProgress)) >= wfRun.Workflow.Concurrency.GetMax() {
+		return nil
+	}
+
+	p, err := project.LoadByID(api.mustDB(), api.Cache, wfRun.ProjectID,
+		project.LoadOptions.WithVariables,
+		project.LoadOptions.WithFeatures,
+		project.LoadOptions.WithIntegrations,
+		project.LoadOptions.WithApplicationVariables,
+		project.LoadOptions.WithApplicationWithDeploymentStrategies,
+		project.LoadOptions.WithEnvironments,
+		project.LoadOptions.WithPipelines,
+	)
+	if err != nil {
+		return err
+	}
+
+	opts, consumerID, err := workflow.LoadQueuedRun(api.mustDB(), wfRun.ID)
+	if err != nil {
+		if !sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return err
+		}
+		// Without the options of the run it can't be started, so the run is failed
+		r1 := failInitWorkflowRun(ctx, api.mustDB(), wfRun, sdk.NewErrorFrom(sdk.ErrNotFound, "options of queued workflow run not found"))
+		workflow.SendEvent(ctx, api.mustDB(), p.Key, r1)
+		return nil
+	}
+
+	consumer, err := authentication.LoadConsumerByID(ctx, api.mustDB(), consumerID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+	if err != nil {
+		// Without its consumer, for example when it was deleted, the run can't be started and would block the queue
+		r1 := failInitWorkflowRun(ctx, api.mustDB(), wfRun, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrNotFound, "consumer of queued workflow run not found")))
+		workflow.SendEvent(ctx, api.mustDB(), p.Key, r1)
+		if err := workflow.DeleteQueuedRun(api.mustDB(), wfRun.ID); err != nil {
+			log.Warning(ctx, "api.startQueuedWorkflowRun> %v", err)
+		}
+		return nil
+	}
+
+	wf, err := workflow.Load(ctx, api.mustDB(), api.Cache, p, wfRun.Workflow.Name, workflow.LoadOptions{
+		DeepPipeline:          true,
+		Base64Keys:            true,
+		WithAsCodeUpdateEvent: true,
+		WithIcon:              true,
+		WithIntegrations:      true,
+	})
+	if err != nil {
+		return err
+	}
+
+	api.initWorkflowRun(ctx, api.mustDB(), api.Cache, p, wf, wfRun, opts, consumer)
+	return nil
+}
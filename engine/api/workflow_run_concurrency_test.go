@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_startQueuedWorkflowRun(t *testing.T) {
+	api, db, _, end := newTestAPI(t)
+	defer end()
+
+	u, _ := assets.InsertAdminUser(t, db)
+	consumer, _ := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, api.Cache, proj, sdk.RandomString(10))
+	w.Concurrency = &sdk.WorkflowConcurrency{Key: "{{.cds.workflow}}", Policy: sdk.WorkflowConcurrencyPolicyQueue}
+	require.NoError(t, workflow.Update(context.TODO(), db, api.Cache, w, proj, workflow.UpdateOptions{}))
+
+	startRun := func() *sdk.WorkflowRun {
+		w1, err := workflow.Load(context.TODO(), db, api.Cache, proj, w.Name, workflow.LoadOptions{DeepPipeline: true})
+		require.NoError(t, err)
+		wr, err := workflow.CreateRun(db, w1, nil, u)
+		require.NoError(t, err)
+		wr.Workflow = *w1
+		_, err = workflow.StartWorkflowRun(context.TODO(), db, api.Cache, proj, wr, &sdk.WorkflowRunPostHandlerOption{Manual: &sdk.WorkflowNodeRunManual{}}, consumer, nil)
+		require.NoError(t, err)
+		res, err := workflow.LoadRunByID(db, wr.ID, workflow.LoadRunOptions{})
+		require.NoError(t, err)
+		return res
+	}
+	loadStatus := func(id int64) string {
+		wr, err := workflow.LoadRunByID(db, id, workflow.LoadRunOptions{})
+		require.NoError(t, err)
+		return wr.Status
+	}
+
+	wr1 := startRun()
+	wr2 := startRun()
+	require.Equal(t, sdk.StatusPending, wr2.Status)
+
+	// The queued run stays queued while the first one is in progress
+	require.NoError(t, api.startQueuedWorkflowRun(context.TODO(), wr2.ID))
+	assert.Equal(t, sdk.StatusPending, loadStatus(wr2.ID))
+
+	wr1.Status = sdk.StatusSuccess
+	require.NoError(t, workflow.UpdateWorkflowRunStatus(db, wr1))
+
+	// The queued run is not started while another API instance holds its lock
+	lockKey := queuedWorkflowRunLockKey(wr2.ID)
+	locked, err := api.Cache.Lock(lockKey, time.Minute, 0, 1)
+	require.NoError(t, err)
+	require.True(t, locked)
+	require.NoError(t, api.startQueuedWorkflowRun(context.TODO(), wr2.ID))
+	assert.Equal(t, sdk.StatusPending, loadStatus(wr2.ID))
+	require.NoError(t, api.Cache.Unlock(lockKey))
+
+	require.NoError(t, api.startQueuedWorkflowRun(context.TODO(), wr2.ID))
+	assert.Contains(t, workflow.ConcurrencyInProgressStatus, loadStatus(wr2.ID))
+	_, _, err = workflow.LoadQueuedRun(db, wr2.ID)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+
+	// A queued run whose consumer was deleted is failed and doesn't block the next one
+	wr2.Status = sdk.StatusSuccess
+	require.NoError(t, workflow.UpdateWorkflowRunStatus(db, wr2))
+	wr3 := startRun()
+	wr4 := startRun()
+	require.Equal(t, sdk.StatusPending, wr3.Status)
+	require.Equal(t, sdk.StatusPending, wr4.Status)
+	opts, _, err := workflow.LoadQueuedRun(db, wr3.ID)
+	require.NoError(t, err)
+	require.NoError(t, workflow.InsertQueuedRun(db, wr3.ID, *opts, sdk.UUID()))
+
+	require.NoError(t, api.startQueuedWorkflowRun(context.TODO(), wr3.ID))
+	assert.Equal(t, sdk.StatusFail, loadStatus(wr3.ID))
+	require.NoError(t, api.startQueuedWorkflowRun(context.TODO(), wr4.ID))
+	assert.Contains(t, workflow.ConcurrencyInProgressStatus, loadStatus(wr4.ID))
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// stepLogsMessage is published each time logs are added to a step, or when the step ends.
+type stepLogsMessage struct {
+	Offset int64  `json:"offset"`
+	Val    string `json:"val,omitempty"`
+	Done   bool   `json:"done,omitempty"`
+}
+
+// stepLogsStatusCheckInterval is the interval at which the step status is checked while streaming its logs, for the
+// stream to end when the job is stopped or restarted without the step being updated.
+const stepLogsStatusCheckInterval = 5 * time.Second
+
+// stepLogsChannel returns the channel where the logs of a step are published.
+func stepLogsChannel(jobID, stepOrder int64) string {
+	return cache.Key("logs", "step", strconv.FormatInt(jobID, 10), strconv.FormatInt(stepOrder, 10))
+}
+
+func (api *API) publishStepLogs(ctx context.Context, jobID, stepOrder int64, m stepLogsMessage) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		log.Error(ctx, "publishStepLogs> unable to marshal message: %v", err)
+		return
+	}
+	if err := api.Cache.Publish(ctx, stepLogsChannel(jobID, stepOrder), string(b)); err != nil {
+		log.Error(ctx, "publishStepLogs> unable to publish logs of job %d step %d: %v", jobID, stepOrder, err)
+	}
+}
+
+func requestLogOffset(r *http.Request) (int64, error) {
+	offsetS := r.FormValue("offset")
+	if offsetS == "" {
+		return 0, nil
+	}
+	offset, err := strconv.ParseInt(offsetS, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid offset %s", offsetS)
+	}
+	return offset, nil
+}
+
+// getWorkflowNodeRunJobStepStatus returns the status of a step, checking that the node run is linked to the workflow.
+// The status of the job is returned when the job is terminated but not the step, for example when the job was stopped.
+func (api *API) getWorkflowNodeRunJobStepStatus(projectKey, workflowName string, number, nodeRunID, runJobID, stepOrder int64) (string, error) {
+	nodeRun, err := workflow.LoadNodeRun(api.mustDB(), projectKey, workflowName, number, nodeRunID, workflow.LoadRunOptions{DisableDetailledNodeRun: true})
+	if err != nil {
+		return "", sdk.WrapError(err, "cannot find nodeRun %d/%d for workflow %s in project %s", nodeRunID, number, workflowName, projectKey)
+	}
+
+	for _, s := range nodeRun.Stages {
+		for _, rj := range s.RunJobs {
+			if rj.ID != runJobID {
+				continue
+			}
+			for _, ss := range rj.Job.StepStatus {
+				if int64(ss.StepOrder) == stepOrder {
+					if sdk.StatusIsTerminated(rj.Status) && !sdk.StatusIsTerminated(ss.Status) {
+						return rj.Status, nil
+					}
+					return ss.Status, nil
+				}
+			}
+		}
+	}
+
+	return "", sdk.WrapError(sdk.ErrStepNotFound, "cannot find step %d on job %d in nodeRun %d/%d for workflow %s in project %s",
+		stepOrder, runJobID, nodeRunID, number, workflowName, projectKey)
+}
+
+// getWorkflowNodeRunJobStepLogsHandler streams the logs of a step from given offset until the step ends.
+func (api *API) getWorkflowNodeRunJobStepLogsHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		projectKey := vars["key"]
+		workflowName := vars["permWorkflowName"]
+		number, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+		nodeRunID, err := requestVarInt(r, "nodeRunID")
+		if err != nil {
+			return err
+		}
+		runJobID, err := requestVarInt(r, "runJobId")
+		if err != nil {
+			return err
+		}
+		stepOrder, err := requestVarInt(r, "stepOrder")
+		if err != nil {
+			return err
+		}
+		offset, err := requestLogOffset(r)
+		if err != nil {
+			return err
+		}
+
+		f, ok := w.(http.Flusher)
+		if !ok {
+			return sdk.WrapError(sdk.ErrNotImplemented, "streaming is not supported")
+		}
+
+		// Subscribe before checking the status and loading the logs to not miss any message
+		channel := stepLogsChannel(runJobID, stepOrder)
+		pubSub, err := api.Cache.Subscribe(channel)
+		if err != nil {
+			return sdk.WrapError(err, "unable to subscribe to %s", channel)
+		}
+		defer pubSub.Unsubscribe(channel) // nolint
+
+		stepStatus, err := api.getWorkflowNodeRunJobStepStatus(projectKey, workflowName, number, nodeRunID, runJobID, stepOrder)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		writeFromStore := func() error {
+			logs, err := workflow.GetLogStore().Load(ctx, api.mustDB(), runJobID, stepOrder, offset)
+			if err != nil {
+				return sdk.WrapError(err, "cannot load log for runJob %d on step %d", runJobID, stepOrder)
+			}
+			if logs == nil || logs.Size <= offset {
+				return nil
+			}
+			if _, err := io.WriteString(w, logs.Val); err != nil {
+				return sdk.WithStack(err)
+			}
+			offset = logs.Size
+			f.Flush()
+			return nil
+		}
+
+		if err := writeFromStore(); err != nil {
+			return err
+		}
+		if sdk.StatusIsTerminated(stepStatus) {
+			return nil
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		msgs := make(chan string)
+		sdk.GoRoutine(ctx, "getWorkflowNodeRunJobStepLogsHandler", func(ctx context.Context) {
+			for {
+				msg, err := api.Cache.GetMessageFromSubscription(ctx, pubSub)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					log.Warning(ctx, "getWorkflowNodeRunJobStepLogsHandler> cannot get message: %v", err)
+					continue
+				}
+				select {
+				case msgs <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+
+		// Done is only published when the worker sends the step status, so the status is also checked periodically
+		ticker := time.NewTicker(stepLogsStatusCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			var msg string
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				status, err := api.getWorkflowNodeRunJobStepStatus(projectKey, workflowName, number, nodeRunID, runJobID, stepOrder)
+				if err != nil && !sdk.ErrorIs(err, sdk.ErrStepNotFound) {
+					log.Warning(ctx, "getWorkflowNodeRunJobStepLogsHandler> cannot get step status: %v", err)
+					continue
+				}
+				// A step reset to waiting from building belongs to a job that was restarted
+				if err != nil || sdk.StatusIsTerminated(status) || (stepStatus == sdk.StatusBuilding && status == sdk.StatusWaiting) {
+					return writeFromStore()
+				}
+				stepStatus = status
+				continue
+			case msg = <-msgs:
+			}
+
+			var m stepLogsMessage
+			if err := json.Unmarshal([]byte(msg), &m); err != nil {
+				continue
+			}
+			if m.Done {
+				return writeFromStore()
+			}
+
+			switch {
+			case m.Offset+int64(len(m.Val)) <= offset: // already sent
+				continue
+			case m.Offset > offset: // some logs were missed
+				if err := writeFromStore(); err != nil {
+					return err
+				}
+			default:
+				if _, err := io.WriteString(w, m.Val[offset-m.Offset:]); err != nil {
+					return sdk.WithStack(err)
+				}
+				offset = m.Offset + int64(len(m.Val))
+				f.Flush()
+			}
+		}
+	}
+}
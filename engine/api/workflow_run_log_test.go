@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_getWorkflowNodeRunJobStepLogsHandler(t *testing.T) {
+	api, tsURL, tsClose := newTestServer(t)
+	defer tsClose()
+
+	u, pass, proj, w1, lastRun, jobRun := initGetWorkflowNodeRunJobTest(t, api, api.mustDB())
+	nodeRun := &lastRun.WorkflowNodeRuns[w1.WorkflowData.Node.ID][0]
+
+	vars := map[string]string{
+		"key":              proj.Key,
+		"permWorkflowName": w1.Name,
+		"number":           fmt.Sprintf("%d", lastRun.Number),
+		"nodeRunID":        fmt.Sprintf("%d", nodeRun.ID),
+		"runJobId":         fmt.Sprintf("%d", jobRun.ID),
+		"stepOrder":        "1",
+	}
+	uri := api.Router.GetRoute("GET", api.getWorkflowNodeRunJobStepLogsHandler, vars)
+	test.NotEmpty(t, uri)
+
+	stored := "123456789012345... truncated\n"
+
+	// The step is running, logs are streamed from the offset until the step ends
+	req := assets.NewAuthentifiedRequest(t, u, pass, "GET", tsURL+uri+"?offset=5", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	chunks := make(chan string)
+	go func() {
+		defer close(chunks)
+		b := make([]byte, 1024)
+		for {
+			n, err := resp.Body.Read(b)
+			if n > 0 {
+				chunks <- string(b[:n])
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	// messages are published until they are received, the subscription of the handler can't be known
+	var received string
+	readUntil := func(expected string, m *stepLogsMessage) bool {
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+		timeout := time.After(10 * time.Second)
+		for {
+			select {
+			case c, ok := <-chunks:
+				if !ok {
+					return received == expected
+				}
+				received += c
+				if received == expected && m == nil {
+					return true
+				}
+			case <-tick.C:
+				if received == expected && m != nil && !m.Done {
+					return true
+				}
+				if m != nil {
+					api.publishStepLogs(context.TODO(), jobRun.ID, 1, *m)
+				}
+			case <-timeout:
+				t.Logf("received %q", received)
+				return false
+			}
+		}
+	}
+
+	require.True(t, readUntil(stored[5:], nil))
+	require.True(t, readUntil(stored[5:]+"live\n", &stepLogsMessage{Offset: int64(len(stored)), Val: "live\n"}))
+	// a message already sent is ignored
+	api.publishStepLogs(context.TODO(), jobRun.ID, 1, stepLogsMessage{Offset: int64(len(stored)) + 2, Val: "ve\n"})
+	require.True(t, readUntil(stored[5:]+"live\n", &stepLogsMessage{Done: true}))
+
+	// The step is terminated, the stored logs are returned
+	nodeRun.Stages[0].RunJobs[0].Job.StepStatus[0].Status = sdk.StatusSuccess
+	require.NoError(t, workflow.UpdateNodeRun(api.mustDB(), nodeRun))
+
+	req = assets.NewAuthentifiedRequest(t, u, pass, "GET", tsURL+uri, nil)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	btes, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, stored, string(btes))
+
+	// Invalid offset
+	req = assets.NewAuthentifiedRequest(t, u, pass, "GET", tsURL+uri+"?offset=-1", nil)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	btes, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(btes), "invalid offset"))
+}
+
+func Test_getWorkflowNodeRunJobStepLogsHandlerWithStoppedJob(t *testing.T) {
+	api, tsURL, tsClose := newTestServer(t)
+	defer tsClose()
+
+	u, pass, proj, w1, lastRun, jobRun := initGetWorkflowNodeRunJobTest(t, api, api.mustDB())
+	nodeRun := &lastRun.WorkflowNodeRuns[w1.WorkflowData.Node.ID][0]
+
+	vars := map[string]string{
+		"key":              proj.Key,
+		"permWorkflowName": w1.Name,
+		"number":           fmt.Sprintf("%d", lastRun.Number),
+		"nodeRunID":        fmt.Sprintf("%d", nodeRun.ID),
+		"runJobId":         fmt.Sprintf("%d", jobRun.ID),
+		"stepOrder":        "1",
+	}
+	uri := api.Router.GetRoute("GET", api.getWorkflowNodeRunJobStepLogsHandler, vars)
+	test.NotEmpty(t, uri)
+
+	req := assets.NewAuthentifiedRequest(t, u, pass, "GET", tsURL+uri, nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// The job is stopped without the step being updated, no done message is published
+	nodeRun.Stages[0].RunJobs[0].Status = sdk.StatusStopped
+	require.NoError(t, workflow.UpdateNodeRun(api.mustDB(), nodeRun))
+
+	done := make(chan string)
+	go func() {
+		btes, _ := ioutil.ReadAll(resp.Body)
+		done <- string(btes)
+	}()
+
+	select {
+	case logs := <-done:
+		assert.Equal(t, "123456789012345... truncated\n", logs)
+	case <-time.After(3 * stepLogsStatusCheckInterval):
+		t.Fatal("the logs stream should end when the job is stopped")
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// getWorkflowRunsSearchHandler searches for workflow runs in elasticsearch if available, or in database.
+func (api *API) getWorkflowRunsSearchHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+
+		if err := r.ParseForm(); err != nil {
+			return sdk.NewErrorWithStack(err, sdk.ErrWrongRequest)
+		}
+		f, err := sdk.NewWorkflowRunSearchFilter(r.Form)
+		if err != nil {
+			return err
+		}
+		f.ProjectKey = key
+		f.WorkflowName = name
+		if f.Limit == 0 {
+			f.Limit = defaultLimit
+		}
+		if f.Limit > 50 {
+			f.Limit = 50
+		}
+
+		ids, count, err := workflow.SearchRunsInIndex(ctx, api.mustDB(), f)
+		if err != nil {
+			if !sdk.ErrorIs(err, sdk.ErrNotFound) {
+				log.Warning(ctx, "getWorkflowRunsSearchHandler> unable to search runs in elasticsearch, fallback on database: %v", err)
+			}
+			ids, count, err = workflow.SearchRuns(api.mustDB(), f)
+			if err != nil {
+				return err
+			}
+		}
+
+		runs, err := workflow.LoadRunsByIDs(api.mustDB(), key, ids)
+		if err != nil {
+			return err
+		}
+
+		w.Header().Add("X-Total-Count", strconv.FormatInt(count, 10))
+		return service.WriteJSON(w, runs, http.StatusOK)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/services"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_getWorkflowRunsSearchHandler(t *testing.T) {
+	api, db, router, end := newTestAPI(t)
+	defer end()
+
+	u, pass := assets.InsertAdminUser(t, db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+	w := assets.InsertTestWorkflow(t, db, api.Cache, proj, sdk.RandomString(10))
+
+	var runs []*sdk.WorkflowRun
+	for _, branch := range []string{"master", "feat/x"} {
+		wr, err := workflow.CreateRun(db, w, nil, u)
+		require.NoError(t, err)
+		require.NoError(t, workflow.InsertWorkflowRunTags(db, wr.ID, []sdk.WorkflowRunTag{{Tag: "git.branch", Value: branch}}))
+		runs = append(runs, wr)
+	}
+
+	uri := router.GetRoute("GET", api.getWorkflowRunsSearchHandler, map[string]string{
+		"key":              proj.Key,
+		"permWorkflowName": w.Name,
+	})
+	require.NotEmpty(t, uri)
+
+	search := func(query string) ([]sdk.WorkflowRun, *httptest.ResponseRecorder) {
+		req := assets.NewAuthentifiedRequest(t, u, pass, "GET", uri+"?"+query, nil)
+		rec := httptest.NewRecorder()
+		router.Mux.ServeHTTP(rec, req)
+		var res []sdk.WorkflowRun
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		}
+		return res, rec
+	}
+
+	// Without elasticsearch, runs are searched in database
+	res, rec := search("branch=master")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, res, 1)
+	assert.Equal(t, runs[0].ID, res[0].ID)
+	assert.Equal(t, "1", rec.Header().Get("X-Total-Count"))
+
+	_, rec = search("status=Unknown")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// With elasticsearch, runs found in the index are loaded from database
+	srv, _ := assets.InsertService(t, db, "Test_getWorkflowRunsSearchHandler", services.TypeElasticsearch)
+	defer services.Delete(db, srv) // nolint
+
+	var filter sdk.WorkflowRunSearchFilter
+	services.HTTPClient = mock(
+		func(r *http.Request) (*http.Response, error) {
+			body := new(bytes.Buffer)
+			w := new(http.Response)
+			enc := json.NewEncoder(body)
+			w.Body = ioutil.NopCloser(body)
+			w.StatusCode = http.StatusOK
+
+			switch r.URL.String() {
+			case "/runs":
+				btes, err := ioutil.ReadAll(r.Body)
+				if err != nil {
+					return writeError(w, err)
+				}
+				if err := json.Unmarshal(btes, &filter); err != nil {
+					return writeError(w, err)
+				}
+				if err := enc.Encode(sdk.WorkflowRunSearchResult{IDs: []int64{runs[1].ID}, Total: 5}); err != nil {
+					return writeError(w, err)
+				}
+			default:
+				return writeError(w, sdk.ErrNotFound)
+			}
+			return w, nil
+		},
+	)
+
+	res, rec = search("text=error&limit=100")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, res, 1)
+	assert.Equal(t, runs[1].ID, res[0].ID)
+	assert.Equal(t, "5", rec.Header().Get("X-Total-Count"))
+	assert.Equal(t, proj.Key, filter.ProjectKey)
+	assert.Equal(t, w.Name, filter.WorkflowName)
+	assert.Equal(t, "error", filter.Text)
+	assert.Equal(t, 50, filter.Limit)
+}
@@ -2213,16 +2213,18 @@ func initGetWorkflowNodeRunJobTest(t *testing.T, api *API, db *gorp.DbMap) (*sdk
 	require.NoError(t, errUJ)
 
 	// Add log
-	require.NoError(t, workflow.AddLog(api.mustDB(), jobRun, &sdk.Log{
+	_, err = workflow.AddLog(context.TODO(), api.mustDB(), jobRun, &sdk.Log{
 		StepOrder: 1,
 		Val:       "1234567890",
-	}, 15))
+	}, 15)
+	require.NoError(t, err)
 
 	// Add truncated log
-	require.NoError(t, workflow.AddLog(api.mustDB(), jobRun, &sdk.Log{
+	_, err = workflow.AddLog(context.TODO(), api.mustDB(), jobRun, &sdk.Log{
 		StepOrder: 1,
 		Val:       "1234567890",
-	}, 15))
+	}, 15)
+	require.NoError(t, err)
 
 	// Add service log
 	require.NoError(t, workflow.AddServiceLog(api.mustDB(), jobRun, &sdk.ServiceLog{
@@ -2441,6 +2443,84 @@ func Test_deleteWorkflowRunsBranchHandler(t *testing.T) {
 	require.Equal(t, 0, len(wfRuns))
 }
 
+func Test_deleteWorkflowRunsBranchHandlerWithRetentionPolicy(t *testing.T) {
+	api, db, router, end := newTestAPI(t, bootstrap.InitiliazeDB)
+	defer end()
+	u, pass := assets.InsertAdminUser(t, api.mustDB())
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, api.Cache, key, key)
+
+	pip := sdk.Pipeline{
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		Name:       "pip1",
+	}
+	require.NoError(t, pipeline.InsertPipeline(api.mustDB(), api.Cache, proj, &pip))
+
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: &sdk.WorkflowData{
+			Node: sdk.Node{
+				Name: "root",
+				Type: sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{
+					PipelineID: pip.ID,
+				},
+			},
+		},
+		RetentionPolicy: &sdk.WorkflowRetentionPolicy{
+			KeepTags:        []string{"release"},
+			KeepLastSuccess: true,
+		},
+	}
+
+	proj2, errP := project.Load(api.mustDB(), api.Cache, proj.Key, project.LoadOptions.WithPipelines, project.LoadOptions.WithGroups, project.LoadOptions.WithIntegrations)
+	require.NoError(t, errP)
+
+	require.NoError(t, workflow.Insert(context.TODO(), api.mustDB(), api.Cache, &w, proj2))
+	w1, err := workflow.Load(context.TODO(), api.mustDB(), api.Cache, proj, "test_1", workflow.LoadOptions{})
+	require.NoError(t, err)
+
+	// The first run is tagged as a release, the second one is the last in success and the third one failed
+	createRun := func(status string, tags map[string]string) *sdk.WorkflowRun {
+		wr, err := workflow.CreateRun(db, w1, nil, u)
+		require.NoError(t, err)
+		wr.Workflow = *w1
+		wr.Status = status
+		wr.Tag("git.branch", "my-feature")
+		for k, v := range tags {
+			wr.Tag(k, v)
+		}
+		require.NoError(t, workflow.UpdateWorkflowRun(context.TODO(), api.mustDB(), wr))
+		return wr
+	}
+	tagged := createRun(sdk.StatusSuccess, map[string]string{"release": "1.0.0"})
+	lastSuccess := createRun(sdk.StatusSuccess, nil)
+	failed := createRun(sdk.StatusFail, nil)
+
+	uri := router.GetRoute("DELETE", api.deleteWorkflowRunsBranchHandler, map[string]string{
+		"key":              proj.Key,
+		"permWorkflowName": w1.Name,
+		"branch":           "my-feature",
+	})
+	test.NotEmpty(t, uri)
+	req := assets.NewAuthentifiedRequest(t, u, pass, "DELETE", uri, nil)
+	rec := httptest.NewRecorder()
+	router.Mux.ServeHTTP(rec, req)
+	require.Equal(t, 200, rec.Code)
+
+	runs, err := workflow.LoadRunsRetention(db, w1.ID)
+	require.NoError(t, err)
+	var ids []int64
+	for _, r := range runs {
+		ids = append(ids, r.ID)
+	}
+	assert.ElementsMatch(t, []int64{tagged.ID, lastSuccess.ID}, ids)
+	assert.NotContains(t, ids, failed.ID)
+}
+
 func Test_deleteWorkflowRunHandler(t *testing.T) {
 	api, db, router, end := newTestAPI(t, bootstrap.InitiliazeDB)
 	defer end()
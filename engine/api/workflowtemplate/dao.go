@@ -3,6 +3,7 @@ package workflowtemplate
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"strings"
 
 	"github.com/go-gorp/gorp"
@@ -73,6 +74,16 @@ func LoadAllByIDs(ctx context.Context, db gorp.SqlExecutor, ids []int64, opts ..
 	return getAll(ctx, db, query, opts...)
 }
 
+// LoadAllByIncludedTemplatePath returns all workflow templates that include the template with given path.
+func LoadAllByIncludedTemplatePath(ctx context.Context, db gorp.SqlExecutor, path string, opts ...LoadOptionFunc) ([]sdk.WorkflowTemplate, error) {
+	includes, err := json.Marshal(sdk.WorkflowTemplateIncludes{{Template: path}})
+	if err != nil {
+		return nil, sdk.WithStack(err)
+	}
+	query := gorpmapping.NewQuery("SELECT * FROM workflow_template WHERE includes @> $1::jsonb").Args(string(includes))
+	return getAll(ctx, db, query, opts...)
+}
+
 // LoadByID retrieves in database the workflow template with given id.
 func LoadByID(ctx context.Context, db gorp.SqlExecutor, id int64, opts ...LoadOptionFunc) (*sdk.WorkflowTemplate, error) {
 	query := gorpmapping.NewQuery("SELECT * FROM workflow_template WHERE id = $1").Args(id)
@@ -328,3 +339,21 @@ func GetBulkByIDAndTemplateID(db gorp.SqlExecutor, id, templateID int64) (*sdk.W
 
 	return &b, nil
 }
+
+// InsertRelease for workflow template in database.
+func InsertRelease(db gorp.SqlExecutor, r *sdk.WorkflowTemplateRelease) error {
+	return sdk.WrapError(gorpmapping.Insert(db, r), "unable to insert release %s for workflow template %d", r.Version, r.WorkflowTemplateID)
+}
+
+// LoadReleasesByTemplateID returns all releases of a workflow template sorted from the greatest version.
+func LoadReleasesByTemplateID(ctx context.Context, db gorp.SqlExecutor, templateID int64) (sdk.WorkflowTemplateReleases, error) {
+	rs := []sdk.WorkflowTemplateRelease{}
+
+	query := gorpmapping.NewQuery("SELECT * FROM workflow_template_release WHERE workflow_template_id = $1").Args(templateID)
+	if err := gorpmapping.GetAll(ctx, db, query, &rs); err != nil {
+		return nil, sdk.WrapError(err, "cannot get releases for workflow template %d", templateID)
+	}
+	sdk.WorkflowTemplateReleases(rs).Sort()
+
+	return rs, nil
+}
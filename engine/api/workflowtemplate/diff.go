@@ -0,0 +1,93 @@
+package workflowtemplate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+// Diff returns the unified diffs of the generated files that differ between two executions of a template.
+func Diff(before, after sdk.WorkflowTemplateResult) ([]sdk.WorkflowTemplateFileDiff, error) {
+	beforeFiles, err := resultFiles(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFiles, err := resultFiles(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(beforeFiles)+len(afterFiles))
+	for name := range beforeFiles {
+		names = append(names, name)
+	}
+	for name := range afterFiles {
+		if _, ok := beforeFiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	diffs := []sdk.WorkflowTemplateFileDiff{}
+	for _, name := range names {
+		b, a := beforeFiles[name], afterFiles[name]
+		if b == a {
+			continue
+		}
+		d, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(b),
+			B:        difflib.SplitLines(a),
+			FromFile: name,
+			ToFile:   name,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		diffs = append(diffs, sdk.WorkflowTemplateFileDiff{Name: name, Diff: d})
+	}
+
+	return diffs, nil
+}
+
+// resultFiles returns the generated files of a template result by file name, file names are the same as in a tar.
+func resultFiles(res sdk.WorkflowTemplateResult) (map[string]string, error) {
+	files := make(map[string]string, 1+len(res.Pipelines)+len(res.Applications)+len(res.Environments))
+
+	var wor exportentities.Workflow
+	if err := yaml.Unmarshal([]byte(res.Workflow), &wor); err != nil {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot parse generated workflow: %v", err)
+	}
+	files[fmt.Sprintf(exportentities.PullWorkflowName, wor.Name)] = res.Workflow
+
+	for _, p := range res.Pipelines {
+		var pip exportentities.PipelineV1
+		if err := yaml.Unmarshal([]byte(p), &pip); err != nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot parse generated pipeline: %v", err)
+		}
+		files[fmt.Sprintf(exportentities.PullPipelineName, pip.Name)] = p
+	}
+
+	for _, a := range res.Applications {
+		var app exportentities.Application
+		if err := yaml.Unmarshal([]byte(a), &app); err != nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot parse generated application: %v", err)
+		}
+		files[fmt.Sprintf(exportentities.PullApplicationName, app.Name)] = a
+	}
+
+	for _, e := range res.Environments {
+		var env exportentities.Environment
+		if err := yaml.Unmarshal([]byte(e), &env); err != nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot parse generated environment: %v", err)
+		}
+		files[fmt.Sprintf(exportentities.PullEnvironmentName, env.Name)] = e
+	}
+
+	return files, nil
+}
@@ -0,0 +1,39 @@
+package workflowtemplate_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/workflowtemplate"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestDiff(t *testing.T) {
+	before := sdk.WorkflowTemplateResult{
+		Workflow:  "name: my-workflow\nversion: v1.0\npipeline: build\n",
+		Pipelines: []string{"name: build\nversion: v1.0\n", "name: deploy\nversion: v1.0\n"},
+	}
+	after := sdk.WorkflowTemplateResult{
+		Workflow:     "name: my-workflow\nversion: v1.0\npipeline: build-and-test\n",
+		Pipelines:    []string{"name: build-and-test\nversion: v1.0\n", "name: deploy\nversion: v1.0\n"},
+		Applications: []string{"name: my-application\nversion: v1.0\n"},
+	}
+
+	diffs, err := workflowtemplate.Diff(before, after)
+	require.NoError(t, err)
+	require.Len(t, diffs, 4)
+
+	assert.Equal(t, "build-and-test.pip.yml", diffs[0].Name)
+	assert.Contains(t, diffs[0].Diff, "+name: build-and-test\n")
+	assert.Equal(t, "build.pip.yml", diffs[1].Name)
+	assert.Contains(t, diffs[1].Diff, "-name: build\n")
+	assert.Equal(t, "my-application.app.yml", diffs[2].Name)
+	assert.Equal(t, "my-workflow.yml", diffs[3].Name)
+	assert.Contains(t, diffs[3].Diff, "-pipeline: build\n+pipeline: build-and-test\n")
+
+	diffs, err = workflowtemplate.Diff(before, before)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+}
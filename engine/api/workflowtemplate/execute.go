@@ -183,6 +183,10 @@ func Execute(wt *sdk.WorkflowTemplate, instance *sdk.WorkflowTemplateInstance) (
 		}
 	}
 
+	if err := executeIncludes(wt, instance, data, &result, &multiErr); err != nil {
+		return result, err
+	}
+
 	if !multiErr.IsEmpty() {
 		var errs []sdk.WorkflowTemplateError
 		causes := make([]string, len(multiErr))
@@ -135,6 +135,73 @@ values:
 		value: value1`, res.Environments[0])
 }
 
+func TestExecuteTemplateWithIncludes(t *testing.T) {
+	fragment := sdk.WorkflowTemplate{
+		ID: 1,
+		Parameters: []sdk.WorkflowTemplateParameter{
+			{Key: "image", Type: sdk.ParameterTypeString, Required: true},
+			{Key: "withScan", Type: sdk.ParameterTypeBoolean},
+		},
+		Pipelines: []sdk.PipelineTemplate{{
+			Value: base64.StdEncoding.EncodeToString([]byte(`name: build-[[.name]]
+image: [[.params.image]]
+scan: [[.params.withScan]]`)),
+		}},
+		Environments: []sdk.EnvironmentTemplate{{
+			Value: base64.StdEncoding.EncodeToString([]byte(`name: registry`)),
+		}},
+	}
+
+	tmpl := &sdk.WorkflowTemplate{
+		ID: 2,
+		Parameters: []sdk.WorkflowTemplateParameter{
+			{Key: "name", Type: sdk.ParameterTypeString, Required: true},
+			{Key: "withScan", Type: sdk.ParameterTypeBoolean},
+		},
+		Workflow: base64.StdEncoding.EncodeToString([]byte(`name: [[.name]]`)),
+		Pipelines: []sdk.PipelineTemplate{{
+			Value: base64.StdEncoding.EncodeToString([]byte(`name: deploy-[[.name]]`)),
+		}},
+		Includes: sdk.WorkflowTemplateIncludes{{
+			Template:   "shared.infra/fragment",
+			Parameters: map[string]string{"image": "registry/[[.params.name]]"},
+		}},
+	}
+
+	// included templates should be loaded
+	_, err := workflowtemplate.Execute(tmpl, nil)
+	assert.Error(t, err)
+
+	tmpl.Included = []sdk.WorkflowTemplate{fragment}
+	_, err = workflowtemplate.Execute(tmpl, nil)
+	assert.NoError(t, err)
+
+	res, err := workflowtemplate.Execute(tmpl, &sdk.WorkflowTemplateInstance{
+		ID: 5,
+		Request: sdk.WorkflowTemplateRequest{
+			ProjectKey:   "PROJ",
+			WorkflowName: "my-workflow",
+			Parameters:   map[string]string{"name": "my-app", "withScan": "true"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "name: my-workflow", res.Workflow)
+	assert.Equal(t, []string{"name: deploy-my-workflow", "name: build-my-workflow\nimage: registry/my-app\nscan: true"}, res.Pipelines)
+	assert.Equal(t, []string{"name: registry"}, res.Environments)
+
+	// parameters of the included template are checked
+	tmpl.Includes[0].Parameters = nil
+	_, err = workflowtemplate.Execute(tmpl, &sdk.WorkflowTemplateInstance{
+		ID: 5,
+		Request: sdk.WorkflowTemplateRequest{
+			ProjectKey:   "PROJ",
+			WorkflowName: "my-workflow",
+			Parameters:   map[string]string{"name": "my-app"},
+		},
+	})
+	assert.Error(t, err)
+}
+
 func TestExecuteTemplateWithError(t *testing.T) {
 	tmpl := &sdk.WorkflowTemplate{
 		ID: 42,
@@ -12,5 +12,6 @@ func init() {
 		gorpmapping.New(sdk.AuditWorkflowTemplate{}, "workflow_template_audit", true, "id"),
 		gorpmapping.New(sdk.AuditWorkflowTemplateInstance{}, "workflow_template_instance_audit", true, "id"),
 		gorpmapping.New(sdk.WorkflowTemplateBulk{}, "workflow_template_bulk", true, "id"),
+		gorpmapping.New(sdk.WorkflowTemplateRelease{}, "workflow_template_release", true, "id"),
 	)
 }
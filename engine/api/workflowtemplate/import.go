@@ -17,6 +17,9 @@ func Push(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate, u
 		return nil, err
 	}
 	if old == nil {
+		if err := LoadIncludes(ctx, db, wt); err != nil {
+			return nil, err
+		}
 		if err := Insert(db, wt); err != nil {
 			return nil, err
 		}
@@ -35,6 +38,9 @@ func Push(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate, u
 	clone.Update(*wt)
 
 	// execute template with no instance only to check if parsing is ok
+	if err := LoadIncludes(ctx, db, &clone); err != nil {
+		return nil, err
+	}
 	if _, err := Execute(&clone, nil); err != nil {
 		return nil, err
 	}
@@ -48,6 +54,11 @@ func Push(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate, u
 		return nil, err
 	}
 
+	// templates that include the updated template are now out of date
+	if err := UpdateDependents(ctx, db, newTemplate, u); err != nil {
+		return nil, err
+	}
+
 	event.PublishWorkflowTemplateUpdate(ctx, *old, *newTemplate, "", u)
 
 	return []sdk.Message{sdk.NewMessage(sdk.MsgWorkflowTemplateImportedUpdated, newTemplate.Group.Name, newTemplate.Slug)}, nil
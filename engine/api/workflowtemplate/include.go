@@ -0,0 +1,202 @@
+package workflowtemplate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/event"
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/sdk"
+)
+
+// LoadIncludes loads recursively the templates included by given template. Included templates should belong to the
+// group of the including template or to the shared infra group, an error is returned if an include cycle is detected.
+func LoadIncludes(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate) error {
+	return loadIncludes(ctx, db, wt, []int64{wt.ID}, []string{wt.Path()})
+}
+
+func loadIncludes(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate, ids []int64, paths []string) error {
+	wt.Included = make([]sdk.WorkflowTemplate, len(wt.Includes))
+	for i, inc := range wt.Includes {
+		groupName, slug, err := inc.GroupAndSlug()
+		if err != nil {
+			return err
+		}
+
+		g, err := group.LoadByName(ctx, db, groupName)
+		if err != nil {
+			if sdk.ErrorIs(err, sdk.ErrNotFound) {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot find included template %s", inc.Template)
+			}
+			return err
+		}
+		if g.ID != wt.GroupID && g.ID != group.SharedInfraGroup.ID {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "included template %s should belong to the group of template %s or to group %s",
+				inc.Template, wt.Path(), group.SharedInfraGroup.Name)
+		}
+
+		included, err := LoadBySlugAndGroupID(ctx, db, slug, g.ID, LoadOptions.Default)
+		if err != nil {
+			if sdk.ErrorIs(err, sdk.ErrNotFound) {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot find included template %s", inc.Template)
+			}
+			return err
+		}
+
+		for _, id := range ids {
+			if id == included.ID {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "include cycle detected: %s -> %s", strings.Join(paths, " -> "), inc.Template)
+			}
+		}
+
+		if err := loadIncludes(ctx, db, included, append(ids, included.ID), append(paths, inc.Template)); err != nil {
+			return err
+		}
+		wt.Included[i] = *included
+	}
+
+	return nil
+}
+
+// UpdateDependents increments the version of all the templates that include given template directly or not,
+// so their instances will be considered as out of date.
+func UpdateDependents(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate, u sdk.Identifiable) error {
+	visited := map[int64]struct{}{wt.ID: {}}
+	updated := []sdk.WorkflowTemplate{*wt}
+	for len(updated) > 0 {
+		current := updated[0]
+		updated = updated[1:]
+
+		dependents, err := LoadAllByIncludedTemplatePath(ctx, db, current.Path(), LoadOptions.Default)
+		if err != nil {
+			return err
+		}
+		for i := range dependents {
+			if _, ok := visited[dependents[i].ID]; ok {
+				continue
+			}
+			visited[dependents[i].ID] = struct{}{}
+
+			clone := sdk.WorkflowTemplate(dependents[i])
+			clone.Version++
+			if err := Update(db, &clone); err != nil {
+				return err
+			}
+
+			event.PublishWorkflowTemplateUpdate(ctx, dependents[i], clone, fmt.Sprintf("Included template %s updated", current.Path()), u)
+
+			updated = append(updated, clone)
+		}
+	}
+
+	return nil
+}
+
+// CheckNotIncluded returns an error if given template is included by other templates, they could not be applied
+// without it.
+func CheckNotIncluded(ctx context.Context, db gorp.SqlExecutor, wt *sdk.WorkflowTemplate) error {
+	dependents, err := LoadAllByIncludedTemplatePath(ctx, db, wt.Path(), LoadOptions.Default)
+	if err != nil {
+		return err
+	}
+	if len(dependents) == 0 {
+		return nil
+	}
+	paths := make([]string, len(dependents))
+	for i := range dependents {
+		paths[i] = dependents[i].Path()
+	}
+	return sdk.NewErrorFrom(sdk.ErrForbidden, "template %s is included by templates %s", wt.Path(), strings.Join(paths, ", "))
+}
+
+// RenameIncludes replaces the old path of a renamed template by its new path in the includes of the templates that
+// include it.
+func RenameIncludes(ctx context.Context, db gorp.SqlExecutor, oldPath, newPath string) error {
+	if oldPath == newPath {
+		return nil
+	}
+	dependents, err := LoadAllByIncludedTemplatePath(ctx, db, oldPath)
+	if err != nil {
+		return err
+	}
+	for i := range dependents {
+		for j := range dependents[i].Includes {
+			if dependents[i].Includes[j].Template == oldPath {
+				dependents[i].Includes[j].Template = newPath
+			}
+		}
+		if err := Update(db, &dependents[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// executeIncludes executes the included templates with the parameters given by the including template.
+func executeIncludes(wt *sdk.WorkflowTemplate, instance *sdk.WorkflowTemplateInstance, data map[string]interface{}, result *sdk.WorkflowTemplateResult, multiErr *sdk.MultiError) error {
+	if len(wt.Included) != len(wt.Includes) {
+		return sdk.WithStack(fmt.Errorf("included templates of template %s are not loaded", wt.Path()))
+	}
+
+	for i, inc := range wt.Includes {
+		included := wt.Included[i]
+
+		// parse the values of the parameters given to the included template
+		params := make(map[string]*template.Template, len(inc.Parameters))
+		var parseErr bool
+		for k, v := range inc.Parameters {
+			tmpl, err := parseTemplate("include", i, v)
+			if err != nil {
+				multiErr.Append(err)
+				parseErr = true
+				continue
+			}
+			params[k] = tmpl
+		}
+		if parseErr {
+			continue
+		}
+
+		var includedInstance *sdk.WorkflowTemplateInstance
+		if instance != nil {
+			req := sdk.WorkflowTemplateRequest{
+				ProjectKey:   instance.Request.ProjectKey,
+				WorkflowName: instance.Request.WorkflowName,
+				Parameters:   make(map[string]string, len(included.Parameters)),
+			}
+			for _, p := range included.Parameters {
+				if v, ok := instance.Request.Parameters[p.Key]; ok {
+					req.Parameters[p.Key] = v
+				}
+			}
+			for k, tmpl := range params {
+				v, err := executeTemplate(tmpl, data)
+				if err != nil {
+					return err
+				}
+				req.Parameters[k] = v
+			}
+			if err := included.CheckParams(req); err != nil {
+				return sdk.NewErrorFrom(sdk.ErrInvalidData, "invalid parameters for included template %s: %v", inc.Template, sdk.Cause(err))
+			}
+			includedInstance = &sdk.WorkflowTemplateInstance{
+				ID:      instance.ID,
+				Request: req,
+			}
+		}
+
+		res, err := Execute(&included, includedInstance)
+		if err != nil {
+			return sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot execute included template %s: %v", inc.Template, sdk.Cause(err)))
+		}
+		result.Pipelines = append(result.Pipelines, res.Pipelines...)
+		result.Applications = append(result.Applications, res.Applications...)
+		result.Environments = append(result.Environments, res.Environments...)
+	}
+
+	return nil
+}
@@ -0,0 +1,89 @@
+package workflowtemplate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflowtemplate"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestLoadIncludesAndUpdateDependents(t *testing.T) {
+	db, _, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	grp := assets.InsertTestGroup(t, db, sdk.RandomString(10))
+	defer assets.DeleteTestGroup(t, db, grp)
+
+	tmpls := []sdk.WorkflowTemplate{
+		{GroupID: grp.ID, Slug: "fragment", Name: "fragment"},
+		{GroupID: grp.ID, Slug: "build", Name: "build", Includes: sdk.WorkflowTemplateIncludes{{Template: grp.Name + "/fragment"}}},
+		{GroupID: grp.ID, Slug: "service", Name: "service", Includes: sdk.WorkflowTemplateIncludes{{Template: grp.Name + "/build"}}},
+	}
+	for i := range tmpls {
+		require.NoError(t, workflowtemplate.Insert(db, &tmpls[i]))
+		tmpls[i].Group = grp
+	}
+
+	require.NoError(t, workflowtemplate.LoadIncludes(context.TODO(), db, &tmpls[2]))
+	require.Len(t, tmpls[2].Included, 1)
+	assert.Equal(t, "build", tmpls[2].Included[0].Slug)
+	require.Len(t, tmpls[2].Included[0].Included, 1)
+	assert.Equal(t, "fragment", tmpls[2].Included[0].Included[0].Slug)
+
+	// the fragment can't include a template that already includes it
+	tmpls[0].Includes = sdk.WorkflowTemplateIncludes{{Template: grp.Name + "/service"}}
+	err := workflowtemplate.LoadIncludes(context.TODO(), db, &tmpls[0])
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle detected")
+
+	// all the templates that include the fragment are updated
+	require.NoError(t, workflowtemplate.UpdateDependents(context.TODO(), db, &tmpls[0], nil))
+	build, err := workflowtemplate.LoadByID(context.TODO(), db, tmpls[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, tmpls[1].Version+1, build.Version)
+	service, err := workflowtemplate.LoadByID(context.TODO(), db, tmpls[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, tmpls[2].Version+1, service.Version)
+}
+
+func TestCheckNotIncludedAndRenameIncludes(t *testing.T) {
+	db, _, end := test.SetupPG(t, bootstrap.InitiliazeDB)
+	defer end()
+
+	grp := assets.InsertTestGroup(t, db, sdk.RandomString(10))
+	defer assets.DeleteTestGroup(t, db, grp)
+
+	tmpls := []sdk.WorkflowTemplate{
+		{GroupID: grp.ID, Slug: "fragment", Name: "fragment"},
+		{GroupID: grp.ID, Slug: "build", Name: "build", Includes: sdk.WorkflowTemplateIncludes{{Template: grp.Name + "/fragment"}}},
+	}
+	for i := range tmpls {
+		require.NoError(t, workflowtemplate.Insert(db, &tmpls[i]))
+		tmpls[i].Group = grp
+	}
+
+	// the fragment is included by the build template
+	err := workflowtemplate.CheckNotIncluded(context.TODO(), db, &tmpls[0])
+	require.Error(t, err)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrForbidden))
+	assert.Contains(t, err.Error(), grp.Name+"/build")
+	assert.NoError(t, workflowtemplate.CheckNotIncluded(context.TODO(), db, &tmpls[1]))
+
+	// the includes follow the renamed fragment
+	tmpls[0].Slug = "fragment-renamed"
+	require.NoError(t, workflowtemplate.Update(db, &tmpls[0]))
+	require.NoError(t, workflowtemplate.RenameIncludes(context.TODO(), db, grp.Name+"/fragment", grp.Name+"/fragment-renamed"))
+	build, err := workflowtemplate.LoadByID(context.TODO(), db, tmpls[1].ID)
+	require.NoError(t, err)
+	require.Len(t, build.Includes, 1)
+	assert.Equal(t, grp.Name+"/fragment-renamed", build.Includes[0].Template)
+	require.NoError(t, workflowtemplate.LoadIncludes(context.TODO(), db, build))
+	assert.Equal(t, tmpls[0].ID, build.Included[0].ID)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"gopkg.in/olivere/elastic.v6"
@@ -132,6 +133,125 @@ func (s *Service) postMetricsHandler() service.Handler {
 	}
 }
 
+func (s *Service) getRunsHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if s.Cfg.ElasticSearch.IndexRuns == "" {
+			return sdk.WrapError(sdk.ErrNotFound, "getRunsHandler> No runs index found")
+		}
+
+		var f sdk.WorkflowRunSearchFilter
+		if err := service.UnmarshalBody(r, &f); err != nil {
+			return sdk.WrapError(err, "Unable to read request")
+		}
+
+		boolQuery := newRunsSearchQuery(f)
+
+		results, errR := esClient.Search().
+			Index(s.Cfg.ElasticSearch.IndexRuns).
+			Type(fmt.Sprintf("%T", sdk.WorkflowRunSearchDocument{})).
+			Query(boolQuery).
+			FetchSource(false).
+			Sort("start", false).
+			From(f.Offset).
+			Size(f.Limit).
+			Do(context.Background())
+		if errR != nil {
+			if strings.Contains(errR.Error(), indexNotFoundException) {
+				log.Warning(ctx, "elasticsearch> getRunsHandler> %v", errR.Error())
+				return service.WriteJSON(w, sdk.WorkflowRunSearchResult{IDs: []int64{}}, http.StatusOK)
+			}
+			return sdk.WrapError(errR, "Unable to get result")
+		}
+
+		res := sdk.WorkflowRunSearchResult{
+			Total: results.TotalHits(),
+			IDs:   make([]int64, 0, len(results.Hits.Hits)),
+		}
+		for _, h := range results.Hits.Hits {
+			id, err := strconv.ParseInt(h.Id, 10, 64)
+			if err != nil {
+				continue
+			}
+			res.IDs = append(res.IDs, id)
+		}
+		return service.WriteJSON(w, res, http.StatusOK)
+	}
+}
+
+// newRunsSearchQuery returns the query of the runs matching given filter.
+func newRunsSearchQuery(f sdk.WorkflowRunSearchFilter) *elastic.BoolQuery {
+	boolQuery := elastic.NewBoolQuery().Must(
+		elastic.NewTermQuery("project_key.keyword", f.ProjectKey),
+		elastic.NewTermQuery("workflow_name.keyword", f.WorkflowName),
+	)
+	if f.Commit != "" {
+		boolQuery.Must(elastic.NewPrefixQuery("commits.keyword", f.Commit))
+	}
+	if f.Author != "" {
+		boolQuery.Must(elastic.NewMatchQuery("authors", f.Author))
+	}
+	if f.Branch != "" {
+		boolQuery.Must(elastic.NewTermQuery("branches.keyword", f.Branch))
+	}
+	if f.Tag != "" {
+		boolQuery.Must(elastic.NewTermQuery("tags.keyword", f.Tag))
+	}
+	if len(f.Status) > 0 {
+		status := make([]interface{}, len(f.Status))
+		for i := range f.Status {
+			status[i] = f.Status[i]
+		}
+		boolQuery.Must(elastic.NewTermsQuery("status.keyword", status...))
+	}
+	if f.MinDuration > 0 || f.MaxDuration > 0 {
+		q := elastic.NewRangeQuery("duration")
+		if f.MinDuration > 0 {
+			q.Gte(f.MinDuration)
+		}
+		if f.MaxDuration > 0 {
+			q.Lte(f.MaxDuration)
+		}
+		boolQuery.Must(q)
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		q := elastic.NewRangeQuery("start")
+		if !f.From.IsZero() {
+			q.Gte(f.From)
+		}
+		if !f.To.IsZero() {
+			q.Lt(f.To)
+		}
+		boolQuery.Must(q)
+	}
+	if f.HookType != "" {
+		boolQuery.Must(elastic.NewTermQuery("hook_types.keyword", f.HookType))
+	}
+	if f.Text != "" {
+		boolQuery.Must(elastic.NewMatchPhraseQuery("logs", f.Text))
+	}
+	return boolQuery
+}
+
+func (s *Service) postRunHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if s.Cfg.ElasticSearch.IndexRuns == "" {
+			return sdk.WrapError(sdk.ErrNotFound, "postRunHandler> No runs index found")
+		}
+
+		var doc sdk.WorkflowRunSearchDocument
+		if err := service.UnmarshalBody(r, &doc); err != nil {
+			return sdk.WrapError(err, "Unable to read body")
+		}
+
+		// The run id is used as document id, so a restarted run replaces its previous document
+		_, errI := esClient.Index().Index(s.Cfg.ElasticSearch.IndexRuns).Id(strconv.FormatInt(doc.ID, 10)).Type(fmt.Sprintf("%T", sdk.WorkflowRunSearchDocument{})).BodyJson(doc).Do(context.Background())
+		if errI != nil {
+			return sdk.WrapError(errI, "Unable to insert run")
+		}
+		return nil
+	}
+}
+
 func (s *Service) getStatusHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		var status = http.StatusOK
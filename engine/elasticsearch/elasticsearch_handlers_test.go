@@ -0,0 +1,100 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/olivere/elastic.v6"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestNewRunsSearchQuery(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	src, err := newRunsSearchQuery(sdk.WorkflowRunSearchFilter{
+		ProjectKey:   "KEY",
+		WorkflowName: "wf",
+		Commit:       "abc",
+		Branch:       "master",
+		Status:       []string{sdk.StatusFail},
+		MinDuration:  10,
+		From:         from,
+		Text:         "100% done",
+	}).Source()
+	require.NoError(t, err)
+	btes, err := json.Marshal(src)
+	require.NoError(t, err)
+
+	expected := `{"bool":{"must":[
+		{"term":{"project_key.keyword":"KEY"}},
+		{"term":{"workflow_name.keyword":"wf"}},
+		{"prefix":{"commits.keyword":"abc"}},
+		{"term":{"branches.keyword":"master"}},
+		{"terms":{"status.keyword":["Fail"]}},
+		{"range":{"duration":{"from":10,"include_lower":true,"include_upper":true,"to":null}}},
+		{"range":{"start":{"from":"2020-01-01T00:00:00Z","include_lower":true,"include_upper":true,"to":null}}},
+		{"match_phrase":{"logs":{"query":"100% done"}}}
+	]}}`
+	assert.JSONEq(t, expected, string(btes))
+}
+
+func TestRunsHandlers(t *testing.T) {
+	var requests []string
+	es := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"hits":{"total":3,"hits":[{"_id":"3"},{"_id":"1"}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"_id":"1","result":"created"}`))
+	}))
+	defer es.Close()
+
+	var err error
+	esClient, err = elastic.NewClient(elastic.SetURL(es.URL), elastic.SetSniff(false), elastic.SetHealthcheck(false))
+	require.NoError(t, err)
+	defer func() { esClient = nil }()
+
+	s := New()
+	s.Cfg.ElasticSearch.IndexRuns = "runs"
+
+	// Index a run
+	btes, err := json.Marshal(sdk.WorkflowRunSearchDocument{ID: 1, ProjectKey: "KEY", WorkflowName: "wf"})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/runs", bytes.NewReader(btes))
+	require.NoError(t, s.postRunHandler()(context.TODO(), httptest.NewRecorder(), req))
+	require.Len(t, requests, 1)
+	assert.Contains(t, requests[0], "PUT /runs/sdk.WorkflowRunSearchDocument/1 ")
+
+	// Search runs
+	btes, err = json.Marshal(sdk.WorkflowRunSearchFilter{ProjectKey: "KEY", WorkflowName: "wf", Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	req = httptest.NewRequest(http.MethodGet, "/runs", bytes.NewReader(btes))
+	rec := httptest.NewRecorder()
+	require.NoError(t, s.getRunsHandler()(context.TODO(), rec, req))
+	require.Len(t, requests, 2)
+	assert.Contains(t, requests[1], "POST /runs/sdk.WorkflowRunSearchDocument/_search ")
+	assert.Contains(t, requests[1], `"from":1`)
+	assert.Contains(t, requests[1], `"size":2`)
+
+	var res sdk.WorkflowRunSearchResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, int64(3), res.Total)
+	assert.Equal(t, []int64{3, 1}, res.IDs)
+
+	// Runs can't be searched without index
+	s.Cfg.ElasticSearch.IndexRuns = ""
+	req = httptest.NewRequest(http.MethodGet, "/runs", bytes.NewReader(btes))
+	err = s.getRunsHandler()(context.TODO(), httptest.NewRecorder(), req)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrNotFound))
+}
@@ -22,4 +22,5 @@ func (s *Service) initRouter(ctx context.Context) {
 	r.Handle("/mon/metrics/all", nil, r.GET(service.GetMetricsHandler, api.Auth(false)))
 	r.Handle("/events", nil, r.GET(s.getEventsHandler), r.POST(s.postEventHandler))
 	r.Handle("/metrics", nil, r.GET(s.getMetricsHandler), r.POST(s.postMetricsHandler))
+	r.Handle("/runs", nil, r.GET(s.getRunsHandler), r.POST(s.postRunHandler))
 }
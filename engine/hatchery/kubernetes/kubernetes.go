@@ -226,8 +226,12 @@ func (h *HatcheryKubernetes) WorkerModelsEnabled() ([]sdk.Model, error) {
 }
 
 // CanSpawn return wether or not hatchery can spawn model.
-// requirements are not supported
+// pod options set on model requirement are checked
 func (h *HatcheryKubernetes) CanSpawn(ctx context.Context, model *sdk.Model, jobID int64, requirements []sdk.Requirement) bool {
+	if _, err := h.computePodOpts(requirements); err != nil {
+		log.Debug("CanSpawn> job %d has invalid pod options: %v", jobID, err)
+		return false
+	}
 	return true
 }
 
@@ -254,6 +258,11 @@ func (h *HatcheryKubernetes) SpawnWorker(ctx context.Context, spawnArgs hatchery
 		logJob = fmt.Sprintf("for workflow job %d,", spawnArgs.JobID)
 	}
 
+	podOpts, err := h.computePodOpts(spawnArgs.Requirements)
+	if err != nil {
+		return sdk.WrapError(err, "cannot compute pod options %s", logJob)
+	}
+
 	memory := int64(h.Config.DefaultMemory)
 	for _, r := range spawnArgs.Requirements {
 		if r.Type == sdk.MemoryRequirement {
@@ -427,7 +436,16 @@ func (h *HatcheryKubernetes) SpawnWorker(ctx context.Context, spawnArgs hatchery
 		podSchema.Spec.HostAliases[0].Hostnames[i+1] = strings.ToLower(serv.Name)
 	}
 
-	_, err := h.k8sClient.CoreV1().Pods(h.Config.Namespace).Create(&podSchema)
+	if spawnArgs.Model.ModelDocker.PodTemplate != "" {
+		tmpl, err := parsePodTemplate(spawnArgs.Model.ModelDocker.PodTemplate)
+		if err != nil {
+			return sdk.WrapError(err, "cannot parse pod template of model %s", spawnArgs.Model.Name)
+		}
+		applyPodTemplate(&podSchema, *tmpl)
+	}
+	podOpts.apply(&podSchema)
+
+	_, err = h.k8sClient.CoreV1().Pods(h.Config.Namespace).Create(&podSchema)
 
 	log.Debug("hatchery> kubernetes> SpawnWorker> %s > Pod created", spawnArgs.WorkerName)
 
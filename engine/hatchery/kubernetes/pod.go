@@ -0,0 +1,254 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// workerContainerName is the name of the container of a pod template used as base for the worker container.
+const workerContainerName = "worker"
+
+type podOpts struct {
+	cpu              *resource.Quantity
+	ephemeralStorage *resource.Quantity
+	nodeSelector     map[string]string
+	nodeAffinity     []apiv1.NodeSelectorRequirement
+	tolerations      []apiv1.Toleration
+	serviceAccount   string
+}
+
+func (h *HatcheryKubernetes) computePodOpts(requirements []sdk.Requirement) (*podOpts, error) {
+	opts := &podOpts{}
+	for _, r := range requirements {
+		if r.Type != sdk.ModelRequirement {
+			continue
+		}
+		if err := h.computePodOptsOnModelRequirement(opts, r); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+func (h *HatcheryKubernetes) computePodOptsOnModelRequirement(o *podOpts, req sdk.Requirement) error {
+	// args are separated by a space
+	// example: myGroup/golang:1.9.1 --cpu=2 --node-selector=pool=highmem
+	for idx, opt := range strings.Split(req.Value, " ") {
+		if idx == 0 || strings.TrimSpace(opt) == "" {
+			continue // it's model name
+		}
+
+		if h.Config.DisablePodOptsOnRequirements {
+			return fmt.Errorf("you could not use this pod options '%s' with a 'shared.infra' hatchery. Please use you own hatchery or remove this option", opt)
+		}
+
+		var err error
+		switch {
+		case strings.HasPrefix(opt, "--cpu="):
+			o.cpu, err = parsePodOptsQuantity(strings.TrimPrefix(opt, "--cpu="))
+		case strings.HasPrefix(opt, "--ephemeral-storage="):
+			o.ephemeralStorage, err = parsePodOptsQuantity(strings.TrimPrefix(opt, "--ephemeral-storage="))
+		case strings.HasPrefix(opt, "--node-selector="):
+			err = o.computePodOptsNodeSelector(strings.TrimPrefix(opt, "--node-selector="))
+		case strings.HasPrefix(opt, "--node-affinity="):
+			err = o.computePodOptsNodeAffinity(strings.TrimPrefix(opt, "--node-affinity="))
+		case strings.HasPrefix(opt, "--toleration="):
+			err = o.computePodOptsToleration(strings.TrimPrefix(opt, "--toleration="))
+		case strings.HasPrefix(opt, "--service-account="):
+			o.serviceAccount = strings.TrimPrefix(opt, "--service-account=")
+			if o.serviceAccount == "" {
+				err = fmt.Errorf("Invalid service account option: %s", opt)
+			}
+		default:
+			err = fmt.Errorf("Options not supported: %s", opt)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parsePodOptsQuantity(s string) (*resource.Quantity, error) {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid quantity %s: %v", s, err)
+	}
+	return &q, nil
+}
+
+// computePodOptsNodeSelector parses a node selector, example: pool=highmem
+func (o *podOpts) computePodOptsNodeSelector(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return fmt.Errorf("Invalid node selector option. Example:pool=highmem current:%s", s)
+	}
+	if o.nodeSelector == nil {
+		o.nodeSelector = map[string]string{}
+	}
+	o.nodeSelector[kv[0]] = kv[1]
+	return nil
+}
+
+// computePodOptsNodeAffinity parses a required node affinity, examples:
+// pool=highmem,bigmem (In), pool!=gpu (NotIn), gpu (Exists), !gpu (DoesNotExist)
+func (o *podOpts) computePodOptsNodeAffinity(s string) error {
+	var r apiv1.NodeSelectorRequirement
+	switch {
+	case strings.Contains(s, "!="):
+		kv := strings.SplitN(s, "!=", 2)
+		r = apiv1.NodeSelectorRequirement{Key: kv[0], Operator: apiv1.NodeSelectorOpNotIn, Values: strings.Split(kv[1], ",")}
+	case strings.Contains(s, "="):
+		kv := strings.SplitN(s, "=", 2)
+		r = apiv1.NodeSelectorRequirement{Key: kv[0], Operator: apiv1.NodeSelectorOpIn, Values: strings.Split(kv[1], ",")}
+	case strings.HasPrefix(s, "!"):
+		r = apiv1.NodeSelectorRequirement{Key: strings.TrimPrefix(s, "!"), Operator: apiv1.NodeSelectorOpDoesNotExist}
+	default:
+		r = apiv1.NodeSelectorRequirement{Key: s, Operator: apiv1.NodeSelectorOpExists}
+	}
+	if r.Key == "" {
+		return fmt.Errorf("Invalid node affinity option. Example:pool=highmem,bigmem current:%s", s)
+	}
+	for _, v := range r.Values {
+		if v == "" {
+			return fmt.Errorf("Invalid node affinity option. Example:pool=highmem,bigmem current:%s", s)
+		}
+	}
+	o.nodeAffinity = append(o.nodeAffinity, r)
+	return nil
+}
+
+// computePodOptsToleration parses a toleration with the same syntax as a taint, examples:
+// dedicated=cds:NoSchedule (Equal), dedicated:NoSchedule (Exists), dedicated=cds (Equal for all effects)
+func (o *podOpts) computePodOptsToleration(s string) error {
+	var t apiv1.Toleration
+	keyValue := s
+	if i := strings.LastIndex(s, ":"); i >= 0 {
+		keyValue = s[:i]
+		t.Effect = apiv1.TaintEffect(s[i+1:])
+		switch t.Effect {
+		case apiv1.TaintEffectNoSchedule, apiv1.TaintEffectPreferNoSchedule, apiv1.TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("Invalid toleration effect %s, should be %s, %s or %s", t.Effect, apiv1.TaintEffectNoSchedule, apiv1.TaintEffectPreferNoSchedule, apiv1.TaintEffectNoExecute)
+		}
+	}
+	if kv := strings.SplitN(keyValue, "=", 2); len(kv) == 2 {
+		t.Key, t.Operator, t.Value = kv[0], apiv1.TolerationOpEqual, kv[1]
+	} else {
+		t.Key, t.Operator = keyValue, apiv1.TolerationOpExists
+	}
+	if t.Key == "" {
+		return fmt.Errorf("Invalid toleration option. Example:dedicated=cds:NoSchedule current:%s", s)
+	}
+	o.tolerations = append(o.tolerations, t)
+	return nil
+}
+
+// apply sets the options on the pod, the first container of the pod is the worker container.
+func (o *podOpts) apply(pod *apiv1.Pod) {
+	worker := &pod.Spec.Containers[0]
+	if o.cpu != nil {
+		worker.Resources.Requests[apiv1.ResourceCPU] = *o.cpu
+	}
+	if o.ephemeralStorage != nil {
+		worker.Resources.Requests[apiv1.ResourceEphemeralStorage] = *o.ephemeralStorage
+	}
+
+	if len(o.nodeSelector) > 0 && pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = map[string]string{}
+	}
+	for k, v := range o.nodeSelector {
+		pod.Spec.NodeSelector[k] = v
+	}
+
+	if len(o.nodeAffinity) > 0 {
+		if pod.Spec.Affinity == nil {
+			pod.Spec.Affinity = &apiv1.Affinity{}
+		}
+		if pod.Spec.Affinity.NodeAffinity == nil {
+			pod.Spec.Affinity.NodeAffinity = &apiv1.NodeAffinity{}
+		}
+		na := pod.Spec.Affinity.NodeAffinity
+		if na.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+			na.RequiredDuringSchedulingIgnoredDuringExecution = &apiv1.NodeSelector{}
+		}
+		terms := &na.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		// Node selector terms are ORed, so requirements are added to each term of the template
+		if len(*terms) == 0 {
+			*terms = []apiv1.NodeSelectorTerm{{}}
+		}
+		for i := range *terms {
+			(*terms)[i].MatchExpressions = append((*terms)[i].MatchExpressions, o.nodeAffinity...)
+		}
+	}
+
+	pod.Spec.Tolerations = append(pod.Spec.Tolerations, o.tolerations...)
+
+	if o.serviceAccount != "" {
+		pod.Spec.ServiceAccountName = o.serviceAccount
+	}
+}
+
+// parsePodTemplate parses a pod template given in yaml or json on a worker model, the template is also
+// checked by the API when the worker model is saved.
+func parsePodTemplate(s string) (*apiv1.PodTemplateSpec, error) {
+	var tmpl apiv1.PodTemplateSpec
+	if err := yaml.NewYAMLOrJSONDecoder(strings.NewReader(s), 4096).Decode(&tmpl); err != nil {
+		return nil, sdk.WithStack(fmt.Errorf("invalid pod template: %v", err))
+	}
+	return &tmpl, nil
+}
+
+// applyPodTemplate uses the pod template as base for the pod. The fields set by the hatchery
+// override the ones of the template, the container named 'worker' of the template is used as base
+// for the worker container and the other containers are added to the pod.
+func applyPodTemplate(pod *apiv1.Pod, tmpl apiv1.PodTemplateSpec) {
+	spec := tmpl.Spec
+	spec.RestartPolicy = pod.Spec.RestartPolicy
+	spec.TerminationGracePeriodSeconds = pod.Spec.TerminationGracePeriodSeconds
+	spec.HostAliases = append(spec.HostAliases, pod.Spec.HostAliases...)
+	spec.ImagePullSecrets = append(spec.ImagePullSecrets, pod.Spec.ImagePullSecrets...)
+
+	containers := make([]apiv1.Container, 0, len(pod.Spec.Containers)+len(tmpl.Spec.Containers))
+	containers = append(containers, pod.Spec.Containers...)
+	for _, c := range tmpl.Spec.Containers {
+		if c.Name != workerContainerName {
+			containers = append(containers, c)
+			continue
+		}
+		worker := &containers[0]
+		base := c
+		base.Name = worker.Name
+		base.Image = worker.Image
+		base.Command = worker.Command
+		base.Args = worker.Args
+		base.Env = append(base.Env, worker.Env...)
+		if base.Resources.Requests == nil {
+			base.Resources.Requests = apiv1.ResourceList{}
+		}
+		for k, v := range worker.Resources.Requests {
+			base.Resources.Requests[k] = v
+		}
+		*worker = base
+	}
+	spec.Containers = containers
+	pod.Spec = spec
+
+	for k, v := range tmpl.Labels {
+		if _, ok := pod.Labels[k]; !ok {
+			pod.Labels[k] = v
+		}
+	}
+	if len(tmpl.Annotations) > 0 && pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	for k, v := range tmpl.Annotations {
+		pod.Annotations[k] = v
+	}
+}
@@ -0,0 +1,207 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/hatchery"
+)
+
+func TestHatcheryKubernetes_computePodOpts(t *testing.T) {
+	cpu := resource.MustParse("500m")
+	storage := resource.MustParse("2Gi")
+
+	tests := []struct {
+		name         string
+		requirements []sdk.Requirement
+		disabled     bool
+		want         *podOpts
+		wantErr      bool
+	}{
+		{
+			name:         "no option",
+			requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "shared.infra/golang"}},
+			want:         &podOpts{},
+		},
+		{
+			name: "all options",
+			requirements: []sdk.Requirement{
+				{Type: sdk.MemoryRequirement, Value: "4096"},
+				{Type: sdk.ModelRequirement, Value: "shared.infra/golang --cpu=500m --ephemeral-storage=2Gi --node-selector=pool=highmem --node-affinity=zone=a,b --node-affinity=!gpu --toleration=dedicated=cds:NoSchedule --toleration=highmem --service-account=cds-worker"},
+			},
+			want: &podOpts{
+				cpu:              &cpu,
+				ephemeralStorage: &storage,
+				nodeSelector:     map[string]string{"pool": "highmem"},
+				nodeAffinity: []apiv1.NodeSelectorRequirement{
+					{Key: "zone", Operator: apiv1.NodeSelectorOpIn, Values: []string{"a", "b"}},
+					{Key: "gpu", Operator: apiv1.NodeSelectorOpDoesNotExist},
+				},
+				tolerations: []apiv1.Toleration{
+					{Key: "dedicated", Operator: apiv1.TolerationOpEqual, Value: "cds", Effect: apiv1.TaintEffectNoSchedule},
+					{Key: "highmem", Operator: apiv1.TolerationOpExists},
+				},
+				serviceAccount: "cds-worker",
+			},
+		},
+		{
+			name:         "invalid cpu",
+			requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "shared.infra/golang --cpu=two"}},
+			wantErr:      true,
+		},
+		{
+			name:         "invalid toleration effect",
+			requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "shared.infra/golang --toleration=dedicated=cds:Never"}},
+			wantErr:      true,
+		},
+		{
+			name:         "invalid node selector",
+			requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "shared.infra/golang --node-selector=highmem"}},
+			wantErr:      true,
+		},
+		{
+			name:         "unsupported option",
+			requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "shared.infra/golang --privileged"}},
+			wantErr:      true,
+		},
+		{
+			name:         "options disabled",
+			requirements: []sdk.Requirement{{Type: sdk.ModelRequirement, Value: "shared.infra/golang --cpu=1"}},
+			disabled:     true,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &HatcheryKubernetes{}
+			h.Config.DisablePodOptsOnRequirements = tt.disabled
+			got, err := h.computePodOpts(tt.requirements)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHatcheryKubernetes_SpawnWorkerWithPodOpts(t *testing.T) {
+	h := &HatcheryKubernetes{k8sClient: fake.NewSimpleClientset()}
+	h.Config.Name = "kyubi"
+	h.Config.Namespace = "hachibi"
+	h.Config.DefaultMemory = 1024
+
+	m := sdk.Model{
+		Name:  "model1",
+		Group: &sdk.Group{Name: sdk.SharedInfraGroupName},
+		ModelDocker: sdk.ModelDocker{
+			Image: "model:9",
+			Shell: "sh -c",
+			Cmd:   "./worker",
+			PodTemplate: `metadata:
+  labels:
+    team: ci
+  annotations:
+    cluster-autoscaler.kubernetes.io/safe-to-evict: "false"
+spec:
+  priorityClassName: ci
+  nodeSelector:
+    disk: ssd
+  affinity:
+    nodeAffinity:
+      requiredDuringSchedulingIgnoredDuringExecution:
+        nodeSelectorTerms:
+        - matchExpressions:
+          - key: arch
+            operator: In
+            values: [amd64]
+  containers:
+  - name: worker
+    image: ignored
+    resources:
+      limits:
+        memory: 8Gi
+    volumeMounts:
+    - name: cache
+      mountPath: /cache
+  - name: proxy
+    image: envoy
+  volumes:
+  - name: cache
+    emptyDir: {}
+`,
+		},
+	}
+
+	err := h.SpawnWorker(context.TODO(), hatchery.SpawnArguments{
+		JobID:      666,
+		Model:      &m,
+		WorkerName: "model1-worker",
+		Requirements: []sdk.Requirement{{
+			Type:  sdk.ModelRequirement,
+			Value: "shared.infra/model1 --cpu=2 --ephemeral-storage=10Gi --node-selector=pool=highmem --node-affinity=zone=a --toleration=highmem:NoSchedule --service-account=cds-worker",
+		}},
+	})
+	require.NoError(t, err)
+
+	pod, err := h.k8sClient.CoreV1().Pods("hachibi").Get("model1-worker", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "ci", pod.Labels["team"])
+	assert.Equal(t, "kyubi", pod.Labels[LABEL_HATCHERY_NAME])
+	assert.Equal(t, "false", pod.Annotations["cluster-autoscaler.kubernetes.io/safe-to-evict"])
+
+	assert.Equal(t, "ci", pod.Spec.PriorityClassName)
+	assert.Equal(t, apiv1.RestartPolicyNever, pod.Spec.RestartPolicy)
+	assert.Equal(t, "cds-worker", pod.Spec.ServiceAccountName)
+	assert.Equal(t, map[string]string{"disk": "ssd", "pool": "highmem"}, pod.Spec.NodeSelector)
+	assert.Equal(t, []apiv1.Toleration{{Key: "highmem", Operator: apiv1.TolerationOpExists, Effect: apiv1.TaintEffectNoSchedule}}, pod.Spec.Tolerations)
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, []apiv1.NodeSelectorRequirement{
+		{Key: "arch", Operator: apiv1.NodeSelectorOpIn, Values: []string{"amd64"}},
+		{Key: "zone", Operator: apiv1.NodeSelectorOpIn, Values: []string{"a"}},
+	}, terms[0].MatchExpressions)
+	require.Len(t, pod.Spec.Volumes, 1)
+
+	require.Len(t, pod.Spec.Containers, 2)
+	worker := pod.Spec.Containers[0]
+	assert.Equal(t, "model1-worker", worker.Name)
+	assert.Equal(t, "model:9", worker.Image)
+	assert.Equal(t, []string{"sh", "-c"}, worker.Command)
+	require.Len(t, worker.VolumeMounts, 1)
+	assert.Equal(t, "/cache", worker.VolumeMounts[0].MountPath)
+	cpu := worker.Resources.Requests[apiv1.ResourceCPU]
+	assert.Equal(t, "2", cpu.String())
+	storage := worker.Resources.Requests[apiv1.ResourceEphemeralStorage]
+	assert.Equal(t, "10Gi", storage.String())
+	limit := worker.Resources.Limits[apiv1.ResourceMemory]
+	assert.Equal(t, "8Gi", limit.String())
+	assert.Equal(t, "proxy", pod.Spec.Containers[1].Name)
+}
+
+func TestHatcheryKubernetes_SpawnWorkerWithInvalidPodTemplate(t *testing.T) {
+	h := &HatcheryKubernetes{k8sClient: fake.NewSimpleClientset()}
+	h.Config.Namespace = "hachibi"
+
+	m := sdk.Model{
+		Name:        "model1",
+		Group:       &sdk.Group{Name: sdk.SharedInfraGroupName},
+		ModelDocker: sdk.ModelDocker{Image: "model:9", Cmd: "./worker", PodTemplate: "spec: [invalid"},
+	}
+	err := h.SpawnWorker(context.TODO(), hatchery.SpawnArguments{JobID: 666, Model: &m, WorkerName: "model1-worker"})
+	require.Error(t, err)
+
+	list, err := h.k8sClient.CoreV1().Pods("hachibi").List(metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, list.Items, 0)
+}
@@ -33,6 +33,8 @@ type HatcheryConfiguration struct {
 	DefaultMemory int `mapstructure:"defaultMemory" toml:"defaultMemory" default:"1024" commented:"false" comment:"Worker default memory in Mo" json:"defaultMemory"`
 	// Namespace is the kubernetes namespace in which workers are spawned"
 	Namespace string `mapstructure:"namespace" toml:"namespace" default:"cds" commented:"false" comment:"Kubernetes namespace in which workers are spawned" json:"namespace"`
+	// DisablePodOptsOnRequirements disables pod options set on model requirements
+	DisablePodOptsOnRequirements bool `mapstructure:"disablePodOptsOnRequirements" toml:"disablePodOptsOnRequirements" default:"" commented:"true" comment:"disable pod options (--cpu, --ephemeral-storage, --node-selector, --node-affinity, --toleration, --service-account) on model requirements" json:"disablePodOptsOnRequirements"`
 	// KubernetesMasterURL Address of kubernetes master
 	KubernetesMasterURL string `mapstructure:"kubernetesMasterURL" toml:"kubernetesMasterURL" default:"" commented:"false" comment:"Address of kubernetes master" json:"kubernetesMasterURL"`
 	// KubernetesConfigFile Kubernetes config file in yaml
@@ -60,7 +62,7 @@ type HatcheryKubernetes struct {
 	client    cdsclient.Interface
 	os        string
 	arch      string
-	k8sClient *kubernetes.Clientset
+	k8sClient kubernetes.Interface
 }
 
 type workerCmd struct {
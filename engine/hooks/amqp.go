@@ -0,0 +1,169 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/go-amqp"
+	"github.com/fsamin/go-dump"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// amqpConsumers stores the cancel func of the consumer of each started amqp hook by task uuid, it's called when the task is stopped
+var amqpConsumers sync.Map
+
+func (s *Service) startAMQPHook(ctx context.Context, t *sdk.Task) error {
+	projectKey := t.Config[sdk.HookConfigProject].Value
+	integrationName := t.Config[sdk.HookModelIntegration].Value
+	pf, err := s.Client.ProjectIntegrationGet(projectKey, integrationName, true)
+	if err != nil {
+		_ = s.stopTask(ctx, t)
+		return sdk.WrapError(err, "Cannot get amqp configuration for %s/%s", projectKey, integrationName)
+	}
+
+	address := t.Config[sdk.AMQPHookModelAddress].Value
+	if address == "" {
+		_ = s.stopTask(ctx, t)
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "missing address")
+	}
+
+	// The first connection is checked, then the consumer reconnects until the task is stopped
+	client, receiver, err := amqpReceive(pf.Config, t.UUID, address)
+	if err != nil {
+		_ = s.stopTask(ctx, t)
+		return fmt.Errorf("startAMQPHook> Error while receiving from %s on %s: %v", address, pf.Config["url"].Value, err)
+	}
+
+	consumerCtx, cancel := context.WithCancel(context.Background())
+	if old, loaded := amqpConsumers.Load(t.UUID); loaded {
+		old.(context.CancelFunc)()
+	}
+	amqpConsumers.Store(t.UUID, cancel)
+
+	go func() {
+		for {
+			err := amqpConsume(consumerCtx, receiver, func(msg *amqp.Message) {
+				exec := sdk.TaskExecution{
+					Status:    TaskExecutionScheduled,
+					Config:    t.Config,
+					Type:      TypeAMQP,
+					UUID:      t.UUID,
+					Timestamp: time.Now().UnixNano(),
+					AMQP:      &sdk.AMQPTaskExecution{Address: address, Message: msg.GetData()},
+				}
+				s.Dao.SaveTaskExecution(&exec)
+			})
+			client.Close() // nolint
+			if consumerCtx.Err() != nil {
+				return
+			}
+			log.Warning(consumerCtx, "Hooks> Unable to receive amqp messages from %s for %s: %v", address, t.UUID, err)
+
+			for {
+				time.Sleep(5 * time.Second)
+				if consumerCtx.Err() != nil {
+					return
+				}
+				client, receiver, err = amqpReceive(pf.Config, t.UUID, address)
+				if err == nil {
+					break
+				}
+				log.Warning(consumerCtx, "Hooks> Unable to reconnect to amqp %s for %s: %v", pf.Config["url"].Value, t.UUID, err)
+			}
+		}
+	}()
+	return nil
+}
+
+func stopAMQPHook(t *sdk.Task) {
+	if cancel, loaded := amqpConsumers.Load(t.UUID); loaded {
+		amqpConsumers.Delete(t.UUID)
+		cancel.(context.CancelFunc)()
+	}
+}
+
+// amqpReceive opens a receiver link on the address of an amqp hook.
+func amqpReceive(config sdk.IntegrationConfig, uuid, address string) (*amqp.Client, *amqp.Receiver, error) {
+	opts := []amqp.ConnOption{amqp.ConnContainerID("cds-hooks-" + uuid)}
+	if config["username"].Value != "" {
+		opts = append(opts, amqp.ConnSASLPlain(config["username"].Value, config["password"].Value))
+	}
+	client, err := amqp.Dial(config["url"].Value, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := client.NewSession()
+	if err != nil {
+		client.Close() // nolint
+		return nil, nil, err
+	}
+	receiver, err := session.NewReceiver(amqp.LinkSourceAddress(address), amqp.LinkCredit(10))
+	if err != nil {
+		client.Close() // nolint
+		return nil, nil, err
+	}
+	return client, receiver, nil
+}
+
+// amqpConsume calls the handler for each message received on the link, a message is accepted once handled so
+// messages sent while the hook is stopped are kept by the broker. It returns when the context is canceled or
+// the link is detached.
+func amqpConsume(ctx context.Context, receiver *amqp.Receiver, handler func(*amqp.Message)) error {
+	for {
+		msg, err := receiver.Receive(ctx)
+		if err != nil {
+			return err
+		}
+		handler(msg)
+		if err := msg.Accept(); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Service) doAMQPTaskExecution(t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error) {
+	log.Debug("Hooks> Processing amqp %s %s", t.UUID, t.Type)
+
+	// Prepare a struct to send to CDS API
+	h := sdk.WorkflowNodeRunHookEvent{
+		WorkflowNodeHookUUID: t.UUID,
+		Payload:              map[string]string{},
+	}
+
+	var bodyJSON interface{}
+
+	//Try to parse the body as an array
+	bodyJSONArray := []interface{}{}
+	if err := json.Unmarshal(t.AMQP.Message, &bodyJSONArray); err != nil {
+		//Try to parse the body as a map
+		bodyJSONMap := map[string]interface{}{}
+		if err2 := json.Unmarshal(t.AMQP.Message, &bodyJSONMap); err2 == nil {
+			bodyJSON = bodyJSONMap
+		}
+	} else {
+		bodyJSON = bodyJSONArray
+	}
+
+	//Go Dump
+	e := dump.NewDefaultEncoder()
+	e.Formatters = []dump.KeyFormatterFunc{dump.WithDefaultLowerCaseFormatter()}
+	e.ExtraFields.DetailedMap = false
+	e.ExtraFields.DetailedStruct = false
+	e.ExtraFields.DeepJSON = true
+	e.ExtraFields.Len = false
+	e.ExtraFields.Type = false
+	m, err := e.ToStringMap(bodyJSON)
+	if err != nil {
+		return nil, sdk.WrapError(err, "Unable to dump body %s", t.AMQP.Message)
+	}
+	h.Payload = m
+	h.Payload["payload"] = string(t.AMQP.Message)
+	h.Payload["cds.amqp.address"] = t.AMQP.Address
+
+	return &h, nil
+}
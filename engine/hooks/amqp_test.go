@@ -0,0 +1,108 @@
+package hooks
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/sdk"
+)
+
+/*
+  To run the AMQP 1.0 integration tests, put the configuration of a broker in the $HOME/.cds/tests.cfg.json file:
+	"amqpURL": "amqp://localhost:5672",
+	"amqpUsername": "",
+	"amqpPassword": "",
+	"amqpAddress": ""
+
+  If amqpURL is not set, the tests are skipped
+*/
+
+func loadAMQPTestingConf(t *testing.T) (sdk.IntegrationConfig, string) {
+	cfg := test.LoadTestingConf(t)
+	if cfg["amqpURL"] == "" {
+		t.SkipNow()
+	}
+	address := cfg["amqpAddress"]
+	if address == "" {
+		address = "cds.tests." + sdk.RandomString(10)
+	}
+	return sdk.IntegrationConfig{
+		"url":      {Value: cfg["amqpURL"]},
+		"username": {Value: cfg["amqpUsername"]},
+		"password": {Value: cfg["amqpPassword"]},
+	}, address
+}
+
+func Test_amqpReceiveUnreachable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "amqp://" + l.Addr().String()
+	require.NoError(t, l.Close())
+
+	_, _, err = amqpReceive(sdk.IntegrationConfig{"url": {Value: url}}, "my-hook", "cds.deployments")
+	assert.Error(t, err)
+}
+
+func Test_doAMQPTaskExecution(t *testing.T) {
+	s := Service{}
+	h, err := s.doAMQPTaskExecution(&sdk.TaskExecution{
+		UUID: sdk.RandomString(10),
+		Type: TypeAMQP,
+		AMQP: &sdk.AMQPTaskExecution{
+			Address: "cds.deployments",
+			Message: []byte(`{"application":{"name":"my-app","version":"1.0.0"}}`),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "my-app", h.Payload["application.name"])
+	assert.Equal(t, "1.0.0", h.Payload["application.version"])
+	assert.Equal(t, "cds.deployments", h.Payload["cds.amqp.address"])
+	assert.Equal(t, `{"application":{"name":"my-app","version":"1.0.0"}}`, h.Payload["payload"])
+}
+
+func Test_amqpReceiveAndConsume(t *testing.T) {
+	config, address := loadAMQPTestingConf(t)
+
+	client, receiver, err := amqpReceive(config, sdk.RandomString(10), address)
+	require.NoError(t, err)
+	defer client.Close() // nolint
+
+	// Send a message on the address of the hook
+	opts := []amqp.ConnOption{}
+	if config["username"].Value != "" {
+		opts = append(opts, amqp.ConnSASLPlain(config["username"].Value, config["password"].Value))
+	}
+	pub, err := amqp.Dial(config["url"].Value, opts...)
+	require.NoError(t, err)
+	defer pub.Close() // nolint
+	session, err := pub.NewSession()
+	require.NoError(t, err)
+	sender, err := session.NewSender(amqp.LinkTargetAddress(address))
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, sender.Send(ctx, amqp.NewMessage([]byte(`{"version":"1.0.0"}`))))
+
+	// The message is handled then accepted, the consumer returns when the context is canceled
+	received := make(chan []byte, 10)
+	consumed := make(chan error)
+	go func() {
+		consumed <- amqpConsume(ctx, receiver, func(msg *amqp.Message) { received <- msg.GetData() })
+	}()
+
+	select {
+	case data := <-received:
+		assert.Equal(t, `{"version":"1.0.0"}`, string(data))
+	case <-ctx.Done():
+		t.Fatal("no message received")
+	}
+	cancel()
+	assert.Error(t, <-consumed)
+}
@@ -0,0 +1,162 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsamin/go-dump"
+	"github.com/nats-io/nats.go"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// natsConnections stores the connection of each started nats hook by task uuid, it's closed when the task is stopped
+var natsConnections sync.Map
+
+func (s *Service) startNATSHook(ctx context.Context, t *sdk.Task) error {
+	projectKey := t.Config[sdk.HookConfigProject].Value
+	integrationName := t.Config[sdk.HookModelIntegration].Value
+	pf, err := s.Client.ProjectIntegrationGet(projectKey, integrationName, true)
+	if err != nil {
+		_ = s.stopTask(ctx, t)
+		return sdk.WrapError(err, "Cannot get nats configuration for %s/%s", projectKey, integrationName)
+	}
+
+	nc, err := natsConnect(pf.Config, t.UUID)
+	if err != nil {
+		_ = s.stopTask(ctx, t)
+		return fmt.Errorf("startNATSHook> Error while connecting to %s: %v", pf.Config["url"].Value, err)
+	}
+
+	if err := natsSubscribe(nc, t.UUID, t.Config, func(msg *nats.Msg) {
+		exec := sdk.TaskExecution{
+			Status:    TaskExecutionScheduled,
+			Config:    t.Config,
+			Type:      TypeNATS,
+			UUID:      t.UUID,
+			Timestamp: time.Now().UnixNano(),
+			NATS:      &sdk.NATSTaskExecution{Subject: msg.Subject, Message: msg.Data},
+		}
+		s.Dao.SaveTaskExecution(&exec)
+	}); err != nil {
+		nc.Close()
+		_ = s.stopTask(ctx, t)
+		return fmt.Errorf("startNATSHook> Error while subscribing to %s: %v", t.Config[sdk.NATSHookModelSubject].Value, err)
+	}
+
+	if old, loaded := natsConnections.Load(t.UUID); loaded {
+		old.(*nats.Conn).Close()
+	}
+	natsConnections.Store(t.UUID, nc)
+	return nil
+}
+
+func stopNATSHook(t *sdk.Task) {
+	if nc, loaded := natsConnections.Load(t.UUID); loaded {
+		natsConnections.Delete(t.UUID)
+		nc.(*nats.Conn).Close()
+	}
+}
+
+func natsConnect(config sdk.IntegrationConfig, uuid string) (*nats.Conn, error) {
+	opts := []nats.Option{nats.Name("cds-hooks-" + uuid), nats.MaxReconnects(-1)}
+	if config["username"].Value != "" {
+		opts = append(opts, nats.UserInfo(config["username"].Value, config["password"].Value))
+	}
+	return nats.Connect(config["url"].Value, opts...)
+}
+
+// natsSubscribe calls the handler for each message published on the subject of a nats hook.
+// Without durable consumer, it's a core NATS subscription in a queue group, by default the hook uuid, so a message
+// is handled only once by all the hooks services. With a durable consumer, messages are pulled from the JetStream
+// stream of the subject and acknowledged once handled, so messages published while the hook is stopped are not lost.
+func natsSubscribe(nc *nats.Conn, uuid string, config sdk.WorkflowNodeHookConfig, handler func(*nats.Msg)) error {
+	subject := config[sdk.NATSHookModelSubject].Value
+	if subject == "" {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "missing subject")
+	}
+
+	durable := config[sdk.NATSHookModelDurable].Value
+	if durable == "" {
+		queueGroup := config[sdk.NATSHookModelQueueGroup].Value
+		if queueGroup == "" {
+			queueGroup = uuid
+		}
+		_, err := nc.QueueSubscribe(subject, queueGroup, handler)
+		return err
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return err
+	}
+	sub, err := js.PullSubscribe(subject, durable)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for !nc.IsClosed() {
+			msgs, err := sub.Fetch(10, nats.MaxWait(5*time.Second))
+			if err != nil {
+				if err != nats.ErrTimeout && !nc.IsClosed() {
+					log.Warning(context.Background(), "Hooks> Unable to fetch nats messages on %s for %s: %v", subject, uuid, err)
+					time.Sleep(time.Second)
+				}
+				continue
+			}
+			for _, msg := range msgs {
+				handler(msg)
+				if err := msg.Ack(); err != nil {
+					log.Warning(context.Background(), "Hooks> Unable to ack nats message on %s for %s: %v", subject, uuid, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (s *Service) doNATSTaskExecution(t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error) {
+	log.Debug("Hooks> Processing nats %s %s", t.UUID, t.Type)
+
+	// Prepare a struct to send to CDS API
+	h := sdk.WorkflowNodeRunHookEvent{
+		WorkflowNodeHookUUID: t.UUID,
+		Payload:              map[string]string{},
+	}
+
+	var bodyJSON interface{}
+
+	//Try to parse the body as an array
+	bodyJSONArray := []interface{}{}
+	if err := json.Unmarshal(t.NATS.Message, &bodyJSONArray); err != nil {
+		//Try to parse the body as a map
+		bodyJSONMap := map[string]interface{}{}
+		if err2 := json.Unmarshal(t.NATS.Message, &bodyJSONMap); err2 == nil {
+			bodyJSON = bodyJSONMap
+		}
+	} else {
+		bodyJSON = bodyJSONArray
+	}
+
+	//Go Dump
+	e := dump.NewDefaultEncoder()
+	e.Formatters = []dump.KeyFormatterFunc{dump.WithDefaultLowerCaseFormatter()}
+	e.ExtraFields.DetailedMap = false
+	e.ExtraFields.DetailedStruct = false
+	e.ExtraFields.DeepJSON = true
+	e.ExtraFields.Len = false
+	e.ExtraFields.Type = false
+	m, err := e.ToStringMap(bodyJSON)
+	if err != nil {
+		return nil, sdk.WrapError(err, "Unable to dump body %s", t.NATS.Message)
+	}
+	h.Payload = m
+	h.Payload["payload"] = string(t.NATS.Message)
+	h.Payload["cds.nats.subject"] = t.NATS.Subject
+
+	return &h, nil
+}
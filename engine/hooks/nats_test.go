@@ -0,0 +1,134 @@
+package hooks
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func runNATSServer(t *testing.T) (*server.Server, func()) {
+	dir, err := ioutil.TempDir("", "nats")
+	require.NoError(t, err)
+	s, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  dir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go s.Start()
+	require.True(t, s.ReadyForConnections(5*time.Second))
+	return s, func() {
+		s.Shutdown()
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func receiveNATSMessage(t *testing.T, c <-chan *nats.Msg) *nats.Msg {
+	select {
+	case msg := <-c:
+		return msg
+	case <-time.After(10 * time.Second):
+		t.Fatal("no message received")
+	}
+	return nil
+}
+
+func Test_natsSubscribeQueueGroup(t *testing.T) {
+	s, stop := runNATSServer(t)
+	defer stop()
+
+	config := sdk.WorkflowNodeHookConfig{
+		sdk.NATSHookModelSubject: {Value: "cds.events"},
+	}
+
+	// Two hooks services share the default queue group, a message is handled once
+	received := make(chan *nats.Msg, 10)
+	for i := 0; i < 2; i++ {
+		nc, err := natsConnect(sdk.IntegrationConfig{"url": {Value: s.ClientURL()}}, "my-hook")
+		require.NoError(t, err)
+		defer nc.Close()
+		require.NoError(t, natsSubscribe(nc, "my-hook", config, func(msg *nats.Msg) { received <- msg }))
+		require.NoError(t, nc.Flush())
+	}
+
+	pub, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer pub.Close()
+	require.NoError(t, pub.Publish("cds.events", []byte(`{"version":"1.0.0"}`)))
+
+	msg := receiveNATSMessage(t, received)
+	assert.Equal(t, `{"version":"1.0.0"}`, string(msg.Data))
+	select {
+	case <-received:
+		t.Fatal("message should be received once")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	assert.Error(t, natsSubscribe(pub, "my-hook", sdk.WorkflowNodeHookConfig{}, func(*nats.Msg) {}))
+}
+
+func Test_natsSubscribeDurable(t *testing.T) {
+	s, stop := runNATSServer(t)
+	defer stop()
+
+	pub, err := nats.Connect(s.ClientURL())
+	require.NoError(t, err)
+	defer pub.Close()
+	js, err := pub.JetStream()
+	require.NoError(t, err)
+	_, err = js.AddStream(&nats.StreamConfig{Name: "CDS", Subjects: []string{"cds.>"}})
+	require.NoError(t, err)
+
+	config := sdk.WorkflowNodeHookConfig{
+		sdk.NATSHookModelSubject: {Value: "cds.deployments"},
+		sdk.NATSHookModelDurable: {Value: "cds-hook"},
+	}
+
+	// A message published before the subscription is delivered to the durable consumer
+	_, err = js.Publish("cds.deployments", []byte("first"))
+	require.NoError(t, err)
+
+	received := make(chan *nats.Msg, 10)
+	nc, err := natsConnect(sdk.IntegrationConfig{"url": {Value: s.ClientURL()}}, "my-hook")
+	require.NoError(t, err)
+	require.NoError(t, natsSubscribe(nc, "my-hook", config, func(msg *nats.Msg) { received <- msg }))
+	assert.Equal(t, "first", string(receiveNATSMessage(t, received).Data))
+	nc.Close()
+
+	// Messages published while the hook is stopped are delivered when it restarts, acknowledged ones are not
+	_, err = js.Publish("cds.deployments", []byte("second"))
+	require.NoError(t, err)
+
+	nc, err = natsConnect(sdk.IntegrationConfig{"url": {Value: s.ClientURL()}}, "my-hook")
+	require.NoError(t, err)
+	defer nc.Close()
+	require.NoError(t, natsSubscribe(nc, "my-hook", config, func(msg *nats.Msg) { received <- msg }))
+	assert.Equal(t, "second", string(receiveNATSMessage(t, received).Data))
+}
+
+func Test_doNATSTaskExecution(t *testing.T) {
+	s := Service{}
+	h, err := s.doNATSTaskExecution(&sdk.TaskExecution{
+		UUID: sdk.RandomString(10),
+		Type: TypeNATS,
+		NATS: &sdk.NATSTaskExecution{
+			Subject: "cds.deployments",
+			Message: []byte(`{"application":{"name":"my-app","version":"1.0.0"}}`),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "my-app", h.Payload["application.name"])
+	assert.Equal(t, "1.0.0", h.Payload["application.version"])
+	assert.Equal(t, "cds.deployments", h.Payload["cds.nats.subject"])
+}
@@ -3,18 +3,63 @@ package hooks
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"math/rand"
+	"time"
 
 	dump "github.com/fsamin/go-dump"
+	"github.com/gorhill/cronexpr"
+
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
 )
 
-func (s *Service) doScheduledTaskExecution(ctx context.Context, t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error) {
+const (
+	// schedulerMaxCatchUp is the max number of missed occurrences triggered by a scheduler after an outage of the hooks service.
+	schedulerMaxCatchUp = 10
+	// schedulerCatchUpDelay is the delay between the executions that trigger the missed occurrences.
+	schedulerCatchUpDelay = time.Minute
+)
+
+func (s *Service) doScheduledTaskExecution(ctx context.Context, task *sdk.Task, t *sdk.TaskExecution) ([]sdk.WorkflowNodeRunHookEvent, error) {
 	log.Debug("Hooks> Processing scheduled task %s", t.UUID)
 
-	// Prepare a struct to send to CDS API
-	h := sdk.WorkflowNodeRunHookEvent{
-		WorkflowNodeHookUUID: t.UUID,
+	confProj := t.Config[sdk.HookConfigProject]
+	confWorkflow := t.Config[sdk.HookConfigWorkflow]
+
+	// The next missed occurrence will be triggered by the next execution, even if this one is skipped
+	occurrence, missed, err := scheduledTaskOccurrences(t, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !missed.IsZero() {
+		t.ScheduledTask.NextCatchUp = missed.UnixNano()
+	}
+
+	// The workflow is not triggered if its previous scheduled run is not over
+	if t.Config[sdk.SchedulerModelSkipIfRunning].Value == "true" {
+		number, running, err := s.isLastScheduledRunInProgress(ctx, task)
+		if err != nil {
+			return nil, err
+		}
+		if running {
+			t.ScheduledTask.SkipReason = fmt.Sprintf("workflow run %s/%s #%d is still running", confProj.Value, confWorkflow.Value, number)
+			log.Info(ctx, "Hooks> Scheduled task %s skipped: %s", t.UUID, t.ScheduledTask.SkipReason)
+			return nil, nil
+		}
+	}
+
+	// Occurrences during a blackout window of the calendar are skipped
+	if confCalendar := t.Config[sdk.SchedulerModelCalendar]; confCalendar.Value != "" {
+		calendar, err := s.Client.ProjectCalendarGet(confProj.Value, confCalendar.Value)
+		if err != nil {
+			return nil, sdk.WrapError(err, "unable to get calendar %s", confCalendar.Value)
+		}
+		if b := calendar.Blackout(occurrence); b != nil {
+			t.ScheduledTask.SkipReason = fmt.Sprintf("blackout %s of calendar %s", b.Name, calendar.Name)
+			log.Info(ctx, "Hooks> Scheduled task %s skipped: %s", t.UUID, t.ScheduledTask.SkipReason)
+			return nil, nil
+		}
 	}
 
 	//Prepare the payload
@@ -44,14 +89,86 @@ func (s *Service) doScheduledTaskExecution(ctx context.Context, t *sdk.TaskExecu
 	}
 	for k, v := range t.Config {
 		switch k {
-		case sdk.HookConfigProject, sdk.HookConfigWorkflow, sdk.SchedulerModelCron, sdk.SchedulerModelTimezone, sdk.Payload:
+		case sdk.HookConfigProject, sdk.HookConfigWorkflow, sdk.SchedulerModelCron, sdk.SchedulerModelTimezone, sdk.Payload,
+			sdk.SchedulerModelCalendar, sdk.SchedulerModelJitter, sdk.SchedulerModelSkipIfRunning, sdk.SchedulerModelCatchUp:
 		default:
 			payloadValues[k] = v.Value
 		}
 	}
 	payloadValues["cds.triggered_by.username"] = "cds.scheduler"
 	payloadValues["cds.triggered_by.fullname"] = "CDS Scheduler"
-	h.Payload = payloadValues
 
-	return &h, nil
+	if t.Config[sdk.SchedulerModelCatchUp].Value == "true" {
+		payloadValues["cds.scheduler.occurrence"] = occurrence.Format(time.RFC3339)
+	}
+
+	// Prepare a struct to send to CDS API
+	h := sdk.WorkflowNodeRunHookEvent{
+		WorkflowNodeHookUUID: t.UUID,
+		Payload:              payloadValues,
+	}
+
+	return []sdk.WorkflowNodeRunHookEvent{h}, nil
+}
+
+// isLastScheduledRunInProgress returns the number of the last workflow run triggered by a scheduler and true if it is not over.
+func (s *Service) isLastScheduledRunInProgress(ctx context.Context, task *sdk.Task) (int64, bool, error) {
+	execs, err := s.Dao.FindAllTaskExecutions(ctx, task)
+	if err != nil {
+		return 0, false, sdk.WrapError(err, "unable to load executions of task %s", task.UUID)
+	}
+	var number int64
+	for _, e := range execs {
+		if e.WorkflowRun > number {
+			number = e.WorkflowRun
+		}
+	}
+	if number == 0 {
+		return 0, false, nil
+	}
+
+	run, err := s.Client.WorkflowRunGet(task.Config[sdk.HookConfigProject].Value, task.Config[sdk.HookConfigWorkflow].Value, number)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrWorkflowNotFound) || sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return number, false, nil
+		}
+		return number, false, sdk.WrapError(err, "unable to get workflow run %d", number)
+	}
+	return number, !sdk.StatusIsTerminated(run.Status), nil
+}
+
+// scheduledTaskOccurrences returns the occurrence of the cron expression to trigger for a scheduled execution and,
+// if catch up is enabled, the next occurrence missed since then. The missed occurrences are triggered one after the
+// other by the next executions, at most schedulerMaxCatchUp.
+func scheduledTaskOccurrences(t *sdk.TaskExecution, now time.Time) (time.Time, time.Time, error) {
+	occurrence := time.Unix(0, t.Timestamp)
+	if t.ScheduledTask.Occurrence > 0 {
+		occurrence = time.Unix(0, t.ScheduledTask.Occurrence)
+	}
+	if t.Config[sdk.SchedulerModelCatchUp].Value != "true" || t.ScheduledTask.CatchUp+1 >= schedulerMaxCatchUp {
+		return occurrence, time.Time{}, nil
+	}
+
+	loc, err := time.LoadLocation(t.Config[sdk.SchedulerModelTimezone].Value)
+	if err != nil {
+		return occurrence, time.Time{}, sdk.WrapError(err, "unable to parse timezone: %v", t.Config[sdk.SchedulerModelTimezone])
+	}
+	cronExpr, err := cronexpr.Parse(t.Config[sdk.SchedulerModelCron].Value)
+	if err != nil {
+		return occurrence, time.Time{}, sdk.WrapError(err, "unable to parse cron expression: %v", t.Config[sdk.SchedulerModelCron])
+	}
+	next := cronExpr.Next(occurrence.In(loc))
+	if next.IsZero() || next.After(now) {
+		return occurrence, time.Time{}, nil
+	}
+	return occurrence, next, nil
+}
+
+// schedulerJitter returns a random delay lower than the jitter of a scheduler.
+func schedulerJitter(config sdk.WorkflowNodeHookConfig) (time.Duration, error) {
+	jitter, err := config.SchedulerJitter()
+	if err != nil || jitter == 0 {
+		return 0, err
+	}
+	return time.Duration(rand.Int63n(int64(jitter))), nil
 }
@@ -0,0 +1,119 @@
+package hooks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+func Test_scheduledTaskOccurrences(t *testing.T) {
+	occurrence := time.Date(2020, 11, 2, 10, 0, 0, 0, time.UTC)
+	e := &sdk.TaskExecution{
+		Timestamp: occurrence.Add(3 * time.Minute).UnixNano(),
+		Type:      TypeScheduler,
+		Config: sdk.WorkflowNodeHookConfig{
+			sdk.SchedulerModelCron:     {Value: "0 * * * *"},
+			sdk.SchedulerModelTimezone: {Value: "UTC"},
+		},
+		ScheduledTask: &sdk.ScheduledTaskExecution{Occurrence: occurrence.UnixNano()},
+	}
+	now := occurrence.Add(150 * time.Minute)
+
+	// Without catch up, only the occurrence of the execution is triggered
+	o, missed, err := scheduledTaskOccurrences(e, now)
+	require.NoError(t, err)
+	assert.True(t, occurrence.Equal(o))
+	assert.True(t, missed.IsZero())
+
+	// With catch up, the next missed occurrence is triggered by the next execution
+	e.Config[sdk.SchedulerModelCatchUp] = sdk.WorkflowNodeHookConfigValue{Value: "true"}
+	o, missed, err = scheduledTaskOccurrences(e, now)
+	require.NoError(t, err)
+	assert.True(t, occurrence.Equal(o))
+	assert.True(t, occurrence.Add(time.Hour).Equal(missed))
+
+	// An occurrence is not missed before its date
+	_, missed, err = scheduledTaskOccurrences(e, occurrence.Add(50*time.Minute))
+	require.NoError(t, err)
+	assert.True(t, missed.IsZero())
+
+	// The catch up stops after schedulerMaxCatchUp executions
+	e.ScheduledTask.CatchUp = schedulerMaxCatchUp - 1
+	_, missed, err = scheduledTaskOccurrences(e, occurrence.Add(100*24*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, missed.IsZero())
+}
+
+func Test_schedulerJitter(t *testing.T) {
+	d, err := schedulerJitter(sdk.WorkflowNodeHookConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	for i := 0; i < 10; i++ {
+		d, err = schedulerJitter(sdk.WorkflowNodeHookConfig{sdk.SchedulerModelJitter: {Value: "5m"}})
+		require.NoError(t, err)
+		assert.True(t, d >= 0 && d < 5*time.Minute)
+	}
+
+	_, err = schedulerJitter(sdk.WorkflowNodeHookConfig{sdk.SchedulerModelJitter: {Value: "five minutes"}})
+	assert.Error(t, err)
+	_, err = schedulerJitter(sdk.WorkflowNodeHookConfig{sdk.SchedulerModelJitter: {Value: "-5m"}})
+	assert.Error(t, err)
+}
+
+func Test_doScheduledTaskExecutionSkipIfRunning(t *testing.T) {
+	s, cancel := setupTestHookService(t)
+	defer cancel()
+	defer gock.Off()
+
+	s.Client = cdsclient.New(cdsclient.Config{Host: "http://lolcat.host"})
+	gock.InterceptClient(s.Client.(cdsclient.Raw).HTTPClient())
+
+	task := &sdk.Task{
+		UUID: sdk.RandomString(10),
+		Type: TypeScheduler,
+		Config: sdk.WorkflowNodeHookConfig{
+			sdk.HookConfigProject:           {Value: "PROJ"},
+			sdk.HookConfigWorkflow:          {Value: "my-workflow"},
+			sdk.SchedulerModelCron:          {Value: "0 * * * *"},
+			sdk.SchedulerModelTimezone:      {Value: "UTC"},
+			sdk.SchedulerModelCatchUp:       {Value: "true"},
+			sdk.SchedulerModelSkipIfRunning: {Value: "true"},
+		},
+	}
+
+	// The run triggered by the previous execution is still running
+	require.NoError(t, s.Dao.SaveTaskExecution(&sdk.TaskExecution{
+		UUID:          task.UUID,
+		Type:          task.Type,
+		Timestamp:     time.Now().Add(-3 * time.Hour).UnixNano(),
+		WorkflowRun:   42,
+		ScheduledTask: &sdk.ScheduledTaskExecution{},
+	}))
+	gock.New("http://lolcat.host").Get("/project/PROJ/workflows/my-workflow/runs/42").
+		Reply(200).JSON(sdk.WorkflowRun{Number: 42, Status: sdk.StatusBuilding})
+
+	occurrence := time.Now().Add(-150 * time.Minute).Truncate(time.Hour)
+	e := &sdk.TaskExecution{
+		UUID:          task.UUID,
+		Type:          task.Type,
+		Timestamp:     occurrence.UnixNano(),
+		Config:        task.Config,
+		ScheduledTask: &sdk.ScheduledTaskExecution{Occurrence: occurrence.UnixNano()},
+	}
+
+	// The execution is skipped but the catch up of the missed occurrences goes on
+	hs, err := s.doScheduledTaskExecution(context.TODO(), task, e)
+	require.NoError(t, err)
+	assert.Len(t, hs, 0)
+	assert.NotEmpty(t, e.ScheduledTask.SkipReason)
+	assert.Equal(t, occurrence.Add(time.Hour).UnixNano(), e.ScheduledTask.NextCatchUp)
+	assert.True(t, gock.IsDone())
+}
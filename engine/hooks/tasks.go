@@ -24,6 +24,8 @@ const (
 	TypeKafka              = "Kafka"
 	TypeGerrit             = "Gerrit"
 	TypeRabbitMQ           = "RabbitMQ"
+	TypeNATS               = "NATS"
+	TypeAMQP               = "AMQP"
 	TypeWorkflowHook       = "Workflow"
 	TypeOutgoingWebHook    = "OutgoingWebhook"
 	TypeOutgoingWorkflow   = "OutgoingWorkflow"
@@ -167,6 +169,18 @@ func (s *Service) hookToTask(h *sdk.NodeHook) (*sdk.Task, error) {
 			Type:   TypeRabbitMQ,
 			Config: h.Config,
 		}, nil
+	case sdk.NATSHookModelName:
+		return &sdk.Task{
+			UUID:   h.UUID,
+			Type:   TypeNATS,
+			Config: h.Config,
+		}, nil
+	case sdk.AMQPHookModelName:
+		return &sdk.Task{
+			UUID:   h.UUID,
+			Type:   TypeAMQP,
+			Config: h.Config,
+		}, nil
 	case sdk.WebHookModelName:
 		h.Config["webHookURL"] = sdk.WorkflowNodeHookConfigValue{
 			Value:        fmt.Sprintf("%s/webhook/%s", s.Cfg.URLPublic, h.UUID),
@@ -265,6 +279,10 @@ func (s *Service) startTask(ctx context.Context, t *sdk.Task) (*sdk.TaskExecutio
 		return nil, s.startKafkaHook(ctx,t)
 	case TypeRabbitMQ:
 		return nil, s.startRabbitMQHook(ctx, t)
+	case TypeNATS:
+		return nil, s.startNATSHook(ctx, t)
+	case TypeAMQP:
+		return nil, s.startAMQPHook(ctx, t)
 	case TypeOutgoingWebHook:
 		return s.startOutgoingWebHookTask(t)
 	case TypeOutgoingWorkflow:
@@ -301,9 +319,19 @@ func (s *Service) prepareNextScheduledTaskExecution(ctx context.Context, t *sdk.
 	}
 
 	var exec *sdk.TaskExecution
-	var nextSchedule time.Time
+	var nextSchedule, occurrence time.Time
+	var catchUp int
 	switch t.Type {
 	case TypeScheduler:
+		//The missed occurrences are triggered one after the other
+		if len(execs) > 0 && execs[len(execs)-1].ScheduledTask != nil && execs[len(execs)-1].ScheduledTask.NextCatchUp > 0 {
+			last := execs[len(execs)-1].ScheduledTask
+			occurrence = time.Unix(0, last.NextCatchUp)
+			nextSchedule = time.Now().Add(schedulerCatchUpDelay)
+			catchUp = last.CatchUp + 1
+			break
+		}
+
 		//Parse the cron expr
 		confCron := t.Config[sdk.SchedulerModelCron]
 		cronExpr, err := cronexpr.Parse(confCron.Value)
@@ -311,9 +339,14 @@ func (s *Service) prepareNextScheduledTaskExecution(ctx context.Context, t *sdk.
 			return sdk.WrapError(err, "unable to parse cron expression: %v", t.Config[sdk.SchedulerModelCron])
 		}
 
-		//Compute a new date
+		//Compute a new date, delayed by a random jitter
 		t0 := time.Now().In(loc)
-		nextSchedule = cronExpr.Next(t0)
+		occurrence = cronExpr.Next(t0)
+		jitter, err := schedulerJitter(t.Config)
+		if err != nil {
+			log.Error(ctx, "Hooks> Scheduled task %s executed without jitter: %v", t.UUID, err)
+		}
+		nextSchedule = occurrence.Add(jitter)
 
 	case TypeRepoPoller:
 		// Default value of next scheduling
@@ -341,6 +374,10 @@ func (s *Service) prepareNextScheduledTaskExecution(ctx context.Context, t *sdk.
 			DateScheduledExecution: fmt.Sprintf("%v", nextSchedule),
 		},
 	}
+	if !occurrence.IsZero() {
+		exec.ScheduledTask.Occurrence = occurrence.UnixNano()
+		exec.ScheduledTask.CatchUp = catchUp
+	}
 
 	s.Dao.SaveTaskExecution(exec)
 	//We don't push in queue, we will the scheduler to run it
@@ -365,6 +402,14 @@ func (s *Service) stopTask(ctx context.Context, t *sdk.Task) error {
 		s.stopGerritHookTask(t)
 		log.Debug("Hooks> Gerrit Task %s has been stopped", t.UUID)
 		return nil
+	case TypeNATS:
+		stopNATSHook(t)
+		log.Debug("Hooks> NATS Task %s has been stopped", t.UUID)
+		return nil
+	case TypeAMQP:
+		stopAMQPHook(t)
+		log.Debug("Hooks> AMQP Task %s has been stopped", t.UUID)
+		return nil
 	default:
 		return fmt.Errorf("Unsupported task type %s", t.Type)
 	}
@@ -391,7 +436,7 @@ func (s *Service) doTask(ctx context.Context, t *sdk.Task, e *sdk.TaskExecution)
 	case e.WebHook != nil && (e.Type == TypeWebHook || e.Type == TypeRepoManagerWebHook):
 		hs, err = s.doWebHookExecution(ctx, e)
 	case e.ScheduledTask != nil && e.Type == TypeScheduler:
-		h, err = s.doScheduledTaskExecution(ctx, e)
+		hs, err = s.doScheduledTaskExecution(ctx, t, e)
 		doRestart = true
 	case e.ScheduledTask != nil && e.Type == TypeRepoPoller:
 		//Populate next execution
@@ -403,6 +448,10 @@ func (s *Service) doTask(ctx context.Context, t *sdk.Task, e *sdk.TaskExecution)
 		h, err = s.doKafkaTaskExecution(e)
 	case e.RabbitMQ != nil && e.Type == TypeRabbitMQ:
 		h, err = s.doRabbitMQTaskExecution(e)
+	case e.NATS != nil && e.Type == TypeNATS:
+		h, err = s.doNATSTaskExecution(e)
+	case e.AMQP != nil && e.Type == TypeAMQP:
+		h, err = s.doAMQPTaskExecution(e)
 	default:
 		err = fmt.Errorf("Unsupported task type %s", e.Type)
 	}
@@ -2,8 +2,14 @@ package hooks
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"mime"
 	"net/http"
 	"net/url"
@@ -11,22 +17,153 @@ import (
 
 	dump "github.com/fsamin/go-dump"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/interpolate"
 	"github.com/ovh/cds/sdk/log"
 )
 
+var webHookSignatureAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
 func (s *Service) doWebHookExecution(ctx context.Context, e *sdk.TaskExecution) ([]sdk.WorkflowNodeRunHookEvent, error) {
 	log.Debug("Hooks> Processing webhook %s %s", e.UUID, e.Type)
 
 	if e.Type == TypeRepoManagerWebHook {
 		return s.executeRepositoryWebHook(ctx, e)
 	}
+
+	// The signature of the request is checked with the hmac secret of the project, before reading the body
+	if keyName := e.Config[sdk.WebHookModelSignatureKey].Value; keyName != "" {
+		key, err := s.Client.ProjectKeyGet(e.Config[sdk.HookConfigProject].Value, keyName, true)
+		if err != nil {
+			return nil, sdk.WrapError(err, "unable to get key %s", keyName)
+		}
+		if key.Type != sdk.KeyTypeHMAC {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "key %s of type %s can't be used to check a signature", keyName, key.Type)
+		}
+		reason, err := checkWebHookSignature(e, key.Private)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			e.WebHook.RejectReason = reason
+			log.Info(ctx, "Hooks> Webhook %s rejected: %s", e.UUID, reason)
+			return nil, nil
+		}
+	}
+
 	event, err := executeWebHook(e)
 	if err != nil {
 		return nil, err
 	}
+
+	reason, err := filterWebHookEvent(e.Config, event)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		e.WebHook.RejectReason = reason
+		log.Info(ctx, "Hooks> Webhook %s rejected: %s", e.UUID, reason)
+		return nil, nil
+	}
+
 	return []sdk.WorkflowNodeRunHookEvent{*event}, nil
 }
 
+// checkWebHookSignature computes the hmac of the request body with given secret and compares it to the signature
+// sent in the request header. It returns the reason why the request is rejected, or an empty string if the signature is valid.
+func checkWebHookSignature(t *sdk.TaskExecution, secret string) (string, error) {
+	headerName := t.Config[sdk.WebHookModelSignatureHeader].Value
+	if headerName == "" {
+		headerName = sdk.WebHookDefaultSignatureHeader
+	}
+	algo := strings.ToLower(t.Config[sdk.WebHookModelSignatureAlgo].Value)
+	if algo == "" {
+		algo = sdk.WebHookDefaultSignatureAlgo
+	}
+	hashFunc, ok := webHookSignatureAlgorithms[algo]
+	if !ok {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "unsupported signature algorithm %s", algo)
+	}
+
+	signature := http.Header(t.WebHook.RequestHeader).Get(headerName)
+	if signature == "" {
+		return fmt.Sprintf("missing signature header %s", headerName), nil
+	}
+	// Some senders prefix the signature with the algorithm, ex: sha256=<signature>
+	signature = strings.TrimPrefix(signature, algo+"=")
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Sprintf("invalid signature in header %s", headerName), nil
+	}
+
+	mac := hmac.New(hashFunc, []byte(secret))
+	_, _ = mac.Write(t.WebHook.RequestBody)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return fmt.Sprintf("signature in header %s doesn't match the body", headerName), nil
+	}
+	return "", nil
+}
+
+// filterWebHookEvent checks the filters of a webhook against the payload of the event. There is one condition per line
+// with the format "variable operator value", all conditions should be true for the event to trigger the workflow.
+// It returns the reason why the event is rejected, or an empty string if it's accepted.
+func filterWebHookEvent(config sdk.WorkflowNodeHookConfig, h *sdk.WorkflowNodeRunHookEvent) (string, error) {
+	params := sdk.ParametersFromMap(h.Payload)
+	for _, line := range strings.Split(config[sdk.WebHookModelFilter].Value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 {
+			return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid filter %q, expected format is \"variable operator value\"", line)
+		}
+		cond := sdk.WorkflowNodeCondition{Variable: fields[0], Operator: fields[1]}
+		if len(fields) == 3 {
+			cond.Value = strings.TrimSpace(fields[2])
+		}
+		if _, ok := sdk.WorkflowConditionsOperators[cond.Operator]; !ok {
+			return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid filter %q, unknown operator %s", line, cond.Operator)
+		}
+		ok, err := sdk.WorkflowCheckConditions([]sdk.WorkflowNodeCondition{cond}, params)
+		if err != nil {
+			return "", sdk.WrapError(err, "unable to check filter %q", line)
+		}
+		if !ok {
+			return fmt.Sprintf("filter %q doesn't match", line), nil
+		}
+	}
+	return "", nil
+}
+
+// mapWebHookPayload adds to the payload the variables of the mapping of a webhook. There is one variable per line
+// with the format "name=template", the template is interpolated with the values of the payload, ex: branch={{.ref}}.
+func mapWebHookPayload(config sdk.WorkflowNodeHookConfig, payload map[string]string) error {
+	values := make(map[string]string, len(payload))
+	for k, v := range payload {
+		values[k] = v
+	}
+	for _, line := range strings.Split(config[sdk.WebHookModelPayloadMapping].Value, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid payload mapping %q, expected format is \"name=template\"", line)
+		}
+		name := strings.TrimSpace(line[:i])
+		value, err := interpolate.Do(strings.TrimSpace(line[i+1:]), values)
+		if err != nil {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "unable to interpolate payload mapping %q: %v", line, err)
+		}
+		payload[name] = value
+	}
+	return nil
+}
+
 func getRepositoryHeader(whe *sdk.WebHookExecution, events []string) string {
 	if v, ok := whe.RequestHeader[GithubHeader]; ok && ((len(events) == 0 && v[0] == "push") || sdk.IsInArray(v[0], events)) {
 		return GithubHeader
@@ -183,7 +320,9 @@ func executeWebHook(t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error)
 	//Prepare the payload
 	for k, v := range t.Config {
 		switch k {
-		case sdk.HookConfigProject, sdk.HookConfigWorkflow, sdk.WebHookModelConfigMethod:
+		case sdk.HookConfigProject, sdk.HookConfigWorkflow, sdk.WebHookModelConfigMethod,
+			sdk.WebHookModelSignatureHeader, sdk.WebHookModelSignatureAlgo, sdk.WebHookModelSignatureKey,
+			sdk.WebHookModelPayloadMapping, sdk.WebHookModelFilter:
 		default:
 			h.Payload[k] = v.Value
 		}
@@ -196,6 +335,10 @@ func executeWebHook(t *sdk.TaskExecution) (*sdk.WorkflowNodeRunHookEvent, error)
 	for k := range values {
 		h.Payload[k] = values.Get(k)
 	}
+
+	if err := mapWebHookPayload(t.Config, h.Payload); err != nil {
+		return nil, err
+	}
 	return &h, nil
 }
 
@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_checkWebHookSignature(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/master"}`)
+	mac := hmac.New(sha256.New, []byte("my-secret"))
+	_, _ = mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	newTask := func(header map[string][]string) *sdk.TaskExecution {
+		return &sdk.TaskExecution{
+			Type: TypeWebHook,
+			Config: sdk.WorkflowNodeHookConfig{
+				sdk.WebHookModelSignatureKey: {Value: "proj-webhook"},
+			},
+			WebHook: &sdk.WebHookExecution{
+				RequestBody:   body,
+				RequestHeader: header,
+			},
+		}
+	}
+
+	reason, err := checkWebHookSignature(newTask(map[string][]string{"X-Hub-Signature-256": {"sha256=" + signature}}), "my-secret")
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	reason, err = checkWebHookSignature(newTask(map[string][]string{"X-Hub-Signature-256": {signature}}), "my-secret")
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	reason, err = checkWebHookSignature(newTask(map[string][]string{"X-Hub-Signature-256": {signature}}), "another-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "signature in header X-Hub-Signature-256 doesn't match the body", reason)
+
+	reason, err = checkWebHookSignature(newTask(nil), "my-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "missing signature header X-Hub-Signature-256", reason)
+
+	task := newTask(map[string][]string{"X-Signature": {signature}})
+	task.Config[sdk.WebHookModelSignatureHeader] = sdk.WorkflowNodeHookConfigValue{Value: "X-Signature"}
+	reason, err = checkWebHookSignature(task, "my-secret")
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	task.Config[sdk.WebHookModelSignatureAlgo] = sdk.WorkflowNodeHookConfigValue{Value: "md5"}
+	_, err = checkWebHookSignature(task, "my-secret")
+	assert.Error(t, err)
+}
+
+func Test_executeWebHookWithMappingAndFilter(t *testing.T) {
+	task := &sdk.TaskExecution{
+		UUID: sdk.RandomString(10),
+		Type: TypeWebHook,
+		Config: sdk.WorkflowNodeHookConfig{
+			sdk.WebHookModelConfigMethod:   {Value: "POST"},
+			sdk.WebHookModelPayloadMapping: {Value: "git.branch={{.ref | trimPrefix \"refs/heads/\"}}\nrelease={{.release.tag}}"},
+			sdk.WebHookModelFilter:         {Value: "action eq published\ngit.branch regex ^(master|release/.*)$"},
+		},
+		WebHook: &sdk.WebHookExecution{
+			RequestBody:   []byte(`{"action":"published","ref":"refs/heads/master","release":{"tag":"v1.2.0"}}`),
+			RequestHeader: map[string][]string{"Content-Type": {"application/json"}},
+		},
+	}
+
+	h, err := executeWebHook(task)
+	require.NoError(t, err)
+	assert.Equal(t, "master", h.Payload["git.branch"])
+	assert.Equal(t, "v1.2.0", h.Payload["release"])
+	assert.NotContains(t, h.Payload, sdk.WebHookModelPayloadMapping)
+	assert.NotContains(t, h.Payload, sdk.WebHookModelFilter)
+
+	reason, err := filterWebHookEvent(task.Config, h)
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	h.Payload["action"] = "deleted"
+	reason, err = filterWebHookEvent(task.Config, h)
+	require.NoError(t, err)
+	assert.Equal(t, `filter "action eq published" doesn't match`, reason)
+
+	task.Config[sdk.WebHookModelFilter] = sdk.WorkflowNodeHookConfigValue{Value: "action equals published"}
+	_, err = filterWebHookEvent(task.Config, h)
+	assert.Error(t, err)
+}
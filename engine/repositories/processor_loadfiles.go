@@ -8,6 +8,7 @@ import (
 	repo "github.com/fsamin/go-repo"
 
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
 	"github.com/ovh/cds/sdk/log"
 )
 
@@ -49,5 +50,53 @@ func (s *Service) processLoadFiles(ctx context.Context, op *sdk.Operation) error
 		op.LoadFiles.Results[f] = btes
 	}
 
+	return s.processLoadIncludes(ctx, gitRepo, op)
+}
+
+// processLoadIncludes loads recursively the files of the repository included by the loaded workflow files,
+// files included from a library repository are loaded by the api with another operation.
+func (s *Service) processLoadIncludes(ctx context.Context, gitRepo repo.Repo, op *sdk.Operation) error {
+	files := make([][]byte, 0, len(op.LoadFiles.Results))
+	for _, btes := range op.LoadFiles.Results {
+		files = append(files, btes)
+	}
+
+	for len(files) > 0 {
+		includes, err := exportentities.ReadWorkflowIncludes(files[0])
+		files = files[1:]
+		if err != nil {
+			// invalid files will be reported by the api
+			continue
+		}
+
+		for _, i := range includes {
+			if i.IsLibrary() || i.IsValid() != nil {
+				continue
+			}
+			key := i.Key()
+			if _, ok := op.LoadFiles.Includes[key]; ok {
+				continue
+			}
+
+			fi, err := gitRepo.Open(key)
+			if err != nil {
+				log.Debug("Repositories> processLoadIncludes> Open > [%s] Error: %v", op.UUID, err)
+				return fmt.Errorf("Unable to open included file %s", key)
+			}
+			btes, err := ioutil.ReadAll(fi)
+			fi.Close() // nolint
+			if err != nil {
+				log.Error(ctx, "Repositories> processLoadIncludes> ReadAll> [%s] Error: %v", op.UUID, err)
+				return err
+			}
+
+			if op.LoadFiles.Includes == nil {
+				op.LoadFiles.Includes = make(map[string][]byte)
+			}
+			op.LoadFiles.Includes[key] = btes
+			files = append(files, btes)
+		}
+	}
+
 	return nil
 }
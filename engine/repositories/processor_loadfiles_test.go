@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	repo "github.com/fsamin/go-repo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_processLoadIncludes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "Test_processLoadIncludes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint
+
+	files := map[string]string{
+		".cds/my-workflow.yml": `name: my-workflow
+version: v2.0
+include:
+- path: .cds/ci/build.yml
+- path: /.cds/ci/build.yml
+- path: deploy.yml
+  repository: my-org/cds-library
+  ref: v1.0.0
+workflow:
+  build:
+    pipeline: build
+`,
+		".cds/ci/build.yml": `include:
+- path: .cds/ci/common.yml
+pipelines:
+  build: {}
+`,
+		".cds/ci/common.yml": `include:
+- path: .cds/ci/build.yml
+environments:
+  prod: {}
+`,
+	}
+	require.NoError(t, os.Mkdir(filepath.Join(dir, ".git"), 0755))
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	gitRepo, err := repo.New(dir)
+	require.NoError(t, err)
+
+	s := new(Service)
+
+	// files of the repository are included recursively, files of library repositories are loaded by the api
+	op := sdk.Operation{
+		LoadFiles: sdk.OperationLoadFiles{
+			Results: map[string][]byte{
+				".cds/my-workflow.yml": []byte(files[".cds/my-workflow.yml"]),
+			},
+		},
+	}
+	require.NoError(t, s.processLoadIncludes(context.TODO(), gitRepo, &op))
+	assert.Equal(t, map[string][]byte{
+		".cds/ci/build.yml":  []byte(files[".cds/ci/build.yml"]),
+		".cds/ci/common.yml": []byte(files[".cds/ci/common.yml"]),
+	}, op.LoadFiles.Includes)
+
+	// an included file missing in the repository fails the operation
+	op = sdk.Operation{
+		LoadFiles: sdk.OperationLoadFiles{
+			Results: map[string][]byte{
+				".cds/my-workflow.yml": []byte(`name: my-workflow
+version: v2.0
+include:
+- path: .cds/ci/unknown.yml
+workflow:
+  build:
+    pipeline: build
+`),
+			},
+		},
+	}
+	err = s.processLoadIncludes(context.TODO(), gitRepo, &op)
+	require.Error(t, err)
+	assert.Equal(t, "Unable to open included file .cds/ci/unknown.yml", err.Error())
+}
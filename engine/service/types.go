@@ -45,7 +45,15 @@ type HatcheryCommonConfiguration struct {
 		MaxConcurrentProvisioning int  `toml:"maxConcurrentProvisioning" default:"10" comment:"Maximum allowed simultaneous workers provisioning" json:"maxConcurrentProvisioning"`
 		MaxConcurrentRegistering  int  `toml:"maxConcurrentRegistering" default:"2" comment:"Maximum allowed simultaneous workers registering. -1 to disable registering on this hatchery" json:"maxConcurrentRegistering"`
 		RegisterFrequency         int  `toml:"registerFrequency" default:"60" comment:"Check if some worker model have to be registered each n Seconds" json:"registerFrequency"`
-		WorkerLogsOptions         struct {
+		WorkerPool                struct {
+			Enabled           bool `toml:"enabled" default:"false" comment:"Keep pre-registered idle workers for each worker model, they take jobs as soon as they are queued. Format:true or false" json:"enabled"`
+			MinIdle           int  `toml:"minIdle" default:"0" comment:"Minimum number of idle workers kept for each worker model" json:"minIdle"`
+			MaxIdle           int  `toml:"maxIdle" default:"2" comment:"Maximum number of idle workers kept for each worker model" json:"maxIdle"`
+			IdleTTL           int  `toml:"idleTTL" default:"900" comment:"Idle workers above the expected pool size are drained after n Seconds" json:"idleTTL"`
+			Frequency         int  `toml:"frequency" default:"30" comment:"Check the pool size each n Seconds" json:"frequency"`
+			ArrivalRateWindow int  `toml:"arrivalRateWindow" default:"1800" comment:"Jobs received during the last n Seconds are used to compute the arrival rate of each worker model" json:"arrivalRateWindow"`
+		} `toml:"workerPool" comment:"Worker pool, only for hatcheries with worker models able to run unbooked workers (openstack, vsphere)" json:"workerPool"`
+		WorkerLogsOptions struct {
 			Graylog struct {
 				Host       string `toml:"host" comment:"Example: thot.ovh.com" json:"host"`
 				Port       int    `toml:"port" comment:"Example: 12202" json:"port"`
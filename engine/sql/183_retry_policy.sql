@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE action ADD COLUMN retry_policy JSONB;
+ALTER TABLE action_edge ADD COLUMN retry_policy JSONB;
+ALTER TABLE workflow_node_run_job ADD COLUMN attempts JSONB;
+
+-- +migrate Down
+ALTER TABLE action DROP COLUMN retry_policy;
+ALTER TABLE action_edge DROP COLUMN retry_policy;
+ALTER TABLE workflow_node_run_job DROP COLUMN attempts;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE action ADD COLUMN matrix JSONB;
+ALTER TABLE workflow_node_run_job ADD COLUMN matrix JSONB;
+
+-- +migrate Down
+ALTER TABLE action DROP COLUMN matrix;
+ALTER TABLE workflow_node_run_job DROP COLUMN matrix;
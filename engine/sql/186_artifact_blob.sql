@@ -0,0 +1,18 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "artifact_blob" (
+  id BIGSERIAL PRIMARY KEY,
+  sha256 VARCHAR(64) NOT NULL,
+  project_integration_id BIGINT NOT NULL DEFAULT 0,
+  size BIGINT NOT NULL DEFAULT 0,
+  object_path TEXT,
+  stored BOOLEAN NOT NULL DEFAULT FALSE,
+  ref_count BIGINT NOT NULL DEFAULT 0,
+  created TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+SELECT create_unique_index('artifact_blob', 'IDX_ARTIFACT_BLOB_SHA256_PROJECT_INTEGRATION', 'sha256,project_integration_id');
+
+ALTER TABLE workflow_node_run_artifacts ADD COLUMN blob_sha256 VARCHAR(64);
+
+-- +migrate Down
+ALTER TABLE workflow_node_run_artifacts DROP COLUMN blob_sha256;
+DROP TABLE "artifact_blob";
@@ -0,0 +1,14 @@
+-- +migrate Up
+ALTER TABLE workflow ADD COLUMN retention_policy JSONB;
+
+CREATE TABLE IF NOT EXISTS "workflow_deleted_branch" (
+  workflow_id BIGINT NOT NULL,
+  branch VARCHAR(256) NOT NULL,
+  deleted TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP,
+  PRIMARY KEY (workflow_id, branch)
+);
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_DELETED_BRANCH_WORKFLOW', 'workflow_deleted_branch', 'workflow', 'workflow_id', 'id');
+
+-- +migrate Down
+DROP TABLE "workflow_deleted_branch";
+ALTER TABLE workflow DROP COLUMN retention_policy;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE integration_model ADD COLUMN secret_backend BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE integration_model DROP COLUMN secret_backend;
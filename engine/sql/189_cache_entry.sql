@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "cache_entry" (
+  id BIGSERIAL PRIMARY KEY,
+  project_id BIGINT NOT NULL,
+  integration_name VARCHAR(256) NOT NULL,
+  tag VARCHAR(256) NOT NULL,
+  size BIGINT NOT NULL DEFAULT 0,
+  created TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP,
+  last_access TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+SELECT create_unique_index('cache_entry', 'IDX_CACHE_ENTRY_PROJECT_INTEGRATION_TAG', 'project_id,integration_name,tag');
+SELECT create_foreign_key_idx_cascade('FK_CACHE_ENTRY_PROJECT', 'cache_entry', 'project', 'project_id', 'id');
+
+-- +migrate Down
+DROP TABLE "cache_entry";
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "workflow_node_run_job_logs_object" (
+  workflow_node_run_job_id BIGINT NOT NULL,
+  step_order BIGINT NOT NULL,
+  workflow_node_run_id BIGINT NOT NULL,
+  start TIMESTAMP WITH TIME ZONE,
+  last_modified TIMESTAMP WITH TIME ZONE,
+  done TIMESTAMP WITH TIME ZONE,
+  compressed_version BIGINT NOT NULL DEFAULT 0,
+  compressed_size BIGINT NOT NULL DEFAULT 0,
+  compressed_blocks BIGINT[] NOT NULL DEFAULT '{}',
+  next_chunk BIGINT NOT NULL DEFAULT 0,
+  chunk_ids BIGINT[] NOT NULL DEFAULT '{}',
+  chunks BIGINT[] NOT NULL DEFAULT '{}',
+  PRIMARY KEY (workflow_node_run_job_id, step_order)
+);
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_NODE_RUN_JOB_LOGS_OBJECT_WORKFLOW_NODE_RUN', 'workflow_node_run_job_logs_object', 'workflow_node_run', 'workflow_node_run_id', 'id');
+
+-- +migrate Down
+DROP TABLE "workflow_node_run_job_logs_object";
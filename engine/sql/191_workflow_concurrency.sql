@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE workflow ADD COLUMN concurrency JSONB;
+
+-- +migrate Down
+ALTER TABLE workflow DROP COLUMN concurrency;
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "project_lock" (
+  id BIGSERIAL PRIMARY KEY,
+  project_id BIGINT NOT NULL,
+  name VARCHAR(256) NOT NULL,
+  held BOOLEAN NOT NULL DEFAULT false,
+  workflow_name VARCHAR(256) NOT NULL,
+  workflow_run_id BIGINT NOT NULL,
+  workflow_run_number BIGINT NOT NULL,
+  workflow_node_run_id BIGINT NOT NULL,
+  node_name VARCHAR(256) NOT NULL,
+  since TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+SELECT create_unique_index('project_lock', 'IDX_PROJECT_LOCK_NODE_RUN', 'workflow_node_run_id');
+CREATE UNIQUE INDEX IF NOT EXISTS IDX_PROJECT_LOCK_HELD ON project_lock (project_id, name) WHERE held;
+SELECT create_foreign_key_idx_cascade('FK_PROJECT_LOCK_PROJECT', 'project_lock', 'project', 'project_id', 'id');
+
+ALTER TABLE environment ADD COLUMN lock_name VARCHAR(256) NOT NULL DEFAULT '';
+
+-- +migrate Down
+DROP TABLE "project_lock";
+ALTER TABLE environment DROP COLUMN lock_name;
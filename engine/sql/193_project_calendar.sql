@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "project_calendar" (
+  id BIGSERIAL PRIMARY KEY,
+  project_id BIGINT NOT NULL,
+  name VARCHAR(256) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  timezone VARCHAR(256) NOT NULL DEFAULT '',
+  blackouts JSONB,
+  last_modified TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+SELECT create_unique_index('project_calendar', 'IDX_PROJECT_CALENDAR_PROJECT_NAME', 'project_id,name');
+SELECT create_foreign_key_idx_cascade('FK_PROJECT_CALENDAR_PROJECT', 'project_calendar', 'project', 'project_id', 'id');
+
+-- +migrate Down
+DROP TABLE "project_calendar";
@@ -0,0 +1,23 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS "workflow_template_release" (
+  id BIGSERIAL PRIMARY KEY,
+  workflow_template_id BIGINT NOT NULL,
+  version VARCHAR(100) NOT NULL,
+  changelog TEXT,
+  template JSONB,
+  author VARCHAR(100),
+  created TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+
+SELECT create_unique_index('workflow_template_release', 'IDX_WORKFLOW_TEMPLATE_RELEASE_VERSION', 'workflow_template_id,version');
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_TEMPLATE_RELEASE_TEMPLATE', 'workflow_template_release', 'workflow_template', 'workflow_template_id', 'id');
+
+ALTER TABLE workflow_template_instance ADD COLUMN release_version VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE workflow_template_instance ADD COLUMN previous_release_version VARCHAR(100) NOT NULL DEFAULT '';
+
+-- +migrate Down
+
+ALTER TABLE workflow_template_instance DROP COLUMN previous_release_version;
+ALTER TABLE workflow_template_instance DROP COLUMN release_version;
+DROP TABLE IF EXISTS workflow_template_release;
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE workflow_template ADD COLUMN includes JSONB;
+
+-- +migrate Down
+
+ALTER TABLE workflow_template DROP COLUMN includes;
@@ -0,0 +1,5 @@
+-- +migrate Up
+SELECT create_index('workflow_node_run', 'IDX_WORKFLOW_NODE_RUN_START', 'start');
+
+-- +migrate Down
+DROP INDEX IDX_WORKFLOW_NODE_RUN_START;
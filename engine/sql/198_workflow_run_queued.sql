@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS "workflow_run_queued" (
+    workflow_run_id BIGINT PRIMARY KEY,
+    options JSONB NOT NULL,
+    consumer_id VARCHAR(36) NOT NULL
+);
+SELECT create_foreign_key_idx_cascade('FK_WORKFLOW_RUN_QUEUED_WORKFLOW_RUN', 'workflow_run_queued', 'workflow_run', 'workflow_run_id', 'id');
+
+-- +migrate Down
+DROP TABLE workflow_run_queued;
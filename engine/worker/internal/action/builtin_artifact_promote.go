@@ -0,0 +1,75 @@
+package action
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ovh/cds/engine/worker/pkg/workerruntime"
+	"github.com/ovh/cds/sdk"
+)
+
+// RunArtifactPromote promotes the artifacts of a run of a workflow into the current run. The source run is given
+// by its number or is the last successful run of the workflow, on given branch if any.
+func RunArtifactPromote(ctx context.Context, wk workerruntime.Runtime, a sdk.Action, secrets []sdk.Variable) (sdk.Result, error) {
+	res := sdk.Result{Status: sdk.StatusSuccess}
+
+	req := sdk.ArtifactPromoteRequest{
+		ProjectKey:      strings.TrimSpace(sdk.ParameterValue(a.Parameters, "project")),
+		WorkflowName:    strings.TrimSpace(sdk.ParameterValue(a.Parameters, "workflow")),
+		Branch:          strings.TrimSpace(sdk.ParameterValue(a.Parameters, "branch")),
+		Pattern:         sdk.ParameterValue(a.Parameters, "pattern"),
+		Tag:             strings.TrimSpace(sdk.ParameterValue(a.Parameters, "tag")),
+		Mode:            strings.TrimSpace(sdk.ParameterValue(a.Parameters, "mode")),
+		DestinationTag:  strings.TrimSpace(sdk.ParameterValue(a.Parameters, "destination-tag")),
+		IntegrationName: strings.TrimSpace(sdk.ParameterValue(a.Parameters, "destination")),
+	}
+	if req.ProjectKey == "" {
+		req.ProjectKey = sdk.ParameterValue(wk.Parameters(), "cds.project")
+	}
+	if number := strings.TrimSpace(sdk.ParameterValue(a.Parameters, "number")); number != "" {
+		n, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return res, sdk.NewErrorFrom(sdk.ErrWrongRequest, "number parameter is not valid: %s", number)
+		}
+		req.Number = n
+	}
+	if err := req.IsValid(); err != nil {
+		return res, err
+	}
+
+	jobID, err := workerruntime.JobID(ctx)
+	if err != nil {
+		return res, err
+	}
+
+	if req.Number > 0 {
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Promoting artifacts from workflow %s/%s #%d...", req.ProjectKey, req.WorkflowName, req.Number))
+	} else if req.Branch != "" {
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Promoting artifacts from the last successful run of workflow %s/%s on branch %s...", req.ProjectKey, req.WorkflowName, req.Branch))
+	} else {
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Promoting artifacts from the last successful run of workflow %s/%s...", req.ProjectKey, req.WorkflowName))
+	}
+
+	arts, err := wk.Client().QueueArtifactPromote(ctx, jobID, req)
+	if err != nil {
+		return res, fmt.Errorf("unable to promote artifacts: %v", err)
+	}
+	if len(arts) == 0 {
+		wk.SendLog(ctx, workerruntime.LevelWarn, "No artifact promoted")
+		return res, nil
+	}
+
+	for _, art := range arts {
+		if p := art.Provenance; p != nil {
+			wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Artifact %s promoted with tag %s (built by %s/%s #%d on %s@%s)",
+				art.Name, art.Tag, p.ProjectKey, p.WorkflowName, p.RunNumber, p.Branch, p.Commit))
+			continue
+		}
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Artifact %s promoted with tag %s", art.Name, art.Tag))
+	}
+	wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("%d artifact(s) promoted", len(arts)))
+
+	return res, nil
+}
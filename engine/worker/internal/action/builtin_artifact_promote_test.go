@@ -0,0 +1,79 @@
+package action
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+func TestRunArtifactPromote(t *testing.T) {
+	defer gock.Off()
+
+	wk, ctx := setupTest(t)
+
+	gock.New("http://lolcat.host").Post("/queue/workflows/666/artifact/promote").
+		MatchType("json").
+		JSON(sdk.ArtifactPromoteRequest{
+			ProjectKey:   "projKey",
+			WorkflowName: "build",
+			Branch:       "master",
+			Pattern:      ".*\\.tar\\.gz",
+			Mode:         sdk.ArtifactPromoteModeCopy,
+		}).
+		Reply(200).
+		JSON([]sdk.WorkflowNodeRunArtifact{{
+			Name: "myapp.tar.gz",
+			Tag:  "1.0.0",
+			Provenance: &sdk.ArtifactProvenance{
+				ProjectKey:   "projKey",
+				WorkflowName: "build",
+				RunNumber:    12,
+				Branch:       "master",
+				Commit:       "abcdef",
+			},
+		}})
+
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPClient())
+
+	wk.Params = append(wk.Params, sdk.Parameter{Name: "cds.project", Value: "projKey"})
+	res, err := RunArtifactPromote(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{Name: "workflow", Value: "build"},
+				{Name: "branch", Value: "master"},
+				{Name: "pattern", Value: ".*\\.tar\\.gz"},
+				{Name: "mode", Value: "copy"},
+			},
+		}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusSuccess, res.Status)
+	assert.True(t, gock.IsDone())
+}
+
+func TestRunArtifactPromoteInvalidParameters(t *testing.T) {
+	wk, ctx := setupTest(t)
+
+	_, err := RunArtifactPromote(ctx, wk, sdk.Action{
+		Parameters: []sdk.Parameter{
+			{Name: "project", Value: "projKey"},
+			{Name: "workflow", Value: "build"},
+			{Name: "number", Value: "last"},
+		},
+	}, nil)
+	assert.Error(t, err)
+
+	_, err = RunArtifactPromote(ctx, wk, sdk.Action{
+		Parameters: []sdk.Parameter{
+			{Name: "project", Value: "projKey"},
+			{Name: "workflow", Value: "build"},
+			{Name: "number", Value: "12"},
+			{Name: "branch", Value: "master"},
+		},
+	}, nil)
+	assert.Error(t, err)
+}
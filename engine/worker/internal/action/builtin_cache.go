@@ -0,0 +1,179 @@
+package action
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/afero"
+
+	"github.com/ovh/cds/engine/worker/pkg/workerruntime"
+	"github.com/ovh/cds/sdk"
+)
+
+// RunCache restores the cache matching the key or one of the restore keys in the workspace. When no cache matched the key,
+// the cache is saved at the end of the job if it succeeded.
+func RunCache(ctx context.Context, wk workerruntime.Runtime, a sdk.Action, secrets []sdk.Variable) (sdk.Result, error) {
+	res := sdk.Result{Status: sdk.StatusSuccess}
+
+	projectKey := sdk.ParameterValue(wk.Parameters(), "cds.project")
+	integrationName := sdk.DefaultIfEmptyStorage(strings.TrimSpace(sdk.ParameterValue(a.Parameters, "destination")))
+
+	workdir, err := workerruntime.WorkingDirectory(ctx)
+	if err != nil {
+		return res, err
+	}
+	var abs string
+	if x, ok := wk.BaseDir().(*afero.BasePathFs); ok {
+		abs, _ = x.RealPath(workdir.Name())
+	} else {
+		abs = workdir.Name()
+	}
+	wkDirFS := afero.NewBasePathFs(afero.NewOsFs(), abs)
+
+	key, err := renderCacheKey(wkDirFS, strings.TrimSpace(sdk.ParameterValue(a.Parameters, "key")))
+	if err != nil {
+		return res, err
+	}
+	if key == "" {
+		return res, sdk.NewErrorFrom(sdk.ErrWrongRequest, "key parameter is empty. aborting")
+	}
+
+	paths := splitCacheLines(sdk.ParameterValue(a.Parameters, "path"))
+	if len(paths) == 0 {
+		return res, sdk.NewErrorFrom(sdk.ErrWrongRequest, "path parameter is empty. aborting")
+	}
+
+	keys := []string{key}
+	for _, k := range splitCacheLines(sdk.ParameterValue(a.Parameters, "restore-keys")) {
+		rk, err := renderCacheKey(wkDirFS, k)
+		if err != nil {
+			return res, err
+		}
+		keys = append(keys, rk)
+	}
+
+	// Caches of the current branch are used first, then the ones of the default branch
+	branches := []string{sdk.ParameterValue(wk.Parameters(), "git.branch")}
+	if defaultBranch := strings.TrimSpace(sdk.ParameterValue(a.Parameters, "default-branch")); defaultBranch != "" && defaultBranch != branches[0] {
+		branches = append(branches, defaultBranch)
+	}
+	tags := make([]string, 0, len(keys)*len(branches))
+	for _, k := range keys {
+		for _, b := range branches {
+			tags = append(tags, sdk.CacheTag(b, k))
+		}
+	}
+	tag := tags[0]
+
+	var hit bool
+	entry, err := wk.Client().WorkflowCacheResolve(projectKey, integrationName, tags)
+	if err != nil {
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("No cache found for key %s", key))
+	} else {
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Restoring cache %s", entry.Tag))
+		// A cache that can't be restored is handled as a cache miss, the cache will be saved again at the end of the job
+		r, err := wk.Client().WorkflowCachePull(projectKey, integrationName, entry.Tag)
+		if err == nil {
+			err = sdk.ExtractTarToPath(r, abs)
+		}
+		if err != nil {
+			wk.SendLog(ctx, workerruntime.LevelWarn, fmt.Sprintf("Unable to restore cache %s: %v", entry.Tag, err))
+		} else {
+			for _, b := range branches {
+				if entry.Tag == sdk.CacheTag(b, key) {
+					hit = true
+				}
+			}
+		}
+	}
+
+	if hit {
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Cache hit for key %s", key))
+		return res, nil
+	}
+
+	wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Cache miss for key %s, the cache will be saved at the end of the job", key))
+	wk.RegisterPostJob(func(ctx context.Context) error {
+		for i := range paths {
+			paths[i] = strings.TrimPrefix(paths[i], abs)
+		}
+		tar, size, err := sdk.CreateTarFromPaths(wkDirFS, "", paths, nil)
+		if err != nil {
+			return sdk.WrapError(err, "cannot tar cache %s", tag)
+		}
+		if err := wk.Client().WorkflowCachePush(projectKey, integrationName, tag, tar, size); err != nil {
+			return sdk.WrapError(err, "cannot push cache %s", tag)
+		}
+		wk.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Cache %s saved", tag))
+		return nil
+	})
+
+	return res, nil
+}
+
+func splitCacheLines(s string) []string {
+	var res []string
+	for _, l := range strings.Split(s, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			res = append(res, l)
+		}
+	}
+	return res
+}
+
+// renderCacheKey executes the key template with the hashFiles function.
+func renderCacheKey(fs afero.Fs, key string) (string, error) {
+	tmpl, err := template.New("key").Funcs(template.FuncMap{
+		"hashFiles": func(patterns ...string) (string, error) {
+			return hashFiles(fs, patterns...)
+		},
+	}).Parse(key)
+	if err != nil {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid cache key %s: %v", key, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid cache key %s: %v", key, err)
+	}
+	return buf.String(), nil
+}
+
+// hashFiles returns the SHA-256 of the content of the files matching given patterns.
+func hashFiles(fs afero.Fs, patterns ...string) (string, error) {
+	var files []string
+	for _, p := range patterns {
+		matches, err := afero.Glob(fs, p)
+		if err != nil {
+			return "", fmt.Errorf("cannot perform globbing of pattern '%s': %v", p, err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("patterns %v matched no file", patterns)
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for i, file := range files {
+		if i > 0 && files[i-1] == file {
+			continue
+		}
+		f, err := fs.Open(file)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		_ = f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,169 @@
+package action
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+func TestRenderCacheKey(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "go.sum", []byte("foo"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "src/a.lock", []byte("bar"), 0644))
+
+	key, err := renderCacheKey(fs, "go-static")
+	require.NoError(t, err)
+	assert.Equal(t, "go-static", key)
+
+	key, err = renderCacheKey(fs, `go-{{ hashFiles "go.sum" }}`)
+	require.NoError(t, err)
+	assert.Equal(t, "go-2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", key)
+
+	key1, err := renderCacheKey(fs, `{{ hashFiles "go.sum" "src/*.lock" }}`)
+	require.NoError(t, err)
+	key2, err := renderCacheKey(fs, `{{ hashFiles "src/*.lock" "go.sum" }}`)
+	require.NoError(t, err)
+	assert.Equal(t, key1, key2)
+	assert.NotEqual(t, "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae", key1)
+
+	_, err = renderCacheKey(fs, `go-{{ hashFiles "unknown.sum" }}`)
+	assert.Error(t, err)
+}
+
+func TestRunCacheMiss(t *testing.T) {
+	defer gock.Off()
+
+	wk, ctx := setupTest(t)
+	require.NoError(t, afero.WriteFile(wk.BaseDir(), filepath.Join("working_directory", "go.sum"), []byte("foo"), 0644))
+	require.NoError(t, wk.BaseDir().MkdirAll(filepath.Join("working_directory", "vendor"), 0755))
+	require.NoError(t, afero.WriteFile(wk.BaseDir(), filepath.Join("working_directory", "vendor", "lib.go"), []byte("package lib"), 0644))
+
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra/cache").
+		MatchParam("tag", "feat_2fmy-feature.go-static").
+		Reply(404).JSON(sdk.ErrNotFound)
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra").
+		Reply(200).JSON(sdk.ArtifactsStore{})
+	gock.New("http://lolcat.host").Post("/project/projKey/storage/shared.infra/cache/feat_2fmy-feature.go-static").
+		Reply(200)
+
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPClient())
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPSSEClient())
+
+	wk.Params = append(wk.Params, []sdk.Parameter{
+		{Name: "cds.project", Value: "projKey"},
+		{Name: "git.branch", Value: "feat/my-feature"},
+	}...)
+	res, err := RunCache(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{Name: "key", Value: "go-static"},
+				{Name: "restore-keys", Value: "go-"},
+				{Name: "path", Value: "vendor"},
+				{Name: "default-branch", Value: "master"},
+			},
+		}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusSuccess, res.Status)
+
+	require.Len(t, *wk.postJobs, 1)
+	require.NoError(t, (*wk.postJobs)[0](context.TODO()))
+	assert.True(t, gock.IsDone())
+}
+
+func TestRunCacheHit(t *testing.T) {
+	defer gock.Off()
+
+	wk, ctx := setupTest(t)
+
+	content := new(bytes.Buffer)
+	tw := tar.NewWriter(content)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "vendor/lib.go", Mode: 0644, Size: 11, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("package lib"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra/cache").
+		Reply(200).JSON(sdk.CacheEntry{Tag: "master.go-static"})
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra").
+		Reply(200).JSON(sdk.ArtifactsStore{})
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra/cache/master.go-static").
+		Reply(200).Body(content)
+
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPClient())
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPSSEClient())
+
+	wk.Params = append(wk.Params, []sdk.Parameter{
+		{Name: "cds.project", Value: "projKey"},
+		{Name: "git.branch", Value: "feat/my-feature"},
+	}...)
+	res, err := RunCache(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{Name: "key", Value: "go-static"},
+				{Name: "path", Value: "vendor"},
+				{Name: "default-branch", Value: "master"},
+			},
+		}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusSuccess, res.Status)
+	assert.Len(t, *wk.postJobs, 0)
+
+	b, err := afero.ReadFile(wk.BaseDir(), filepath.Join("working_directory", "vendor", "lib.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package lib", string(b))
+	assert.True(t, gock.IsDone())
+}
+
+func TestRunCachePullFailed(t *testing.T) {
+	defer gock.Off()
+
+	wk, ctx := setupTest(t)
+	require.NoError(t, wk.BaseDir().MkdirAll(filepath.Join("working_directory", "vendor"), 0755))
+	require.NoError(t, afero.WriteFile(wk.BaseDir(), filepath.Join("working_directory", "vendor", "lib.go"), []byte("package lib"), 0644))
+
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra/cache$").
+		Reply(200).JSON(sdk.CacheEntry{Tag: "master.go-static"})
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra$").
+		Times(2).
+		Reply(200).JSON(sdk.ArtifactsStore{TemporaryURLSupported: true})
+	gock.New("http://lolcat.host").Get("/project/projKey/storage/shared.infra/cache/master.go-static/url").
+		Reply(404).JSON(sdk.ErrNotFound)
+	gock.New("http://lolcat.host").Post("/project/projKey/storage/shared.infra/cache/master.go-static/url$").
+		Reply(200).JSON(sdk.Cache{TmpURL: "http://lolcat.store/cache.tar"})
+	gock.New("http://lolcat.store").Put("/cache.tar").
+		Reply(200)
+	gock.New("http://lolcat.host").Post("/project/projKey/storage/shared.infra/cache/master.go-static/url/callback").
+		Reply(200)
+
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPClient())
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPSSEClient())
+
+	wk.Params = append(wk.Params, []sdk.Parameter{
+		{Name: "cds.project", Value: "projKey"},
+		{Name: "git.branch", Value: "master"},
+	}...)
+	res, err := RunCache(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{Name: "key", Value: "go-static"},
+				{Name: "path", Value: "vendor"},
+			},
+		}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusSuccess, res.Status)
+
+	// The cache that can't be pulled is saved again
+	require.Len(t, *wk.postJobs, 1)
+	require.NoError(t, (*wk.postJobs)[0](context.TODO()))
+	assert.True(t, gock.IsDone())
+}
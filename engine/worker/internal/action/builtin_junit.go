@@ -61,7 +61,7 @@ func RunParseJunitTestResultAction(ctx context.Context, wk workerruntime.Runtime
 		wk.SendLog(ctx, workerruntime.LevelInfo, r)
 	}
 
-	if err := wk.Blur(tests); err != nil {
+	if err := wk.Blur(&tests); err != nil {
 		return res, err
 	}
 
@@ -1,12 +1,22 @@
 package action
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/ovh/venom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
 
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
 )
 
 func Test_ComputeStats(t *testing.T) {
@@ -215,3 +225,51 @@ func Test_ComputeStats(t *testing.T) {
 		})
 	}
 }
+
+func TestRunParseJunitTestResultAction(t *testing.T) {
+	defer gock.Off()
+
+	wk, ctx := setupTest(t)
+
+	report := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="myTestSuite" tests="2" failures="1">
+    <testcase name="ok"></testcase>
+    <testcase name="ko"><failure message="expected 1"></failure></testcase>
+  </testsuite>
+</testsuites>`
+	dir, err := ioutil.TempDir("", "junit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "results.xml"), []byte(report), os.FileMode(0644)))
+
+	gock.New("http://lolcat.host").Post("/queue/workflows/666/test").
+		Reply(200)
+
+	var sent venom.Tests
+	var checkRequest gock.ObserverFunc = func(request *http.Request, mock gock.Mock) {
+		bodyContent, err := ioutil.ReadAll(request.Body)
+		assert.NoError(t, err)
+		request.Body = ioutil.NopCloser(bytes.NewReader(bodyContent))
+		if mock != nil && mock.Request().URLStruct.String() == "http://lolcat.host/queue/workflows/666/test" {
+			assert.NoError(t, json.Unmarshal(bodyContent, &sent))
+		}
+	}
+	gock.Observe(checkRequest)
+	defer gock.Observe(nil)
+
+	gock.InterceptClient(wk.Client().(cdsclient.Raw).HTTPClient())
+
+	res, err := RunParseJunitTestResultAction(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{Name: "path", Value: filepath.Join(dir, "*.xml")},
+			},
+		}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusFail, res.Status)
+	assert.True(t, gock.IsDone())
+	assert.Equal(t, 2, sent.Total)
+	assert.Equal(t, 1, sent.TotalOK)
+	assert.Equal(t, 1, sent.TotalKO)
+}
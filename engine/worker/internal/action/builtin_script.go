@@ -156,6 +156,8 @@ func writeScriptContent(ctx context.Context, script *script, fs afero.Fs, basedi
 func RunScriptAction(ctx context.Context, wk workerruntime.Runtime, a sdk.Action, secrets []sdk.Variable) (sdk.Result, error) {
 	chanRes := make(chan sdk.Result)
 	chanErr := make(chan error)
+	// exitCode is only written before sending on chanErr
+	var exitCode int
 
 	workdir, err := workerruntime.WorkingDirectory(ctx)
 	if err != nil {
@@ -188,6 +190,7 @@ func RunScriptAction(ctx context.Context, wk workerruntime.Runtime, a sdk.Action
 		res.Status = sdk.StatusUnknown
 		cmd.Dir = script.dir
 		cmd.Env = wk.Environ()
+		setProcessGroup(cmd)
 
 		workerpath, err := osext.Executable()
 		if err != nil {
@@ -245,10 +248,28 @@ func RunScriptAction(ctx context.Context, wk workerruntime.Runtime, a sdk.Action
 			chanErr <- fmt.Errorf("unable to start command: %v", err)
 		}
 
+		// Kill the whole process tree when the step is cancelled or timed out,
+		// otherwise children processes keep stdout and stderr opened
+		cmdDone := make(chan struct{})
+		defer close(cmdDone)
+		go func() {
+			select {
+			case <-ctx.Done():
+				if err := killProcessGroup(cmd); err != nil {
+					log.Warning(ctx, "runScriptAction> unable to kill process group: %v", err)
+				}
+			case <-cmdDone:
+			}
+		}()
+
 		<-outchan
 		<-errchan
 		if err := cmd.Wait(); err != nil {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			}
 			chanErr <- fmt.Errorf("command failure: %v", err)
+			return
 		}
 
 		res.Status = sdk.StatusSuccess
@@ -264,6 +285,7 @@ func RunScriptAction(ctx context.Context, wk workerruntime.Runtime, a sdk.Action
 		return res, errors.New("CDS Worker execution canceled")
 	case res = <-chanRes:
 	case globalErr = <-chanErr:
+		res.ExitCode = exitCode
 	}
 
 	log.Info(ctx, "runScriptAction> %s %s", res.Status, res.Reason)
@@ -1,7 +1,9 @@
 package action
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
@@ -105,3 +107,38 @@ func TestRunScriptAction(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, sdk.StatusSuccess, res.Status)
 }
+
+func TestRunScriptActionWithTimeout(t *testing.T) {
+	wk, ctx := setupTest(t)
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	start := time.Now()
+	res, err := RunScriptAction(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{
+					Name:  "script",
+					Value: "sleep 30 &\nsleep 30",
+				},
+			},
+		}, nil)
+	assert.Error(t, err)
+	assert.NotEqual(t, sdk.StatusSuccess, res.Status)
+	assert.True(t, time.Since(start) < 10*time.Second, "script should have been killed")
+}
+
+func TestRunScriptActionWithExitCode(t *testing.T) {
+	wk, ctx := setupTest(t)
+	res, err := RunScriptAction(ctx, wk,
+		sdk.Action{
+			Parameters: []sdk.Parameter{
+				{
+					Name:  "script",
+					Value: "exit 42",
+				},
+			},
+		}, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 42, res.ExitCode)
+}
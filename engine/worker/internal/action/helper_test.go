@@ -2,6 +2,7 @@ package action
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
@@ -26,17 +27,27 @@ type TestWorker struct {
 	keyDirectory     *afero.BasePathFile
 	client           cdsclient.WorkerInterface
 	Params           []sdk.Parameter
+	postJobs         *[]func(ctx context.Context) error
 }
 
+// Blur does the same json round trip as the worker to fail if i is not a pointer.
 func (w TestWorker) Blur(i interface{}) error {
 	w.t.Log("Blur")
-	return nil
+	data, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, i)
 }
 
 func (w TestWorker) Parameters() []sdk.Parameter {
 	return w.Params
 }
 
+func (w TestWorker) RegisterPostJob(f func(ctx context.Context) error) {
+	*w.postJobs = append(*w.postJobs, f)
+}
+
 func (w TestWorker) Client() cdsclient.WorkerInterface {
 	return w.client
 }
@@ -104,6 +115,7 @@ func setupTest(t *testing.T) (TestWorker, context.Context) {
 	wk := TestWorker{
 		t:         t,
 		workspace: afero.NewBasePathFs(fs, basedir),
+		postJobs:  new([]func(ctx context.Context) error),
 	}
 
 	err := wk.BaseDir().Mkdir("working_directory", os.FileMode(0755))
@@ -15,6 +15,7 @@ var mapBuiltinActions = map[string]BuiltInAction{}
 func init() {
 	mapBuiltinActions[sdk.ArtifactUpload] = action.RunArtifactUpload
 	mapBuiltinActions[sdk.ArtifactDownload] = action.RunArtifactDownload
+	mapBuiltinActions[sdk.ArtifactPromote] = action.RunArtifactPromote
 	mapBuiltinActions[sdk.ScriptAction] = action.RunScriptAction
 	mapBuiltinActions[sdk.JUnitAction] = action.RunParseJunitTestResultAction
 	mapBuiltinActions[sdk.GitCloneAction] = action.RunGitClone
@@ -25,6 +26,7 @@ func init() {
 	mapBuiltinActions[sdk.CoverageAction] = action.RunParseCoverageResultAction
 	mapBuiltinActions[sdk.ServeStaticFiles] = action.RunServeStaticFiles
 	mapBuiltinActions[sdk.InstallKeyAction] = action.RunInstallKey
+	mapBuiltinActions[sdk.CacheAction] = action.RunCache
 }
 
 func (w *CurrentWorker) runBuiltin(ctx context.Context, a sdk.Action, secrets []sdk.Variable) sdk.Result {
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/ovh/cds/engine/worker/pkg/workerruntime"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+// InitLocal initializes a worker that runs jobs on the local machine, the worker is not registered on CDS
+// and all the calls to the API are done with given client.
+func (wk *CurrentWorker) InitLocal(name string, workspace afero.Fs, client cdsclient.WorkerInterface) {
+	wk.status.Name = name
+	wk.basedir = workspace
+	wk.client = client
+}
+
+// RunLocalJob runs given job like a job taken from the queue, with given parameters and secrets.
+func (wk *CurrentWorker) RunLocalJob(ctx context.Context, job sdk.Job, params []sdk.Parameter, secrets []sdk.Variable) (sdk.Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Start the worker http server used by worker commands and plugins
+	if err := wk.Serve(ctx); err != nil {
+		return sdk.Result{Status: sdk.StatusFail, Reason: err.Error()}, err
+	}
+
+	jobInfo := sdk.WorkflowNodeJobRunData{
+		NodeJobRun: sdk.WorkflowNodeJobRun{
+			ID:         1,
+			Job:        sdk.ExecutedJob{Job: job, WorkerName: wk.Name()},
+			Parameters: params,
+			Status:     sdk.StatusBuilding,
+			Start:      time.Now(),
+		},
+		Secrets: secrets,
+	}
+
+	wk.currentJob.context = workerruntime.SetJobID(ctx, jobInfo.NodeJobRun.ID)
+	wk.currentJob.wJob = &jobInfo.NodeJobRun
+	wk.currentJob.secrets = secrets
+	wk.currentJob.newVariables = nil
+	wk.currentJob.postJobs = nil
+
+	start := time.Now()
+	res, err := wk.ProcessJob(jobInfo)
+	res.BuildID = jobInfo.NodeJobRun.ID
+	res.RemoteTime = time.Now()
+	res.Duration = sdk.Round(time.Since(start), time.Second).String()
+	return res, err
+}
@@ -108,7 +108,16 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 		BuildID: jobID,
 	}
 
-	var nDisabled, nCriticalFailed int
+	// The job context is only used to run steps, step statuses have to be sent even if the job timed out
+	jobCtx := ctx
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		jobCtx, cancel = context.WithTimeout(ctx, time.Duration(a.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	var nDisabled, nCriticalFailed, nCriticalTimeout int
+	var timeoutReason string
 	for jobStepIndex, step := range a.Actions {
 		ctx = workerruntime.SetStepOrder(ctx, jobStepIndex)
 		if err := w.updateStepStatus(ctx, jobID, jobStepIndex, sdk.StatusBuilding); err != nil {
@@ -120,8 +129,8 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 			Status:  sdk.StatusNeverBuilt,
 			BuildID: jobID,
 		}
-		if nCriticalFailed == 0 || step.AlwaysExecuted {
-			stepResult = w.runAction(ctx, step, jobID, secrets, step.Name)
+		if jobCtx.Err() == nil && (nCriticalFailed == 0 || step.AlwaysExecuted) {
+			stepResult = w.runActionWithRetry(workerruntime.SetStepOrder(jobCtx, jobStepIndex), step, jobID, secrets, step.Name)
 
 			for _, newVariable := range stepResult.NewVariables {
 				// append the new variable from a step to the following steps
@@ -134,8 +143,17 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 			case sdk.StatusDisabled:
 				nDisabled++
 			case sdk.StatusFail:
+				if !step.Optional {
+					if nCriticalFailed == 0 {
+						jobResult.ExitCode = stepResult.ExitCode
+					}
+					nCriticalFailed++
+				}
+			case sdk.StatusTimeout:
 				if !step.Optional {
 					nCriticalFailed++
+					nCriticalTimeout++
+					timeoutReason = stepResult.Reason
 				}
 			}
 		}
@@ -157,10 +175,28 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 	if nCriticalFailed > 0 {
 		jobResult.Status = sdk.StatusFail
 	}
+	if nCriticalTimeout > 0 || jobCtx.Err() == context.DeadlineExceeded {
+		jobResult.Status = sdk.StatusTimeout
+		jobResult.Reason = timeoutReason
+		if jobCtx.Err() == context.DeadlineExceeded {
+			jobResult.Reason = fmt.Sprintf("Job timed out after %s", time.Duration(a.Timeout)*time.Second)
+			w.SendLog(ctx, workerruntime.LevelError, jobResult.Reason)
+		}
+	}
+
+	// Post job functions registered by steps are only executed for successful jobs
+	if jobResult.Status == sdk.StatusSuccess {
+		for _, f := range w.currentJob.postJobs {
+			if err := f(ctx); err != nil {
+				log.Error(ctx, "runJob> post job error: %v", err)
+				w.SendLog(ctx, workerruntime.LevelWarn, fmt.Sprintf("Post job error: %v", err))
+			}
+		}
+	}
 	return jobResult, nil
 }
 
-func (w *CurrentWorker) runAction(ctx context.Context, a sdk.Action, jobID int64, secrets []sdk.Variable, actionName string) sdk.Result {
+func (w *CurrentWorker) runAction(ctx context.Context, a sdk.Action, jobID int64, secrets []sdk.Variable, actionName string) (res sdk.Result) {
 	log.Info(ctx, "runAction> start action %s %s %d", a.StepName, actionName, jobID)
 	defer func() { log.Info(ctx, "runAction> end action %s %s run %d", a.StepName, actionName, jobID) }()
 
@@ -178,6 +214,20 @@ func (w *CurrentWorker) runAction(ctx context.Context, a sdk.Action, jobID int64
 		}
 	}
 
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(a.Timeout)*time.Second)
+		defer cancel()
+	}
+	// If the step or the job timed out, the step status is set to timeout
+	defer func() {
+		if res.Status != sdk.StatusSuccess && ctx.Err() == context.DeadlineExceeded {
+			res.Status = sdk.StatusTimeout
+			res.Reason = fmt.Sprintf("Step \"%s\" timed out", actionName)
+			w.SendLog(ctx, workerruntime.LevelError, res.Reason)
+		}
+	}()
+
 	// Replace variable placeholder that may have been added by last step
 	if err := w.replaceVariablesPlaceholder(&a, w.currentJob.params); err != nil {
 		return sdk.Result{
@@ -227,13 +277,31 @@ func (w *CurrentWorker) runAction(ctx context.Context, a sdk.Action, jobID int64
 	return r
 }
 
+// runActionWithRetry runs the action then starts new attempts while its retry policy allows it.
+func (w *CurrentWorker) runActionWithRetry(ctx context.Context, a sdk.Action, jobID int64, secrets []sdk.Variable, actionName string) sdk.Result {
+	for attempt := 1; ; attempt++ {
+		res := w.runAction(ctx, a, jobID, secrets, actionName)
+		if !a.RetryPolicy.ShouldRetry(attempt, res) {
+			return res
+		}
+
+		delay := a.RetryPolicy.Delay(attempt)
+		w.SendLog(ctx, workerruntime.LevelWarn, fmt.Sprintf("Attempt %d/%d of step \"%s\" failed, retrying in %s", attempt, a.RetryPolicy.MaxAttempts, actionName, delay))
+		select {
+		case <-ctx.Done():
+			return res
+		case <-time.After(delay):
+		}
+	}
+}
+
 func (w *CurrentWorker) runSteps(ctx context.Context, steps []sdk.Action, a sdk.Action, jobID int64, secrets []sdk.Variable, stepName string) (sdk.Result, int) {
 	log.Info(ctx, "runSteps> start action steps %s %d len(steps):%d context=%p", stepName, jobID, len(steps), ctx)
 	defer func() {
 		log.Info(ctx, "runSteps> end action steps %s %d len(steps):%d context=%p (%s)", stepName, jobID, len(steps), ctx, ctx.Err())
 	}()
-	var criticalStepFailed bool
-	var nbDisabledChildren int
+	var criticalStepFailed, criticalStepTimeout bool
+	var nbDisabledChildren, exitCode int
 
 	r := sdk.Result{
 		Status:  sdk.StatusFail,
@@ -251,9 +319,13 @@ func (w *CurrentWorker) runSteps(ctx context.Context, steps []sdk.Action, a sdk.
 		}
 
 		if !criticalStepFailed || child.AlwaysExecuted {
-			r = w.runAction(ctx, child, jobID, secrets, childName)
+			r = w.runActionWithRetry(ctx, child, jobID, secrets, childName)
 			if r.Status != sdk.StatusSuccess && !child.Optional {
+				if !criticalStepFailed {
+					exitCode = r.ExitCode
+				}
 				criticalStepFailed = true
+				criticalStepTimeout = criticalStepTimeout || r.Status == sdk.StatusTimeout
 			}
 		} else if criticalStepFailed && !child.AlwaysExecuted {
 			r.Status = sdk.StatusNeverBuilt
@@ -267,8 +339,11 @@ func (w *CurrentWorker) runSteps(ctx context.Context, steps []sdk.Action, a sdk.
 		}
 	}
 
-	if criticalStepFailed {
+	if criticalStepTimeout {
+		r.Status = sdk.StatusTimeout
+	} else if criticalStepFailed {
 		r.Status = sdk.StatusFail
+		r.ExitCode = exitCode
 	} else {
 		r.Status = sdk.StatusSuccess
 	}
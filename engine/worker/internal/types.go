@@ -42,6 +42,7 @@ type CurrentWorker struct {
 		params       []sdk.Parameter
 		secrets      []sdk.Variable
 		context      context.Context
+		postJobs     []func(ctx context.Context) error
 	}
 	status struct {
 		Name   string `json:"name"`
@@ -67,6 +68,11 @@ func (wk *CurrentWorker) Parameters() []sdk.Parameter {
 	return wk.currentJob.params
 }
 
+// RegisterPostJob registers a function executed after the last step of the current job, if the job succeeded.
+func (wk *CurrentWorker) RegisterPostJob(f func(ctx context.Context) error) {
+	wk.currentJob.postJobs = append(wk.currentJob.postJobs, f)
+}
+
 func (wk *CurrentWorker) SendLog(ctx context.Context, level workerruntime.Level, s string) {
 	jobID, _ := workerruntime.JobID(ctx)
 	stepOrder, err := workerruntime.StepOrder(ctx)
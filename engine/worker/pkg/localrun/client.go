@@ -0,0 +1,201 @@
+package localrun
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ovh/venom"
+	"github.com/sguiheux/go-coverage"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+// client replaces the calls made by the worker to the CDS API during a local run, plugin binaries are
+// downloaded with the wrapped client.
+type client struct {
+	cdsclient.WorkerInterface
+	output       io.Writer
+	artifactsDir string
+}
+
+func errNotAvailable(feature string) error {
+	return fmt.Errorf("%s is not available when running a job locally", feature)
+}
+
+func (c *client) PluginGetBinaryInfos(name, os, arch string) (*sdk.GRPCPluginBinary, error) {
+	if c.WorkerInterface == nil {
+		return nil, fmt.Errorf("you should be logged in to download plugin %s", name)
+	}
+	return c.WorkerInterface.PluginGetBinaryInfos(name, os, arch)
+}
+
+func (c *client) PluginGetBinary(name, os, arch string, w io.Writer) error {
+	if c.WorkerInterface == nil {
+		return fmt.Errorf("you should be logged in to download plugin %s", name)
+	}
+	return c.WorkerInterface.PluginGetBinary(name, os, arch, w)
+}
+
+func (c *client) QueueSendLogs(ctx context.Context, id int64, l sdk.Log) error {
+	_, err := fmt.Fprint(c.output, l.Val)
+	return err
+}
+
+func (c *client) QueueSendStepResult(ctx context.Context, id int64, res sdk.StepStatus) error {
+	return nil
+}
+
+func (c *client) QueueSendResult(ctx context.Context, id int64, res sdk.Result) error {
+	return nil
+}
+
+func (c *client) QueueSendCoverage(ctx context.Context, id int64, report coverage.Report) error {
+	return nil
+}
+
+func (c *client) QueueSendUnitTests(ctx context.Context, id int64, report venom.Tests) error {
+	return nil
+}
+
+func (c *client) QueueSendVulnerability(ctx context.Context, id int64, report sdk.VulnerabilityWorkerReport) error {
+	return nil
+}
+
+func (c *client) QueueJobTag(ctx context.Context, jobID int64, tags []sdk.WorkflowRunTag) error {
+	return nil
+}
+
+func (c *client) QueueJobInfo(ctx context.Context, id int64) (*sdk.WorkflowNodeJobRun, error) {
+	return nil, errNotAvailable("job information")
+}
+
+// artifactTagDir returns the sub directory of the artifacts directory for given tag. The tag is escaped so it
+// can't point outside of the artifacts directory.
+func (c *client) artifactTagDir(tag string) (string, error) {
+	escaped := url.PathEscape(tag)
+	if escaped == "" || escaped == "." || escaped == ".." {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid artifact tag %q", tag)
+	}
+	return filepath.Join(c.artifactsDir, escaped), nil
+}
+
+// QueueArtifactUpload copies the file in a sub directory of the artifacts directory named by the tag.
+func (c *client) QueueArtifactUpload(ctx context.Context, projectKey, integrationName string, nodeJobRunID int64, tag, filePath string) (bool, time.Duration, error) {
+	t0 := time.Now()
+	src, err := os.Open(filePath)
+	if err != nil {
+		return false, 0, sdk.WithStack(err)
+	}
+	defer src.Close() // nolint
+
+	fi, err := src.Stat()
+	if err != nil {
+		return false, 0, sdk.WithStack(err)
+	}
+
+	dir, err := c.artifactTagDir(tag)
+	if err != nil {
+		return false, 0, err
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return false, 0, sdk.WithStack(err)
+	}
+	dst, err := os.OpenFile(filepath.Join(dir, filepath.Base(filePath)), os.O_RDWR|os.O_CREATE|os.O_TRUNC, fi.Mode())
+	if err != nil {
+		return false, 0, sdk.WithStack(err)
+	}
+	defer dst.Close() // nolint
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return false, 0, sdk.WithStack(err)
+	}
+	return false, time.Since(t0), nil
+}
+
+// WorkflowRunArtifacts returns the files of the artifacts directory, whatever the given run.
+func (c *client) WorkflowRunArtifacts(projectKey string, name string, number int64) ([]sdk.WorkflowNodeRunArtifact, error) {
+	tags, err := ioutil.ReadDir(c.artifactsDir)
+	if err != nil {
+		return nil, sdk.WithStack(err)
+	}
+
+	var arts []sdk.WorkflowNodeRunArtifact
+	for _, tag := range tags {
+		if !tag.IsDir() {
+			continue
+		}
+		tagName, err := url.PathUnescape(tag.Name())
+		if err != nil {
+			continue
+		}
+		files, err := ioutil.ReadDir(filepath.Join(c.artifactsDir, tag.Name()))
+		if err != nil {
+			return nil, sdk.WithStack(err)
+		}
+		for _, f := range files {
+			if f.IsDir() {
+				continue
+			}
+			arts = append(arts, sdk.WorkflowNodeRunArtifact{
+				Name:    f.Name(),
+				Tag:     tagName,
+				Size:    f.Size(),
+				Perm:    uint32(f.Mode().Perm()),
+				Created: f.ModTime(),
+			})
+		}
+	}
+	return arts, nil
+}
+
+func (c *client) WorkflowNodeRunArtifactDownload(projectKey string, name string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error {
+	dir, err := c.artifactTagDir(a.Tag)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(filepath.Join(dir, filepath.Base(a.Name)))
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer f.Close() // nolint
+
+	_, err = io.Copy(w, f)
+	return sdk.WithStack(err)
+}
+
+func (c *client) QueueArtifactPromote(ctx context.Context, jobID int64, req sdk.ArtifactPromoteRequest) ([]sdk.WorkflowNodeRunArtifact, error) {
+	return nil, errNotAvailable("artifact promotion")
+}
+
+func (c *client) QueueStaticFilesUpload(ctx context.Context, projectKey, integrationName string, nodeJobRunID int64, name, entrypoint, staticKey string, tarContent io.Reader) (string, bool, time.Duration, error) {
+	return "", false, 0, errNotAvailable("static files")
+}
+
+func (c *client) ProjectIntegrationGet(projectKey string, integrationName string, clearPassword bool) (sdk.ProjectIntegration, error) {
+	return sdk.ProjectIntegration{}, errNotAvailable("integration " + integrationName)
+}
+
+func (c *client) WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error {
+	return errNotAvailable("cache")
+}
+
+func (c *client) WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error) {
+	return nil, errNotAvailable("cache")
+}
+
+func (c *client) WorkflowCacheResolve(projectKey, integrationName string, tags []string) (*sdk.CacheEntry, error) {
+	return nil, errNotAvailable("cache")
+}
+
+func (c *client) WorkflowNodeRunRelease(projectKey string, workflowName string, runNumber int64, nodeRunID int64, release sdk.WorkflowNodeRunRelease) error {
+	return errNotAvailable("release")
+}
+
+var _ cdsclient.WorkerInterface = new(client)
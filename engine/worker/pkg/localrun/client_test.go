@@ -0,0 +1,46 @@
+package localrun
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientArtifactTag(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localrun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint
+
+	src := filepath.Join(dir, "out.txt")
+	require.NoError(t, ioutil.WriteFile(src, []byte("content"), os.FileMode(0644)))
+	c := &client{artifactsDir: filepath.Join(dir, "artifacts")}
+
+	// a tag can't point outside of the artifacts directory
+	for _, tag := range []string{"", ".", ".."} {
+		_, _, err := c.QueueArtifactUpload(context.TODO(), "", "", 0, tag, src)
+		assert.Error(t, err, "tag %q", tag)
+	}
+	for _, tag := range []string{"../escaped", "feature/my-branch", "v1"} {
+		_, _, err := c.QueueArtifactUpload(context.TODO(), "", "", 0, tag, src)
+		require.NoError(t, err)
+	}
+	_, err = os.Stat(filepath.Join(dir, "escaped"))
+	assert.True(t, os.IsNotExist(err))
+
+	arts, err := c.WorkflowRunArtifacts("", "", 0)
+	require.NoError(t, err)
+	var tags []string
+	for _, a := range arts {
+		tags = append(tags, a.Tag)
+		buf := new(bytes.Buffer)
+		require.NoError(t, c.WorkflowNodeRunArtifactDownload("", "", a, buf))
+		assert.Equal(t, "content", buf.String())
+	}
+	assert.ElementsMatch(t, []string{"../escaped", "feature/my-branch", "v1"}, tags)
+}
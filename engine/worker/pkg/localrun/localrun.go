@@ -0,0 +1,73 @@
+package localrun
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+
+	"github.com/ovh/cds/engine/worker/internal"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+// WorkerName is the name of the worker that runs local jobs.
+const WorkerName = "local"
+
+// Options of a job run on the local machine.
+type Options struct {
+	// Workspace is the directory where the working and keys directories of the job are created.
+	Workspace string
+	// ArtifactsDir is the directory where artifacts are uploaded to and downloaded from, by tag.
+	ArtifactsDir string
+	// Output receives the logs of the job, as they are sent to CDS by a worker.
+	Output io.Writer
+	// Client is used to download the binaries of action plugins, it can be nil.
+	Client cdsclient.WorkerInterface
+}
+
+// Run runs the steps of given job on the local machine with the worker engine, calls to the CDS API are
+// replaced by a client that prints logs to the output and stores artifacts in the artifacts directory.
+func Run(ctx context.Context, job sdk.Job, params []sdk.Parameter, secrets []sdk.Variable, opts Options) (sdk.Result, error) {
+	workspace, err := filepath.Abs(opts.Workspace)
+	if err != nil {
+		return sdk.Result{}, sdk.WithStack(err)
+	}
+	artifactsDir, err := filepath.Abs(opts.ArtifactsDir)
+	if err != nil {
+		return sdk.Result{}, sdk.WithStack(err)
+	}
+	for _, dir := range []string{workspace, artifactsDir} {
+		if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+			return sdk.Result{}, sdk.WithStack(err)
+		}
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+
+	// Parameters used by builtin actions that are given by the API to the worker
+	defaults := []sdk.Parameter{
+		{Name: "cds.project", Type: sdk.StringParameter, Value: WorkerName},
+		{Name: "cds.workflow", Type: sdk.StringParameter, Value: WorkerName},
+		{Name: "cds.run.number", Type: sdk.StringParameter, Value: "0"},
+		{Name: "cds.version", Type: sdk.StringParameter, Value: "0"},
+		{Name: "cds.job", Type: sdk.StringParameter, Value: job.Action.Name},
+	}
+	ps := append([]sdk.Parameter{}, params...)
+	for _, p := range defaults {
+		if sdk.ParameterFind(ps, p.Name) == nil {
+			ps = append(ps, p)
+		}
+	}
+
+	var w internal.CurrentWorker
+	w.InitLocal(WorkerName, afero.NewBasePathFs(afero.NewOsFs(), workspace), &client{
+		WorkerInterface: opts.Client,
+		output:          opts.Output,
+		artifactsDir:    artifactsDir,
+	})
+	return w.RunLocalJob(ctx, job, ps, secrets)
+}
@@ -0,0 +1,102 @@
+package localrun_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/worker/pkg/localrun"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localrun")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint
+
+	job := sdk.Job{
+		Action: sdk.Action{
+			Name: "Build",
+			Actions: []sdk.Action{
+				{
+					Name:    sdk.ScriptAction,
+					Type:    sdk.BuiltinAction,
+					Enabled: true,
+					Parameters: []sdk.Parameter{{
+						Name:  "script",
+						Type:  sdk.TextParameter,
+						Value: "echo \"building {{.cds.app.name}} with $MY_TOKEN\"\necho content > out.txt",
+					}},
+				},
+				{
+					Name:    sdk.ArtifactUpload,
+					Type:    sdk.BuiltinAction,
+					Enabled: true,
+					Parameters: []sdk.Parameter{
+						{Name: "path", Type: sdk.StringParameter, Value: "out.txt"},
+						{Name: "tag", Type: sdk.StringParameter, Value: "v1"},
+					},
+				},
+				{
+					Name:    sdk.ArtifactDownload,
+					Type:    sdk.BuiltinAction,
+					Enabled: true,
+					Parameters: []sdk.Parameter{
+						{Name: "path", Type: sdk.StringParameter, Value: "downloaded"},
+						{Name: "tag", Type: sdk.StringParameter, Value: "v1"},
+					},
+				},
+				{
+					Name:    sdk.ScriptAction,
+					Type:    sdk.BuiltinAction,
+					Enabled: true,
+					Parameters: []sdk.Parameter{{
+						Name:  "script",
+						Type:  sdk.TextParameter,
+						Value: "cat downloaded/out.txt",
+					}},
+				},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	res, err := localrun.Run(context.TODO(), job,
+		[]sdk.Parameter{{Name: "cds.app.name", Type: sdk.StringParameter, Value: "my-app"}},
+		[]sdk.Variable{{Name: "my.token", Type: sdk.SecretVariable, Value: "my-secret-token"}},
+		localrun.Options{
+			Workspace:    filepath.Join(dir, "workspace"),
+			ArtifactsDir: filepath.Join(dir, "artifacts"),
+			Output:       buf,
+		})
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusSuccess, res.Status, buf.String())
+
+	out := buf.String()
+	t.Log(out)
+	assert.Contains(t, out, "[INFO] Starting step \"Script\"\n")
+	assert.Contains(t, out, "[INFO] building my-app with "+sdk.PasswordPlaceholder+"\n")
+	assert.NotContains(t, out, "my-secret-token")
+	assert.Contains(t, out, "[INFO] content\n")
+
+	btes, err := ioutil.ReadFile(filepath.Join(dir, "artifacts", "v1", "out.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "content\n", string(btes))
+
+	// a failing step fails the job
+	job.Action.Actions = job.Action.Actions[:1]
+	job.Action.Actions[0].Parameters[0].Value = "exit 3"
+	res, err = localrun.Run(context.TODO(), job, nil, nil, localrun.Options{
+		Workspace:    filepath.Join(dir, "workspace"),
+		ArtifactsDir: filepath.Join(dir, "artifacts"),
+		Output:       new(bytes.Buffer),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusFail, res.Status)
+}
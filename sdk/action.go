@@ -25,6 +25,7 @@ const (
 	CheckoutApplicationAction = "CheckoutApplication"
 	DeployApplicationAction   = "DeployApplication"
 	InstallKeyAction          = "InstallKey"
+	CacheAction               = "Cache"
 
 	DefaultGitCloneParameterTagValue = "{{.git.tag}}"
 )
@@ -39,13 +40,16 @@ func NewAction(name string) *Action {
 
 // Action is the base element of CDS pipeline
 type Action struct {
-	ID          int64  `json:"id" yaml:"-" db:"id"`
-	GroupID     *int64 `json:"group_id,omitempty" yaml:"-" db:"group_id"`
-	Name        string `json:"name" db:"name"`
-	Type        string `json:"type" yaml:"-" db:"type"`
-	Description string `json:"description" yaml:"desc,omitempty" db:"description"`
-	Enabled     bool   `json:"enabled" yaml:"-" db:"enabled"`
-	Deprecated  bool   `json:"deprecated" yaml:"-" db:"deprecated"`
+	ID          int64             `json:"id" yaml:"-" db:"id"`
+	GroupID     *int64            `json:"group_id,omitempty" yaml:"-" db:"group_id"`
+	Name        string            `json:"name" db:"name"`
+	Type        string            `json:"type" yaml:"-" db:"type"`
+	Description string            `json:"description" yaml:"desc,omitempty" db:"description"`
+	Enabled     bool              `json:"enabled" yaml:"-" db:"enabled"`
+	Deprecated  bool              `json:"deprecated" yaml:"-" db:"deprecated"`
+	Timeout     int64             `json:"timeout,omitempty" yaml:"-" db:"timeout"` // in seconds, overridden by action_edge for a step
+	RetryPolicy ActionRetryPolicy `json:"retry_policy" yaml:"-" db:"retry_policy"` // overridden by action_edge for a step
+	Matrix      ActionMatrix      `json:"matrix" yaml:"-" db:"matrix"`             // only used for a job
 	// aggregates from action_edge
 	StepName       string `json:"step_name,omitempty" yaml:"step_name,omitempty" db:"-"`
 	Optional       bool   `json:"optional" yaml:"-" db:"-"`
@@ -93,6 +97,18 @@ func (a Action) IsValid() error {
 		return NewErrorFrom(ErrWrongRequest, "invalid name for action")
 	}
 
+	if a.Timeout < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid timeout for action")
+	}
+
+	if err := a.RetryPolicy.IsValid(); err != nil {
+		return err
+	}
+
+	if err := a.Matrix.IsValid(); err != nil {
+		return err
+	}
+
 	for i := range a.Parameters {
 		if err := a.Parameters[i].IsValid(); err != nil {
 			return err
@@ -107,6 +123,12 @@ func (a Action) IsValid() error {
 		if a.Actions[i].ID == 0 {
 			return NewErrorFrom(ErrWrongRequest, "invalid action id for child")
 		}
+		if a.Actions[i].Timeout < 0 {
+			return NewErrorFrom(ErrWrongRequest, "invalid timeout for child")
+		}
+		if err := a.Actions[i].RetryPolicy.IsValid(); err != nil {
+			return err
+		}
 		for j := range a.Actions[i].Parameters {
 			if err := a.Actions[i].Parameters[j].IsValid(); err != nil {
 				return err
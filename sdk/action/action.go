@@ -13,7 +13,9 @@ import (
 // List of all available actions.
 var List = []Manifest{
 	ArtifactDownload,
+	ArtifactPromote,
 	ArtifactUpload,
+	Cache,
 	CheckoutApplication,
 	Coverage,
 	DeployApplication,
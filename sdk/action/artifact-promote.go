@@ -0,0 +1,92 @@
+package action
+
+import (
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+// ArtifactPromote action definition.
+var ArtifactPromote = Manifest{
+	Action: sdk.Action{
+		Name: sdk.ArtifactPromote,
+		Description: `This action promotes the artifacts of a run of another workflow into the current run, the artifacts keep the provenance of the run that built them.
+The groups that can execute the job should be able to read the source workflow.`,
+		Parameters: []sdk.Parameter{
+			{
+				Name:        "project",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Key of the project of the source workflow, the current project if empty.",
+				Value:       "",
+			},
+			{
+				Name:        "workflow",
+				Type:        sdk.StringParameter,
+				Description: "Name of the source workflow.",
+			},
+			{
+				Name:        "number",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Number of the source run. Empty: the last successful run of the workflow.",
+				Value:       "",
+			},
+			{
+				Name:        "branch",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Branch of the last successful run of the workflow, ignored if a number is given.",
+				Value:       "",
+			},
+			{
+				Name:        "pattern",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Empty: promote all artifacts. Otherwise, enter regexp pattern to choose artifacts: (fileA|fileB).",
+				Value:       "",
+			},
+			{
+				Name:        "tag",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Promote only the artifacts uploaded with this tag.",
+				Value:       "",
+				Advanced:    true,
+			},
+			{
+				Name:        "destination-tag",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Tag of the promoted artifacts, the tag of the source artifacts if empty.",
+				Value:       "",
+				Advanced:    true,
+			},
+			{
+				Name:        "mode",
+				Type:        sdk.ListParameter,
+				Description: "link: the promoted artifacts share the data of the source artifacts when they are stored by content in the same storage, otherwise they are copied. copy: the data is always copied.",
+				Value:       "link;copy",
+				Advanced:    true,
+			},
+			{
+				Name:        "destination",
+				Description: "(optional) Storage of the promoted artifacts. Use the name of integration attached on your project.",
+				Value:       "", // empty is the default value
+				Type:        sdk.StringParameter,
+				Advanced:    true,
+			},
+		},
+	},
+	Example: exportentities.PipelineV1{
+		Version: exportentities.PipelineVersion1,
+		Name:    "Pipeline1",
+		Stages:  []string{"Stage1"},
+		Jobs: []exportentities.Job{{
+			Name:  "Job1",
+			Stage: "Stage1",
+			Steps: []exportentities.Step{
+				{
+					ArtifactPromote: &exportentities.StepArtifactPromote{
+						Workflow: "build",
+						Branch:   "master",
+						Pattern:  ".*\\.tar\\.gz",
+					},
+				},
+			},
+		}},
+	},
+}
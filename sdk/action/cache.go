@@ -0,0 +1,66 @@
+package action
+
+import (
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+// Cache action definition.
+var Cache = Manifest{
+	Action: sdk.Action{
+		Name: sdk.CacheAction,
+		Description: `This action restores a dependency cache in the workspace and saves it at the end of the job when no cache matched the key.
+Caches are scoped by project and branch, a cache from the default branch is used when none exists for the current branch.
+A cache that can't be restored is handled as a cache miss.`,
+		Parameters: []sdk.Parameter{
+			{
+				Name:        "key",
+				Type:        sdk.StringParameter,
+				Description: `Key of the cache, hashFiles returns the hash of the files matching given patterns, example: go-{{ hashFiles "go.sum" }}.`,
+			},
+			{
+				Name:        "restore-keys",
+				Type:        sdk.TextParameter,
+				Description: "(optional) Keys prefixes used to restore a cache when no cache matches the key, one per line, example: go-.",
+				Value:       "",
+			},
+			{
+				Name:        "path",
+				Type:        sdk.TextParameter,
+				Description: "Paths of the directories or files to cache, one per line, example: ./vendor.",
+			},
+			{
+				Name:        "default-branch",
+				Type:        sdk.StringParameter,
+				Description: "(optional) Branch whose caches are used when no cache exists for the current branch.",
+				Value:       "master",
+				Advanced:    true,
+			},
+			{
+				Name:        "destination",
+				Description: "(optional) Storage of the cache. Use the name of integration attached on your project.",
+				Value:       "", // empty is the default value
+				Type:        sdk.StringParameter,
+				Advanced:    true,
+			},
+		},
+	},
+	Example: exportentities.PipelineV1{
+		Version: exportentities.PipelineVersion1,
+		Name:    "Pipeline1",
+		Stages:  []string{"Stage1"},
+		Jobs: []exportentities.Job{{
+			Name:  "Job1",
+			Stage: "Stage1",
+			Steps: []exportentities.Step{
+				{
+					Cache: &exportentities.StepCache{
+						Key:         `go-{{ hashFiles "go.sum" }}`,
+						RestoreKeys: "go-",
+						Path:        "./vendor",
+					},
+				},
+			},
+		}},
+	},
+}
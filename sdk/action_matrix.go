@@ -0,0 +1,183 @@
+package sdk
+
+import (
+	"database/sql/driver"
+	json "encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxMatrixCombinations is the maximum number of job runs that a matrix can generate.
+const MaxMatrixCombinations = 256
+
+var matrixAxisNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+// ActionMatrix describes the variations of a job, each combination of axis values
+// is run as a distinct job run with its own cds.matrix.* variables.
+type ActionMatrix struct {
+	Axes    map[string][]string `json:"axes,omitempty"`
+	Include []map[string]string `json:"include,omitempty"`
+	Exclude []map[string]string `json:"exclude,omitempty"`
+}
+
+// Value returns driver.Value from action matrix.
+func (m ActionMatrix) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, WrapError(err, "cannot marshal ActionMatrix")
+}
+
+// Scan action matrix.
+func (m *ActionMatrix) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, m), "cannot unmarshal ActionMatrix")
+}
+
+// IsEmpty returns true if there is no axis and no included combination.
+func (m ActionMatrix) IsEmpty() bool {
+	return len(m.Axes) == 0 && len(m.Include) == 0
+}
+
+// IsValid returns an error if the matrix is not valid.
+func (m ActionMatrix) IsValid() error {
+	for name, values := range m.Axes {
+		if !matrixAxisNamePattern.MatchString(name) {
+			return NewErrorFrom(ErrWrongRequest, "invalid matrix axis name %q", name)
+		}
+		if len(values) == 0 {
+			return NewErrorFrom(ErrWrongRequest, "invalid matrix axis %s: at least one value is required", name)
+		}
+	}
+	for _, c := range append(append([]map[string]string{}, m.Include...), m.Exclude...) {
+		if len(c) == 0 {
+			return NewErrorFrom(ErrWrongRequest, "invalid empty matrix include or exclude entry")
+		}
+		for name := range c {
+			if !matrixAxisNamePattern.MatchString(name) {
+				return NewErrorFrom(ErrWrongRequest, "invalid matrix axis name %q", name)
+			}
+		}
+	}
+	// the number of axis combinations is checked before generating them to not allocate huge matrices
+	if len(m.Axes) > 0 {
+		n := 1
+		for _, values := range m.Axes {
+			n *= len(values)
+			if n > MaxMatrixCombinations {
+				return NewErrorFrom(ErrWrongRequest, "matrix generates more than %d combinations", MaxMatrixCombinations)
+			}
+		}
+	}
+	if n := len(m.Combinations()); n > MaxMatrixCombinations {
+		return NewErrorFrom(ErrWrongRequest, "matrix generates %d combinations, maximum is %d", n, MaxMatrixCombinations)
+	}
+	return nil
+}
+
+// AxisNames returns the sorted list of axis names.
+func (m ActionMatrix) AxisNames() []string {
+	names := make([]string, 0, len(m.Axes))
+	for name := range m.Axes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Combinations returns all combinations of axis values, without the excluded
+// ones and followed by the included ones.
+func (m ActionMatrix) Combinations() []map[string]string {
+	var res []map[string]string
+	if len(m.Axes) > 0 {
+		res = []map[string]string{{}}
+		for _, name := range m.AxisNames() {
+			next := make([]map[string]string, 0, len(res)*len(m.Axes[name]))
+			for _, c := range res {
+				for _, v := range m.Axes[name] {
+					nc := make(map[string]string, len(c)+1)
+					for k := range c {
+						nc[k] = c[k]
+					}
+					nc[name] = v
+					next = append(next, nc)
+				}
+			}
+			res = next
+		}
+	}
+
+	filtered := res[:0]
+	for _, c := range res {
+		var excluded bool
+		for _, e := range m.Exclude {
+			if matrixCombinationMatches(c, e) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			filtered = append(filtered, c)
+		}
+	}
+	res = filtered
+
+	for _, i := range m.Include {
+		var found bool
+		for _, c := range res {
+			if len(c) == len(i) && matrixCombinationMatches(c, i) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			res = append(res, i)
+		}
+	}
+
+	return res
+}
+
+// matrixCombinationMatches returns true if all values of the given filter are in the combination.
+func matrixCombinationMatches(combination, filter map[string]string) bool {
+	for k, v := range filter {
+		if cv, ok := combination[k]; !ok || cv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// MatrixCombinationString returns a readable representation of a combination (ex: arch=amd64, go=1.13).
+func MatrixCombinationString(combination map[string]string) string {
+	names := make([]string, 0, len(combination))
+	for name := range combination {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = name + "=" + combination[name]
+	}
+	return strings.Join(values, ", ")
+}
+
+// MatrixParameters returns cds.matrix.* parameters for the given combination.
+func MatrixParameters(combination map[string]string) []Parameter {
+	names := make([]string, 0, len(combination))
+	for name := range combination {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	params := make([]Parameter, 0, len(names))
+	for _, name := range names {
+		AddParameter(&params, "cds.matrix."+name, StringParameter, combination[name])
+	}
+	return params
+}
@@ -0,0 +1,84 @@
+package sdk_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestActionMatrixCombinations(t *testing.T) {
+	m := sdk.ActionMatrix{
+		Axes: map[string][]string{
+			"go":   {"1.12", "1.13"},
+			"arch": {"amd64", "arm"},
+		},
+		Exclude: []map[string]string{
+			{"go": "1.12", "arch": "arm"},
+		},
+		Include: []map[string]string{
+			{"go": "1.14", "arch": "amd64"},
+			{"go": "1.13", "arch": "arm"},
+		},
+	}
+	assert.NoError(t, m.IsValid())
+
+	cs := m.Combinations()
+	assert.Equal(t, []map[string]string{
+		{"arch": "amd64", "go": "1.12"},
+		{"arch": "amd64", "go": "1.13"},
+		{"arch": "arm", "go": "1.13"},
+		{"arch": "amd64", "go": "1.14"},
+	}, cs)
+
+	assert.Equal(t, "arch=amd64, go=1.12", sdk.MatrixCombinationString(cs[0]))
+	assert.Equal(t, []sdk.Parameter{
+		{Name: "cds.matrix.arch", Type: sdk.StringParameter, Value: "amd64"},
+		{Name: "cds.matrix.go", Type: sdk.StringParameter, Value: "1.12"},
+	}, sdk.MatrixParameters(cs[0]))
+}
+
+func TestActionMatrixExcludePartial(t *testing.T) {
+	m := sdk.ActionMatrix{
+		Axes: map[string][]string{
+			"go": {"1.12", "1.13"},
+			"os": {"linux", "windows", "darwin"},
+		},
+		Exclude: []map[string]string{
+			{"os": "windows"},
+		},
+	}
+	assert.Len(t, m.Combinations(), 4)
+}
+
+func TestActionMatrixIsValid(t *testing.T) {
+	assert.NoError(t, sdk.ActionMatrix{}.IsValid())
+	assert.True(t, sdk.ActionMatrix{}.IsEmpty())
+	assert.Len(t, sdk.ActionMatrix{}.Combinations(), 0)
+
+	assert.Error(t, sdk.ActionMatrix{Axes: map[string][]string{"go.version": {"1.13"}}}.IsValid())
+	assert.Error(t, sdk.ActionMatrix{Axes: map[string][]string{"go": {}}}.IsValid())
+	assert.Error(t, sdk.ActionMatrix{Include: []map[string]string{{}}}.IsValid())
+
+	values := make([]string, 20)
+	assert.Error(t, sdk.ActionMatrix{Axes: map[string][]string{"a": values, "b": values}}.IsValid())
+
+	// a huge matrix is rejected without generating its combinations
+	values = make([]string, 1000)
+	err := sdk.ActionMatrix{Axes: map[string][]string{"a": values, "b": values, "c": values, "d": values}}.IsValid()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than 256 combinations")
+
+	// included combinations are counted with the axis ones
+	values = make([]string, 16)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+	m := sdk.ActionMatrix{Axes: map[string][]string{"a": values, "b": values}}
+	assert.NoError(t, m.IsValid())
+	m.Include = []map[string]string{{"a": "16"}}
+	assert.Error(t, m.IsValid())
+}
@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"database/sql/driver"
+	json "encoding/json"
+	"fmt"
+	"time"
+)
+
+// maxRetryBackoff is the maximum delay between two attempts.
+const maxRetryBackoff = time.Hour
+
+// ActionRetryPolicy describes how a failed job or step has to be retried.
+type ActionRetryPolicy struct {
+	MaxAttempts             int   `json:"max_attempts,omitempty"`
+	Backoff                 int64 `json:"backoff,omitempty"` // in seconds, doubled after each attempt
+	ExitCodes               []int `json:"exit_codes,omitempty"`
+	OnInfrastructureFailure bool  `json:"on_infrastructure_failure,omitempty"`
+}
+
+// Value returns driver.Value from action retry policy.
+func (p ActionRetryPolicy) Value() (driver.Value, error) {
+	j, err := json.Marshal(p)
+	return j, WrapError(err, "cannot marshal ActionRetryPolicy")
+}
+
+// Scan action retry policy.
+func (p *ActionRetryPolicy) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, p), "cannot unmarshal ActionRetryPolicy")
+}
+
+// IsValid returns an error if the retry policy is not valid.
+func (p ActionRetryPolicy) IsValid() error {
+	if p.MaxAttempts < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid max attempts for retry policy")
+	}
+	if p.Backoff < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid backoff for retry policy")
+	}
+	return nil
+}
+
+// IsEmpty returns true if no retry is configured.
+func (p ActionRetryPolicy) IsEmpty() bool {
+	return p.MaxAttempts <= 1
+}
+
+// ShouldRetry returns true if a new attempt has to be started for the given result.
+// Attempt is the number of the attempt that produced the result, starting at 1.
+// A failure without exit code is considered as an infrastructure failure.
+func (p ActionRetryPolicy) ShouldRetry(attempt int, res Result) bool {
+	if p.IsEmpty() || attempt >= p.MaxAttempts || res.Status != StatusFail {
+		return false
+	}
+	if len(p.ExitCodes) == 0 && !p.OnInfrastructureFailure {
+		return true
+	}
+	if res.ExitCode == 0 {
+		return p.OnInfrastructureFailure
+	}
+	for _, c := range p.ExitCodes {
+		if c == res.ExitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the delay to wait before starting the attempt following the given one.
+func (p ActionRetryPolicy) Delay(attempt int) time.Duration {
+	if p.Backoff <= 0 || attempt < 1 {
+		return 0
+	}
+	d := time.Duration(p.Backoff) * time.Second
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxRetryBackoff {
+			return maxRetryBackoff
+		}
+	}
+	return d
+}
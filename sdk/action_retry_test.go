@@ -0,0 +1,86 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestActionRetryPolicyShouldRetry(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy sdk.ActionRetryPolicy
+		res    sdk.Result
+		exp    []bool
+	}{
+		{
+			name:   "no policy",
+			policy: sdk.ActionRetryPolicy{},
+			res:    sdk.Result{Status: sdk.StatusFail, ExitCode: 1},
+			exp:    []bool{false},
+		},
+		{
+			name:   "any failure",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 3},
+			res:    sdk.Result{Status: sdk.StatusFail, ExitCode: 1},
+			exp:    []bool{true, true, false},
+		},
+		{
+			name:   "success",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 3},
+			res:    sdk.Result{Status: sdk.StatusSuccess},
+			exp:    []bool{false},
+		},
+		{
+			name:   "timeout",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 3},
+			res:    sdk.Result{Status: sdk.StatusTimeout},
+			exp:    []bool{false},
+		},
+		{
+			name:   "matching exit code",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 2, ExitCodes: []int{1, 128}},
+			res:    sdk.Result{Status: sdk.StatusFail, ExitCode: 128},
+			exp:    []bool{true, false},
+		},
+		{
+			name:   "not matching exit code",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 2, ExitCodes: []int{1, 128}},
+			res:    sdk.Result{Status: sdk.StatusFail, ExitCode: 2},
+			exp:    []bool{false},
+		},
+		{
+			name:   "infrastructure failure",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 2, OnInfrastructureFailure: true},
+			res:    sdk.Result{Status: sdk.StatusFail},
+			exp:    []bool{true, false},
+		},
+		{
+			name:   "not an infrastructure failure",
+			policy: sdk.ActionRetryPolicy{MaxAttempts: 2, OnInfrastructureFailure: true},
+			res:    sdk.Result{Status: sdk.StatusFail, ExitCode: 1},
+			exp:    []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, exp := range tt.exp {
+				assert.Equal(t, exp, tt.policy.ShouldRetry(i+1, tt.res), "attempt %d", i+1)
+			}
+		})
+	}
+}
+
+func TestActionRetryPolicyDelay(t *testing.T) {
+	p := sdk.ActionRetryPolicy{MaxAttempts: 20, Backoff: 10}
+	assert.Equal(t, 10*time.Second, p.Delay(1))
+	assert.Equal(t, 20*time.Second, p.Delay(2))
+	assert.Equal(t, 40*time.Second, p.Delay(3))
+	assert.Equal(t, time.Hour, p.Delay(15))
+
+	assert.Equal(t, time.Duration(0), sdk.ActionRetryPolicy{MaxAttempts: 2}.Delay(1))
+}
@@ -1,14 +1,122 @@
 package sdk
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // Builtin artifact manipulation actions
 const (
 	ArtifactUpload   = "Artifact Upload"
 	ArtifactDownload = "Artifact Download"
+	ArtifactPromote  = "Artifact Promote"
 	ServeStaticFiles = "Serve Static Files"
 )
 
+// Artifact promotion modes, a linked artifact shares the data of its source, it is only available
+// for artifacts stored by content in the same storage, others are copied.
+const (
+	ArtifactPromoteModeLink = "link"
+	ArtifactPromoteModeCopy = "copy"
+)
+
 // ArtifactsStore represents
 type ArtifactsStore struct {
 	Name                  string `json:"name"`
 	TemporaryURLSupported bool   `json:"temporary_url_supported"`
 }
+
+// ArtifactBlob is the content of artifacts stored by SHA-256 when the content-addressed
+// mode is enabled. A blob is shared by all the artifacts with the same content in a storage,
+// it is deleted when its last reference is removed.
+type ArtifactBlob struct {
+	ID                   int64     `json:"id" db:"id"`
+	SHA256               string    `json:"sha256" db:"sha256"`
+	ProjectIntegrationID int64     `json:"project_integration_id" db:"project_integration_id"`
+	Size                 int64     `json:"size" db:"size"`
+	ObjectPath           string    `json:"object_path" db:"object_path"`
+	Stored               bool      `json:"stored" db:"stored"`
+	RefCount             int64     `json:"ref_count" db:"ref_count"`
+	Created              time.Time `json:"created" db:"created"`
+}
+
+// GetName returns the name of the blob in the storage.
+func (b *ArtifactBlob) GetName() string {
+	return b.SHA256
+}
+
+// GetPath returns the container of the blob in the storage, blobs are spread in containers
+// according to the first characters of their hash.
+func (b *ArtifactBlob) GetPath() string {
+	if len(b.SHA256) < 2 {
+		return "blobs"
+	}
+	return "blobs-" + b.SHA256[:2]
+}
+
+// ArtifactPromoteRequest is the request sent by a job to promote the artifacts of a workflow run
+// into its own run. The source run is given by its number, or is the last successful run
+// of the workflow, on given branch if any.
+type ArtifactPromoteRequest struct {
+	ProjectKey      string `json:"project_key"`
+	WorkflowName    string `json:"workflow_name"`
+	Number          int64  `json:"number,omitempty"`
+	Branch          string `json:"branch,omitempty"`
+	Pattern         string `json:"pattern,omitempty"`
+	Tag             string `json:"tag,omitempty"`
+	Mode            string `json:"mode,omitempty"`
+	DestinationTag  string `json:"destination_tag,omitempty"`
+	IntegrationName string `json:"integration_name,omitempty"`
+}
+
+// IsValid returns an error if the request is not valid.
+func (r ArtifactPromoteRequest) IsValid() error {
+	if r.ProjectKey == "" || r.WorkflowName == "" {
+		return NewErrorFrom(ErrWrongRequest, "project key and workflow name are mandatory")
+	}
+	if r.Number > 0 && r.Branch != "" {
+		return NewErrorFrom(ErrWrongRequest, "number and branch can't be set together")
+	}
+	switch r.Mode {
+	case "", ArtifactPromoteModeLink, ArtifactPromoteModeCopy:
+	default:
+		return NewErrorFrom(ErrWrongRequest, "invalid mode %q, should be %s or %s", r.Mode, ArtifactPromoteModeLink, ArtifactPromoteModeCopy)
+	}
+	return nil
+}
+
+// ArtifactProvenance is the origin of a promoted artifact, the run that built it. It is kept
+// when an artifact is promoted several times.
+type ArtifactProvenance struct {
+	ProjectKey   string    `json:"project_key"`
+	WorkflowName string    `json:"workflow_name"`
+	RunNumber    int64     `json:"run_number"`
+	NodeRunID    int64     `json:"node_run_id"`
+	ArtifactID   int64     `json:"artifact_id"`
+	Repository   string    `json:"repository,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	Commit       string    `json:"commit,omitempty"`
+	MD5sum       string    `json:"md5sum,omitempty"`
+	SHA512sum    string    `json:"sha512sum,omitempty"`
+	Promoted     time.Time `json:"promoted"`
+}
+
+// Value returns driver.Value from artifact provenance.
+func (p ArtifactProvenance) Value() (driver.Value, error) {
+	j, err := json.Marshal(p)
+	return j, WrapError(err, "cannot marshal ArtifactProvenance")
+}
+
+// Scan artifact provenance.
+func (p *ArtifactProvenance) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, p), "cannot unmarshal ArtifactProvenance")
+}
@@ -0,0 +1,41 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArtifactPromoteRequestIsValid(t *testing.T) {
+	assert.NoError(t, ArtifactPromoteRequest{ProjectKey: "PROJ", WorkflowName: "build"}.IsValid())
+	assert.NoError(t, ArtifactPromoteRequest{ProjectKey: "PROJ", WorkflowName: "build", Number: 12, Mode: ArtifactPromoteModeCopy}.IsValid())
+	assert.NoError(t, ArtifactPromoteRequest{ProjectKey: "PROJ", WorkflowName: "build", Branch: "master", Mode: ArtifactPromoteModeLink}.IsValid())
+
+	assert.Error(t, ArtifactPromoteRequest{ProjectKey: "PROJ"}.IsValid())
+	assert.Error(t, ArtifactPromoteRequest{ProjectKey: "PROJ", WorkflowName: "build", Number: 12, Branch: "master"}.IsValid())
+	assert.Error(t, ArtifactPromoteRequest{ProjectKey: "PROJ", WorkflowName: "build", Mode: "move"}.IsValid())
+}
+
+func TestArtifactProvenanceValueScan(t *testing.T) {
+	p := ArtifactProvenance{
+		ProjectKey:   "PROJ",
+		WorkflowName: "build",
+		RunNumber:    12,
+		Branch:       "master",
+		Commit:       "abcdef",
+		SHA512sum:    "123456",
+		Promoted:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	v, err := p.Value()
+	require.NoError(t, err)
+
+	var res ArtifactProvenance
+	require.NoError(t, res.Scan(v))
+	assert.Equal(t, p, res)
+
+	var empty ArtifactProvenance
+	require.NoError(t, empty.Scan(nil))
+	assert.Equal(t, ArtifactProvenance{}, empty)
+}
@@ -109,6 +109,8 @@ const (
 	StatusUnknown           = "Unknown"
 	StatusSkipped           = "Skipped"
 	StatusStopped           = "Stopped"
+	StatusTimeout           = "Timeout"
+	StatusWaitingApproval   = "Waiting approval"
 	StatusWorkerPending     = "Pending"
 	StatusWorkerRegistering = "Registering"
 )
@@ -116,7 +118,7 @@ const (
 // StatusIsTerminated returns if status is terminated (nothing related to building or waiting, ...)
 func StatusIsTerminated(status string) bool {
 	switch status {
-	case StatusBuilding, StatusWaiting:
+	case StatusBuilding, StatusWaiting, StatusWaitingApproval:
 		return false
 	default:
 		return true
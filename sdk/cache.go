@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -22,11 +23,48 @@ type Cache struct {
 	TmpURL          string `json:"tmp_url"`
 	SecretKey       string `json:"secret_key"`
 	IntegrationName string `json:"integration_name"`
+	Size            int64  `json:"size,omitempty"`
 
 	Files            []string `json:"files"`
 	WorkingDirectory string   `json:"working_directory"`
 }
 
+// CacheEntry is a cache stored on a storage integration of a project, used to resolve
+// cache keys and to evict the least recently used caches.
+type CacheEntry struct {
+	ID              int64     `json:"id" db:"id"`
+	ProjectID       int64     `json:"project_id" db:"project_id"`
+	IntegrationName string    `json:"integration_name" db:"integration_name"`
+	Tag             string    `json:"tag" db:"tag" cli:"tag"`
+	Size            int64     `json:"size" db:"size" cli:"size"`
+	Created         time.Time `json:"created" db:"created" cli:"created"`
+	LastAccess      time.Time `json:"last_access" db:"last_access" cli:"last_access"`
+}
+
+// CacheTag returns the tag of a cache for given branch and key. Forbidden characters and '_' are escaped
+// with '_' followed by their hexadecimal code so the tag matches the name pattern and two branches or keys
+// can't have the same tag. The branch can't contain any dot to keep the tag unambiguous.
+func CacheTag(branch, key string) string {
+	key = cacheTagEscape(key, ".-")
+	if branch == "" {
+		return key
+	}
+	return cacheTagEscape(branch, "-") + "." + key
+}
+
+func cacheTagEscape(s string, allowed string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte(allowed, c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "_%02x", c)
+	}
+	return b.String()
+}
+
 //GetName returns the name the artifact
 func (c *Cache) GetName() string {
 	return c.Name
@@ -134,3 +172,135 @@ func CreateTarFromPaths(fs afero.Fs, cwd string, paths []string, opts *TarOption
 
 	return res, size, nil
 }
+
+// ExtractTarToPath extracts a tar formatted reader in given directory. Entries and links that are not
+// contained in the directory are rejected.
+func ExtractTarToPath(r io.Reader, path string) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return WrapError(err, "unable to create directory %s", path)
+	}
+	root, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return WrapError(err, "unable to resolve directory %s", path)
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return WithStack(err)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return WrapError(err, "unable to read tar file")
+		}
+		if header == nil {
+			continue
+		}
+
+		if filepath.IsAbs(header.Name) || pathHasParentElem(header.Name) {
+			return NewErrorFrom(ErrWrongRequest, "invalid path %s in tar file", header.Name)
+		}
+		target := filepath.Join(root, header.Name)
+		if target == root {
+			continue
+		}
+
+		// The parent directory is resolved to check that it was not replaced by a link outside the directory
+		dir := filepath.Dir(target)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return WrapError(err, "unable to create directory %s", dir)
+		}
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return WrapError(err, "unable to resolve directory %s", dir)
+		}
+		if !pathIsWithin(root, realDir) {
+			return NewErrorFrom(ErrWrongRequest, "invalid path %s in tar file", header.Name)
+		}
+		target = filepath.Join(realDir, filepath.Base(target))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return WrapError(err, "unable to create directory %s", target)
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) || !pathIsWithin(root, filepath.Join(realDir, header.Linkname)) {
+				return NewErrorFrom(ErrWrongRequest, "invalid link %s to %s in tar file", header.Name, header.Linkname)
+			}
+			if err := removeIfExists(target); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return WrapError(err, "unable to create symlink %s", target)
+			}
+		case tar.TypeLink:
+			if filepath.IsAbs(header.Linkname) || pathHasParentElem(header.Linkname) {
+				return NewErrorFrom(ErrWrongRequest, "invalid link %s to %s in tar file", header.Name, header.Linkname)
+			}
+			source, err := filepath.EvalSymlinks(filepath.Join(root, header.Linkname))
+			if err != nil {
+				return WrapError(err, "unable to resolve link %s to %s", header.Name, header.Linkname)
+			}
+			if !pathIsWithin(root, source) {
+				return NewErrorFrom(ErrWrongRequest, "invalid link %s to %s in tar file", header.Name, header.Linkname)
+			}
+			if err := removeIfExists(target); err != nil {
+				return err
+			}
+			if err := os.Link(source, target); err != nil {
+				return WrapError(err, "unable to create link %s", target)
+			}
+		case tar.TypeReg:
+			// An existing link is replaced to not write the content of the file at its target
+			if err := removeIfExists(target); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return WrapError(err, "unable to open file %s", target)
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				_ = f.Close()
+				return WrapError(err, "unable to write file %s", target)
+			}
+			_ = f.Close()
+		}
+	}
+}
+
+func pathHasParentElem(p string) bool {
+	for _, e := range strings.Split(filepath.ToSlash(p), "/") {
+		if e == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// pathIsWithin returns true if given path is the root directory or one of its descendants.
+func pathIsWithin(root, p string) bool {
+	rel, err := filepath.Rel(root, filepath.Clean(p))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func removeIfExists(p string) error {
+	fi, err := os.Lstat(p)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return WithStack(err)
+	}
+	if fi.IsDir() {
+		return NewErrorFrom(ErrWrongRequest, "%s is an existing directory", p)
+	}
+	return WithStack(os.Remove(p))
+}
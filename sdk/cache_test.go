@@ -0,0 +1,82 @@
+package sdk_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestCacheTag(t *testing.T) {
+	assert.Equal(t, "go.sum", sdk.CacheTag("", "go.sum"))
+	assert.Equal(t, "master.go.sum", sdk.CacheTag("master", "go.sum"))
+	assert.Equal(t, "feat_2fx.node_5fmodules", sdk.CacheTag("feat/x", "node_modules"))
+	assert.NotEqual(t, sdk.CacheTag("feat/x", "key"), sdk.CacheTag("feat-x", "key"))
+	assert.NotEqual(t, sdk.CacheTag("feat/x", "key"), sdk.CacheTag("feat_2fx", "key"))
+	assert.NotEqual(t, sdk.CacheTag("a.b", "c"), sdk.CacheTag("a", "b.c"))
+	assert.Regexp(t, sdk.NamePatternRegex, sdk.CacheTag("feat/é", "~/.m2"))
+}
+
+func TestExtractTarToPath(t *testing.T) {
+	type entry struct {
+		name, link string
+		typ        byte
+		content    string
+	}
+	newTar := func(entries ...entry) *bytes.Buffer {
+		buf := new(bytes.Buffer)
+		tw := tar.NewWriter(buf)
+		for _, e := range entries {
+			require.NoError(t, tw.WriteHeader(&tar.Header{Name: e.name, Linkname: e.link, Typeflag: e.typ, Mode: 0644, Size: int64(len(e.content))}))
+			_, err := tw.Write([]byte(e.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		return buf
+	}
+
+	root, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(root) // nolint
+	dir := filepath.Join(root, "workspace")
+
+	require.NoError(t, sdk.ExtractTarToPath(newTar(
+		entry{name: "dir", typ: tar.TypeDir},
+		entry{name: "dir/file", typ: tar.TypeReg, content: "content"},
+		entry{name: "dir/symlink", typ: tar.TypeSymlink, link: "file"},
+		entry{name: "hardlink", typ: tar.TypeLink, link: "dir/file"},
+	), dir))
+	btes, err := ioutil.ReadFile(filepath.Join(dir, "dir", "symlink"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(btes))
+	btes, err = ioutil.ReadFile(filepath.Join(dir, "hardlink"))
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(btes))
+
+	invalids := map[string]*bytes.Buffer{
+		"parent":            newTar(entry{name: "../evil", typ: tar.TypeReg, content: "evil"}),
+		"nested parent":     newTar(entry{name: "dir/../../evil", typ: tar.TypeReg, content: "evil"}),
+		"absolute":          newTar(entry{name: filepath.Join(root, "evil"), typ: tar.TypeReg, content: "evil"}),
+		"absolute symlink":  newTar(entry{name: "link", typ: tar.TypeSymlink, link: root}),
+		"escaping symlink":  newTar(entry{name: "dir/link", typ: tar.TypeSymlink, link: "../.."}),
+		"escaping hardlink": newTar(entry{name: "link", typ: tar.TypeLink, link: "../evil"}),
+		"through symlink": newTar(
+			entry{name: "up", typ: tar.TypeSymlink, link: "."},
+			entry{name: "up/link", typ: tar.TypeSymlink, link: "../evil"},
+		),
+	}
+	for name, tr := range invalids {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, sdk.ExtractTarToPath(tr, dir))
+			_, err := os.Lstat(filepath.Join(root, "evil"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
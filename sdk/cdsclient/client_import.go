@@ -198,3 +198,28 @@ func (c *client) WorkflowPush(projectKey string, tarContent io.Reader, mods ...R
 
 	return messages, tarReader, nil
 }
+
+func (c *client) WorkflowPlan(projectKey string, tarContent io.Reader, mods ...RequestModifier) (*sdk.WorkflowPlan, error) {
+	url := fmt.Sprintf("/project/%s/push/workflows/plan", projectKey)
+
+	mods = append(mods,
+		func(r *http.Request) {
+			r.Header.Set("Content-Type", "application/tar")
+		})
+
+	btes, _, code, err := c.Request(context.Background(), "POST", url, tarContent, mods...)
+	if err != nil {
+		return nil, err
+	}
+
+	if code >= 400 {
+		return nil, fmt.Errorf("HTTP Status code %d", code)
+	}
+
+	var plan sdk.WorkflowPlan
+	if err := json.Unmarshal(btes, &plan); err != nil {
+		return nil, sdk.WithStack(err)
+	}
+
+	return &plan, nil
+}
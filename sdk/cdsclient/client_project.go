@@ -64,6 +64,68 @@ func (c *client) ProjectList(withApplications, withWorkflows bool, filters ...Fi
 	return p, nil
 }
 
+func (c *client) ProjectDeliveryMetrics(projectKey string, filters ...Filter) ([]sdk.DeliveryMetrics, error) {
+	var res []sdk.DeliveryMetrics
+	path := fmt.Sprintf("/project/%s/metrics/delivery", projectKey)
+
+	for i, f := range filters {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		path += fmt.Sprintf("%s%s=%s", sep, url.QueryEscape(f.Name), url.QueryEscape(f.Value))
+	}
+
+	if _, err := c.GetJSON(context.Background(), path, &res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *client) ProjectCalendarList(projectKey string) ([]sdk.ProjectCalendar, error) {
+	var cs []sdk.ProjectCalendar
+	if _, err := c.GetJSON(context.Background(), fmt.Sprintf("/project/%s/calendar", projectKey), &cs); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+func (c *client) ProjectCalendarGet(projectKey, name string) (*sdk.ProjectCalendar, error) {
+	var cal sdk.ProjectCalendar
+	if _, err := c.GetJSON(context.Background(), fmt.Sprintf("/project/%s/calendar/%s", projectKey, url.PathEscape(name)), &cal); err != nil {
+		return nil, err
+	}
+	return &cal, nil
+}
+
+func (c *client) ProjectCalendarCreate(projectKey string, cal *sdk.ProjectCalendar) error {
+	_, err := c.PostJSON(context.Background(), fmt.Sprintf("/project/%s/calendar", projectKey), cal, cal)
+	return err
+}
+
+func (c *client) ProjectCalendarUpdate(projectKey string, cal *sdk.ProjectCalendar) error {
+	_, err := c.PutJSON(context.Background(), fmt.Sprintf("/project/%s/calendar/%s", projectKey, url.PathEscape(cal.Name)), cal, cal)
+	return err
+}
+
+func (c *client) ProjectCalendarDelete(projectKey, name string) error {
+	_, err := c.DeleteJSON(context.Background(), fmt.Sprintf("/project/%s/calendar/%s", projectKey, url.PathEscape(name)), nil, nil)
+	return err
+}
+
+func (c *client) ProjectLockList(projectKey string) ([]sdk.ProjectLock, error) {
+	var locks []sdk.ProjectLock
+	if _, err := c.GetJSON(context.Background(), fmt.Sprintf("/project/%s/lock", projectKey), &locks); err != nil {
+		return nil, err
+	}
+	return locks, nil
+}
+
+func (c *client) ProjectLockRelease(projectKey, lockName string) error {
+	_, err := c.DeleteJSON(context.Background(), fmt.Sprintf("/project/%s/lock/%s", projectKey, url.PathEscape(lockName)), nil, nil)
+	return err
+}
+
 func (c *client) ProjectGroupsImport(projectKey string, content io.Reader, format string, force bool) (sdk.Project, error) {
 	var proj sdk.Project
 	url := fmt.Sprintf("/project/%s/group/import?format=%s", projectKey, format)
@@ -2,6 +2,7 @@ package cdsclient
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 
 	"github.com/ovh/cds/sdk"
@@ -15,6 +16,18 @@ func (c *client) ProjectKeysList(key string) ([]sdk.ProjectKey, error) {
 	return k, nil
 }
 
+func (c *client) ProjectKeyGet(projectKey string, keyName string, clearKey bool) (*sdk.ProjectKey, error) {
+	path := fmt.Sprintf("/project/%s/keys/%s", projectKey, url.PathEscape(keyName))
+	if clearKey {
+		path += "?clearKey=true"
+	}
+	k := &sdk.ProjectKey{}
+	if _, err := c.GetJSON(context.Background(), path, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
 func (c *client) ProjectKeyCreate(projectKey string, keyProject *sdk.ProjectKey) error {
 	_, err := c.PostJSON(context.Background(), "/project/"+projectKey+"/keys", keyProject, keyProject)
 	return err
@@ -101,8 +101,8 @@ func (c *client) QueuePolling(ctx context.Context, jobs chan<- sdk.WorkflowNodeJ
 						continue
 					}
 
-					// push the job in the channel
-					if job.Status == sdk.StatusWaiting && job.BookedBy.Name == "" {
+					// push the job in the channel, a job waiting for a retry delay will be listed in the queue later
+					if job.Status == sdk.StatusWaiting && job.BookedBy.Name == "" && !job.Queued.After(time.Now()) {
 						job.Header["SSE"] = "true"
 						jobs <- *job
 					}
@@ -498,6 +498,15 @@ func (c *client) queueDirectArtifactUpload(projectKey, integrationName string, n
 	return fmt.Errorf("x%d: %v", c.config.Retry, err)
 }
 
+func (c *client) QueueArtifactPromote(ctx context.Context, jobID int64, req sdk.ArtifactPromoteRequest) ([]sdk.WorkflowNodeRunArtifact, error) {
+	path := fmt.Sprintf("/queue/workflows/%d/artifact/promote", jobID)
+	var arts []sdk.WorkflowNodeRunArtifact
+	if _, err := c.PostJSON(ctx, path, req, &arts); err != nil {
+		return nil, err
+	}
+	return arts, nil
+}
+
 func (c *client) QueueJobTag(ctx context.Context, jobID int64, tags []sdk.WorkflowRunTag) error {
 	path := fmt.Sprintf("/queue/workflows/%d/tag", jobID)
 	_, err := c.PostJSON(ctx, path, tags, nil)
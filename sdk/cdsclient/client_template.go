@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/ovh/cds/sdk"
 )
@@ -151,3 +152,61 @@ func (c *client) TemplateDeleteInstance(groupName, templateSlug string, id int64
 
 	return nil
 }
+
+func (c *client) TemplateGetReleases(groupName, templateSlug string) ([]sdk.WorkflowTemplateRelease, error) {
+	url := fmt.Sprintf("/template/%s/%s/release", groupName, templateSlug)
+
+	var rs []sdk.WorkflowTemplateRelease
+	if _, err := c.GetJSON(context.Background(), url, &rs); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+func (c *client) TemplateRelease(groupName, templateSlug string, release sdk.WorkflowTemplateRelease) (*sdk.WorkflowTemplateRelease, error) {
+	url := fmt.Sprintf("/template/%s/%s/release", groupName, templateSlug)
+
+	var res sdk.WorkflowTemplateRelease
+	if _, err := c.PostJSON(context.Background(), url, release, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *client) TemplateUpgrade(groupName, templateSlug string, req sdk.WorkflowTemplateUpgradeRequest) (*sdk.WorkflowTemplateUpgradeResult, error) {
+	url := fmt.Sprintf("/template/%s/%s/upgrade", groupName, templateSlug)
+
+	var res sdk.WorkflowTemplateUpgradeResult
+	if _, err := c.PostJSON(context.Background(), url, req, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *client) TemplateInstanceDiff(groupName, templateSlug string, id int64, version string) (*sdk.WorkflowTemplateInstanceDiff, error) {
+	path := fmt.Sprintf("/template/%s/%s/instance/%d/diff", groupName, templateSlug, id)
+	if version != "" {
+		path += "?version=" + url.QueryEscape(version)
+	}
+
+	var res sdk.WorkflowTemplateInstanceDiff
+	if _, err := c.GetJSON(context.Background(), path, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *client) TemplateInstanceRollback(groupName, templateSlug string, id int64) ([]string, error) {
+	url := fmt.Sprintf("/template/%s/%s/instance/%d/rollback", groupName, templateSlug, id)
+
+	var msgs []string
+	if _, err := c.PostJSON(context.Background(), url, nil, &msgs); err != nil {
+		return nil, err
+	}
+
+	return msgs, nil
+}
@@ -78,6 +78,21 @@ func (c *client) WorkflowRunsDeleteByBranch(projectKey string, workflowName stri
 	return nil
 }
 
+func (c *client) WorkflowPurge(projectKey string, workflowName string, dryRun bool) ([]sdk.WorkflowRunPurge, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/purge", projectKey, workflowName)
+	var runs []sdk.WorkflowRunPurge
+	if dryRun {
+		if _, err := c.GetJSON(context.Background(), url, &runs); err != nil {
+			return nil, err
+		}
+		return runs, nil
+	}
+	if _, err := c.PostJSON(context.Background(), url, nil, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
 func (c *client) WorkflowRunResync(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error) {
 	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/resync", projectKey, workflowName, number)
 	var run sdk.WorkflowRun
@@ -106,6 +121,15 @@ func (c *client) WorkflowRunSearch(projectKey string, offset, limit int64, filte
 	return runs, nil
 }
 
+func (c *client) WorkflowRunsSearch(projectKey string, workflowName string, filter sdk.WorkflowRunSearchFilter) ([]sdk.WorkflowRun, error) {
+	path := fmt.Sprintf("/project/%s/workflows/%s/runs/search?%s", projectKey, workflowName, filter.QueryParams().Encode())
+	runs := []sdk.WorkflowRun{}
+	if _, err := c.GetJSON(context.Background(), path, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
 func (c *client) WorkflowRunList(projectKey string, workflowName string, offset, limit int64) ([]sdk.WorkflowRun, error) {
 	if offset < 0 {
 		offset = 0
@@ -176,6 +200,23 @@ func (c *client) WorkflowNodeRunJobStep(projectKey string, workflowName string,
 	return &buildState, nil
 }
 
+func (c *client) WorkflowNodeRunJobStepLogs(ctx context.Context, projectKey string, workflowName string, number int64, nodeRunID, job int64, step int, offset int64) (io.ReadCloser, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/job/%d/step/%d/logs?offset=%d", projectKey, workflowName, number, nodeRunID, job, step, offset)
+	reader, _, code, err := c.Stream(ctx, "GET", url, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if code >= 400 {
+		body, _ := ioutil.ReadAll(reader)
+		reader.Close() // nolint
+		if err := sdk.DecodeError(body); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to stream logs. HTTP code error : %d", code)
+	}
+	return reader, nil
+}
+
 func (c *client) WorkflowNodeRunArtifactDownload(projectKey string, workflowName string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error {
 	var url = fmt.Sprintf("/project/%s/workflows/%s/artifact/%d", projectKey, workflowName, a.ID)
 	var reader io.ReadCloser
@@ -282,6 +323,21 @@ func (c *client) WorkflowNodeStop(projectKey string, workflowName string, number
 	return nodeRun, nil
 }
 
+func (c *client) WorkflowNodeApprove(projectKey string, workflowName string, number, nodeRunID int64, comment string) (*sdk.WorkflowNodeRun, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/approve", projectKey, workflowName, number, nodeRunID)
+
+	nodeRun := &sdk.WorkflowNodeRun{}
+	code, err := c.PostJSON(context.Background(), url, sdk.WorkflowNodeRunApprovalRequest{Comment: comment}, nodeRun)
+	if err != nil {
+		return nil, err
+	}
+	if code >= 300 {
+		return nil, fmt.Errorf("Cannot approve workflow node %d. HTTP code error: %d", nodeRunID, code)
+	}
+
+	return nodeRun, nil
+}
+
 func (c *client) WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error {
 	store := new(sdk.ArtifactsStore)
 	uri := fmt.Sprintf("/project/%s/storage/%s", projectKey, integrationName)
@@ -315,7 +371,7 @@ func (c *client) workflowCachePushDirectUpload(projectKey, integrationName, ref
 
 func (c *client) workflowCachePushIndirectUpload(projectKey, integrationName, ref string, tarContent io.Reader, size int) error {
 	uri := fmt.Sprintf("/project/%s/storage/%s/cache/%s/url", projectKey, integrationName, ref)
-	cacheObj := sdk.Cache{}
+	cacheObj := sdk.Cache{Size: int64(size)}
 	code, err := c.PostJSON(context.Background(), uri, cacheObj, &cacheObj)
 	if err != nil {
 		return err
@@ -325,7 +381,19 @@ func (c *client) workflowCachePushIndirectUpload(projectKey, integrationName, re
 		return fmt.Errorf("HTTP Code %d", code)
 	}
 
-	return c.workflowCachePushIndirectUploadPost(cacheObj.TmpURL, tarContent, size)
+	if err := c.workflowCachePushIndirectUploadPost(cacheObj.TmpURL, tarContent, size); err != nil {
+		return err
+	}
+
+	// The cache is only recorded once uploaded
+	code, err = c.PostJSON(context.Background(), uri+"/callback", sdk.Cache{Size: int64(size)}, nil)
+	if err != nil {
+		return err
+	}
+	if code >= 400 {
+		return fmt.Errorf("HTTP Code %d", code)
+	}
+	return nil
 }
 
 func (c *client) workflowCachePushIndirectUploadPost(url string, tarContent io.Reader, size int) error {
@@ -369,6 +437,19 @@ func (c *client) workflowCachePushIndirectUploadPost(url string, tarContent io.R
 	return globalErr
 }
 
+func (c *client) WorkflowCacheResolve(projectKey, integrationName string, tags []string) (*sdk.CacheEntry, error) {
+	q := url.Values{}
+	for _, t := range tags {
+		q.Add("tag", t)
+	}
+	uri := fmt.Sprintf("/project/%s/storage/%s/cache?%s", projectKey, integrationName, q.Encode())
+	var entry sdk.CacheEntry
+	if _, err := c.GetJSON(context.Background(), uri, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
 func (c *client) WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error) {
 	uri := fmt.Sprintf("/project/%s/storage/%s", projectKey, integrationName)
 	store := new(sdk.ArtifactsStore)
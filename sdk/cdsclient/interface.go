@@ -29,6 +29,11 @@ type TemplateClient interface {
 	TemplateDelete(groupName, templateSlug string) error
 	TemplateGetInstances(groupName, templateSlug string) ([]sdk.WorkflowTemplateInstance, error)
 	TemplateDeleteInstance(groupName, templateSlug string, id int64) error
+	TemplateGetReleases(groupName, templateSlug string) ([]sdk.WorkflowTemplateRelease, error)
+	TemplateRelease(groupName, templateSlug string, release sdk.WorkflowTemplateRelease) (*sdk.WorkflowTemplateRelease, error)
+	TemplateUpgrade(groupName, templateSlug string, req sdk.WorkflowTemplateUpgradeRequest) (*sdk.WorkflowTemplateUpgradeResult, error)
+	TemplateInstanceDiff(groupName, templateSlug string, id int64, version string) (*sdk.WorkflowTemplateInstanceDiff, error)
+	TemplateInstanceRollback(groupName, templateSlug string, id int64) ([]string, error)
 }
 
 // Admin expose all function to CDS administration
@@ -68,6 +73,7 @@ type ExportImportInterface interface {
 	WorkerModelExport(groupName, name, format string) ([]byte, error)
 	WorkerModelImport(content io.Reader, format string, force bool) (*sdk.Model, error)
 	WorkflowPush(projectKey string, tarContent io.Reader, mods ...RequestModifier) ([]string, *tar.Reader, error)
+	WorkflowPlan(projectKey string, tarContent io.Reader, mods ...RequestModifier) (*sdk.WorkflowPlan, error)
 	WorkflowAsCodeInterface
 }
 
@@ -204,6 +210,14 @@ type ProjectClient interface {
 	ProjectGet(projectKey string, opts ...RequestModifier) (*sdk.Project, error)
 	ProjectUpdate(key string, project *sdk.Project) error
 	ProjectList(withApplications, withWorkflow bool, filters ...Filter) ([]sdk.Project, error)
+	ProjectDeliveryMetrics(projectKey string, filters ...Filter) ([]sdk.DeliveryMetrics, error)
+	ProjectCalendarList(projectKey string) ([]sdk.ProjectCalendar, error)
+	ProjectCalendarGet(projectKey, name string) (*sdk.ProjectCalendar, error)
+	ProjectCalendarCreate(projectKey string, cal *sdk.ProjectCalendar) error
+	ProjectCalendarUpdate(projectKey string, cal *sdk.ProjectCalendar) error
+	ProjectCalendarDelete(projectKey, name string) error
+	ProjectLockList(projectKey string) ([]sdk.ProjectLock, error)
+	ProjectLockRelease(projectKey, lockName string) error
 	ProjectKeysClient
 	ProjectVariablesClient
 	ProjectGroupsImport(projectKey string, content io.Reader, format string, force bool) (sdk.Project, error)
@@ -218,6 +232,7 @@ type ProjectClient interface {
 // ProjectKeysClient exposes project keys related functions
 type ProjectKeysClient interface {
 	ProjectKeysList(projectKey string) ([]sdk.ProjectKey, error)
+	ProjectKeyGet(projectKey string, keyName string, clearKey bool) (*sdk.ProjectKey, error)
 	ProjectKeyCreate(projectKey string, key *sdk.ProjectKey) error
 	ProjectKeysDelete(projectKey string, keyProjectName string) error
 }
@@ -249,6 +264,7 @@ type QueueClient interface {
 	QueueSendStepResult(ctx context.Context, id int64, res sdk.StepStatus) error
 	QueueSendResult(ctx context.Context, id int64, res sdk.Result) error
 	QueueArtifactUpload(ctx context.Context, projectKey, integrationName string, nodeJobRunID int64, tag, filePath string) (bool, time.Duration, error)
+	QueueArtifactPromote(ctx context.Context, jobID int64, req sdk.ArtifactPromoteRequest) ([]sdk.WorkflowNodeRunArtifact, error)
 	QueueStaticFilesUpload(ctx context.Context, projectKey, integrationName string, nodeJobRunID int64, name, entrypoint, staticKey string, tarContent io.Reader) (string, bool, time.Duration, error)
 	QueueJobTag(ctx context.Context, jobID int64, tags []sdk.WorkflowRunTag) error
 	QueueServiceLogs(ctx context.Context, logs []sdk.ServiceLog) error
@@ -296,8 +312,10 @@ type WorkflowClient interface {
 	WorkflowGroupDelete(projectKey, name, groupName string) error
 	WorkflowRunGet(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error)
 	WorkflowRunsDeleteByBranch(projectKey string, workflowName string, branch string) error
+	WorkflowPurge(projectKey string, workflowName string, dryRun bool) ([]sdk.WorkflowRunPurge, error)
 	WorkflowRunResync(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error)
 	WorkflowRunSearch(projectKey string, offset, limit int64, filter ...Filter) ([]sdk.WorkflowRun, error)
+	WorkflowRunsSearch(projectKey string, workflowName string, filter sdk.WorkflowRunSearchFilter) ([]sdk.WorkflowRun, error)
 	WorkflowRunList(projectKey string, workflowName string, offset, limit int64) ([]sdk.WorkflowRun, error)
 	WorkflowRunArtifacts(projectKey string, name string, number int64) ([]sdk.WorkflowNodeRunArtifact, error)
 	WorkflowRunFromHook(projectKey string, workflowName string, hook sdk.WorkflowNodeRunHookEvent) (*sdk.WorkflowRun, error)
@@ -306,13 +324,16 @@ type WorkflowClient interface {
 	WorkflowRunNumberSet(projectKey string, workflowName string, number int64) error
 	WorkflowStop(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error)
 	WorkflowNodeStop(projectKey string, workflowName string, number, fromNodeID int64) (*sdk.WorkflowNodeRun, error)
+	WorkflowNodeApprove(projectKey string, workflowName string, number, nodeRunID int64, comment string) (*sdk.WorkflowNodeRun, error)
 	WorkflowNodeRun(projectKey string, name string, number int64, nodeRunID int64) (*sdk.WorkflowNodeRun, error)
 	WorkflowNodeRunArtifactDownload(projectKey string, name string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error
 	WorkflowNodeRunJobStep(projectKey string, workflowName string, number int64, nodeRunID, job int64, step int) (*sdk.BuildState, error)
+	WorkflowNodeRunJobStepLogs(ctx context.Context, projectKey string, workflowName string, number int64, nodeRunID, job int64, step int, offset int64) (io.ReadCloser, error)
 	WorkflowNodeRunRelease(projectKey string, workflowName string, runNumber int64, nodeRunID int64, release sdk.WorkflowNodeRunRelease) error
 	WorkflowAllHooksList() ([]sdk.NodeHook, error)
 	WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error
 	WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error)
+	WorkflowCacheResolve(projectKey, integrationName string, tags []string) (*sdk.CacheEntry, error)
 	WorkflowTemplateInstanceGet(projectKey, workflowName string) (*sdk.WorkflowTemplateInstance, error)
 	WorkflowTransformAsCode(projectKey, workflowName string) (*sdk.Operation, error)
 	WorkflowTransformAsCodeFollow(projectKey, workflowName string, ope *sdk.Operation) error
@@ -379,6 +400,7 @@ type WorkerInterface interface {
 	WorkflowRunArtifacts(projectKey string, name string, number int64) ([]sdk.WorkflowNodeRunArtifact, error)
 	WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error
 	WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error)
+	WorkflowCacheResolve(projectKey, integrationName string, tags []string) (*sdk.CacheEntry, error)
 	WorkflowRunSearch(projectKey string, offset, limit int64, filter ...Filter) ([]sdk.WorkflowRun, error)
 	WorkflowNodeRunArtifactDownload(projectKey string, name string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error
 	WorkflowNodeRunRelease(projectKey string, workflowName string, runNumber int64, nodeRunID int64, release sdk.WorkflowNodeRunRelease) error
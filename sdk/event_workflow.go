@@ -43,6 +43,18 @@ type EventWorkflowPermissionDelete struct {
 	Permission GroupPermission `json:"group_permission"`
 }
 
+// EventWorkflowNodeRunApproval represents the event when approving an approval node run
+//easyjson:json
+type EventWorkflowNodeRunApproval struct {
+	WorkflowID        int64  `json:"workflow_id"`
+	WorkflowRunNumber int64  `json:"workflow_run_number"`
+	NodeRunID         int64  `json:"workflow_node_run_id"`
+	NodeName          string `json:"workflow_node_name"`
+	Comment           string `json:"comment,omitempty"`
+	Approvals         int    `json:"approvals"`
+	RequiredApprovals int    `json:"required_approvals"`
+}
+
 // ToEventWorkflowPermissionAdd get the payload as EventWorkflowPermissionAdd
 func (e Event) ToEventWorkflowPermissionAdd() (EventWorkflowPermissionAdd, error) {
 	var permEvent EventWorkflowPermissionAdd
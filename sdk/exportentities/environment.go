@@ -9,12 +9,14 @@ type Environment struct {
 	Name   string                   `json:"name" yaml:"name" jsonschema_description:"The name of the environment."`
 	Values map[string]VariableValue `json:"values,omitempty" yaml:"values,omitempty"`
 	Keys   map[string]KeyValue      `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Lock   string                   `json:"lock,omitempty" yaml:"lock,omitempty" jsonschema_description:"Name of a project lock acquired by the nodes using this environment."`
 }
 
 //NewEnvironment returns an Environment from an sdk.Environment pointer
 func NewEnvironment(e sdk.Environment, keys []EncryptedKey) (env *Environment) {
 	env = new(Environment)
 	env.Name = e.Name
+	env.Lock = e.Lock
 	env.Values = make(map[string]VariableValue, len(e.Variable))
 	for _, v := range e.Variable {
 		env.Values[v.Name] = VariableValue{
@@ -36,6 +38,7 @@ func NewEnvironment(e sdk.Environment, keys []EncryptedKey) (env *Environment) {
 func (e *Environment) Environment() (env *sdk.Environment) {
 	env = new(sdk.Environment)
 	env.Name = e.Name
+	env.Lock = e.Lock
 	env.Variable = make([]sdk.Variable, len(e.Values))
 	var i int
 	for k, v := range e.Values {
@@ -0,0 +1,188 @@
+package exportentities
+
+import (
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// LintReferences contains the names of the entities of a project that can be used by workflow files.
+type LintReferences struct {
+	Pipelines    []string `json:"pipelines,omitempty" yaml:"pipelines,omitempty"`
+	Applications []string `json:"applications,omitempty" yaml:"applications,omitempty"`
+	Environments []string `json:"environments,omitempty" yaml:"environments,omitempty"`
+	Integrations []string `json:"integrations,omitempty" yaml:"integrations,omitempty"`
+}
+
+// Lint checks given workflow, pipeline, application and environment yaml files indexed by file name and returns
+// a message for each problem found. Files included by workflows are searched by include key in includes, files
+// included from a library repository can't be checked. References to pipelines, applications, environments and
+// integrations that are not declared by the files are checked against refs if given.
+func Lint(files map[string][]byte, includes map[string][]byte, refs *LintReferences) []sdk.Message {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var msgs []sdk.Message
+	var workflows []WorkflowV2
+	local := LintReferences{}
+	if refs != nil {
+		local = *refs
+	}
+
+	for _, name := range names {
+		btes := files[name]
+		switch {
+		case strings.Contains(name, ".pip."):
+			var p PipelineV1
+			if !lintStrict(name, btes, &p, &msgs) {
+				continue
+			}
+			if _, err := p.Pipeline(); err != nil {
+				msgs = append(msgs, lintErrorMessage(name, err))
+				continue
+			}
+			local.Pipelines = append(local.Pipelines, p.Name)
+		case strings.Contains(name, ".app."):
+			var a Application
+			if !lintStrict(name, btes, &a, &msgs) {
+				continue
+			}
+			local.Applications = append(local.Applications, a.Name)
+		case strings.Contains(name, ".env."):
+			var e Environment
+			if !lintStrict(name, btes, &e, &msgs) {
+				continue
+			}
+			local.Environments = append(local.Environments, e.Name)
+		default:
+			w, err := UnmarshalWorkflow(btes, FormatYAML)
+			if err != nil {
+				msgs = append(msgs, lintErrorMessage(name, err))
+				continue
+			}
+			var v struct {
+				Version string `yaml:"version"`
+			}
+			_ = yaml.Unmarshal(btes, &v)
+			if v.Version == WorkflowVersion2 {
+				if !lintStrict(name, btes, &WorkflowV2{}, &msgs) {
+					continue
+				}
+			} else if !lintStrict(name, btes, &Workflow{}, &msgs) {
+				continue
+			}
+
+			// files from library repositories are loaded by the API at the given ref
+			wIncludes := w.Include
+			w.Include = nil
+			for _, i := range wIncludes {
+				if i.IsLibrary() {
+					msgs = append(msgs, sdk.NewMessage(sdk.MsgWorkflowLintLibraryInclude, i.Path, i.Repository))
+					continue
+				}
+				w.Include = append(w.Include, i)
+			}
+			if err := w.ResolveIncludes(includes); err != nil {
+				msgs = append(msgs, lintErrorMessage(name, err))
+				continue
+			}
+
+			var hasErr bool
+			for pipName, p := range w.Pipelines {
+				p.Name = pipName
+				if _, err := p.Pipeline(); err != nil {
+					msgs = append(msgs, lintErrorMessage(name, err))
+					hasErr = true
+				}
+			}
+			if _, err := w.GetWorkflow(); err != nil {
+				msgs = append(msgs, lintErrorMessage(name, err))
+				hasErr = true
+			}
+			if hasErr {
+				continue
+			}
+
+			workflows = append(workflows, *w)
+		}
+	}
+
+	if refs == nil {
+		return msgs
+	}
+
+	for _, w := range workflows {
+		lintRefs := LintReferences{
+			Pipelines:    append([]string{}, local.Pipelines...),
+			Applications: local.Applications,
+			Environments: append([]string{}, local.Environments...),
+			Integrations: local.Integrations,
+		}
+		for name := range w.Pipelines {
+			lintRefs.Pipelines = append(lintRefs.Pipelines, name)
+		}
+		for name := range w.Environments {
+			lintRefs.Environments = append(lintRefs.Environments, name)
+		}
+
+		nodeNames := make([]string, 0, len(w.Workflow))
+		for name := range w.Workflow {
+			nodeNames = append(nodeNames, name)
+		}
+		sort.Strings(nodeNames)
+
+		for _, nodeName := range nodeNames {
+			n := w.Workflow[nodeName]
+			if n.PipelineName != "" && !sdk.IsInArray(n.PipelineName, lintRefs.Pipelines) {
+				msgs = append(msgs, sdk.NewMessage(sdk.MsgWorkflowErrorBadPipelineName, n.PipelineName))
+			}
+			if n.ApplicationName != "" && !sdk.IsInArray(n.ApplicationName, lintRefs.Applications) {
+				msgs = append(msgs, sdk.NewMessage(sdk.MsgWorkflowErrorBadApplicationName, n.ApplicationName))
+			}
+			if n.EnvironmentName != "" && !sdk.IsInArray(n.EnvironmentName, lintRefs.Environments) {
+				msgs = append(msgs, sdk.NewMessage(sdk.MsgWorkflowErrorBadEnvironmentName, n.EnvironmentName))
+			}
+			if n.ProjectIntegrationName != "" && !sdk.IsInArray(n.ProjectIntegrationName, lintRefs.Integrations) {
+				msgs = append(msgs, sdk.NewMessage(sdk.MsgWorkflowErrorBadIntegrationName, n.ProjectIntegrationName))
+			}
+		}
+	}
+
+	return msgs
+}
+
+// lintStrict unmarshals the file in strict mode to find unknown fields and type errors, keys starting with a dot
+// are ignored because they are used to declare yaml anchors.
+func lintStrict(name string, btes []byte, i interface{}, msgs *[]sdk.Message) bool {
+	err := yaml.UnmarshalStrict(btes, i)
+	if err == nil {
+		return true
+	}
+	typeErr, ok := err.(*yaml.TypeError)
+	if !ok {
+		*msgs = append(*msgs, sdk.NewMessage(sdk.MsgWorkflowLintInvalidFile, name, err.Error()))
+		return false
+	}
+	valid := true
+	for _, e := range typeErr.Errors {
+		if strings.Contains(e, " field .") {
+			continue
+		}
+		*msgs = append(*msgs, sdk.NewMessage(sdk.MsgWorkflowLintInvalidFile, name, e))
+		valid = false
+	}
+	return valid
+}
+
+func lintErrorMessage(name string, err error) sdk.Message {
+	if m, ok := sdk.ErrorToMessage(err); ok {
+		return m
+	}
+	return sdk.NewMessage(sdk.MsgWorkflowLintInvalidFile, name, sdk.Cause(err).Error())
+}
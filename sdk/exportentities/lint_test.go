@@ -0,0 +1,102 @@
+package exportentities_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func TestLint(t *testing.T) {
+	files := map[string][]byte{
+		"my-workflow.yml": []byte(`name: my-workflow
+version: v2.0
+.defaults: &defaults
+  application: my-app
+include:
+- path: .cds/deploy.yml
+- path: deploy.yml
+  repository: my-org/cds-library
+  ref: v1.0.0
+pipelines:
+  build:
+    jobs:
+    - job: Build
+      steps:
+      - script: make build
+workflow:
+  build:
+    <<: *defaults
+    pipeline: build
+  test:
+    needs:
+    - build
+    pipeline: test
+  deploy:
+    needs:
+    - test
+    pipeline: deploy
+    environment: prod
+    integration: my-integration
+`),
+		"test.pip.yml": []byte(`version: v1.0
+name: test
+`),
+	}
+	includes := map[string][]byte{
+		".cds/deploy.yml": []byte(`environments:
+  prod: {}
+`),
+	}
+
+	// without references only the files are checked
+	msgs := exportentities.Lint(files, includes, nil)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, sdk.MsgWorkflowLintLibraryInclude.ID, msgs[0].ID)
+
+	// with references, unknown pipelines, applications, environments and integrations are reported
+	msgs = exportentities.Lint(files, includes, &exportentities.LintReferences{
+		Applications: []string{"my-app"},
+		Integrations: []string{"my-integration"},
+	})
+	require.Len(t, msgs, 2)
+	assert.Equal(t, sdk.MsgWorkflowLintLibraryInclude.ID, msgs[0].ID)
+	assert.Equal(t, sdk.MsgWorkflowErrorBadPipelineName.ID, msgs[1].ID)
+	assert.Equal(t, "The pipeline deploy mentioned in your workflow's yaml file doesn't exist", msgs[1].String("en-US"))
+
+	msgs = exportentities.Lint(files, includes, &exportentities.LintReferences{
+		Pipelines: []string{"deploy"},
+	})
+	require.Len(t, msgs, 3)
+	assert.Equal(t, sdk.MsgWorkflowErrorBadApplicationName.ID, msgs[1].ID)
+	assert.Equal(t, sdk.MsgWorkflowErrorBadIntegrationName.ID, msgs[2].ID)
+
+	// unknown fields and invalid values are reported for each file
+	msgs = exportentities.Lint(map[string][]byte{
+		"my-workflow.yml": []byte(`name: my-workflow
+version: v1.0
+pipeline: build
+unknown: value
+`),
+		"build.pip.yml": []byte(`version: v1.0
+name: build
+jobs:
+- job: Build
+  requirements: my-requirement
+`),
+		"my-app.app.yml": []byte(`version: v1.0
+name: my-app
+variables: []
+`),
+	}, nil, nil)
+	require.Len(t, msgs, 3)
+	for _, m := range msgs {
+		assert.Equal(t, sdk.MsgWorkflowLintInvalidFile.ID, m.ID)
+	}
+	assert.Contains(t, msgs[0].String("en-US"), "File build.pip.yml is invalid: line 5")
+	assert.Contains(t, msgs[1].String("en-US"), "File my-app.app.yml is invalid: line 3")
+	assert.Contains(t, msgs[2].String("en-US"), "File my-workflow.yml is invalid: line 4: field unknown not found")
+}
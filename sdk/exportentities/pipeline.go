@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
@@ -51,6 +53,24 @@ type Job struct {
 	Requirements   []Requirement `json:"requirements,omitempty" yaml:"requirements,omitempty" jsonschema_description:"The list of requirements for the jobs."`
 	Optional       *bool         `json:"optional,omitempty" yaml:"optional,omitempty" jsonschema_description:"Set this option to ignore job's errors."`
 	AlwaysExecuted *bool         `json:"always_executed,omitempty" yaml:"always_executed,omitempty" jsonschema_description:"Set this option to execute the job even if a previous step failed."`
+	Timeout        string        `json:"timeout,omitempty" yaml:"timeout,omitempty" jsonschema_description:"Maximum duration of the job (ex: 30m, 1h30m). The job is killed and set to Timeout after this delay."`
+	Retry          *RetryPolicy  `json:"retry,omitempty" yaml:"retry,omitempty" jsonschema_description:"Retry policy applied when the job fails."`
+	Matrix         *Matrix       `json:"matrix,omitempty" yaml:"matrix,omitempty" jsonschema_description:"Run the job once for each combination of the matrix."`
+}
+
+// Matrix represents an exported sdk.ActionMatrix
+type Matrix struct {
+	Axes    map[string][]string `json:"axes,omitempty" yaml:"axes,omitempty" jsonschema_description:"Values for each axis, available in the job as cds.matrix.<axis> variables."`
+	Include []map[string]string `json:"include,omitempty" yaml:"include,omitempty" jsonschema_description:"Additional combinations to run."`
+	Exclude []map[string]string `json:"exclude,omitempty" yaml:"exclude,omitempty" jsonschema_description:"Combinations to skip, an entry can only define some of the axes."`
+}
+
+// RetryPolicy represents an exported sdk.ActionRetryPolicy
+type RetryPolicy struct {
+	MaxAttempts             int    `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty" jsonschema_description:"Maximum number of attempts, including the first one."`
+	Backoff                 string `json:"backoff,omitempty" yaml:"backoff,omitempty" jsonschema_description:"Delay before the first retry (ex: 30s), doubled after each attempt."`
+	ExitCodes               []int  `json:"exit_codes,omitempty" yaml:"exit_codes,omitempty" jsonschema_description:"Only retry when the failure exit code is one of these codes."`
+	OnInfrastructureFailure bool   `json:"on_infrastructure_failure,omitempty" yaml:"on_infrastructure_failure,omitempty" jsonschema_description:"Retry when the failure is not caused by a command exit code."`
 }
 
 // Requirement represents an exported sdk.Requirement
@@ -121,7 +141,7 @@ func newStagesForPipelineV1(stages []sdk.Stage) ([]string, map[string]Stage) {
 			st.Enabled = &s.Enabled
 			hasOptions = true
 		}
-		if len(s.Conditions.PlainConditions) > 0 || s.Conditions.LuaScript != "" {
+		if !s.Conditions.IsEmpty() {
 			st.Conditions = &s.Conditions
 			hasOptions = true
 		}
@@ -169,6 +189,15 @@ func newJob(j sdk.Job) Job {
 	jo.Steps = newSteps(j.Action)
 	jo.Description = j.Action.Description
 	jo.Requirements = newRequirements(j.Action.Requirements)
+	jo.Timeout = newTimeout(j.Action.Timeout)
+	jo.Retry = newRetryPolicy(j.Action.RetryPolicy)
+	if !j.Action.Matrix.IsEmpty() {
+		jo.Matrix = &Matrix{
+			Axes:    j.Action.Matrix.Axes,
+			Include: j.Action.Matrix.Include,
+			Exclude: j.Action.Matrix.Exclude,
+		}
+	}
 	return jo
 }
 
@@ -185,6 +214,66 @@ func newJobs(jobs []sdk.Job) map[string]Job {
 	return res
 }
 
+// newTimeout returns a human readable duration (ex: 1h30m) from a timeout in seconds.
+func newTimeout(timeout int64) string {
+	if timeout <= 0 {
+		return ""
+	}
+	d := (time.Duration(timeout) * time.Second).String()
+	if strings.HasSuffix(d, "m0s") {
+		d = strings.TrimSuffix(d, "0s")
+	}
+	if strings.HasSuffix(d, "h0m") {
+		d = strings.TrimSuffix(d, "0m")
+	}
+	return d
+}
+
+// computeTimeout returns a timeout in seconds from a duration (ex: 1h30m).
+func computeTimeout(timeout string) (int64, error) {
+	if timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, sdk.WithStack(err)
+	}
+	if d < 0 {
+		return 0, sdk.WithStack(fmt.Errorf("timeout %s should be positive", timeout))
+	}
+	return int64(d / time.Second), nil
+}
+
+func newRetryPolicy(p sdk.ActionRetryPolicy) *RetryPolicy {
+	if p.IsEmpty() {
+		return nil
+	}
+	return &RetryPolicy{
+		MaxAttempts:             p.MaxAttempts,
+		Backoff:                 newTimeout(p.Backoff),
+		ExitCodes:               p.ExitCodes,
+		OnInfrastructureFailure: p.OnInfrastructureFailure,
+	}
+}
+
+func computeRetryPolicy(r *RetryPolicy) (sdk.ActionRetryPolicy, error) {
+	var p sdk.ActionRetryPolicy
+	if r == nil {
+		return p, nil
+	}
+	backoff, err := computeTimeout(r.Backoff)
+	if err != nil {
+		return p, err
+	}
+	p = sdk.ActionRetryPolicy{
+		MaxAttempts:             r.MaxAttempts,
+		Backoff:                 backoff,
+		ExitCodes:               r.ExitCodes,
+		OnInfrastructureFailure: r.OnInfrastructureFailure,
+	}
+	return p, p.IsValid()
+}
+
 func computeSteps(steps []Step) ([]sdk.Action, error) {
 	res := make([]sdk.Action, len(steps))
 	for i, s := range steps {
@@ -259,6 +348,29 @@ func computeJob(name string, j Job) (*sdk.Job, error) {
 	job.Action.Enabled = job.Enabled
 	job.Action.Requirements = computeJobRequirements(j.Requirements)
 
+	timeout, err := computeTimeout(j.Timeout)
+	if err != nil {
+		return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid timeout for job %s", name))
+	}
+	job.Action.Timeout = timeout
+
+	retryPolicy, err := computeRetryPolicy(j.Retry)
+	if err != nil {
+		return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid retry policy for job %s", name))
+	}
+	job.Action.RetryPolicy = retryPolicy
+
+	if j.Matrix != nil {
+		job.Action.Matrix = sdk.ActionMatrix{
+			Axes:    j.Matrix.Axes,
+			Include: j.Matrix.Include,
+			Exclude: j.Matrix.Exclude,
+		}
+		if err := job.Action.Matrix.IsValid(); err != nil {
+			return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid matrix for job %s", name))
+		}
+	}
+
 	//Compute steps for the jobs
 	children, err := computeSteps(j.Steps)
 	if err != nil {
@@ -596,6 +596,105 @@ jobs:
 	assert.Len(t, p.Stages[0].Jobs[0].Action.Actions[0].Parameters, 1)
 }
 
+func Test_ImportPipelineWithTimeout(t *testing.T) {
+	in := `name: build
+jobs:
+- job: build
+  timeout: 1h30m
+  steps:
+  - script: make
+    timeout: 10m
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	assert.Equal(t, int64(5400), p.Stages[0].Jobs[0].Action.Timeout)
+	assert.Equal(t, int64(600), p.Stages[0].Jobs[0].Action.Actions[0].Timeout)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, "1h30m", exported.Jobs[0].Timeout)
+	assert.Equal(t, "10m", exported.Jobs[0].Steps[0].Timeout)
+
+	payload.Jobs[0].Timeout = "forever"
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithRetryPolicy(t *testing.T) {
+	in := `name: build
+jobs:
+- job: build
+  retry:
+    max_attempts: 3
+    backoff: 30s
+    on_infrastructure_failure: true
+  steps:
+  - script: npm install
+    retry:
+      max_attempts: 2
+      exit_codes: [1, 128]
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	assert.Equal(t, sdk.ActionRetryPolicy{MaxAttempts: 3, Backoff: 30, OnInfrastructureFailure: true}, p.Stages[0].Jobs[0].Action.RetryPolicy)
+	assert.Equal(t, sdk.ActionRetryPolicy{MaxAttempts: 2, ExitCodes: []int{1, 128}}, p.Stages[0].Jobs[0].Action.Actions[0].RetryPolicy)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, &exportentities.RetryPolicy{MaxAttempts: 3, Backoff: "30s", OnInfrastructureFailure: true}, exported.Jobs[0].Retry)
+	assert.Equal(t, &exportentities.RetryPolicy{MaxAttempts: 2, ExitCodes: []int{1, 128}}, exported.Jobs[0].Steps[0].Retry)
+
+	payload.Jobs[0].Retry.MaxAttempts = -1
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithMatrix(t *testing.T) {
+	in := `name: build
+jobs:
+- job: test
+  matrix:
+    axes:
+      go: [1.12, 1.13]
+      arch: [amd64, arm]
+    exclude:
+    - go: 1.12
+      arch: arm
+  requirements:
+  - model: golang-{{.cds.matrix.go}}
+  steps:
+  - script: go test ./...
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	m := p.Stages[0].Jobs[0].Action.Matrix
+	assert.Equal(t, map[string][]string{"go": {"1.12", "1.13"}, "arch": {"amd64", "arm"}}, m.Axes)
+	assert.Len(t, m.Combinations(), 3)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, &exportentities.Matrix{
+		Axes:    map[string][]string{"go": {"1.12", "1.13"}, "arch": {"amd64", "arm"}},
+		Exclude: []map[string]string{{"go": "1.12", "arch": "arm"}},
+	}, exported.Jobs[0].Matrix)
+
+	payload.Jobs[0].Matrix.Axes["go-version"] = []string{"1.13"}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
 func Test_ImportPipelineWithOneStageAndRunConditions(t *testing.T) {
 	in := `version: v1.0
 name: echo
@@ -26,6 +26,8 @@ func newStep(act sdk.Action) Step {
 	if act.AlwaysExecuted {
 		s.AlwaysExecuted = &sdk.True
 	}
+	s.Timeout = newTimeout(act.Timeout)
+	s.Retry = newRetryPolicy(act.RetryPolicy)
 
 	switch act.Type {
 	case sdk.BuiltinAction:
@@ -63,6 +65,44 @@ func newStep(act sdk.Action) Step {
 			if pattern != nil {
 				s.ArtifactDownload.Pattern = pattern.Value
 			}
+		case sdk.ArtifactPromote:
+			s.ArtifactPromote = &StepArtifactPromote{}
+			project := sdk.ParameterFind(act.Parameters, "project")
+			if project != nil {
+				s.ArtifactPromote.Project = project.Value
+			}
+			workflow := sdk.ParameterFind(act.Parameters, "workflow")
+			if workflow != nil {
+				s.ArtifactPromote.Workflow = workflow.Value
+			}
+			number := sdk.ParameterFind(act.Parameters, "number")
+			if number != nil {
+				s.ArtifactPromote.Number = number.Value
+			}
+			branch := sdk.ParameterFind(act.Parameters, "branch")
+			if branch != nil {
+				s.ArtifactPromote.Branch = branch.Value
+			}
+			pattern := sdk.ParameterFind(act.Parameters, "pattern")
+			if pattern != nil {
+				s.ArtifactPromote.Pattern = pattern.Value
+			}
+			tag := sdk.ParameterFind(act.Parameters, "tag")
+			if tag != nil {
+				s.ArtifactPromote.Tag = tag.Value
+			}
+			destinationTag := sdk.ParameterFind(act.Parameters, "destination-tag")
+			if destinationTag != nil {
+				s.ArtifactPromote.DestinationTag = destinationTag.Value
+			}
+			mode := sdk.ParameterFind(act.Parameters, "mode")
+			if mode != nil {
+				s.ArtifactPromote.Mode = mode.Value
+			}
+			destination := sdk.ParameterFind(act.Parameters, "destination")
+			if destination != nil {
+				s.ArtifactPromote.Destination = destination.Value
+			}
 		case sdk.ArtifactUpload:
 			s.ArtifactUpload = &StepArtifactUpload{}
 			path := sdk.ParameterFind(act.Parameters, "path")
@@ -77,6 +117,28 @@ func newStep(act sdk.Action) Step {
 			if destination != nil {
 				s.ArtifactUpload.Destination = destination.Value
 			}
+		case sdk.CacheAction:
+			s.Cache = &StepCache{}
+			key := sdk.ParameterFind(act.Parameters, "key")
+			if key != nil {
+				s.Cache.Key = key.Value
+			}
+			restoreKeys := sdk.ParameterFind(act.Parameters, "restore-keys")
+			if restoreKeys != nil {
+				s.Cache.RestoreKeys = restoreKeys.Value
+			}
+			path := sdk.ParameterFind(act.Parameters, "path")
+			if path != nil {
+				s.Cache.Path = path.Value
+			}
+			defaultBranch := sdk.ParameterFind(act.Parameters, "default-branch")
+			if defaultBranch != nil {
+				s.Cache.DefaultBranch = defaultBranch.Value
+			}
+			destination := sdk.ParameterFind(act.Parameters, "destination")
+			if destination != nil {
+				s.Cache.Destination = destination.Value
+			}
 		case sdk.ServeStaticFiles:
 			s.ServeStaticFiles = &StepServeStaticFiles{}
 			name := sdk.ParameterFind(act.Parameters, "name")
@@ -259,6 +321,19 @@ type StepArtifactDownload struct {
 	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty" jsonschema:"required"`
 }
 
+// StepArtifactPromote represents exported artifact promote step.
+type StepArtifactPromote struct {
+	Branch         string `json:"branch,omitempty" yaml:"branch,omitempty"`
+	Destination    string `json:"destination,omitempty" yaml:"destination,omitempty"`
+	DestinationTag string `json:"destination-tag,omitempty" yaml:"destination-tag,omitempty"`
+	Mode           string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Number         string `json:"number,omitempty" yaml:"number,omitempty"`
+	Pattern        string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Project        string `json:"project,omitempty" yaml:"project,omitempty"`
+	Tag            string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Workflow       string `json:"workflow,omitempty" yaml:"workflow,omitempty" jsonschema:"required"`
+}
+
 // StepArtifactUpload represents exported artifact upload step.
 type StepArtifactUpload struct {
 	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
@@ -266,6 +341,15 @@ type StepArtifactUpload struct {
 	Tag         string `json:"tag,omitempty" yaml:"tag,omitempty" jsonschema:"required"`
 }
 
+// StepCache represents exported cache step.
+type StepCache struct {
+	DefaultBranch string `json:"default-branch,omitempty" yaml:"default-branch,omitempty"`
+	Destination   string `json:"destination,omitempty" yaml:"destination,omitempty"`
+	Key           string `json:"key,omitempty" yaml:"key,omitempty" jsonschema:"required"`
+	Path          string `json:"path,omitempty" yaml:"path,omitempty" jsonschema:"required"`
+	RestoreKeys   string `json:"restore-keys,omitempty" yaml:"restore-keys,omitempty"`
+}
+
 // StepServeStaticFiles represents exported serve static files step.
 type StepServeStaticFiles struct {
 	Destination string `json:"destination,omitempty" yaml:"destination,omitempty"`
@@ -322,16 +406,20 @@ type StepDeploy string
 // Step represents exported step used in a job.
 type Step struct {
 	// common step data
-	Name           string `json:"name,omitempty" yaml:"name,omitempty" jsonschema_description:"The name for this step."`
-	Enabled        *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
-	Optional       *bool  `json:"optional,omitempty" yaml:"optional,omitempty"`
-	AlwaysExecuted *bool  `json:"always_executed,omitempty" yaml:"always_executed,omitempty"`
+	Name           string       `json:"name,omitempty" yaml:"name,omitempty" jsonschema_description:"The name for this step."`
+	Enabled        *bool        `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Optional       *bool        `json:"optional,omitempty" yaml:"optional,omitempty"`
+	AlwaysExecuted *bool        `json:"always_executed,omitempty" yaml:"always_executed,omitempty"`
+	Timeout        string       `json:"timeout,omitempty" yaml:"timeout,omitempty" jsonschema_description:"Maximum duration of the step (ex: 10m)."`
+	Retry          *RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty" jsonschema_description:"Retry policy applied when the step fails."`
 	// step specific data, only one option should be set
 	StepCustom       `json:"-" yaml:",inline"`
 	Script           interface{}           `json:"script,omitempty" yaml:"script,omitempty" jsonschema:"-" jsonschema_description:"Script.\nhttps://ovh.github.io/cds/docs/actions/builtin-script"`
 	Coverage         *StepCoverage         `json:"coverage,omitempty" yaml:"coverage,omitempty" jsonschema_description:"Parse coverage report.\nhttps://ovh.github.io/cds/docs/actions/builtin-coverage"`
 	ArtifactDownload *StepArtifactDownload `json:"artifactDownload,omitempty" yaml:"artifactDownload,omitempty" jsonschema_description:"Download artifacts in workspace.\nhttps://ovh.github.io/cds/docs/actions/builtin-artifact-download"`
+	ArtifactPromote  *StepArtifactPromote  `json:"artifactPromote,omitempty" yaml:"artifactPromote,omitempty" jsonschema_description:"Promote artifacts of another workflow run.\nhttps://ovh.github.io/cds/docs/actions/builtin-artifact-promote"`
 	ArtifactUpload   *StepArtifactUpload   `json:"artifactUpload,omitempty" yaml:"artifactUpload,omitempty" jsonschema_description:"Upload artifacts from workspace.\nhttps://ovh.github.io/cds/docs/actions/builtin-artifact-upload"`
+	Cache            *StepCache            `json:"cache,omitempty" yaml:"cache,omitempty" jsonschema_description:"Restore and save a dependency cache keyed on file hashes.\nhttps://ovh.github.io/cds/docs/actions/builtin-cache"`
 	ServeStaticFiles *StepServeStaticFiles `json:"serveStaticFiles,omitempty" yaml:"serveStaticFiles,omitempty" jsonschema_description:"Serve static files.\nhttps://ovh.github.io/cds/docs/actions/builtin-serve-static-files"`
 	GitClone         *StepGitClone         `json:"gitClone,omitempty" yaml:"gitClone,omitempty" jsonschema_description:"Clone a git repository.\nhttps://ovh.github.io/cds/docs/actions/builtin-gitclone"`
 	GitTag           *StepGitTag           `json:"gitTag,omitempty" yaml:"gitTag,omitempty" jsonschema_description:"Create a git tag.\nhttps://ovh.github.io/cds/docs/actions/builtin-gittag"`
@@ -426,9 +514,15 @@ func (s Step) IsValid() bool {
 	if s.isArtifactDownload() {
 		count++
 	}
+	if s.isArtifactPromote() {
+		count++
+	}
 	if s.isArtifactUpload() {
 		count++
 	}
+	if s.isCache() {
+		count++
+	}
 	if s.isServeStaticFiles() {
 		count++
 	}
@@ -473,8 +567,12 @@ func (s Step) toAction() (*sdk.Action, error) {
 	var err error
 	if s.isArtifactDownload() {
 		a, err = s.asArtifactDownload()
+	} else if s.isArtifactPromote() {
+		a, err = s.asArtifactPromote()
 	} else if s.isArtifactUpload() {
 		a, err = s.asArtifactUpload()
+	} else if s.isCache() {
+		a, err = s.asCache()
 	} else if s.isServeStaticFiles() {
 		a, err = s.asServeStaticFiles()
 	} else if s.isJUnitReport() {
@@ -506,6 +604,14 @@ func (s Step) toAction() (*sdk.Action, error) {
 	a.Enabled = s.Enabled == nil || *s.Enabled == sdk.True // enabled is true by default
 	a.Optional = s.Optional != nil && *s.Optional == sdk.True
 	a.AlwaysExecuted = s.AlwaysExecuted != nil && *s.AlwaysExecuted == sdk.True
+	a.Timeout, err = computeTimeout(s.Timeout)
+	if err != nil {
+		return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid timeout for step"))
+	}
+	a.RetryPolicy, err = computeRetryPolicy(s.Retry)
+	if err != nil {
+		return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid retry policy for step"))
+	}
 
 	return &a, nil
 }
@@ -563,6 +669,22 @@ func (s Step) asArtifactDownload() (sdk.Action, error) {
 	return a, nil
 }
 
+func (s Step) isArtifactPromote() bool { return s.ArtifactPromote != nil }
+
+func (s Step) asArtifactPromote() (sdk.Action, error) {
+	var a sdk.Action
+	m, err := stepToMap(s.ArtifactPromote)
+	if err != nil {
+		return a, err
+	}
+	a = sdk.Action{
+		Name:       sdk.ArtifactPromote,
+		Type:       sdk.BuiltinAction,
+		Parameters: sdk.ParametersFromMap(m),
+	}
+	return a, nil
+}
+
 func (s Step) isCheckout() bool { return s.Checkout != nil }
 
 func (s Step) asCheckoutApplication() sdk.Action {
@@ -743,6 +865,22 @@ func (s Step) asArtifactUpload() (sdk.Action, error) {
 	return a, nil
 }
 
+func (s Step) isCache() bool { return s.Cache != nil }
+
+func (s Step) asCache() (sdk.Action, error) {
+	var a sdk.Action
+	m, err := stepToMap(s.Cache)
+	if err != nil {
+		return a, err
+	}
+	a = sdk.Action{
+		Name:       sdk.CacheAction,
+		Type:       sdk.BuiltinAction,
+		Parameters: sdk.ParametersFromMap(m),
+	}
+	return a, nil
+}
+
 func (s Step) asAction() sdk.Action {
 	var name string
 	for k := range s.StepCustom {
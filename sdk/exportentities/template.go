@@ -21,6 +21,7 @@ type Template struct {
 	Group        string              `json:"group" yaml:"group"`
 	Description  string              `json:"description,omitempty" yaml:"description,omitempty"`
 	Parameters   []TemplateParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Includes     []TemplateInclude   `json:"includes,omitempty" yaml:"includes,omitempty"`
 	Workflow     string
 	Pipelines    []string
 	Applications []string
@@ -34,6 +35,12 @@ type TemplateParameter struct {
 	Required bool   `json:"required" yaml:"required"`
 }
 
+// TemplateInclude is the "as code" representation of a sdk.WorkflowTemplateInclude.
+type TemplateInclude struct {
+	Template   string            `json:"template" yaml:"template"`
+	Parameters map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
 // Name pattern for template files.
 const (
 	TemplateWorkflowName    = "workflow.yml"
@@ -62,6 +69,13 @@ func NewTemplate(wt sdk.WorkflowTemplate) (Template, error) {
 		exportedTemplate.Parameters[i].Required = p.Required
 	}
 
+	for _, inc := range wt.Includes {
+		exportedTemplate.Includes = append(exportedTemplate.Includes, TemplateInclude{
+			Template:   inc.Template,
+			Parameters: inc.Parameters,
+		})
+	}
+
 	for i := range wt.Pipelines {
 		exportedTemplate.Pipelines[i] = fmt.Sprintf(TemplatePipelineName, i+1)
 	}
@@ -98,6 +112,13 @@ func (w Template) GetTemplate(wkf []byte, pips, apps, envs [][]byte) sdk.Workflo
 		})
 	}
 
+	for _, inc := range w.Includes {
+		wt.Includes = append(wt.Includes, sdk.WorkflowTemplateInclude{
+			Template:   inc.Template,
+			Parameters: inc.Parameters,
+		})
+	}
+
 	for i := range pips {
 		wt.Pipelines[i].Value = base64.StdEncoding.EncodeToString(pips[i])
 	}
@@ -22,6 +22,7 @@ type WorkerModel struct {
 	PreCmd        string            `json:"pre_cmd,omitempty" yaml:"pre_cmd,omitempty"`
 	Cmd           string            `json:"cmd,omitempty" yaml:"cmd,omitempty"`
 	PostCmd       string            `json:"post_cmd,omitempty" yaml:"post_cmd,omitempty"`
+	PodTemplate   string            `json:"pod_template,omitempty" yaml:"pod_template,omitempty"`
 	Restricted    bool              `json:"restricted,omitempty" yaml:"restricted,omitempty"`
 	IsDeprecated  bool              `json:"is_deprecated,omitempty" yaml:"is_deprecated,omitempty"`
 }
@@ -40,6 +41,7 @@ func loadWorkerModelWithoutAdminFields(_ sdk.Model, wm *WorkerModel) error {
 	wm.Cmd = ""
 	wm.PostCmd = ""
 	wm.Envs = nil
+	wm.PodTemplate = ""
 	return nil
 }
 
@@ -62,6 +64,7 @@ func NewWorkerModel(wm sdk.Model, opts ...WorkerModelOption) WorkerModel {
 		model.Image = wm.ModelDocker.Image
 		model.Cmd = wm.ModelDocker.Cmd
 		model.Envs = wm.ModelDocker.Envs
+		model.PodTemplate = wm.ModelDocker.PodTemplate
 		if wm.ModelDocker.Private {
 			model.Registry = wm.ModelDocker.Registry
 			model.Username = wm.ModelDocker.Username
@@ -100,10 +103,11 @@ func (wm WorkerModel) GetWorkerModel() sdk.Model {
 	switch wm.Type {
 	case sdk.Docker:
 		model.ModelDocker = sdk.ModelDocker{
-			Shell: wm.Shell,
-			Image: wm.Image,
-			Cmd:   wm.Cmd,
-			Envs:  wm.Envs,
+			Shell:       wm.Shell,
+			Image:       wm.Image,
+			Cmd:         wm.Cmd,
+			Envs:        wm.Envs,
+			PodTemplate: wm.PodTemplate,
 		}
 		if wm.Username != "" || wm.Registry != "" || wm.Password != "" {
 			model.ModelDocker.Registry = wm.Registry
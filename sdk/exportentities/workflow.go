@@ -33,6 +33,7 @@ type Workflow struct {
 	// this will be filled for simple workflows
 	DependsOn              []string                    `json:"depends_on,omitempty" yaml:"depends_on,omitempty" jsonschema_description:"Names of the parent nodes, can be pipelines, forks or joins."`
 	OneAtATime             *bool                       `json:"one_at_a_time,omitempty" yaml:"one_at_a_time,omitempty" jsonschema_description:"Set to true if you want to limit the execution of this node to one at a time."`
+	Lock                   string                      `json:"lock,omitempty" yaml:"lock,omitempty" jsonschema_description:"Name of a project lock acquired by the node, runs of nodes with the same lock are executed one at a time across all the workflows of the project."`
 	Conditions             *sdk.WorkflowNodeConditions `json:"conditions,omitempty" yaml:"conditions,omitempty" jsonschema_description:"Conditions to run this node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/run-conditions."`
 	When                   []string                    `json:"when,omitempty" yaml:"when,omitempty" jsonschema_description:"Set manual and status condition (ex: 'success')."` //This is used only for manual and success condition
 	PipelineName           string                      `json:"pipeline,omitempty" yaml:"pipeline,omitempty" jsonschema_description:"The name of a pipeline used for pipeline node."`
@@ -48,9 +49,26 @@ type Workflow struct {
 	PurgeTags        []string                       `json:"purge_tags,omitempty" yaml:"purge_tags,omitempty"`
 	Notifications    []NotificationEntry            `json:"notify,omitempty" yaml:"notify,omitempty"` // This is used when the workflow have only one pipeline
 	HistoryLength    *int64                         `json:"history_length,omitempty" yaml:"history_length,omitempty"`
+	Retention        *RetentionEntry                `json:"retention,omitempty" yaml:"retention,omitempty" jsonschema_description:"Retention policy for workflow runs, replaces history_length and purge_tags."`
+	Concurrency      *ConcurrencyEntry              `json:"concurrency,omitempty" yaml:"concurrency,omitempty" jsonschema_description:"Limit the number of concurrent runs of the workflow for a key (ex: {{.git.branch}})."`
 	MapNotifications map[string][]NotificationEntry `json:"notifications,omitempty" yaml:"notifications,omitempty"` // This is used when the workflow have more than one pipeline
 }
 
+// RetentionEntry represents a workflow retention policy as code
+type RetentionEntry struct {
+	KeepLastPerBranch  int64    `json:"keep_last_per_branch,omitempty" yaml:"keep_last_per_branch,omitempty" jsonschema_description:"Number of runs kept for each git branch."`
+	KeepTags           []string `json:"keep_tags,omitempty" yaml:"keep_tags,omitempty" jsonschema_description:"Runs with one of these tags are always kept (ex: release or git.branch=master)."`
+	DeletedBranchesTTL string   `json:"deleted_branches_ttl,omitempty" yaml:"deleted_branches_ttl,omitempty" jsonschema_description:"Runs of a deleted branch are deleted after this delay (ex: 168h)."`
+	KeepLastSuccess    bool     `json:"keep_last_success,omitempty" yaml:"keep_last_success,omitempty" jsonschema_description:"Always keep the last run in success."`
+}
+
+// ConcurrencyEntry represents a workflow concurrency as code
+type ConcurrencyEntry struct {
+	Key    string `json:"key" yaml:"key" jsonschema_description:"Template of the key used to group runs (ex: {{.git.branch}})."`
+	Max    int64  `json:"max,omitempty" yaml:"max,omitempty" jsonschema_description:"Max number of concurrent runs for a key, default is 1."`
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty" jsonschema_description:"What to do when the max is reached: cancel older runs (default), queue or skip the new run."`
+}
+
 // WorkflowPulled contains all the yaml base64 that are needed to generate a workflow tar file.
 type WorkflowPulled struct {
 	Workflow     WorkflowPulledItem   `json:"workflow"`
@@ -76,11 +94,21 @@ type NodeEntry struct {
 	EnvironmentName        string                      `json:"environment,omitempty" yaml:"environment,omitempty" jsonschema_description:"The environment to use in the context of the node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
 	ProjectIntegrationName string                      `json:"integration,omitempty" yaml:"integration,omitempty" jsonschema_description:"The integration to use in the context of the node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
 	OneAtATime             *bool                       `json:"one_at_a_time,omitempty" yaml:"one_at_a_time,omitempty" jsonschema_description:"Set to true if you want to limit the execution of this node to one at a time."`
+	Lock                   string                      `json:"lock,omitempty" yaml:"lock,omitempty" jsonschema_description:"Name of a project lock acquired by the node, runs of nodes with the same lock are executed one at a time across all the workflows of the project."`
 	Payload                map[string]interface{}      `json:"payload,omitempty" yaml:"payload,omitempty"`
 	Parameters             map[string]string           `json:"parameters,omitempty" yaml:"parameters,omitempty" jsonschema_description:"List of parameters for the workflow."`
 	OutgoingHookModelName  string                      `json:"trigger,omitempty" yaml:"trigger,omitempty"`
 	OutgoingHookConfig     map[string]string           `json:"config,omitempty" yaml:"config,omitempty"`
 	Permissions            map[string]int              `json:"permissions,omitempty" yaml:"permissions,omitempty" jsonschema_description:"The permissions for the node (ex: myGroup: 7).\nhttps://ovh.github.io/cds/docs/concepts/permissions"`
+	Approval               *ApprovalEntry              `json:"approval,omitempty" yaml:"approval,omitempty" jsonschema_description:"Set to make the node a manual approval gate."`
+}
+
+// ApprovalEntry represents the configuration of an approval node as code
+type ApprovalEntry struct {
+	Groups       []string `json:"groups,omitempty" yaml:"groups,omitempty" jsonschema_description:"Names of the groups allowed to approve."`
+	MinApprovals int      `json:"min_approvals,omitempty" yaml:"min_approvals,omitempty" jsonschema_description:"Number of approvals needed, default is 1."`
+	Expiry       string   `json:"expiry,omitempty" yaml:"expiry,omitempty" jsonschema_description:"Delay after which the node fails if not approved (ex: 24h)."`
+	Comment      string   `json:"comment,omitempty" yaml:"comment,omitempty"`
 }
 
 // HookEntry represents a hook as code
@@ -97,6 +125,7 @@ type WorkflowVersion string
 // There are the supported versions
 const (
 	WorkflowVersion1 = "v1.0"
+	WorkflowVersion2 = "v2.0"
 )
 
 func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
@@ -141,8 +170,12 @@ func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
 
 	if n.Context != nil {
 		conditions := []sdk.WorkflowNodeCondition{}
+		// Status and manual conditions can only be exported as 'when' if they are combined with others using 'and'
+		isAndGroup := !n.Context.Conditions.Not && n.Context.Conditions.Operator != sdk.WorkflowConditionsGroupOperatorOr
 		for _, c := range n.Context.Conditions.PlainConditions {
-			if c.Operator == sdk.WorkflowConditionsOperatorEquals &&
+			if !isAndGroup {
+				conditions = append(conditions, c)
+			} else if c.Operator == sdk.WorkflowConditionsOperatorEquals &&
 				c.Value == sdk.StatusSuccess &&
 				c.Variable == "cds.status" {
 				entry.When = append(entry.When, "success")
@@ -155,10 +188,13 @@ func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
 			}
 		}
 
-		if len(conditions) > 0 || n.Context.Conditions.LuaScript != "" {
+		if len(conditions) > 0 || n.Context.Conditions.LuaScript != "" || len(n.Context.Conditions.Groups) > 0 {
 			entry.Conditions = &sdk.WorkflowNodeConditions{
 				PlainConditions: conditions,
 				LuaScript:       n.Context.Conditions.LuaScript,
+				Operator:        n.Context.Conditions.Operator,
+				Not:             n.Context.Conditions.Not,
+				Groups:          n.Context.Conditions.Groups,
 			}
 		}
 
@@ -175,6 +211,7 @@ func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
 		if n.Context.Mutex {
 			entry.OneAtATime = &n.Context.Mutex
 		}
+		entry.Lock = n.Context.Lock
 
 		if n.Context.HasDefaultPayload() {
 			enc := dump.NewDefaultEncoder()
@@ -202,6 +239,15 @@ func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
 		}
 	}
 
+	if n.ApprovalContext != nil {
+		entry.Approval = &ApprovalEntry{
+			Groups:       n.ApprovalContext.Groups,
+			MinApprovals: n.ApprovalContext.MinApprovals,
+			Expiry:       newTimeout(n.ApprovalContext.Expiry),
+			Comment:      n.ApprovalContext.Comment,
+		}
+	}
+
 	if n.OutGoingHookContext != nil {
 		entry.OutgoingHookModelName = n.OutGoingHookContext.HookModelName
 
@@ -280,7 +326,7 @@ func WorkflowSkipIfOnlyOneRepoWebhook(w sdk.Workflow, exportedWorkflow *Workflow
 }
 
 func joinAsNode(n *sdk.Node) bool {
-	return n.Context != nil && (n.Context.Conditions.LuaScript != "" || len(n.Context.Conditions.PlainConditions) > 0)
+	return n.Context != nil && !n.Context.Conditions.IsEmpty()
 }
 
 //NewWorkflow creates a new exportable workflow
@@ -307,6 +353,23 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, opts ...WorkflowOptions) (
 
 	exportedWorkflow.PurgeTags = w.PurgeTags
 
+	if w.RetentionPolicy != nil && !w.RetentionPolicy.IsEmpty() {
+		exportedWorkflow.Retention = &RetentionEntry{
+			KeepLastPerBranch:  w.RetentionPolicy.KeepLastPerBranch,
+			KeepTags:           w.RetentionPolicy.KeepTags,
+			DeletedBranchesTTL: newTimeout(w.RetentionPolicy.DeletedBranchesTTL),
+			KeepLastSuccess:    w.RetentionPolicy.KeepLastSuccess,
+		}
+	}
+
+	if w.Concurrency != nil {
+		exportedWorkflow.Concurrency = &ConcurrencyEntry{
+			Key:    w.Concurrency.Key,
+			Max:    w.Concurrency.Max,
+			Policy: w.Concurrency.Policy,
+		}
+	}
+
 	nodes := w.WorkflowData.Array()
 
 	if len(nodes) == 1 {
@@ -321,7 +384,8 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, opts ...WorkflowOptions) (
 		exportedWorkflow.ProjectIntegrationName = entry.ProjectIntegrationName
 		exportedWorkflow.DependsOn = entry.DependsOn
 		exportedWorkflow.OneAtATime = entry.OneAtATime
-		if entry.Conditions != nil && (len(entry.Conditions.PlainConditions) > 0 || entry.Conditions.LuaScript != "") {
+		exportedWorkflow.Lock = entry.Lock
+		if entry.Conditions != nil && !entry.Conditions.IsEmpty() {
 			exportedWorkflow.When = entry.When
 			exportedWorkflow.Conditions = entry.Conditions
 		}
@@ -342,7 +406,7 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, opts ...WorkflowOptions) (
 				Conditions: &h.Conditions,
 			}
 
-			if h.Conditions.LuaScript == "" && len(h.Conditions.PlainConditions) == 0 {
+			if h.Conditions.IsEmpty() {
 				pipHook.Conditions = nil
 			}
 
@@ -378,7 +442,7 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, opts ...WorkflowOptions) (
 					Conditions: &h.Conditions,
 				}
 
-				if h.Conditions.LuaScript == "" && len(h.Conditions.PlainConditions) == 0 {
+				if h.Conditions.IsEmpty() {
 					pipHook.Conditions = nil
 				}
 
@@ -423,6 +487,7 @@ func (w Workflow) Entries() map[string]NodeEntry {
 		Payload:                w.Payload,
 		Parameters:             w.Parameters,
 		OneAtATime:             w.OneAtATime,
+		Lock:                   w.Lock,
 	}
 	return map[string]NodeEntry{
 		w.PipelineName: singleEntry,
@@ -539,6 +604,25 @@ func (w Workflow) GetWorkflow() (*sdk.Workflow, error) {
 	} else {
 		wf.HistoryLength = sdk.DefaultHistoryLength
 	}
+	if w.Retention != nil {
+		ttl, err := computeTimeout(w.Retention.DeletedBranchesTTL)
+		if err != nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid deleted branches ttl %s for retention", w.Retention.DeletedBranchesTTL)
+		}
+		wf.RetentionPolicy = &sdk.WorkflowRetentionPolicy{
+			KeepLastPerBranch:  w.Retention.KeepLastPerBranch,
+			KeepTags:           w.Retention.KeepTags,
+			DeletedBranchesTTL: ttl,
+			KeepLastSuccess:    w.Retention.KeepLastSuccess,
+		}
+	}
+	if w.Concurrency != nil {
+		wf.Concurrency = &sdk.WorkflowConcurrency{
+			Key:    w.Concurrency.Key,
+			Max:    w.Concurrency.Max,
+			Policy: w.Concurrency.Policy,
+		}
+	}
 
 	rand.Seed(time.Now().Unix())
 	entries := w.Entries()
@@ -617,6 +701,8 @@ func (e *NodeEntry) getNode(name string, w *sdk.Workflow) (*sdk.Node, error) {
 		node.Type = sdk.NodeTypePipeline
 	} else if e.OutgoingHookModelName != "" {
 		node.Type = sdk.NodeTypeOutGoingHook
+	} else if e.Approval != nil {
+		node.Type = sdk.NodeTypeApproval
 	} else if len(e.DependsOn) > 1 {
 		node.Type = sdk.NodeTypeJoin
 		node.JoinContext = make([]sdk.NodeJoin, 0, len(e.DependsOn))
@@ -648,6 +734,17 @@ func (e *NodeEntry) getNode(name string, w *sdk.Workflow) (*sdk.Node, error) {
 	mapPipelineParameters := sdk.ParametersFromMap(e.Parameters)
 	node.Context.DefaultPipelineParameters = mapPipelineParameters
 
+	// The 'when' statuses are and'ed with the other conditions, so conditions combined with 'or' or negated are
+	// moved to a nested group to keep their meaning
+	if len(e.When) > 0 && (node.Context.Conditions.Operator == sdk.WorkflowConditionsGroupOperatorOr || node.Context.Conditions.Not) {
+		group := node.Context.Conditions
+		group.LuaScript = ""
+		node.Context.Conditions = sdk.WorkflowNodeConditions{
+			LuaScript: node.Context.Conditions.LuaScript,
+			Groups:    []sdk.WorkflowNodeConditions{group},
+		}
+	}
+
 	for _, w := range e.When {
 		switch w {
 		case "success":
@@ -670,6 +767,7 @@ func (e *NodeEntry) getNode(name string, w *sdk.Workflow) (*sdk.Node, error) {
 	if e.OneAtATime != nil {
 		node.Context.Mutex = *e.OneAtATime
 	}
+	node.Context.Lock = e.Lock
 
 	if e.OutgoingHookModelName != "" {
 		node.Type = sdk.NodeTypeOutGoingHook
@@ -684,6 +782,19 @@ func (e *NodeEntry) getNode(name string, w *sdk.Workflow) (*sdk.Node, error) {
 			HookModelName: e.OutgoingHookModelName,
 		}
 	}
+
+	if e.Approval != nil {
+		expiry, err := computeTimeout(e.Approval.Expiry)
+		if err != nil {
+			return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid approval expiry for node %s", name))
+		}
+		node.ApprovalContext = &sdk.NodeApproval{
+			Groups:       e.Approval.Groups,
+			MinApprovals: e.Approval.MinApprovals,
+			Expiry:       expiry,
+			Comment:      e.Approval.Comment,
+		}
+	}
 	return node, nil
 }
 
@@ -694,7 +805,7 @@ func (w *Workflow) processHooks(n *sdk.Node, wf *sdk.Workflow) {
 			for k, v := range h.Config {
 				var hType string
 				switch h.Model {
-				case sdk.KafkaHookModelName, sdk.RabbitMQHookModelName:
+				case sdk.KafkaHookModelName, sdk.RabbitMQHookModelName, sdk.NATSHookModelName, sdk.AMQPHookModelName:
 					if k == sdk.HookModelIntegration {
 						hType = sdk.HookConfigTypeIntegration
 					} else {
@@ -10,6 +10,7 @@ import (
 
 	"github.com/fsamin/go-dump"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 
 	"github.com/ovh/cds/sdk"
@@ -1157,6 +1158,30 @@ workflow:
 version: v1.0
 one_at_a_time: true
 pipeline: env
+`,
+		},
+		{
+			name: "Workflow with retention policy",
+			yaml: `name: myretention
+version: v1.0
+pipeline: env
+retention:
+  keep_last_per_branch: 50
+  keep_tags:
+  - release
+  deleted_branches_ttl: 168h
+  keep_last_success: true
+`,
+		},
+		{
+			name: "Workflow with concurrency",
+			yaml: `name: myconcurrency
+version: v1.0
+pipeline: env
+concurrency:
+  key: '{{.git.branch}}'
+  max: 2
+  policy: queue
 `,
 		},
 		{
@@ -1174,6 +1199,76 @@ workflow:
     - success
     pipeline: env
     one_at_a_time: true
+`,
+		},
+		{
+			name: "Workflow with lock",
+			yaml: `name: mylock
+version: v1.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    when:
+    - success
+    pipeline: deploy
+    lock: staging-db
+`,
+		},
+		{
+			name: "Workflow with grouped conditions",
+			yaml: `name: conditions
+version: v1.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    conditions:
+      check:
+      - variable: cds.status
+        operator: eq
+        value: Success
+      - variable: git.tag
+        operator: semver
+        value: '>=1.9.0'
+      operator: or
+      groups:
+      - check:
+        - variable: git.branch
+          operator: glob
+          value: release/*
+        not: true
+    pipeline: deploy
+`,
+		},
+		{
+			name: "Workflow with approval node",
+			yaml: `name: approval
+version: v1.0
+workflow:
+  approve:
+    depends_on:
+    - build
+    when:
+    - success
+    approval:
+      groups:
+      - ops
+      min_approvals: 2
+      expiry: 24h
+      comment: Deployment to production
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - approve
+    when:
+    - success
+    pipeline: deploy
 `,
 		},
 	}
@@ -1247,3 +1342,95 @@ workflow:
 		})
 	}
 }
+
+func TestWorkflow_GetWorkflowWhenWithConditionsGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions string
+		params     map[string]string
+		want       bool
+	}{
+		{
+			name: "when is and'ed with or conditions",
+			conditions: `
+      check:
+      - variable: git.branch
+        operator: eq
+        value: master
+      - variable: git.tag
+        operator: semver
+        value: '>=1.0.0'
+      operator: or`,
+			params: map[string]string{"cds.status": sdk.StatusFail, "git.branch": "master", "git.tag": "1.2.0"},
+			want:   false,
+		},
+		{
+			name: "or conditions still apply with when",
+			conditions: `
+      check:
+      - variable: git.branch
+        operator: eq
+        value: master
+      - variable: git.tag
+        operator: semver
+        value: '>=1.0.0'
+      operator: or`,
+			params: map[string]string{"cds.status": sdk.StatusSuccess, "git.branch": "dev", "git.tag": "1.2.0"},
+			want:   true,
+		},
+		{
+			name: "when is not negated with not conditions",
+			conditions: `
+      check:
+      - variable: git.branch
+        operator: eq
+        value: master
+      not: true`,
+			params: map[string]string{"cds.status": sdk.StatusFail, "git.branch": "dev"},
+			want:   false,
+		},
+		{
+			name: "not conditions still apply with when",
+			conditions: `
+      check:
+      - variable: git.branch
+        operator: eq
+        value: master
+      not: true`,
+			params: map[string]string{"cds.status": sdk.StatusSuccess, "git.branch": "dev"},
+			want:   true,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			var yamlWorkflow exportentities.Workflow
+			require.NoError(t, yaml.Unmarshal([]byte(`name: test
+version: v1.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    when:
+    - success
+    conditions:`+tst.conditions+`
+    pipeline: deploy
+`), &yamlWorkflow))
+
+			w, err := yamlWorkflow.GetWorkflow()
+			require.NoError(t, err)
+			deploy := w.WorkflowData.NodeByName("deploy")
+			require.NotNil(t, deploy)
+
+			ok, err := sdk.WorkflowCheckNodeConditions(deploy.Context.Conditions, sdk.ParametersFromMap(tst.params))
+			require.NoError(t, err)
+			assert.Equal(t, tst.want, ok)
+
+			// The conditions can be exported back with the when statuses
+			exportedWorkflow, err := exportentities.NewWorkflow(context.TODO(), *w)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"success"}, exportedWorkflow.Workflow["deploy"].When)
+		})
+	}
+}
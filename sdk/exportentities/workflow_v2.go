@@ -0,0 +1,393 @@
+package exportentities
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// WorkflowV2 is the "as code" representation of a sdk.Workflow for the v2.0 syntax. Pipelines and environments
+// can be declared inline, other files can be included and each node declares its parents with needs.
+type WorkflowV2 struct {
+	Name          string                         `json:"name,omitempty" yaml:"name,omitempty" jsonschema_description:"The name of the workflow."`
+	Description   string                         `json:"description,omitempty" yaml:"description,omitempty"`
+	Version       string                         `json:"version" yaml:"version" jsonschema_description:"Version for the yaml syntax, should be v2.0."`
+	Template      *string                        `json:"template,omitempty" yaml:"template,omitempty" jsonschema_description:"Optional path of the template used to generate the workflow."`
+	Include       []WorkflowInclude              `json:"include,omitempty" yaml:"include,omitempty" jsonschema_description:"Files to include from the repository or from a library repository, definitions of the including file take precedence over included ones."`
+	Pipelines     map[string]PipelineV1          `json:"pipelines,omitempty" yaml:"pipelines,omitempty" jsonschema_description:"Inline pipelines by name."`
+	Environments  map[string]Environment         `json:"environments,omitempty" yaml:"environments,omitempty" jsonschema_description:"Inline environments by name."`
+	Workflow      map[string]NodeEntryV2         `json:"workflow,omitempty" yaml:"workflow,omitempty" jsonschema_description:"Workflow nodes list."`
+	Hooks         map[string][]HookEntry         `json:"hooks,omitempty" yaml:"hooks,omitempty" jsonschema_description:"Workflow hooks list."`
+	Permissions   map[string]int                 `json:"permissions,omitempty" yaml:"permissions,omitempty" jsonschema_description:"The permissions for the workflow (ex: myGroup: 7).\nhttps://ovh.github.io/cds/docs/concepts/permissions"`
+	Metadata      map[string]string              `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	PurgeTags     []string                       `json:"purge_tags,omitempty" yaml:"purge_tags,omitempty"`
+	HistoryLength *int64                         `json:"history_length,omitempty" yaml:"history_length,omitempty"`
+	Retention     *RetentionEntry                `json:"retention,omitempty" yaml:"retention,omitempty" jsonschema_description:"Retention policy for workflow runs, replaces history_length and purge_tags."`
+	Concurrency   *ConcurrencyEntry              `json:"concurrency,omitempty" yaml:"concurrency,omitempty" jsonschema_description:"Limit the number of concurrent runs of the workflow for a key (ex: {{.git.branch}})."`
+	Notifications map[string][]NotificationEntry `json:"notifications,omitempty" yaml:"notifications,omitempty" jsonschema_description:"Notifications by node names, several nodes can be given separated by commas."`
+}
+
+// NodeEntryV2 represents a node as code for the v2.0 syntax
+type NodeEntryV2 struct {
+	Needs                  []string                    `json:"needs,omitempty" yaml:"needs,omitempty" jsonschema_description:"Names of the parent nodes, can be pipelines, forks or joins."`
+	Conditions             *sdk.WorkflowNodeConditions `json:"conditions,omitempty" yaml:"conditions,omitempty" jsonschema_description:"Conditions to run this node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/run-conditions."`
+	When                   []string                    `json:"when,omitempty" yaml:"when,omitempty" jsonschema_description:"Set manual and status condition (ex: 'success')."`
+	PipelineName           string                      `json:"pipeline,omitempty" yaml:"pipeline,omitempty" jsonschema_description:"The name of a pipeline used for pipeline node, inline or not."`
+	ApplicationName        string                      `json:"application,omitempty" yaml:"application,omitempty" jsonschema_description:"The application to use in the context of the node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
+	EnvironmentName        string                      `json:"environment,omitempty" yaml:"environment,omitempty" jsonschema_description:"The environment to use in the context of the node, inline or not.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
+	ProjectIntegrationName string                      `json:"integration,omitempty" yaml:"integration,omitempty" jsonschema_description:"The integration to use in the context of the node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
+	OneAtATime             *bool                       `json:"one_at_a_time,omitempty" yaml:"one_at_a_time,omitempty" jsonschema_description:"Set to true if you want to limit the execution of this node to one at a time."`
+	Lock                   string                      `json:"lock,omitempty" yaml:"lock,omitempty" jsonschema_description:"Name of a project lock acquired by the node, runs of nodes with the same lock are executed one at a time across all the workflows of the project."`
+	Payload                map[string]interface{}      `json:"payload,omitempty" yaml:"payload,omitempty"`
+	Parameters             map[string]string           `json:"parameters,omitempty" yaml:"parameters,omitempty" jsonschema_description:"List of parameters for the workflow."`
+	OutgoingHookModelName  string                      `json:"trigger,omitempty" yaml:"trigger,omitempty"`
+	OutgoingHookConfig     map[string]string           `json:"config,omitempty" yaml:"config,omitempty"`
+	Permissions            map[string]int              `json:"permissions,omitempty" yaml:"permissions,omitempty" jsonschema_description:"The permissions for the node (ex: myGroup: 7).\nhttps://ovh.github.io/cds/docs/concepts/permissions"`
+	Approval               *ApprovalEntry              `json:"approval,omitempty" yaml:"approval,omitempty" jsonschema_description:"Set to make the node a manual approval gate."`
+}
+
+// WorkflowInclude is a file included by a v2.0 workflow, from the repository of the workflow
+// or from a library repository at a given tag.
+type WorkflowInclude struct {
+	Path       string `json:"path" yaml:"path" jsonschema_description:"Path of the file from the root of the repository."`
+	Repository string `json:"repository,omitempty" yaml:"repository,omitempty" jsonschema_description:"Full name of a library repository on the same repository manager (ex: my-org/cds-library), default is the repository of the workflow."`
+	Ref        string `json:"ref,omitempty" yaml:"ref,omitempty" jsonschema_description:"Tag of the library repository to include the file from, mandatory for a library repository."`
+}
+
+// IsValid returns include validity.
+func (i WorkflowInclude) IsValid() error {
+	if i.Path == "" {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid include: missing path")
+	}
+	if strings.HasPrefix(path.Clean(strings.TrimPrefix(i.Path, "/")), "..") {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid include %s: path should be inside the repository", i.Path)
+	}
+	if i.IsLibrary() && i.Ref == "" {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid include %s: a ref is mandatory to include a file from repository %s", i.Path, i.Repository)
+	}
+	if !i.IsLibrary() && i.Ref != "" {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid include %s: a ref can only be given for a library repository", i.Path)
+	}
+	return nil
+}
+
+// IsLibrary returns true if the file is included from a library repository.
+func (i WorkflowInclude) IsLibrary() bool {
+	return i.Repository != ""
+}
+
+// Key returns the key of the included file in the files given to ResolveIncludes, it's the path of the file
+// for the repository of the workflow and is prefixed by the repository and the ref for a library repository.
+func (i WorkflowInclude) Key() string {
+	p := path.Clean(strings.TrimPrefix(i.Path, "/"))
+	if i.IsLibrary() {
+		return fmt.Sprintf("%s@%s:%s", i.Repository, i.Ref, p)
+	}
+	return p
+}
+
+// ReadWorkflowIncludes returns the files included by given workflow file.
+func ReadWorkflowIncludes(btes []byte) ([]WorkflowInclude, error) {
+	var w struct {
+		Include []WorkflowInclude `yaml:"include"`
+	}
+	if err := yaml.Unmarshal(btes, &w); err != nil {
+		return nil, sdk.WithStack(err)
+	}
+	return w.Include, nil
+}
+
+// UnmarshalWorkflow reads a workflow file of any version and returns it with the v2.0 syntax.
+func UnmarshalWorkflow(btes []byte, f Format) (*WorkflowV2, error) {
+	var v struct {
+		Version string `json:"version" yaml:"version"`
+	}
+	if err := Unmarshal(btes, f, &v); err != nil {
+		return nil, err
+	}
+
+	switch v.Version {
+	case WorkflowVersion2:
+		var w WorkflowV2
+		if err := Unmarshal(btes, f, &w); err != nil {
+			return nil, err
+		}
+		return &w, nil
+	case "", WorkflowVersion1:
+		var w Workflow
+		if err := Unmarshal(btes, f, &w); err != nil {
+			return nil, err
+		}
+		w2 := NewWorkflowV2(w)
+		return &w2, nil
+	default:
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "unsupported workflow version %s", v.Version)
+	}
+}
+
+// NewWorkflowV2 converts a v1.0 workflow to the v2.0 syntax, a workflow with only one pipeline is converted to a workflow
+// with one node named as its pipeline.
+func NewWorkflowV2(w Workflow) WorkflowV2 {
+	w2 := WorkflowV2{
+		Name:          w.Name,
+		Description:   w.Description,
+		Version:       WorkflowVersion2,
+		Template:      w.Template,
+		Hooks:         w.Hooks,
+		Permissions:   w.Permissions,
+		Metadata:      w.Metadata,
+		PurgeTags:     w.PurgeTags,
+		HistoryLength: w.HistoryLength,
+		Retention:     w.Retention,
+		Concurrency:   w.Concurrency,
+		Notifications: w.MapNotifications,
+	}
+
+	entries := w.Entries()
+	w2.Workflow = make(map[string]NodeEntryV2, len(entries))
+	for name, e := range entries {
+		w2.Workflow[name] = NodeEntryV2{
+			Needs:                  e.DependsOn,
+			Conditions:             e.Conditions,
+			When:                   e.When,
+			PipelineName:           e.PipelineName,
+			ApplicationName:        e.ApplicationName,
+			EnvironmentName:        e.EnvironmentName,
+			ProjectIntegrationName: e.ProjectIntegrationName,
+			OneAtATime:             e.OneAtATime,
+			Lock:                   e.Lock,
+			Payload:                e.Payload,
+			Parameters:             e.Parameters,
+			OutgoingHookModelName:  e.OutgoingHookModelName,
+			OutgoingHookConfig:     e.OutgoingHookConfig,
+			Permissions:            e.Permissions,
+			Approval:               e.Approval,
+		}
+	}
+
+	// hooks and notifications of a workflow with only one pipeline are attached to its node
+	if len(w.Workflow) == 0 {
+		if len(w.PipelineHooks) > 0 {
+			w2.Hooks = map[string][]HookEntry{w.PipelineName: w.PipelineHooks}
+		}
+		if len(w.Notifications) > 0 {
+			w2.Notifications = map[string][]NotificationEntry{w.PipelineName: w.Notifications}
+		}
+	}
+
+	return w2
+}
+
+// V1 converts the workflow to the v1.0 syntax, inline pipelines and environments are not part of the v1.0 workflow.
+func (w WorkflowV2) V1() Workflow {
+	w1 := Workflow{
+		Name:             w.Name,
+		Description:      w.Description,
+		Version:          WorkflowVersion1,
+		Template:         w.Template,
+		Hooks:            w.Hooks,
+		Permissions:      w.Permissions,
+		Metadata:         w.Metadata,
+		PurgeTags:        w.PurgeTags,
+		HistoryLength:    w.HistoryLength,
+		Retention:        w.Retention,
+		Concurrency:      w.Concurrency,
+		MapNotifications: w.Notifications,
+	}
+
+	w1.Workflow = make(map[string]NodeEntry, len(w.Workflow))
+	for name, e := range w.Workflow {
+		w1.Workflow[name] = NodeEntry{
+			DependsOn:              e.Needs,
+			Conditions:             e.Conditions,
+			When:                   e.When,
+			PipelineName:           e.PipelineName,
+			ApplicationName:        e.ApplicationName,
+			EnvironmentName:        e.EnvironmentName,
+			ProjectIntegrationName: e.ProjectIntegrationName,
+			OneAtATime:             e.OneAtATime,
+			Lock:                   e.Lock,
+			Payload:                e.Payload,
+			Parameters:             e.Parameters,
+			OutgoingHookModelName:  e.OutgoingHookModelName,
+			OutgoingHookConfig:     e.OutgoingHookConfig,
+			Permissions:            e.Permissions,
+			Approval:               e.Approval,
+		}
+	}
+
+	return w1
+}
+
+// CheckValidity checks the parts of the workflow that are specific to the v2.0 syntax.
+func (w WorkflowV2) CheckValidity() error {
+	mError := new(sdk.MultiError)
+
+	if w.Version != WorkflowVersion2 {
+		mError.Append(fmt.Errorf("Error: wrong usage: invalid version %s, should be %s", w.Version, WorkflowVersion2))
+	}
+	if len(w.Workflow) == 0 {
+		mError.Append(fmt.Errorf("Error: wrong usage: at least one node is required"))
+	}
+	for _, i := range w.Include {
+		if err := i.IsValid(); err != nil {
+			mError.Append(fmt.Errorf("Error: wrong usage: %v", sdk.Cause(err)))
+		}
+	}
+	for name, p := range w.Pipelines {
+		if p.Name != "" && p.Name != name {
+			mError.Append(fmt.Errorf("Error: wrong usage: inline pipeline %s is named %s", name, p.Name))
+		}
+	}
+	for name, e := range w.Environments {
+		if e.Name != "" && e.Name != name {
+			mError.Append(fmt.Errorf("Error: wrong usage: inline environment %s is named %s", name, e.Name))
+		}
+	}
+
+	if mError.IsEmpty() {
+		return nil
+	}
+	return mError
+}
+
+// GetWorkflow returns a fresh sdk.Workflow
+func (w WorkflowV2) GetWorkflow() (*sdk.Workflow, error) {
+	if len(w.Include) > 0 {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "includes of workflow %s are not resolved, they are only supported for workflows as code", w.Name)
+	}
+	if err := w.CheckValidity(); err != nil {
+		return nil, sdk.WrapError(err, "Unable to check validity")
+	}
+	return w.V1().GetWorkflow()
+}
+
+// CheckNoInlineEntities returns an error if the workflow declares inline pipelines or environments, they are only
+// imported with the files of a pushed workflow or of a workflow as code.
+func (w WorkflowV2) CheckNoInlineEntities() error {
+	if len(w.Pipelines) > 0 || len(w.Environments) > 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "inline pipelines and environments of workflow %s are only supported for pushed workflows and workflows as code", w.Name)
+	}
+	return nil
+}
+
+// InlinePipelines returns the pipelines declared in the workflow file.
+func (w WorkflowV2) InlinePipelines() []PipelineV1 {
+	pips := make([]PipelineV1, 0, len(w.Pipelines))
+	for name, p := range w.Pipelines {
+		p.Name = name
+		if p.Version == "" {
+			p.Version = PipelineVersion1
+		}
+		pips = append(pips, p)
+	}
+	return pips
+}
+
+// InlineEnvironments returns the environments declared in the workflow file.
+func (w WorkflowV2) InlineEnvironments() []Environment {
+	envs := make([]Environment, 0, len(w.Environments))
+	for name, e := range w.Environments {
+		e.Name = name
+		envs = append(envs, e)
+	}
+	return envs
+}
+
+// ResolveIncludes merges recursively the files included by the workflow, given files are indexed by include key.
+// Pipelines, environments, nodes, hooks and notifications of the including file take precedence over included ones,
+// an error is returned if an included file is missing or if an include cycle is detected.
+func (w *WorkflowV2) ResolveIncludes(files map[string][]byte) error {
+	return w.resolveIncludes(files, nil)
+}
+
+func (w *WorkflowV2) resolveIncludes(files map[string][]byte, keys []string) error {
+	includes := w.Include
+	w.Include = nil
+
+	for _, i := range includes {
+		if err := i.IsValid(); err != nil {
+			return err
+		}
+
+		key := i.Key()
+		for _, k := range keys {
+			if k == key {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "include cycle detected: %s -> %s", strings.Join(keys, " -> "), key)
+			}
+		}
+
+		btes, ok := files[key]
+		if !ok {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot find included file %s", key)
+		}
+		var included WorkflowV2
+		if err := yaml.Unmarshal(btes, &included); err != nil {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "cannot parse included file %s: %v", key, err)
+		}
+
+		// files included by a library file without repository are in the same library repository
+		if i.IsLibrary() {
+			for j := range included.Include {
+				if !included.Include[j].IsLibrary() {
+					included.Include[j].Repository = i.Repository
+					included.Include[j].Ref = i.Ref
+				}
+			}
+		}
+
+		if err := included.resolveIncludes(files, append(keys, key)); err != nil {
+			return err
+		}
+		w.merge(included)
+	}
+
+	return nil
+}
+
+func (w *WorkflowV2) merge(included WorkflowV2) {
+	for name, p := range included.Pipelines {
+		if _, ok := w.Pipelines[name]; !ok {
+			if w.Pipelines == nil {
+				w.Pipelines = make(map[string]PipelineV1)
+			}
+			w.Pipelines[name] = p
+		}
+	}
+	for name, e := range included.Environments {
+		if _, ok := w.Environments[name]; !ok {
+			if w.Environments == nil {
+				w.Environments = make(map[string]Environment)
+			}
+			w.Environments[name] = e
+		}
+	}
+	for name, n := range included.Workflow {
+		if _, ok := w.Workflow[name]; !ok {
+			if w.Workflow == nil {
+				w.Workflow = make(map[string]NodeEntryV2)
+			}
+			w.Workflow[name] = n
+		}
+	}
+	for name, hs := range included.Hooks {
+		if _, ok := w.Hooks[name]; !ok {
+			if w.Hooks == nil {
+				w.Hooks = make(map[string][]HookEntry)
+			}
+			w.Hooks[name] = hs
+		}
+	}
+	for names, ns := range included.Notifications {
+		if _, ok := w.Notifications[names]; !ok {
+			if w.Notifications == nil {
+				w.Notifications = make(map[string][]NotificationEntry)
+			}
+			w.Notifications[names] = ns
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package exportentities_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func TestWorkflowV2FromV1(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{
+			name: "simple pipeline triggered by a webhook",
+			yaml: `name: test4
+version: v1.0
+pipeline: DDOS-me
+application: test1
+pipeline_hooks:
+- type: WebHook
+  ref: "1541182443"
+  config:
+    method: POST
+metadata:
+  default_tags: git.branch,git.author
+`,
+		},
+		{
+			name: "workflow with a fork, a join and hooks",
+			yaml: `name: test3
+version: v1.0
+workflow:
+  1_start:
+    pipeline: test
+  2_after:
+    depends_on:
+    - 1_start
+    when:
+    - success
+    pipeline: test
+    lock: staging-db
+  3_fork:
+    depends_on:
+    - 2_after
+  4_end:
+    depends_on:
+    - 3_fork
+    when:
+    - success
+    pipeline: test
+    environment: prod
+  5_join:
+    depends_on:
+    - 1_start
+    - 4_end
+    when:
+    - manual
+  6_after_join:
+    depends_on:
+    - 5_join
+    when:
+    - success
+    pipeline: test
+hooks:
+  1_start:
+  - type: Scheduler
+    ref: "1542119521"
+`,
+		},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			ew, err := exportentities.UnmarshalWorkflow([]byte(tst.yaml), exportentities.FormatYAML)
+			require.NoError(t, err)
+			assert.Equal(t, exportentities.WorkflowVersion2, ew.Version)
+
+			w, err := ew.GetWorkflow()
+			require.NoError(t, err)
+
+			// exporting the workflow parsed from the v2.0 syntax should give the v1.0 file back
+			exported, err := exportentities.NewWorkflow(context.TODO(), *w)
+			require.NoError(t, err)
+			b, err := yaml.Marshal(exported)
+			require.NoError(t, err)
+			assert.Equal(t, tst.yaml, string(b))
+		})
+	}
+}
+
+func TestWorkflowV2InlineAndAnchors(t *testing.T) {
+	ew, err := exportentities.UnmarshalWorkflow([]byte(`name: my-workflow
+version: v2.0
+.defaults: &defaults
+  application: my-app
+  when:
+  - success
+pipelines:
+  build:
+    jobs:
+    - job: Build
+      steps:
+      - script: make build
+environments:
+  prod:
+    values:
+      url:
+        value: https://my-app.net
+workflow:
+  build:
+    pipeline: build
+    application: my-app
+  deploy:
+    <<: *defaults
+    needs:
+    - build
+    pipeline: deploy
+    environment: prod
+`), exportentities.FormatYAML)
+	require.NoError(t, err)
+
+	pips := ew.InlinePipelines()
+	require.Len(t, pips, 1)
+	assert.Equal(t, "build", pips[0].Name)
+	envs := ew.InlineEnvironments()
+	require.Len(t, envs, 1)
+	assert.Equal(t, "prod", envs[0].Name)
+	assert.True(t, sdk.ErrorIs(ew.CheckNoInlineEntities(), sdk.ErrWrongRequest))
+
+	w, err := ew.GetWorkflow()
+	require.NoError(t, err)
+	deploy := w.WorkflowData.NodeByName("deploy")
+	require.NotNil(t, deploy)
+	assert.Equal(t, "my-app", deploy.Context.ApplicationName)
+	assert.Equal(t, "prod", deploy.Context.EnvironmentName)
+	assert.Equal(t, "build", w.WorkflowData.Node.Name)
+	require.Len(t, w.WorkflowData.Node.Triggers, 1)
+	assert.Equal(t, "deploy", w.WorkflowData.Node.Triggers[0].ChildNode.Name)
+}
+
+func TestWorkflowV2ResolveIncludes(t *testing.T) {
+	ew := exportentities.WorkflowV2{
+		Name:    "my-workflow",
+		Version: exportentities.WorkflowVersion2,
+		Include: []exportentities.WorkflowInclude{
+			{Path: "./ci/build.yml"},
+			{Path: "deploy.yml", Repository: "my-org/cds-library", Ref: "v1.0.0"},
+		},
+		Workflow: map[string]exportentities.NodeEntryV2{
+			"build": {PipelineName: "build"},
+		},
+		Pipelines: map[string]exportentities.PipelineV1{
+			"build": {Description: "from workflow"},
+		},
+	}
+
+	files := map[string][]byte{
+		"ci/build.yml": []byte(`pipelines:
+  build:
+    description: from include
+  test: {}
+`),
+		"my-org/cds-library@v1.0.0:deploy.yml": []byte(`include:
+- path: envs/prod.yml
+workflow:
+  deploy:
+    needs:
+    - build
+    pipeline: deploy
+    environment: prod
+pipelines:
+  deploy: {}
+`),
+		"my-org/cds-library@v1.0.0:envs/prod.yml": []byte(`environments:
+  prod: {}
+`),
+	}
+
+	require.NoError(t, ew.ResolveIncludes(files))
+	assert.Empty(t, ew.Include)
+	assert.Equal(t, "from workflow", ew.Pipelines["build"].Description)
+	assert.Contains(t, ew.Pipelines, "test")
+	assert.Contains(t, ew.Pipelines, "deploy")
+	assert.Contains(t, ew.Environments, "prod")
+	assert.Equal(t, []string{"build"}, ew.Workflow["deploy"].Needs)
+
+	_, err := ew.GetWorkflow()
+	require.NoError(t, err)
+
+	// missing file
+	ew.Include = []exportentities.WorkflowInclude{{Path: "unknown.yml"}}
+	err = ew.ResolveIncludes(files)
+	require.Error(t, err)
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	// cycle
+	files["a.yml"] = []byte("include:\n- path: b.yml\n")
+	files["b.yml"] = []byte("include:\n- path: a.yml\n")
+	ew.Include = []exportentities.WorkflowInclude{{Path: "a.yml"}}
+	err = ew.ResolveIncludes(files)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle detected: a.yml -> b.yml -> a.yml")
+
+	// library include without ref
+	ew.Include = []exportentities.WorkflowInclude{{Path: "deploy.yml", Repository: "my-org/cds-library"}}
+	require.Error(t, ew.ResolveIncludes(files))
+}
@@ -65,7 +65,7 @@ func Create(ctx context.Context, h Interface) error {
 		return fmt.Errorf("Create> Init error: %v", err)
 	}
 
-	var chanRegister, chanGetModels <-chan time.Time
+	var chanRegister, chanGetModels, chanWorkerPool <-chan time.Time
 	var modelType string
 
 	hWithModels, isWithModels := h.(InterfaceWithModels)
@@ -83,6 +83,17 @@ func Create(ctx context.Context, h Interface) error {
 		chanGetModels = time.Tick(10 * time.Second)                                                          // nolint
 
 		modelType = hWithModels.ModelType()
+
+		if h.Configuration().Provision.WorkerPool.Enabled && !isPoolModelType(modelType) {
+			log.Warning(ctx, "Create> worker pool is not available for worker models of type %s, it will not be used", modelType)
+		}
+		if isPoolEnabled(h) {
+			frequency := h.Configuration().Provision.WorkerPool.Frequency
+			if frequency < 1 {
+				frequency = 30
+			}
+			chanWorkerPool = time.Tick(time.Duration(frequency) * time.Second) // nolint
+		}
 	}
 
 	wjobs := make(chan sdk.WorkflowNodeJobRun, h.Configuration().Provision.MaxConcurrentProvisioning)
@@ -215,6 +226,7 @@ func Create(ctx context.Context, h Interface) error {
 				hostname:          hostname,
 				timestamp:         time.Now().Unix(),
 				workflowNodeRunID: j.WorkflowNodeRunID,
+				queued:            j.Queued,
 			}
 
 			// Check at least one worker model can match
@@ -249,6 +261,16 @@ func Create(ctx context.Context, h Interface) error {
 			}
 
 			if chosenModel != nil {
+				// An idle worker from the pool will take the job
+				if isPoolEnabled(h) && canPoolRunJob(workerRequest) && workerPoolState.dispatch(j.ID, chosenModel, time.Now(), poolArrivalRateWindow(h)) {
+					log.Debug("hatchery> job %d dispatched to worker pool of model %s", j.ID, chosenModel.Name)
+					if !j.Queued.IsZero() {
+						stats.Record(currentCtx, GetMetrics().JobWaitTime.M(time.Since(j.Queued).Milliseconds()))
+					}
+					endTrace("worker pool")
+					continue
+				}
+
 				//We got a model, let's start a worker
 				workerRequest.model = chosenModel
 			}
@@ -261,6 +283,11 @@ func Create(ctx context.Context, h Interface) error {
 			if err := workerRegister(ctx, hWithModels, workersStartChan); err != nil {
 				log.Warning(ctx, "Error on workerRegister: %s", err)
 			}
+
+		case <-chanWorkerPool:
+			if err := provisionWorkerPool(ctx, hWithModels, workersStartChan); err != nil {
+				log.Warning(ctx, "Error on provisionWorkerPool: %s", err)
+			}
 		}
 	}
 }
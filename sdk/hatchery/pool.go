@@ -3,7 +3,11 @@ package hatchery
 import (
 	"context"
 	"fmt"
+	"math"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats"
@@ -11,6 +15,7 @@ import (
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
+	"github.com/ovh/cds/sdk/namesgenerator"
 )
 
 // WorkerPool returns all the worker owned by the hatchery h, registered or not on the CDS API
@@ -123,3 +128,345 @@ func WorkerPool(ctx context.Context, h Interface, status ...string) ([]sdk.Worke
 
 	return res, nil
 }
+
+const (
+	// defaultPoolWarmup is the expected duration of a worker spawn while no spawn was observed for a model
+	defaultPoolWarmup = 3 * time.Minute
+	poolWorkerPrefix  = "pool-"
+)
+
+// workerPoolTracker keeps the state of the worker pool mode: job arrivals used to compute
+// the arrival rate, observed spawn durations and idle workers available for each model.
+type workerPoolTracker struct {
+	mutex          sync.Mutex
+	jobs           map[int64]time.Time
+	arrivals       map[string][]time.Time
+	spawnDurations map[string]time.Duration
+	idle           map[string][]string
+	idleSince      map[string]time.Time
+	hits           int64
+	misses         int64
+}
+
+var workerPoolState = newWorkerPoolTracker()
+
+func newWorkerPoolTracker() *workerPoolTracker {
+	return &workerPoolTracker{
+		jobs:           map[int64]time.Time{},
+		arrivals:       map[string][]time.Time{},
+		spawnDurations: map[string]time.Duration{},
+		idle:           map[string][]string{},
+		idleSince:      map[string]time.Time{},
+	}
+}
+
+func modelPath(m *sdk.Model) string {
+	return m.Group.Name + "/" + m.Name
+}
+
+// poolWorkerNamePrefix returns the prefix of the names of pool workers spawned by the hatchery for given model
+func poolWorkerNamePrefix(hatcheryName string, m *sdk.Model) string {
+	return fmt.Sprintf("%s%s-%s-", poolWorkerPrefix, hatcheryName, strings.Replace(strings.ToLower(modelPath(m)), "/", "-", -1))
+}
+
+// dispatch is called when a job that can be run with given model is received. It records the
+// job arrival and returns true if an idle worker from the pool is reserved to take the job.
+// A job already received is never dispatched again to the pool, so it will be spawned normally
+// if the reserved worker did not take it.
+func (t *workerPoolTracker) dispatch(jobID int64, m *sdk.Model, now time.Time, window time.Duration) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if _, has := t.jobs[jobID]; has {
+		return false
+	}
+	t.jobs[jobID] = now
+	path := modelPath(m)
+	t.arrivals[path] = append(t.arrivals[path], now)
+	t.prune(now, window)
+
+	if len(t.idle[path]) == 0 {
+		t.misses++
+		return false
+	}
+	name := t.idle[path][0]
+	t.idle[path] = t.idle[path][1:]
+	delete(t.idleSince, name)
+	t.hits++
+	return true
+}
+
+// prune removes arrivals and jobs older than the window.
+func (t *workerPoolTracker) prune(now time.Time, window time.Duration) {
+	for id, d := range t.jobs {
+		if now.Sub(d) > window {
+			delete(t.jobs, id)
+		}
+	}
+	for path, as := range t.arrivals {
+		i := 0
+		for i < len(as) && now.Sub(as[i]) > window {
+			i++
+		}
+		if i == len(as) {
+			delete(t.arrivals, path)
+			continue
+		}
+		t.arrivals[path] = as[i:]
+	}
+}
+
+// arrivalRate returns the number of jobs received per second for given model during the window.
+func (t *workerPoolTracker) arrivalRate(m *sdk.Model, now time.Time, window time.Duration) float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.prune(now, window)
+	if window <= 0 {
+		return 0
+	}
+	return float64(len(t.arrivals[modelPath(m)])) / window.Seconds()
+}
+
+// recordSpawnDuration keeps a moving average of the spawn durations for given model.
+func (t *workerPoolTracker) recordSpawnDuration(m *sdk.Model, d time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	path := modelPath(m)
+	if current, has := t.spawnDurations[path]; has {
+		d = (current*3 + d) / 4
+	}
+	t.spawnDurations[path] = d
+}
+
+// warmup returns the expected duration to spawn a worker for given model.
+func (t *workerPoolTracker) warmup(m *sdk.Model) time.Duration {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if d, has := t.spawnDurations[modelPath(m)]; has {
+		return d
+	}
+	return defaultPoolWarmup
+}
+
+// setIdle refreshes the idle workers of given model, and returns the ones idle since more than the ttl.
+func (t *workerPoolTracker) setIdle(m *sdk.Model, names []string, now time.Time, ttl time.Duration) []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	path := modelPath(m)
+	for _, name := range t.idle[path] {
+		if !sdk.IsInArray(name, names) {
+			delete(t.idleSince, name)
+		}
+	}
+	var expired []string
+	for _, name := range names {
+		since, has := t.idleSince[name]
+		if !has {
+			t.idleSince[name] = now
+			continue
+		}
+		if ttl > 0 && now.Sub(since) > ttl {
+			expired = append(expired, name)
+		}
+	}
+	t.idle[path] = names
+	return expired
+}
+
+// drop removes a worker from the idle ones.
+func (t *workerPoolTracker) drop(m *sdk.Model, name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	path := modelPath(m)
+	for i := range t.idle[path] {
+		if t.idle[path][i] == name {
+			t.idle[path] = append(t.idle[path][:i], t.idle[path][i+1:]...)
+			break
+		}
+	}
+	delete(t.idleSince, name)
+}
+
+// hitRatio returns the ratio of jobs dispatched to an idle worker.
+func (t *workerPoolTracker) hitRatio() float64 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.hits+t.misses == 0 {
+		return 0
+	}
+	return float64(t.hits) / float64(t.hits+t.misses)
+}
+
+// poolTargetSize returns the number of workers that should be kept in pool for a model: the jobs
+// expected during a worker spawn according to the arrival rate, plus the jobs already queued.
+func poolTargetSize(minIdle, maxIdle, queued int, rate float64, warmup time.Duration) int {
+	target := int(math.Ceil(rate*warmup.Seconds())) + queued
+	if target < minIdle {
+		target = minIdle
+	}
+	if maxIdle >= 0 && target > maxIdle {
+		target = maxIdle
+	}
+	return target
+}
+
+// isPoolModelType returns true if idle workers can be kept for the worker models of given type. Only virtual
+// machines are slow enough to start to need a pool.
+func isPoolModelType(modelType string) bool {
+	return modelType == sdk.Openstack || modelType == sdk.VSphere
+}
+
+// isPoolEnabled returns true if the worker pool mode is enabled for the hatchery.
+func isPoolEnabled(h Interface) bool {
+	hWithModels, isWithModels := h.(InterfaceWithModels)
+	return isWithModels && h.Configuration().Provision.WorkerPool.Enabled && isPoolModelType(hWithModels.ModelType())
+}
+
+func poolArrivalRateWindow(h Interface) time.Duration {
+	w := h.Configuration().Provision.WorkerPool.ArrivalRateWindow
+	if w <= 0 {
+		w = 1800
+	}
+	return time.Duration(w) * time.Second
+}
+
+// canPoolRunJob returns false if the job needs something that can't be provided by an unbooked worker.
+func canPoolRunJob(j workerStarterRequest) bool {
+	for _, r := range j.requirements {
+		if r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement || r.Type == sdk.HostnameRequirement {
+			return false
+		}
+	}
+	return true
+}
+
+// provisionWorkerPool is called by a ticker. For each worker model, the hatchery computes the
+// expected pool size from the queue length and the arrival rate, spawns the missing idle workers
+// and drains the idle workers above the expected size after the idle ttl.
+func provisionWorkerPool(ctx context.Context, h InterfaceWithModels, startWorkerChan chan<- workerStarterRequest) error {
+	cfg := h.Configuration().Provision.WorkerPool
+	now := time.Now()
+	window := poolArrivalRateWindow(h)
+
+	workers, err := WorkerPool(ctx, h, sdk.StatusWaiting, sdk.StatusWorkerPending, sdk.StatusWorkerRegistering)
+	if err != nil {
+		return sdk.WrapError(err, "unable to get worker pool")
+	}
+
+	queue, err := h.CDSClient().QueueWorkflowNodeJobRun(sdk.StatusWaiting)
+	if err != nil {
+		return sdk.WrapError(err, "unable to get queue")
+	}
+
+	hostname, _ := os.Hostname()
+	var poolSize int
+	for k := range models {
+		m := &models[k]
+		if m.Type != h.ModelType() || m.IsDeprecated || m.NbSpawnErr > 5 || h.NeedRegistration(ctx, m) {
+			continue
+		}
+
+		prefix := poolWorkerNamePrefix(h.Service().Name, m)
+		var idle []string
+		var starting int
+		for _, w := range workers {
+			if !strings.HasPrefix(w.Name, prefix) {
+				continue
+			}
+			if w.Status == sdk.StatusWaiting {
+				idle = append(idle, w.Name)
+			} else {
+				starting++
+			}
+		}
+
+		var queued int
+		for _, j := range queue {
+			if j.BookedBy.ID != 0 {
+				continue
+			}
+			req := workerStarterRequest{
+				id:           j.ID,
+				execGroups:   j.ExecGroups,
+				requirements: j.Job.Action.Requirements,
+				hostname:     hostname,
+				timestamp:    now.Unix(),
+			}
+			if canPoolRunJob(req) && canRunJobWithModel(ctx, h, req, m) {
+				queued++
+			}
+		}
+
+		target := poolTargetSize(cfg.MinIdle, cfg.MaxIdle, queued, workerPoolState.arrivalRate(m, now, window), workerPoolState.warmup(m))
+		expired := workerPoolState.setIdle(m, idle, now, time.Duration(cfg.IdleTTL)*time.Second)
+		current := len(idle) + starting
+		log.Debug("hatchery> provisionWorkerPool> model %s: %d idle, %d starting, %d queued, target %d", m.Name, len(idle), starting, queued, target)
+
+		// Drain expired idle workers while the pool is bigger than expected
+		for _, name := range expired {
+			if current <= target {
+				break
+			}
+			for _, w := range workers {
+				if w.Name != name || w.ID == "" {
+					continue
+				}
+				log.Info(ctx, "hatchery> provisionWorkerPool> draining idle worker %s", name)
+				if err := h.CDSClient().WorkerDisable(ctx, w.ID); err != nil {
+					log.Error(ctx, "hatchery> provisionWorkerPool> unable to disable worker %s: %v", name, err)
+					break
+				}
+				workerPoolState.drop(m, name)
+				current--
+				break
+			}
+		}
+
+		for ; current < target; current++ {
+			if !checkCapacities(ctx, h) {
+				log.Debug("hatchery> provisionWorkerPool> unable to provision pool now")
+				return nil
+			}
+			startWorkerChan <- workerStarterRequest{poolWorkerModel: m}
+		}
+		poolSize += current
+	}
+
+	stats.Record(ctx,
+		GetMetrics().PoolWorkers.M(int64(poolSize)),
+		GetMetrics().PoolHitRatio.M(workerPoolState.hitRatio()),
+	)
+	return nil
+}
+
+// spawnPoolWorker starts an unbooked worker for given model that will wait for jobs.
+func spawnPoolWorker(ctx context.Context, h Interface, m *sdk.Model) {
+	if atomic.LoadInt64(&nbWorkerToStart) > int64(h.Configuration().Provision.MaxConcurrentProvisioning) {
+		return
+	}
+	atomic.AddInt64(&nbWorkerToStart, 1)
+	defer atomic.AddInt64(&nbWorkerToStart, -1)
+
+	arg := SpawnArguments{
+		WorkerName:   poolWorkerNamePrefix(h.Service().Name, m) + strings.Replace(namesgenerator.GetRandomNameCDS(0), "_", "-", -1),
+		Model:        m,
+		HatcheryName: h.Service().Name,
+	}
+
+	// Get a JWT to authentified the worker
+	jwt, err := NewWorkerToken(h.Service().Name, h.GetPrivateKey(), time.Now().Add(1*time.Hour), arg)
+	if err != nil {
+		log.Error(ctx, "hatchery> spawnPoolWorker> cannot get token for model %s: %v", m.Name, err)
+		return
+	}
+	arg.WorkerToken = jwt
+
+	log.Info(ctx, "hatchery> spawnPoolWorker> starting pool worker %s", arg.WorkerName)
+	start := time.Now()
+	if err := h.SpawnWorker(ctx, arg); err != nil {
+		log.Warning(ctx, "hatchery> spawnPoolWorker> cannot spawn pool worker for model %s: %v", m.Name, err)
+		return
+	}
+	observability.Record(ctx, GetMetrics().SpawnedWorkers, 1)
+	workerPoolState.recordSpawnDuration(m, time.Since(start))
+}
@@ -0,0 +1,60 @@
+package hatchery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestPoolTargetSize(t *testing.T) {
+	// no activity, keep the minimum
+	assert.Equal(t, 1, poolTargetSize(1, 5, 0, 0, 3*time.Minute))
+	// one job per minute during a 3 minutes spawn
+	assert.Equal(t, 3, poolTargetSize(0, 5, 0, 1.0/60, 3*time.Minute))
+	// queued jobs are added
+	assert.Equal(t, 5, poolTargetSize(0, 10, 2, 1.0/60, 3*time.Minute))
+	// bounded by the maximum
+	assert.Equal(t, 5, poolTargetSize(0, 5, 10, 1, 3*time.Minute))
+}
+
+func TestIsPoolModelType(t *testing.T) {
+	assert.True(t, isPoolModelType(sdk.Openstack))
+	assert.True(t, isPoolModelType(sdk.VSphere))
+	assert.False(t, isPoolModelType(sdk.Docker))
+	assert.False(t, isPoolModelType(sdk.HostProcess))
+}
+
+func TestWorkerPoolTracker(t *testing.T) {
+	tracker := newWorkerPoolTracker()
+	m := &sdk.Model{Name: "my-model", Group: &sdk.Group{Name: "my-group"}}
+	now := time.Now()
+	window := 10 * time.Minute
+
+	// No idle worker, the job is a miss
+	assert.False(t, tracker.dispatch(1, m, now, window))
+	assert.Equal(t, float64(0), tracker.hitRatio())
+
+	expired := tracker.setIdle(m, []string{"pool-hatch-my-group-my-model-a"}, now, time.Minute)
+	assert.Empty(t, expired)
+
+	// One idle worker is reserved for the second job
+	assert.True(t, tracker.dispatch(2, m, now, window))
+	assert.Equal(t, 0.5, tracker.hitRatio())
+	// A job already received is not dispatched again
+	assert.False(t, tracker.dispatch(2, m, now, window))
+
+	assert.InDelta(t, 2/window.Seconds(), tracker.arrivalRate(m, now, window), 0.0001)
+	assert.Equal(t, float64(0), tracker.arrivalRate(m, now.Add(time.Hour), window))
+
+	// Idle workers are expired after the ttl
+	tracker.setIdle(m, []string{"pool-hatch-my-group-my-model-b"}, now, time.Minute)
+	expired = tracker.setIdle(m, []string{"pool-hatch-my-group-my-model-b"}, now.Add(2*time.Minute), time.Minute)
+	assert.Equal(t, []string{"pool-hatch-my-group-my-model-b"}, expired)
+
+	assert.Equal(t, defaultPoolWarmup, tracker.warmup(m))
+	tracker.recordSpawnDuration(m, time.Minute)
+	assert.Equal(t, time.Minute, tracker.warmup(m))
+}
@@ -26,7 +26,9 @@ type workerStarterRequest struct {
 	hostname            string
 	timestamp           int64
 	workflowNodeRunID   int64
+	queued              time.Time
 	registerWorkerModel *sdk.Model
+	poolWorkerModel     *sdk.Model
 }
 
 func PanicDump(h Interface) func(s string) (io.WriteCloser, error) {
@@ -58,6 +60,11 @@ func startWorkerStarters(ctx context.Context, h Interface) chan<- workerStarterR
 
 func workerStarter(ctx context.Context, h Interface, workerNum string, jobs <-chan workerStarterRequest) {
 	for j := range jobs {
+		// Start an idle worker for the pool
+		if m := j.poolWorkerModel; m != nil {
+			spawnPoolWorker(ctx, h, m)
+			continue
+		}
 		// Start a worker for a job
 		if m := j.registerWorkerModel; m == nil {
 			_ = spawnWorkerForJob(ctx, h, j)
@@ -204,6 +211,12 @@ func spawnWorkerForJob(ctx context.Context, h Interface, j workerStarterRequest)
 		next()
 		return false
 	}
+	if j.model != nil {
+		workerPoolState.recordSpawnDuration(j.model, time.Since(start))
+	}
+	if !j.queued.IsZero() {
+		observability.Record(ctxJob, GetMetrics().JobWaitTime, time.Since(j.queued).Milliseconds())
+	}
 
 	ctxSendSpawnInfo, next = observability.Span(ctxJob, "hatchery.SendSpawnInfo", observability.Tag("msg", sdk.MsgSpawnInfoHatcheryStartsSuccessfully.ID))
 	SendSpawnInfo(ctxSendSpawnInfo, h, j.id, sdk.SpawnMsg{
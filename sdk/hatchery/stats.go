@@ -33,6 +33,9 @@ func initMetrics() error {
 		metrics.CheckingWorkers = stats.Int64("cds/checking_workers", "number of checking workers", stats.UnitDimensionless)
 		metrics.BuildingWorkers = stats.Int64("cds/building_workers", "number of building workers", stats.UnitDimensionless)
 		metrics.DisabledWorkers = stats.Int64("cds/disabled_workers", "number of disabled workers", stats.UnitDimensionless)
+		metrics.PoolWorkers = stats.Int64("cds/pool_workers", "number of idle workers in pool", stats.UnitDimensionless)
+		metrics.PoolHitRatio = stats.Float64("cds/pool_hit_ratio", "ratio of jobs taken by an idle worker from pool", stats.UnitDimensionless)
+		metrics.JobWaitTime = stats.Int64("cds/job_wait_time", "time between job queued and worker available", stats.UnitMilliseconds)
 
 		tags := []tag.Key{observability.MustNewKey(observability.TagServiceType), observability.MustNewKey(observability.TagServiceName)}
 		err = observability.RegisterView(
@@ -45,6 +48,9 @@ func initMetrics() error {
 			observability.NewViewLast("cds/hatchery/checking_workers", metrics.CheckingWorkers, tags),
 			observability.NewViewLast("cds/hatchery/building_workers", metrics.BuildingWorkers, tags),
 			observability.NewViewLast("cds/hatchery/disabled_workers", metrics.DisabledWorkers, tags),
+			observability.NewViewLast("cds/hatchery/pool_workers", metrics.PoolWorkers, tags),
+			observability.NewViewLastFloat64("cds/hatchery/pool_hit_ratio", metrics.PoolHitRatio, tags),
+			observability.NewViewLast("cds/hatchery/job_wait_time", metrics.JobWaitTime, tags),
 		)
 	})
 	return err
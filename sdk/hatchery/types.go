@@ -123,4 +123,7 @@ type Metrics struct {
 	WaitingWorkers     *stats.Int64Measure
 	BuildingWorkers    *stats.Int64Measure
 	DisabledWorkers    *stats.Int64Measure
+	PoolWorkers        *stats.Int64Measure
+	PoolHitRatio       *stats.Float64Measure
+	JobWaitTime        *stats.Int64Measure
 }
@@ -1,5 +1,11 @@
 package sdk
 
+import (
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
 // These are constants about hooks
 const (
 	WebHookModelName              = "WebHook"
@@ -9,6 +15,8 @@ const (
 	GitPollerModelName            = "Git Repository Poller"
 	KafkaHookModelName            = "Kafka hook"
 	RabbitMQHookModelName         = "RabbitMQ hook"
+	NATSHookModelName             = "NATS hook"
+	AMQPHookModelName             = "AMQP hook"
 	WorkflowModelName             = "Workflow"
 	HookConfigProject             = "project"
 	HookConfigWorkflow            = "workflow"
@@ -24,9 +32,18 @@ const (
 	HookConfigModelName           = "model_name"
 	HookConfigIcon                = "hookIcon"
 	WebHookModelConfigMethod      = "method"
+	WebHookModelSignatureHeader   = "signature_header"
+	WebHookModelSignatureAlgo     = "signature_algorithm"
+	WebHookModelSignatureKey      = "signature_key"
+	WebHookModelPayloadMapping    = "payload_mapping"
+	WebHookModelFilter            = "filter"
 	RepositoryWebHookModelMethod  = "method"
 	SchedulerModelCron            = "cron"
 	SchedulerModelTimezone        = "timezone"
+	SchedulerModelCalendar        = "calendar"
+	SchedulerModelJitter          = "jitter"
+	SchedulerModelSkipIfRunning   = "skip_if_running"
+	SchedulerModelCatchUp         = "catch_up"
 	Payload                       = "payload"
 	HookModelIntegration          = "integration"
 	KafkaHookModelConsumerGroup   = "consumer group"
@@ -36,6 +53,16 @@ const (
 	RabbitMQHookModelExchangeType = "exchange_type"
 	RabbitMQHookModelExchangeName = "exchange_name"
 	RabbitMQHookModelConsumerTag  = "consumer_tag"
+	NATSHookModelSubject          = "subject"
+	NATSHookModelQueueGroup       = "queue_group"
+	NATSHookModelDurable          = "durable"
+	AMQPHookModelAddress          = "address"
+)
+
+// Default signature settings of a webhook, the signature is only checked if a hmac key is set
+const (
+	WebHookDefaultSignatureHeader = "X-Hub-Signature-256"
+	WebHookDefaultSignatureAlgo   = "sha256"
 )
 
 // Here are the default hooks
@@ -47,6 +74,8 @@ var (
 		&SchedulerModel,
 		&KafkaHookModel,
 		&RabbitMQHookModel,
+		&NATSHookModel,
+		&AMQPHookModel,
 		&WorkflowModel,
 		&GerritHookModel,
 	}
@@ -116,6 +145,56 @@ var (
 		},
 	}
 
+	NATSHookModel = WorkflowHookModel{
+		Author:     "CDS",
+		Type:       WorkflowHookModelBuiltin,
+		Identifier: "github.com/ovh/cds/hook/builtin/nats",
+		Name:       NATSHookModelName,
+		Icon:       "Linkify",
+		DefaultConfig: WorkflowNodeHookConfig{
+			HookModelIntegration: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeIntegration,
+			},
+			NATSHookModelSubject: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			NATSHookModelQueueGroup: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			NATSHookModelDurable: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+		},
+	}
+
+	AMQPHookModel = WorkflowHookModel{
+		Author:     "CDS",
+		Type:       WorkflowHookModelBuiltin,
+		Identifier: "github.com/ovh/cds/hook/builtin/amqp",
+		Name:       AMQPHookModelName,
+		Icon:       "Linkify",
+		DefaultConfig: WorkflowNodeHookConfig{
+			HookModelIntegration: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeIntegration,
+			},
+			AMQPHookModelAddress: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+		},
+	}
+
 	WebHookModel = WorkflowHookModel{
 		Author:     "CDS",
 		Type:       WorkflowHookModelBuiltin,
@@ -128,6 +207,31 @@ var (
 				Configurable: true,
 				Type:         HookConfigTypeString,
 			},
+			WebHookModelSignatureHeader: {
+				Value:        WebHookDefaultSignatureHeader,
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			WebHookModelSignatureAlgo: {
+				Value:        WebHookDefaultSignatureAlgo,
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			WebHookModelSignatureKey: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			WebHookModelPayloadMapping: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			WebHookModelFilter: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
 		},
 	}
 
@@ -178,6 +282,26 @@ var (
 				Configurable: true,
 				Type:         HookConfigTypeString,
 			},
+			SchedulerModelCalendar: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			SchedulerModelJitter: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			SchedulerModelSkipIfRunning: {
+				Value:        "false",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			SchedulerModelCatchUp: {
+				Value:        "false",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
 			Payload: {
 				Value:        "{}",
 				Configurable: true,
@@ -278,3 +402,57 @@ func GetDefaultHookModel(modelName string) WorkflowHookModel {
 
 	return WebHookModel
 }
+
+// HookModelIntegrationModel returns the name of the integration model needed by a hook on a message broker.
+func HookModelIntegrationModel(hookModelName string) string {
+	switch hookModelName {
+	case KafkaHookModelName:
+		return KafkaIntegrationModel
+	case RabbitMQHookModelName:
+		return RabbitMQIntegrationModel
+	case NATSHookModelName:
+		return NATSIntegrationModel
+	case AMQPHookModelName:
+		return AMQPIntegrationModel
+	}
+	return ""
+}
+
+// SchedulerJitter returns the max random delay added to the executions of a scheduler.
+func (cfg WorkflowNodeHookConfig) SchedulerJitter() (time.Duration, error) {
+	value := cfg[SchedulerModelJitter].Value
+	if value == "" {
+		return 0, nil
+	}
+	jitter, err := time.ParseDuration(value)
+	if err != nil || jitter < 0 {
+		return 0, NewErrorFrom(ErrWrongRequest, "invalid jitter %q for scheduler, ex: 5m", value)
+	}
+	return jitter, nil
+}
+
+// IsValidScheduler returns an error if the cron expression, the timezone or the jitter of a scheduler is not valid.
+// The jitter should be lower than the period of the cron expression, else executions would be delayed after the next one.
+func (cfg WorkflowNodeHookConfig) IsValidScheduler() error {
+	loc, err := time.LoadLocation(cfg[SchedulerModelTimezone].Value)
+	if err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid timezone %q for scheduler", cfg[SchedulerModelTimezone].Value)
+	}
+	expr, err := cronexpr.Parse(cfg[SchedulerModelCron].Value)
+	if err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid cron expression %q for scheduler", cfg[SchedulerModelCron].Value)
+	}
+	jitter, err := cfg.SchedulerJitter()
+	if err != nil || jitter == 0 {
+		return err
+	}
+
+	// The period of a cron expression is not constant, ex: "0 9,18 * * *", so the shortest one of the next occurrences is used
+	occurrences := expr.NextN(time.Now().In(loc), 10)
+	for i := 1; i < len(occurrences); i++ {
+		if period := occurrences[i].Sub(occurrences[i-1]); jitter >= period {
+			return NewErrorFrom(ErrWrongRequest, "jitter %s of scheduler should be lower than its period %s", jitter, period)
+		}
+	}
+	return nil
+}
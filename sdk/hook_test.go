@@ -0,0 +1,45 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestWorkflowNodeHookConfigIsValidScheduler(t *testing.T) {
+	config := func(cron, jitter string) sdk.WorkflowNodeHookConfig {
+		c := sdk.SchedulerModel.DefaultConfig.Clone()
+		c[sdk.SchedulerModelCron] = sdk.WorkflowNodeHookConfigValue{Value: cron}
+		c[sdk.SchedulerModelJitter] = sdk.WorkflowNodeHookConfigValue{Value: jitter}
+		return c
+	}
+
+	assert.NoError(t, config("0 * * * *", "").IsValidScheduler())
+	assert.NoError(t, config("0 * * * *", "5m").IsValidScheduler())
+	assert.Error(t, config("every hour", "").IsValidScheduler())
+	assert.Error(t, config("0 * * * *", "five minutes").IsValidScheduler())
+	assert.Error(t, config("0 * * * *", "-5m").IsValidScheduler())
+	// The jitter should be lower than the shortest period of the cron expression
+	assert.Error(t, config("0 * * * *", "1h").IsValidScheduler())
+	assert.NoError(t, config("0 9,18 * * *", "8h").IsValidScheduler())
+	assert.Error(t, config("0 9,18 * * *", "10h").IsValidScheduler())
+
+	c := config("0 * * * *", "")
+	c[sdk.SchedulerModelTimezone] = sdk.WorkflowNodeHookConfigValue{Value: "Mars/Olympus"}
+	assert.Error(t, c.IsValidScheduler())
+
+	jitter, err := config("0 * * * *", "5m").SchedulerJitter()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, jitter)
+}
+
+func TestHookModelIntegrationModel(t *testing.T) {
+	assert.Equal(t, sdk.KafkaIntegrationModel, sdk.HookModelIntegrationModel(sdk.KafkaHookModelName))
+	assert.Equal(t, sdk.NATSIntegrationModel, sdk.HookModelIntegrationModel(sdk.NATSHookModelName))
+	assert.Equal(t, sdk.AMQPIntegrationModel, sdk.HookModelIntegrationModel(sdk.AMQPHookModelName))
+	assert.Equal(t, "", sdk.HookModelIntegrationModel(sdk.WebHookModelName))
+}
@@ -25,6 +25,8 @@ type TaskExecution struct {
 	WebHook             *WebHookExecution       `json:"webhook,omitempty" cli:"-"`
 	Kafka               *KafkaTaskExecution     `json:"kafka,omitempty" cli:"-"`
 	RabbitMQ            *RabbitMQTaskExecution  `json:"rabbitmq,omitempty" cli:"-"`
+	NATS                *NATSTaskExecution      `json:"nats,omitempty" cli:"-"`
+	AMQP                *AMQPTaskExecution      `json:"amqp,omitempty" cli:"-"`
 	ScheduledTask       *ScheduledTaskExecution `json:"scheduled_task,omitempty" cli:"-"`
 	GerritEvent         *GerritEventExecution   `json:"gerrit,omitempty" cli:"-"`
 	Status              string                  `json:"status" cli:"status"`
@@ -41,6 +43,8 @@ type WebHookExecution struct {
 	RequestBody   []byte              `json:"request_body"`
 	RequestHeader map[string][]string `json:"request_header"`
 	RequestMethod string              `json:"request_method"`
+	// Reason why the request didn't trigger the workflow, ex: invalid signature or filtered event
+	RejectReason string `json:"reject_reason,omitempty"`
 }
 
 // KafkaTaskExecution contains specific data for a kafka hook
@@ -53,7 +57,25 @@ type RabbitMQTaskExecution struct {
 	Message []byte `json:"message"`
 }
 
+// NATSTaskExecution contains specific data for a nats hook
+type NATSTaskExecution struct {
+	Subject string `json:"subject"`
+	Message []byte `json:"message"`
+}
+
+// AMQPTaskExecution contains specific data for an amqp 1.0 hook
+type AMQPTaskExecution struct {
+	Address string `json:"address"`
+	Message []byte `json:"message"`
+}
+
 // ScheduledTaskExecution contains specific data for a scheduled task execution
 type ScheduledTaskExecution struct {
 	DateScheduledExecution string `json:"date_scheduled_execution"`
+	// Timestamp of the occurrence of the cron expression, the execution can be delayed by a jitter
+	Occurrence int64  `json:"occurrence,omitempty"`
+	SkipReason string `json:"skip_reason,omitempty"`
+	// Number of the missed occurrences caught up before this execution and timestamp of the next missed occurrence
+	CatchUp     int   `json:"catch_up,omitempty"`
+	NextCatchUp int64 `json:"next_catch_up,omitempty"`
 }
@@ -1,11 +1,16 @@
 package sdk
 
+import "strings"
+
 // This is the buitin integration model
 const (
 	KafkaIntegrationModel         = "Kafka"
 	RabbitMQIntegrationModel      = "RabbitMQ"
+	NATSIntegrationModel          = "NATS"
+	AMQPIntegrationModel          = "AMQP"
 	OpenstackIntegrationModel     = "Openstack"
 	AWSIntegrationModel           = "AWS"
+	VaultIntegrationModel         = "Vault"
 	DefaultStorageIntegrationName = "shared.infra"
 )
 
@@ -14,8 +19,11 @@ var (
 	BuiltinIntegrationModels = []*IntegrationModel{
 		&KafkaIntegration,
 		&RabbitMQIntegration,
+		&NATSIntegration,
+		&AMQPIntegration,
 		&OpenstackIntegration,
 		&AWSIntegration,
+		&VaultIntegration,
 	}
 	// KafkaIntegration represents a kafka integration
 	KafkaIntegration = IntegrationModel{
@@ -62,6 +70,58 @@ var (
 		Disabled: false,
 		Hook:     true,
 	}
+	// NATSIntegration represents a NATS integration, events are published with JetStream
+	NATSIntegration = IntegrationModel{
+		Name:       NATSIntegrationModel,
+		Author:     "CDS",
+		Identifier: "github.com/ovh/cds/integration/builtin/nats",
+		Icon:       "",
+		DefaultConfig: IntegrationConfig{
+			"url": IntegrationConfigValue{
+				Type:        IntegrationConfigTypeString,
+				Description: "Comma separated list of NATS servers, ex: nats://nats1.mycompany.com:4222,nats://nats2.mycompany.com:4222",
+			},
+			"username": IntegrationConfigValue{
+				Type: IntegrationConfigTypeString,
+			},
+			"password": IntegrationConfigValue{
+				Type: IntegrationConfigTypePassword,
+			},
+			"subject": IntegrationConfigValue{
+				Type:        IntegrationConfigTypeString,
+				Description: "This is mandatory only if you want to use Event Integration",
+			},
+		},
+		Disabled: false,
+		Hook:     true,
+		Event:    true,
+	}
+	// AMQPIntegration represents an AMQP 1.0 integration, ex: ActiveMQ Artemis, Qpid or Azure Service Bus
+	AMQPIntegration = IntegrationModel{
+		Name:       AMQPIntegrationModel,
+		Author:     "CDS",
+		Identifier: "github.com/ovh/cds/integration/builtin/amqp",
+		Icon:       "",
+		DefaultConfig: IntegrationConfig{
+			"url": IntegrationConfigValue{
+				Type:        IntegrationConfigTypeString,
+				Description: "URL of the AMQP 1.0 broker, ex: amqps://amqp.mycompany.com:5671",
+			},
+			"username": IntegrationConfigValue{
+				Type: IntegrationConfigTypeString,
+			},
+			"password": IntegrationConfigValue{
+				Type: IntegrationConfigTypePassword,
+			},
+			"address": IntegrationConfigValue{
+				Type:        IntegrationConfigTypeString,
+				Description: "Address of the queue or topic, this is mandatory only if you want to use Event Integration",
+			},
+		},
+		Disabled: false,
+		Hook:     true,
+		Event:    true,
+	}
 	// OpenstackIntegration represents an openstack integration
 	OpenstackIntegration = IntegrationModel{
 		Name:       OpenstackIntegrationModel,
@@ -134,17 +194,37 @@ var (
 		Disabled: false,
 		Hook:     false,
 	}
+	// VaultIntegration represents a hashicorp vault integration, used as secret backend
+	VaultIntegration = IntegrationModel{
+		Name:       VaultIntegrationModel,
+		Author:     "CDS",
+		Identifier: "github.com/ovh/cds/integration/builtin/vault",
+		Icon:       "",
+		DefaultConfig: IntegrationConfig{
+			"address": IntegrationConfigValue{
+				Type:        IntegrationConfigTypeString,
+				Description: "Vault address, ex: https://vault.mycompany.com:8200",
+			},
+			"token": IntegrationConfigValue{
+				Type: IntegrationConfigTypePassword,
+			},
+		},
+		SecretBackend: true,
+		Disabled:      false,
+		Hook:          false,
+	}
 )
 
 // IntegrationType represents all different type of integrations
 type IntegrationType string
 
 const (
-	IntegrationTypeEvent      = IntegrationType("event")
-	IntegrationTypeCompute    = IntegrationType("compute")
-	IntegrationTypeHook       = IntegrationType("hook")
-	IntegrationTypeStorage    = IntegrationType("storage")
-	IntegrationTypeDeployment = IntegrationType("deployment")
+	IntegrationTypeEvent         = IntegrationType("event")
+	IntegrationTypeCompute       = IntegrationType("compute")
+	IntegrationTypeHook          = IntegrationType("hook")
+	IntegrationTypeStorage       = IntegrationType("storage")
+	IntegrationTypeDeployment    = IntegrationType("deployment")
+	IntegrationTypeSecretBackend = IntegrationType("secret_backend")
 )
 
 // DefaultIfEmptyStorage return sdk.DefaultStorageIntegrationName if integrationName is empty
@@ -231,6 +311,7 @@ type IntegrationModel struct {
 	Deployment              bool                         `json:"deployment" db:"deployment" yaml:"deployment" cli:"deployment_supported"`
 	Compute                 bool                         `json:"compute" db:"compute" yaml:"compute" cli:"compute_supported"`
 	Event                   bool                         `json:"event" db:"event" yaml:"event" cli:"event_supported"`
+	SecretBackend           bool                         `json:"secret_backend" db:"secret_backend" yaml:"secret_backend" cli:"secret_backend_supported"`
 	Public                  bool                         `json:"public,omitempty" db:"public" yaml:"public,omitempty"`
 }
 
@@ -291,3 +372,22 @@ func (config *IntegrationConfig) HideSecrets() {
 		}
 	}
 }
+
+// VaultReferencePrefix is the prefix of a variable value that references a secret stored in Vault,
+// ex: vault://secret/data/myapp#db_password
+const VaultReferencePrefix = "vault://"
+
+// IsVaultReference returns true if the given value references a secret stored in Vault.
+func IsVaultReference(value string) bool {
+	return strings.HasPrefix(value, VaultReferencePrefix)
+}
+
+// ParseVaultReference returns the path and the field name of a Vault reference.
+func ParseVaultReference(value string) (string, string, error) {
+	ref := strings.TrimPrefix(value, VaultReferencePrefix)
+	i := strings.LastIndex(ref, "#")
+	if !IsVaultReference(value) || i <= 0 || i == len(ref)-1 {
+		return "", "", NewErrorFrom(ErrWrongRequest, "invalid vault reference %q, it should be formatted like vault://path#field", value)
+	}
+	return strings.Trim(ref[:i], "/"), ref[i+1:], nil
+}
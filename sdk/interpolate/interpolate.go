@@ -10,7 +10,7 @@ import (
 	"text/template"
 )
 
-var interpolateRegex = regexp.MustCompile("({{[\\.\"a-zA-Z0-9._\\-µ|\\s]+}})")
+var interpolateRegex = regexp.MustCompile("({{[\\.\"a-zA-Z0-9._\\-µ|\\s*/]+}})")
 
 type void struct{}
 type val map[string]interface{}
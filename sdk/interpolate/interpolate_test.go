@@ -190,6 +190,15 @@ func TestDo(t *testing.T) {
 			want:   `echo '{{"conf"|uvault}}'`,
 			enable: true,
 		},
+		{
+			name: "unknown function with glob",
+			args: args{
+				input: `go-{{.cds.app.value}}-{{ hashFiles "go.sum" "src/*.lock" }}`,
+				vars:  map[string]string{"cds.app.value": "value"},
+			},
+			want:   `go-value-{{ hashFiles "go.sum" "src/*.lock" }}`,
+			enable: true,
+		},
 		{
 			name: "simple",
 			args: args{
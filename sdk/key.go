@@ -2,8 +2,9 @@ package sdk
 
 // Those are types if key managed in CDS
 const (
-	KeyTypeSSH = "ssh"
-	KeyTypePGP = "pgp"
+	KeyTypeSSH  = "ssh"
+	KeyTypePGP  = "pgp"
+	KeyTypeHMAC = "hmac"
 )
 
 // Key represent a key of type SSH or GPG.
@@ -29,6 +29,7 @@ type Log struct {
 	Done         *time.Time `json:"done,omitempty" db:"done"`
 	StepOrder    int64      `json:"stepOrder,omitempty" db:"step_order"`
 	Val          string     `json:"val,omitempty" db:"value"`
+	Size         int64      `json:"size,omitempty" db:"-"` // size in bytes of the whole step log
 }
 
 type ServiceLog struct {
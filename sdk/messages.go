@@ -73,16 +73,28 @@ var (
 	MsgSpawnInfoWorkerForJob               = &Message{"MsgSpawnInfoWorkerForJob", trad{FR: "Ce worker %s a été créé pour lancer ce job", EN: "This worker %s was created to take this action"}, nil}
 	MsgSpawnInfoWorkerForJobError          = &Message{"MsgSpawnInfoWorkerForJobError", trad{FR: "⚠ Ce worker %s a été créé pour lancer ce job, mais ne possède pas tous les pré-requis. Vérifiez que les prérequis suivants:%s", EN: "⚠ This worker %s was created to take this action, but does not have all prerequisites. Please verify the following prerequisites:%s"}, nil}
 	MsgSpawnInfoJobError                   = &Message{"MsgSpawnInfoJobError", trad{FR: "⚠ Impossible de lancer ce job : %s", EN: "⚠ Unable to run this job: %s"}, nil}
+	MsgSpawnInfoJobTimeout                 = &Message{"MsgSpawnInfoJobTimeout", trad{FR: "⚠ Le job a dépassé son délai d'exécution : %s", EN: "⚠ Job timed out: %s"}, nil}
+	MsgSpawnInfoJobRetry                   = &Message{"MsgSpawnInfoJobRetry", trad{FR: "⚠ La tentative %d/%d du job a échoué, nouvelle tentative dans %s", EN: "⚠ Job attempt %d/%d failed, retrying in %s"}, nil}
 	MsgWorkflowStarting                    = &Message{"MsgWorkflowStarting", trad{FR: "Le workflow %s#%s a été démarré", EN: "Workflow %s#%s has been started"}, nil}
 	MsgWorkflowError                       = &Message{"MsgWorkflowError", trad{FR: "⚠ Une erreur est survenue: %v", EN: "⚠ An error has occurred: %v"}, nil}
 	MsgWorkflowConditionError              = &Message{"MsgWorkflowConditionError", trad{FR: "Les conditions de lancement ne sont pas respectées.", EN: "Run conditions aren't ok."}, nil}
 	MsgWorkflowNodeStop                    = &Message{"MsgWorkflowNodeStop", trad{FR: "Le pipeline a été arrété par %s", EN: "The pipeline has been stopped by %s"}, nil}
 	MsgWorkflowNodeMutex                   = &Message{"MsgWorkflowNodeMutex", trad{FR: "Le pipeline %s est mis en attente tant qu'il est en cours sur un autre run", EN: "The pipeline %s is waiting while it's running on another run"}, nil}
 	MsgWorkflowNodeMutexRelease            = &Message{"MsgWorkflowNodeMutexRelease", trad{FR: "Lancement du pipeline %s", EN: "Triggering pipeline %s"}, nil}
+	MsgWorkflowNodeWaitingApproval         = &Message{"MsgWorkflowNodeWaitingApproval", trad{FR: "Le noeud %s est en attente de %d approbation(s)", EN: "The node %s is waiting for %d approval(s)"}, nil}
+	MsgWorkflowNodeApproved                = &Message{"MsgWorkflowNodeApproved", trad{FR: "Le noeud %s a été approuvé par %s (%d/%d)", EN: "The node %s has been approved by %s (%d/%d)"}, nil}
+	MsgWorkflowNodeApprovalExpired         = &Message{"MsgWorkflowNodeApprovalExpired", trad{FR: "Le délai d'approbation du noeud %s a expiré", EN: "The approval delay of node %s has expired"}, nil}
+	MsgWorkflowNodeLockWaiting             = &Message{"MsgWorkflowNodeLockWaiting", trad{FR: "Le noeud %s attend le verrou %s détenu par le workflow %s #%d", EN: "The node %s is waiting for lock %s held by workflow %s #%d"}, nil}
+	MsgWorkflowNodeLockAcquired            = &Message{"MsgWorkflowNodeLockAcquired", trad{FR: "Le noeud %s a obtenu le verrou %s", EN: "The node %s has acquired lock %s"}, nil}
+	MsgWorkflowNodeLockReleased            = &Message{"MsgWorkflowNodeLockReleased", trad{FR: "Le verrou %s a été libéré de force par %s", EN: "Lock %s has been force released by %s"}, nil}
+	MsgWorkflowNodeLockExpired             = &Message{"MsgWorkflowNodeLockExpired", trad{FR: "Le verrou %s détenu par le noeud %s a expiré", EN: "Lock %s held by node %s has expired"}, nil}
 	MsgWorkflowImportedUpdated             = &Message{"MsgWorkflowImportedUpdated", trad{FR: "Le workflow %s a été mis à jour", EN: "Workflow %s has been updated"}, nil}
 	MsgWorkflowImportedInserted            = &Message{"MsgWorkflowImportedInserted", trad{FR: "Le workflow %s a été créé", EN: "Workflow %s has been created"}, nil}
 	MsgSpawnInfoHatcheryCannotStartJob     = &Message{"MsgSpawnInfoHatcheryCannotStart", trad{FR: "Aucune hatchery n'a pu démarrer de worker respectant vos pré-requis de job, merci de les vérifier.", EN: "No hatchery can spawn a worker corresponding your job's requirements. Please check your job's requirements."}, nil}
 	MsgWorkflowRunBranchDeleted            = &Message{"MsgWorkflowRunBranchDeleted", trad{FR: "La branche %s  a été supprimée", EN: "Branch %s has been deleted"}, nil}
+	MsgWorkflowRunConcurrencyCancelled     = &Message{"MsgWorkflowRunConcurrencyCancelled", trad{FR: "Exécution annulée par la politique de concurrence : remplacée par l'exécution %d pour la clé %s", EN: "Run cancelled by the concurrency policy: superseded by run %d for key %s"}, nil}
+	MsgWorkflowRunConcurrencyQueued        = &Message{"MsgWorkflowRunConcurrencyQueued", trad{FR: "Exécution mise en attente par la politique de concurrence : %d exécution(s) en cours pour la clé %s", EN: "Run queued by the concurrency policy: %d run(s) in progress for key %s"}, nil}
+	MsgWorkflowRunConcurrencySkipped       = &Message{"MsgWorkflowRunConcurrencySkipped", trad{FR: "Exécution ignorée par la politique de concurrence : %d exécution(s) en cours pour la clé %s", EN: "Run skipped by the concurrency policy: %d run(s) in progress for key %s"}, nil}
 	MsgWorkflowTemplateImportedInserted    = &Message{"MsgWorkflowTemplateImportedInserted", trad{FR: "Le template de workflow %s/%s a été créé", EN: "Workflow template %s/%s has been created"}, nil}
 	MsgWorkflowTemplateImportedUpdated     = &Message{"MsgWorkflowTemplateImportedUpdated", trad{FR: "Le template de workflow %s/%s a été mis à jour", EN: "Workflow template %s/%s has been updated"}, nil}
 	MsgWorkflowErrorBadPipelineName        = &Message{"MsgWorkflowErrorBadPipelineName", trad{FR: "Le pipeline %s indiqué dans votre fichier yaml de workflow n'existe pas", EN: "The pipeline %s mentioned in your workflow's yaml file doesn't exist"}, nil}
@@ -92,6 +104,8 @@ var (
 	MsgWorkflowErrorBadCdsDir              = &Message{"MsgWorkflowErrorBadCdsDir", trad{FR: "Un problème est survenu avec votre répertoire .cds", EN: "A problem occurred about your .cds directory"}, nil}
 	MsgWorkflowErrorUnknownKey             = &Message{"MsgWorkflowErrorUnknownKey", trad{FR: "La clé '%s' est incorrecte ou n'existe pas", EN: "The key '%s' is incorrect or doesn't exist"}, nil}
 	MsgWorkflowErrorBadVCSStrategy         = &Message{"MsgWorkflowErrorBadVCSStrategy", trad{FR: "Vos informations vcs_* sont incorrectes", EN: "Your vcs_* fields are incorrects"}, nil}
+	MsgWorkflowLintInvalidFile             = &Message{"MsgWorkflowLintInvalidFile", trad{FR: "Le fichier %s est invalide : %s", EN: "File %s is invalid: %s"}, nil}
+	MsgWorkflowLintLibraryInclude          = &Message{"MsgWorkflowLintLibraryInclude", trad{FR: "Le fichier %s inclus depuis le dépôt %s ne peut pas être vérifié localement", EN: "File %s included from repository %s can't be checked locally"}, nil}
 )
 
 // Messages contains all sdk Messages
@@ -149,16 +163,28 @@ var Messages = map[string]*Message{
 	MsgSpawnInfoWorkerForJob.ID:               MsgSpawnInfoWorkerForJob,
 	MsgSpawnInfoWorkerForJobError.ID:          MsgSpawnInfoWorkerForJobError,
 	MsgSpawnInfoJobError.ID:                   MsgSpawnInfoJobError,
+	MsgSpawnInfoJobTimeout.ID:                 MsgSpawnInfoJobTimeout,
+	MsgSpawnInfoJobRetry.ID:                   MsgSpawnInfoJobRetry,
 	MsgWorkflowStarting.ID:                    MsgWorkflowStarting,
 	MsgWorkflowError.ID:                       MsgWorkflowError,
 	MsgWorkflowConditionError.ID:              MsgWorkflowConditionError,
 	MsgWorkflowNodeStop.ID:                    MsgWorkflowNodeStop,
 	MsgWorkflowNodeMutex.ID:                   MsgWorkflowNodeMutex,
 	MsgWorkflowNodeMutexRelease.ID:            MsgWorkflowNodeMutexRelease,
+	MsgWorkflowNodeWaitingApproval.ID:         MsgWorkflowNodeWaitingApproval,
+	MsgWorkflowNodeApproved.ID:                MsgWorkflowNodeApproved,
+	MsgWorkflowNodeApprovalExpired.ID:         MsgWorkflowNodeApprovalExpired,
+	MsgWorkflowNodeLockWaiting.ID:             MsgWorkflowNodeLockWaiting,
+	MsgWorkflowNodeLockAcquired.ID:            MsgWorkflowNodeLockAcquired,
+	MsgWorkflowNodeLockReleased.ID:            MsgWorkflowNodeLockReleased,
+	MsgWorkflowNodeLockExpired.ID:             MsgWorkflowNodeLockExpired,
 	MsgWorkflowImportedUpdated.ID:             MsgWorkflowImportedUpdated,
 	MsgWorkflowImportedInserted.ID:            MsgWorkflowImportedInserted,
 	MsgSpawnInfoHatcheryCannotStartJob.ID:     MsgSpawnInfoHatcheryCannotStartJob,
 	MsgWorkflowRunBranchDeleted.ID:            MsgWorkflowRunBranchDeleted,
+	MsgWorkflowRunConcurrencyCancelled.ID:     MsgWorkflowRunConcurrencyCancelled,
+	MsgWorkflowRunConcurrencyQueued.ID:        MsgWorkflowRunConcurrencyQueued,
+	MsgWorkflowRunConcurrencySkipped.ID:       MsgWorkflowRunConcurrencySkipped,
 	MsgWorkflowTemplateImportedInserted.ID:    MsgWorkflowTemplateImportedInserted,
 	MsgWorkflowTemplateImportedUpdated.ID:     MsgWorkflowTemplateImportedUpdated,
 	MsgWorkflowErrorBadPipelineName.ID:        MsgWorkflowErrorBadPipelineName,
@@ -168,6 +194,8 @@ var Messages = map[string]*Message{
 	MsgWorkflowErrorBadCdsDir.ID:              MsgWorkflowErrorBadCdsDir,
 	MsgWorkflowErrorUnknownKey.ID:             MsgWorkflowErrorUnknownKey,
 	MsgWorkflowErrorBadVCSStrategy.ID:         MsgWorkflowErrorBadVCSStrategy,
+	MsgWorkflowLintInvalidFile.ID:             MsgWorkflowLintInvalidFile,
+	MsgWorkflowLintLibraryInclude.ID:          MsgWorkflowLintLibraryInclude,
 }
 
 //Message represent a struc format translated messages
@@ -26,3 +26,32 @@ type MetricRequest struct {
 	WorkflowID    int64  `json:"workflow_id"`
 	Key           string `json:"key"`
 }
+
+// Deployment is the run of a workflow node on an environment, used to compute delivery metrics.
+type Deployment struct {
+	ProjectKey      string      `json:"project_key"`
+	WorkflowName    string      `json:"workflow_name"`
+	EnvironmentName string      `json:"environment_name"`
+	NodeRunID       int64       `json:"node_run_id"`
+	Status          string      `json:"status"`
+	Start           time.Time   `json:"start"`
+	Done            time.Time   `json:"done"`
+	VCSTag          string      `json:"vcs_tag,omitempty"`
+	Commits         []VCSCommit `json:"commits,omitempty"`
+}
+
+// DeliveryMetrics are DORA-style metrics computed from the deployments of a project, a workflow or an environment
+// over a time window. Durations are in seconds, the deployment frequency is the number of successful deployments per day.
+type DeliveryMetrics struct {
+	ProjectKey          string    `json:"project_key" cli:"-"`
+	WorkflowName        string    `json:"workflow_name,omitempty" cli:"workflow"`
+	EnvironmentName     string    `json:"environment_name,omitempty" cli:"environment"`
+	From                time.Time `json:"from" cli:"from"`
+	To                  time.Time `json:"to" cli:"to"`
+	Deployments         int64     `json:"deployments" cli:"deployments"`
+	FailedDeployments   int64     `json:"failed_deployments" cli:"failed"`
+	DeploymentFrequency float64   `json:"deployment_frequency" cli:"frequency"`
+	LeadTime            int64     `json:"lead_time" cli:"lead_time"`
+	ChangeFailureRate   float64   `json:"change_failure_rate" cli:"failure_rate"`
+	MTTR                int64     `json:"mttr" cli:"mttr"`
+}
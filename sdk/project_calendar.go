@@ -0,0 +1,168 @@
+package sdk
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+)
+
+var projectCalendarNamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,}$`)
+
+// Date formats allowed for the bounds of a calendar blackout, a date without time covers the whole day.
+const (
+	CalendarDateFormat     = "2006-01-02"
+	CalendarDateTimeFormat = "2006-01-02T15:04"
+)
+
+// ProjectCalendar defines blackout windows during which the scheduled workflows of a project
+// that reference the calendar are not triggered, ex: public holidays or a freeze declared by ops.
+type ProjectCalendar struct {
+	ID           int64             `json:"id" db:"id" yaml:"-"`
+	ProjectID    int64             `json:"project_id" db:"project_id" yaml:"-"`
+	Name         string            `json:"name" db:"name" cli:"name,key" yaml:"name"`
+	Description  string            `json:"description" db:"description" cli:"description" yaml:"description,omitempty"`
+	Timezone     string            `json:"timezone" db:"timezone" cli:"timezone" yaml:"timezone,omitempty"`
+	Blackouts    CalendarBlackouts `json:"blackouts" db:"blackouts" cli:"-" yaml:"blackouts"`
+	LastModified time.Time         `json:"last_modified" db:"last_modified" cli:"last_modified" yaml:"-"`
+}
+
+// CalendarBlackout is a window of a calendar. It is either a fixed window between two dates,
+// or a recurring window that starts at each occurrence of a cron expression and lasts for a duration.
+type CalendarBlackout struct {
+	Name     string `json:"name,omitempty" yaml:"name,omitempty"`
+	From     string `json:"from,omitempty" yaml:"from,omitempty"`
+	To       string `json:"to,omitempty" yaml:"to,omitempty"`
+	Cron     string `json:"cron,omitempty" yaml:"cron,omitempty"`
+	Duration string `json:"duration,omitempty" yaml:"duration,omitempty"`
+}
+
+// CalendarBlackouts is a list of blackout windows.
+type CalendarBlackouts []CalendarBlackout
+
+// Value returns driver.Value from calendar blackouts.
+func (b CalendarBlackouts) Value() (driver.Value, error) {
+	j, err := json.Marshal(b)
+	return j, WrapError(err, "cannot marshal CalendarBlackouts")
+}
+
+// Scan calendar blackouts.
+func (b *CalendarBlackouts) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, b), "cannot unmarshal CalendarBlackouts")
+}
+
+// IsValid returns an error if the calendar is not valid.
+func (c ProjectCalendar) IsValid() error {
+	if !projectCalendarNamePattern.MatchString(c.Name) {
+		return NewErrorFrom(ErrWrongRequest, "invalid calendar name %q, should match %s", c.Name, projectCalendarNamePattern.String())
+	}
+	if _, err := c.location(); err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid timezone %q for calendar %s", c.Timezone, c.Name)
+	}
+	for i, b := range c.Blackouts {
+		if err := b.IsValid(); err != nil {
+			return NewErrorFrom(ErrWrongRequest, "invalid blackout %d for calendar %s: %s", i, c.Name, Cause(err).Error())
+		}
+	}
+	return nil
+}
+
+func (c ProjectCalendar) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(c.Timezone)
+}
+
+// Blackout returns the blackout of the calendar active at given time, or nil if there is none.
+func (c ProjectCalendar) Blackout(t time.Time) *CalendarBlackout {
+	loc, err := c.location()
+	if err != nil {
+		loc = time.UTC
+	}
+	for i := range c.Blackouts {
+		if c.Blackouts[i].isActive(t, loc) {
+			return &c.Blackouts[i]
+		}
+	}
+	return nil
+}
+
+// IsValid returns an error if the blackout is not valid.
+func (b CalendarBlackout) IsValid() error {
+	if b.Cron != "" {
+		if b.From != "" || b.To != "" {
+			return fmt.Errorf("from and to can't be set with cron")
+		}
+		if _, err := cronexpr.Parse(b.Cron); err != nil {
+			return fmt.Errorf("invalid cron expression %q", b.Cron)
+		}
+		if d, err := time.ParseDuration(b.Duration); err != nil || d <= 0 {
+			return fmt.Errorf("invalid duration %q", b.Duration)
+		}
+		return nil
+	}
+	from, _, err := parseCalendarDate(b.From, time.UTC)
+	if err != nil {
+		return err
+	}
+	to, _, err := parseCalendarDate(b.To, time.UTC)
+	if err != nil {
+		return err
+	}
+	if to.Before(from) {
+		return fmt.Errorf("to date should be after from date")
+	}
+	return nil
+}
+
+func (b CalendarBlackout) isActive(t time.Time, loc *time.Location) bool {
+	if b.Cron != "" {
+		expr, err := cronexpr.Parse(b.Cron)
+		if err != nil {
+			return false
+		}
+		d, err := time.ParseDuration(b.Duration)
+		if err != nil {
+			return false
+		}
+		// The window is active if an occurrence started during the last duration
+		start := expr.Next(t.In(loc).Add(-d))
+		return !start.IsZero() && !start.After(t)
+	}
+
+	from, _, err := parseCalendarDate(b.From, loc)
+	if err != nil {
+		return false
+	}
+	to, dateOnly, err := parseCalendarDate(b.To, loc)
+	if err != nil {
+		return false
+	}
+	if dateOnly {
+		to = to.AddDate(0, 0, 1)
+	}
+	return !t.Before(from) && t.Before(to)
+}
+
+// parseCalendarDate parses a date or a date time in given location, it returns true if the value is a date without time.
+func parseCalendarDate(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation(CalendarDateFormat, s, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.ParseInLocation(CalendarDateTimeFormat, s, loc)
+	if err != nil {
+		return t, false, fmt.Errorf("invalid date %q, expected format is %s or %s", s, CalendarDateFormat, CalendarDateTimeFormat)
+	}
+	return t, false, nil
+}
@@ -0,0 +1,67 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestProjectCalendarIsValid(t *testing.T) {
+	assert.NoError(t, sdk.ProjectCalendar{Name: "holidays", Blackouts: sdk.CalendarBlackouts{
+		{From: "2020-12-25", To: "2020-12-25"},
+		{From: "2020-12-20T18:00", To: "2021-01-04"},
+		{Cron: "0 0 * * 6", Duration: "48h"},
+	}}.IsValid())
+	assert.Error(t, sdk.ProjectCalendar{Name: "my calendar"}.IsValid())
+	assert.Error(t, sdk.ProjectCalendar{Name: "holidays", Timezone: "Mars/Olympus"}.IsValid())
+	assert.Error(t, sdk.ProjectCalendar{Name: "holidays", Blackouts: sdk.CalendarBlackouts{{From: "25/12/2020", To: "2020-12-25"}}}.IsValid())
+	assert.Error(t, sdk.ProjectCalendar{Name: "holidays", Blackouts: sdk.CalendarBlackouts{{From: "2020-12-26", To: "2020-12-25"}}}.IsValid())
+	assert.Error(t, sdk.ProjectCalendar{Name: "holidays", Blackouts: sdk.CalendarBlackouts{{Cron: "0 0 * * 6"}}}.IsValid())
+	assert.Error(t, sdk.ProjectCalendar{Name: "holidays", Blackouts: sdk.CalendarBlackouts{{Cron: "0 0 * * 6", Duration: "48h", From: "2020-12-25"}}}.IsValid())
+}
+
+func TestProjectCalendarBlackout(t *testing.T) {
+	c := sdk.ProjectCalendar{
+		Name:     "ops",
+		Timezone: "Europe/Paris",
+		Blackouts: sdk.CalendarBlackouts{
+			{Name: "christmas", From: "2020-12-25", To: "2020-12-25"},
+			{Name: "freeze", From: "2020-11-02T18:00", To: "2020-11-03T08:00"},
+			{Name: "weekend", Cron: "0 0 * * 6", Duration: "48h"},
+		},
+	}
+
+	loc, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	cases := []struct {
+		date     time.Time
+		expected string
+	}{
+		{date: time.Date(2020, 12, 24, 23, 59, 0, 0, loc)},
+		{date: time.Date(2020, 12, 25, 0, 0, 0, 0, loc), expected: "christmas"},
+		{date: time.Date(2020, 12, 25, 23, 59, 0, 0, loc), expected: "christmas"},
+		{date: time.Date(2020, 12, 24, 23, 30, 0, 0, time.UTC), expected: "christmas"},
+		{date: time.Date(2020, 11, 2, 17, 59, 0, 0, loc)},
+		{date: time.Date(2020, 11, 2, 18, 0, 0, 0, loc), expected: "freeze"},
+		{date: time.Date(2020, 11, 3, 8, 0, 0, 0, loc)},
+		{date: time.Date(2020, 11, 6, 23, 59, 0, 0, loc)},
+		{date: time.Date(2020, 11, 7, 0, 0, 0, 0, loc), expected: "weekend"},
+		{date: time.Date(2020, 11, 8, 23, 59, 0, 0, loc), expected: "weekend"},
+		{date: time.Date(2020, 11, 9, 0, 0, 0, 0, loc)},
+	}
+	for _, c2 := range cases {
+		b := c.Blackout(c2.date)
+		if c2.expected == "" {
+			assert.Nil(t, b, "unexpected blackout for %s", c2.date)
+			continue
+		}
+		if assert.NotNil(t, b, "missing blackout for %s", c2.date) {
+			assert.Equal(t, c2.expected, b.Name)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package sdk
+
+import "time"
+
+// ProjectLockTimeout is the max duration a project lock can be held by a node run, after that
+// the node run is stopped and the lock is released.
+const ProjectLockTimeout = 6 * time.Hour
+
+// ProjectLock is a named lock shared by all the workflows of a project. A node or an environment
+// that declares a lock can't be run while another node run holds the lock with the same name.
+// For a lock name, there is at most one holder and the other node runs are waiting for it.
+type ProjectLock struct {
+	ID                int64     `json:"id" db:"id"`
+	ProjectID         int64     `json:"project_id" db:"project_id"`
+	Name              string    `json:"name" db:"name" cli:"name,key"`
+	Held              bool      `json:"held" db:"held" cli:"held"`
+	WorkflowName      string    `json:"workflow_name" db:"workflow_name" cli:"workflow"`
+	WorkflowRunID     int64     `json:"workflow_run_id" db:"workflow_run_id"`
+	WorkflowRunNumber int64     `json:"workflow_run_number" db:"workflow_run_number" cli:"run"`
+	WorkflowNodeRunID int64     `json:"workflow_node_run_id" db:"workflow_node_run_id"`
+	NodeName          string    `json:"node_name" db:"node_name" cli:"node"`
+	Since             time.Time `json:"since" db:"since" cli:"since"`
+}
+
+// IsExpired returns true if the lock has been held for more than ProjectLockTimeout.
+func (l ProjectLock) IsExpired() bool {
+	return l.Held && time.Since(l.Since) > ProjectLockTimeout
+}
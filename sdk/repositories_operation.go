@@ -34,10 +34,12 @@ type OperationRepositoryInfo struct {
 	DefaultBranch string `json:"default_branch,omitempty"`
 }
 
-// OperationLoadFiles represents files loading from a globbing pattern
+// OperationLoadFiles represents files loading from a globbing pattern, Includes contains
+// the files of the repository included by the loaded workflow files.
 type OperationLoadFiles struct {
-	Pattern string            `json:"pattern,omitempty"`
-	Results map[string][]byte `json:"results,omitempty"`
+	Pattern  string            `json:"pattern,omitempty"`
+	Results  map[string][]byte `json:"results,omitempty"`
+	Includes map[string][]byte `json:"includes,omitempty"`
 }
 
 // OperationCheckout represents a smart git checkout
@@ -10,5 +10,6 @@ type Result struct {
 	Reason       string     `json:"reason,omitempty"`
 	RemoteTime   time.Time  `json:"remoteTime,omitempty"`
 	Duration     string     `json:"duration,omitempty"`
+	ExitCode     int        `json:"exit_code,omitempty"`
 	NewVariables []Variable `json:"new_variables,omitempty"`
 }
@@ -160,6 +160,8 @@ type ModelDocker struct {
 	Envs     map[string]string `json:"envs,omitempty"`
 	Shell    string            `json:"shell,omitempty"`
 	Cmd      string            `json:"cmd,omitempty"`
+	// PodTemplate is an optional Kubernetes pod template in yaml used as base for the pods of the workers
+	PodTemplate string `json:"pod_template,omitempty"`
 }
 
 // ModelPattern represent patterns for users and admin when creating a worker model
@@ -34,6 +34,8 @@ type Workflow struct {
 	Usage                   *Usage                       `json:"usage,omitempty" db:"-" cli:"-"`
 	HistoryLength           int64                        `json:"history_length" db:"history_length" cli:"-"`
 	PurgeTags               []string                     `json:"purge_tags,omitempty" db:"-" cli:"-"`
+	RetentionPolicy         *WorkflowRetentionPolicy     `json:"retention_policy,omitempty" db:"-" cli:"-"`
+	Concurrency             *WorkflowConcurrency         `json:"concurrency,omitempty" db:"-" cli:"-"`
 	Notifications           []WorkflowNotification       `json:"notifications,omitempty" db:"-" cli:"-"`
 	FromRepository          string                       `json:"from_repository,omitempty" db:"from_repository" cli:"from"`
 	DerivedFromWorkflowID   int64                        `json:"derived_from_workflow_id,omitempty" db:"derived_from_workflow_id" cli:"-"`
@@ -80,6 +82,12 @@ type AsCodeEvent struct {
 	CreationDate   time.Time `json:"creation_date" db:"creation_date" cli:"-"`
 }
 
+// WorkflowPlan contains the messages and the file diffs of a workflow import that was not applied.
+type WorkflowPlan struct {
+	Messages []string                   `json:"messages"`
+	Files    []WorkflowTemplateFileDiff `json:"files"`
+}
+
 // GetApplication retrieve application from workflow
 func (w *Workflow) GetApplication(ID int64) Application {
 	return w.Applications[ID]
@@ -170,6 +178,8 @@ func (w *Workflow) AssignEmptyType() {
 				n.Type = NodeTypePipeline
 			} else if n.OutGoingHookContext != nil && n.OutGoingHookContext.HookModelID != 0 {
 				n.Type = NodeTypeOutGoingHook
+			} else if n.ApprovalContext != nil {
+				n.Type = NodeTypeApproval
 			} else {
 				n.Type = NodeTypeFork
 			}
@@ -195,10 +205,18 @@ func (w *Workflow) ValidateType() error {
 			if n.JoinContext == nil || len(n.JoinContext) == 0 {
 				namesInError = append(namesInError, n.Name)
 			}
+		case NodeTypeApproval:
+			if n.ApprovalContext == nil {
+				namesInError = append(namesInError, n.Name)
+				continue
+			}
+			if err := n.ApprovalContext.IsValid(); err != nil {
+				return err
+			}
 		case NodeTypeFork:
 			if (n.Context != nil && (n.Context.PipelineID != 0 || n.Context.PipelineName != "")) ||
 				(n.OutGoingHookContext != nil && (n.OutGoingHookContext.HookModelID != 0 || n.OutGoingHookContext.HookModelName != "")) ||
-				(n.JoinContext != nil && len(n.JoinContext) > 0) {
+				(n.JoinContext != nil && len(n.JoinContext) > 0) || n.ApprovalContext != nil {
 				namesInError = append(namesInError, n.Name)
 			}
 		default:
@@ -211,10 +229,15 @@ func (w *Workflow) ValidateType() error {
 	return nil
 }
 
-//WorkflowNodeConditions is either an array of WorkflowNodeCondition or a lua script
+//WorkflowNodeConditions is either an array of WorkflowNodeCondition or a lua script.
+//Plain conditions and nested groups are combined with the given operator (and by default)
+//and the result can be negated.
 type WorkflowNodeConditions struct {
-	PlainConditions []WorkflowNodeCondition `json:"plain,omitempty" yaml:"check,omitempty"`
-	LuaScript       string                  `json:"lua_script,omitempty" yaml:"script,omitempty"`
+	PlainConditions []WorkflowNodeCondition  `json:"plain,omitempty" yaml:"check,omitempty"`
+	LuaScript       string                   `json:"lua_script,omitempty" yaml:"script,omitempty"`
+	Operator        string                   `json:"operator,omitempty" yaml:"operator,omitempty" jsonschema:"enum=and,enum=or"`
+	Not             bool                     `json:"not,omitempty" yaml:"not,omitempty"`
+	Groups          []WorkflowNodeConditions `json:"groups,omitempty" yaml:"groups,omitempty"`
 }
 
 // Value returns driver.Value from WorkflowNodeConditions request.
@@ -235,7 +258,7 @@ func (w *WorkflowNodeConditions) Scan(src interface{}) error {
 	return WrapError(json.Unmarshal(source, w), "cannot unmarshal WorkflowNodeConditions")
 }
 
-//WorkflowNodeCondition represents a condition to trigger ot not a pipeline in a workflow. Operator can be =, !=, regex
+//WorkflowNodeCondition represents a condition to trigger ot not a pipeline in a workflow. Operator can be =, !=, regex, semver...
 type WorkflowNodeCondition struct {
 	Variable string `json:"variable" yaml:"variable"`
 	Operator string `json:"operator" yaml:"operator"`
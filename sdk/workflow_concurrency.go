@@ -0,0 +1,64 @@
+package sdk
+
+import "strings"
+
+// Concurrency policies applied when the maximum number of concurrent runs for a key is reached.
+const (
+	WorkflowConcurrencyPolicyCancel = "cancel" // older runs are cancelled
+	WorkflowConcurrencyPolicyQueue  = "queue"  // the new run waits for a running one to end
+	WorkflowConcurrencyPolicySkip   = "skip"   // the new run is not started
+)
+
+// WorkflowConcurrencyTag is the tag of a workflow run containing its concurrency key.
+const WorkflowConcurrencyTag = "cds.concurrency"
+
+// WorkflowConcurrency limits the number of runs of a workflow that can be processed at the same time.
+// Runs are grouped by a key computed from the variables of the run, ex: {{.git.branch}}.
+type WorkflowConcurrency struct {
+	Key    string `json:"key"`
+	Max    int64  `json:"max,omitempty"`
+	Policy string `json:"policy,omitempty"`
+}
+
+// GetMax returns the max number of concurrent runs per key, default is 1.
+func (c WorkflowConcurrency) GetMax() int64 {
+	if c.Max <= 0 {
+		return 1
+	}
+	return c.Max
+}
+
+// GetPolicy returns the policy of the concurrency, default is to cancel older runs.
+func (c WorkflowConcurrency) GetPolicy() string {
+	if c.Policy == "" {
+		return WorkflowConcurrencyPolicyCancel
+	}
+	return c.Policy
+}
+
+// IsValid returns an error if the concurrency is not valid.
+func (c WorkflowConcurrency) IsValid() error {
+	if strings.TrimSpace(c.Key) == "" {
+		return NewErrorFrom(ErrWorkflowInvalid, "missing key for concurrency")
+	}
+	if c.Max < 0 {
+		return NewErrorFrom(ErrWorkflowInvalid, "invalid max value for concurrency")
+	}
+	switch c.GetPolicy() {
+	case WorkflowConcurrencyPolicyCancel, WorkflowConcurrencyPolicyQueue, WorkflowConcurrencyPolicySkip:
+	default:
+		return NewErrorFrom(ErrWorkflowInvalid, "invalid policy %q for concurrency, expected one of %s, %s or %s", c.Policy,
+			WorkflowConcurrencyPolicyCancel, WorkflowConcurrencyPolicyQueue, WorkflowConcurrencyPolicySkip)
+	}
+	return nil
+}
+
+// Superseded returns the runs that should be cancelled to start a new run, given the ids of the
+// runs in progress for the same key ordered from the oldest to the most recent.
+func (c WorkflowConcurrency) Superseded(inProgress []int64) []int64 {
+	nb := int64(len(inProgress)) - c.GetMax() + 1
+	if nb <= 0 {
+		return nil
+	}
+	return inProgress[:nb]
+}
@@ -0,0 +1,30 @@
+package sdk_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestWorkflowConcurrencyIsValid(t *testing.T) {
+	assert.NoError(t, sdk.WorkflowConcurrency{Key: "{{.git.branch}}"}.IsValid())
+	assert.NoError(t, sdk.WorkflowConcurrency{Key: "{{.git.branch}}", Max: 2, Policy: sdk.WorkflowConcurrencyPolicyQueue}.IsValid())
+	assert.Error(t, sdk.WorkflowConcurrency{Key: " "}.IsValid())
+	assert.Error(t, sdk.WorkflowConcurrency{Key: "{{.git.branch}}", Max: -1}.IsValid())
+	assert.Error(t, sdk.WorkflowConcurrency{Key: "{{.git.branch}}", Policy: "unknown"}.IsValid())
+}
+
+func TestWorkflowConcurrencySuperseded(t *testing.T) {
+	c := sdk.WorkflowConcurrency{Key: "{{.git.branch}}"}
+	assert.Equal(t, int64(1), c.GetMax())
+	assert.Equal(t, sdk.WorkflowConcurrencyPolicyCancel, c.GetPolicy())
+	assert.Empty(t, c.Superseded(nil))
+	assert.Equal(t, []int64{1, 2}, c.Superseded([]int64{1, 2}))
+
+	c.Max = 3
+	assert.Empty(t, c.Superseded([]int64{1, 2}))
+	assert.Equal(t, []int64{1}, c.Superseded([]int64{1, 2, 3}))
+	assert.Equal(t, []int64{1, 2}, c.Superseded([]int64{1, 2, 3, 4}))
+}
@@ -2,40 +2,100 @@ package sdk
 
 import (
 	"fmt"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 
+	"github.com/blang/semver"
+
 	"github.com/ovh/cds/sdk/interpolate"
 )
 
 // WorkflowData conditions operator
 const (
-	WorkflowConditionsOperatorEquals             = "eq"
-	WorkflowConditionsOperatorNotEquals          = "ne"
-	WorkflowConditionsOperatorLessThan           = "lt"
-	WorkflowConditionsOperatorLessOrEqualThan    = "le"
-	WorkflowConditionsOperatorGreaterThan        = "gt"
-	WorkflowConditionsOperatorGreaterOrEqualThan = "ge"
-	WorkflowConditionsOperatorRegex              = "regex"
+	WorkflowConditionsOperatorEquals                = "eq"
+	WorkflowConditionsOperatorNotEquals             = "ne"
+	WorkflowConditionsOperatorLessThan              = "lt"
+	WorkflowConditionsOperatorLessOrEqualThan       = "le"
+	WorkflowConditionsOperatorGreaterThan           = "gt"
+	WorkflowConditionsOperatorGreaterOrEqualThan    = "ge"
+	WorkflowConditionsOperatorRegex                 = "regex"
+	WorkflowConditionsOperatorNumLessThan           = "num_lt"
+	WorkflowConditionsOperatorNumLessOrEqualThan    = "num_le"
+	WorkflowConditionsOperatorNumGreaterThan        = "num_gt"
+	WorkflowConditionsOperatorNumGreaterOrEqualThan = "num_ge"
+	WorkflowConditionsOperatorSemver                = "semver"
+	WorkflowConditionsOperatorGlob                  = "glob"
+	WorkflowConditionsOperatorIn                    = "in"
+	WorkflowConditionsOperatorContains              = "contains"
+)
+
+// WorkflowData conditions group operator
+const (
+	WorkflowConditionsGroupOperatorAnd = "and"
+	WorkflowConditionsGroupOperatorOr  = "or"
 )
 
 // WorkflowData conditions operator
 var (
 	WorkflowConditionsOperators = map[string]string{
-		WorkflowConditionsOperatorEquals:             "=",
-		WorkflowConditionsOperatorNotEquals:          "!=",
-		WorkflowConditionsOperatorLessThan:           "<",
-		WorkflowConditionsOperatorLessOrEqualThan:    "<=",
-		WorkflowConditionsOperatorGreaterThan:        ">",
-		WorkflowConditionsOperatorGreaterOrEqualThan: ">=",
-		WorkflowConditionsOperatorRegex:              "match",
+		WorkflowConditionsOperatorEquals:                "=",
+		WorkflowConditionsOperatorNotEquals:             "!=",
+		WorkflowConditionsOperatorLessThan:              "<",
+		WorkflowConditionsOperatorLessOrEqualThan:       "<=",
+		WorkflowConditionsOperatorGreaterThan:           ">",
+		WorkflowConditionsOperatorGreaterOrEqualThan:    ">=",
+		WorkflowConditionsOperatorRegex:                 "match",
+		WorkflowConditionsOperatorNumLessThan:           "< (number)",
+		WorkflowConditionsOperatorNumLessOrEqualThan:    "<= (number)",
+		WorkflowConditionsOperatorNumGreaterThan:        "> (number)",
+		WorkflowConditionsOperatorNumGreaterOrEqualThan: ">= (number)",
+		WorkflowConditionsOperatorSemver:                "in semver range",
+		WorkflowConditionsOperatorGlob:                  "glob",
+		WorkflowConditionsOperatorIn:                    "in list",
+		WorkflowConditionsOperatorContains:              "contains",
 	}
 )
 
+// IsValid checks that all operators used in conditions and in nested groups are known.
+func (w WorkflowNodeConditions) IsValid() error {
+	switch w.Operator {
+	case "", WorkflowConditionsGroupOperatorAnd, WorkflowConditionsGroupOperatorOr:
+	default:
+		return NewErrorFrom(ErrWorkflowConditionBadOperator, "unknown group operator %s", w.Operator)
+	}
+	for _, cond := range w.PlainConditions {
+		if _, ok := WorkflowConditionsOperators[cond.Operator]; !ok {
+			return NewErrorFrom(ErrWorkflowConditionBadOperator, "unknown operator %s", cond.Operator)
+		}
+	}
+	for _, g := range w.Groups {
+		if g.LuaScript != "" {
+			return NewErrorFrom(ErrWorkflowConditionBadOperator, "lua script is not allowed in a conditions group")
+		}
+		if err := g.IsValid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsEmpty returns true if there is no condition at all.
+func (w WorkflowNodeConditions) IsEmpty() bool {
+	return w.LuaScript == "" && len(w.PlainConditions) == 0 && len(w.Groups) == 0
+}
+
 //WorkflowCheckConditions checks conditions given a list of parameters
 func WorkflowCheckConditions(conditions []WorkflowNodeCondition, params []Parameter) (bool, error) {
-	if len(conditions) == 0 {
-		return true, nil
+	return WorkflowCheckNodeConditions(WorkflowNodeConditions{PlainConditions: conditions}, params)
+}
+
+// WorkflowCheckNodeConditions checks plain conditions and conditions groups given a list of parameters.
+// Lua script is not handled here and must be checked by the caller.
+func WorkflowCheckNodeConditions(conditions WorkflowNodeConditions, params []Parameter) (bool, error) {
+	if len(conditions.PlainConditions) == 0 && len(conditions.Groups) == 0 {
+		return !conditions.Not, nil
 	}
 	mapParams := ParametersToMap(params)
 	for k, v := range mapParams {
@@ -45,42 +105,139 @@ func WorkflowCheckConditions(conditions []WorkflowNodeCondition, params []Parame
 			return false, fmt.Errorf("Unable to interpolate %s (%v)", v, err)
 		}
 	}
+	return checkConditionsGroup(conditions, mapParams)
+}
 
-	var conditionsOK = true
-	for _, cond := range conditions {
-		var err error
-		cond.Value, err = interpolate.Do(cond.Value, mapParams)
+func checkConditionsGroup(conditions WorkflowNodeConditions, mapParams map[string]string) (bool, error) {
+	results := make([]bool, 0, len(conditions.PlainConditions)+len(conditions.Groups))
+	for _, cond := range conditions.PlainConditions {
+		ok, err := checkCondition(cond, mapParams)
+		if err != nil {
+			return false, err
+		}
+		results = append(results, ok)
+	}
+	for _, g := range conditions.Groups {
+		ok, err := checkConditionsGroup(g, mapParams)
 		if err != nil {
-			return false, fmt.Errorf("Unable to interpolate %s (%v)", cond.Value, err)
+			return false, err
 		}
+		results = append(results, ok)
+	}
 
-		switch cond.Operator {
-		case WorkflowConditionsOperatorEquals:
-			conditionsOK = conditionsOK && cond.Value == mapParams[cond.Variable]
+	var conditionsOK bool
+	switch conditions.Operator {
+	case WorkflowConditionsGroupOperatorOr:
+		conditionsOK = false
+		for _, r := range results {
+			conditionsOK = conditionsOK || r
+		}
+		// An empty OR group is considered as true
+		if len(results) == 0 {
+			conditionsOK = true
+		}
+	case "", WorkflowConditionsGroupOperatorAnd:
+		conditionsOK = true
+		for _, r := range results {
+			conditionsOK = conditionsOK && r
+		}
+	default:
+		return false, fmt.Errorf("Unknown conditions group operator %s", conditions.Operator)
+	}
 
-		case WorkflowConditionsOperatorNotEquals:
-			conditionsOK = conditionsOK && cond.Value != mapParams[cond.Variable]
+	if conditions.Not {
+		return !conditionsOK, nil
+	}
+	return conditionsOK, nil
+}
+
+func checkCondition(cond WorkflowNodeCondition, mapParams map[string]string) (bool, error) {
+	var err error
+	cond.Value, err = interpolate.Do(cond.Value, mapParams)
+	if err != nil {
+		return false, fmt.Errorf("Unable to interpolate %s (%v)", cond.Value, err)
+	}
+	variable := mapParams[cond.Variable]
+
+	switch cond.Operator {
+	case WorkflowConditionsOperatorEquals:
+		return cond.Value == variable, nil
+
+	case WorkflowConditionsOperatorNotEquals:
+		return cond.Value != variable, nil
 
-		case WorkflowConditionsOperatorLessThan:
-			conditionsOK = conditionsOK && strings.Compare(mapParams[cond.Variable], cond.Value) < 0
+	case WorkflowConditionsOperatorLessThan:
+		return strings.Compare(variable, cond.Value) < 0, nil
 
-		case WorkflowConditionsOperatorLessOrEqualThan:
-			conditionsOK = conditionsOK && strings.Compare(mapParams[cond.Variable], cond.Value) <= 0
+	case WorkflowConditionsOperatorLessOrEqualThan:
+		return strings.Compare(variable, cond.Value) <= 0, nil
 
-		case WorkflowConditionsOperatorGreaterThan:
-			conditionsOK = conditionsOK && strings.Compare(mapParams[cond.Variable], cond.Value) > 0
+	case WorkflowConditionsOperatorGreaterThan:
+		return strings.Compare(variable, cond.Value) > 0, nil
 
-		case WorkflowConditionsOperatorGreaterOrEqualThan:
-			conditionsOK = conditionsOK && strings.Compare(mapParams[cond.Variable], cond.Value) >= 0
+	case WorkflowConditionsOperatorGreaterOrEqualThan:
+		return strings.Compare(variable, cond.Value) >= 0, nil
 
-		case WorkflowConditionsOperatorRegex:
-			match, err := regexp.MatchString(cond.Value, mapParams[cond.Variable])
-			if err != nil {
-				return false, fmt.Errorf("Unable to match string with regex %s (%v)", cond.Value, err)
+	case WorkflowConditionsOperatorRegex:
+		match, err := regexp.MatchString(cond.Value, variable)
+		if err != nil {
+			return false, fmt.Errorf("Unable to match string with regex %s (%v)", cond.Value, err)
+		}
+		return match, nil
+
+	case WorkflowConditionsOperatorNumLessThan, WorkflowConditionsOperatorNumLessOrEqualThan,
+		WorkflowConditionsOperatorNumGreaterThan, WorkflowConditionsOperatorNumGreaterOrEqualThan:
+		left, err := strconv.ParseFloat(strings.TrimSpace(variable), 64)
+		if err != nil {
+			// A variable that is not a number never matches a numeric comparison
+			return false, nil
+		}
+		right, err := strconv.ParseFloat(strings.TrimSpace(cond.Value), 64)
+		if err != nil {
+			return false, fmt.Errorf("Unable to compare %s with invalid number %s", cond.Variable, cond.Value)
+		}
+		switch cond.Operator {
+		case WorkflowConditionsOperatorNumLessThan:
+			return left < right, nil
+		case WorkflowConditionsOperatorNumLessOrEqualThan:
+			return left <= right, nil
+		case WorkflowConditionsOperatorNumGreaterThan:
+			return left > right, nil
+		default:
+			return left >= right, nil
+		}
+
+	case WorkflowConditionsOperatorSemver:
+		r, err := semver.ParseRange(cond.Value)
+		if err != nil {
+			return false, fmt.Errorf("Unable to parse semver range %s (%v)", cond.Value, err)
+		}
+		v, err := semver.ParseTolerant(variable)
+		if err != nil {
+			// A variable that is not a valid version never matches a semver range
+			return false, nil
+		}
+		return r(v), nil
+
+	case WorkflowConditionsOperatorGlob:
+		match, err := path.Match(cond.Value, variable)
+		if err != nil {
+			return false, fmt.Errorf("Unable to match string with glob %s (%v)", cond.Value, err)
+		}
+		return match, nil
+
+	case WorkflowConditionsOperatorIn:
+		for _, v := range strings.Split(cond.Value, ",") {
+			if strings.TrimSpace(v) == variable {
+				return true, nil
 			}
-			conditionsOK = conditionsOK && match
 		}
+		return false, nil
+
+	case WorkflowConditionsOperatorContains:
+		return strings.Contains(variable, cond.Value), nil
 	}
 
-	return conditionsOK, nil
+	// Unknown operators are ignored to keep the behaviour of existing conditions
+	return true, nil
 }
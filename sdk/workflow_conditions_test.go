@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowCheckConditionsOperators(t *testing.T) {
+	params := []Parameter{
+		{Name: "cds.version", Type: StringParameter, Value: "10"},
+		{Name: "git.tag", Type: StringParameter, Value: "v1.10.0"},
+		{Name: "git.branch", Type: StringParameter, Value: "feat/my-feature"},
+	}
+
+	tests := []struct {
+		cond WorkflowNodeCondition
+		want bool
+	}{
+		{WorkflowNodeCondition{Variable: "cds.version", Operator: WorkflowConditionsOperatorGreaterThan, Value: "9"}, false},
+		{WorkflowNodeCondition{Variable: "cds.version", Operator: WorkflowConditionsOperatorNumGreaterThan, Value: "9"}, true},
+		{WorkflowNodeCondition{Variable: "cds.version", Operator: WorkflowConditionsOperatorNumLessOrEqualThan, Value: "10"}, true},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorNumLessThan, Value: "10"}, false},
+		{WorkflowNodeCondition{Variable: "git.tag", Operator: WorkflowConditionsOperatorSemver, Value: ">1.9.0"}, true},
+		{WorkflowNodeCondition{Variable: "git.tag", Operator: WorkflowConditionsOperatorSemver, Value: ">=1.2.0 <1.10.0"}, false},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorSemver, Value: ">=1.0.0"}, false},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorGlob, Value: "feat/*"}, true},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorGlob, Value: "fix/*"}, false},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorIn, Value: "master, feat/my-feature"}, true},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorIn, Value: "master,develop"}, false},
+		{WorkflowNodeCondition{Variable: "git.branch", Operator: WorkflowConditionsOperatorContains, Value: "my-feat"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cond.Operator+" "+tt.cond.Value, func(t *testing.T) {
+			ok, err := WorkflowCheckConditions([]WorkflowNodeCondition{tt.cond}, params)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestWorkflowCheckNodeConditionsGroups(t *testing.T) {
+	params := []Parameter{
+		{Name: "git.branch", Type: StringParameter, Value: "master"},
+		{Name: "git.tag", Type: StringParameter, Value: ""},
+	}
+
+	// (branch == master OR tag != "") AND NOT (branch glob feat/*)
+	conditions := WorkflowNodeConditions{
+		Groups: []WorkflowNodeConditions{
+			{
+				Operator: WorkflowConditionsGroupOperatorOr,
+				PlainConditions: []WorkflowNodeCondition{
+					{Variable: "git.branch", Operator: WorkflowConditionsOperatorEquals, Value: "master"},
+					{Variable: "git.tag", Operator: WorkflowConditionsOperatorNotEquals, Value: ""},
+				},
+			},
+			{
+				Not: true,
+				PlainConditions: []WorkflowNodeCondition{
+					{Variable: "git.branch", Operator: WorkflowConditionsOperatorGlob, Value: "feat/*"},
+				},
+			},
+		},
+	}
+	require.NoError(t, conditions.IsValid())
+
+	ok, err := WorkflowCheckNodeConditions(conditions, params)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	conditions.Not = true
+	ok, err = WorkflowCheckNodeConditions(conditions, params)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	conditions.Groups[0].Operator = "xor"
+	assert.Error(t, conditions.IsValid())
+}
@@ -12,6 +12,7 @@ const (
 	NodeTypeJoin         = "join"
 	NodeTypeOutGoingHook = "outgoinghook"
 	NodeTypeFork         = "fork"
+	NodeTypeApproval     = "approval"
 )
 
 // Node represents a node in a workflow
@@ -26,6 +27,7 @@ type Node struct {
 	Context             *NodeContext      `json:"context" db:"-"`
 	OutGoingHookContext *NodeOutGoingHook `json:"outgoing_hook" db:"-"`
 	JoinContext         []NodeJoin        `json:"parents" db:"-"`
+	ApprovalContext     *NodeApproval     `json:"approval,omitempty" db:"-"`
 	Hooks               []NodeHook        `json:"hooks" db:"-"`
 	Groups              []GroupPermission `json:"groups,omitempty" db:"-"`
 }
@@ -46,6 +48,7 @@ type NodeContext struct {
 	DefaultPipelineParameters []Parameter            `json:"default_pipeline_parameters" db:"-"`
 	Conditions                WorkflowNodeConditions `json:"conditions" db:"-"`
 	Mutex                     bool                   `json:"mutex" db:"mutex"`
+	Lock                      string                 `json:"lock,omitempty" db:"-"`
 }
 
 // FilterHooksConfig filter all hooks configuration and remove somme configuration key
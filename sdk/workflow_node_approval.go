@@ -0,0 +1,70 @@
+package sdk
+
+import (
+	"time"
+)
+
+// NodeApproval represents the configuration of a manual approval gate node.
+// The run of an approval node waits until enough members of the approver groups
+// approve it, then its children are triggered.
+type NodeApproval struct {
+	Groups       []string `json:"groups,omitempty"` // names of the groups allowed to approve, any user that can execute the workflow if empty
+	MinApprovals int      `json:"min_approvals,omitempty"`
+	Expiry       int64    `json:"expiry,omitempty"` // in seconds, the node run fails when the delay is exceeded
+	Comment      string   `json:"comment,omitempty"`
+}
+
+// IsValid returns an error if the approval configuration is not valid.
+func (a NodeApproval) IsValid() error {
+	if a.MinApprovals < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid min approvals for approval node")
+	}
+	if a.Expiry < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid expiry for approval node")
+	}
+	for _, g := range a.Groups {
+		if g == "" {
+			return NewErrorFrom(ErrWrongRequest, "invalid empty group name for approval node")
+		}
+	}
+	return nil
+}
+
+// RequiredApprovals returns the number of approvals needed to pass the gate.
+func (a NodeApproval) RequiredApprovals() int {
+	if a.MinApprovals < 1 {
+		return 1
+	}
+	return a.MinApprovals
+}
+
+// IsExpired returns true if the approval delay started at given time is exceeded.
+func (a NodeApproval) IsExpired(start time.Time) bool {
+	if a.Expiry <= 0 {
+		return false
+	}
+	return time.Since(start) > time.Duration(a.Expiry)*time.Second
+}
+
+// WorkflowNodeRunApproval represents an approval given by a user on an approval node run.
+type WorkflowNodeRunApproval struct {
+	UserID   string    `json:"user_id"`
+	Username string    `json:"username"`
+	Comment  string    `json:"comment,omitempty"`
+	Date     time.Time `json:"date"`
+}
+
+// WorkflowNodeRunApprovalRequest is the body of an approval request.
+type WorkflowNodeRunApprovalRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// HasApproved returns true if given user already approved the node run.
+func (n WorkflowNodeRun) HasApproved(userID string) bool {
+	for _, a := range n.Approvals {
+		if a.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
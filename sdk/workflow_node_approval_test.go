@@ -0,0 +1,35 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestNodeApproval(t *testing.T) {
+	a := sdk.NodeApproval{}
+	assert.NoError(t, a.IsValid())
+	assert.Equal(t, 1, a.RequiredApprovals())
+	assert.False(t, a.IsExpired(time.Now().Add(-24*time.Hour)))
+
+	a = sdk.NodeApproval{Groups: []string{"ops"}, MinApprovals: 2, Expiry: 3600}
+	assert.NoError(t, a.IsValid())
+	assert.Equal(t, 2, a.RequiredApprovals())
+	assert.False(t, a.IsExpired(time.Now().Add(-time.Minute)))
+	assert.True(t, a.IsExpired(time.Now().Add(-2*time.Hour)))
+
+	assert.Error(t, sdk.NodeApproval{MinApprovals: -1}.IsValid())
+	assert.Error(t, sdk.NodeApproval{Expiry: -1}.IsValid())
+	assert.Error(t, sdk.NodeApproval{Groups: []string{""}}.IsValid())
+}
+
+func TestWorkflowNodeRunHasApproved(t *testing.T) {
+	nr := sdk.WorkflowNodeRun{
+		Approvals: []sdk.WorkflowNodeRunApproval{{UserID: "123", Username: "foo"}},
+	}
+	assert.True(t, nr.HasApproved("123"))
+	assert.False(t, nr.HasApproved("456"))
+}
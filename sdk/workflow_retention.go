@@ -0,0 +1,137 @@
+package sdk
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// WorkflowRetentionPolicy describes the workflow runs kept by the purge, it replaces the purge tags
+// of the workflow. The last runs of each branch are kept, or the last runs of the workflow according
+// to its history length, tags and last success rules only add exceptions to this rule.
+type WorkflowRetentionPolicy struct {
+	KeepLastPerBranch  int64    `json:"keep_last_per_branch,omitempty"` // number of runs kept for each git branch
+	KeepTags           []string `json:"keep_tags,omitempty"`            // runs with one of these tags are always kept, ex: release or git.branch=master
+	DeletedBranchesTTL int64    `json:"deleted_branches_ttl,omitempty"` // in seconds, runs of a deleted branch are deleted after this delay
+	KeepLastSuccess    bool     `json:"keep_last_success,omitempty"`    // the last run in success is always kept
+}
+
+// IsEmpty returns true if the policy contains no rule.
+func (p WorkflowRetentionPolicy) IsEmpty() bool {
+	return p.KeepLastPerBranch <= 0 && len(p.KeepTags) == 0 && p.DeletedBranchesTTL <= 0 && !p.KeepLastSuccess
+}
+
+// IsValid returns an error if the retention policy is not valid.
+func (p WorkflowRetentionPolicy) IsValid() error {
+	if p.KeepLastPerBranch < 0 {
+		return NewErrorFrom(ErrWorkflowInvalid, "invalid keep last per branch value for retention policy")
+	}
+	if p.DeletedBranchesTTL < 0 {
+		return NewErrorFrom(ErrWorkflowInvalid, "invalid deleted branches ttl for retention policy")
+	}
+	for _, t := range p.KeepTags {
+		if strings.TrimSpace(t) == "" || strings.HasPrefix(t, "=") {
+			return NewErrorFrom(ErrWorkflowInvalid, "invalid keep tag %q for retention policy", t)
+		}
+	}
+	return nil
+}
+
+// keepTag returns true if one of the run tags matches a kept tag.
+func (p WorkflowRetentionPolicy) keepTag(r WorkflowRunRetention) bool {
+	for _, kt := range p.KeepTags {
+		name, value := kt, ""
+		if i := strings.Index(kt, "="); i > 0 {
+			name, value = kt[:i], kt[i+1:]
+		}
+		for _, t := range r.Tags {
+			if t.Tag == name && (value == "" || t.Value == value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WorkflowRunRetention contains the data of a workflow run needed to apply a retention policy.
+type WorkflowRunRetention struct {
+	ID           int64            `db:"id"`
+	Number       int64            `db:"num"`
+	Status       string           `db:"status"`
+	LastModified time.Time        `db:"last_modified"`
+	Tags         []WorkflowRunTag `db:"-"`
+}
+
+// Branch returns the value of the git.branch tag of the run.
+func (r WorkflowRunRetention) Branch() string {
+	for _, t := range r.Tags {
+		if t.Tag == "git.branch" {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// Apply returns the runs that are not kept by the retention policy, from the most recent to the oldest.
+// Runs that are not terminated are always kept. The deleted branches map contains the deletion date
+// of each deleted branch. Without per branch rule, the last runs are kept according to given history
+// length, all runs are kept if it's zero.
+func (p WorkflowRetentionPolicy) Apply(runs []WorkflowRunRetention, deletedBranches map[string]time.Time, historyLength int64, now time.Time) []WorkflowRunRetention {
+	sorted := make([]WorkflowRunRetention, len(runs))
+	copy(sorted, runs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Number > sorted[j].Number })
+
+	var lastSuccessFound bool
+	var nb int64
+	nbByBranch := map[string]int64{}
+	var res []WorkflowRunRetention
+	for _, r := range sorted {
+		if !StatusIsTerminated(r.Status) {
+			continue
+		}
+
+		var keep bool
+		if p.KeepLastSuccess && !lastSuccessFound && r.Status == StatusSuccess {
+			lastSuccessFound = true
+			keep = true
+		}
+		if p.keepTag(r) {
+			keep = true
+		}
+
+		branch := r.Branch()
+		deletedAt, isDeleted := deletedBranches[branch]
+		// Runs started after the deletion belong to a new branch with the same name
+		branchExpired := branch != "" && isDeleted && r.LastModified.Before(deletedAt) &&
+			now.Sub(deletedAt) >= time.Duration(p.DeletedBranchesTTL)*time.Second
+		if !branchExpired {
+			switch {
+			case p.KeepLastPerBranch > 0:
+				nbByBranch[branch]++
+				keep = keep || nbByBranch[branch] <= p.KeepLastPerBranch
+			case historyLength > 0:
+				nb++
+				keep = keep || nb <= historyLength
+			default:
+				keep = true
+			}
+		}
+
+		if !keep {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+// WorkflowRunPurge describes a workflow run deleted by the purge.
+type WorkflowRunPurge struct {
+	ID            int64    `json:"id" cli:"-"`
+	Number        int64    `json:"number" cli:"number,key"`
+	Status        string   `json:"status" cli:"status"`
+	Branch        string   `json:"branch,omitempty" cli:"branch"`
+	LastModified  string   `json:"last_modified" cli:"last_modified"`
+	NbArtifacts   int      `json:"nb_artifacts" cli:"artifacts"`
+	ArtifactsSize int64    `json:"artifacts_size" cli:"artifacts_size"`
+	Artifacts     []string `json:"artifacts,omitempty" cli:"-"`
+}
@@ -0,0 +1,102 @@
+package sdk_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestWorkflowRetentionPolicyIsValid(t *testing.T) {
+	assert.True(t, sdk.WorkflowRetentionPolicy{}.IsEmpty())
+	assert.NoError(t, sdk.WorkflowRetentionPolicy{KeepLastPerBranch: 50, KeepTags: []string{"release", "git.branch=master"}}.IsValid())
+	assert.Error(t, sdk.WorkflowRetentionPolicy{KeepLastPerBranch: -1}.IsValid())
+	assert.Error(t, sdk.WorkflowRetentionPolicy{DeletedBranchesTTL: -1}.IsValid())
+	assert.Error(t, sdk.WorkflowRetentionPolicy{KeepTags: []string{"=value"}}.IsValid())
+}
+
+func TestWorkflowRetentionPolicyApply(t *testing.T) {
+	now := time.Now()
+	run := func(num int64, status, branch string, tags ...sdk.WorkflowRunTag) sdk.WorkflowRunRetention {
+		return sdk.WorkflowRunRetention{
+			ID:           num,
+			Number:       num,
+			Status:       status,
+			LastModified: now.Add(-time.Duration(100-num) * time.Hour),
+			Tags:         append(tags, sdk.WorkflowRunTag{Tag: "git.branch", Value: branch}),
+		}
+	}
+	runs := []sdk.WorkflowRunRetention{
+		run(1, sdk.StatusSuccess, "master"),
+		run(2, sdk.StatusFail, "master", sdk.WorkflowRunTag{Tag: "release", Value: "v1.0.0"}),
+		run(3, sdk.StatusFail, "master"),
+		run(4, sdk.StatusFail, "feat"),
+		run(5, sdk.StatusFail, "master"),
+		run(6, sdk.StatusBuilding, "master"),
+		run(7, sdk.StatusFail, "old"),
+	}
+	deletedBranches := map[string]time.Time{
+		"feat": now.Add(-time.Hour),
+		"old":  now.Add(-10 * 24 * time.Hour),
+	}
+
+	numbers := func(rs []sdk.WorkflowRunRetention) []int64 {
+		var res []int64
+		for _, r := range rs {
+			res = append(res, r.Number)
+		}
+		return res
+	}
+
+	p := sdk.WorkflowRetentionPolicy{KeepLastPerBranch: 2}
+	assert.Equal(t, []int64{2, 1}, numbers(p.Apply(runs, nil, 0, now)))
+
+	p = sdk.WorkflowRetentionPolicy{KeepLastPerBranch: 2, KeepTags: []string{"release"}, KeepLastSuccess: true}
+	assert.Empty(t, p.Apply(runs, nil, 0, now))
+
+	p = sdk.WorkflowRetentionPolicy{KeepLastPerBranch: 1, KeepTags: []string{"release=v2.0.0"}, DeletedBranchesTTL: 7 * 24 * 3600}
+	// run 7 was started after the deletion of branch old, it belongs to a new branch
+	assert.Equal(t, []int64{3, 2, 1}, numbers(p.Apply(runs, deletedBranches, 0, now)))
+
+	// only runs of expired deleted branches are deleted
+	p = sdk.WorkflowRetentionPolicy{DeletedBranchesTTL: 1800}
+	assert.Equal(t, []int64{4}, numbers(p.Apply(runs, deletedBranches, 0, now)))
+
+	// without TTL, runs of deleted branches are deleted immediately
+	p = sdk.WorkflowRetentionPolicy{KeepTags: []string{"release"}}
+	assert.Equal(t, []int64{4}, numbers(p.Apply(runs, deletedBranches, 0, now)))
+
+	// per branch rule ignores the history length
+	p = sdk.WorkflowRetentionPolicy{KeepLastPerBranch: 1, KeepLastSuccess: true}
+	assert.Equal(t, []int64{3, 2}, numbers(p.Apply(runs, nil, 3, now)))
+}
+
+func TestWorkflowRetentionPolicyApplyWithExceptionsOnly(t *testing.T) {
+	now := time.Now()
+	runs := []sdk.WorkflowRunRetention{
+		{ID: 1, Number: 1, Status: sdk.StatusSuccess},
+		{ID: 2, Number: 2, Status: sdk.StatusFail, Tags: []sdk.WorkflowRunTag{{Tag: "release", Value: "v1.0.0"}}},
+		{ID: 3, Number: 3, Status: sdk.StatusFail},
+		{ID: 4, Number: 4, Status: sdk.StatusFail},
+		{ID: 5, Number: 5, Status: sdk.StatusFail},
+		{ID: 6, Number: 6, Status: sdk.StatusBuilding},
+	}
+	numbers := func(rs []sdk.WorkflowRunRetention) []int64 {
+		var res []int64
+		for _, r := range rs {
+			res = append(res, r.Number)
+		}
+		return res
+	}
+
+	// tags and last success only add exceptions, without history length all runs are kept
+	assert.Empty(t, sdk.WorkflowRetentionPolicy{KeepTags: []string{"release"}}.Apply(runs, nil, 0, now))
+	assert.Empty(t, sdk.WorkflowRetentionPolicy{KeepLastSuccess: true}.Apply(runs, nil, 0, now))
+
+	// the last runs are kept according to the history length
+	assert.Equal(t, []int64{3, 1}, numbers(sdk.WorkflowRetentionPolicy{KeepTags: []string{"release"}}.Apply(runs, nil, 2, now)))
+	assert.Equal(t, []int64{3, 2}, numbers(sdk.WorkflowRetentionPolicy{KeepLastSuccess: true}.Apply(runs, nil, 2, now)))
+	assert.Equal(t, []int64{3}, numbers(sdk.WorkflowRetentionPolicy{KeepTags: []string{"release"}, KeepLastSuccess: true}.Apply(runs, nil, 2, now)))
+}
@@ -240,6 +240,7 @@ type WorkflowNodeRun struct {
 	HookExecutionID        string                               `json:"execution_id,omitempty"`
 	Callback               *WorkflowNodeOutgoingHookRunCallback `json:"callback,omitempty"`
 	VCSReport              string                               `json:"vcs_report,omitempty"`
+	Approvals              []WorkflowNodeRunApproval            `json:"approvals,omitempty"`
 }
 
 // WorkflowNodeOutgoingHookRunCallback is the callback coming from hooks uservice avec an outgoing hook execution
@@ -308,23 +309,25 @@ func (nr *WorkflowNodeRun) Translate(lang string) {
 
 //WorkflowNodeRunArtifact represents tests list
 type WorkflowNodeRunArtifact struct {
-	WorkflowID           int64     `json:"workflow_id" db:"workflow_run_id"`
-	WorkflowNodeRunID    int64     `json:"workflow_node_run_id" db:"workflow_node_run_id"`
-	WorkflowNodeJobRunID int64     `json:"workflow_node_job_run_id" db:"-"`
-	ID                   int64     `json:"id" db:"id"`
-	Name                 string    `json:"name" db:"name" cli:"name,key"`
-	Tag                  string    `json:"tag" db:"tag" cli:"tag"`
-	Ref                  string    `json:"ref" db:"ref" cli:"ref"`
-	DownloadHash         string    `json:"download_hash" db:"download_hash"`
-	Size                 int64     `json:"size,omitempty" db:"size"`
-	Perm                 uint32    `json:"perm,omitempty" db:"perm"`
-	MD5sum               string    `json:"md5sum,omitempty" db:"md5sum" cli:"-"`
-	SHA512sum            string    `json:"sha512sum,omitempty" db:"sha512sum" cli:"sha512sum"`
-	ObjectPath           string    `json:"object_path,omitempty" db:"object_path"`
-	Created              time.Time `json:"created,omitempty" db:"created"`
-	TempURL              string    `json:"temp_url,omitempty" db:"-"`
-	TempURLSecretKey     string    `json:"-" db:"-"`
-	ProjectIntegrationID *int64    `json:"project_integration_id" db:"project_integration_id"`
+	WorkflowID           int64               `json:"workflow_id" db:"workflow_run_id"`
+	WorkflowNodeRunID    int64               `json:"workflow_node_run_id" db:"workflow_node_run_id"`
+	WorkflowNodeJobRunID int64               `json:"workflow_node_job_run_id" db:"-"`
+	ID                   int64               `json:"id" db:"id"`
+	Name                 string              `json:"name" db:"name" cli:"name,key"`
+	Tag                  string              `json:"tag" db:"tag" cli:"tag"`
+	Ref                  string              `json:"ref" db:"ref" cli:"ref"`
+	DownloadHash         string              `json:"download_hash" db:"download_hash"`
+	Size                 int64               `json:"size,omitempty" db:"size"`
+	Perm                 uint32              `json:"perm,omitempty" db:"perm"`
+	MD5sum               string              `json:"md5sum,omitempty" db:"md5sum" cli:"-"`
+	SHA512sum            string              `json:"sha512sum,omitempty" db:"sha512sum" cli:"sha512sum"`
+	ObjectPath           string              `json:"object_path,omitempty" db:"object_path"`
+	Created              time.Time           `json:"created,omitempty" db:"created"`
+	TempURL              string              `json:"temp_url,omitempty" db:"-"`
+	TempURLSecretKey     string              `json:"-" db:"-"`
+	ProjectIntegrationID *int64              `json:"project_integration_id" db:"project_integration_id"`
+	BlobSHA256           string              `json:"blob_sha256,omitempty" db:"blob_sha256"`
+	Provenance           *ArtifactProvenance `json:"provenance,omitempty" db:"provenance" cli:"-"`
 }
 
 // Equal returns true if w WorkflowNodeRunArtifact equals c
@@ -368,6 +371,19 @@ type WorkflowNodeJobRun struct {
 	IntegrationPluginBinaries []GRPCPluginBinary `json:"integration_plugin_binaries,omitempty"`
 	Header                    WorkflowRunHeaders `json:"header,omitempty"`
 	ContainsService           bool               `json:"contains_service,omitempty"`
+	Attempts                  []JobRunAttempt    `json:"attempts,omitempty"`
+	Matrix                    map[string]string  `json:"matrix,omitempty"`
+}
+
+// JobRunAttempt is a failed execution of a job run that has been retried
+type JobRunAttempt struct {
+	Attempt    int       `json:"attempt"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+	ExitCode   int       `json:"exit_code,omitempty"`
+	WorkerName string    `json:"worker_name,omitempty"`
+	Start      time.Time `json:"start"`
+	Done       time.Time `json:"done"`
 }
 
 // /!\ DONT FORGET TO REGENERATE EASYJSON FILES /!\
@@ -0,0 +1,128 @@
+package sdk
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Hook type used to search for workflow runs that were started manually.
+const WorkflowRunSearchManual = "Manual"
+
+// WorkflowRunSearchFilter contains the criteria to search for workflow runs.
+// Durations are in seconds, dates are compared with the start date of the runs.
+type WorkflowRunSearchFilter struct {
+	ProjectKey   string    `json:"project_key"`
+	WorkflowName string    `json:"workflow_name"`
+	Commit       string    `json:"commit,omitempty"`
+	Author       string    `json:"author,omitempty"`
+	Branch       string    `json:"branch,omitempty"`
+	Tag          string    `json:"tag,omitempty"`
+	Status       []string  `json:"status,omitempty"`
+	MinDuration  int64     `json:"min_duration,omitempty"`
+	MaxDuration  int64     `json:"max_duration,omitempty"`
+	From         time.Time `json:"from,omitempty"`
+	To           time.Time `json:"to,omitempty"`
+	HookType     string    `json:"hook_type,omitempty"`
+	Text         string    `json:"text,omitempty"`
+	Offset       int       `json:"offset,omitempty"`
+	Limit        int       `json:"limit,omitempty"`
+}
+
+// NewWorkflowRunSearchFilter reads search criteria from query parameters.
+func NewWorkflowRunSearchFilter(values url.Values) (WorkflowRunSearchFilter, error) {
+	f := WorkflowRunSearchFilter{
+		Commit:   values.Get("commit"),
+		Author:   values.Get("author"),
+		Branch:   values.Get("branch"),
+		Tag:      values.Get("tag"),
+		Status:   values["status"],
+		HookType: values.Get("hook"),
+		Text:     values.Get("text"),
+	}
+
+	for _, s := range f.Status {
+		if !StatusValidate(s) {
+			return f, NewErrorFrom(ErrWrongRequest, "invalid status %s", s)
+		}
+	}
+
+	var err error
+	for name, v := range map[string]*int64{"minDuration": &f.MinDuration, "maxDuration": &f.MaxDuration} {
+		if s := values.Get(name); s != "" {
+			if *v, err = strconv.ParseInt(s, 10, 64); err != nil || *v < 0 {
+				return f, NewErrorFrom(ErrWrongRequest, "invalid %s %s, expected a number of seconds", name, s)
+			}
+		}
+	}
+	for name, v := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if s := values.Get(name); s != "" {
+			if *v, err = time.Parse(time.RFC3339, s); err != nil {
+				return f, NewErrorFrom(ErrWrongRequest, "invalid %s date %s, expected format is RFC3339", name, s)
+			}
+		}
+	}
+	for name, v := range map[string]*int{"offset": &f.Offset, "limit": &f.Limit} {
+		if s := values.Get(name); s != "" {
+			if *v, err = strconv.Atoi(s); err != nil || *v < 0 {
+				return f, NewErrorFrom(ErrWrongRequest, "invalid %s %s", name, s)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// QueryParams returns the search criteria as query parameters.
+func (f WorkflowRunSearchFilter) QueryParams() url.Values {
+	values := url.Values{}
+	for name, v := range map[string]string{"commit": f.Commit, "author": f.Author, "branch": f.Branch,
+		"tag": f.Tag, "hook": f.HookType, "text": f.Text} {
+		if v != "" {
+			values.Set(name, v)
+		}
+	}
+	for _, s := range f.Status {
+		values.Add("status", s)
+	}
+	for name, v := range map[string]int64{"minDuration": f.MinDuration, "maxDuration": f.MaxDuration} {
+		if v > 0 {
+			values.Set(name, strconv.FormatInt(v, 10))
+		}
+	}
+	for name, v := range map[string]time.Time{"from": f.From, "to": f.To} {
+		if !v.IsZero() {
+			values.Set(name, v.Format(time.RFC3339))
+		}
+	}
+	for name, v := range map[string]int{"offset": f.Offset, "limit": f.Limit} {
+		if v > 0 {
+			values.Set(name, strconv.Itoa(v))
+		}
+	}
+	return values
+}
+
+// WorkflowRunSearchDocument is the document indexed in elasticsearch for a terminated workflow run.
+type WorkflowRunSearchDocument struct {
+	ID           int64     `json:"id"`
+	ProjectKey   string    `json:"project_key"`
+	WorkflowName string    `json:"workflow_name"`
+	Number       int64     `json:"num"`
+	Status       string    `json:"status"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Duration     int64     `json:"duration"`
+	Branches     []string  `json:"branches,omitempty"`
+	Tags         []string  `json:"tags,omitempty"`
+	Commits      []string  `json:"commits,omitempty"`
+	Authors      []string  `json:"authors,omitempty"`
+	HookTypes    []string  `json:"hook_types,omitempty"`
+	Logs         string    `json:"logs,omitempty"`
+}
+
+// WorkflowRunSearchResult contains the ids of the workflow runs matching a search, ordered by start date desc.
+type WorkflowRunSearchResult struct {
+	Total int64   `json:"total"`
+	IDs   []int64 `json:"ids"`
+}
@@ -0,0 +1,37 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowRunSearchFilterQueryParams(t *testing.T) {
+	f := WorkflowRunSearchFilter{
+		Commit:      "abc123",
+		Author:      "john",
+		Branch:      "feat/my-feature",
+		Status:      []string{StatusSuccess, StatusFail},
+		MinDuration: 60,
+		From:        time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		HookType:    SchedulerModelName,
+		Text:        "connection refused",
+		Limit:       20,
+	}
+
+	res, err := NewWorkflowRunSearchFilter(f.QueryParams())
+	require.NoError(t, err)
+	assert.Equal(t, f, res)
+
+	values := f.QueryParams()
+	values.Set("minDuration", "-1")
+	_, err = NewWorkflowRunSearchFilter(values)
+	assert.Error(t, err)
+
+	values = f.QueryParams()
+	values.Set("from", "2020-01-01")
+	_, err = NewWorkflowRunSearchFilter(values)
+	assert.Error(t, err)
+}
@@ -15,6 +15,7 @@ type WorkflowTemplateRequest struct {
 	WorkflowName string            `json:"workflow_name"`
 	Parameters   map[string]string `json:"parameters"`
 	Detached     bool              `json:"detached,omitempty"`
+	VersionRange string            `json:"version_range,omitempty"`
 }
 
 // Value returns driver.Value from workflow template request.
@@ -55,10 +56,12 @@ type WorkflowTemplate struct {
 	Pipelines    PipelineTemplates          `json:"pipelines" db:"pipelines"`
 	Applications ApplicationTemplates       `json:"applications" db:"applications"`
 	Environments EnvironmentTemplates       `json:"environments" db:"environments"`
+	Includes     WorkflowTemplateIncludes   `json:"includes,omitempty" db:"includes"`
 	Version      int64                      `json:"version" db:"version"`
 	ImportURL    string                     `json:"import_url" db:"import_url"`
 	// aggregates
 	Group         *Group                 `json:"group,omitempty" db:"-"`
+	Included      []WorkflowTemplate     `json:"included,omitempty" db:"-"`
 	FirstAudit    *AuditWorkflowTemplate `json:"first_audit,omitempty" db:"-"`
 	LastAudit     *AuditWorkflowTemplate `json:"last_audit,omitempty" db:"-"`
 	Editable      bool                   `json:"editable,omitempty" db:"-"`
@@ -126,6 +129,12 @@ func (w *WorkflowTemplate) IsValid() error {
 		}
 	}
 
+	for _, i := range w.Includes {
+		if err := i.IsValid(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -138,6 +147,9 @@ func (w *WorkflowTemplate) CheckParams(r WorkflowTemplateRequest) error {
 	if !regexp.MatchString(r.WorkflowName) {
 		return NewErrorFrom(ErrInvalidData, "Invalid given workflow name, should match %s pattern", NamePattern)
 	}
+	if err := CheckVersionRange(r.VersionRange); err != nil {
+		return err
+	}
 
 	for _, p := range w.Parameters {
 		v, ok := r.Parameters[p.Key]
@@ -183,10 +195,19 @@ func (w *WorkflowTemplate) Update(data WorkflowTemplate) {
 	w.Pipelines = data.Pipelines
 	w.Applications = data.Applications
 	w.Environments = data.Environments
+	w.Includes = data.Includes
 	w.Version = w.Version + 1
 	w.ImportURL = data.ImportURL
 }
 
+// Path returns the path of the template like group-name/template-slug.
+func (w WorkflowTemplate) Path() string {
+	if w.Group == nil {
+		return w.Slug
+	}
+	return fmt.Sprintf("%s/%s", w.Group.Name, w.Slug)
+}
+
 // WorkflowTemplatesToIDs returns ids of given workflow templates.
 func WorkflowTemplatesToIDs(wts []*WorkflowTemplate) []int64 {
 	ids := make([]int64, len(wts))
@@ -244,6 +265,52 @@ func (e *EnvironmentTemplate) IsValid() error {
 	return nil
 }
 
+// WorkflowTemplateInclude is a reference to another template whose pipelines, applications and environments are
+// generated with the ones of the including template. The values of the parameters given to the included template
+// are templates executed with the data of the including one, parameters with the same key are passed down if not set.
+type WorkflowTemplateInclude struct {
+	Template   string            `json:"template"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// IsValid returns template include validity.
+func (i WorkflowTemplateInclude) IsValid() error {
+	if _, _, err := i.GroupAndSlug(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// GroupAndSlug returns the group name and the slug of the included template.
+func (i WorkflowTemplateInclude) GroupAndSlug() (string, string, error) {
+	sp := strings.Split(i.Template, "/")
+	if len(sp) != 2 || sp[0] == "" || sp[1] == "" {
+		return "", "", NewErrorFrom(ErrInvalidData, "invalid included template %q, should be like group-name/template-slug", i.Template)
+	}
+	return sp[0], sp[1], nil
+}
+
+// WorkflowTemplateIncludes struct.
+type WorkflowTemplateIncludes []WorkflowTemplateInclude
+
+// Value returns driver.Value from workflow template includes.
+func (w WorkflowTemplateIncludes) Value() (driver.Value, error) {
+	j, err := json.Marshal(w)
+	return j, WrapError(err, "cannot marshal WorkflowTemplateIncludes")
+}
+
+// Scan workflow template includes.
+func (w *WorkflowTemplateIncludes) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, w), "cannot unmarshal WorkflowTemplateIncludes")
+}
+
 // TemplateParameterType used for template parameter.
 type TemplateParameterType string
 
@@ -372,6 +439,8 @@ type WorkflowTemplateInstance struct {
 	WorkflowTemplateVersion int64                   `json:"workflow_template_version" db:"workflow_template_version"`
 	Request                 WorkflowTemplateRequest `json:"request" db:"request"`
 	WorkflowName            string                  `json:"workflow_name" db:"workflow_name"`
+	ReleaseVersion          string                  `json:"release_version,omitempty" db:"release_version"`
+	PreviousReleaseVersion  string                  `json:"previous_release_version,omitempty" db:"previous_release_version"`
 	// aggregates
 	FirstAudit *AuditWorkflowTemplateInstance `json:"first_audit,omitempty" db:"-"`
 	LastAudit  *AuditWorkflowTemplateInstance `json:"last_audit,omitempty" db:"-"`
@@ -441,6 +510,7 @@ type WorkflowTemplateBulkOperation struct {
 	Status  OperationStatus         `json:"status"`
 	Error   string                  `json:"error,omitempty"`
 	Request WorkflowTemplateRequest `json:"request"`
+	Version string                  `json:"version,omitempty"`
 }
 
 // WorkflowTemplateBulkOperations struct.
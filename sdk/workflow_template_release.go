@@ -0,0 +1,199 @@
+package sdk
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/blang/semver"
+)
+
+// WorkflowTemplateRelease is a release of a workflow template identified by a semantic version,
+// it contains a snapshot of the template at the time of the release.
+type WorkflowTemplateRelease struct {
+	ID                 int64            `json:"id" db:"id"`
+	WorkflowTemplateID int64            `json:"workflow_template_id" db:"workflow_template_id"`
+	Version            string           `json:"version" db:"version"`
+	Changelog          string           `json:"changelog" db:"changelog"`
+	Template           WorkflowTemplate `json:"template" db:"template"`
+	Author             string           `json:"author" db:"author"`
+	Created            time.Time        `json:"created" db:"created"`
+}
+
+// IsValid returns workflow template release validity.
+func (r WorkflowTemplateRelease) IsValid() error {
+	if _, err := semver.Parse(r.Version); err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid release version %q, should be a semantic version like 1.2.0", r.Version)
+	}
+	return nil
+}
+
+// CheckVersionRange returns an error if given version range is not valid.
+func CheckVersionRange(versionRange string) error {
+	if versionRange == "" {
+		return nil
+	}
+	if _, err := semver.ParseRange(versionRange); err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid version range %q, should be like '>=1.2.0 <2.0.0' or '1.x'", versionRange)
+	}
+	return nil
+}
+
+// WorkflowTemplateReleases is a list of releases of a workflow template.
+type WorkflowTemplateReleases []WorkflowTemplateRelease
+
+// Sort releases from the greatest version to the lowest.
+func (rs WorkflowTemplateReleases) Sort() {
+	sort.Slice(rs, func(i, j int) bool {
+		vi, _ := semver.Parse(rs[i].Version)
+		vj, _ := semver.Parse(rs[j].Version)
+		return vi.GT(vj)
+	})
+}
+
+// Get returns the release with given version.
+func (rs WorkflowTemplateReleases) Get(version string) (*WorkflowTemplateRelease, error) {
+	for i := range rs {
+		if rs[i].Version == version {
+			return &rs[i], nil
+		}
+	}
+	return nil, NewErrorFrom(ErrNotFound, "no release found for version %s", version)
+}
+
+// CheckNewVersion returns an error if given version is not greater than the version of all releases.
+func (rs WorkflowTemplateReleases) CheckNewVersion(version string) error {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid release version %q, should be a semantic version like 1.2.0", version)
+	}
+	for i := range rs {
+		rv, err := semver.Parse(rs[i].Version)
+		if err == nil && rv.GTE(v) {
+			return NewErrorFrom(ErrWrongRequest, "release version %s should be greater than existing release %s", version, rs[i].Version)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the release to apply on an instance pinned to given version range. If version is set,
+// the release with this version is returned if it matches the range, otherwise the greatest release matching the range.
+func (rs WorkflowTemplateReleases) Resolve(version, versionRange string) (*WorkflowTemplateRelease, error) {
+	if err := CheckVersionRange(versionRange); err != nil {
+		return nil, err
+	}
+	var rg semver.Range
+	if versionRange != "" {
+		rg = semver.MustParseRange(versionRange)
+	}
+	inRange := func(v semver.Version) bool { return rg == nil || rg(v) }
+
+	if version != "" {
+		r, err := rs.Get(version)
+		if err != nil {
+			return nil, err
+		}
+		if v, err := semver.Parse(r.Version); err != nil || !inRange(v) {
+			return nil, NewErrorFrom(ErrWrongRequest, "release %s doesn't match the version range %s", version, versionRange)
+		}
+		return r, nil
+	}
+
+	var res *WorkflowTemplateRelease
+	var resVersion semver.Version
+	for i := range rs {
+		v, err := semver.Parse(rs[i].Version)
+		if err != nil || !inRange(v) {
+			continue
+		}
+		if res == nil || v.GT(resVersion) {
+			res, resVersion = &rs[i], v
+		}
+	}
+	if res == nil {
+		return nil, NewErrorFrom(ErrNotFound, "no release found matching the version range %s", versionRange)
+	}
+	return res, nil
+}
+
+// WithRelease returns the template as it was for given release.
+func (w WorkflowTemplate) WithRelease(r WorkflowTemplateRelease) WorkflowTemplate {
+	t := r.Template
+	t.ID, t.GroupID, t.Group, t.Name, t.Slug = w.ID, w.GroupID, w.Group, w.Name, w.Slug
+	return t
+}
+
+// WorkflowTemplateUpgradeRequest is used to upgrade the instances of a template to a release. The upgrade
+// can be rolled out to a subset of the instances first, selected by percentage or by workflow labels.
+type WorkflowTemplateUpgradeRequest struct {
+	Version    string   `json:"version,omitempty"`
+	Percentage int      `json:"percentage,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	DryRun     bool     `json:"dry_run,omitempty"`
+}
+
+// IsValid returns upgrade request validity.
+func (r WorkflowTemplateUpgradeRequest) IsValid() error {
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return NewErrorFrom(ErrWrongRequest, "invalid percentage %d, should be between 0 and 100", r.Percentage)
+	}
+	return nil
+}
+
+// Selects returns true if the instance with given id and workflow labels is part of the rollout.
+// The selection by percentage is stable, so an instance selected for a percentage is also selected for greater ones.
+func (r WorkflowTemplateUpgradeRequest) Selects(instanceID int64, labels []string) bool {
+	if len(r.Labels) > 0 {
+		var found bool
+		for _, l := range labels {
+			if IsInArray(l, r.Labels) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if r.Percentage > 0 && r.Percentage < 100 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(strconv.FormatInt(instanceID, 10)))
+		return int(h.Sum32()%100) < r.Percentage
+	}
+	return true
+}
+
+// WorkflowTemplateUpgradeResult contains the instances selected by an upgrade request and the bulk that applies it.
+type WorkflowTemplateUpgradeResult struct {
+	Version   string                         `json:"version"`
+	Instances []WorkflowTemplateInstanceDiff `json:"instances"`
+	Bulk      *WorkflowTemplateBulk          `json:"bulk,omitempty"`
+}
+
+// WorkflowTemplateInstanceDiff contains the differences between the files generated for an instance
+// by its current version of the template and another one.
+type WorkflowTemplateInstanceDiff struct {
+	InstanceID   int64                      `json:"instance_id"`
+	ProjectKey   string                     `json:"project_key"`
+	WorkflowName string                     `json:"workflow_name"`
+	FromVersion  string                     `json:"from_version"`
+	ToVersion    string                     `json:"to_version"`
+	Files        []WorkflowTemplateFileDiff `json:"files,omitempty"`
+	Skipped      string                     `json:"skipped,omitempty"`
+}
+
+// WorkflowTemplateFileDiff is the unified diff of a generated file.
+type WorkflowTemplateFileDiff struct {
+	Name string `json:"name"`
+	Diff string `json:"diff"`
+}
+
+// WorkflowTemplateVersionName returns the displayed name of a template version, the release version if any.
+func WorkflowTemplateVersionName(releaseVersion string, version int64) string {
+	if releaseVersion != "" {
+		return releaseVersion
+	}
+	return fmt.Sprintf("unreleased (v%d)", version)
+}
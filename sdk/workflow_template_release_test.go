@@ -0,0 +1,65 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowTemplateReleasesResolve(t *testing.T) {
+	rs := WorkflowTemplateReleases{{Version: "1.0.0"}, {Version: "2.1.0"}, {Version: "1.10.0"}, {Version: "1.2.0"}}
+	rs.Sort()
+	assert.Equal(t, []string{"2.1.0", "1.10.0", "1.2.0", "1.0.0"}, []string{rs[0].Version, rs[1].Version, rs[2].Version, rs[3].Version})
+
+	r, err := rs.Resolve("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.0", r.Version)
+
+	r, err = rs.Resolve("", ">=1.0.0 <2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, "1.10.0", r.Version)
+
+	r, err = rs.Resolve("1.2.0", "1.x")
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0", r.Version)
+
+	_, err = rs.Resolve("2.1.0", "1.x")
+	assert.True(t, ErrorIs(err, ErrWrongRequest))
+
+	_, err = rs.Resolve("", ">=3.0.0")
+	assert.True(t, ErrorIs(err, ErrNotFound))
+
+	_, err = rs.Resolve("", "not a range")
+	assert.True(t, ErrorIs(err, ErrWrongRequest))
+
+	assert.NoError(t, rs.CheckNewVersion("2.1.1"))
+	assert.Error(t, rs.CheckNewVersion("2.1.0"))
+	assert.Error(t, rs.CheckNewVersion("1.11.0"))
+}
+
+func TestWorkflowTemplateUpgradeRequestSelects(t *testing.T) {
+	req := WorkflowTemplateUpgradeRequest{Labels: []string{"canary"}}
+	assert.True(t, req.Selects(1, []string{"canary", "prod"}))
+	assert.False(t, req.Selects(1, []string{"prod"}))
+	assert.False(t, req.Selects(1, nil))
+
+	// an instance selected for a percentage should be selected for all greater percentages
+	var selected10, selected50 int
+	for id := int64(1); id <= 1000; id++ {
+		s10 := WorkflowTemplateUpgradeRequest{Percentage: 10}.Selects(id, nil)
+		s50 := WorkflowTemplateUpgradeRequest{Percentage: 50}.Selects(id, nil)
+		if s10 {
+			selected10++
+			assert.True(t, s50)
+		}
+		if s50 {
+			selected50++
+		}
+		assert.True(t, WorkflowTemplateUpgradeRequest{Percentage: 100}.Selects(id, nil))
+	}
+	assert.InDelta(t, 100, selected10, 50)
+	assert.InDelta(t, 500, selected50, 100)
+
+	assert.Error(t, WorkflowTemplateUpgradeRequest{Percentage: 101}.IsValid())
+}
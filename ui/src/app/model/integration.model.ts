@@ -12,6 +12,7 @@ export class IntegrationModel {
     deployment: boolean;
     compute: boolean;
     event: boolean;
+    secret_backend: boolean;
     public: boolean;
 }
 
@@ -15,17 +15,19 @@ export class PipelineStatus {
     static NEVER_BUILT = 'Never Built';
     static STOPPED = 'Stopped';
     static PENDING = 'Pending';
+    static TIMEOUT = 'Timeout';
+    static WAITING_APPROVAL = 'Waiting approval';
 
     static neverRun(status: string) {
         return status === this.SKIPPED || status === this.NEVER_BUILT || status === this.SKIPPED || status === this.DISABLED;
     }
 
     static isActive(status: string) {
-        return status === this.WAITING || status === this.BUILDING || status === this.PENDING;
+        return status === this.WAITING || status === this.BUILDING || status === this.PENDING || status === this.WAITING_APPROVAL;
     }
 
     static isDone(status: string) {
-        return status === this.SUCCESS || status === this.STOPPED || status === this.FAIL;
+        return status === this.SUCCESS || status === this.STOPPED || status === this.FAIL || status === this.TIMEOUT;
     }
 }
 
@@ -43,6 +43,7 @@ export class ModelDocker {
     envs: {};
     cmd: string;
     memory: number;
+    pod_template: string;
 }
 
 export class ModelVirtualMachine {
@@ -43,6 +43,8 @@ export class TaskExecution {
     webhook: Webhook;
     rabbitmq: RabbitMQ;
     kafka: Kafka;
+    nats: NATS;
+    amqp: AMQP;
     scheduled_task?: any;
     status: HookStatus;
 }
@@ -61,3 +63,13 @@ export class Kafka {
     message: string;
 }
 
+export class NATS {
+    subject: string;
+    message: string;
+}
+
+export class AMQP {
+    address: string;
+    message: string;
+}
+
@@ -17,6 +17,7 @@ export class WNodeType {
     static JOIN = 'join';
     static FORK = 'fork';
     static OUTGOINGHOOK = 'outgoinghook';
+    static APPROVAL = 'approval';
 }
 
 // Workflow represents a pipeline based workflow
@@ -374,6 +375,9 @@ export class WorkflowNodeHookConfigValue {
 export class WorkflowNodeConditions {
     lua_script: string;
     plain: Array<WorkflowNodeCondition>;
+    operator: string;
+    not: boolean;
+    groups: Array<WorkflowNodeConditions>;
 }
 
 // WorkflowTriggerCondition represents a condition to trigger ot not a pipeline in a workflow. Operator can be =, !=, regex
@@ -421,6 +425,7 @@ export class WNode {
     triggers: Array<WNodeTrigger>;
     context: WNodeContext;
     outgoing_hook: WNodeOutgoingHook;
+    approval: WNodeApproval;
     parents: Array<WNodeJoin>;
     hooks: Array<WNodeHook>;
     groups: Array<GroupPermission>;
@@ -665,6 +670,13 @@ export class WNodeOutgoingHook {
     config: Map<string, WorkflowNodeHookConfigValue>;
 }
 
+export class WNodeApproval {
+    groups: Array<string>;
+    min_approvals: number;
+    expiry: number;
+    comment: string;
+}
+
 export class WNodeJoin {
     id: number;
     node_id: number;
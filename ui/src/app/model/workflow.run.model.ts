@@ -97,12 +97,20 @@ export class WorkflowNodeRun implements WithKey {
     execution_id: string;
     callback: WorkflowNodeOutgoingHookRunCallback;
     static_files: Array<WorkflowNodeRunStaticFiles>;
+    approvals: Array<WorkflowNodeRunApproval>;
 
     key(): string {
         return `${this.id}-${this.num}.${this.subnumber}`;
     }
 }
 
+export class WorkflowNodeRunApproval {
+    user_id: string;
+    username: string;
+    comment: string;
+    date: Date;
+}
+
 export class WorkflowNodeOutgoingHookRunCallback {
     workflow_node_outgoing_hook_id: number;
     start: Date;
@@ -152,6 +160,7 @@ export class WorkflowNodeJobRun {
     model: string;
     bookedby: Hatchery;
     spawninfos: Array<SpawnInfo>;
+    matrix: { [axis: string]: string };
 
     // UI infos for queue
     duration: string;
@@ -132,6 +132,11 @@ export class ConditionsComponent extends Table<WorkflowNodeCondition> implements
         this.conditionsChange.emit(this.conditions);
     }
 
+    setOperator(or: boolean): void {
+        this.conditions.operator = or ? 'or' : 'and';
+        this.pushChange('operator');
+    }
+
     filterConditionVariables(opts: string[], query: string) {
         let result: Array<string> = opts.filter((opt) => opt.indexOf(query) > -1);
         if (result.indexOf(query) === -1) {
@@ -102,6 +102,10 @@ export class HookTaskShowComponent {
                 this.selectedExecutionBody = this.decodeBody(e.rabbitmq.message);
             } else if (e.kafka) {
                 this.selectedExecutionBody = this.decodeBody(e.kafka.message);
+            } else if (e.nats) {
+                this.selectedExecutionBody = this.decodeBody(e.nats.message);
+            } else if (e.amqp) {
+                this.selectedExecutionBody = this.decodeBody(e.amqp.message);
             }
         };
     }
@@ -44,6 +44,7 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
     selectedRunJobParameters = {};
     mapJobStatus: Map<number, { status: string, warnings: number }> = new Map<number, { status: string, warnings: number }>();
     mapStepStatus: Map<string, StepStatus> = new Map<string, StepStatus>();
+    mapMatrixRunJobs: Map<number, Array<WorkflowNodeJobRun>> = new Map<number, Array<WorkflowNodeJobRun>>();
 
     previousStatus: string;
     manual = false;
@@ -124,6 +125,16 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
         }
     }
 
+    selectedMatrixRunJob(rj: WorkflowNodeJobRun): void {
+        this.manual = true;
+        this.selectedRunJob = rj;
+        this.checkJobParameters();
+    }
+
+    matrixLabel(rj: WorkflowNodeJobRun): string {
+        return Object.keys(rj.matrix).sort().map(k => k + '=' + rj.matrix[k]).join(', ');
+    }
+
     selectedJob(j: Job): void {
         this.nodeRun.stages.forEach(s => {
             if (s.run_jobs) {
@@ -144,6 +155,7 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
             this.previousStatus = this.nodeRun.status;
         }
         // Set selected job if needed or refresh step_status
+        this.mapMatrixRunJobs = new Map<number, Array<WorkflowNodeJobRun>>();
         if (this.nodeRun.stages) {
             this.nodeRun.stages.forEach((s, sIndex) => {
                 if (!this.manual && previousRun && (!previousRun.stages[sIndex].status ||
@@ -157,7 +169,7 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
                         // Update map step status
                         if (rj.job.step_status) {
                             rj.job.step_status.forEach(ss => {
-                                this.mapStepStatus[rj.job.pipeline_action_id + '-' + ss.step_order] = ss;
+                                this.mapStepStatus[rj.id + '-' + ss.step_order] = ss;
                                 if (ss.status === PipelineStatus.FAIL && rj.job.action.actions[ss.step_order] &&
                                     rj.job.action.actions[ss.step_order].optional) {
                                     warnings++;
@@ -165,8 +177,19 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
                             });
                         }
 
-                        // Update job status
-                        this.mapJobStatus.set(rj.job.pipeline_action_id, { status: rj.status, warnings });
+                        // Update job status, runs of a matrix job are grouped under the job
+                        if (rj.matrix) {
+                            let matrixRunJobs = this.mapMatrixRunJobs.get(rj.job.pipeline_action_id) || [];
+                            matrixRunJobs.push(rj);
+                            this.mapMatrixRunJobs.set(rj.job.pipeline_action_id, matrixRunJobs);
+                            let previous = matrixRunJobs.length > 1 ? this.mapJobStatus.get(rj.job.pipeline_action_id) : null;
+                            this.mapJobStatus.set(rj.job.pipeline_action_id, {
+                                status: previous ? this.matrixStatus(previous.status, rj.status) : rj.status,
+                                warnings: previous ? previous.warnings + warnings : warnings
+                            });
+                        } else {
+                            this.mapJobStatus.set(rj.job.pipeline_action_id, { status: rj.status, warnings });
+                        }
 
                         // Select temp job
                         if (!this.selectedRunJob && sIndex === 0 && rjIndex === 0) {
@@ -184,6 +207,17 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
         }
     }
 
+    // matrixStatus aggregates the statuses of matrix job runs, a running or failed job run prevails
+    matrixStatus(s1: string, s2: string): string {
+        for (let s of [PipelineStatus.BUILDING, PipelineStatus.WAITING, PipelineStatus.FAIL, PipelineStatus.TIMEOUT,
+            PipelineStatus.STOPPED, PipelineStatus.SUCCESS]) {
+            if (s1 === s || s2 === s) {
+                return s;
+            }
+        }
+        return s1;
+    }
+
     updateTime(): void {
         this.jobTime = new Map<number, string>();
         let stillRunning = false;
@@ -205,6 +239,7 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
                             case this.pipelineStatusEnum.SUCCESS:
                             case this.pipelineStatusEnum.FAIL:
                             case this.pipelineStatusEnum.STOPPED:
+                            case this.pipelineStatusEnum.TIMEOUT:
                                 this.jobTime.set(rj.job.pipeline_action_id,
                                     this._durationService.duration(new Date(rj.start), new Date(rj.done)));
                                 break;
@@ -212,7 +247,7 @@ export class WorkflowRunNodePipelineComponent implements OnInit, OnDestroy {
 
                         if (rj.job.step_status) {
                             rj.job.step_status.forEach(ss => {
-                                this.mapStepStatus.set(rj.job.pipeline_action_id + '-' + ss.step_order, ss);
+                                this.mapStepStatus.set(rj.id + '-' + ss.step_order, ss);
                             });
                         }
                     });
@@ -100,7 +100,7 @@ export class WorkflowServiceLogComponent implements OnDestroy {
                             this.loading = false;
                         }
                         if (this.nodeJobRun.status === PipelineStatus.SUCCESS || this.nodeJobRun.status === PipelineStatus.FAIL ||
-                            this.nodeJobRun.status === PipelineStatus.STOPPED) {
+                            this.nodeJobRun.status === PipelineStatus.STOPPED || this.nodeJobRun.status === PipelineStatus.TIMEOUT) {
                             this.stopWorker();
                         }
                     });
@@ -140,7 +140,7 @@ export class WorkflowRunJobSpawnInfoComponent implements OnDestroy {
                             this.spawnInfos = this.getSpawnInfos(serviceSpawnInfos);
                         }
                         if (this.jobStatus === PipelineStatus.SUCCESS || this.jobStatus === PipelineStatus.FAIL ||
-                            this.jobStatus === PipelineStatus.STOPPED) {
+                            this.jobStatus === PipelineStatus.STOPPED || this.jobStatus === PipelineStatus.TIMEOUT) {
                             this.stopWorker();
                             if (this.nodeJobRun.spawninfos && this.nodeJobRun.spawninfos.length > 0) {
                                 this.spawnInfos = this.getSpawnInfos(this.nodeJobRun.spawninfos);